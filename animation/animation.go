@@ -0,0 +1,239 @@
+package animation
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/scene"
+	"image"
+	imagecolor "image/color"
+	"time"
+)
+
+/*
+ * The time each slice is displayed and the number of frames per second
+ * written for videos unless configured otherwise.
+ */
+const (
+	DEFAULT_DELAY      = 500 * time.Millisecond
+	DEFAULT_FRAME_RATE = 30.0
+)
+
+/*
+ * An overlay draws onto a rendered frame before it is encoded, e. g. a date
+ * label or a logo.
+ *
+ * It receives the number of the frame and the point in time it shows.
+ */
+type Overlay func(img *image.NRGBA, frame int, t time.Time) error
+
+/*
+ * Data structure representing the configuration of an animation.
+ */
+type configStruct struct {
+	background imagecolor.NRGBA
+	cumulative bool
+	delay      time.Duration
+	dithering  bool
+	frameRate  float64
+	overlay    Overlay
+	plays      int
+}
+
+/*
+ * A configuration option for an animation.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Fill the background of each frame with a color.
+ *
+ * Since GIF only supports fully transparent pixels, use an opaque background
+ * for GIF animations.
+ */
+func WithBackground(background imagecolor.NRGBA) Option {
+
+	/*
+	 * Set background.
+	 */
+	return func(config *configStruct) {
+		config.background = background
+	}
+
+}
+
+/*
+ * Show all slices up to the current one in each frame instead of only the
+ * current slice, so that the map fills up over time.
+ */
+func WithCumulative() Option {
+
+	/*
+	 * Enable cumulative frames.
+	 */
+	return func(config *configStruct) {
+		config.cumulative = true
+	}
+
+}
+
+/*
+ * Set the time each slice is displayed.
+ */
+func WithDelay(delay time.Duration) Option {
+
+	/*
+	 * Set delay.
+	 */
+	return func(config *configStruct) {
+		config.delay = delay
+	}
+
+}
+
+/*
+ * Enable dithering when reducing the colors of GIF frames.
+ */
+func WithDithering() Option {
+
+	/*
+	 * Enable dithering.
+	 */
+	return func(config *configStruct) {
+		config.dithering = true
+	}
+
+}
+
+/*
+ * Set the number of frames per second written for videos.
+ *
+ * Each slice is repeated for as many frames as needed to display it for
+ * the configured delay. Pass the same frame rate to the video encoder.
+ */
+func WithFrameRate(frameRate float64) Option {
+
+	/*
+	 * Set frame rate.
+	 */
+	return func(config *configStruct) {
+		config.frameRate = frameRate
+	}
+
+}
+
+/*
+ * Draw an overlay onto each frame written for videos.
+ */
+func WithOverlay(overlay Overlay) Option {
+
+	/*
+	 * Set overlay.
+	 */
+	return func(config *configStruct) {
+		config.overlay = overlay
+	}
+
+}
+
+/*
+ * Set the number of times the animation is played, where zero plays it
+ * forever.
+ */
+func WithPlays(plays int) Option {
+
+	/*
+	 * Set number of plays.
+	 */
+	return func(config *configStruct) {
+		config.plays = plays
+	}
+
+}
+
+/*
+ * Apply options to the default configuration.
+ */
+func configure(options []Option) configStruct {
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		delay:     DEFAULT_DELAY,
+		frameRate: DEFAULT_FRAME_RATE,
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	return config
+}
+
+/*
+ * Create a scene for each frame of the animation and a mapping, which maps
+ * the counts of all frames using a fixed color scale.
+ *
+ * If the mapping implements color.ChunkedMapping, like the single-color and
+ * gradient mappings provided by the color package, the color scale is fitted
+ * to all frames. Otherwise, the mapping is used as it is.
+ */
+func prepare(ts scene.TemporalScene, mapping color.Mapping, config *configStruct) ([]scene.Scene, color.Mapping, error) {
+	numFrames := ts.Len()
+
+	/*
+	 * Verify that there are frames to animate.
+	 */
+	if numFrames == 0 {
+		return nil, nil, fmt.Errorf("%s", "Temporal scene has no slices to animate.")
+	} else if mapping == nil {
+		return nil, nil, fmt.Errorf("%s", "Color mapping must not be nil when rendering an animation!")
+	} else {
+		frames := make([]scene.Scene, numFrames)
+		stats := color.CreateStatistics()
+
+		/*
+		 * Create each frame.
+		 */
+		for i := range frames {
+			frame := scene.Scene(nil)
+			err := error(nil)
+
+			/*
+			 * Decide on the slices shown in the frame.
+			 */
+			if config.cumulative {
+				frame, err = ts.Window(0, i+1)
+			} else {
+				frame, err = ts.Slice(i)
+			}
+
+			/*
+			 * Check if frame could be created.
+			 */
+			if err != nil {
+				return nil, nil, err
+			}
+
+			frame.SetBackground(config.background)
+			frame.SetDithering(config.dithering)
+			counts := frame.Counts()
+			stats.Add(counts)
+			frames[i] = frame
+		}
+
+		shared := color.Shared(mapping, stats)
+		return frames, shared, nil
+	}
+
+}
@@ -0,0 +1,450 @@
+package animation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/scene"
+	"hash/crc32"
+	"image"
+	imagecolor "image/color"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+/*
+ * Data structure representing a chunk read from a PNG file.
+ */
+type chunkStruct struct {
+	data []byte
+	kind string
+}
+
+/*
+ * Returns a temporal scene with three slices, each holding different
+ * points.
+ */
+func testScene(t *testing.T) scene.TemporalScene {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Hour)
+	boundaries := scene.Steps(start, end, time.Hour)
+	ts, err := scene.CreateTemporal(4, 3, 0.0, 4.0, 0.0, 3.0, boundaries)
+
+	/*
+	 * Check if scene could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	/*
+	 * The points and the times they were recorded.
+	 */
+	points := []coordinates.Cartesian{
+		coordinates.CreateCartesian(0.5, 0.5),
+		coordinates.CreateCartesian(0.5, 0.5),
+		coordinates.CreateCartesian(3.5, 2.5),
+		coordinates.CreateCartesian(1.5, 1.5),
+		coordinates.CreateCartesian(2.5, 0.5),
+	}
+
+	times := []time.Time{
+		start.Add(10 * time.Minute),
+		start.Add(20 * time.Minute),
+		start.Add(70 * time.Minute),
+		start.Add(130 * time.Minute),
+		start.Add(140 * time.Minute),
+	}
+
+	err = ts.Aggregate(points, times)
+
+	/*
+	 * Check if points could be aggregated.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	return ts
+}
+
+/*
+ * Split a PNG file into its chunks, verifying their checksums.
+ */
+func readChunks(t *testing.T, data []byte) []chunkStruct {
+
+	/*
+	 * Check the signature.
+	 */
+	if !bytes.HasPrefix(data, []byte(APNG_SIGNATURE)) {
+		t.Fatalf("%s", "Invalid PNG signature.")
+	}
+
+	data = data[len(APNG_SIGNATURE):]
+	chunks := []chunkStruct{}
+
+	/*
+	 * Read each chunk.
+	 */
+	for len(data) > 0 {
+
+		/*
+		 * Check if chunk header is complete.
+		 */
+		if len(data) < 12 {
+			t.Fatalf("%s", "Truncated chunk.")
+		}
+
+		size := int(binary.BigEndian.Uint32(data[0:4]))
+
+		/*
+		 * Check if chunk is complete.
+		 */
+		if len(data) < 12+size {
+			t.Fatalf("%s", "Truncated chunk.")
+		}
+
+		kind := string(data[4:8])
+		payload := data[8 : 8+size]
+		sum := binary.BigEndian.Uint32(data[8+size:])
+
+		/*
+		 * Check the checksum.
+		 */
+		if crc32.ChecksumIEEE(data[4:8+size]) != sum {
+			t.Errorf("Chunk '%s' has an invalid checksum.", kind)
+		}
+
+		chunks = append(chunks, chunkStruct{data: payload, kind: kind})
+		data = data[12+size:]
+	}
+
+	return chunks
+}
+
+/*
+ * Decompress the data of a frame and remove the filter types of its rows,
+ * which must all be zero.
+ */
+func decodePixels(t *testing.T, data []byte, width int) []byte {
+	rd, err := zlib.NewReader(bytes.NewReader(data))
+
+	/*
+	 * Read pixels if stream could be opened.
+	 */
+	if err == nil {
+		data, err = ioutil.ReadAll(rd)
+	}
+
+	/*
+	 * Check if pixels could be decompressed.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	rowSize := 1 + (4 * width)
+	pixels := []byte{}
+
+	/*
+	 * Remove the filter type of each row.
+	 */
+	for len(data) >= rowSize {
+
+		/*
+		 * Rows are not filtered.
+		 */
+		if data[0] != 0 {
+			t.Errorf("Row has filter type %d.", data[0])
+		}
+
+		pixels = append(pixels, data[1:rowSize]...)
+		data = data[rowSize:]
+	}
+
+	return pixels
+}
+
+/*
+ * Encode a temporal scene as APNG and read the frames back.
+ */
+func TestEncodeAPNG(t *testing.T) {
+	ts := testScene(t)
+	mapping := color.DefaultMapping()
+	buf := bytes.Buffer{}
+	err := EncodeAPNG(&buf, ts, mapping, WithDelay(250*time.Millisecond), WithPlays(2), nil)
+
+	/*
+	 * Check if animation could be encoded.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	data := buf.Bytes()
+	chunks := readChunks(t, data)
+	config := configure([]Option{WithDelay(250 * time.Millisecond)})
+	frames, shared, err := prepare(ts, mapping, &config)
+
+	/*
+	 * Check if frames could be prepared.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	kinds := []string{}
+	sequence := uint32(0)
+	frame := -1
+	frameData := [][]byte{}
+
+	/*
+	 * Check the chunks in order.
+	 */
+	for _, chunk := range chunks {
+		kinds = append(kinds, chunk.kind)
+		payload := chunk.data
+
+		/*
+		 * Decide on the kind of chunk.
+		 */
+		switch chunk.kind {
+		case "IHDR":
+
+			/*
+			 * Check the image header.
+			 */
+			if (binary.BigEndian.Uint32(payload[0:4]) != 4) || (binary.BigEndian.Uint32(payload[4:8]) != 3) {
+				t.Errorf("%s", "Image is not 4x3.")
+			} else if (payload[8] != 8) || (payload[9] != 6) {
+				t.Errorf("%s", "Image is not 8-bit RGBA.")
+			}
+
+		case "acTL":
+
+			/*
+			 * Check the animation control.
+			 */
+			if (binary.BigEndian.Uint32(payload[0:4]) != 3) || (binary.BigEndian.Uint32(payload[4:8]) != 2) {
+				t.Errorf("%s", "Animation control does not announce 3 frames played twice.")
+			}
+
+		case "fcTL":
+			frame++
+			frameData = append(frameData, nil)
+
+			/*
+			 * Check the frame control.
+			 */
+			if binary.BigEndian.Uint32(payload[0:4]) != sequence {
+				t.Errorf("Frame control has sequence number %d, expected %d.", binary.BigEndian.Uint32(payload[0:4]), sequence)
+			} else if (binary.BigEndian.Uint16(payload[20:22]) != 250) || (binary.BigEndian.Uint16(payload[22:24]) != 1000) {
+				t.Errorf("%s", "Frame delay is not 250/1000 seconds.")
+			}
+
+			sequence++
+		case "IDAT":
+			frameData[frame] = append(frameData[frame], payload...)
+		case "fdAT":
+
+			/*
+			 * Check the sequence number.
+			 */
+			if binary.BigEndian.Uint32(payload[0:4]) != sequence {
+				t.Errorf("Frame data has sequence number %d, expected %d.", binary.BigEndian.Uint32(payload[0:4]), sequence)
+			}
+
+			sequence++
+			frameData[frame] = append(frameData[frame], payload[4:]...)
+		}
+
+	}
+
+	expectedKinds := "[IHDR acTL fcTL IDAT fcTL fdAT fcTL fdAT IEND]"
+
+	/*
+	 * Check the order of chunks.
+	 */
+	if actual := fmt.Sprint(kinds); actual != expectedKinds {
+		t.Fatalf("Chunks are %s, expected %s.", actual, expectedKinds)
+	}
+
+	/*
+	 * Compare each frame with the rendered slice.
+	 */
+	for i, frameScene := range frames {
+		img, err := frameScene.Render(shared)
+
+		/*
+		 * Check if frame could be rendered.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		pixels := decodePixels(t, frameData[i], 4)
+
+		/*
+		 * Check if pixels match.
+		 */
+		if !bytes.Equal(pixels, img.Pix) {
+			t.Errorf("Frame %d does not match the rendered slice.", i)
+		}
+
+	}
+
+	still, err := png.Decode(bytes.NewReader(data))
+
+	/*
+	 * Viewers without support for animations show the first frame.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if bounds := still.Bounds(); (bounds.Dx() != 4) || (bounds.Dy() != 3) {
+		t.Errorf("Still image is %v, expected 4x3.", bounds)
+	}
+
+}
+
+/*
+ * Encode a temporal scene as GIF and decode it again.
+ */
+func TestEncodeGIF(t *testing.T) {
+	ts := testScene(t)
+	mapping := color.DefaultMapping()
+	background := imagecolor.NRGBA{R: 0, G: 0, B: 0, A: 255}
+
+	/*
+	 * The number of plays and the loop counts stored in the file.
+	 */
+	cases := []struct {
+		plays     int
+		loopCount int
+	}{
+		{plays: 0, loopCount: 0},
+		{plays: 1, loopCount: -1},
+		{plays: 3, loopCount: 2},
+	}
+
+	/*
+	 * Encode the scene using each number of plays.
+	 */
+	for _, c := range cases {
+		buf := bytes.Buffer{}
+		err := EncodeGIF(&buf, ts, mapping, WithBackground(background), WithCumulative(), WithPlays(c.plays))
+
+		/*
+		 * Check if animation could be encoded.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		anim, err := gif.DecodeAll(&buf)
+
+		/*
+		 * Check if animation could be decoded.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		} else if len(anim.Image) != 3 {
+			t.Fatalf("Animation has %d frames, expected 3.", len(anim.Image))
+		} else if anim.LoopCount != c.loopCount {
+			t.Errorf("Loop count for %d plays is %d, expected %d.", c.plays, anim.LoopCount, c.loopCount)
+		}
+
+		config := configure([]Option{WithBackground(background), WithCumulative()})
+		frames, shared, err := prepare(ts, mapping, &config)
+
+		/*
+		 * Check if frames could be prepared.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		/*
+		 * Compare each frame with the rendered window.
+		 */
+		for i, img := range anim.Image {
+			expected, err := frames[i].RenderPaletted(shared)
+
+			/*
+			 * Check if frame could be rendered.
+			 */
+			if err != nil {
+				t.Fatalf("%s", err.Error())
+			} else if anim.Delay[i] != 50 {
+				t.Errorf("Frame %d has delay %d, expected 50.", i, anim.Delay[i])
+			} else if !equalImages(img, expected) {
+				t.Errorf("Frame %d does not match the rendered window.", i)
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Returns whether two images hold the same colors.
+ */
+func equalImages(a image.Image, b image.Image) bool {
+	rect := a.Bounds()
+
+	/*
+	 * Check if dimensions match.
+	 */
+	if rect != b.Bounds() {
+		return false
+	}
+
+	/*
+	 * Compare each row.
+	 */
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+		/*
+		 * Compare each pixel of the row.
+		 */
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			r1, g1, b1, a1 := a.At(x, y).RGBA()
+			r2, g2, b2, a2 := b.At(x, y).RGBA()
+
+			/*
+			 * Check if colors differ.
+			 */
+			if (r1 != r2) || (g1 != g2) || (b1 != b2) || (a1 != a2) {
+				return false
+			}
+
+		}
+
+	}
+
+	return true
+}
+
+/*
+ * Animations require a color mapping.
+ */
+func TestNilMapping(t *testing.T) {
+	ts := testScene(t)
+	buf := bytes.Buffer{}
+	errAPNG := EncodeAPNG(&buf, ts, nil)
+	errGIF := EncodeGIF(&buf, ts, nil)
+
+	/*
+	 * Check if errors were returned.
+	 */
+	if (errAPNG == nil) || (errGIF == nil) {
+		t.Errorf("%s", "Expected an error for a nil mapping.")
+	}
+
+}
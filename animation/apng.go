@@ -0,0 +1,324 @@
+package animation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/scene"
+	"hash/crc32"
+	"image"
+	"io"
+	"math"
+)
+
+/*
+ * Constants describing the APNG format.
+ */
+const (
+	APNG_MAX_CHUNK_SIZE = 1 << 20
+	APNG_SIGNATURE      = "\x89PNG\r\n\x1a\n"
+)
+
+/*
+ * Data structure representing a writer for the chunks of an APNG file.
+ */
+type apngWriterStruct struct {
+	sequence uint32
+	w        io.Writer
+}
+
+/*
+ * Write a chunk with its length and checksum.
+ */
+func (this *apngWriterStruct) chunk(kind string, data []byte) error {
+	header := make([]byte, 8)
+	size := uint32(len(data))
+	binary.BigEndian.PutUint32(header[0:4], size)
+	copy(header[4:8], kind)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:8])
+	crc.Write(data)
+	footer := make([]byte, 4)
+	sum := crc.Sum32()
+	binary.BigEndian.PutUint32(footer, sum)
+	_, err := this.w.Write(header)
+
+	/*
+	 * Write data after header.
+	 */
+	if err == nil {
+		_, err = this.w.Write(data)
+	}
+
+	/*
+	 * Write checksum after data.
+	 */
+	if err == nil {
+		_, err = this.w.Write(footer)
+	}
+
+	return err
+}
+
+/*
+ * Returns the next sequence number of the animation chunks.
+ */
+func (this *apngWriterStruct) next() uint32 {
+	sequence := this.sequence
+	this.sequence++
+	return sequence
+}
+
+/*
+ * Write the frame control chunk preceding the data of a frame.
+ *
+ * Frames cover the entire image and replace the previous frame.
+ */
+func (this *apngWriterStruct) frameControl(width uint32, height uint32, delayNumerator uint16, delayDenominator uint16) error {
+	data := make([]byte, 26)
+	sequence := this.next()
+	binary.BigEndian.PutUint32(data[0:4], sequence)
+	binary.BigEndian.PutUint32(data[4:8], width)
+	binary.BigEndian.PutUint32(data[8:12], height)
+	binary.BigEndian.PutUint16(data[20:22], delayNumerator)
+	binary.BigEndian.PutUint16(data[22:24], delayDenominator)
+	return this.chunk("fcTL", data)
+}
+
+/*
+ * Write the compressed data of a frame, split into chunks of limited size.
+ *
+ * The first frame is stored in IDAT chunks, so that viewers without support
+ * for animations display it as a still image. All other frames are stored in
+ * fdAT chunks, which carry a sequence number.
+ */
+func (this *apngWriterStruct) frameData(data []byte, first bool) error {
+
+	/*
+	 * Write one chunk at a time.
+	 */
+	for len(data) > 0 {
+		size := len(data)
+
+		/*
+		 * Limit size of chunk.
+		 */
+		if size > APNG_MAX_CHUNK_SIZE {
+			size = APNG_MAX_CHUNK_SIZE
+		}
+
+		err := error(nil)
+
+		/*
+		 * Decide on the kind of chunk.
+		 */
+		if first {
+			err = this.chunk("IDAT", data[:size])
+		} else {
+			payload := make([]byte, 4+size)
+			sequence := this.next()
+			binary.BigEndian.PutUint32(payload[0:4], sequence)
+			copy(payload[4:], data[:size])
+			err = this.chunk("fdAT", payload)
+		}
+
+		/*
+		 * Check if chunk could be written.
+		 */
+		if err != nil {
+			return err
+		}
+
+		data = data[size:]
+	}
+
+	return nil
+}
+
+/*
+ * Compress the pixels of an image as 8-bit RGBA scanlines without
+ * filtering.
+ */
+func compressPixels(img *image.NRGBA) ([]byte, error) {
+	buf := bytes.Buffer{}
+	zw := zlib.NewWriter(&buf)
+	rect := img.Bounds()
+	width := rect.Dx()
+	filter := []byte{0}
+	err := error(nil)
+
+	/*
+	 * Write each row, preceded by its filter type.
+	 */
+	for y := rect.Min.Y; (y < rect.Max.Y) && (err == nil); y++ {
+		offset := img.PixOffset(rect.Min.X, y)
+		row := img.Pix[offset : offset+(4*width)]
+		_, err = zw.Write(filter)
+
+		/*
+		 * Write pixels after filter type.
+		 */
+		if err == nil {
+			_, err = zw.Write(row)
+		}
+
+	}
+
+	errClose := zw.Close()
+
+	/*
+	 * Report the first error.
+	 */
+	if err == nil {
+		err = errClose
+	}
+
+	/*
+	 * Check if pixels could be compressed.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		return buf.Bytes(), nil
+	}
+
+}
+
+/*
+ * Render each slice of a temporal scene and encode the frames as an animated
+ * PNG (APNG), writing it to w.
+ *
+ * All frames share a fixed color scale, so that identical counts map to
+ * identical colors in every frame. Unlike GIF, APNG keeps all colors and
+ * partial transparency. Viewers without support for APNG show the first
+ * frame.
+ */
+func EncodeAPNG(w io.Writer, ts scene.TemporalScene, mapping color.Mapping, options ...Option) error {
+	config := configure(options)
+	frames, shared, err := prepare(ts, mapping, &config)
+
+	/*
+	 * Check if frames could be created.
+	 */
+	if err != nil {
+		return err
+	} else {
+		width, height := frames[0].Dimensions()
+		delayNumerator := uint16(0)
+		delayDenominator := uint16(1000)
+		milliseconds := config.delay.Milliseconds()
+
+		/*
+		 * Fall back to seconds for very long delays.
+		 */
+		if milliseconds <= math.MaxUint16 {
+			delayNumerator = uint16(milliseconds)
+		} else {
+			seconds := math.Min(math.Round(config.delay.Seconds()), math.MaxUint16)
+			delayNumerator = uint16(seconds)
+			delayDenominator = 1
+		}
+
+		/*
+		 * A negative delay displays frames as fast as possible.
+		 */
+		if milliseconds < 0 {
+			delayNumerator = 0
+		}
+
+		numFrames := uint32(len(frames))
+		plays := config.plays
+
+		/*
+		 * Treat a negative number of plays like zero.
+		 */
+		if plays < 0 {
+			plays = 0
+		}
+
+		header := make([]byte, 13)
+		binary.BigEndian.PutUint32(header[0:4], width)
+		binary.BigEndian.PutUint32(header[4:8], height)
+		header[8] = 8
+		header[9] = 6
+		control := make([]byte, 8)
+		binary.BigEndian.PutUint32(control[0:4], numFrames)
+		binary.BigEndian.PutUint32(control[4:8], uint32(plays))
+
+		/*
+		 * Create APNG writer.
+		 */
+		aw := apngWriterStruct{
+			w: w,
+		}
+
+		_, err = io.WriteString(w, APNG_SIGNATURE)
+
+		/*
+		 * Write image header after signature.
+		 */
+		if err == nil {
+			err = aw.chunk("IHDR", header)
+		}
+
+		/*
+		 * Write animation control after image header.
+		 */
+		if err == nil {
+			err = aw.chunk("acTL", control)
+		}
+
+		/*
+		 * Render and write each frame.
+		 */
+		for i := 0; (i < len(frames)) && (err == nil); i++ {
+			img, errRender := frames[i].Render(shared)
+			data := []byte(nil)
+
+			/*
+			 * Compress pixels of rendered frame.
+			 */
+			if errRender != nil {
+				err = errRender
+			} else {
+				data, err = compressPixels(img)
+			}
+
+			/*
+			 * Write frame control before frame data.
+			 */
+			if err == nil {
+				err = aw.frameControl(width, height, delayNumerator, delayDenominator)
+			}
+
+			/*
+			 * Write frame data.
+			 */
+			if err == nil {
+				first := i == 0
+				err = aw.frameData(data, first)
+			}
+
+		}
+
+		/*
+		 * Write end of image after all frames.
+		 */
+		if err == nil {
+			err = aw.chunk("IEND", nil)
+		}
+
+		/*
+		 * Check if animation could be written.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to write APNG: %s", err.Error())
+		} else {
+			return nil
+		}
+
+	}
+
+}
@@ -0,0 +1,181 @@
+package animation
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/scene"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+/*
+ * Render each slice of a temporal scene into a series of video frames and
+ * pass each frame, encoded as PNG, to a function along with its number.
+ *
+ * Each slice is repeated for as many frames as needed to display it for the
+ * configured delay at the configured frame rate. Without overlay, the frames
+ * of a slice are encoded only once. With overlay, each frame is drawn onto a
+ * copy of the rendered slice, where the point in time advances linearly
+ * across the slice, so that e. g. a clock drawn by the overlay runs smoothly.
+ */
+func writeFrames(ts scene.TemporalScene, mapping color.Mapping, config *configStruct, write func(frame int, data []byte) error) error {
+	frameRate := config.frameRate
+
+	/*
+	 * Verify that frame rate is positive.
+	 */
+	if !(frameRate > 0.0) || math.IsInf(frameRate, 1) {
+		return fmt.Errorf("Frame rate must be positive and finite, but is %f.", frameRate)
+	} else {
+		frames, shared, err := prepare(ts, mapping, config)
+
+		/*
+		 * Check if frames could be created.
+		 */
+		if err != nil {
+			return err
+		} else {
+			boundaries := ts.Boundaries()
+			repetitions := int(math.Round(config.delay.Seconds() * frameRate))
+
+			/*
+			 * Each slice is shown in at least one frame.
+			 */
+			if repetitions < 1 {
+				repetitions = 1
+			}
+
+			overlay := config.overlay
+			enc := png.Encoder{}
+			buf := bytes.Buffer{}
+			number := 0
+
+			/*
+			 * Render and write the frames of each slice.
+			 */
+			for i, frame := range frames {
+				img, err := frame.Render(shared)
+
+				/*
+				 * Check if slice could be rendered.
+				 */
+				if err != nil {
+					return err
+				}
+
+				start := boundaries[i]
+				duration := boundaries[i+1].Sub(start)
+				encoded := []byte(nil)
+
+				/*
+				 * Write each repetition of the slice.
+				 */
+				for j := 0; j < repetitions; j++ {
+
+					/*
+					 * Decide whether the frame must be encoded.
+					 */
+					if overlay != nil {
+						rect := img.Bounds()
+						canvas := image.NewNRGBA(rect)
+						copy(canvas.Pix, img.Pix)
+						progress := float64(j) / float64(repetitions)
+						offset := time.Duration(progress * float64(duration))
+						t := start.Add(offset)
+						err = overlay(canvas, number, t)
+
+						/*
+						 * Check if overlay could be drawn.
+						 */
+						if err != nil {
+							return err
+						}
+
+						buf.Reset()
+						err = enc.Encode(&buf, canvas)
+						encoded = buf.Bytes()
+					} else if encoded == nil {
+						buf.Reset()
+						err = enc.Encode(&buf, img)
+						encoded = buf.Bytes()
+					}
+
+					/*
+					 * Check if frame could be encoded.
+					 */
+					if err != nil {
+						return err
+					}
+
+					err = write(number, encoded)
+
+					/*
+					 * Check if frame could be written.
+					 */
+					if err != nil {
+						return err
+					}
+
+					number++
+				}
+
+			}
+
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Render each slice of a temporal scene into a series of numbered PNG
+ * files, e. g. to encode them into a video using ffmpeg.
+ *
+ * The path of each frame is created by formatting its number, starting at
+ * zero, using a pattern like "frames/frame%05d.png", which can be passed to
+ * ffmpeg as it is. Frames are written at the configured frame rate, which
+ * must also be passed to ffmpeg using its "-framerate" option. All frames
+ * share a fixed color scale.
+ */
+func WriteFrames(pattern string, ts scene.TemporalScene, mapping color.Mapping, options ...Option) error {
+	config := configure(options)
+
+	/*
+	 * Write each frame into a file.
+	 */
+	write := func(frame int, data []byte) error {
+		path := fmt.Sprintf(pattern, frame)
+		return ioutil.WriteFile(path, data, 0644)
+	}
+
+	return writeFrames(ts, mapping, &config, write)
+}
+
+/*
+ * Render each slice of a temporal scene into a series of PNG images, which
+ * are written to w one after another, e. g. into the standard input of
+ * ffmpeg reading them using "-f image2pipe".
+ *
+ * Frames are written at the configured frame rate, which must also be
+ * passed to ffmpeg using its "-framerate" option. All frames share a fixed
+ * color scale.
+ */
+func StreamFrames(w io.Writer, ts scene.TemporalScene, mapping color.Mapping, options ...Option) error {
+	config := configure(options)
+
+	/*
+	 * Write each frame to the stream.
+	 */
+	write := func(frame int, data []byte) error {
+		_, err := w.Write(data)
+		return err
+	}
+
+	return writeFrames(ts, mapping, &config, write)
+}
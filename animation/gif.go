@@ -0,0 +1,81 @@
+package animation
+
+import (
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/scene"
+	"image"
+	"image/gif"
+	"io"
+	"math"
+)
+
+/*
+ * Render each slice of a temporal scene and encode the frames as an animated
+ * GIF, writing it to w.
+ *
+ * All frames share a fixed color scale, so that identical counts map to
+ * identical colors in every frame. Each frame is reduced to a palette of at
+ * most 256 colors.
+ */
+func EncodeGIF(w io.Writer, ts scene.TemporalScene, mapping color.Mapping, options ...Option) error {
+	config := configure(options)
+	frames, shared, err := prepare(ts, mapping, &config)
+
+	/*
+	 * Check if frames could be created.
+	 */
+	if err != nil {
+		return err
+	} else {
+		numFrames := len(frames)
+		images := make([]*image.Paletted, numFrames)
+		delays := make([]int, numFrames)
+		disposals := make([]byte, numFrames)
+		delay := int(math.Round(config.delay.Seconds() * 100.0))
+
+		/*
+		 * Render each frame.
+		 */
+		for i, frame := range frames {
+			img, err := frame.RenderPaletted(shared)
+
+			/*
+			 * Check if frame could be rendered.
+			 */
+			if err != nil {
+				return err
+			}
+
+			images[i] = img
+			delays[i] = delay
+			disposals[i] = gif.DisposalBackground
+		}
+
+		plays := config.plays
+		loopCount := 0
+
+		/*
+		 * GIF counts the number of repetitions, where -1 shows each
+		 * frame only once.
+		 */
+		if plays == 1 {
+			loopCount = -1
+		} else if plays > 1 {
+			loopCount = plays - 1
+		}
+
+		/*
+		 * Create animation.
+		 */
+		anim := gif.GIF{
+			Image:     images,
+			Delay:     delays,
+			LoopCount: loopCount,
+			Disposal:  disposals,
+		}
+
+		err = gif.EncodeAll(w, &anim)
+		return err
+	}
+
+}
@@ -0,0 +1,1030 @@
+package arrow
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"math"
+)
+
+/*
+ * Numeric types of arrays.
+ */
+const (
+	TYPE_INT8    = 0
+	TYPE_INT16   = 1
+	TYPE_INT32   = 2
+	TYPE_INT64   = 3
+	TYPE_UINT8   = 4
+	TYPE_UINT16  = 5
+	TYPE_UINT32  = 6
+	TYPE_UINT64  = 7
+	TYPE_FLOAT32 = 8
+	TYPE_FLOAT64 = 9
+)
+
+/*
+ * Types of message headers.
+ */
+const (
+	MESSAGE_SCHEMA           = 1
+	MESSAGE_DICTIONARY_BATCH = 2
+	MESSAGE_RECORD_BATCH     = 3
+)
+
+/*
+ * Types of fields in the schema.
+ */
+const (
+	FIELD_NULL              = 1
+	FIELD_INT               = 2
+	FIELD_FLOATING_POINT    = 3
+	FIELD_BINARY            = 4
+	FIELD_UTF8              = 5
+	FIELD_BOOL              = 6
+	FIELD_DECIMAL           = 7
+	FIELD_DATE              = 8
+	FIELD_TIME              = 9
+	FIELD_TIMESTAMP         = 10
+	FIELD_INTERVAL          = 11
+	FIELD_LIST              = 12
+	FIELD_STRUCT            = 13
+	FIELD_FIXED_SIZE_BINARY = 15
+	FIELD_FIXED_SIZE_LIST   = 16
+	FIELD_MAP               = 17
+	FIELD_DURATION          = 18
+	FIELD_LARGE_BINARY      = 19
+	FIELD_LARGE_UTF8        = 20
+	FIELD_LARGE_LIST        = 21
+)
+
+/*
+ * Markers of the IPC format.
+ */
+const (
+	CONTINUATION_MARKER = 0xffffffff
+	FILE_MAGIC          = "ARROW1"
+)
+
+/*
+ * Limits protecting against corrupt streams.
+ */
+const (
+	MAX_METADATA_SIZE = 64 * 1024 * 1024
+	MAX_BODY_SIZE     = 2 * 1024 * 1024 * 1024
+)
+
+/*
+ * The number of points aggregated at once.
+ */
+const (
+	BATCH_SIZE = 65536
+)
+
+/*
+ * Size in bytes of the values of each numeric type.
+ */
+var typeSizes = [...]int{1, 2, 4, 8, 1, 2, 4, 8, 4, 8}
+
+/*
+ * Data structure representing a numeric Arrow array.
+ *
+ * The array refers to its buffers without copying them. Arrays are
+ * immutable.
+ */
+type Array struct {
+	arrayType int
+	length    int
+	validity  []byte
+	values    []byte
+}
+
+/*
+ * Returns the value at an index as a floating-point number or NaN if the
+ * value is null.
+ */
+func (this *Array) Float(idx int) float64 {
+
+	/*
+	 * Null values are NaN.
+	 */
+	if !this.IsValid(idx) {
+		return math.NaN()
+	} else {
+		values := this.values
+
+		/*
+		 * Decide on the type.
+		 */
+		switch this.arrayType {
+		case TYPE_INT8:
+			return float64(int8(values[idx]))
+		case TYPE_INT16:
+			return float64(int16(binary.LittleEndian.Uint16(values[2*idx:])))
+		case TYPE_INT32:
+			return float64(int32(binary.LittleEndian.Uint32(values[4*idx:])))
+		case TYPE_INT64:
+			return float64(int64(binary.LittleEndian.Uint64(values[8*idx:])))
+		case TYPE_UINT8:
+			return float64(values[idx])
+		case TYPE_UINT16:
+			return float64(binary.LittleEndian.Uint16(values[2*idx:]))
+		case TYPE_UINT32:
+			return float64(binary.LittleEndian.Uint32(values[4*idx:]))
+		case TYPE_UINT64:
+			return float64(binary.LittleEndian.Uint64(values[8*idx:]))
+		case TYPE_FLOAT32:
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(values[4*idx:])))
+		default:
+			return math.Float64frombits(binary.LittleEndian.Uint64(values[8*idx:]))
+		}
+
+	}
+
+}
+
+/*
+ * Returns whether the value at an index is not null.
+ */
+func (this *Array) IsValid(idx int) bool {
+	validity := this.validity
+
+	/*
+	 * Without validity bitmap, all values are valid.
+	 */
+	if validity == nil {
+		return true
+	} else {
+		return (validity[idx/8] & (1 << uint(idx%8))) != 0
+	}
+
+}
+
+/*
+ * Returns the number of values in this array.
+ */
+func (this *Array) Len() int {
+	return this.length
+}
+
+/*
+ * Returns the type of this array, e. g. TYPE_FLOAT64.
+ */
+func (this *Array) Type() int {
+	return this.arrayType
+}
+
+/*
+ * Create an array from existing buffers in Arrow's memory layout, e. g. from
+ * another Arrow implementation, without copying them.
+ *
+ * The values are stored in little-endian order. The validity bitmap holds a
+ * bit per value, which is set if the value is not null, and may be nil if
+ * all values are valid.
+ */
+func CreateArray(arrayType int, length int, validity []byte, values []byte) (Array, error) {
+
+	/*
+	 * Check if type and buffers are valid.
+	 */
+	if (arrayType < 0) || (arrayType >= len(typeSizes)) {
+		return Array{}, fmt.Errorf("Unsupported array type: %d", arrayType)
+	} else if length < 0 {
+		return Array{}, fmt.Errorf("%s", "Length must not be negative.")
+	} else if len(values) < length*typeSizes[arrayType] {
+		return Array{}, fmt.Errorf("Values buffer too short: %d bytes for %d values", len(values), length)
+	} else if (validity != nil) && (len(validity) < (length+7)/8) {
+		return Array{}, fmt.Errorf("Validity bitmap too short: %d bytes for %d values", len(validity), length)
+	} else {
+
+		/*
+		 * Create array.
+		 */
+		array := Array{
+			arrayType: arrayType,
+			length:    length,
+			validity:  validity,
+			values:    values,
+		}
+
+		return array, nil
+	}
+
+}
+
+/*
+ * Aggregate points, whose coordinates are stored in two arrays, into a
+ * scene, projecting them first if a projection is given. Points with null
+ * coordinates are skipped.
+ *
+ * Without projection, the arrays hold x- and y-coordinates, otherwise they
+ * hold longitudes and latitudes in degrees.
+ */
+func AggregateArrays(scn scene.Scene, x *Array, y *Array, proj projection.Projection) error {
+
+	/*
+	 * Check if arrays match.
+	 */
+	if x.length != y.length {
+		return fmt.Errorf("Arrays differ in length: %d and %d", x.length, y.length)
+	} else {
+		batch := make([]coordinates.Cartesian, 0, BATCH_SIZE)
+		locations := []coordinates.Geographic{}
+
+		/*
+		 * Process points in batches.
+		 */
+		for start := 0; start < x.length; start += BATCH_SIZE {
+			end := start + BATCH_SIZE
+
+			/*
+			 * The last batch may be smaller.
+			 */
+			if end > x.length {
+				end = x.length
+			}
+
+			batch = batch[:0]
+			locations = locations[:0]
+
+			/*
+			 * Collect points with valid coordinates.
+			 */
+			for i := start; i < end; i++ {
+
+				/*
+				 * Skip null coordinates.
+				 */
+				if x.IsValid(i) && y.IsValid(i) {
+					xValue := x.Float(i)
+					yValue := y.Float(i)
+
+					/*
+					 * Collect locations if they need to be projected.
+					 */
+					if proj != nil {
+						location := coordinates.CreateGeographicDegrees(xValue, yValue)
+						locations = append(locations, location)
+					} else {
+						point := coordinates.CreateCartesian(xValue, yValue)
+						batch = append(batch, point)
+					}
+
+				}
+
+			}
+
+			/*
+			 * Project locations if needed.
+			 */
+			if proj != nil {
+				batch = batch[:len(locations)]
+				err := proj.Forward(batch, locations)
+
+				/*
+				 * Points which could not be projected are NaN and are not
+				 * aggregated, so only other errors are fatal.
+				 */
+				if _, ok := err.(projection.BatchError); (err != nil) && !ok {
+					return err
+				}
+
+			}
+
+			scn.Aggregate(batch)
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Data structure representing a record batch, i. e. a number of rows stored
+ * as a set of columns.
+ *
+ * Batches are immutable.
+ */
+type Batch struct {
+	columns map[string]Array
+	length  int
+}
+
+/*
+ * Returns the column with a certain name and whether it exists.
+ *
+ * Only numeric columns are available. Fields of structs are named by their
+ * path, joined by dots, e. g. "position.lon".
+ */
+func (this *Batch) Column(name string) (Array, bool) {
+	array, ok := this.columns[name]
+	return array, ok
+}
+
+/*
+ * Returns the number of rows in this batch.
+ */
+func (this *Batch) Len() int {
+	return this.length
+}
+
+/*
+ * Data structure representing the configuration of a reader.
+ */
+type configStruct struct {
+	projection projection.Projection
+	x          string
+	y          string
+}
+
+/*
+ * A configuration option for a reader.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Select the columns holding the x- and y-coordinates (or longitude and
+ * latitude) by name.
+ */
+func WithCoordinates(x string, y string) Option {
+
+	/*
+	 * Set coordinate columns.
+	 */
+	return func(config *configStruct) {
+		config.x = x
+		config.y = y
+	}
+
+}
+
+/*
+ * Project longitudes and latitudes (in degrees) when aggregating batches
+ * into a scene.
+ */
+func WithProjection(proj projection.Projection) Option {
+
+	/*
+	 * Set projection.
+	 */
+	return func(config *configStruct) {
+		config.projection = proj
+	}
+
+}
+
+/*
+ * A reader reads record batches from an Arrow IPC stream or file.
+ */
+type Reader interface {
+	Aggregate(scn scene.Scene) error
+	Columns() []string
+	Read() (Batch, error)
+}
+
+/*
+ * Data structure representing a field of the schema, flattened in
+ * depth-first order.
+ */
+type fieldStruct struct {
+	arrayType  int
+	exposed    bool
+	name       string
+	numBuffers int
+}
+
+/*
+ * Data structure representing a reader.
+ */
+type readerStruct struct {
+	config configStruct
+	done   bool
+	fields []fieldStruct
+	r      *bufio.Reader
+}
+
+/*
+ * Data structure representing a message of the IPC format.
+ */
+type messageStruct struct {
+	body       []byte
+	header     tableStruct
+	headerType uint8
+}
+
+/*
+ * Determine the numeric type of a field or -1 if the field is not numeric.
+ */
+func numericType(typeType uint8, typeTable *tableStruct) int {
+
+	/*
+	 * Decide on the type of field.
+	 */
+	switch typeType {
+	case FIELD_INT:
+		bitWidth := typeTable.int32(0, 0)
+		signed := typeTable.uint8(1, 0) != 0
+		base := TYPE_UINT8
+
+		/*
+		 * Signed types precede unsigned ones.
+		 */
+		if signed {
+			base = TYPE_INT8
+		}
+
+		/*
+		 * Decide on the bit width.
+		 */
+		switch bitWidth {
+		case 8:
+			return base
+		case 16:
+			return base + 1
+		case 32:
+			return base + 2
+		case 64:
+			return base + 3
+		default:
+			return -1
+		}
+
+	case FIELD_FLOATING_POINT:
+		precision := typeTable.int16(0, 0)
+
+		/*
+		 * Half precision is not supported.
+		 */
+		switch precision {
+		case 1:
+			return TYPE_FLOAT32
+		case 2:
+			return TYPE_FLOAT64
+		default:
+			return -1
+		}
+
+	default:
+		return -1
+	}
+
+}
+
+/*
+ * Flatten a field and its children in depth-first order, which is the order
+ * in which their nodes and buffers appear in record batches.
+ *
+ * Only numeric fields which are not nested within lists or maps are exposed.
+ */
+func flattenField(field *tableStruct, prefix string, exposed bool, fields []fieldStruct) ([]fieldStruct, error) {
+	name, err := field.string(0)
+
+	/*
+	 * Check if name could be read.
+	 */
+	if err != nil {
+		return nil, err
+	}
+
+	/*
+	 * Fields of structs are named by their path.
+	 */
+	if prefix != "" {
+		name = prefix + "." + name
+	}
+
+	typeType := field.uint8(2, 0)
+	typeTable, _, err := field.table(3)
+
+	/*
+	 * Check if type could be read.
+	 */
+	if err != nil {
+		return nil, err
+	}
+
+	numBuffers := 0
+	childrenExposed := false
+
+	/*
+	 * Decide on the number of buffers.
+	 */
+	switch typeType {
+	case FIELD_NULL:
+		numBuffers = 0
+	case FIELD_INT, FIELD_FLOATING_POINT, FIELD_BOOL, FIELD_DECIMAL, FIELD_DATE, FIELD_TIME, FIELD_TIMESTAMP, FIELD_INTERVAL, FIELD_FIXED_SIZE_BINARY, FIELD_DURATION:
+		numBuffers = 2
+	case FIELD_BINARY, FIELD_UTF8, FIELD_LARGE_BINARY, FIELD_LARGE_UTF8:
+		numBuffers = 3
+	case FIELD_LIST, FIELD_LARGE_LIST, FIELD_MAP:
+		numBuffers = 2
+	case FIELD_STRUCT:
+		numBuffers = 1
+		childrenExposed = exposed
+	case FIELD_FIXED_SIZE_LIST:
+		numBuffers = 1
+	default:
+		return nil, fmt.Errorf("Unsupported type of field '%s': %d", name, typeType)
+	}
+
+	arrayType := numericType(typeType, &typeTable)
+	_, dictionary, err := field.table(4)
+
+	/*
+	 * Check if dictionary could be read.
+	 */
+	if err != nil {
+		return nil, err
+	}
+
+	/*
+	 * Create field.
+	 */
+	flattened := fieldStruct{
+		arrayType:  arrayType,
+		exposed:    exposed && !dictionary && (arrayType >= 0),
+		name:       name,
+		numBuffers: numBuffers,
+	}
+
+	fields = append(fields, flattened)
+	children, err := field.tables(5)
+
+	/*
+	 * Check if children could be read.
+	 */
+	if err != nil {
+		return nil, err
+	}
+
+	/*
+	 * Flatten each child.
+	 */
+	for i := range children {
+		fields, err = flattenField(&children[i], name, childrenExposed, fields)
+
+		/*
+		 * Check if child could be flattened.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	return fields, nil
+}
+
+/*
+ * Read the next message.
+ *
+ * Returns io.EOF at the end of the stream.
+ */
+func (this *readerStruct) readMessage() (messageStruct, error) {
+
+	/*
+	 * In files, the footer follows the end of the stream.
+	 */
+	if this.done {
+		return messageStruct{}, io.EOF
+	}
+
+	prefix := make([]byte, 4)
+	_, err := io.ReadFull(this.r, prefix)
+
+	/*
+	 * Check if the stream ends.
+	 */
+	if err != nil {
+		return messageStruct{}, err
+	}
+
+	length := binary.LittleEndian.Uint32(prefix)
+
+	/*
+	 * Messages written by older versions lack the continuation marker.
+	 */
+	if length == CONTINUATION_MARKER {
+		_, err = io.ReadFull(this.r, prefix)
+
+		/*
+		 * Check if length could be read.
+		 */
+		if err != nil {
+			return messageStruct{}, err
+		}
+
+		length = binary.LittleEndian.Uint32(prefix)
+	}
+
+	/*
+	 * A length of zero marks the end of the stream.
+	 */
+	if length == 0 {
+		this.done = true
+		return messageStruct{}, io.EOF
+	} else if length > MAX_METADATA_SIZE {
+		return messageStruct{}, fmt.Errorf("Message metadata too large: %d bytes", length)
+	}
+
+	metadata := make([]byte, length)
+	_, err = io.ReadFull(this.r, metadata)
+
+	/*
+	 * Check if metadata could be read.
+	 */
+	if err != nil {
+		return messageStruct{}, fmt.Errorf("Failed to read message metadata: %s", err.Error())
+	}
+
+	message, err := rootTable(metadata)
+
+	/*
+	 * Check if message could be decoded.
+	 */
+	if err != nil {
+		return messageStruct{}, fmt.Errorf("Failed to decode message: %s", err.Error())
+	}
+
+	header, ok, err := message.table(2)
+	bodyLength := message.int64(3, 0)
+
+	/*
+	 * Check if header and body are valid.
+	 */
+	if err != nil {
+		return messageStruct{}, fmt.Errorf("Failed to decode message header: %s", err.Error())
+	} else if !ok {
+		return messageStruct{}, fmt.Errorf("%s", "Message without header.")
+	} else if (bodyLength < 0) || (bodyLength > MAX_BODY_SIZE) {
+		return messageStruct{}, fmt.Errorf("Invalid message body length: %d bytes", bodyLength)
+	}
+
+	body := make([]byte, bodyLength)
+	_, err = io.ReadFull(this.r, body)
+
+	/*
+	 * Check if body could be read.
+	 */
+	if err != nil {
+		return messageStruct{}, fmt.Errorf("Failed to read message body: %s", err.Error())
+	}
+
+	/*
+	 * Create message.
+	 */
+	result := messageStruct{
+		body:       body,
+		header:     header,
+		headerType: message.uint8(1, 0),
+	}
+
+	return result, nil
+}
+
+/*
+ * Decode a schema.
+ */
+func (this *readerStruct) decodeSchema(schema *tableStruct) error {
+
+	/*
+	 * Only little-endian data is supported.
+	 */
+	if schema.int16(0, 0) != 0 {
+		return fmt.Errorf("%s", "Big-endian data is not supported.")
+	}
+
+	fields, err := schema.tables(1)
+
+	/*
+	 * Check if fields could be read.
+	 */
+	if err != nil {
+		return err
+	}
+
+	flattened := []fieldStruct{}
+
+	/*
+	 * Flatten each field.
+	 */
+	for i := range fields {
+		flattened, err = flattenField(&fields[i], "", true, flattened)
+
+		/*
+		 * Check if field could be flattened.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+	this.fields = flattened
+	return nil
+}
+
+/*
+ * Decode a record batch, referring to the buffers within the body of its
+ * message.
+ */
+func (this *readerStruct) decodeRecordBatch(message *messageStruct) (Batch, error) {
+	header := &message.header
+	body := message.body
+	length := header.int64(0, 0)
+	nodesStart, numNodes, errNodes := header.vector(1, 16)
+	buffersStart, numBuffers, errBuffers := header.vector(2, 16)
+	_, compressed, errCompression := header.table(3)
+
+	/*
+	 * Check if record batch is valid.
+	 */
+	if errNodes != nil {
+		return Batch{}, errNodes
+	} else if errBuffers != nil {
+		return Batch{}, errBuffers
+	} else if errCompression != nil {
+		return Batch{}, errCompression
+	} else if compressed {
+		return Batch{}, fmt.Errorf("%s", "Compressed record batches are not supported.")
+	} else if numNodes != len(this.fields) {
+		return Batch{}, fmt.Errorf("Record batch holds %d nodes for %d fields.", numNodes, len(this.fields))
+	}
+
+	buf := header.buf
+	columns := make(map[string]Array)
+	bufferIdx := 0
+
+	/*
+	 * Locate a buffer within the body.
+	 */
+	locate := func(idx int) ([]byte, error) {
+
+		/*
+		 * Check if buffer exists.
+		 */
+		if idx >= numBuffers {
+			return nil, fmt.Errorf("%s", "Record batch holds too few buffers.")
+		} else {
+			pos := buffersStart + (16 * idx)
+			offset := int64(binary.LittleEndian.Uint64(buf[pos:]))
+			size := int64(binary.LittleEndian.Uint64(buf[pos+8:]))
+
+			/*
+			 * Check if buffer lies within the body.
+			 */
+			if (offset < 0) || (size < 0) || (offset+size > int64(len(body))) {
+				return nil, fmt.Errorf("%s", "Buffer exceeds message body.")
+			} else {
+				return body[offset : offset+size], nil
+			}
+
+		}
+
+	}
+
+	/*
+	 * Create an array for each exposed field.
+	 */
+	for i := range this.fields {
+		field := &this.fields[i]
+
+		/*
+		 * Only numeric fields are exposed.
+		 */
+		if field.exposed {
+			pos := nodesStart + (16 * i)
+			nodeLength := int64(binary.LittleEndian.Uint64(buf[pos:]))
+			nullCount := int64(binary.LittleEndian.Uint64(buf[pos+8:]))
+			validity, errValidity := locate(bufferIdx)
+			values, errValues := locate(bufferIdx + 1)
+
+			/*
+			 * Check if buffers could be located.
+			 */
+			if errValidity != nil {
+				return Batch{}, errValidity
+			} else if errValues != nil {
+				return Batch{}, errValues
+			} else if (nodeLength < 0) || (nodeLength > int64(len(body))*8) {
+				return Batch{}, fmt.Errorf("Invalid length of field '%s': %d", field.name, nodeLength)
+			}
+
+			/*
+			 * Arrays without nulls may omit the validity bitmap.
+			 */
+			if nullCount == 0 {
+				validity = nil
+			}
+
+			array, err := CreateArray(field.arrayType, int(nodeLength), validity, values)
+
+			/*
+			 * Check if array could be created.
+			 */
+			if err != nil {
+				return Batch{}, fmt.Errorf("Invalid field '%s': %s", field.name, err.Error())
+			}
+
+			columns[field.name] = array
+		}
+
+		bufferIdx += field.numBuffers
+	}
+
+	/*
+	 * Create batch.
+	 */
+	batch := Batch{
+		columns: columns,
+		length:  int(length),
+	}
+
+	return batch, nil
+}
+
+/*
+ * Returns the names of all numeric columns.
+ */
+func (this *readerStruct) Columns() []string {
+	names := []string{}
+
+	/*
+	 * Collect names of exposed fields.
+	 */
+	for _, field := range this.fields {
+
+		/*
+		 * Only numeric fields are exposed.
+		 */
+		if field.exposed {
+			names = append(names, field.name)
+		}
+
+	}
+
+	return names
+}
+
+/*
+ * Read the next record batch.
+ *
+ * The columns of the batch refer to the body of the message it was read
+ * from, without copying. Dictionary batches are skipped. Returns io.EOF at
+ * the end of the stream.
+ */
+func (this *readerStruct) Read() (Batch, error) {
+
+	/*
+	 * Read messages until a record batch arrives.
+	 */
+	for {
+		message, err := this.readMessage()
+
+		/*
+		 * Check if message could be read.
+		 */
+		if err != nil {
+			return Batch{}, err
+		} else if message.headerType == MESSAGE_RECORD_BATCH {
+			return this.decodeRecordBatch(&message)
+		}
+
+	}
+
+}
+
+/*
+ * Read all remaining record batches and aggregate them into a scene,
+ * projecting them first if a projection is configured.
+ */
+func (this *readerStruct) Aggregate(scn scene.Scene) error {
+	config := &this.config
+
+	/*
+	 * Read all batches.
+	 */
+	for {
+		batch, err := this.Read()
+
+		/*
+		 * Stop at the end of the stream.
+		 */
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		x, okX := batch.Column(config.x)
+		y, okY := batch.Column(config.y)
+
+		/*
+		 * Check if coordinates are present.
+		 */
+		if !okX {
+			return fmt.Errorf("No such numeric column: '%s'", config.x)
+		} else if !okY {
+			return fmt.Errorf("No such numeric column: '%s'", config.y)
+		}
+
+		err = AggregateArrays(scn, &x, &y, config.projection)
+
+		/*
+		 * Check if batch could be aggregated.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+}
+
+/*
+ * Create a reader for an Arrow IPC stream or file (also known as Feather
+ * version 2), reading the schema from its first message.
+ *
+ * By default, the columns named "x" and "y" hold the coordinates. Only
+ * uncompressed, little-endian data is supported.
+ */
+func Create(r io.Reader, options ...Option) (Reader, error) {
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		x: "x",
+		y: "y",
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	rd := bufio.NewReader(decompress.Auto(r))
+	magic, err := rd.Peek(len(FILE_MAGIC))
+
+	/*
+	 * Files start with a magic number padded to eight bytes, followed by a
+	 * stream.
+	 */
+	if (err == nil) && (string(magic) == FILE_MAGIC) {
+		_, err = rd.Discard(8)
+
+		/*
+		 * Check if magic number could be skipped.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	/*
+	 * Create reader data structure.
+	 */
+	reader := readerStruct{
+		config: config,
+		r:      rd,
+	}
+
+	message, err := reader.readMessage()
+
+	/*
+	 * The first message holds the schema.
+	 */
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read schema: %s", err.Error())
+	} else if message.headerType != MESSAGE_SCHEMA {
+		return nil, fmt.Errorf("%s", "Stream does not start with a schema.")
+	} else {
+		err = reader.decodeSchema(&message.header)
+
+		/*
+		 * Check if schema could be decoded.
+		 */
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode schema: %s", err.Error())
+		} else {
+			return &reader, nil
+		}
+
+	}
+
+}
@@ -0,0 +1,690 @@
+package arrow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"math"
+	"testing"
+)
+
+/*
+ * Data structure representing a field of a FlatBuffers table to be encoded.
+ *
+ * Exactly one of scalar, str, structs, table and tables is set.
+ */
+type fbFieldStruct struct {
+	count   int
+	scalar  []byte
+	str     *string
+	structs []byte
+	table   *fbTableStruct
+	tables  []*fbTableStruct
+}
+
+/*
+ * Data structure representing a FlatBuffers table to be encoded.
+ *
+ * Fields are indexed by their position, absent fields are nil. Vectors of
+ * structs hold count elements.
+ */
+type fbTableStruct struct {
+	fields []*fbFieldStruct
+}
+
+/*
+ * Data structure representing an offset to be patched once the object it
+ * refers to is encoded.
+ */
+type fbPatchStruct struct {
+	field *fbFieldStruct
+	pos   int
+}
+
+/*
+ * Returns a scalar field holding a little-endian value.
+ */
+func fbScalar(value interface{}) *fbFieldStruct {
+	buf := bytes.Buffer{}
+	binary.Write(&buf, binary.LittleEndian, value)
+	return &fbFieldStruct{scalar: buf.Bytes()}
+}
+
+/*
+ * Returns a field referring to a string.
+ */
+func fbString(value string) *fbFieldStruct {
+	return &fbFieldStruct{str: &value}
+}
+
+/*
+ * Returns a field referring to a table.
+ */
+func fbTable(fields ...*fbFieldStruct) *fbFieldStruct {
+	return &fbFieldStruct{table: &fbTableStruct{fields: fields}}
+}
+
+/*
+ * Returns a field referring to a vector of tables.
+ */
+func fbTables(tables ...*fbFieldStruct) *fbFieldStruct {
+	field := fbFieldStruct{tables: []*fbTableStruct{}}
+
+	/*
+	 * Collect the table of each field.
+	 */
+	for _, table := range tables {
+		field.tables = append(field.tables, table.table)
+	}
+
+	return &field
+}
+
+/*
+ * Returns a field referring to a vector of structs, which are stored inline.
+ */
+func fbStructs(count int, values ...interface{}) *fbFieldStruct {
+	buf := bytes.Buffer{}
+
+	/*
+	 * Encode each value.
+	 */
+	for _, value := range values {
+		binary.Write(&buf, binary.LittleEndian, value)
+	}
+
+	return &fbFieldStruct{structs: buf.Bytes(), count: count}
+}
+
+/*
+ * Append a 32-bit value to a buffer.
+ */
+func putUint32(buf *bytes.Buffer, value uint32) {
+	binary.Write(buf, binary.LittleEndian, value)
+}
+
+/*
+ * Encode a table, followed by the objects it refers to, returning the
+ * position of the table.
+ */
+func (this *fbTableStruct) encode(buf *bytes.Buffer) int {
+	numFields := len(this.fields)
+	size := 4
+	offsets := make([]int, numFields)
+
+	/*
+	 * Lay out the fields of the table.
+	 */
+	for i, field := range this.fields {
+
+		/*
+		 * Absent fields occupy no space.
+		 */
+		if field != nil {
+			offsets[i] = size
+
+			/*
+			 * Scalars are stored inline, other fields are offsets.
+			 */
+			if field.scalar != nil {
+				size += len(field.scalar)
+			} else {
+				size += 4
+			}
+
+		}
+
+	}
+
+	vtable := buf.Len()
+	binary.Write(buf, binary.LittleEndian, uint16(4+(2*numFields)))
+	binary.Write(buf, binary.LittleEndian, uint16(size))
+
+	/*
+	 * Encode the offset of each field.
+	 */
+	for _, offset := range offsets {
+		binary.Write(buf, binary.LittleEndian, uint16(offset))
+	}
+
+	pos := buf.Len()
+	binary.Write(buf, binary.LittleEndian, int32(pos-vtable))
+	patches := []fbPatchStruct{}
+
+	/*
+	 * Encode each field.
+	 */
+	for _, field := range this.fields {
+
+		/*
+		 * Only encode present fields.
+		 */
+		if field != nil {
+
+			/*
+			 * Offsets are patched once their target is encoded.
+			 */
+			if field.scalar != nil {
+				buf.Write(field.scalar)
+			} else {
+				patches = append(patches, fbPatchStruct{field: field, pos: buf.Len()})
+				putUint32(buf, 0)
+			}
+
+		}
+
+	}
+
+	/*
+	 * Encode the objects the table refers to.
+	 */
+	for _, patch := range patches {
+		target := buf.Len()
+		binary.LittleEndian.PutUint32(buf.Bytes()[patch.pos:], uint32(target-patch.pos))
+		field := patch.field
+
+		/*
+		 * Decide on the type of object.
+		 */
+		if field.str != nil {
+			putUint32(buf, uint32(len(*field.str)))
+			buf.WriteString(*field.str)
+			buf.WriteByte(0)
+		} else if field.table != nil {
+			target = field.table.encode(buf)
+			binary.LittleEndian.PutUint32(buf.Bytes()[patch.pos:], uint32(target-patch.pos))
+		} else if field.tables != nil {
+			putUint32(buf, uint32(len(field.tables)))
+			slots := buf.Len()
+
+			/*
+			 * Reserve the offsets of the tables.
+			 */
+			for range field.tables {
+				putUint32(buf, 0)
+			}
+
+			/*
+			 * Encode each table and patch its offset.
+			 */
+			for i, table := range field.tables {
+				slot := slots + (4 * i)
+				target := table.encode(buf)
+				binary.LittleEndian.PutUint32(buf.Bytes()[slot:], uint32(target-slot))
+			}
+
+		} else {
+			putUint32(buf, uint32(field.count))
+			buf.Write(field.structs)
+		}
+
+	}
+
+	return pos
+}
+
+/*
+ * Encode a message of the IPC format, starting with the continuation marker.
+ */
+func encodeMessage(w *bytes.Buffer, headerType uint8, header *fbFieldStruct, body []byte) {
+	message := fbTableStruct{
+		fields: []*fbFieldStruct{
+			fbScalar(int16(4)),
+			fbScalar(headerType),
+			header,
+			fbScalar(int64(len(body))),
+		},
+	}
+
+	buf := bytes.Buffer{}
+	putUint32(&buf, 0)
+	root := message.encode(&buf)
+	binary.LittleEndian.PutUint32(buf.Bytes(), uint32(root))
+
+	/*
+	 * Pad the metadata to eight bytes.
+	 */
+	for (buf.Len() % 8) != 0 {
+		buf.WriteByte(0)
+	}
+
+	putUint32(w, CONTINUATION_MARKER)
+	putUint32(w, uint32(buf.Len()))
+	w.Write(buf.Bytes())
+	w.Write(body)
+}
+
+/*
+ * Returns a field of the schema.
+ */
+func schemaField(name string, typeType uint8, typeTable *fbFieldStruct, children ...*fbFieldStruct) *fbFieldStruct {
+	return fbTable(fbString(name), fbScalar(uint8(1)), fbScalar(typeType), typeTable, nil, fbTables(children...))
+}
+
+/*
+ * Data structure representing a column of a record batch to be encoded.
+ */
+type testColumnStruct struct {
+	buffers   [][]byte
+	length    int
+	nullCount int
+}
+
+/*
+ * Encode a record batch holding columns in depth-first order.
+ */
+func encodeRecordBatch(w *bytes.Buffer, length int, columns []testColumnStruct) {
+	body := bytes.Buffer{}
+	nodes := []interface{}{}
+	buffers := []interface{}{}
+
+	/*
+	 * Encode the buffers of each column, padded to eight bytes.
+	 */
+	for _, column := range columns {
+		nodes = append(nodes, int64(column.length), int64(column.nullCount))
+
+		/*
+		 * Encode each buffer.
+		 */
+		for _, buffer := range column.buffers {
+			buffers = append(buffers, int64(body.Len()), int64(len(buffer)))
+			body.Write(buffer)
+
+			/*
+			 * Pad the buffer.
+			 */
+			for (body.Len() % 8) != 0 {
+				body.WriteByte(0)
+			}
+
+		}
+
+	}
+
+	header := fbTable(fbScalar(int64(length)), fbStructs(len(nodes)/2, nodes...), fbStructs(len(buffers)/2, buffers...))
+	encodeMessage(w, MESSAGE_RECORD_BATCH, header, body.Bytes())
+}
+
+/*
+ * Returns the little-endian encoding of values.
+ */
+func littleEndian(values interface{}) []byte {
+	buf := bytes.Buffer{}
+	binary.Write(&buf, binary.LittleEndian, values)
+	return buf.Bytes()
+}
+
+/*
+ * Encode a stream holding a schema and two record batches.
+ *
+ * The schema holds the fields "x" (float64), "y" (int32), "name" (UTF-8) and
+ * "position" (struct of "lon" (float32) and "lat" (float64)).
+ */
+func encodeStream() []byte {
+	buf := bytes.Buffer{}
+	float32Type := fbTable(fbScalar(int16(1)))
+	float64Type := fbTable(fbScalar(int16(2)))
+	int32Type := fbTable(fbScalar(int32(32)), fbScalar(uint8(1)))
+	lon := schemaField("lon", FIELD_FLOATING_POINT, float32Type)
+	lat := schemaField("lat", FIELD_FLOATING_POINT, float64Type)
+
+	/*
+	 * The fields of the schema.
+	 */
+	fields := []*fbFieldStruct{
+		schemaField("x", FIELD_FLOATING_POINT, float64Type),
+		schemaField("y", FIELD_INT, int32Type),
+		schemaField("name", FIELD_UTF8, fbTable()),
+		schemaField("position", FIELD_STRUCT, fbTable(), lon, lat),
+	}
+
+	schema := fbTable(fbScalar(int16(0)), fbTables(fields...))
+	encodeMessage(&buf, MESSAGE_SCHEMA, schema, nil)
+
+	/*
+	 * The columns of the first record batch, the third value of "x" is
+	 * null.
+	 */
+	first := []testColumnStruct{
+		testColumnStruct{length: 4, nullCount: 1, buffers: [][]byte{[]byte{0x0b}, littleEndian([]float64{1.5, -2.0, 0.0, 3.25})}},
+		testColumnStruct{length: 4, buffers: [][]byte{nil, littleEndian([]int32{-7, 0, 5, 2147483647})}},
+		testColumnStruct{length: 4, buffers: [][]byte{nil, littleEndian([]int32{0, 1, 2, 3, 4}), []byte("abcd")}},
+		testColumnStruct{length: 4, buffers: [][]byte{nil}},
+		testColumnStruct{length: 4, buffers: [][]byte{nil, littleEndian([]float32{13.25, -0.5, 2.5, 151.0})}},
+		testColumnStruct{length: 4, buffers: [][]byte{nil, littleEndian([]float64{52.5, 51.5, 48.75, -33.9})}},
+	}
+
+	encodeRecordBatch(&buf, 4, first)
+
+	/*
+	 * The columns of the second record batch.
+	 */
+	second := []testColumnStruct{
+		testColumnStruct{length: 1, buffers: [][]byte{nil, littleEndian([]float64{9.0})}},
+		testColumnStruct{length: 1, buffers: [][]byte{nil, littleEndian([]int32{-1})}},
+		testColumnStruct{length: 1, buffers: [][]byte{nil, littleEndian([]int32{0, 0}), nil}},
+		testColumnStruct{length: 1, buffers: [][]byte{nil}},
+		testColumnStruct{length: 1, buffers: [][]byte{nil, littleEndian([]float32{-73.75})}},
+		testColumnStruct{length: 1, buffers: [][]byte{nil, littleEndian([]float64{40.5})}},
+	}
+
+	encodeRecordBatch(&buf, 1, second)
+	putUint32(&buf, CONTINUATION_MARKER)
+	putUint32(&buf, 0)
+	return buf.Bytes()
+}
+
+/*
+ * Returns the values of a column as a string, printing null values as
+ * "null".
+ */
+func columnValues(array Array) string {
+	values := []string{}
+
+	/*
+	 * Format each value.
+	 */
+	for i := 0; i < array.Len(); i++ {
+
+		/*
+		 * Check if value is null.
+		 */
+		if !array.IsValid(i) {
+			values = append(values, "null")
+		} else {
+			values = append(values, fmt.Sprint(array.Float(i)))
+		}
+
+	}
+
+	return fmt.Sprint(values)
+}
+
+/*
+ * Read a stream, the same stream as a file and a compressed stream.
+ */
+func TestRead(t *testing.T) {
+	stream := encodeStream()
+	file := append([]byte(FILE_MAGIC+"\x00\x00"), stream...)
+	compressed := bytes.Buffer{}
+	w := gzip.NewWriter(&compressed)
+	w.Write(stream)
+	w.Close()
+
+	/*
+	 * The inputs to read.
+	 */
+	inputs := []struct {
+		name string
+		data []byte
+	}{
+		{name: "stream", data: stream},
+		{name: "file", data: file},
+		{name: "compressed", data: compressed.Bytes()},
+	}
+
+	/*
+	 * The columns expected in each batch.
+	 */
+	expected := []map[string]string{
+		map[string]string{
+			"x":            "[1.5 -2 null 3.25]",
+			"y":            "[-7 0 5 2.147483647e+09]",
+			"position.lon": "[13.25 -0.5 2.5 151]",
+			"position.lat": "[52.5 51.5 48.75 -33.9]",
+		},
+		map[string]string{
+			"x":            "[9]",
+			"y":            "[-1]",
+			"position.lon": "[-73.75]",
+			"position.lat": "[40.5]",
+		},
+	}
+
+	/*
+	 * Read each input.
+	 */
+	for _, input := range inputs {
+		rd, err := Create(bytes.NewReader(input.data))
+
+		/*
+		 * Check if reader could be created.
+		 */
+		if err != nil {
+			t.Fatalf("%s: %s", input.name, err.Error())
+		}
+
+		columns := fmt.Sprint(rd.Columns())
+
+		/*
+		 * Only numeric columns are exposed.
+		 */
+		if columns != "[x y position.lon position.lat]" {
+			t.Errorf("%s: Columns are %s.", input.name, columns)
+		}
+
+		/*
+		 * Read each batch.
+		 */
+		for i, want := range expected {
+			batch, err := rd.Read()
+
+			/*
+			 * Check if batch could be read.
+			 */
+			if err != nil {
+				t.Fatalf("%s: %s", input.name, err.Error())
+			}
+
+			_, hasName := batch.Column("name")
+
+			/*
+			 * Check if non-numeric columns are hidden.
+			 */
+			if hasName {
+				t.Errorf("%s: Column 'name' is exposed.", input.name)
+			}
+
+			/*
+			 * Compare each column.
+			 */
+			for name, values := range want {
+				array, ok := batch.Column(name)
+
+				/*
+				 * Check if column exists and matches.
+				 */
+				if !ok {
+					t.Errorf("%s: Batch %d lacks column '%s'.", input.name, i, name)
+				} else if columnValues(array) != values {
+					t.Errorf("%s: Column '%s' of batch %d is %s, expected %s.", input.name, name, i, columnValues(array), values)
+				}
+
+			}
+
+		}
+
+		_, err = rd.Read()
+
+		/*
+		 * Check if the end of the stream was reached.
+		 */
+		if err != io.EOF {
+			t.Errorf("%s: Expected io.EOF after the last batch, got %v", input.name, err)
+		}
+
+	}
+
+}
+
+/*
+ * Aggregate a stream into a scene using the fields of a struct.
+ */
+func TestAggregate(t *testing.T) {
+	rd, err := Create(bytes.NewReader(encodeStream()), WithCoordinates("position.lon", "x"))
+
+	/*
+	 * Check if reader could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	scn := scene.Create(16, 4, -180.0, 180.0, -10.0, 10.0)
+	err = rd.Aggregate(scn)
+
+	/*
+	 * Check if stream could be aggregated.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	expected := scene.Create(16, 4, -180.0, 180.0, -10.0, 10.0)
+
+	/*
+	 * The points with valid coordinates.
+	 */
+	points := []coordinates.Cartesian{
+		coordinates.CreateCartesian(13.25, 1.5),
+		coordinates.CreateCartesian(-0.5, -2.0),
+		coordinates.CreateCartesian(151.0, 3.25),
+		coordinates.CreateCartesian(-73.75, 9.0),
+	}
+
+	expected.Aggregate(points)
+	bins := fmt.Sprint(scn.Hotspots(64))
+	expectedBins := fmt.Sprint(expected.Hotspots(64))
+
+	/*
+	 * Compare the counts of all non-empty bins.
+	 */
+	if bins != expectedBins {
+		t.Errorf("Bins are %s, expected %s.", bins, expectedBins)
+	}
+
+	rd, err = Create(bytes.NewReader(encodeStream()), WithCoordinates("name", "x"))
+
+	/*
+	 * Check if reader could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	err = rd.Aggregate(scn)
+
+	/*
+	 * Non-numeric columns cannot hold coordinates.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Expected an error for a non-numeric column.")
+	}
+
+}
+
+/*
+ * Create arrays of each type and read their values back.
+ */
+func TestCreateArray(t *testing.T) {
+
+	/*
+	 * Arrays holding the same values as different types.
+	 */
+	cases := []struct {
+		arrayType int
+		values    []byte
+		expected  string
+	}{
+		{arrayType: TYPE_INT8, values: littleEndian([]int8{-128, 0, 127}), expected: "[-128 0 127]"},
+		{arrayType: TYPE_INT16, values: littleEndian([]int16{-32768, 0, 32767}), expected: "[-32768 0 32767]"},
+		{arrayType: TYPE_INT32, values: littleEndian([]int32{-1, 0, 1}), expected: "[-1 0 1]"},
+		{arrayType: TYPE_INT64, values: littleEndian([]int64{-1 << 40, 0, 1 << 40}), expected: "[-1.099511627776e+12 0 1.099511627776e+12]"},
+		{arrayType: TYPE_UINT8, values: littleEndian([]uint8{0, 1, 255}), expected: "[0 1 255]"},
+		{arrayType: TYPE_UINT16, values: littleEndian([]uint16{0, 1, 65535}), expected: "[0 1 65535]"},
+		{arrayType: TYPE_UINT32, values: littleEndian([]uint32{0, 1, 4294967295}), expected: "[0 1 4.294967295e+09]"},
+		{arrayType: TYPE_UINT64, values: littleEndian([]uint64{0, 1, 1 << 63}), expected: "[0 1 9.223372036854776e+18]"},
+		{arrayType: TYPE_FLOAT32, values: littleEndian([]float32{-0.5, 0, 1e10}), expected: "[-0.5 0 1e+10]"},
+		{arrayType: TYPE_FLOAT64, values: littleEndian([]float64{math.Inf(-1), 0, math.Pi}), expected: "[-Inf 0 3.141592653589793]"},
+	}
+
+	/*
+	 * Create each array.
+	 */
+	for _, c := range cases {
+		array, err := CreateArray(c.arrayType, 3, nil, c.values)
+
+		/*
+		 * Check if array could be created and holds the values.
+		 */
+		if err != nil {
+			t.Errorf("Type %d: %s", c.arrayType, err.Error())
+		} else if array.Type() != c.arrayType {
+			t.Errorf("Type %d: Array has type %d.", c.arrayType, array.Type())
+		} else if columnValues(array) != c.expected {
+			t.Errorf("Type %d: Values are %s, expected %s.", c.arrayType, columnValues(array), c.expected)
+		}
+
+	}
+
+	array, err := CreateArray(TYPE_UINT8, 3, []byte{0x05}, []byte{1, 2, 3})
+
+	/*
+	 * Check if the validity bitmap is applied.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if values := columnValues(array); values != "[1 null 3]" {
+		t.Errorf("Values are %s, expected [1 null 3].", values)
+	} else if !math.IsNaN(array.Float(1)) {
+		t.Errorf("%s", "Null value is not NaN.")
+	}
+
+	_, errType := CreateArray(len(typeSizes), 1, nil, make([]byte, 8))
+	_, errValues := CreateArray(TYPE_INT32, 3, nil, make([]byte, 11))
+	_, errValidity := CreateArray(TYPE_UINT8, 9, []byte{0xff}, make([]byte, 9))
+
+	/*
+	 * Check if invalid arrays are rejected.
+	 */
+	if errType == nil {
+		t.Errorf("%s", "Expected an error for an invalid type.")
+	} else if errValues == nil {
+		t.Errorf("%s", "Expected an error for a short values buffer.")
+	} else if errValidity == nil {
+		t.Errorf("%s", "Expected an error for a short validity bitmap.")
+	}
+
+}
+
+/*
+ * Streams without schema are rejected.
+ */
+func TestInvalid(t *testing.T) {
+	_, err := Create(bytes.NewReader(nil))
+
+	/*
+	 * Check if an empty stream was rejected.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Expected an error for an empty stream.")
+	}
+
+	buf := bytes.Buffer{}
+	column := testColumnStruct{length: 1, buffers: [][]byte{nil, littleEndian([]float64{1.0})}}
+	encodeRecordBatch(&buf, 1, []testColumnStruct{column})
+	_, err = Create(&buf)
+
+	/*
+	 * Check if a stream starting with a record batch was rejected.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Expected an error for a stream without schema.")
+	}
+
+}
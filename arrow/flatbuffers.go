@@ -0,0 +1,306 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+ * Data structure representing a table within a FlatBuffers buffer, which
+ * Arrow uses for the metadata of its messages.
+ */
+type tableStruct struct {
+	buf     []byte
+	pos     int
+	size    int
+	vtable  int
+	vfields int
+}
+
+/*
+ * Open the table at a certain position of a buffer, verifying that the table
+ * and its vtable lie within the buffer.
+ */
+func openTable(buf []byte, pos int) (tableStruct, error) {
+
+	/*
+	 * Check if the offset to the vtable lies within the buffer.
+	 */
+	if (pos < 0) || (pos+4 > len(buf)) {
+		return tableStruct{}, fmt.Errorf("%s", "Table out of bounds.")
+	} else {
+		vtable := pos - int(int32(binary.LittleEndian.Uint32(buf[pos:])))
+
+		/*
+		 * Check if the header of the vtable lies within the buffer.
+		 */
+		if (vtable < 0) || (vtable+4 > len(buf)) {
+			return tableStruct{}, fmt.Errorf("%s", "Vtable out of bounds.")
+		} else {
+			vsize := int(binary.LittleEndian.Uint16(buf[vtable:]))
+			size := int(binary.LittleEndian.Uint16(buf[vtable+2:]))
+
+			/*
+			 * Check if vtable and table lie within the buffer.
+			 */
+			if (vsize < 4) || (vtable+vsize > len(buf)) || (pos+size > len(buf)) {
+				return tableStruct{}, fmt.Errorf("%s", "Table out of bounds.")
+			} else {
+
+				/*
+				 * Create table.
+				 */
+				t := tableStruct{
+					buf:     buf,
+					pos:     pos,
+					size:    size,
+					vtable:  vtable,
+					vfields: (vsize - 4) / 2,
+				}
+
+				return t, nil
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Open the root table of a buffer.
+ */
+func rootTable(buf []byte) (tableStruct, error) {
+
+	/*
+	 * Check if the offset to the root table is present.
+	 */
+	if len(buf) < 4 {
+		return tableStruct{}, fmt.Errorf("%s", "Buffer too short.")
+	} else {
+		pos := int(binary.LittleEndian.Uint32(buf))
+		return openTable(buf, pos)
+	}
+
+}
+
+/*
+ * Returns the position of a field of a certain size within the buffer or -1
+ * if the field is absent.
+ */
+func (this *tableStruct) field(idx int, size int) int {
+
+	/*
+	 * Fields beyond the vtable are absent.
+	 */
+	if idx >= this.vfields {
+		return -1
+	} else {
+		offset := int(binary.LittleEndian.Uint16(this.buf[this.vtable+4+(2*idx):]))
+
+		/*
+		 * Fields with zero offset or exceeding the table are absent.
+		 */
+		if (offset == 0) || (offset+size > this.size) {
+			return -1
+		} else {
+			return this.pos + offset
+		}
+
+	}
+
+}
+
+/*
+ * Read an unsigned 8-bit field.
+ */
+func (this *tableStruct) uint8(idx int, def uint8) uint8 {
+	pos := this.field(idx, 1)
+
+	/*
+	 * Return default value for absent fields.
+	 */
+	if pos < 0 {
+		return def
+	} else {
+		return this.buf[pos]
+	}
+
+}
+
+/*
+ * Read a signed 16-bit field.
+ */
+func (this *tableStruct) int16(idx int, def int16) int16 {
+	pos := this.field(idx, 2)
+
+	/*
+	 * Return default value for absent fields.
+	 */
+	if pos < 0 {
+		return def
+	} else {
+		return int16(binary.LittleEndian.Uint16(this.buf[pos:]))
+	}
+
+}
+
+/*
+ * Read a signed 32-bit field.
+ */
+func (this *tableStruct) int32(idx int, def int32) int32 {
+	pos := this.field(idx, 4)
+
+	/*
+	 * Return default value for absent fields.
+	 */
+	if pos < 0 {
+		return def
+	} else {
+		return int32(binary.LittleEndian.Uint32(this.buf[pos:]))
+	}
+
+}
+
+/*
+ * Read a signed 64-bit field.
+ */
+func (this *tableStruct) int64(idx int, def int64) int64 {
+	pos := this.field(idx, 8)
+
+	/*
+	 * Return default value for absent fields.
+	 */
+	if pos < 0 {
+		return def
+	} else {
+		return int64(binary.LittleEndian.Uint64(this.buf[pos:]))
+	}
+
+}
+
+/*
+ * Follow the offset stored in a field, returning the position it refers to
+ * or -1 if the field is absent.
+ */
+func (this *tableStruct) indirect(idx int) int {
+	pos := this.field(idx, 4)
+
+	/*
+	 * Return -1 for absent fields.
+	 */
+	if pos < 0 {
+		return -1
+	} else {
+		return pos + int(binary.LittleEndian.Uint32(this.buf[pos:]))
+	}
+
+}
+
+/*
+ * Open a table referenced by a field.
+ *
+ * Returns false if the field is absent.
+ */
+func (this *tableStruct) table(idx int) (tableStruct, bool, error) {
+	pos := this.indirect(idx)
+
+	/*
+	 * Check if field is present.
+	 */
+	if pos < 0 {
+		return tableStruct{}, false, nil
+	} else {
+		t, err := openTable(this.buf, pos)
+		return t, err == nil, err
+	}
+
+}
+
+/*
+ * Locate a vector referenced by a field, whose elements have a certain size,
+ * returning the position of its first element and its length.
+ *
+ * Absent vectors are empty.
+ */
+func (this *tableStruct) vector(idx int, elementSize int) (int, int, error) {
+	pos := this.indirect(idx)
+
+	/*
+	 * Check if field is present.
+	 */
+	if pos < 0 {
+		return 0, 0, nil
+	} else if pos+4 > len(this.buf) {
+		return 0, 0, fmt.Errorf("%s", "Vector out of bounds.")
+	} else {
+		length := int(binary.LittleEndian.Uint32(this.buf[pos:]))
+		start := pos + 4
+
+		/*
+		 * Check if elements lie within the buffer.
+		 */
+		if (length < 0) || (length > (len(this.buf)-start)/elementSize) {
+			return 0, 0, fmt.Errorf("%s", "Vector out of bounds.")
+		} else {
+			return start, length, nil
+		}
+
+	}
+
+}
+
+/*
+ * Open the tables referenced by a vector of offsets.
+ */
+func (this *tableStruct) tables(idx int) ([]tableStruct, error) {
+	start, length, err := this.vector(idx, 4)
+
+	/*
+	 * Check if vector could be located.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		result := make([]tableStruct, length)
+
+		/*
+		 * Open each table.
+		 */
+		for i := range result {
+			pos := start + (4 * i)
+			pos += int(binary.LittleEndian.Uint32(this.buf[pos:]))
+			result[i], err = openTable(this.buf, pos)
+
+			/*
+			 * Check if table could be opened.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+		}
+
+		return result, nil
+	}
+
+}
+
+/*
+ * Read a string referenced by a field.
+ *
+ * Absent strings are empty.
+ */
+func (this *tableStruct) string(idx int) (string, error) {
+	start, length, err := this.vector(idx, 1)
+
+	/*
+	 * Check if string could be located.
+	 */
+	if err != nil {
+		return "", err
+	} else {
+		return string(this.buf[start : start+length]), nil
+	}
+
+}
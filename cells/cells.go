@@ -0,0 +1,238 @@
+package cells
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"math"
+)
+
+/*
+ * A cell system divides the surface of the earth into cells identified by
+ * 64-bit IDs, e. g. S2 or H3 cells.
+ *
+ * Boundary returns the vertices of a cell in counter-clockwise order, while
+ * Cell returns the ID of the cell at the resolution of the system
+ * containing a location.
+ */
+type System interface {
+	Boundary(id uint64) ([]coordinates.Geographic, error)
+	Cell(location coordinates.Geographic) (uint64, error)
+}
+
+/*
+ * Make the longitudes of a boundary continuous, so that cells crossing the
+ * antimeridian do not span the whole map.
+ *
+ * Returns the boundary in degrees and the offsets of the longitudes at
+ * which it must be rendered to cover both sides of the antimeridian.
+ */
+func unwrap(boundary []coordinates.Geographic) ([]coordinates.Cartesian, []float64) {
+	points := make([]coordinates.Cartesian, len(boundary))
+	previous := 0.0
+	east := false
+	west := false
+
+	/*
+	 * Shift each longitude to the turn closest to the previous one.
+	 */
+	for i, location := range boundary {
+		longitude := location.LongitudeDegrees()
+		latitude := location.LatitudeDegrees()
+
+		/*
+		 * The first longitude is kept.
+		 */
+		if i > 0 {
+			longitude -= 360.0 * math.Round((longitude-previous)/360.0)
+		}
+
+		east = east || (longitude > 180.0)
+		west = west || (longitude < -180.0)
+		points[i] = coordinates.CreateCartesian(longitude, latitude)
+		previous = longitude
+	}
+
+	offsets := []float64{0.0}
+
+	/*
+	 * Render parts beyond the antimeridian on the other side of the map.
+	 */
+	if east {
+		offsets = append(offsets, -360.0)
+	} else if west {
+		offsets = append(offsets, 360.0)
+	}
+
+	return points, offsets
+}
+
+/*
+ * Project a ring given in degrees as x- and y-coordinates.
+ */
+func projectRing(ring []coordinates.Cartesian, offset float64, proj projection.Projection) []coordinates.Cartesian {
+	numPoints := len(ring)
+	result := make([]coordinates.Cartesian, numPoints)
+
+	/*
+	 * Project locations if needed.
+	 */
+	if proj == nil {
+
+		/*
+		 * Shift each point.
+		 */
+		for i, point := range ring {
+			result[i] = coordinates.CreateCartesian(point.X()+offset, point.Y())
+		}
+
+	} else {
+		locations := make([]coordinates.Geographic, numPoints)
+
+		/*
+		 * Convert each point into a location.
+		 */
+		for i, point := range ring {
+			locations[i] = coordinates.CreateGeographicDegrees(point.X()+offset, point.Y())
+		}
+
+		proj.Forward(result, locations)
+	}
+
+	return result
+}
+
+/*
+ * Render cells into a scene, filling the polygon of each cell with its
+ * value, e. g. counts of events aggregated per cell by an analytics
+ * pipeline.
+ *
+ * The boundaries of the cells are projected using the projection. Without
+ * projection, longitude and latitude in degrees are used as x- and
+ * y-coordinates. A bin of the scene is filled if its center lies within a
+ * cell. Cells crossing the antimeridian are filled on both sides of the
+ * map. Returns the first error encountered, but renders all valid cells.
+ */
+func Aggregate(scn scene.Scene, sys System, values map[uint64]uint64, proj projection.Projection) error {
+	firstErr := error(nil)
+
+	/*
+	 * Render each cell.
+	 */
+	for id, value := range values {
+		boundary, err := sys.Boundary(id)
+
+		/*
+		 * Remember the first error and skip invalid cells.
+		 */
+		if err != nil {
+
+			/*
+			 * Check if this is the first error.
+			 */
+			if firstErr == nil {
+				firstErr = err
+			}
+
+		} else {
+			ring, offsets := unwrap(boundary)
+
+			/*
+			 * Fill each copy of the cell.
+			 */
+			for _, offset := range offsets {
+				projected := projectRing(ring, offset, proj)
+				rings := [][]coordinates.Cartesian{projected}
+				polygon := coordinates.CreatePolygon(rings)
+				scn.Fill(polygon, value)
+			}
+
+		}
+
+	}
+
+	return firstErr
+}
+
+/*
+ * Re-bin the counts of a scene into cells, so that they can be joined with
+ * other data keyed by cell IDs.
+ *
+ * The count of each bin is assigned to the cell containing its center. The
+ * centers are converted into geographic locations using the inverse of the
+ * projection. Without projection, x- and y-coordinates are used as
+ * longitude and latitude in degrees. Bins which cannot be projected or
+ * assigned to a cell are skipped. Choose a resolution, whose cells are
+ * considerably larger than the bins, so that each cell receives the counts
+ * of several bins.
+ */
+func Export(scn scene.Scene, sys System, proj projection.Projection) map[uint64]uint64 {
+	width, height := scn.Dimensions()
+	bounds := scn.Bounds()
+	min := bounds.Min()
+	max := bounds.Max()
+	sizeX := (max.X() - min.X()) / float64(width)
+	sizeY := (max.Y() - min.Y()) / float64(height)
+	counts := scn.Counts()
+	result := map[uint64]uint64{}
+	widthInt := int(width)
+	points := make([]coordinates.Cartesian, widthInt)
+	locations := make([]coordinates.Geographic, widthInt)
+
+	/*
+	 * Process one row of bins at a time.
+	 */
+	for y := 0; y < int(height); y++ {
+		row := counts[y*widthInt : (y+1)*widthInt]
+		centerY := max.Y() - ((float64(y) + 0.5) * sizeY)
+
+		/*
+		 * Calculate the center of each bin.
+		 */
+		for x := range points {
+			centerX := min.X() + ((float64(x) + 0.5) * sizeX)
+			points[x] = coordinates.CreateCartesian(centerX, centerY)
+		}
+
+		/*
+		 * Convert centers into locations.
+		 */
+		if proj != nil {
+			proj.Inverse(locations, points)
+		} else {
+
+			/*
+			 * Coordinates are longitude and latitude in degrees.
+			 */
+			for x, point := range points {
+				locations[x] = coordinates.CreateGeographicDegrees(point.X(), point.Y())
+			}
+
+		}
+
+		/*
+		 * Assign each non-empty bin to a cell.
+		 */
+		for x, count := range row {
+
+			/*
+			 * Skip empty bins.
+			 */
+			if count > 0 {
+				id, err := sys.Cell(locations[x])
+
+				/*
+				 * Skip bins which cannot be assigned to a cell.
+				 */
+				if err == nil {
+					result[id] += count
+				}
+
+			}
+
+		}
+
+	}
+
+	return result
+}
@@ -0,0 +1,1786 @@
+package cells
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"strconv"
+)
+
+/*
+ * Constants describing the H3 cell hierarchy.
+ */
+const (
+	H3_MAX_RESOLUTION   = 15
+	H3_NUM_BASE_CELLS   = 122
+	H3_NUM_FACES        = 20
+	H3_MODE_CELL        = 1
+	H3_MODE_OFFSET      = 59
+	H3_RESERVED_OFFSET  = 56
+	H3_RES_OFFSET       = 52
+	H3_BASE_CELL_OFFSET = 45
+	H3_DIGIT_BITS       = 3
+	H3_DIGIT_MASK       = 7
+	H3_UNUSED_DIGITS    = 0x1fffffffffff
+)
+
+/*
+ * The digits of an H3 cell ID, which select a child of a cell by its
+ * direction on the grid.
+ */
+const (
+	H3_CENTER_DIGIT  = 0
+	H3_K_DIGIT       = 1
+	H3_I_DIGIT       = 4
+	H3_IK_DIGIT      = 5
+	H3_INVALID_DIGIT = 7
+)
+
+/*
+ * Kinds of overage of a location beyond the grid of its face.
+ */
+const (
+	H3_NO_OVERAGE = 0
+	H3_FACE_EDGE  = 1
+	H3_NEW_FACE   = 2
+)
+
+/*
+ * Number of vertices of hexagons and pentagons.
+ */
+const (
+	H3_NUM_VERTS   = 6
+	H3_PENTA_VERTS = 5
+)
+
+/*
+ * Constants describing the geometry of the H3 grid.
+ *
+ * H3_RES0_U_GNOMONIC is the length of the unit vector of the grid at
+ * resolution 0 in the gnomonic projection of a face, while H3_AP7_ROT is
+ * the angle (in radians) by which the grids of Class III resolutions are
+ * rotated.
+ */
+const (
+	H3_RES0_U_GNOMONIC = 0.38196601125010500003
+	H3_AP7_ROT         = 0.333473172251832115336090755351601070065900389
+	H3_SQRT3_2         = 0.8660254037844386467637231707529361834714
+	H3_SQRT7           = 2.6457513110645905905016157536392604257102
+	H3_EPSILON         = 1.0e-16
+	H3_VERTEX_EPSILON  = 1.0e-6
+)
+
+/*
+ * Number of segments each edge of a cell is divided into, since edges are
+ * geodesics, which are curved on most maps.
+ */
+const (
+	H3_EDGE_SEGMENTS = 8
+)
+
+/*
+ * Data structure representing coordinates on the three axes of a grid of
+ * hexagons, which are 120 degrees apart.
+ */
+type h3Coord struct {
+	i int
+	j int
+	k int
+}
+
+/*
+ * Data structure representing coordinates on the grid of a face of the
+ * icosahedron.
+ */
+type h3FaceCoord struct {
+	face  int
+	coord h3Coord
+}
+
+/*
+ * Data structure representing how the grid of a face continues on one of
+ * its neighbours, given by the neighbouring face, the translation of the
+ * origin (in units of the grid at resolution 0) and the number of
+ * counter-clockwise rotations by 60 degrees.
+ */
+type h3Orientation struct {
+	face      int
+	translate h3Coord
+	rotation  int
+}
+
+/*
+ * Data structure representing a base cell, given by the coordinates of its
+ * center on its home face and whether it is a pentagon.
+ *
+ * For pentagons, clockwiseFaces holds the faces on which its children are
+ * rotated clockwise instead of counter-clockwise.
+ */
+type h3BaseCell struct {
+	home           h3FaceCoord
+	pentagon       bool
+	clockwiseFaces [2]int
+}
+
+/*
+ * Data structure representing the base cell at a location on the grid of a
+ * face at resolution 0, along with the number of counter-clockwise rotations
+ * by 60 degrees of the grid of the face relative to the base cell.
+ */
+type h3BaseCellRotation struct {
+	baseCell int
+	rotation int
+}
+
+/*
+ * The geographic location (latitude, longitude in radians) of the center of
+ * each face of the icosahedron.
+ */
+var h3FaceCenters = [H3_NUM_FACES][2]float64{
+	[2]float64{0.803582649718989942, 1.248397419617396099},
+	[2]float64{1.307747883455638156, 2.536945009877921159},
+	[2]float64{1.054751253523952054, -1.347517358900396623},
+	[2]float64{0.600191595538186799, -0.450603909469755746},
+	[2]float64{0.491715428198773866, 0.401988202911306943},
+	[2]float64{0.172745327415618701, 1.678146885280433686},
+	[2]float64{0.605929321571350690, 2.953923329812411617},
+	[2]float64{0.427370518328979641, -1.888876200336285401},
+	[2]float64{-0.079066118549212831, -0.733429513380867741},
+	[2]float64{-0.230961644455383637, 0.506495587332349035},
+	[2]float64{0.079066118549212831, 2.408163140208925497},
+	[2]float64{0.230961644455383637, -2.635097066257444203},
+	[2]float64{-0.172745327415618701, -1.463445768309359553},
+	[2]float64{-0.605929321571350690, -0.187669323777381622},
+	[2]float64{-0.427370518328979641, 1.252716453253507838},
+	[2]float64{-0.600191595538186799, 2.690988744120037492},
+	[2]float64{-0.491715428198773866, -2.739604450678486295},
+	[2]float64{-0.803582649718989942, -1.893195233972397139},
+	[2]float64{-1.307747883455638156, -0.604647643711872080},
+	[2]float64{-1.054751253523952054, 1.794075294689396615},
+}
+
+/*
+ * The azimuth (in radians) of the i-axis of each face, as seen from its
+ * center.
+ */
+var h3FaceAxes = [H3_NUM_FACES]float64{
+	5.619958268523939882,
+	5.760339081714187279,
+	0.780213654393430055,
+	0.430469363979999913,
+	6.130269123335111400,
+	2.692877706530642877,
+	2.982963003477243874,
+	3.532912002790141181,
+	3.494305004259568154,
+	3.003214169499538391,
+	5.930472956509811562,
+	0.138378484090254847,
+	0.448714947059150361,
+	0.158629650112549365,
+	5.891865957979238535,
+	2.711123289609793325,
+	3.294508837434268316,
+	3.804819692245439833,
+	3.664438879055192436,
+	2.361378999196363184,
+}
+
+/*
+ * The center of each face as a point on the unit sphere.
+ */
+var h3FacePoints [H3_NUM_FACES][3]float64
+
+/*
+ * The orientation of each face itself and of its neighbours across the
+ * edges between the i- and j-, k- and i- as well as j- and k-axes, in this
+ * order.
+ */
+var h3FaceNeighbors = [H3_NUM_FACES][4]h3Orientation{
+	{{0, h3Coord{0, 0, 0}, 0}, {4, h3Coord{2, 0, 2}, 1}, {1, h3Coord{2, 2, 0}, 5}, {5, h3Coord{0, 2, 2}, 3}},
+	{{1, h3Coord{0, 0, 0}, 0}, {0, h3Coord{2, 0, 2}, 1}, {2, h3Coord{2, 2, 0}, 5}, {6, h3Coord{0, 2, 2}, 3}},
+	{{2, h3Coord{0, 0, 0}, 0}, {1, h3Coord{2, 0, 2}, 1}, {3, h3Coord{2, 2, 0}, 5}, {7, h3Coord{0, 2, 2}, 3}},
+	{{3, h3Coord{0, 0, 0}, 0}, {2, h3Coord{2, 0, 2}, 1}, {4, h3Coord{2, 2, 0}, 5}, {8, h3Coord{0, 2, 2}, 3}},
+	{{4, h3Coord{0, 0, 0}, 0}, {3, h3Coord{2, 0, 2}, 1}, {0, h3Coord{2, 2, 0}, 5}, {9, h3Coord{0, 2, 2}, 3}},
+	{{5, h3Coord{0, 0, 0}, 0}, {10, h3Coord{2, 2, 0}, 3}, {14, h3Coord{2, 0, 2}, 3}, {0, h3Coord{0, 2, 2}, 3}},
+	{{6, h3Coord{0, 0, 0}, 0}, {11, h3Coord{2, 2, 0}, 3}, {10, h3Coord{2, 0, 2}, 3}, {1, h3Coord{0, 2, 2}, 3}},
+	{{7, h3Coord{0, 0, 0}, 0}, {12, h3Coord{2, 2, 0}, 3}, {11, h3Coord{2, 0, 2}, 3}, {2, h3Coord{0, 2, 2}, 3}},
+	{{8, h3Coord{0, 0, 0}, 0}, {13, h3Coord{2, 2, 0}, 3}, {12, h3Coord{2, 0, 2}, 3}, {3, h3Coord{0, 2, 2}, 3}},
+	{{9, h3Coord{0, 0, 0}, 0}, {14, h3Coord{2, 2, 0}, 3}, {13, h3Coord{2, 0, 2}, 3}, {4, h3Coord{0, 2, 2}, 3}},
+	{{10, h3Coord{0, 0, 0}, 0}, {5, h3Coord{2, 2, 0}, 3}, {6, h3Coord{2, 0, 2}, 3}, {15, h3Coord{0, 2, 2}, 3}},
+	{{11, h3Coord{0, 0, 0}, 0}, {6, h3Coord{2, 2, 0}, 3}, {7, h3Coord{2, 0, 2}, 3}, {16, h3Coord{0, 2, 2}, 3}},
+	{{12, h3Coord{0, 0, 0}, 0}, {7, h3Coord{2, 2, 0}, 3}, {8, h3Coord{2, 0, 2}, 3}, {17, h3Coord{0, 2, 2}, 3}},
+	{{13, h3Coord{0, 0, 0}, 0}, {8, h3Coord{2, 2, 0}, 3}, {9, h3Coord{2, 0, 2}, 3}, {18, h3Coord{0, 2, 2}, 3}},
+	{{14, h3Coord{0, 0, 0}, 0}, {9, h3Coord{2, 2, 0}, 3}, {5, h3Coord{2, 0, 2}, 3}, {19, h3Coord{0, 2, 2}, 3}},
+	{{15, h3Coord{0, 0, 0}, 0}, {16, h3Coord{2, 0, 2}, 1}, {19, h3Coord{2, 2, 0}, 5}, {10, h3Coord{0, 2, 2}, 3}},
+	{{16, h3Coord{0, 0, 0}, 0}, {17, h3Coord{2, 0, 2}, 1}, {15, h3Coord{2, 2, 0}, 5}, {11, h3Coord{0, 2, 2}, 3}},
+	{{17, h3Coord{0, 0, 0}, 0}, {18, h3Coord{2, 0, 2}, 1}, {16, h3Coord{2, 2, 0}, 5}, {12, h3Coord{0, 2, 2}, 3}},
+	{{18, h3Coord{0, 0, 0}, 0}, {19, h3Coord{2, 0, 2}, 1}, {17, h3Coord{2, 2, 0}, 5}, {13, h3Coord{0, 2, 2}, 3}},
+	{{19, h3Coord{0, 0, 0}, 0}, {15, h3Coord{2, 0, 2}, 1}, {18, h3Coord{2, 2, 0}, 5}, {14, h3Coord{0, 2, 2}, 3}},
+}
+
+/*
+ * Quadrants of a face, which select a neighbour in h3FaceNeighbors.
+ */
+const (
+	H3_IJ_QUADRANT = 1
+	H3_KI_QUADRANT = 2
+	H3_JK_QUADRANT = 3
+)
+
+/*
+ * The home face and center of each base cell.
+ */
+var h3BaseCells = [H3_NUM_BASE_CELLS]h3BaseCell{
+	{h3FaceCoord{1, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{2, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{1, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{2, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{0, h3Coord{2, 0, 0}}, true, [2]int{-1, -1}},
+	{h3FaceCoord{1, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{1, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{2, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{0, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{2, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{1, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{1, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{3, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{3, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{11, h3Coord{2, 0, 0}}, true, [2]int{2, 6}},
+	{h3FaceCoord{4, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{0, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{6, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{0, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{2, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{7, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{2, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{0, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{6, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{10, h3Coord{2, 0, 0}}, true, [2]int{1, 5}},
+	{h3FaceCoord{6, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{3, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{11, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{4, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{3, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{0, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{4, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{5, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{0, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{7, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{11, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{7, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{10, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{12, h3Coord{2, 0, 0}}, true, [2]int{3, 7}},
+	{h3FaceCoord{6, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{7, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{4, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{3, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{3, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{4, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{6, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{11, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{8, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{5, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{14, h3Coord{2, 0, 0}}, true, [2]int{0, 9}},
+	{h3FaceCoord{5, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{12, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{10, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{4, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{12, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{7, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{11, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{10, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{13, h3Coord{2, 0, 0}}, true, [2]int{4, 8}},
+	{h3FaceCoord{10, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{11, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{9, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{8, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{6, h3Coord{2, 0, 0}}, true, [2]int{11, 15}},
+	{h3FaceCoord{8, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{9, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{14, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{5, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{16, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{8, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{5, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{12, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{7, h3Coord{2, 0, 0}}, true, [2]int{12, 16}},
+	{h3FaceCoord{12, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{10, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{9, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{13, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{16, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{15, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{15, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{16, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{14, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{13, h3Coord{1, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{5, h3Coord{2, 0, 0}}, true, [2]int{10, 19}},
+	{h3FaceCoord{8, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{14, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{9, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{14, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{17, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{12, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{16, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{17, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{15, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{16, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{9, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{15, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{13, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{8, h3Coord{2, 0, 0}}, true, [2]int{13, 17}},
+	{h3FaceCoord{13, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{17, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{19, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{14, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{19, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{17, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{13, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{17, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{16, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{9, h3Coord{2, 0, 0}}, true, [2]int{14, 18}},
+	{h3FaceCoord{15, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{15, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{18, h3Coord{0, 1, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{18, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{19, h3Coord{0, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{17, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{19, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{18, h3Coord{0, 1, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{18, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{19, h3Coord{2, 0, 0}}, true, [2]int{-1, -1}},
+	{h3FaceCoord{19, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{18, h3Coord{0, 0, 0}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{19, h3Coord{1, 0, 1}}, false, [2]int{-1, -1}},
+	{h3FaceCoord{18, h3Coord{1, 0, 0}}, false, [2]int{-1, -1}},
+}
+
+/*
+ * The base cell at each location of the grid of each face at resolution 0,
+ * indexed by face and the i-, j- and k-coordinates.
+ */
+var h3FaceBaseCells = [H3_NUM_FACES][3][3][3]h3BaseCellRotation{
+	{
+		{{{16, 0}, {18, 0}, {24, 0}}, {{33, 0}, {30, 0}, {32, 3}}, {{49, 1}, {48, 3}, {50, 3}}},
+		{{{8, 0}, {5, 5}, {10, 5}}, {{22, 0}, {16, 0}, {18, 0}}, {{41, 1}, {33, 0}, {30, 0}}},
+		{{{4, 0}, {0, 5}, {2, 5}}, {{15, 1}, {8, 0}, {5, 5}}, {{31, 1}, {22, 0}, {16, 0}}},
+	},
+	{
+		{{{2, 0}, {6, 0}, {14, 0}}, {{10, 0}, {11, 0}, {17, 3}}, {{24, 1}, {23, 3}, {25, 3}}},
+		{{{0, 0}, {1, 5}, {9, 5}}, {{5, 0}, {2, 0}, {6, 0}}, {{18, 1}, {10, 0}, {11, 0}}},
+		{{{4, 1}, {3, 5}, {7, 5}}, {{8, 1}, {0, 0}, {1, 5}}, {{16, 1}, {5, 0}, {2, 0}}},
+	},
+	{
+		{{{7, 0}, {21, 0}, {38, 0}}, {{9, 0}, {19, 0}, {34, 3}}, {{14, 1}, {20, 3}, {36, 3}}},
+		{{{3, 0}, {13, 5}, {29, 5}}, {{1, 0}, {7, 0}, {21, 0}}, {{6, 1}, {9, 0}, {19, 0}}},
+		{{{4, 2}, {12, 5}, {26, 5}}, {{0, 1}, {3, 0}, {13, 5}}, {{2, 1}, {1, 0}, {7, 0}}},
+	},
+	{
+		{{{26, 0}, {42, 0}, {58, 0}}, {{29, 0}, {43, 0}, {62, 3}}, {{38, 1}, {47, 3}, {64, 3}}},
+		{{{12, 0}, {28, 5}, {44, 5}}, {{13, 0}, {26, 0}, {42, 0}}, {{21, 1}, {29, 0}, {43, 0}}},
+		{{{4, 3}, {15, 5}, {31, 5}}, {{3, 1}, {12, 0}, {28, 5}}, {{7, 1}, {13, 0}, {26, 0}}},
+	},
+	{
+		{{{31, 0}, {41, 0}, {49, 0}}, {{44, 0}, {53, 0}, {61, 3}}, {{58, 1}, {65, 3}, {75, 3}}},
+		{{{15, 0}, {22, 5}, {33, 5}}, {{28, 0}, {31, 0}, {41, 0}}, {{42, 1}, {44, 0}, {53, 0}}},
+		{{{4, 4}, {8, 5}, {16, 5}}, {{12, 1}, {15, 0}, {22, 5}}, {{26, 1}, {28, 0}, {31, 0}}},
+	},
+	{
+		{{{50, 0}, {48, 0}, {49, 3}}, {{32, 0}, {30, 3}, {33, 3}}, {{24, 3}, {18, 3}, {16, 3}}},
+		{{{70, 0}, {67, 0}, {66, 3}}, {{52, 3}, {50, 0}, {48, 0}}, {{37, 3}, {32, 0}, {30, 3}}},
+		{{{83, 0}, {87, 3}, {85, 3}}, {{74, 3}, {70, 0}, {67, 0}}, {{57, 3}, {52, 3}, {50, 0}}},
+	},
+	{
+		{{{25, 0}, {23, 0}, {24, 3}}, {{17, 0}, {11, 3}, {10, 3}}, {{14, 3}, {6, 3}, {2, 3}}},
+		{{{45, 0}, {39, 0}, {37, 3}}, {{35, 3}, {25, 0}, {23, 0}}, {{27, 3}, {17, 0}, {11, 3}}},
+		{{{63, 0}, {59, 3}, {57, 3}}, {{56, 3}, {45, 0}, {39, 0}}, {{46, 3}, {35, 3}, {25, 0}}},
+	},
+	{
+		{{{36, 0}, {20, 0}, {14, 3}}, {{34, 0}, {19, 3}, {9, 3}}, {{38, 3}, {21, 3}, {7, 3}}},
+		{{{55, 0}, {40, 0}, {27, 3}}, {{54, 3}, {36, 0}, {20, 0}}, {{51, 3}, {34, 0}, {19, 3}}},
+		{{{72, 0}, {60, 3}, {46, 3}}, {{73, 3}, {55, 0}, {40, 0}}, {{71, 3}, {54, 3}, {36, 0}}},
+	},
+	{
+		{{{64, 0}, {47, 0}, {38, 3}}, {{62, 0}, {43, 3}, {29, 3}}, {{58, 3}, {42, 3}, {26, 3}}},
+		{{{84, 0}, {69, 0}, {51, 3}}, {{82, 3}, {64, 0}, {47, 0}}, {{76, 3}, {62, 0}, {43, 3}}},
+		{{{97, 0}, {89, 3}, {71, 3}}, {{98, 3}, {84, 0}, {69, 0}}, {{96, 3}, {82, 3}, {64, 0}}},
+	},
+	{
+		{{{75, 0}, {65, 0}, {58, 3}}, {{61, 0}, {53, 3}, {44, 3}}, {{49, 3}, {41, 3}, {31, 3}}},
+		{{{94, 0}, {86, 0}, {76, 3}}, {{81, 3}, {75, 0}, {65, 0}}, {{66, 3}, {61, 0}, {53, 3}}},
+		{{{107, 0}, {104, 3}, {96, 3}}, {{101, 3}, {94, 0}, {86, 0}}, {{85, 3}, {81, 3}, {75, 0}}},
+	},
+	{
+		{{{57, 0}, {59, 0}, {63, 3}}, {{74, 0}, {78, 3}, {79, 3}}, {{83, 3}, {92, 3}, {95, 3}}},
+		{{{37, 0}, {39, 3}, {45, 3}}, {{52, 0}, {57, 0}, {59, 0}}, {{70, 3}, {74, 0}, {78, 3}}},
+		{{{24, 0}, {23, 3}, {25, 3}}, {{32, 3}, {37, 0}, {39, 3}}, {{50, 3}, {52, 0}, {57, 0}}},
+	},
+	{
+		{{{46, 0}, {60, 0}, {72, 3}}, {{56, 0}, {68, 3}, {80, 3}}, {{63, 3}, {77, 3}, {90, 3}}},
+		{{{27, 0}, {40, 3}, {55, 3}}, {{35, 0}, {46, 0}, {60, 0}}, {{45, 3}, {56, 0}, {68, 3}}},
+		{{{14, 0}, {20, 3}, {36, 3}}, {{17, 3}, {27, 0}, {40, 3}}, {{25, 3}, {35, 0}, {46, 0}}},
+	},
+	{
+		{{{71, 0}, {89, 0}, {97, 3}}, {{73, 0}, {91, 3}, {103, 3}}, {{72, 3}, {88, 3}, {105, 3}}},
+		{{{51, 0}, {69, 3}, {84, 3}}, {{54, 0}, {71, 0}, {89, 0}}, {{55, 3}, {73, 0}, {91, 3}}},
+		{{{38, 0}, {47, 3}, {64, 3}}, {{34, 3}, {51, 0}, {69, 3}}, {{36, 3}, {54, 0}, {71, 0}}},
+	},
+	{
+		{{{96, 0}, {104, 0}, {107, 3}}, {{98, 0}, {110, 3}, {115, 3}}, {{97, 3}, {111, 3}, {119, 3}}},
+		{{{76, 0}, {86, 3}, {94, 3}}, {{82, 0}, {96, 0}, {104, 0}}, {{84, 3}, {98, 0}, {110, 3}}},
+		{{{58, 0}, {65, 3}, {75, 3}}, {{62, 3}, {76, 0}, {86, 3}}, {{64, 3}, {82, 0}, {96, 0}}},
+	},
+	{
+		{{{85, 0}, {87, 0}, {83, 3}}, {{101, 0}, {102, 3}, {100, 3}}, {{107, 3}, {112, 3}, {114, 3}}},
+		{{{66, 0}, {67, 3}, {70, 3}}, {{81, 0}, {85, 0}, {87, 0}}, {{94, 3}, {101, 0}, {102, 3}}},
+		{{{49, 0}, {48, 3}, {50, 3}}, {{61, 3}, {66, 0}, {67, 3}}, {{75, 3}, {81, 0}, {85, 0}}},
+	},
+	{
+		{{{95, 0}, {92, 0}, {83, 0}}, {{79, 0}, {78, 0}, {74, 3}}, {{63, 1}, {59, 3}, {57, 3}}},
+		{{{109, 0}, {108, 0}, {100, 5}}, {{93, 1}, {95, 0}, {92, 0}}, {{77, 1}, {79, 0}, {78, 0}}},
+		{{{117, 4}, {118, 5}, {114, 5}}, {{106, 1}, {109, 0}, {108, 0}}, {{90, 1}, {93, 1}, {95, 0}}},
+	},
+	{
+		{{{90, 0}, {77, 0}, {63, 0}}, {{80, 0}, {68, 0}, {56, 3}}, {{72, 1}, {60, 3}, {46, 3}}},
+		{{{106, 0}, {93, 0}, {79, 5}}, {{99, 1}, {90, 0}, {77, 0}}, {{88, 1}, {80, 0}, {68, 0}}},
+		{{{117, 3}, {109, 5}, {95, 5}}, {{113, 1}, {106, 0}, {93, 0}}, {{105, 1}, {99, 1}, {90, 0}}},
+	},
+	{
+		{{{105, 0}, {88, 0}, {72, 0}}, {{103, 0}, {91, 0}, {73, 3}}, {{97, 1}, {89, 3}, {71, 3}}},
+		{{{113, 0}, {99, 0}, {80, 5}}, {{116, 1}, {105, 0}, {88, 0}}, {{111, 1}, {103, 0}, {91, 0}}},
+		{{{117, 2}, {106, 5}, {90, 5}}, {{121, 1}, {113, 0}, {99, 0}}, {{119, 1}, {116, 1}, {105, 0}}},
+	},
+	{
+		{{{119, 0}, {111, 0}, {97, 0}}, {{115, 0}, {110, 0}, {98, 3}}, {{107, 1}, {104, 3}, {96, 3}}},
+		{{{121, 0}, {116, 0}, {103, 5}}, {{120, 1}, {119, 0}, {111, 0}}, {{112, 1}, {115, 0}, {110, 0}}},
+		{{{117, 1}, {113, 5}, {105, 5}}, {{118, 1}, {121, 0}, {116, 0}}, {{114, 1}, {120, 1}, {119, 0}}},
+	},
+	{
+		{{{114, 0}, {112, 0}, {107, 0}}, {{100, 0}, {102, 0}, {101, 3}}, {{83, 1}, {87, 3}, {85, 3}}},
+		{{{118, 0}, {120, 0}, {115, 5}}, {{108, 1}, {114, 0}, {112, 0}}, {{92, 1}, {100, 0}, {102, 0}}},
+		{{{117, 0}, {121, 5}, {119, 5}}, {{109, 1}, {118, 0}, {120, 0}}, {{95, 1}, {108, 1}, {114, 0}}},
+	},
+}
+
+/*
+ * The unit vector of each digit.
+ */
+var h3UnitVectors = [7]h3Coord{
+	h3Coord{0, 0, 0},
+	h3Coord{0, 0, 1},
+	h3Coord{0, 1, 0},
+	h3Coord{0, 1, 1},
+	h3Coord{1, 0, 0},
+	h3Coord{1, 0, 1},
+	h3Coord{1, 1, 0},
+}
+
+/*
+ * The digit resulting from rotating each digit by 60 degrees.
+ */
+var h3DigitsCCW = [8]int{0, 5, 3, 1, 6, 4, 2, 7}
+var h3DigitsCW = [8]int{0, 3, 6, 2, 5, 1, 4, 7}
+
+/*
+ * The vertices of a cell centered at the origin of a grid of Class II,
+ * given on a grid with aperture 3 and 3r relative to it, in
+ * counter-clockwise order starting at the i-axis.
+ */
+var h3VerticesClassII = [H3_NUM_VERTS]h3Coord{
+	h3Coord{2, 1, 0},
+	h3Coord{1, 2, 0},
+	h3Coord{0, 2, 1},
+	h3Coord{0, 1, 2},
+	h3Coord{1, 0, 2},
+	h3Coord{2, 0, 1},
+}
+
+/*
+ * The vertices of a cell centered at the origin of a grid of Class III,
+ * given on a grid with aperture 3, 3r and 7r relative to it, in
+ * counter-clockwise order starting at the i-axis.
+ */
+var h3VerticesClassIII = [H3_NUM_VERTS]h3Coord{
+	h3Coord{5, 4, 0},
+	h3Coord{1, 5, 0},
+	h3Coord{0, 5, 4},
+	h3Coord{0, 1, 5},
+	h3Coord{4, 0, 5},
+	h3Coord{5, 0, 1},
+}
+
+/*
+ * Convert a geographic location (latitude, longitude in radians) into a
+ * point on the unit sphere.
+ */
+func h3Point(latitude float64, longitude float64) [3]float64 {
+	cosLatitude := math.Cos(latitude)
+	x := cosLatitude * math.Cos(longitude)
+	y := cosLatitude * math.Sin(longitude)
+	z := math.Sin(latitude)
+	return [3]float64{x, y, z}
+}
+
+/*
+ * Initialize the centers of the faces.
+ */
+func init() {
+
+	/*
+	 * Convert the center of each face.
+	 */
+	for face, center := range h3FaceCenters {
+		h3FacePoints[face] = h3Point(center[0], center[1])
+	}
+
+}
+
+/*
+ * Returns the sum of two coordinates.
+ */
+func (this h3Coord) add(other h3Coord) h3Coord {
+	return h3Coord{this.i + other.i, this.j + other.j, this.k + other.k}
+}
+
+/*
+ * Returns the difference of two coordinates.
+ */
+func (this h3Coord) sub(other h3Coord) h3Coord {
+	return h3Coord{this.i - other.i, this.j - other.j, this.k - other.k}
+}
+
+/*
+ * Returns the coordinates scaled by a factor.
+ */
+func (this h3Coord) scale(factor int) h3Coord {
+	return h3Coord{factor * this.i, factor * this.j, factor * this.k}
+}
+
+/*
+ * Returns the normalized form of the coordinates, in which no component is
+ * negative and at least one is zero.
+ */
+func (this h3Coord) normalize() h3Coord {
+	i := this.i
+	j := this.j
+	k := this.k
+
+	/*
+	 * Remove negative components.
+	 */
+	if i < 0 {
+		j -= i
+		k -= i
+		i = 0
+	}
+
+	if j < 0 {
+		i -= j
+		k -= j
+		j = 0
+	}
+
+	if k < 0 {
+		i -= k
+		j -= k
+		k = 0
+	}
+
+	min := i
+
+	/*
+	 * Find the smallest component.
+	 */
+	if j < min {
+		min = j
+	}
+
+	if k < min {
+		min = k
+	}
+
+	return h3Coord{i - min, j - min, k - min}
+}
+
+/*
+ * Returns the sum of three vectors, weighted by the components of the
+ * coordinates.
+ */
+func (this h3Coord) combine(i h3Coord, j h3Coord, k h3Coord) h3Coord {
+	sum := i.scale(this.i).add(j.scale(this.j)).add(k.scale(this.k))
+	return sum.normalize()
+}
+
+/*
+ * Returns the coordinates rotated counter-clockwise by 60 degrees.
+ */
+func (this h3Coord) rotate60CCW() h3Coord {
+	return this.combine(h3Coord{1, 1, 0}, h3Coord{0, 1, 1}, h3Coord{1, 0, 1})
+}
+
+/*
+ * Returns the coordinates rotated clockwise by 60 degrees.
+ */
+func (this h3Coord) rotate60CW() h3Coord {
+	return this.combine(h3Coord{1, 0, 1}, h3Coord{1, 1, 0}, h3Coord{0, 1, 1})
+}
+
+/*
+ * Returns the coordinates of the center of a cell on the grid with the next
+ * finer resolution of aperture 7, rotated counter-clockwise.
+ */
+func (this h3Coord) downAp7() h3Coord {
+	return this.combine(h3Coord{3, 0, 1}, h3Coord{1, 3, 0}, h3Coord{0, 1, 3})
+}
+
+/*
+ * Returns the coordinates of the center of a cell on the grid with the next
+ * finer resolution of aperture 7, rotated clockwise.
+ */
+func (this h3Coord) downAp7r() h3Coord {
+	return this.combine(h3Coord{3, 1, 0}, h3Coord{0, 3, 1}, h3Coord{1, 0, 3})
+}
+
+/*
+ * Returns the coordinates of the center of a cell on the grid with the next
+ * finer resolution of aperture 3, rotated counter-clockwise.
+ */
+func (this h3Coord) downAp3() h3Coord {
+	return this.combine(h3Coord{2, 0, 1}, h3Coord{1, 2, 0}, h3Coord{0, 1, 2})
+}
+
+/*
+ * Returns the coordinates of the center of a cell on the grid with the next
+ * finer resolution of aperture 3, rotated clockwise.
+ */
+func (this h3Coord) downAp3r() h3Coord {
+	return this.combine(h3Coord{2, 1, 0}, h3Coord{0, 2, 1}, h3Coord{1, 0, 2})
+}
+
+/*
+ * Returns the coordinates of the parent of a cell on the grid with the next
+ * coarser resolution of aperture 7, rotated counter-clockwise.
+ */
+func (this h3Coord) upAp7() h3Coord {
+	i := float64(this.i - this.k)
+	j := float64(this.j - this.k)
+	iParent := int(math.Round(((3.0 * i) - j) / 7.0))
+	jParent := int(math.Round((i + (2.0 * j)) / 7.0))
+	parent := h3Coord{iParent, jParent, 0}
+	return parent.normalize()
+}
+
+/*
+ * Returns the coordinates of the parent of a cell on the grid with the next
+ * coarser resolution of aperture 7, rotated clockwise.
+ */
+func (this h3Coord) upAp7r() h3Coord {
+	i := float64(this.i - this.k)
+	j := float64(this.j - this.k)
+	iParent := int(math.Round(((2.0 * i) + j) / 7.0))
+	jParent := int(math.Round(((3.0 * j) - i) / 7.0))
+	parent := h3Coord{iParent, jParent, 0}
+	return parent.normalize()
+}
+
+/*
+ * Returns the coordinates of the neighbouring cell in the direction of a
+ * digit.
+ */
+func (this h3Coord) neighbor(digit int) h3Coord {
+
+	/*
+	 * The center digit and invalid digits do not move.
+	 */
+	if (digit > H3_CENTER_DIGIT) && (digit < H3_INVALID_DIGIT) {
+		neighbor := this.add(h3UnitVectors[digit])
+		return neighbor.normalize()
+	} else {
+		return this
+	}
+
+}
+
+/*
+ * Returns the digit of a unit vector or H3_INVALID_DIGIT if the coordinates
+ * are no unit vector.
+ */
+func (this h3Coord) digit() int {
+	coord := this.normalize()
+
+	/*
+	 * Find the matching unit vector.
+	 */
+	for digit, unit := range h3UnitVectors {
+
+		/*
+		 * Check if the unit vector matches.
+		 */
+		if coord == unit {
+			return digit
+		}
+
+	}
+
+	return H3_INVALID_DIGIT
+}
+
+/*
+ * Convert the coordinates into Cartesian coordinates in the plane of the
+ * grid.
+ */
+func (this h3Coord) hex2D() (float64, float64) {
+	i := float64(this.i - this.k)
+	j := float64(this.j - this.k)
+	x := i - (0.5 * j)
+	y := H3_SQRT3_2 * j
+	return x, y
+}
+
+/*
+ * Convert Cartesian coordinates in the plane of the grid into the
+ * coordinates of the cell containing them.
+ */
+func h3Hex2DToCoord(x float64, y float64) h3Coord {
+	a1 := math.Abs(x)
+	a2 := math.Abs(y)
+	x2 := a2 / H3_SQRT3_2
+	x1 := a1 + (x2 / 2.0)
+	m1 := int(x1)
+	m2 := int(x2)
+	r1 := x1 - float64(m1)
+	r2 := x2 - float64(m2)
+	i := m1
+	j := m2
+
+	/*
+	 * Decide on the cell depending on the position within the
+	 * parallelogram spanned by the axes.
+	 */
+	if r1 < 0.5 {
+
+		/*
+		 * Decide on the part of the lower half.
+		 */
+		if r1 < (1.0 / 3.0) {
+
+			/*
+			 * Check if the upper cell is closer.
+			 */
+			if r2 >= ((1.0 + r1) / 2.0) {
+				j = m2 + 1
+			}
+
+		} else {
+
+			/*
+			 * Check if the upper cells are closer.
+			 */
+			if r2 >= (1.0 - r1) {
+				j = m2 + 1
+			}
+
+			/*
+			 * Check if the right cell is closer.
+			 */
+			if ((1.0 - r1) <= r2) && (r2 < (2.0 * r1)) {
+				i = m1 + 1
+			}
+
+		}
+
+	} else {
+
+		/*
+		 * Decide on the part of the upper half.
+		 */
+		if r1 < (2.0 / 3.0) {
+
+			/*
+			 * Check if the upper cells are closer.
+			 */
+			if r2 >= (1.0 - r1) {
+				j = m2 + 1
+			}
+
+			/*
+			 * Check if the right cell is closer.
+			 */
+			if (((2.0 * r1) - 1.0) >= r2) || (r2 >= (1.0 - r1)) {
+				i = m1 + 1
+			}
+
+		} else {
+			i = m1 + 1
+
+			/*
+			 * Check if the upper cell is closer.
+			 */
+			if r2 >= (r1 / 2.0) {
+				j = m2 + 1
+			}
+
+		}
+
+	}
+
+	/*
+	 * Fold across the axes if necessary.
+	 */
+	if x < 0.0 {
+
+		/*
+		 * Rows with odd j are shifted by half a cell.
+		 */
+		if (j % 2) == 0 {
+			axis := j / 2
+			diff := i - axis
+			i = i - (2 * diff)
+		} else {
+			axis := (j + 1) / 2
+			diff := i - axis
+			i = i - ((2 * diff) + 1)
+		}
+
+	}
+
+	if y < 0.0 {
+		i = i - (((2 * j) + 1) / 2)
+		j = -j
+	}
+
+	coord := h3Coord{i, j, 0}
+	return coord.normalize()
+}
+
+/*
+ * Returns the angle normalized into the range from 0 to 2 pi.
+ */
+func h3PositiveAngle(angle float64) float64 {
+	result := math.Mod(angle, 2.0*math.Pi)
+
+	/*
+	 * Negative angles wrap around.
+	 */
+	if result < 0.0 {
+		result += 2.0 * math.Pi
+	}
+
+	return result
+}
+
+/*
+ * Convert a geographic location (latitude, longitude in radians) into the
+ * closest face and Cartesian coordinates on its grid at a resolution.
+ */
+func h3GeographicToHex2D(latitude float64, longitude float64, res int) (int, float64, float64) {
+	point := h3Point(latitude, longitude)
+	face := 0
+	sqd := 5.0
+
+	/*
+	 * Find the face whose center is closest.
+	 */
+	for f, center := range h3FacePoints {
+		dx := center[0] - point[0]
+		dy := center[1] - point[1]
+		dz := center[2] - point[2]
+		d := (dx * dx) + (dy * dy) + (dz * dz)
+
+		/*
+		 * Check if this face is closer.
+		 */
+		if d < sqd {
+			face = f
+			sqd = d
+		}
+
+	}
+
+	r := math.Acos(1.0 - (sqd / 2.0))
+
+	/*
+	 * The center of the face is the origin of its grid.
+	 */
+	if r < H3_EPSILON {
+		return face, 0.0, 0.0
+	} else {
+		center := h3FaceCenters[face]
+		latitudeCenter := center[0]
+		dLongitude := longitude - center[1]
+		sinAzimuth := math.Cos(latitude) * math.Sin(dLongitude)
+		cosAzimuth := (math.Cos(latitudeCenter) * math.Sin(latitude)) - (math.Sin(latitudeCenter) * math.Cos(latitude) * math.Cos(dLongitude))
+		azimuth := h3PositiveAngle(math.Atan2(sinAzimuth, cosAzimuth))
+		theta := h3PositiveAngle(h3FaceAxes[face] - azimuth)
+
+		/*
+		 * Grids of Class III resolutions are rotated.
+		 */
+		if (res % 2) == 1 {
+			theta = h3PositiveAngle(theta - H3_AP7_ROT)
+		}
+
+		r = math.Tan(r) / H3_RES0_U_GNOMONIC
+
+		/*
+		 * Scale to the length of the unit vector at this resolution.
+		 */
+		for i := 0; i < res; i++ {
+			r *= H3_SQRT7
+		}
+
+		x := r * math.Cos(theta)
+		y := r * math.Sin(theta)
+		return face, x, y
+	}
+
+}
+
+/*
+ * Convert Cartesian coordinates on the grid of a face at a resolution into a
+ * point on the unit sphere.
+ *
+ * Coordinates on a substrate grid are given on a grid with aperture 3 and 3r
+ * relative to the grid of Class II at this resolution.
+ */
+func h3Hex2DToPoint(x float64, y float64, face int, res int, substrate bool) [3]float64 {
+	center := h3FaceCenters[face]
+	r := math.Hypot(x, y)
+
+	/*
+	 * The origin of the grid is the center of the face.
+	 */
+	if r < H3_EPSILON {
+		return h3FacePoints[face]
+	} else {
+		theta := math.Atan2(y, x)
+
+		/*
+		 * Scale from the length of the unit vector at this resolution.
+		 */
+		for i := 0; i < res; i++ {
+			r /= H3_SQRT7
+		}
+
+		/*
+		 * Substrate grids are finer.
+		 */
+		if substrate {
+			r /= 3.0
+
+			/*
+			 * Grids of Class III resolutions are finer by another
+			 * aperture 7.
+			 */
+			if (res % 2) == 1 {
+				r /= H3_SQRT7
+			}
+
+		}
+
+		r = math.Atan(r * H3_RES0_U_GNOMONIC)
+
+		/*
+		 * Grids of Class III resolutions are rotated, unless they are
+		 * substrate grids, which are already of Class II.
+		 */
+		if !substrate && ((res % 2) == 1) {
+			theta = h3PositiveAngle(theta + H3_AP7_ROT)
+		}
+
+		azimuth := h3PositiveAngle(h3FaceAxes[face] - theta)
+		latitudeCenter := center[0]
+		sinLatitude := (math.Sin(latitudeCenter) * math.Cos(r)) + (math.Cos(latitudeCenter) * math.Sin(r) * math.Cos(azimuth))
+		sinLatitude = math.Max(-1.0, math.Min(1.0, sinLatitude))
+		latitude := math.Asin(sinLatitude)
+		sinLongitude := math.Sin(azimuth) * math.Sin(r) * math.Cos(latitudeCenter)
+		cosLongitude := math.Cos(r) - (math.Sin(latitudeCenter) * sinLatitude)
+		longitude := center[1] + math.Atan2(sinLongitude, cosLongitude)
+		return h3Point(latitude, longitude)
+	}
+
+}
+
+/*
+ * Returns the number of cells along each axis of the grid of a face at a
+ * resolution of Class II.
+ */
+func h3UnitScale(res int) int {
+	scale := 1
+
+	/*
+	 * Each resolution of Class II has an aperture of 7 relative to the
+	 * previous one.
+	 */
+	for i := 0; i < res; i += 2 {
+		scale *= 7
+	}
+
+	return scale
+}
+
+/*
+ * Returns the direction from a face to a neighbouring face, i. e. its
+ * quadrant, or -1 if the faces are not adjacent.
+ */
+func h3AdjacentDirection(from int, to int) int {
+
+	/*
+	 * Find the neighbour.
+	 */
+	for quadrant, orientation := range h3FaceNeighbors[from] {
+
+		/*
+		 * Check if the face matches.
+		 */
+		if orientation.face == to {
+			return quadrant
+		}
+
+	}
+
+	return -1
+}
+
+/*
+ * Transform coordinates on the grid of a face into coordinates on the grid
+ * of the neighbouring face in a quadrant, with the origin of the neighbour
+ * translated by a number of cells.
+ */
+func (this *h3FaceCoord) transform(quadrant int, scale int) {
+	orientation := h3FaceNeighbors[this.face][quadrant]
+	coord := this.coord
+
+	/*
+	 * Rotate into the orientation of the neighbour.
+	 */
+	for i := 0; i < orientation.rotation; i++ {
+		coord = coord.rotate60CCW()
+	}
+
+	coord = coord.add(orientation.translate.scale(scale))
+	this.face = orientation.face
+	this.coord = coord.normalize()
+}
+
+/*
+ * Move coordinates on the grid of a face at a resolution of Class II onto
+ * the neighbouring face if they lie beyond the edge of the face.
+ *
+ * Returns whether the coordinates lie on the edge of the face (only for
+ * substrate grids), beyond it or neither.
+ */
+func (this *h3FaceCoord) adjustOverage(res int, pentagonLeading4 bool, substrate bool) int {
+	maxDim := 2 * h3UnitScale(res)
+	scale := h3UnitScale(res)
+
+	/*
+	 * Substrate grids are finer.
+	 */
+	if substrate {
+		maxDim *= 3
+		scale *= 3
+	}
+
+	coord := this.coord
+	sum := coord.i + coord.j + coord.k
+
+	/*
+	 * Check if the coordinates lie on the edge of the face or beyond it.
+	 */
+	if substrate && (sum == maxDim) {
+		return H3_FACE_EDGE
+	} else if sum > maxDim {
+		quadrant := H3_IJ_QUADRANT
+
+		/*
+		 * Find the quadrant containing the coordinates.
+		 */
+		if coord.k > 0 {
+
+			/*
+			 * Decide on the neighbour.
+			 */
+			if coord.j > 0 {
+				quadrant = H3_JK_QUADRANT
+			} else {
+				quadrant = H3_KI_QUADRANT
+
+				/*
+				 * Pentagons lack the sequence of cells along the
+				 * k-axis, so rotate around the vertex.
+				 */
+				if pentagonLeading4 {
+					origin := h3Coord{maxDim, 0, 0}
+					this.coord = coord.sub(origin).rotate60CW().add(origin)
+				}
+
+			}
+
+		}
+
+		this.transform(quadrant, scale)
+		coord = this.coord
+		sum = coord.i + coord.j + coord.k
+
+		/*
+		 * Coordinates near pentagons may end up on the edge.
+		 */
+		if substrate && (sum == maxDim) {
+			return H3_FACE_EDGE
+		} else {
+			return H3_NEW_FACE
+		}
+
+	} else {
+		return H3_NO_OVERAGE
+	}
+
+}
+
+/*
+ * Returns the resolution of a cell ID.
+ */
+func h3Resolution(id uint64) int {
+	return int((id >> H3_RES_OFFSET) & 0xf)
+}
+
+/*
+ * Returns the base cell of a cell ID.
+ */
+func h3BaseCellOf(id uint64) int {
+	return int((id >> H3_BASE_CELL_OFFSET) & 0x7f)
+}
+
+/*
+ * Returns the digit of a cell ID at a resolution.
+ */
+func h3Digit(id uint64, res int) int {
+	shift := uint((H3_MAX_RESOLUTION - res) * H3_DIGIT_BITS)
+	return int((id >> shift) & H3_DIGIT_MASK)
+}
+
+/*
+ * Returns a cell ID with the digit at a resolution replaced.
+ */
+func h3SetDigit(id uint64, res int, digit int) uint64 {
+	shift := uint((H3_MAX_RESOLUTION - res) * H3_DIGIT_BITS)
+	mask := uint64(H3_DIGIT_MASK) << shift
+	return (id &^ mask) | (uint64(digit) << shift)
+}
+
+/*
+ * Returns the first digit of a cell ID which is not the center digit.
+ */
+func h3LeadingDigit(id uint64) int {
+	res := h3Resolution(id)
+
+	/*
+	 * Find the first digit which is not the center digit.
+	 */
+	for r := 1; r <= res; r++ {
+		digit := h3Digit(id, r)
+
+		/*
+		 * Check if digit moves away from the center.
+		 */
+		if digit != H3_CENTER_DIGIT {
+			return digit
+		}
+
+	}
+
+	return H3_CENTER_DIGIT
+}
+
+/*
+ * Returns a cell ID with all digits rotated by 60 degrees, as given by a
+ * table of rotated digits.
+ */
+func h3Rotate60(id uint64, rotated [8]int) uint64 {
+	res := h3Resolution(id)
+
+	/*
+	 * Rotate each digit.
+	 */
+	for r := 1; r <= res; r++ {
+		digit := h3Digit(id, r)
+		id = h3SetDigit(id, r, rotated[digit])
+	}
+
+	return id
+}
+
+/*
+ * Returns a cell ID within a pentagon with all digits rotated
+ * counter-clockwise by 60 degrees, skipping the missing sequence of cells
+ * along the k-axis.
+ */
+func h3RotatePentagon60CCW(id uint64) uint64 {
+	res := h3Resolution(id)
+	found := false
+
+	/*
+	 * Rotate each digit.
+	 */
+	for r := 1; r <= res; r++ {
+		digit := h3DigitsCCW[h3Digit(id, r)]
+		id = h3SetDigit(id, r, digit)
+
+		/*
+		 * Rotate once more if the leading digit moved onto the k-axis.
+		 */
+		if !found && (digit != H3_CENTER_DIGIT) {
+			found = true
+
+			/*
+			 * Check if leading digit is on the k-axis.
+			 */
+			if h3LeadingDigit(id) == H3_K_DIGIT {
+				id = h3Rotate60(id, h3DigitsCCW)
+			}
+
+		}
+
+	}
+
+	return id
+}
+
+/*
+ * Returns whether a cell ID denotes a valid H3 cell.
+ */
+func h3Valid(id uint64) bool {
+	mode := (id >> H3_MODE_OFFSET) & 0xf
+	reserved := (id >> H3_RESERVED_OFFSET) & 0x7
+	res := h3Resolution(id)
+	baseCell := h3BaseCellOf(id)
+
+	/*
+	 * Check the header of the cell ID.
+	 */
+	if (mode != H3_MODE_CELL) || (reserved != 0) || (baseCell >= H3_NUM_BASE_CELLS) {
+		return false
+	} else {
+
+		/*
+		 * Only digits up to the resolution are used.
+		 */
+		for r := 1; r <= H3_MAX_RESOLUTION; r++ {
+			digit := h3Digit(id, r)
+			used := r <= res
+
+			/*
+			 * Check if digit is used as required.
+			 */
+			if used == (digit == H3_INVALID_DIGIT) {
+				return false
+			}
+
+		}
+
+		pentagon := h3BaseCells[baseCell].pentagon
+		return !pentagon || (h3LeadingDigit(id) != H3_K_DIGIT)
+	}
+
+}
+
+/*
+ * Returns whether a valid cell ID denotes a pentagon.
+ */
+func h3Pentagon(id uint64) bool {
+	baseCell := h3BaseCellOf(id)
+	return h3BaseCells[baseCell].pentagon && (h3LeadingDigit(id) == H3_CENTER_DIGIT)
+}
+
+/*
+ * Convert the coordinates of a cell on the grid of a face at a resolution
+ * into its cell ID.
+ *
+ * Returns false if the coordinates lie too far beyond the face.
+ */
+func h3FromFaceCoord(location h3FaceCoord, res int) (uint64, bool) {
+	id := (uint64(H3_MODE_CELL) << H3_MODE_OFFSET) | (uint64(res) << H3_RES_OFFSET) | H3_UNUSED_DIGITS
+	coord := location.coord
+
+	/*
+	 * Find the digit of each resolution, walking up to resolution 0.
+	 */
+	for r := res; r > 0; r-- {
+		last := coord
+		center := h3Coord{}
+
+		/*
+		 * The aperture of Class III resolutions is rotated
+		 * counter-clockwise.
+		 */
+		if (r % 2) == 1 {
+			coord = coord.upAp7()
+			center = coord.downAp7()
+		} else {
+			coord = coord.upAp7r()
+			center = coord.downAp7r()
+		}
+
+		digit := last.sub(center).digit()
+		id = h3SetDigit(id, r, digit)
+	}
+
+	/*
+	 * Check if base cell lies on the face or its neighbours.
+	 */
+	if (coord.i > 2) || (coord.j > 2) || (coord.k > 2) {
+		return 0, false
+	} else {
+		entry := h3FaceBaseCells[location.face][coord.i][coord.j][coord.k]
+		baseCell := entry.baseCell
+		id |= uint64(baseCell) << H3_BASE_CELL_OFFSET
+		data := h3BaseCells[baseCell]
+
+		/*
+		 * Rotate into the orientation of the base cell.
+		 */
+		if data.pentagon {
+
+			/*
+			 * Children of pentagons must not lead along the k-axis.
+			 */
+			if h3LeadingDigit(id) == H3_K_DIGIT {
+				clockwise := (data.clockwiseFaces[0] == location.face) || (data.clockwiseFaces[1] == location.face)
+
+				/*
+				 * Rotate away from the k-axis.
+				 */
+				if clockwise {
+					id = h3Rotate60(id, h3DigitsCW)
+				} else {
+					id = h3Rotate60(id, h3DigitsCCW)
+				}
+
+			}
+
+			/*
+			 * Rotate around the pentagon.
+			 */
+			for i := 0; i < entry.rotation; i++ {
+				id = h3RotatePentagon60CCW(id)
+			}
+
+		} else {
+
+			/*
+			 * Rotate around the hexagon.
+			 */
+			for i := 0; i < entry.rotation; i++ {
+				id = h3Rotate60(id, h3DigitsCCW)
+			}
+
+		}
+
+		return id, true
+	}
+
+}
+
+/*
+ * Convert a valid cell ID into the coordinates of its center on the grid of
+ * the face containing it.
+ */
+func h3ToFaceCoord(id uint64) h3FaceCoord {
+	baseCell := h3BaseCellOf(id)
+	data := h3BaseCells[baseCell]
+	res := h3Resolution(id)
+
+	/*
+	 * Children of pentagons leading along the ik-axis lie on a rotated
+	 * grid.
+	 */
+	if data.pentagon && (h3LeadingDigit(id) == H3_IK_DIGIT) {
+		id = h3Rotate60(id, h3DigitsCW)
+	}
+
+	location := data.home
+
+	/*
+	 * Cells of hexagons centered on their home face stay on it.
+	 */
+	overage := data.pentagon || ((res > 0) && (location.coord != h3Coord{}))
+
+	/*
+	 * Descend along the digits of the cell ID.
+	 */
+	for r := 1; r <= res; r++ {
+
+		/*
+		 * The aperture of Class III resolutions is rotated
+		 * counter-clockwise.
+		 */
+		if (r % 2) == 1 {
+			location.coord = location.coord.downAp7()
+		} else {
+			location.coord = location.coord.downAp7r()
+		}
+
+		location.coord = location.coord.neighbor(h3Digit(id, r))
+	}
+
+	/*
+	 * Check if the cell may lie on a neighbouring face.
+	 */
+	if overage {
+		original := location.coord
+		adjusted := res
+
+		/*
+		 * Overages are determined on a grid of Class II.
+		 */
+		if (res % 2) == 1 {
+			location.coord = location.coord.downAp7r()
+			adjusted++
+		}
+
+		pentagonLeading4 := data.pentagon && (h3LeadingDigit(id) == H3_I_DIGIT)
+
+		/*
+		 * Move onto the neighbouring face if needed.
+		 */
+		if location.adjustOverage(adjusted, pentagonLeading4, false) != H3_NO_OVERAGE {
+
+			/*
+			 * Cells of pentagons may lie beyond a second edge.
+			 */
+			if data.pentagon {
+
+				moving := true
+
+				/*
+				 * Move until the cell lies on a face.
+				 */
+				for moving {
+					moving = location.adjustOverage(adjusted, false, false) != H3_NO_OVERAGE
+				}
+
+			}
+
+			/*
+			 * Return to the grid of Class III.
+			 */
+			if adjusted != res {
+				location.coord = location.coord.upAp7r()
+			}
+
+		} else {
+			location.coord = original
+		}
+
+	}
+
+	return location
+}
+
+/*
+ * Find the intersection of a line through two points with the edge of a
+ * face towards one of its quadrants on a substrate grid at a resolution of
+ * Class II.
+ */
+func h3EdgeIntersection(x0 float64, y0 float64, x1 float64, y1 float64, quadrant int, res int) (float64, float64) {
+	maxDim := float64(2 * h3UnitScale(res))
+	vertices := [3][2]float64{
+		[2]float64{3.0 * maxDim, 0.0},
+		[2]float64{-1.5 * maxDim, 3.0 * H3_SQRT3_2 * maxDim},
+		[2]float64{-1.5 * maxDim, -3.0 * H3_SQRT3_2 * maxDim},
+	}
+
+	start := vertices[2]
+	end := vertices[0]
+
+	/*
+	 * Select the edge towards the quadrant.
+	 */
+	switch quadrant {
+	case H3_IJ_QUADRANT:
+		start = vertices[0]
+		end = vertices[1]
+	case H3_JK_QUADRANT:
+		start = vertices[1]
+		end = vertices[2]
+	}
+
+	dx0 := x1 - x0
+	dy0 := y1 - y0
+	dx1 := end[0] - start[0]
+	dy1 := end[1] - start[1]
+	t := ((dx1 * (y0 - start[1])) - (dy1 * (x0 - start[0]))) / ((dx0 * dy1) - (dx1 * dy0))
+	x := x0 + (t * dx0)
+	y := y0 + (t * dy0)
+	return x, y
+}
+
+/*
+ * Returns the vertices of a valid cell as points on the unit sphere in
+ * counter-clockwise order.
+ *
+ * Each face of the icosahedron has its own projection, so where an edge of
+ * a cell of a Class III resolution crosses an edge of a face, an additional
+ * vertex is inserted.
+ */
+func h3Vertices(id uint64) [][3]float64 {
+	res := h3Resolution(id)
+	center := h3ToFaceCoord(id)
+	pentagon := h3Pentagon(id)
+	classIII := (res % 2) == 1
+	numVertices := H3_NUM_VERTS
+
+	/*
+	 * Pentagons lack the last vertex.
+	 */
+	if pentagon {
+		numVertices = H3_PENTA_VERTS
+	}
+
+	coord := center.coord.downAp3().downAp3r()
+	adjusted := res
+	offsets := h3VerticesClassII
+
+	/*
+	 * Vertices of Class III resolutions lie on the substrate grid of the
+	 * next finer resolution.
+	 */
+	if classIII {
+		coord = coord.downAp7r()
+		adjusted++
+		offsets = h3VerticesClassIII
+	}
+
+	locations := make([]h3FaceCoord, numVertices)
+
+	/*
+	 * Place the vertices around the center of the cell.
+	 */
+	for v := range locations {
+		locations[v].face = center.face
+		locations[v].coord = coord.add(offsets[v]).normalize()
+	}
+
+	result := make([][3]float64, 0, 2*numVertices)
+	last := h3FaceCoord{}
+	lastOverage := H3_NO_OVERAGE
+
+	/*
+	 * Visit each vertex, returning to the first one to check the last edge
+	 * for crossings.
+	 */
+	for n := 0; n <= numVertices; n++ {
+		v := n % numVertices
+		location := locations[v]
+		overage := location.adjustOverage(adjusted, false, true)
+
+		/*
+		 * Vertices of pentagons may lie beyond a second edge.
+		 */
+		for pentagon && (overage == H3_NEW_FACE) {
+			overage = location.adjustOverage(adjusted, false, true)
+		}
+
+		/*
+		 * Insert vertices where edges of Class III resolutions cross
+		 * edges of faces, which is the case for all edges of pentagons.
+		 */
+		if classIII && (n > 0) && pentagon {
+			x0, y0 := last.coord.hex2D()
+			quadrant := h3AdjacentDirection(location.face, last.face)
+			other := location
+			other.transform(quadrant, 3*h3UnitScale(adjusted))
+			x1, y1 := other.coord.hex2D()
+			edge := h3AdjacentDirection(other.face, location.face)
+			x, y := h3EdgeIntersection(x0, y0, x1, y1, edge, adjusted)
+			point := h3Hex2DToPoint(x, y, other.face, adjusted, true)
+			result = append(result, point)
+		} else if classIII && (n > 0) && (location.face != last.face) && (lastOverage != H3_FACE_EDGE) {
+			x0, y0 := locations[(v+numVertices-1)%numVertices].coord.hex2D()
+			x1, y1 := locations[v].coord.hex2D()
+			face := last.face
+
+			/*
+			 * The edge crossed lies between the face of the center
+			 * and the other face.
+			 */
+			if face == center.face {
+				face = location.face
+			}
+
+			quadrant := h3AdjacentDirection(center.face, face)
+			x, y := h3EdgeIntersection(x0, y0, x1, y1, quadrant, adjusted)
+			atVertex0 := (math.Abs(x-x0) < H3_VERTEX_EPSILON) && (math.Abs(y-y0) < H3_VERTEX_EPSILON)
+			atVertex1 := (math.Abs(x-x1) < H3_VERTEX_EPSILON) && (math.Abs(y-y1) < H3_VERTEX_EPSILON)
+
+			/*
+			 * Edges crossing at a vertex lie on a single face each.
+			 */
+			if !atVertex0 && !atVertex1 {
+				point := h3Hex2DToPoint(x, y, center.face, adjusted, true)
+				result = append(result, point)
+			}
+
+		}
+
+		/*
+		 * The first vertex is only visited again to check the last
+		 * edge.
+		 */
+		if n < numVertices {
+			x, y := location.coord.hex2D()
+			point := h3Hex2DToPoint(x, y, location.face, adjusted, true)
+			result = append(result, point)
+		}
+
+		last = location
+		lastOverage = overage
+	}
+
+	return result
+}
+
+/*
+ * Data structure representing the H3 cell system at a certain resolution.
+ */
+type h3Struct struct {
+	resolution int
+}
+
+/*
+ * Returns the boundary of an H3 cell of any resolution.
+ */
+func (this *h3Struct) Boundary(id uint64) ([]coordinates.Geographic, error) {
+
+	/*
+	 * Check if cell ID is valid.
+	 */
+	if !h3Valid(id) {
+		return nil, fmt.Errorf("Invalid H3 cell ID: %x", id)
+	} else {
+		vertices := h3Vertices(id)
+		numVertices := len(vertices)
+		boundary := make([]coordinates.Geographic, 0, numVertices*H3_EDGE_SEGMENTS)
+
+		/*
+		 * Edges are straight lines on the faces, so interpolating
+		 * linearly in space yields points along the geodesics.
+		 */
+		for k, start := range vertices {
+			end := vertices[(k+1)%numVertices]
+
+			/*
+			 * Subdivide each edge.
+			 */
+			for n := 0; n < H3_EDGE_SEGMENTS; n++ {
+				f := float64(n) / H3_EDGE_SEGMENTS
+				x := start[0] + (f * (end[0] - start[0]))
+				y := start[1] + (f * (end[1] - start[1]))
+				z := start[2] + (f * (end[2] - start[2]))
+				latitude := math.Atan2(z, math.Hypot(x, y))
+				longitude := math.Atan2(y, x)
+				location := coordinates.CreateGeographic(longitude, latitude)
+				boundary = append(boundary, location)
+			}
+
+		}
+
+		return boundary, nil
+	}
+
+}
+
+/*
+ * Returns the ID of the H3 cell at the configured resolution containing a
+ * location.
+ */
+func (this *h3Struct) Cell(location coordinates.Geographic) (uint64, error) {
+	longitude := location.Longitude()
+	latitude := location.Latitude()
+
+	/*
+	 * Check if location is valid.
+	 */
+	if math.IsNaN(longitude) || math.IsNaN(latitude) || math.IsInf(longitude, 0) || math.IsInf(latitude, 0) {
+		return 0, fmt.Errorf("%s", "Location is invalid.")
+	} else {
+		res := this.resolution
+		face, x, y := h3GeographicToHex2D(latitude, longitude, res)
+
+		/*
+		 * Coordinates of the cell on the face.
+		 */
+		coord := h3FaceCoord{
+			face:  face,
+			coord: h3Hex2DToCoord(x, y),
+		}
+
+		id, ok := h3FromFaceCoord(coord, res)
+
+		/*
+		 * Check if cell could be found.
+		 */
+		if !ok {
+			return 0, fmt.Errorf("%s", "Location could not be mapped to an H3 cell.")
+		} else {
+			return id, nil
+		}
+
+	}
+
+}
+
+/*
+ * Create the H3 cell system, which maps locations to hexagonal cells (and
+ * twelve pentagons) at a resolution between 0 (122 base cells) and
+ * H3_MAX_RESOLUTION (about 1 m²).
+ *
+ * The boundaries of cells of all resolutions are available.
+ */
+func H3(resolution int) (System, error) {
+
+	/*
+	 * Check if resolution is valid.
+	 */
+	if (resolution < 0) || (resolution > H3_MAX_RESOLUTION) {
+		return nil, fmt.Errorf("H3 resolution must be between 0 and %d, but is %d.", H3_MAX_RESOLUTION, resolution)
+	} else {
+
+		/*
+		 * Create H3 data structure.
+		 */
+		h3 := h3Struct{
+			resolution: resolution,
+		}
+
+		return &h3, nil
+	}
+
+}
+
+/*
+ * Convert an H3 cell ID into its hexadecimal representation, as used by
+ * many tools.
+ */
+func H3String(id uint64) string {
+	return strconv.FormatUint(id, 16)
+}
+
+/*
+ * Parse the hexadecimal representation of an H3 cell ID.
+ */
+func ParseH3(text string) (uint64, error) {
+	id, err := strconv.ParseUint(text, 16, 64)
+
+	/*
+	 * Check if cell ID could be parsed.
+	 */
+	if err != nil {
+		return 0, fmt.Errorf("Invalid H3 cell ID: '%s'", text)
+	} else {
+		return id, nil
+	}
+
+}
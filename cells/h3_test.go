@@ -0,0 +1,247 @@
+package cells
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"testing"
+)
+
+/*
+ * Map locations to the cells published for them.
+ */
+func TestH3Cell(t *testing.T) {
+
+	/*
+	 * Locations with known cells.
+	 */
+	cases := []struct {
+		longitude  float64
+		latitude   float64
+		resolution int
+		id         string
+	}{
+		{-122.0553238, 37.3615593, 5, "85283473fffffff"},
+		{-122.0553238, 37.3615593, 7, "87283472bffffff"},
+		{-122.418307270836, 37.7752702151959, 9, "8928308280fffff"},
+	}
+
+	/*
+	 * Compare each cell with the known one.
+	 */
+	for _, c := range cases {
+		sys, err := H3(c.resolution)
+
+		/*
+		 * Check if cell system could be created.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		location := coordinates.CreateGeographicDegrees(c.longitude, c.latitude)
+		id, err := sys.Cell(location)
+
+		/*
+		 * Check if cell matches.
+		 */
+		if err != nil {
+			t.Errorf("%s", err.Error())
+		} else if H3String(id) != c.id {
+			t.Errorf("(%f, %f) lies in cell %s, expected %s", c.longitude, c.latitude, H3String(id), c.id)
+		}
+
+	}
+
+}
+
+/*
+ * Compare the vertices of a boundary with the published ones.
+ */
+func TestH3Boundary(t *testing.T) {
+	sys, err := H3(5)
+
+	/*
+	 * Check if cell system could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	id, _ := ParseH3("85283473fffffff")
+	boundary, err := sys.Boundary(id)
+
+	/*
+	 * The published vertices (latitude, longitude in degrees).
+	 */
+	expected := [][2]float64{
+		[2]float64{37.271355866731895, -121.91508032705622},
+		[2]float64{37.353926450852256, -121.86222328902491},
+		[2]float64{37.42834118609435, -121.9235499963016},
+		[2]float64{37.42012867767778, -122.0377349642703},
+		[2]float64{37.33755608435298, -122.09042892904395},
+		[2]float64{37.26319797461824, -122.02910130919},
+	}
+
+	/*
+	 * Check if boundary has the expected number of points.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	} else if len(boundary) != len(expected)*H3_EDGE_SEGMENTS {
+		t.Fatalf("Boundary has %d points, expected %d.", len(boundary), len(expected)*H3_EDGE_SEGMENTS)
+	}
+
+	/*
+	 * Each edge starts at a vertex.
+	 */
+	for i, vertex := range expected {
+		location := boundary[i*H3_EDGE_SEGMENTS]
+		latitude := location.LatitudeDegrees()
+		longitude := location.LongitudeDegrees()
+
+		/*
+		 * Check if vertex matches.
+		 */
+		if (math.Abs(latitude-vertex[0]) > 1e-9) || (math.Abs(longitude-vertex[1]) > 1e-9) {
+			t.Errorf("Vertex %d is (%.12f, %.12f), expected (%.12f, %.12f)", i, latitude, longitude, vertex[0], vertex[1])
+		}
+
+	}
+
+}
+
+/*
+ * There are twelve pentagons at each resolution, centered on the vertices of
+ * the icosahedron.
+ */
+func TestH3Pentagons(t *testing.T) {
+	sys, _ := H3(0)
+	pentagons := 0
+
+	/*
+	 * Check the boundary of each base cell.
+	 */
+	for baseCell := 0; baseCell < H3_NUM_BASE_CELLS; baseCell++ {
+		id := (uint64(H3_MODE_CELL) << H3_MODE_OFFSET) | (uint64(baseCell) << H3_BASE_CELL_OFFSET) | H3_UNUSED_DIGITS
+		boundary, err := sys.Boundary(id)
+
+		/*
+		 * Count the pentagons.
+		 */
+		if err != nil {
+			t.Errorf("Base cell %d: %s", baseCell, err.Error())
+		} else if len(boundary) == H3_PENTA_VERTS*H3_EDGE_SEGMENTS {
+			pentagons++
+		} else if len(boundary) != H3_NUM_VERTS*H3_EDGE_SEGMENTS {
+			t.Errorf("Base cell %d has %d points.", baseCell, len(boundary))
+		}
+
+	}
+
+	/*
+	 * Check if all pentagons were found.
+	 */
+	if pentagons != 12 {
+		t.Errorf("Found %d pentagons, expected 12.", pentagons)
+	}
+
+}
+
+/*
+ * The center of a cell lies within the cell itself.
+ */
+func TestH3CellOfCenter(t *testing.T) {
+
+	/*
+	 * Check cells around the globe at all resolutions.
+	 */
+	for res := 0; res <= H3_MAX_RESOLUTION; res++ {
+		sys, _ := H3(res)
+
+		/*
+		 * Sample locations along a spiral.
+		 */
+		for n := 0; n < 200; n++ {
+			f := (float64(n) + 0.5) / 200.0
+			latitude := math.Asin((2.0 * f) - 1.0)
+			longitude := math.Remainder(float64(n)*2.399963229728653, 2.0*math.Pi)
+			location := coordinates.CreateGeographic(longitude, latitude)
+			id, err := sys.Cell(location)
+
+			/*
+			 * Check if cell could be found.
+			 */
+			if err != nil {
+				t.Fatalf("%s", err.Error())
+			}
+
+			center := h3ToFaceCoord(id)
+			x, y := center.coord.hex2D()
+			p := h3Hex2DToPoint(x, y, center.face, res, false)
+			centerLocation := coordinates.CreateGeographic(math.Atan2(p[1], p[0]), math.Atan2(p[2], math.Hypot(p[0], p[1])))
+			centerID, err := sys.Cell(centerLocation)
+
+			/*
+			 * Check if the center lies within the cell.
+			 */
+			if err != nil {
+				t.Fatalf("%s", err.Error())
+			} else if centerID != id {
+				t.Errorf("Center of cell %s lies in cell %s.", H3String(id), H3String(centerID))
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Invalid cell IDs and resolutions are rejected.
+ */
+func TestH3Invalid(t *testing.T) {
+	sys, _ := H3(5)
+
+	/*
+	 * Invalid cell IDs.
+	 */
+	ids := []string{
+		"0",
+		"85283473ffffff7",
+		"8f283473fffffff",
+		"85084003fffffff",
+		"80f5fffffffffff",
+	}
+
+	/*
+	 * Check each cell ID.
+	 */
+	for _, text := range ids {
+		id, err := ParseH3(text)
+
+		/*
+		 * Check if cell ID could be parsed.
+		 */
+		if err != nil {
+			t.Errorf("%s", err.Error())
+		} else if _, err = sys.Boundary(id); err == nil {
+			t.Errorf("Expected an error for cell ID %s.", text)
+		}
+
+	}
+
+	/*
+	 * Check that invalid resolutions are rejected.
+	 */
+	if _, err := H3(16); err == nil {
+		t.Errorf("%s", "Expected an error for resolution 16.")
+	}
+
+	/*
+	 * Check that invalid text is rejected.
+	 */
+	if _, err := ParseH3("not a cell"); err == nil {
+		t.Errorf("%s", "Expected an error for invalid text.")
+	}
+
+}
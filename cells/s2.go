@@ -0,0 +1,481 @@
+package cells
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Constants describing the S2 cell hierarchy.
+ */
+const (
+	S2_MAX_LEVEL   = 30
+	S2_POS_BITS    = (2 * S2_MAX_LEVEL) + 1
+	S2_MAX_SIZE    = 1 << S2_MAX_LEVEL
+	S2_NUM_FACES   = 6
+	S2_LOOKUP_BITS = 4
+	S2_SWAP_MASK   = 0x01
+	S2_INVERT_MASK = 0x02
+)
+
+/*
+ * Number of segments each edge of a cell is divided into, since edges are
+ * geodesics, which are curved on most maps.
+ */
+const (
+	S2_EDGE_SEGMENTS = 8
+)
+
+/*
+ * Position of each child within its parent along the Hilbert curve, given
+ * as (i << 1) | j, for each orientation.
+ */
+var s2PosToIJ = [4][4]int{
+	[4]int{0, 1, 3, 2},
+	[4]int{0, 2, 3, 1},
+	[4]int{3, 2, 0, 1},
+	[4]int{3, 1, 0, 2},
+}
+
+/*
+ * Change of orientation of each child along the Hilbert curve.
+ */
+var s2PosToOrientation = [4]int{S2_SWAP_MASK, 0, 0, S2_INVERT_MASK | S2_SWAP_MASK}
+
+/*
+ * Tables converting between positions along the Hilbert curve and (i, j)
+ * coordinates, S2_LOOKUP_BITS at a time.
+ */
+var s2LookupPos [1 << ((2 * S2_LOOKUP_BITS) + 2)]int
+var s2LookupIJ [1 << ((2 * S2_LOOKUP_BITS) + 2)]int
+
+/*
+ * Fill the lookup tables recursively.
+ */
+func initLookupCell(level int, i int, j int, origOrientation int, pos int, orientation int) {
+
+	/*
+	 * Store entry once the required depth is reached.
+	 */
+	if level == S2_LOOKUP_BITS {
+		ij := (i << S2_LOOKUP_BITS) + j
+		s2LookupPos[(ij<<2)+origOrientation] = (pos << 2) + orientation
+		s2LookupIJ[(pos<<2)+origOrientation] = (ij << 2) + orientation
+	} else {
+		level++
+		i <<= 1
+		j <<= 1
+		pos <<= 2
+		r := s2PosToIJ[orientation]
+
+		/*
+		 * Descend into each child.
+		 */
+		for k := 0; k < 4; k++ {
+			initLookupCell(level, i+(r[k]>>1), j+(r[k]&1), origOrientation, pos+k, orientation^s2PosToOrientation[k])
+		}
+
+	}
+
+}
+
+/*
+ * Initialize the lookup tables.
+ */
+func init() {
+	initLookupCell(0, 0, 0, 0, 0, 0)
+	initLookupCell(0, 0, 0, S2_SWAP_MASK, 0, S2_SWAP_MASK)
+	initLookupCell(0, 0, 0, S2_INVERT_MASK, 0, S2_INVERT_MASK)
+	initLookupCell(0, 0, 0, S2_SWAP_MASK|S2_INVERT_MASK, 0, S2_SWAP_MASK|S2_INVERT_MASK)
+}
+
+/*
+ * Returns the lowest set bit of a cell ID.
+ */
+func s2LowestBit(id uint64) uint64 {
+	return id & -id
+}
+
+/*
+ * Returns whether a cell ID denotes a valid S2 cell.
+ */
+func s2Valid(id uint64) bool {
+	face := id >> S2_POS_BITS
+	return (face < S2_NUM_FACES) && ((s2LowestBit(id) & 0x1555555555555555) != 0)
+}
+
+/*
+ * Returns the level of a valid cell ID.
+ */
+func s2Level(id uint64) int {
+	return S2_MAX_LEVEL - (bits.TrailingZeros64(id) >> 1)
+}
+
+/*
+ * Decode a cell ID into its face and the (i, j) coordinates of a leaf cell
+ * within it.
+ */
+func s2FaceIJ(id uint64) (int, int, int) {
+	face := int(id >> S2_POS_BITS)
+	i := 0
+	j := 0
+	orientation := face & S2_SWAP_MASK
+	numBits := S2_MAX_LEVEL - (7 * S2_LOOKUP_BITS)
+
+	/*
+	 * Decode S2_LOOKUP_BITS of each coordinate at a time.
+	 */
+	for k := 7; k >= 0; k-- {
+		shift := uint((k * 2 * S2_LOOKUP_BITS) + 1)
+		orientation += (int(id>>shift) & ((1 << uint(2*numBits)) - 1)) << 2
+		orientation = s2LookupIJ[orientation]
+		offset := uint(k * S2_LOOKUP_BITS)
+		i += (orientation >> (S2_LOOKUP_BITS + 2)) << offset
+		j += ((orientation >> 2) & ((1 << S2_LOOKUP_BITS) - 1)) << offset
+		orientation &= S2_SWAP_MASK | S2_INVERT_MASK
+		numBits = S2_LOOKUP_BITS
+	}
+
+	return face, i, j
+}
+
+/*
+ * Encode the face and the (i, j) coordinates of a leaf cell into a cell ID.
+ */
+func s2FromFaceIJ(face int, i int, j int) uint64 {
+	n := uint64(face) << (S2_POS_BITS - 1)
+	b := face & S2_SWAP_MASK
+	mask := (1 << S2_LOOKUP_BITS) - 1
+
+	/*
+	 * Encode S2_LOOKUP_BITS of each coordinate at a time.
+	 */
+	for k := 7; k >= 0; k-- {
+		offset := uint(k * S2_LOOKUP_BITS)
+		b += ((i >> offset) & mask) << (S2_LOOKUP_BITS + 2)
+		b += ((j >> offset) & mask) << 2
+		b = s2LookupPos[b]
+		n |= uint64(b>>2) << (uint(k) * 2 * S2_LOOKUP_BITS)
+		b &= S2_SWAP_MASK | S2_INVERT_MASK
+	}
+
+	return (n * 2) + 1
+}
+
+/*
+ * Returns the ancestor of a cell ID at a certain level.
+ */
+func s2Parent(id uint64, level int) uint64 {
+	lsb := uint64(1) << uint(2*(S2_MAX_LEVEL-level))
+	return (id & -lsb) | lsb
+}
+
+/*
+ * Convert an s- or t-coordinate into a u- or v-coordinate using the
+ * quadratic transform.
+ */
+func s2STToUV(s float64) float64 {
+
+	/*
+	 * The transform is symmetric around the center of the face.
+	 */
+	if s >= 0.5 {
+		return (1.0 / 3.0) * ((4.0 * s * s) - 1.0)
+	} else {
+		return (1.0 / 3.0) * (1.0 - (4.0 * (1.0 - s) * (1.0 - s)))
+	}
+
+}
+
+/*
+ * Convert a u- or v-coordinate into an s- or t-coordinate using the
+ * quadratic transform.
+ */
+func s2UVToST(u float64) float64 {
+
+	/*
+	 * The transform is symmetric around the center of the face.
+	 */
+	if u >= 0.0 {
+		return 0.5 * math.Sqrt(1.0+(3.0*u))
+	} else {
+		return 1.0 - (0.5 * math.Sqrt(1.0-(3.0*u)))
+	}
+
+}
+
+/*
+ * Convert an s- or t-coordinate into an i- or j-coordinate of a leaf cell.
+ */
+func s2STToIJ(s float64) int {
+	value := math.Floor(S2_MAX_SIZE * s)
+
+	/*
+	 * Clamp coordinate into valid range.
+	 */
+	if !(value > 0.0) {
+		return 0
+	} else if value >= (S2_MAX_SIZE - 1) {
+		return S2_MAX_SIZE - 1
+	} else {
+		return int(value)
+	}
+
+}
+
+/*
+ * Convert a point on a face into a point in space.
+ */
+func s2FaceUVToXYZ(face int, u float64, v float64) (float64, float64, float64) {
+
+	/*
+	 * Each face has its own orientation.
+	 */
+	switch face {
+	case 0:
+		return 1.0, u, v
+	case 1:
+		return -u, 1.0, v
+	case 2:
+		return -u, -v, 1.0
+	case 3:
+		return -1.0, -v, -u
+	case 4:
+		return v, -1.0, -u
+	default:
+		return v, u, -1.0
+	}
+
+}
+
+/*
+ * Convert a point in space into a face and a point on it.
+ */
+func s2XYZToFaceUV(x float64, y float64, z float64) (int, float64, float64) {
+	absX := math.Abs(x)
+	absY := math.Abs(y)
+	absZ := math.Abs(z)
+	face := 2
+	value := z
+
+	/*
+	 * The face is determined by the largest component.
+	 */
+	if absX > absY {
+
+		/*
+		 * Check if x-component is the largest.
+		 */
+		if absX > absZ {
+			face = 0
+			value = x
+		}
+
+	} else if absY > absZ {
+		face = 1
+		value = y
+	}
+
+	/*
+	 * Negative components select the opposite face.
+	 */
+	if value < 0.0 {
+		face += 3
+	}
+
+	/*
+	 * Each face has its own orientation.
+	 */
+	switch face {
+	case 0:
+		return face, y / x, z / x
+	case 1:
+		return face, -x / y, z / y
+	case 2:
+		return face, -x / z, -y / z
+	case 3:
+		return face, z / x, y / x
+	case 4:
+		return face, z / y, -x / y
+	default:
+		return face, -y / z, -x / z
+	}
+
+}
+
+/*
+ * Convert a point on a face into a geographic location.
+ */
+func s2FaceUVToGeographic(face int, u float64, v float64) coordinates.Geographic {
+	x, y, z := s2FaceUVToXYZ(face, u, v)
+	latitude := math.Atan2(z, math.Hypot(x, y))
+	longitude := math.Atan2(y, x)
+	return coordinates.CreateGeographic(longitude, latitude)
+}
+
+/*
+ * Data structure representing the S2 cell system at a certain level.
+ */
+type s2Struct struct {
+	level int
+}
+
+/*
+ * Returns the boundary of an S2 cell of any level.
+ */
+func (this *s2Struct) Boundary(id uint64) ([]coordinates.Geographic, error) {
+
+	/*
+	 * Check if cell ID is valid.
+	 */
+	if !s2Valid(id) {
+		return nil, fmt.Errorf("Invalid S2 cell ID: %d", id)
+	} else {
+		level := s2Level(id)
+		face, i, j := s2FaceIJ(id)
+		size := 1 << uint(S2_MAX_LEVEL-level)
+		iLow := i & -size
+		jLow := j & -size
+		uLow := s2STToUV(float64(iLow) / S2_MAX_SIZE)
+		uHigh := s2STToUV(float64(iLow+size) / S2_MAX_SIZE)
+		vLow := s2STToUV(float64(jLow) / S2_MAX_SIZE)
+		vHigh := s2STToUV(float64(jLow+size) / S2_MAX_SIZE)
+		corners := [5][2]float64{
+			[2]float64{uLow, vLow},
+			[2]float64{uHigh, vLow},
+			[2]float64{uHigh, vHigh},
+			[2]float64{uLow, vHigh},
+			[2]float64{uLow, vLow},
+		}
+
+		boundary := make([]coordinates.Geographic, 0, 4*S2_EDGE_SEGMENTS)
+
+		/*
+		 * Edges are straight lines on the face, so interpolating linearly
+		 * yields points along the geodesics.
+		 */
+		for k := 0; k < 4; k++ {
+			start := corners[k]
+			end := corners[k+1]
+
+			/*
+			 * Subdivide each edge.
+			 */
+			for n := 0; n < S2_EDGE_SEGMENTS; n++ {
+				f := float64(n) / S2_EDGE_SEGMENTS
+				u := start[0] + (f * (end[0] - start[0]))
+				v := start[1] + (f * (end[1] - start[1]))
+				location := s2FaceUVToGeographic(face, u, v)
+				boundary = append(boundary, location)
+			}
+
+		}
+
+		return boundary, nil
+	}
+
+}
+
+/*
+ * Returns the ID of the S2 cell at the configured level containing a
+ * location.
+ */
+func (this *s2Struct) Cell(location coordinates.Geographic) (uint64, error) {
+	longitude := location.Longitude()
+	latitude := location.Latitude()
+
+	/*
+	 * Check if location is valid.
+	 */
+	if math.IsNaN(longitude) || math.IsNaN(latitude) || math.IsInf(longitude, 0) || math.IsInf(latitude, 0) {
+		return 0, fmt.Errorf("%s", "Location is invalid.")
+	} else {
+		cosLatitude := math.Cos(latitude)
+		x := cosLatitude * math.Cos(longitude)
+		y := cosLatitude * math.Sin(longitude)
+		z := math.Sin(latitude)
+		face, u, v := s2XYZToFaceUV(x, y, z)
+		i := s2STToIJ(s2UVToST(u))
+		j := s2STToIJ(s2UVToST(v))
+		id := s2FromFaceIJ(face, i, j)
+		return s2Parent(id, this.level), nil
+	}
+
+}
+
+/*
+ * Create the S2 cell system, which maps locations to cells at a level
+ * between 0 (the six faces of the cube) and S2_MAX_LEVEL (about 1 cm).
+ *
+ * The boundaries of cells of all levels are available.
+ */
+func S2(level int) (System, error) {
+
+	/*
+	 * Check if level is valid.
+	 */
+	if (level < 0) || (level > S2_MAX_LEVEL) {
+		return nil, fmt.Errorf("S2 level must be between 0 and %d, but is %d.", S2_MAX_LEVEL, level)
+	} else {
+
+		/*
+		 * Create S2 data structure.
+		 */
+		s2 := s2Struct{
+			level: level,
+		}
+
+		return &s2, nil
+	}
+
+}
+
+/*
+ * Convert an S2 cell ID into its token, i. e. its hexadecimal
+ * representation without trailing zeros, as used by many tools.
+ */
+func S2Token(id uint64) string {
+
+	/*
+	 * The token of the invalid ID zero is "X".
+	 */
+	if id == 0 {
+		return "X"
+	} else {
+		hex := fmt.Sprintf("%016x", id)
+		return strings.TrimRight(hex, "0")
+	}
+
+}
+
+/*
+ * Parse an S2 cell token into a cell ID.
+ */
+func ParseS2Token(token string) (uint64, error) {
+	length := len(token)
+
+	/*
+	 * Check if token has a valid length.
+	 */
+	if (length == 0) || (length > 16) {
+		return 0, fmt.Errorf("Invalid S2 token: '%s'", token)
+	} else {
+		padded := token + strings.Repeat("0", 16-length)
+		id, err := strconv.ParseUint(padded, 16, 64)
+
+		/*
+		 * Check if token could be parsed.
+		 */
+		if err != nil {
+			return 0, fmt.Errorf("Invalid S2 token: '%s'", token)
+		} else {
+			return id, nil
+		}
+
+	}
+
+}
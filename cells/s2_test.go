@@ -0,0 +1,152 @@
+package cells
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"testing"
+)
+
+/*
+ * Map locations to known cells.
+ */
+func TestS2Cell(t *testing.T) {
+
+	/*
+	 * Locations with known cells.
+	 */
+	cases := []struct {
+		longitude float64
+		latitude  float64
+		level     int
+		token     string
+	}{
+		{0.0, 0.0, 0, "1"},
+		{90.0, 0.0, 0, "3"},
+		{0.0, 90.0, 0, "5"},
+		{180.0, 0.0, 0, "7"},
+		{-90.0, 0.0, 0, "9"},
+		{0.0, -90.0, 0, "b"},
+		{0.0, 0.0, S2_MAX_LEVEL, "1000000000000001"},
+	}
+
+	/*
+	 * Compare each cell with the known one.
+	 */
+	for _, c := range cases {
+		sys, err := S2(c.level)
+
+		/*
+		 * Check if cell system could be created.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		location := coordinates.CreateGeographicDegrees(c.longitude, c.latitude)
+		id, err := sys.Cell(location)
+
+		/*
+		 * Check if cell matches.
+		 */
+		if err != nil {
+			t.Errorf("%s", err.Error())
+		} else if S2Token(id) != c.token {
+			t.Errorf("(%f, %f) lies in cell %s, expected %s", c.longitude, c.latitude, S2Token(id), c.token)
+		}
+
+	}
+
+}
+
+/*
+ * The corners of a face lie at the corners of the cube.
+ */
+func TestS2Boundary(t *testing.T) {
+	sys, _ := S2(0)
+	id, _ := ParseS2Token("1")
+	boundary, err := sys.Boundary(id)
+	corner := math.Atan(1.0/math.Sqrt2) * coordinates.DEGREES_PER_RADIAN
+
+	/*
+	 * The corners of face 0 (longitude, latitude in degrees).
+	 */
+	expected := [][2]float64{
+		[2]float64{-45.0, -corner},
+		[2]float64{45.0, -corner},
+		[2]float64{45.0, corner},
+		[2]float64{-45.0, corner},
+	}
+
+	/*
+	 * Check if boundary has the expected number of points.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	} else if len(boundary) != 4*S2_EDGE_SEGMENTS {
+		t.Fatalf("Boundary has %d points, expected %d.", len(boundary), 4*S2_EDGE_SEGMENTS)
+	}
+
+	/*
+	 * Each edge starts at a corner.
+	 */
+	for i, vertex := range expected {
+		location := boundary[i*S2_EDGE_SEGMENTS]
+		longitude := location.LongitudeDegrees()
+		latitude := location.LatitudeDegrees()
+
+		/*
+		 * Check if corner matches.
+		 */
+		if (math.Abs(longitude-vertex[0]) > 1e-9) || (math.Abs(latitude-vertex[1]) > 1e-9) {
+			t.Errorf("Corner %d is (%.12f, %.12f), expected (%.12f, %.12f)", i, longitude, latitude, vertex[0], vertex[1])
+		}
+
+	}
+
+}
+
+/*
+ * Tokens are converted to cell IDs and back.
+ */
+func TestS2Token(t *testing.T) {
+	tokens := []string{"1", "89c25", "89c259c4", "b", "1000000000000001"}
+
+	/*
+	 * Convert each token back and forth.
+	 */
+	for _, token := range tokens {
+		id, err := ParseS2Token(token)
+
+		/*
+		 * Check if token could be parsed.
+		 */
+		if err != nil {
+			t.Errorf("%s", err.Error())
+		} else if S2Token(id) != token {
+			t.Errorf("Token %s converted to %s.", token, S2Token(id))
+		}
+
+	}
+
+	/*
+	 * The invalid ID zero has a special token.
+	 */
+	if S2Token(0) != "X" {
+		t.Errorf("Token of zero is %s, expected X.", S2Token(0))
+	}
+
+	/*
+	 * Check that invalid tokens are rejected.
+	 */
+	if _, err := ParseS2Token("not a token"); err == nil {
+		t.Errorf("%s", "Expected an error for an invalid token.")
+	}
+
+	/*
+	 * Check that invalid levels are rejected.
+	 */
+	if _, err := S2(S2_MAX_LEVEL + 1); err == nil {
+		t.Errorf("%s", "Expected an error for an invalid level.")
+	}
+
+}
@@ -0,0 +1,136 @@
+package color
+
+import (
+	"image/color"
+)
+
+/*
+ * The colors of the Okabe-Ito palette, which remain distinguishable for
+ * viewers with all common forms of color vision deficiency.
+ */
+var okabeIto = []color.NRGBA{
+	{R: 0, G: 0, B: 0, A: 255},
+	{R: 230, G: 159, B: 0, A: 255},
+	{R: 86, G: 180, B: 233, A: 255},
+	{R: 0, G: 158, B: 115, A: 255},
+	{R: 240, G: 228, B: 66, A: 255},
+	{R: 0, G: 114, B: 178, A: 255},
+	{R: 213, G: 94, B: 0, A: 255},
+	{R: 204, G: 121, B: 167, A: 255},
+}
+
+/*
+ * Indices of individual colors within the Okabe-Ito palette.
+ */
+const (
+	okabeItoSkyBlue    = 2
+	okabeItoYellow     = 4
+	okabeItoBlue       = 5
+	okabeItoVermillion = 6
+)
+
+/*
+ * Control points of the cividis color map.
+ */
+var cividisColors = []color.NRGBA{
+	{R: 0, G: 34, B: 78, A: 255},
+	{R: 65, G: 77, B: 107, A: 255},
+	{R: 124, G: 123, B: 120, A: 255},
+	{R: 188, G: 175, B: 111, A: 255},
+	{R: 254, G: 232, B: 56, A: 255},
+}
+
+/*
+ * Create stops spacing a series of colors evenly from zero to one.
+ */
+func evenStops(colors []color.NRGBA) []Stop {
+	numColors := len(colors)
+	stops := make([]Stop, numColors)
+	last := float64(numColors - 1)
+
+	/*
+	 * Create a stop for each color.
+	 */
+	for i, c := range colors {
+		pos := float64(0.0)
+
+		/*
+		 * Avoid division by zero.
+		 */
+		if last > 0.0 {
+			pos = float64(i) / last
+		}
+
+		stops[i] = CreateStop(pos, c)
+	}
+
+	return stops
+}
+
+/*
+ * Returns the eight colors of the Okabe-Ito palette (black, orange, sky blue,
+ * bluish green, yellow, blue, vermillion and reddish purple), which remain
+ * distinguishable for viewers with all common forms of color vision
+ * deficiency. This is well-suited for categorical data.
+ */
+func OkabeItoPalette() []color.NRGBA {
+	numColors := len(okabeIto)
+	result := make([]color.NRGBA, numColors)
+	copy(result, okabeIto)
+	return result
+}
+
+/*
+ * Create a new cividis color mapping.
+ *
+ * Cividis ranges from dark blue over gray to yellow. It is perceptually
+ * uniform and was optimized to appear nearly identical to viewers with and
+ * without red-green color vision deficiency.
+ */
+func CividisMapping(options ...Option) Mapping {
+	stops := evenStops(cividisColors)
+	return GradientMapping(stops, options...)
+}
+
+/*
+ * Create a new sequential color mapping derived from the Okabe-Ito palette,
+ * ranging from blue over sky blue to yellow, which is safe for viewers with
+ * color vision deficiency.
+ */
+func OkabeItoMapping(options ...Option) Mapping {
+
+	/*
+	 * Colors of the ramp.
+	 */
+	colors := []color.NRGBA{
+		okabeIto[okabeItoBlue],
+		okabeIto[okabeItoSkyBlue],
+		okabeIto[okabeItoYellow],
+	}
+
+	stops := evenStops(colors)
+	return GradientMapping(stops, options...)
+}
+
+/*
+ * Create a new diverging color mapping for signed data derived from the
+ * Okabe-Ito palette, ranging from blue (below the center) over white (at the
+ * center) to vermillion (above the center), which is safe for viewers with
+ * color vision deficiency.
+ */
+func OkabeItoDivergingMapping(center int64, options ...Option) SignedMapping {
+	low := okabeIto[okabeItoBlue]
+	high := okabeIto[okabeItoVermillion]
+
+	/*
+	 * Color for values at the center.
+	 */
+	middle := color.NRGBA{
+		R: 247,
+		G: 247,
+		B: 247,
+		A: 255,
+	}
+
+	return DivergingMapping(low, middle, high, center, options...)
+}
@@ -0,0 +1,276 @@
+package color
+
+import (
+	"image/color"
+	"sync"
+)
+
+/*
+ * Statistics describe a distribution, which is mapped in chunks, so that
+ * each chunk is mapped consistently.
+ */
+type Statistics interface {
+	Add(counts []uint64)
+	Max() uint64
+}
+
+/*
+ * Maps a distribution to a series of colors one chunk (e. g. one row of a
+ * scene) at a time, given statistics of the entire distribution.
+ *
+ * This bounds memory use for very large distributions, since only the colors
+ * of a single chunk have to be held at a time.
+ */
+type ChunkedMapping interface {
+	MapChunk(dst []color.NRGBA, counts []uint64, stats Statistics) error
+}
+
+/*
+ * Data structure representing statistics of a distribution.
+ *
+ * Adaptive scales are fitted to the histogram once and cached, since fitting
+ * sorts the entire histogram.
+ */
+type statisticsStruct struct {
+	fitted    map[histogramScale]Scale
+	histogram map[uint64]uint64
+	max       uint64
+	mutex     sync.Mutex
+}
+
+/*
+ * Add a chunk of the distribution to the statistics.
+ */
+func (this *statisticsStruct) Add(counts []uint64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	max := this.max
+
+	/*
+	 * Iterate over the chunk.
+	 */
+	for _, count := range counts {
+
+		/*
+		 * If we found a larger value, make this the new maximum.
+		 */
+		if count > max {
+			max = count
+		}
+
+		/*
+		 * Count non-empty bins.
+		 */
+		if count > 0 {
+			this.histogram[count]++
+		}
+
+	}
+
+	this.max = max
+
+	/*
+	 * Scales fitted to the previous distribution are outdated.
+	 */
+	if len(this.fitted) > 0 {
+		this.fitted = make(map[histogramScale]Scale)
+	}
+
+}
+
+/*
+ * Fit an adaptive scale provided by this package to the distribution.
+ *
+ * Each scale is only fitted once, until further chunks are added. Other
+ * scales are returned unchanged.
+ */
+func (this *statisticsStruct) fit(scale Scale) Scale {
+	h, ok := scale.(histogramScale)
+
+	/*
+	 * Fit scales which support histograms.
+	 */
+	if !ok {
+		return scale
+	} else {
+		this.mutex.Lock()
+		defer this.mutex.Unlock()
+		fitted, ok := this.fitted[h]
+
+		/*
+		 * Fit scale if it was not fitted before.
+		 */
+		if !ok {
+			fitted = h.fitHistogram(this.histogram)
+			this.fitted[h] = fitted
+		}
+
+		return fitted
+	}
+
+}
+
+/*
+ * Returns the maximum count of the distribution.
+ */
+func (this *statisticsStruct) Max() uint64 {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.max
+}
+
+/*
+ * Map a chunk of counts to color values, storing the colors in dst, which
+ * must have the same length as counts.
+ */
+func (this *simpleMappingStruct) MapChunk(dst []color.NRGBA, counts []uint64, stats Statistics) error {
+	return this.MapInto(dst, counts)
+}
+
+/*
+ * Map a chunk of counts to color values, storing the colors in dst, which
+ * must have the same length as counts.
+ *
+ * Unless the maximum is fixed, it is taken from the statistics. The adaptive
+ * scales of this package are fitted to the statistics, if they were created
+ * using CreateStatistics.
+ */
+func (this *rampMappingStruct) MapChunk(dst []color.NRGBA, counts []uint64, stats Statistics) error {
+	err := checkLength(dst, counts)
+
+	/*
+	 * Check if colors fit into destination.
+	 */
+	if err != nil {
+		return err
+	} else {
+		max := this.max
+
+		/*
+		 * Take the maximum from the statistics unless it is fixed.
+		 */
+		if (max == 0) && (stats != nil) {
+			max = stats.Max()
+		}
+
+		scale := this.scale
+		s, ok := stats.(*statisticsStruct)
+
+		/*
+		 * Fit adaptive scales to the statistics.
+		 */
+		if ok {
+			scale = s.fit(scale)
+		}
+
+		/*
+		 * Map each count in the chunk to a color value.
+		 */
+		for i, count := range counts {
+
+			/*
+			 * Empty bins and bins below the minimum count map
+			 * to the background color.
+			 */
+			if this.visible(count) {
+				c := this.color(scale, count, max)
+				dst[i] = c.nrgba()
+			} else {
+				dst[i] = this.background
+			}
+
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Create new, empty statistics of a distribution.
+ *
+ * Add each chunk of the distribution before mapping any of them.
+ */
+func CreateStatistics() Statistics {
+
+	/*
+	 * Create statistics.
+	 */
+	s := statisticsStruct{
+		fitted:    make(map[histogramScale]Scale),
+		histogram: make(map[uint64]uint64),
+	}
+
+	return &s
+}
+
+/*
+ * Data structure representing a mapping, which maps each distribution given
+ * statistics shared by a series of distributions.
+ */
+type sharedMappingStruct struct {
+	mapping ChunkedMapping
+	stats   Statistics
+}
+
+/*
+ * Map each count to a color value.
+ *
+ * Returns nil if the counts could not be mapped.
+ */
+func (this *sharedMappingStruct) Map(counts []uint64) []color.NRGBA {
+	n := len(counts)
+	colors := make([]color.NRGBA, n)
+	err := this.MapInto(colors, counts)
+
+	/*
+	 * Check if colors could be mapped.
+	 */
+	if err != nil {
+		return nil
+	} else {
+		return colors
+	}
+
+}
+
+/*
+ * Map each count to a color value, storing the colors in dst, which must have
+ * the same length as counts.
+ */
+func (this *sharedMappingStruct) MapInto(dst []color.NRGBA, counts []uint64) error {
+	return this.mapping.MapChunk(dst, counts, this.stats)
+}
+
+/*
+ * Create a mapping, which maps each distribution using statistics of a
+ * series of distributions, e. g. the frames of an animation, instead of
+ * statistics of the distribution itself.
+ *
+ * Add all distributions of the series to the statistics first, so that the
+ * maximum and adaptive scales are shared and identical counts map to
+ * identical colors in every distribution. Mappings which do not implement
+ * ChunkedMapping are returned unchanged.
+ */
+func Shared(mapping Mapping, stats Statistics) Mapping {
+	chunked, ok := mapping.(ChunkedMapping)
+
+	/*
+	 * Only chunked mappings can make use of the statistics.
+	 */
+	if !ok {
+		return mapping
+	} else {
+
+		/*
+		 * Create shared mapping.
+		 */
+		m := sharedMappingStruct{
+			mapping: chunked,
+			stats:   stats,
+		}
+
+		return &m
+	}
+
+}
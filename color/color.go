@@ -1,8 +1,10 @@
 package color
 
 import (
+	"fmt"
 	"image/color"
 	"math"
+	"sort"
 )
 
 /*
@@ -12,6 +14,34 @@ type Mapping interface {
 	Map(counts []uint64) []color.NRGBA
 }
 
+/*
+ * Maps a distribution, which forms a rectangle of bins with the given width
+ * stored in row-major order, like the bins of a scene, to a series of colors.
+ *
+ * Mappings whose colors depend on neighbouring bins implement this in
+ * addition to Mapping. Scenes use it instead of Map whenever a mapping
+ * implements it.
+ */
+type GridMapping interface {
+	MapGrid(counts []uint64, width uint32) []color.NRGBA
+}
+
+/*
+ * Maps a distribution to a series of colors stored in a caller-provided
+ * slice, which may be reused across renders to avoid allocations.
+ */
+type BufferedMapping interface {
+	MapInto(dst []color.NRGBA, counts []uint64) error
+}
+
+/*
+ * Maps a distribution to a series of 16-bit colors, which avoids banding of
+ * smooth gradients.
+ */
+type Mapping16 interface {
+	Map16(counts []uint64) []color.NRGBA64
+}
+
 /*
  * Restricts a value to an interval, so that min <= value <= max.
  */
@@ -30,6 +60,24 @@ func clamp(value float64, min float64, max float64) float64 {
 
 }
 
+/*
+ * Verify that a destination slice can hold the colors of a distribution.
+ */
+func checkLength(dst []color.NRGBA, counts []uint64) error {
+	numColors := len(dst)
+	numCounts := len(counts)
+
+	/*
+	 * Verify that lengths match.
+	 */
+	if numColors != numCounts {
+		return fmt.Errorf("Destination holds %d colors, but distribution has %d counts.", numColors, numCounts)
+	} else {
+		return nil
+	}
+
+}
+
 /*
  * Data structure representing a simple color mapping.
  */
@@ -38,18 +86,82 @@ type simpleMappingStruct struct {
 }
 
 /*
- * Data structure representing the default color mapping.
+ * Data structure representing a color mapping, which maps each count to an
+ * intensity using a scale and each intensity to a color using a color ramp.
  */
-type defaultMappingStruct struct {
+type rampMappingStruct struct {
+	background    color.NRGBA
+	breaks        []uint64
+	classes       uint32
+	interpolation uint8
+	max           uint64
+	min           uint64
+	minOpacity    float64
+	opacity       bool
+	ramp          rampFunc
+	scale         Scale
 }
 
+/*
+ * An option configures a color mapping.
+ */
+type Option func(mapping *rampMappingStruct)
+
 /*
  * Map each count to a color value.
  */
 func (this *simpleMappingStruct) Map(counts []uint64) []color.NRGBA {
 	n := len(counts)
 	colors := make([]color.NRGBA, n)
+	this.MapInto(colors, counts)
+	return colors
+}
+
+/*
+ * Map each count to a color value, storing the colors in dst, which must have
+ * the same length as counts.
+ */
+func (this *simpleMappingStruct) MapInto(dst []color.NRGBA, counts []uint64) error {
+	err := checkLength(dst, counts)
+
+	/*
+	 * Check if colors fit into destination.
+	 */
+	if err != nil {
+		return err
+	} else {
+		fg := this.foreground
+
+		/*
+		 * Map each count in the distribution to a color value.
+		 */
+		for i, count := range counts {
+
+			/*
+			 * Check if there are dots in this cell.
+			 */
+			if count > 0 {
+				dst[i] = fg
+			} else {
+				dst[i] = color.NRGBA{}
+			}
+
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Map each count to a 16-bit color value.
+ */
+func (this *simpleMappingStruct) Map16(counts []uint64) []color.NRGBA64 {
+	n := len(counts)
+	colors := make([]color.NRGBA64, n)
 	fg := this.foreground
+	c := fromNRGBA(fg)
+	fg64 := c.nrgba64()
 
 	/*
 	 * Map each count in the distribution to a color value.
@@ -60,7 +172,7 @@ func (this *simpleMappingStruct) Map(counts []uint64) []color.NRGBA {
 		 * Check if there are dots in this cell.
 		 */
 		if count > 0 {
-			colors[i] = fg
+			colors[i] = fg64
 		}
 
 	}
@@ -69,92 +181,205 @@ func (this *simpleMappingStruct) Map(counts []uint64) []color.NRGBA {
 }
 
 /*
- * Map each count to a color value.
+ * Quantize an intensity into discrete classes, if the mapping is configured
+ * to do so.
  */
-func (this *defaultMappingStruct) Map(counts []uint64) []color.NRGBA {
-	max := uint64(0)
+func (this *rampMappingStruct) quantize(intensity float64, count uint64) float64 {
+	breaks := this.breaks
+	numBreaks := len(breaks)
+	classes := this.classes
 
 	/*
-	 * Iterate over the distribution.
+	 * Decide on the kind of quantization.
 	 */
-	for _, count := range counts {
+	if numBreaks > 0 {
 
 		/*
-		 * If we found a larger value, make this the new maximum.
+		 * Find the number of breaks not exceeding the count.
 		 */
-		if count > max {
-			max = count
+		class := sort.Search(numBreaks, func(i int) bool {
+			return breaks[i] > count
+		})
+
+		classFloat := float64(class)
+		numBreaksFloat := float64(numBreaks)
+		return classFloat / numBreaksFloat
+	} else if classes == 1 {
+		return 1.0
+	} else if classes > 1 {
+		classesFloat := float64(classes)
+		class := math.Floor(intensity * classesFloat)
+		class = clamp(class, 0.0, classesFloat-1.0)
+		return class / (classesFloat - 1.0)
+	} else {
+		return intensity
+	}
+
+}
+
+/*
+ * Returns the maximum count used for scaling a distribution, which is either
+ * fixed or derived from the distribution.
+ */
+func (this *rampMappingStruct) maximum(counts []uint64) uint64 {
+	max := this.max
+
+	/*
+	 * Derive the maximum from the distribution unless it is fixed.
+	 */
+	if max == 0 {
+
+		/*
+		 * Iterate over the distribution.
+		 */
+		for _, count := range counts {
+
+			/*
+			 * If we found a larger value, make this the new maximum.
+			 */
+			if count > max {
+				max = count
+			}
+
 		}
 
 	}
 
-	maxFloat := float64(max)
-	maxLog := math.Log(maxFloat)
+	return max
+}
+
+/*
+ * Check whether a count is visible, i. e. non-zero and not below the minimum
+ * count of the mapping.
+ */
+func (this *rampMappingStruct) visible(count uint64) bool {
+	return (count > 0) && (count >= this.min)
+}
+
+/*
+ * Returns the scale used for mapping a distribution, which is fitted to the
+ * distribution if the scale of the mapping is adaptive.
+ */
+func (this *rampMappingStruct) fit(counts []uint64) Scale {
+	scale := this.scale
+	adaptive, ok := scale.(AdaptiveScale)
+
+	/*
+	 * Fit adaptive scales to the distribution.
+	 */
+	if ok {
+		scale = adaptive.Fit(counts)
+	}
+
+	return scale
+}
+
+/*
+ * Map a single count to a floating-point color, given the scale and the
+ * maximum count.
+ *
+ * Empty bins map to transparent.
+ */
+func (this *rampMappingStruct) color(scale Scale, count uint64, max uint64) rgba {
+
+	/*
+	 * Empty bins remain transparent.
+	 */
+	if count == 0 {
+		return rgba{}
+	} else {
+		frac := scale.Intensity(count, max)
+		frac = clamp(frac, 0.0, 1.0)
+		frac = this.quantize(frac, count)
+		c := this.ramp(frac)
+
+		/*
+		 * Fade in colors if an opacity ramp is configured.
+		 */
+		if this.opacity {
+			minOpacity := this.minOpacity
+			c.a *= minOpacity + ((1.0 - minOpacity) * frac)
+		}
+
+		return c
+	}
+
+}
+
+/*
+ * Map each count to a color value.
+ */
+func (this *rampMappingStruct) Map(counts []uint64) []color.NRGBA {
 	n := len(counts)
 	colors := make([]color.NRGBA, n)
+	this.MapInto(colors, counts)
+	return colors
+}
+
+/*
+ * Map each count to a color value, storing the colors in dst, which must have
+ * the same length as counts.
+ */
+func (this *rampMappingStruct) MapInto(dst []color.NRGBA, counts []uint64) error {
+	err := checkLength(dst, counts)
 
 	/*
-	 * Map each count in the distribution to a color value.
+	 * Check if colors fit into destination.
 	 */
-	for i, count := range counts {
-		countFloat := float64(count)
-		countLog := math.Log(countFloat)
+	if err != nil {
+		return err
+	} else {
+		max := this.maximum(counts)
+		scale := this.fit(counts)
 
 		/*
-		 * If the logarithm is finite, map to color scale.
+		 * Map each count in the distribution to a color value.
 		 */
-		if !math.IsInf(countLog, 0) {
-			frac := countLog / maxLog
-			redFloat := float64(0.0)
-			greenFloat := float64(0.0)
-			blueFloat := float64(0.0)
+		for i, count := range counts {
 
 			/*
-			 * Map to a color.
+			 * Empty bins and bins below the minimum count map to the
+			 * background color.
 			 */
-			if frac <= 0.25 {
-				diff := frac - 0.0
-				greenFloat = 4.0 * diff
-				blueFloat = 1.0
-			} else if frac <= 0.5 {
-				diff := frac - 0.25
-				greenFloat = 1.0
-				blueFloat = 1.0 - (4.0 * diff)
-			} else if frac <= 0.75 {
-				diff := frac - 0.5
-				redFloat = 4.0 * diff
-				greenFloat = 1.0
-			} else if frac <= 1.0 {
-				diff := frac - 0.75
-				redFloat = 1.0
-				greenFloat = 1.0
-				blueFloat = 4.0 * diff
+			if this.visible(count) {
+				c := this.color(scale, count, max)
+				dst[i] = c.nrgba()
 			} else {
-				redFloat = 1.0
-				greenFloat = 1.0
-				blueFloat = 1.0
+				dst[i] = this.background
 			}
 
-			redFloat = math.Round(255.0 * redFloat)
-			greenFloat = math.Round(255.0 * greenFloat)
-			blueFloat = math.Round(255.0 * blueFloat)
-			redFloat = clamp(redFloat, 0.0, 255.0)
-			greenFloat = clamp(greenFloat, 0.0, 255.0)
-			blueFloat = clamp(blueFloat, 0.0, 255.0)
-			red := uint8(redFloat)
-			green := uint8(greenFloat)
-			blue := uint8(blueFloat)
+		}
 
-			/*
-			 * The resulting color.
-			 */
-			colors[i] = color.NRGBA{
-				R: red,
-				G: green,
-				B: blue,
-				A: 255,
-			}
+		return nil
+	}
+
+}
 
+/*
+ * Map each count to a 16-bit color value.
+ */
+func (this *rampMappingStruct) Map16(counts []uint64) []color.NRGBA64 {
+	max := this.maximum(counts)
+	scale := this.fit(counts)
+	n := len(counts)
+	colors := make([]color.NRGBA64, n)
+	bg := fromNRGBA(this.background)
+	bg64 := bg.nrgba64()
+
+	/*
+	 * Map each count in the distribution to a color value.
+	 */
+	for i, count := range counts {
+
+		/*
+		 * Empty bins and bins below the minimum count map to the
+		 * background color.
+		 */
+		if this.visible(count) {
+			c := this.color(scale, count, max)
+			colors[i] = c.nrgba64()
+		} else {
+			colors[i] = bg64
 		}
 
 	}
@@ -165,8 +390,13 @@ func (this *defaultMappingStruct) Map(counts []uint64) []color.NRGBA {
 /*
  * Create a new simple color mapping, which maps all cells with hits to a
  * predefined color.
+ *
+ * Without options, the mapping is strictly binary. Options turn it into a
+ * mapping with a constant color ramp, so that e. g. WithOpacityRamp fades in
+ * the color with increasing density or WithBackground sets the color of
+ * empty cells.
  */
-func SimpleMapping(red uint8, green uint8, blue uint8) Mapping {
+func SimpleMapping(red uint8, green uint8, blue uint8, options ...Option) Mapping {
 
 	/*
 	 * Create forground color.
@@ -179,10 +409,232 @@ func SimpleMapping(red uint8, green uint8, blue uint8) Mapping {
 	}
 
 	/*
-	 * Create simple color mapping.
+	 * Check if options are present.
 	 */
-	m := simpleMappingStruct{
-		foreground: c,
+	if len(options) == 0 {
+
+		/*
+		 * Create simple color mapping.
+		 */
+		m := simpleMappingStruct{
+			foreground: c,
+		}
+
+		return &m
+	} else {
+		ramp := solid(c)
+		return createRampMapping(ramp, options)
+	}
+
+}
+
+/*
+ * Create an option, which selects the scale used to map counts to
+ * intensities.
+ *
+ * If scale is nil, the option has no effect.
+ */
+func WithScale(scale Scale) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+
+		/*
+		 * Only apply non-nil scales.
+		 */
+		if scale != nil {
+			mapping.scale = scale
+		}
+
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which fixes the maximum count of the mapping instead of
+ * deriving it from the distribution being mapped.
+ *
+ * Counts of at least max map to full intensity. This makes the colors of a
+ * sequence of rendered frames (e. g. an animation or a before / after
+ * comparison) comparable, since all of them share an identical color scale.
+ * A max of zero restores the default behaviour.
+ */
+func WithMax(max uint64) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+		mapping.max = max
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which sets a minimum count of the mapping.
+ *
+ * Counts below min are treated like empty bins, so that noise below this
+ * floor renders as background. Combine this with WithMax to clip the mapped
+ * range at both ends. A min of zero restores the default behaviour.
+ */
+func WithMin(min uint64) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+		mapping.min = min
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which makes the opacity of the colors of the mapping
+ * increase with intensity, from minOpacity (between zero and one) at the
+ * lowest to fully opaque at the highest intensity.
+ *
+ * Combined with SimpleMapping, this yields a single-color heatmap, whose
+ * opacity increases with (by default, the logarithm of the) density.
+ */
+func WithOpacityRamp(minOpacity float64) Option {
+	minOpacity = clamp(minOpacity, 0.0, 1.0)
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+		mapping.minOpacity = minOpacity
+		mapping.opacity = true
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which quantizes intensities into a number of discrete
+ * classes of equal width instead of mapping them to a continuous gradient.
+ *
+ * The lowest class maps to the lowest and the highest class to the highest
+ * color of the ramp. A number of zero classes restores continuous mapping.
+ */
+func WithClasses(classes uint32) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+		mapping.classes = classes
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which quantizes counts into discrete classes separated by
+ * explicit boundary values, similar to a choropleth map.
+ *
+ * A count belongs to the class after the last break it reaches, so n breaks
+ * define n + 1 classes, which are spread evenly across the color ramp. The
+ * scale of the mapping has no effect on classification. Breaks take
+ * precedence over WithClasses. Passing no breaks restores the default
+ * behaviour.
+ */
+func WithBreaks(breaks []uint64) Option {
+	numBreaks := len(breaks)
+	sorted := make([]uint64, numBreaks)
+	copy(sorted, breaks)
+
+	/*
+	 * Order breaks ascending.
+	 */
+	sort.Slice(sorted, func(i int, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+		mapping.breaks = sorted
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which selects the color space in which gradients are
+ * interpolated.
+ *
+ * Interpolating in a perceptual color space (INTERPOLATION_LAB or
+ * INTERPOLATION_OKLAB) avoids muddy midpoints between saturated colors, while
+ * INTERPOLATION_HSV interpolates hue along the color wheel. The default is
+ * INTERPOLATION_RGB. This option only affects mappings based on stops.
+ */
+func WithInterpolation(space uint8) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+		mapping.interpolation = space
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which maps empty bins to a background color instead of
+ * leaving them transparent.
+ *
+ * This avoids drawing the rendered image over a uniform background. Passing
+ * a fully transparent color restores the default behaviour.
+ */
+func WithBackground(background color.NRGBA) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(mapping *rampMappingStruct) {
+		mapping.background = background
+	}
+
+	return option
+}
+
+/*
+ * Create a new color mapping, which maps counts to a color ramp.
+ *
+ * By default, counts are mapped logarithmically. Options are applied in
+ * order.
+ */
+func createRampMapping(ramp rampFunc, options []Option) Mapping {
+	scale := LogarithmicScale()
+
+	/*
+	 * Create ramp color mapping.
+	 */
+	m := rampMappingStruct{
+		ramp:  ramp,
+		scale: scale,
+	}
+
+	/*
+	 * Apply options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Ignore nil options.
+		 */
+		if option != nil {
+			option(&m)
+		}
+
 	}
 
 	return &m
@@ -190,8 +642,146 @@ func SimpleMapping(red uint8, green uint8, blue uint8) Mapping {
 
 /*
  * Create a new default color mapping.
+ *
+ * The default mapping maps low densities to dark, cold colors and high
+ * densities to bright, hot colors.
  */
-func DefaultMapping() Mapping {
-	m := defaultMappingStruct{}
-	return &m
+func DefaultMapping(options ...Option) Mapping {
+	return createRampMapping(rainbow, options)
+}
+
+/*
+ * Create a new viridis color mapping.
+ *
+ * Viridis is a perceptually uniform color map ranging from dark blue over
+ * green to yellow, which does not distort perceived density.
+ */
+func ViridisMapping(options ...Option) Mapping {
+	return createRampMapping(viridis, options)
+}
+
+/*
+ * Create a new inferno color mapping.
+ *
+ * Inferno is a perceptually uniform color map ranging from black over purple
+ * and red to pale yellow, which provides good contrast for low densities on
+ * dark backgrounds.
+ */
+func InfernoMapping(options ...Option) Mapping {
+	return createRampMapping(inferno, options)
+}
+
+/*
+ * Create a new magma color mapping.
+ *
+ * Magma is a perceptually uniform color map ranging from black over purple
+ * and pink to pale yellow, which provides good contrast for low densities on
+ * dark backgrounds.
+ */
+func MagmaMapping(options ...Option) Mapping {
+	return createRampMapping(magma, options)
+}
+
+/*
+ * Create a new plasma color mapping.
+ *
+ * Plasma is a perceptually uniform color map ranging from dark blue over
+ * magenta and orange to yellow.
+ */
+func PlasmaMapping(options ...Option) Mapping {
+	return createRampMapping(plasma, options)
+}
+
+/*
+ * Create a new cubehelix color mapping.
+ *
+ * Cubehelix ranges from black to white, increasing monotonically in perceived
+ * brightness, so that it also prints well in grayscale. The start parameter
+ * selects the initial hue (0 is blue, 1 is red, 2 is green), rotations is the
+ * number of rotations through the hues, hue controls the saturation and gamma
+ * emphasizes low (gamma < 1) or high (gamma > 1) intensities.
+ *
+ * The classic cubehelix color map uses start = 0.5, rotations = -1.5,
+ * hue = 1.0 and gamma = 1.0.
+ */
+func CubehelixMapping(start float64, rotations float64, hue float64, gamma float64, options ...Option) Mapping {
+	ramp := cubehelix(start, rotations, hue, gamma)
+	return createRampMapping(ramp, options)
+}
+
+/*
+ * Create a new turbo color mapping.
+ *
+ * Turbo is a rainbow color map ranging from dark blue over cyan, green, yellow
+ * and orange to dark red, with smoother and more perceptually even transitions
+ * than the classic jet color map.
+ */
+func TurboMapping(options ...Option) Mapping {
+	return createRampMapping(turbo, options)
+}
+
+/*
+ * Create a new alpha color mapping, which maps all cells with hits to a
+ * predefined color, whose opacity increases with density.
+ *
+ * Low densities map to (almost) transparent and high densities to opaque
+ * pixels, so that the rendered image can be composited over a basemap without
+ * a hard footprint.
+ */
+func AlphaMapping(red uint8, green uint8, blue uint8, options ...Option) Mapping {
+
+	/*
+	 * Create foreground color.
+	 */
+	c := color.NRGBA{
+		R: red,
+		G: green,
+		B: blue,
+		A: 255,
+	}
+
+	ramp := transparency(c)
+	return createRampMapping(ramp, options)
+}
+
+/*
+ * Create a new tinted color mapping, which maps all cells with hits to a
+ * single hue, whose brightness increases with density.
+ *
+ * Low densities map to black and high densities to the predefined color. This
+ * is a middle ground between SimpleMapping, which only distinguishes empty
+ * from non-empty cells, and multi-hued mappings like the default mapping. To
+ * fade in from transparent instead of black, use AlphaMapping.
+ */
+func TintMapping(red uint8, green uint8, blue uint8, options ...Option) Mapping {
+
+	/*
+	 * Create foreground color.
+	 */
+	c := color.NRGBA{
+		R: red,
+		G: green,
+		B: blue,
+		A: 255,
+	}
+
+	/*
+	 * Color for the lowest densities.
+	 */
+	black := color.NRGBA{
+		R: 0,
+		G: 0,
+		B: 0,
+		A: 255,
+	}
+
+	/*
+	 * Stops of the tinted ramp.
+	 */
+	stops := []Stop{
+		CreateStop(0.0, black),
+		CreateStop(1.0, c),
+	}
+
+	return GradientMapping(stops, options...)
 }
@@ -0,0 +1,201 @@
+package color
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+/*
+ * Orientations of a colorbar.
+ */
+const (
+	ORIENTATION_HORIZONTAL = 0
+	ORIENTATION_VERTICAL   = 1
+)
+
+/*
+ * Find the smallest count within [1, max], which a scale maps to at least a
+ * certain intensity.
+ */
+func inverseIntensity(scale Scale, intensity float64, max uint64) uint64 {
+	n := int(max)
+
+	/*
+	 * Search for the first count reaching the intensity.
+	 */
+	idx := sort.Search(n, func(i int) bool {
+		count := uint64(i) + 1
+		frac := scale.Intensity(count, max)
+		return frac >= intensity
+	})
+
+	/*
+	 * Counts beyond the maximum are clamped to the maximum.
+	 */
+	if idx >= n {
+		return max
+	} else {
+		return uint64(idx) + 1
+	}
+
+}
+
+/*
+ * Choose a tick color which contrasts with the color of the bar.
+ */
+func tickColor(c color.NRGBA) color.NRGBA {
+	red := float64(c.R)
+	green := float64(c.G)
+	blue := float64(c.B)
+	alpha := float64(c.A)
+	luminance := (0.299 * red) + (0.587 * green) + (0.114 * blue)
+	luminance = (luminance * alpha) / 255.0
+
+	/*
+	 * Draw dark ticks on bright colors and bright ticks on dark colors.
+	 */
+	if (c.A < 128) || (luminance >= 128.0) {
+		return color.NRGBA{R: 0, G: 0, B: 0, A: 255}
+	} else {
+		return color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+
+}
+
+/*
+ * Render a colorbar explaining the intensity scale of a mapping.
+ *
+ * The colorbar has a size of length times thickness pixels and spans counts
+ * from one to max, mapped using the given scale, which must match the scale
+ * used by the mapping. Pass the maximum count of the rendered scene (or the
+ * value passed to WithMax) as max. If scale is nil, the logarithmic default
+ * scale is assumed. Horizontal colorbars (ORIENTATION_HORIZONTAL) increase
+ * from left to right, vertical colorbars (ORIENTATION_VERTICAL) from bottom
+ * to top.
+ *
+ * For each tick value, a line is drawn across the bar at the position
+ * representing that count, in black or white, depending on which contrasts
+ * better with the bar.
+ */
+func Colorbar(mapping Mapping, scale Scale, max uint64, length uint32, thickness uint32, orientation uint8, ticks []uint64) (*image.NRGBA, error) {
+
+	/*
+	 * Verify parameters.
+	 */
+	if mapping == nil {
+		return nil, fmt.Errorf("%s", "Color mapping must not be nil when rendering a colorbar!")
+	} else if max == 0 {
+		return nil, fmt.Errorf("%s", "Maximum count must be positive when rendering a colorbar!")
+	} else if (length == 0) || (thickness == 0) {
+		return nil, fmt.Errorf("Colorbar size (%d * %d) must be positive.", length, thickness)
+	} else if (orientation != ORIENTATION_HORIZONTAL) && (orientation != ORIENTATION_VERTICAL) {
+		return nil, fmt.Errorf("Unknown colorbar orientation: %d", orientation)
+	} else {
+
+		/*
+		 * Use default scale if none is given.
+		 */
+		if scale == nil {
+			scale = LogarithmicScale()
+		}
+
+		lengthInt := int(length)
+		lengthFloat := float64(length)
+		counts := make([]uint64, lengthInt+1)
+
+		/*
+		 * Find the count represented by the center of each pixel.
+		 */
+		for i := 0; i < lengthInt; i++ {
+			iFloat := float64(i)
+			intensity := (iFloat + 0.5) / lengthFloat
+			counts[i] = inverseIntensity(scale, intensity, max)
+		}
+
+		/*
+		 * Include the maximum count, so that mappings deriving the
+		 * maximum from the distribution scale identically.
+		 */
+		counts[lengthInt] = max
+		colors := mapping.Map(counts)
+		numColors := len(colors)
+
+		/*
+		 * Verify that the color mapping returned a result of the
+		 * expected length.
+		 */
+		if numColors != len(counts) {
+			return nil, fmt.Errorf("Color mapping returned %d colors, but expected %d.", numColors, len(counts))
+		} else {
+			colors = colors[:lengthInt]
+
+			/*
+			 * Draw tick marks.
+			 */
+			for _, tick := range ticks {
+
+				/*
+				 * Only draw ticks within the range of the colorbar.
+				 */
+				if (tick > 0) && (tick <= max) {
+					frac := scale.Intensity(tick, max)
+					frac = clamp(frac, 0.0, 1.0)
+					pos := int(frac * lengthFloat)
+
+					/*
+					 * The highest value is drawn at the last pixel.
+					 */
+					if pos >= lengthInt {
+						pos = lengthInt - 1
+					}
+
+					colors[pos] = tickColor(colors[pos])
+				}
+
+			}
+
+			thicknessInt := int(thickness)
+			rect := image.Rectangle{}
+
+			/*
+			 * Decide on the dimensions of the image.
+			 */
+			if orientation == ORIENTATION_HORIZONTAL {
+				rect = image.Rect(0, 0, lengthInt, thicknessInt)
+			} else {
+				rect = image.Rect(0, 0, thicknessInt, lengthInt)
+			}
+
+			img := image.NewNRGBA(rect)
+
+			/*
+			 * Draw the bar.
+			 */
+			for i, c := range colors {
+
+				/*
+				 * Draw a line across the bar.
+				 */
+				for j := 0; j < thicknessInt; j++ {
+
+					/*
+					 * Decide on the orientation.
+					 */
+					if orientation == ORIENTATION_HORIZONTAL {
+						img.SetNRGBA(i, j, c)
+					} else {
+						img.SetNRGBA(j, lengthInt-i-1, c)
+					}
+
+				}
+
+			}
+
+			return img, nil
+		}
+
+	}
+
+}
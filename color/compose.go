@@ -0,0 +1,147 @@
+package color
+
+import (
+	"image/color"
+)
+
+/*
+ * A ramp maps an intensity between zero and one to a color.
+ */
+type Ramp interface {
+	Color(intensity float64) color.NRGBA
+}
+
+/*
+ * Data structure representing one of the color ramps of this package.
+ */
+type rampStruct struct {
+	ramp rampFunc
+}
+
+/*
+ * Map an intensity to a color.
+ */
+func (this *rampStruct) Color(intensity float64) color.NRGBA {
+	intensity = clamp(intensity, 0.0, 1.0)
+	c := this.ramp(intensity)
+	return c.nrgba()
+}
+
+/*
+ * Wrap a color ramp function into a ramp.
+ */
+func createRamp(ramp rampFunc) Ramp {
+
+	/*
+	 * Create color ramp.
+	 */
+	r := rampStruct{
+		ramp: ramp,
+	}
+
+	return &r
+}
+
+/*
+ * Create a color mapping, which combines a scale, mapping counts to
+ * intensities, with a ramp, mapping intensities to colors.
+ *
+ * This allows pairing any scale, e. g. LinearScale or EqualizedScale, with any
+ * ramp, e. g. ViridisRamp or a ramp from GradientRamp. If scale is nil, counts
+ * are mapped logarithmically. If ramp is nil, this returns nil. Options are
+ * applied afterwards, so WithScale overrides the scale passed in.
+ */
+func Compose(scale Scale, ramp Ramp, options ...Option) Mapping {
+
+	/*
+	 * Check if a ramp was given.
+	 */
+	if ramp == nil {
+		return nil
+	} else {
+		f := rampFunc(nil)
+		r, ok := ramp.(*rampStruct)
+
+		/*
+		 * Use ramps of this package directly, wrap others.
+		 */
+		if ok {
+			f = r.ramp
+		} else {
+
+			/*
+			 * Map intensities using the custom ramp.
+			 */
+			f = func(intensity float64) rgba {
+				c := ramp.Color(intensity)
+				return fromNRGBA(c)
+			}
+
+		}
+
+		defaults := []Option{WithScale(scale)}
+		options = append(defaults, options...)
+		return createRampMapping(f, options)
+	}
+
+}
+
+/*
+ * Create the color ramp of the default mapping.
+ */
+func DefaultRamp() Ramp {
+	return createRamp(rainbow)
+}
+
+/*
+ * Create the viridis color ramp.
+ */
+func ViridisRamp() Ramp {
+	return createRamp(viridis)
+}
+
+/*
+ * Create the inferno color ramp.
+ */
+func InfernoRamp() Ramp {
+	return createRamp(inferno)
+}
+
+/*
+ * Create the magma color ramp.
+ */
+func MagmaRamp() Ramp {
+	return createRamp(magma)
+}
+
+/*
+ * Create the plasma color ramp.
+ */
+func PlasmaRamp() Ramp {
+	return createRamp(plasma)
+}
+
+/*
+ * Create the turbo color ramp.
+ */
+func TurboRamp() Ramp {
+	return createRamp(turbo)
+}
+
+/*
+ * Create a cubehelix color ramp. See CubehelixMapping for a description of
+ * the parameters.
+ */
+func CubehelixRamp(start float64, rotations float64, hue float64, gamma float64) Ramp {
+	ramp := cubehelix(start, rotations, hue, gamma)
+	return createRamp(ramp)
+}
+
+/*
+ * Create a color ramp, which interpolates between color stops in a color
+ * space (e. g. INTERPOLATION_OKLAB).
+ */
+func GradientRamp(stops []Stop, space uint8) Ramp {
+	ramp := gradient(stops, space)
+	return createRamp(ramp)
+}
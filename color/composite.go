@@ -0,0 +1,148 @@
+package color
+
+import (
+	"fmt"
+	"image/color"
+)
+
+/*
+ * Brightness of the lowest non-zero density when compositing categories, so
+ * that sparse bins remain visible.
+ */
+const COMPOSITE_MIN_BRIGHTNESS = 0.2
+
+/*
+ * Composite several distributions, one per category, into a series of
+ * colors.
+ *
+ * The hue of each color reflects the mixture of categories within a bin, as
+ * the category colors are averaged in linear light, weighted by their counts.
+ * The brightness of each color reflects the total count of the bin, which is
+ * mapped to an intensity using the scale of the options (logarithmic by
+ * default). The options WithScale, WithMax, WithMin, WithClasses,
+ * WithBreaks and WithBackground are supported.
+ *
+ * All distributions must have the same length and there must be one color
+ * per distribution.
+ */
+func Composite(counts [][]uint64, colors []color.NRGBA, options ...Option) ([]color.NRGBA, error) {
+	numCategories := len(counts)
+	numColors := len(colors)
+
+	/*
+	 * Verify that there is a color for each category.
+	 */
+	if numCategories != numColors {
+		return nil, fmt.Errorf("Got %d distributions, but %d colors.", numCategories, numColors)
+	} else {
+		n := 0
+
+		/*
+		 * Determine the length of the distributions.
+		 */
+		if numCategories > 0 {
+			n = len(counts[0])
+		}
+
+		/*
+		 * Verify that all distributions have the same length.
+		 */
+		for i, category := range counts {
+			numCounts := len(category)
+
+			/*
+			 * Check length of distribution.
+			 */
+			if numCounts != n {
+				return nil, fmt.Errorf("Distribution %d has %d counts, but expected %d.", i, numCounts, n)
+			}
+
+		}
+
+		totals := make([]uint64, n)
+
+		/*
+		 * Sum up the counts of all categories.
+		 */
+		for _, category := range counts {
+
+			/*
+			 * Add the counts of this category.
+			 */
+			for i, count := range category {
+				totals[i] += count
+			}
+
+		}
+
+		linear := make([]rgba, numColors)
+
+		/*
+		 * Convert colors to linear light.
+		 */
+		for i, c := range colors {
+			f := fromNRGBA(c)
+			f.r = linearize(f.r)
+			f.g = linearize(f.g)
+			f.b = linearize(f.b)
+			linear[i] = f
+		}
+
+		mapping := createRampMapping(nil, options).(*rampMappingStruct)
+		max := mapping.maximum(totals)
+		scale := mapping.fit(totals)
+		result := make([]color.NRGBA, n)
+
+		/*
+		 * Composite the colors of each bin.
+		 */
+		for i, total := range totals {
+
+			/*
+			 * Empty bins and bins below the minimum count map
+			 * to the background color.
+			 */
+			if !mapping.visible(total) {
+				result[i] = mapping.background
+			} else {
+				mix := rgba{}
+				totalFloat := float64(total)
+
+				/*
+				 * Average the category colors, weighted by their
+				 * counts.
+				 */
+				for j, category := range counts {
+					count := category[i]
+
+					/*
+					 * Only consider categories present in this
+					 * bin.
+					 */
+					if count > 0 {
+						weight := float64(count) / totalFloat
+						c := linear[j]
+						mix.r += weight * c.r
+						mix.g += weight * c.g
+						mix.b += weight * c.b
+						mix.a += weight * c.a
+					}
+
+				}
+
+				intensity := scale.Intensity(total, max)
+				intensity = clamp(intensity, 0.0, 1.0)
+				intensity = mapping.quantize(intensity, total)
+				brightness := COMPOSITE_MIN_BRIGHTNESS + ((1.0 - COMPOSITE_MIN_BRIGHTNESS) * intensity)
+				mix.r = delinearize(brightness * mix.r)
+				mix.g = delinearize(brightness * mix.g)
+				mix.b = delinearize(brightness * mix.b)
+				result[i] = mix.nrgba()
+			}
+
+		}
+
+		return result, nil
+	}
+
+}
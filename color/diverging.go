@@ -0,0 +1,178 @@
+package color
+
+import (
+	"image/color"
+)
+
+/*
+ * Maps a signed distribution (e. g. the difference between two scenes) to a
+ * series of colors.
+ */
+type SignedMapping interface {
+	MapSigned(values []int64) []color.NRGBA
+}
+
+/*
+ * Data structure representing a diverging color mapping.
+ */
+type divergingMappingStruct struct {
+	center  int64
+	mapping rampMappingStruct
+}
+
+/*
+ * Calculate the absolute deviation of a value from the center and whether
+ * the value lies below the center.
+ */
+func deviation(value int64, center int64) (uint64, bool) {
+
+	/*
+	 * Calculate the difference without overflowing.
+	 */
+	if value < center {
+		d := uint64(center) - uint64(value)
+		return d, true
+	} else {
+		d := uint64(value) - uint64(center)
+		return d, false
+	}
+
+}
+
+/*
+ * Map each signed value to a color value.
+ *
+ * Values below the center map to the lower half, values above the center to
+ * the upper half of the color ramp, with the deviation from the center
+ * determining the intensity.
+ */
+func (this *divergingMappingStruct) MapSigned(values []int64) []color.NRGBA {
+	center := this.center
+	mapping := &this.mapping
+	n := len(values)
+	deviations := make([]uint64, n)
+	below := make([]bool, n)
+
+	/*
+	 * Calculate the deviation of each value from the center.
+	 */
+	for i, value := range values {
+		deviations[i], below[i] = deviation(value, center)
+	}
+
+	max := mapping.maximum(deviations)
+	scale := mapping.fit(deviations)
+	colors := make([]color.NRGBA, n)
+	ramp := mapping.ramp
+
+	/*
+	 * Map each value in the distribution to a color value.
+	 */
+	for i, d := range deviations {
+		frac := float64(0.0)
+
+		/*
+		 * Values at the center and deviations below the minimum map
+		 * to the middle of the ramp.
+		 */
+		if mapping.visible(d) {
+			frac = scale.Intensity(d, max)
+			frac = clamp(frac, 0.0, 1.0)
+			frac = mapping.quantize(frac, d)
+		}
+
+		/*
+		 * Decide on the half of the ramp.
+		 */
+		if below[i] {
+			frac = 0.5 - (0.5 * frac)
+		} else {
+			frac = 0.5 + (0.5 * frac)
+		}
+
+		c := ramp(frac)
+		colors[i] = c.nrgba()
+	}
+
+	return colors
+}
+
+/*
+ * Create a new diverging color mapping for signed data, which interpolates
+ * from the low color over the middle color (at the center value) to the high
+ * color.
+ *
+ * The intensity of each color is determined by the absolute deviation of a
+ * value from the center, relative to the largest deviation. By default,
+ * deviations are mapped linearly. The options WithScale, WithMax (fixing the
+ * largest deviation), WithMin (hiding smaller deviations), WithClasses and
+ * WithBreaks (all applied to deviations) as well as WithInterpolation are
+ * supported.
+ */
+func DivergingMapping(low color.NRGBA, middle color.NRGBA, high color.NRGBA, center int64, options ...Option) SignedMapping {
+
+	/*
+	 * Stops of the diverging ramp.
+	 */
+	stops := []Stop{
+		CreateStop(0.0, low),
+		CreateStop(0.5, middle),
+		CreateStop(1.0, high),
+	}
+
+	scale := LinearScale()
+	defaults := []Option{WithScale(scale)}
+	options = append(defaults, options...)
+	mapping := createRampMapping(nil, options).(*rampMappingStruct)
+	space := mapping.interpolation
+	mapping.ramp = gradient(stops, space)
+
+	/*
+	 * Create diverging color mapping.
+	 */
+	m := divergingMappingStruct{
+		center:  center,
+		mapping: *mapping,
+	}
+
+	return &m
+}
+
+/*
+ * Create a new diverging color mapping for signed data, ranging from blue
+ * (below the center) over white (at the center) to red (above the center).
+ */
+func BlueWhiteRedMapping(center int64, options ...Option) SignedMapping {
+
+	/*
+	 * Color for values below the center.
+	 */
+	low := color.NRGBA{
+		R: 33,
+		G: 102,
+		B: 172,
+		A: 255,
+	}
+
+	/*
+	 * Color for values at the center.
+	 */
+	middle := color.NRGBA{
+		R: 247,
+		G: 247,
+		B: 247,
+		A: 255,
+	}
+
+	/*
+	 * Color for values above the center.
+	 */
+	high := color.NRGBA{
+		R: 178,
+		G: 24,
+		B: 43,
+		A: 255,
+	}
+
+	return DivergingMapping(low, middle, high, center, options...)
+}
@@ -0,0 +1,157 @@
+package color
+
+import (
+	"image/color"
+	"math"
+)
+
+/*
+ * Data structure representing a color mapping, which embosses the colors of
+ * another mapping based on the local density gradient.
+ */
+type embossMappingStruct struct {
+	mapping  Mapping
+	strength float64
+}
+
+/*
+ * Returns the logarithmic relief of a distribution at a pair of coordinates,
+ * treating bins outside the distribution as empty.
+ */
+func reliefAt(counts []uint64, width int, height int, x int, y int) float64 {
+
+	/*
+	 * Check if coordinates are in valid range.
+	 */
+	if (x < 0) || (y < 0) || (x >= width) || (y >= height) {
+		return 0.0
+	} else {
+		idx := (width * y) + x
+		count := float64(counts[idx])
+		return math.Log1p(count)
+	}
+
+}
+
+/*
+ * Multiply a color channel by a factor, clamping the result to the range of
+ * the channel.
+ */
+func scaleChannel(value uint8, factor float64) uint8 {
+	valueFloat := float64(value)
+	result := math.Round(factor * valueFloat)
+	result = clamp(result, 0.0, 255.0)
+	return uint8(result)
+}
+
+/*
+ * Map each count to a color value.
+ *
+ * Since the neighbours of a bin are unknown, the colors of the underlying
+ * mapping are returned unchanged.
+ */
+func (this *embossMappingStruct) Map(counts []uint64) []color.NRGBA {
+	return this.mapping.Map(counts)
+}
+
+/*
+ * Map each count of a rectangle of bins with the given width to a color value,
+ * embossing the colors based on the local density gradient.
+ */
+func (this *embossMappingStruct) MapGrid(counts []uint64, width uint32) []color.NRGBA {
+	colors := []color.NRGBA(nil)
+
+	/*
+	 * Pass the shape of the distribution on to mappings which need it.
+	 */
+	if grid, ok := this.mapping.(GridMapping); ok {
+		colors = grid.MapGrid(counts, width)
+	} else {
+		colors = this.mapping.Map(counts)
+	}
+
+	numCounts := len(counts)
+	numColors := len(colors)
+	widthInt := int(width)
+
+	/*
+	 * Only emboss if the distribution forms a rectangle.
+	 */
+	if (widthInt > 0) && (numColors == numCounts) && ((numCounts % widthInt) == 0) {
+		height := numCounts / widthInt
+		strength := this.strength
+
+		/*
+		 * Iterate over the rows of the distribution.
+		 */
+		for y := 0; y < height; y++ {
+
+			/*
+			 * Iterate over the columns of the distribution.
+			 */
+			for x := 0; x < widthInt; x++ {
+				a := reliefAt(counts, widthInt, height, x-1, y-1)
+				b := reliefAt(counts, widthInt, height, x, y-1)
+				c := reliefAt(counts, widthInt, height, x+1, y-1)
+				d := reliefAt(counts, widthInt, height, x-1, y)
+				f := reliefAt(counts, widthInt, height, x+1, y)
+				g := reliefAt(counts, widthInt, height, x-1, y+1)
+				h := reliefAt(counts, widthInt, height, x, y+1)
+				i := reliefAt(counts, widthInt, height, x+1, y+1)
+				dzdx := ((c + (2.0 * f) + i) - (a + (2.0 * d) + g)) / 8.0
+				dzdy := ((g + (2.0 * h) + i) - (a + (2.0 * b) + c)) / 8.0
+
+				/*
+				 * Slopes facing the light in the upper left are
+				 * brightened, others are darkened.
+				 */
+				factor := 1.0 + (strength * math.Tanh(dzdx+dzdy))
+				idx := (widthInt * y) + x
+				col := &colors[idx]
+				col.R = scaleChannel(col.R, factor)
+				col.G = scaleChannel(col.G, factor)
+				col.B = scaleChannel(col.B, factor)
+			}
+
+		}
+
+	}
+
+	return colors
+}
+
+/*
+ * Create a new color mapping, which embosses the colors of another mapping
+ * based on the local density gradient, highlighting structure in dense
+ * regions.
+ *
+ * The logarithm of the counts is treated as a relief lit from the upper left,
+ * so that slopes facing the light are brightened and slopes facing away are
+ * darkened by up to strength (between zero and one). Since this requires the
+ * neighbours of each bin, embossing is only applied when the mapping is used
+ * as a GridMapping, as scenes do when rendering. Bins outside the scene (or
+ * tile) are treated as empty. When used through Map, the colors of the
+ * underlying mapping are returned unchanged. If m is nil, this returns nil.
+ */
+func EmbossMapping(m Mapping, strength float64) Mapping {
+
+	/*
+	 * Check if a mapping was given.
+	 */
+	if m == nil {
+		return nil
+	} else {
+		strength = clamp(strength, 0.0, 1.0)
+
+		/*
+		 * Create emboss color mapping.
+		 */
+		e := embossMappingStruct{
+			mapping:  m,
+			strength: strength,
+		}
+
+		return &e
+	}
+
+}
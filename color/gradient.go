@@ -0,0 +1,153 @@
+package color
+
+import (
+	"image/color"
+	"sort"
+)
+
+/*
+ * Data structure representing a control point of a color gradient.
+ *
+ * Stops are immutable.
+ */
+type Stop struct {
+	color    color.NRGBA
+	position float64
+}
+
+/*
+ * Returns the color of this stop.
+ */
+func (this *Stop) Color() color.NRGBA {
+	return this.color
+}
+
+/*
+ * Returns the position of this stop within the gradient, ranging from zero
+ * (lowest intensity) to one (highest intensity).
+ */
+func (this *Stop) Position() float64 {
+	return this.position
+}
+
+/*
+ * Creates an immutable control point of a color gradient at a position
+ * between zero (lowest intensity) and one (highest intensity).
+ */
+func CreateStop(position float64, c color.NRGBA) Stop {
+	position = clamp(position, 0.0, 1.0)
+
+	/*
+	 * Create a new stop.
+	 */
+	s := Stop{
+		color:    c,
+		position: position,
+	}
+
+	return s
+}
+
+/*
+ * Convert an 8-bit NRGBA color to a floating-point color.
+ */
+func fromNRGBA(c color.NRGBA) rgba {
+
+	/*
+	 * The resulting color.
+	 */
+	result := rgba{
+		r: float64(c.R) / 255.0,
+		g: float64(c.G) / 255.0,
+		b: float64(c.B) / 255.0,
+		a: float64(c.A) / 255.0,
+	}
+
+	return result
+}
+
+/*
+ * Create a color ramp interpolating linearly between a set of stops in a
+ * color space.
+ *
+ * Intensities below the first or above the last stop map to the color of the
+ * first or last stop, respectively. Without any stops, all intensities map to
+ * transparent.
+ */
+func gradient(stops []Stop, space uint8) rampFunc {
+	numStops := len(stops)
+	sorted := make([]Stop, numStops)
+	copy(sorted, stops)
+
+	/*
+	 * Order stops by ascending position.
+	 */
+	sort.SliceStable(sorted, func(i int, j int) bool {
+		return sorted[i].position < sorted[j].position
+	})
+
+	positions := make([]float64, numStops)
+	colors := make([]rgba, numStops)
+	converted := make([]coords, numStops)
+
+	/*
+	 * Convert stops to floating-point colors and into the color space
+	 * used for interpolation.
+	 */
+	for i := range sorted {
+		s := &sorted[i]
+		c := fromNRGBA(s.color)
+		positions[i] = s.position
+		colors[i] = c
+		converted[i] = toSpace(c, space)
+	}
+
+	/*
+	 * The color ramp.
+	 */
+	ramp := func(intensity float64) rgba {
+
+		/*
+		 * Handle degenerate gradients and out-of-range intensities.
+		 */
+		if numStops == 0 {
+			return rgba{}
+		} else if intensity <= positions[0] {
+			return colors[0]
+		} else if intensity >= positions[numStops-1] {
+			return colors[numStops-1]
+		} else {
+
+			/*
+			 * Find the first stop after the intensity.
+			 */
+			idx := sort.Search(numStops, func(i int) bool {
+				return positions[i] > intensity
+			})
+
+			left := positions[idx-1]
+			right := positions[idx]
+			t := (intensity - left) / (right - left)
+			c := interpolate(converted[idx-1], converted[idx], t, space)
+			return fromSpace(c, space)
+		}
+
+	}
+
+	return ramp
+}
+
+/*
+ * Create a new color mapping, which interpolates linearly between a set of
+ * user-defined stops.
+ *
+ * Each stop assigns a color to a position between zero (lowest intensity) and
+ * one (highest intensity). Use the WithInterpolation option to select the
+ * color space in which colors are interpolated.
+ */
+func GradientMapping(stops []Stop, options ...Option) Mapping {
+	m := createRampMapping(nil, options).(*rampMappingStruct)
+	space := m.interpolation
+	m.ramp = gradient(stops, space)
+	return m
+}
@@ -0,0 +1,747 @@
+package color
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Number of stops sampled from each segment of a GIMP gradient.
+ */
+const (
+	GGR_SAMPLES_PER_SEGMENT = 32
+)
+
+/*
+ * Blending functions of GIMP gradient segments.
+ */
+const (
+	ggrBlendLinear           = 0
+	ggrBlendCurved           = 1
+	ggrBlendSine             = 2
+	ggrBlendSphereIncreasing = 3
+	ggrBlendSphereDecreasing = 4
+	ggrBlendStep             = 5
+)
+
+/*
+ * Parse a hexadecimal color specification of the form RRGGBB or RRGGBBAA,
+ * optionally prefixed by '#'.
+ */
+func parseHexColor(s string) (color.NRGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	n := len(s)
+
+	/*
+	 * Check length of color specification.
+	 */
+	if (n != 6) && (n != 8) {
+		return color.NRGBA{}, fmt.Errorf("Invalid hexadecimal color: '%s'", s)
+	} else {
+		val, err := strconv.ParseUint(s, 16, 32)
+
+		/*
+		 * Check if color could be parsed.
+		 */
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("Invalid hexadecimal color: '%s'", s)
+		} else {
+
+			/*
+			 * Add opaque alpha channel if it is missing.
+			 */
+			if n == 6 {
+				val = (val << 8) | 0xff
+			}
+
+			/*
+			 * The resulting color.
+			 */
+			c := color.NRGBA{
+				R: uint8(val >> 24),
+				G: uint8(val >> 16),
+				B: uint8(val >> 8),
+				A: uint8(val),
+			}
+
+			return c, nil
+		}
+
+	}
+
+}
+
+/*
+ * Parse a color channel value ranging from 0 to 255.
+ */
+func parseChannel(s string) (uint8, error) {
+	val, err := strconv.ParseFloat(s, 64)
+
+	/*
+	 * Check if value could be parsed.
+	 */
+	if err != nil {
+		return 0, fmt.Errorf("Invalid color channel value: '%s'", s)
+	} else {
+		val = math.Round(val)
+		val = clamp(val, 0.0, 255.0)
+		return uint8(val), nil
+	}
+
+}
+
+/*
+ * Parse a color of a GMT color palette table.
+ *
+ * Returns the color and the number of tokens consumed.
+ */
+func parseCPTColor(tokens []string, hsv bool) (color.NRGBA, int, error) {
+	numTokens := len(tokens)
+
+	/*
+	 * Decide on the notation of the color.
+	 */
+	if numTokens < 1 {
+		return color.NRGBA{}, 0, fmt.Errorf("%s", "Missing color in color palette table.")
+	} else if strings.HasPrefix(tokens[0], "#") {
+		c, err := parseHexColor(tokens[0])
+		return c, 1, err
+	} else {
+		components := []string{}
+		consumed := 0
+
+		/*
+		 * Colors are either given as one token with components
+		 * separated by '/' (or '-' for HSV) or as three tokens.
+		 */
+		if strings.Contains(tokens[0], "/") {
+			components = strings.Split(tokens[0], "/")
+			consumed = 1
+		} else if hsv && strings.Count(tokens[0], "-") == 2 {
+			components = strings.Split(tokens[0], "-")
+			consumed = 1
+		} else if numTokens >= 3 {
+			components = tokens[0:3]
+			consumed = 3
+		}
+
+		/*
+		 * Check number of components.
+		 */
+		if len(components) != 3 {
+			return color.NRGBA{}, 0, fmt.Errorf("Invalid color in color palette table: '%s'", tokens[0])
+		} else if hsv {
+			values := [3]float64{}
+
+			/*
+			 * Parse hue, saturation and value.
+			 */
+			for i, component := range components {
+				val, err := strconv.ParseFloat(component, 64)
+
+				/*
+				 * Check if component could be parsed.
+				 */
+				if err != nil {
+					return color.NRGBA{}, 0, fmt.Errorf("Invalid color component in color palette table: '%s'", component)
+				}
+
+				values[i] = val
+			}
+
+			/*
+			 * The color in HSV space.
+			 */
+			h := coords{
+				c0:    values[0] / 360.0,
+				c1:    values[1],
+				c2:    values[2],
+				alpha: 1.0,
+			}
+
+			c := fromHSV(h)
+			return c.nrgba(), consumed, nil
+		} else {
+			channels := [3]uint8{}
+
+			/*
+			 * Parse red, green and blue channels.
+			 */
+			for i, component := range components {
+				val, err := parseChannel(component)
+
+				/*
+				 * Check if component could be parsed.
+				 */
+				if err != nil {
+					return color.NRGBA{}, 0, err
+				}
+
+				channels[i] = val
+			}
+
+			/*
+			 * The resulting color.
+			 */
+			c := color.NRGBA{
+				R: channels[0],
+				G: channels[1],
+				B: channels[2],
+				A: 255,
+			}
+
+			return c, consumed, nil
+		}
+
+	}
+
+}
+
+/*
+ * Normalize the positions of a set of stops, given in arbitrary units, to the
+ * range from zero to one.
+ */
+func normalizeStops(values []float64, colors []color.NRGBA) []Stop {
+	numValues := len(values)
+	stops := make([]Stop, numValues)
+
+	/*
+	 * Only normalize if there are values.
+	 */
+	if numValues > 0 {
+		min := values[0]
+		max := values[0]
+
+		/*
+		 * Find smallest and largest value.
+		 */
+		for _, val := range values {
+			min = math.Min(min, val)
+			max = math.Max(max, val)
+		}
+
+		span := max - min
+
+		/*
+		 * Create a stop for each value.
+		 */
+		for i, val := range values {
+			pos := float64(0.0)
+
+			/*
+			 * Avoid division by zero.
+			 */
+			if span > 0.0 {
+				pos = (val - min) / span
+			}
+
+			stops[i] = CreateStop(pos, colors[i])
+		}
+
+	}
+
+	return stops
+}
+
+/*
+ * Read the stops from a GMT color palette table (.cpt).
+ *
+ * Both RGB and HSV color models are supported. Background, foreground and NaN
+ * colors as well as annotations are ignored. Slice boundaries are normalized
+ * to the range from zero to one.
+ */
+func ReadCPTStops(r io.Reader) ([]Stop, error) {
+	scanner := bufio.NewScanner(r)
+	values := []float64{}
+	colors := []color.NRGBA{}
+	hsv := false
+
+	/*
+	 * Read line by line.
+	 */
+	for scanner.Scan() {
+		line := scanner.Text()
+		line = strings.TrimSpace(line)
+
+		/*
+		 * Strip labels.
+		 */
+		if idx := strings.Index(line, ";"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		/*
+		 * Handle comments and special lines.
+		 */
+		if strings.HasPrefix(line, "#") {
+			comment := strings.ToUpper(line)
+			comment = strings.ReplaceAll(comment, " ", "")
+
+			/*
+			 * Check for color model.
+			 */
+			if strings.Contains(comment, "COLOR_MODEL=HSV") {
+				hsv = true
+			} else if strings.Contains(comment, "COLOR_MODEL=RGB") {
+				hsv = false
+			}
+
+		} else if (line != "") && !strings.ContainsAny(line[0:1], "BFN") {
+			tokens := strings.Fields(line)
+			z0, err := strconv.ParseFloat(tokens[0], 64)
+
+			/*
+			 * Check if lower boundary could be parsed.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Invalid slice boundary in color palette table: '%s'", tokens[0])
+			}
+
+			tokens = tokens[1:]
+			c0, consumed, err := parseCPTColor(tokens, hsv)
+
+			/*
+			 * Check if lower color could be parsed.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = tokens[consumed:]
+
+			/*
+			 * Check if upper boundary is present.
+			 */
+			if len(tokens) < 1 {
+				return nil, fmt.Errorf("%s", "Missing upper slice boundary in color palette table.")
+			}
+
+			z1, err := strconv.ParseFloat(tokens[0], 64)
+
+			/*
+			 * Check if upper boundary could be parsed.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Invalid slice boundary in color palette table: '%s'", tokens[0])
+			}
+
+			tokens = tokens[1:]
+			c1, _, err := parseCPTColor(tokens, hsv)
+
+			/*
+			 * Check if upper color could be parsed.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, z0, z1)
+			colors = append(colors, c0, c1)
+		}
+
+	}
+
+	err := scanner.Err()
+
+	/*
+	 * Check for read errors.
+	 */
+	if err != nil {
+		return nil, err
+	} else if len(values) == 0 {
+		return nil, fmt.Errorf("%s", "Color palette table does not contain any slices.")
+	} else {
+		stops := normalizeStops(values, colors)
+		return stops, nil
+	}
+
+}
+
+/*
+ * Evaluate the blending function of a GIMP gradient segment.
+ *
+ * The position and midpoint are relative to the segment.
+ */
+func ggrBlend(pos float64, middle float64, blend int64) float64 {
+	factor := float64(0.0)
+
+	/*
+	 * Apply the linear midpoint transform first.
+	 */
+	if pos <= middle {
+
+		/*
+		 * Avoid division by zero.
+		 */
+		if middle > 0.0 {
+			factor = 0.5 * pos / middle
+		}
+
+	} else {
+
+		/*
+		 * Avoid division by zero.
+		 */
+		if middle < 1.0 {
+			factor = 0.5 + (0.5 * (pos - middle) / (1.0 - middle))
+		} else {
+			factor = 1.0
+		}
+
+	}
+
+	/*
+	 * Decide on the blending function.
+	 */
+	switch blend {
+	case ggrBlendCurved:
+
+		/*
+		 * Avoid degenerate midpoints.
+		 */
+		if (middle > 0.0) && (middle < 1.0) {
+			factor = math.Pow(pos, math.Log(0.5)/math.Log(middle))
+		}
+
+	case ggrBlendSine:
+		factor = 0.5 * (math.Sin((-0.5*math.Pi)+(math.Pi*factor)) + 1.0)
+	case ggrBlendSphereIncreasing:
+		f := factor - 1.0
+		factor = math.Sqrt(1.0 - (f * f))
+	case ggrBlendSphereDecreasing:
+		factor = 1.0 - math.Sqrt(1.0-(factor*factor))
+	case ggrBlendStep:
+
+		/*
+		 * Jump at the midpoint.
+		 */
+		if pos < middle {
+			factor = 0.0
+		} else {
+			factor = 1.0
+		}
+
+	}
+
+	return clamp(factor, 0.0, 1.0)
+}
+
+/*
+ * Read the stops from a GIMP gradient (.ggr).
+ *
+ * Each segment is sampled at GGR_SAMPLES_PER_SEGMENT positions, honoring its
+ * blending function. Colors are always interpolated in RGB space, so segments
+ * using HSV coloring are approximated.
+ */
+func ReadGGRStops(r io.Reader) ([]Stop, error) {
+	scanner := bufio.NewScanner(r)
+	lines := []string{}
+
+	/*
+	 * Read all non-empty lines.
+	 */
+	for scanner.Scan() {
+		line := scanner.Text()
+		line = strings.TrimSpace(line)
+
+		/*
+		 * Skip empty lines.
+		 */
+		if line != "" {
+			lines = append(lines, line)
+		}
+
+	}
+
+	err := scanner.Err()
+
+	/*
+	 * Check for read errors and header.
+	 */
+	if err != nil {
+		return nil, err
+	} else if (len(lines) < 2) || (lines[0] != "GIMP Gradient") {
+		return nil, fmt.Errorf("%s", "Not a GIMP gradient.")
+	} else {
+		lines = lines[1:]
+
+		/*
+		 * Skip the name of the gradient.
+		 */
+		if strings.HasPrefix(lines[0], "Name:") {
+			lines = lines[1:]
+		}
+
+		/*
+		 * Check if number of segments is present.
+		 */
+		if len(lines) < 1 {
+			return nil, fmt.Errorf("%s", "Missing number of segments in GIMP gradient.")
+		}
+
+		numSegments, err := strconv.ParseUint(lines[0], 10, 32)
+
+		/*
+		 * Check if number of segments could be parsed.
+		 */
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number of segments in GIMP gradient: '%s'", lines[0])
+		}
+
+		lines = lines[1:]
+		numLines := uint64(len(lines))
+
+		/*
+		 * Check if all segments are present.
+		 */
+		if numLines < numSegments {
+			return nil, fmt.Errorf("GIMP gradient declares %d segments, but contains only %d.", numSegments, numLines)
+		}
+
+		stops := []Stop{}
+
+		/*
+		 * Parse each segment.
+		 */
+		for _, line := range lines[:numSegments] {
+			tokens := strings.Fields(line)
+
+			/*
+			 * Check number of fields.
+			 */
+			if len(tokens) < 11 {
+				return nil, fmt.Errorf("Invalid segment in GIMP gradient: '%s'", line)
+			}
+
+			values := [11]float64{}
+
+			/*
+			 * Parse positions and colors.
+			 */
+			for i := range values {
+				val, err := strconv.ParseFloat(tokens[i], 64)
+
+				/*
+				 * Check if value could be parsed.
+				 */
+				if err != nil {
+					return nil, fmt.Errorf("Invalid value in GIMP gradient: '%s'", tokens[i])
+				}
+
+				values[i] = val
+			}
+
+			blend := int64(ggrBlendLinear)
+
+			/*
+			 * Parse blending function, if present.
+			 */
+			if len(tokens) > 11 {
+				blend, err = strconv.ParseInt(tokens[11], 10, 64)
+
+				/*
+				 * Check if blending function could be parsed.
+				 */
+				if err != nil {
+					return nil, fmt.Errorf("Invalid blending function in GIMP gradient: '%s'", tokens[11])
+				}
+
+			}
+
+			left := values[0]
+			middle := values[1]
+			right := values[2]
+			width := right - left
+			middleRelative := float64(0.5)
+
+			/*
+			 * Calculate midpoint relative to segment.
+			 */
+			if width > 0.0 {
+				middleRelative = (middle - left) / width
+			}
+
+			/*
+			 * Colors at the ends of the segment.
+			 */
+			c0 := rgba{r: values[3], g: values[4], b: values[5], a: values[6]}
+			c1 := rgba{r: values[7], g: values[8], b: values[9], a: values[10]}
+
+			/*
+			 * Sample the segment.
+			 */
+			for i := 0; i <= GGR_SAMPLES_PER_SEGMENT; i++ {
+				t := float64(i) / GGR_SAMPLES_PER_SEGMENT
+				factor := ggrBlend(t, middleRelative, blend)
+				s := 1.0 - factor
+
+				/*
+				 * The color at this position.
+				 */
+				c := rgba{
+					r: (s * c0.r) + (factor * c1.r),
+					g: (s * c0.g) + (factor * c1.g),
+					b: (s * c0.b) + (factor * c1.b),
+					a: (s * c0.a) + (factor * c1.a),
+				}
+
+				pos := left + (t * width)
+				stop := CreateStop(pos, c.nrgba())
+				stops = append(stops, stop)
+			}
+
+		}
+
+		return stops, nil
+	}
+
+}
+
+/*
+ * Read the stops from a text file listing colors in hexadecimal notation
+ * (RRGGBB or RRGGBBAA, optionally prefixed by '#'), separated by whitespace,
+ * commas or line breaks.
+ *
+ * The colors are spaced evenly from zero to one. Lines starting with ';' or
+ * "//", as well as tokens starting with '#' which are not colors, begin
+ * comments extending to the end of the line.
+ */
+func ReadHexStops(r io.Reader) ([]Stop, error) {
+	scanner := bufio.NewScanner(r)
+	colors := []color.NRGBA{}
+
+	/*
+	 * Read line by line.
+	 */
+	for scanner.Scan() {
+		line := scanner.Text()
+		line = strings.TrimSpace(line)
+
+		/*
+		 * Skip comment lines.
+		 */
+		if !strings.HasPrefix(line, ";") && !strings.HasPrefix(line, "//") {
+			line = strings.ReplaceAll(line, ",", " ")
+			tokens := strings.Fields(line)
+
+			/*
+			 * Parse each token.
+			 */
+			for _, token := range tokens {
+				c, err := parseHexColor(token)
+
+				/*
+				 * Tokens starting with '#' which are no colors
+				 * start comments.
+				 */
+				if err != nil {
+
+					/*
+					 * Decide whether this is a comment.
+					 */
+					if strings.HasPrefix(token, "#") {
+						break
+					} else {
+						return nil, err
+					}
+
+				}
+
+				colors = append(colors, c)
+			}
+
+		}
+
+	}
+
+	err := scanner.Err()
+	numColors := len(colors)
+
+	/*
+	 * Check for read errors.
+	 */
+	if err != nil {
+		return nil, err
+	} else if numColors == 0 {
+		return nil, fmt.Errorf("%s", "Color list does not contain any colors.")
+	} else {
+		values := make([]float64, numColors)
+
+		/*
+		 * Space colors evenly.
+		 */
+		for i := range values {
+			values[i] = float64(i)
+		}
+
+		stops := normalizeStops(values, colors)
+		return stops, nil
+	}
+
+}
+
+/*
+ * Read a color mapping from a GMT color palette table (.cpt).
+ *
+ * See ReadCPTStops for details. Options are passed on to GradientMapping.
+ */
+func ReadCPT(r io.Reader, options ...Option) (Mapping, error) {
+	stops, err := ReadCPTStops(r)
+
+	/*
+	 * Check if stops could be read.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		m := GradientMapping(stops, options...)
+		return m, nil
+	}
+
+}
+
+/*
+ * Read a color mapping from a GIMP gradient (.ggr).
+ *
+ * See ReadGGRStops for details. Options are passed on to GradientMapping.
+ */
+func ReadGGR(r io.Reader, options ...Option) (Mapping, error) {
+	stops, err := ReadGGRStops(r)
+
+	/*
+	 * Check if stops could be read.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		m := GradientMapping(stops, options...)
+		return m, nil
+	}
+
+}
+
+/*
+ * Read a color mapping from a text file listing colors in hexadecimal
+ * notation.
+ *
+ * See ReadHexStops for details. Options are passed on to GradientMapping.
+ */
+func ReadHex(r io.Reader, options ...Option) (Mapping, error) {
+	stops, err := ReadHexStops(r)
+
+	/*
+	 * Check if stops could be read.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		m := GradientMapping(stops, options...)
+		return m, nil
+	}
+
+}
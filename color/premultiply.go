@@ -0,0 +1,59 @@
+package color
+
+import (
+	"image/color"
+)
+
+/*
+ * Multiply an 8-bit color channel by an 8-bit alpha value.
+ *
+ * This yields the same result as the conversion performed by the image/color
+ * package.
+ */
+func premultiplyChannel(value uint8, alpha uint8) uint8 {
+	v := uint32(value)
+	v |= v << 8
+	v *= uint32(alpha)
+	v /= 0xff
+	return uint8(v >> 8)
+}
+
+/*
+ * Convert a color to premultiplied alpha.
+ */
+func PremultiplyColor(c color.NRGBA) color.RGBA {
+	alpha := c.A
+
+	/*
+	 * The resulting color.
+	 */
+	result := color.RGBA{
+		R: premultiplyChannel(c.R, alpha),
+		G: premultiplyChannel(c.G, alpha),
+		B: premultiplyChannel(c.B, alpha),
+		A: alpha,
+	}
+
+	return result
+}
+
+/*
+ * Convert a series of colors, as returned by a mapping, to premultiplied
+ * alpha.
+ *
+ * Premultiplied colors composite faster, e. g. when drawing an image over a
+ * background using draw.Draw.
+ */
+func Premultiply(colors []color.NRGBA) []color.RGBA {
+	n := len(colors)
+	result := make([]color.RGBA, n)
+
+	/*
+	 * Convert each color.
+	 */
+	for i, c := range colors {
+		result[i] = PremultiplyColor(c)
+	}
+
+	return result
+}
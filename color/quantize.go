@@ -0,0 +1,383 @@
+package color
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+/*
+ * Data structure representing a color along with the number of times it
+ * occurs.
+ */
+type histogramEntry struct {
+	color color.NRGBA
+	count uint64
+}
+
+/*
+ * Data structure representing a box in color space, which holds a subset of
+ * the colors of a histogram.
+ */
+type colorBox struct {
+	entries []histogramEntry
+}
+
+/*
+ * Returns a channel of a color, selected by its index (0 = red, 1 = green,
+ * 2 = blue, 3 = alpha).
+ */
+func channel(c color.NRGBA, idx int) uint8 {
+
+	/*
+	 * Decide on the channel.
+	 */
+	switch idx {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	case 2:
+		return c.B
+	default:
+		return c.A
+	}
+
+}
+
+/*
+ * Find the channel with the largest range of values within the box and
+ * returns its index along with the range.
+ */
+func (this *colorBox) widest() (int, uint8) {
+	bestIdx := 0
+	bestRange := uint8(0)
+
+	/*
+	 * Iterate over all channels.
+	 */
+	for idx := 0; idx < 4; idx++ {
+		min := uint8(255)
+		max := uint8(0)
+
+		/*
+		 * Determine the range of values in this channel.
+		 */
+		for _, entry := range this.entries {
+			value := channel(entry.color, idx)
+
+			/*
+			 * Update minimum and maximum.
+			 */
+			if value < min {
+				min = value
+			}
+
+			if value > max {
+				max = value
+			}
+
+		}
+
+		/*
+		 * Check if this channel is wider.
+		 */
+		if (max >= min) && ((max - min) > bestRange) {
+			bestIdx = idx
+			bestRange = max - min
+		}
+
+	}
+
+	return bestIdx, bestRange
+}
+
+/*
+ * Split a box at the weighted median of its widest channel.
+ */
+func (this *colorBox) split() (colorBox, colorBox) {
+	idx, _ := this.widest()
+	entries := this.entries
+
+	/*
+	 * Order colors along the widest channel.
+	 */
+	sort.SliceStable(entries, func(i int, j int) bool {
+		return channel(entries[i].color, idx) < channel(entries[j].color, idx)
+	})
+
+	total := uint64(0)
+
+	/*
+	 * Sum up the counts of all colors.
+	 */
+	for _, entry := range entries {
+		total += entry.count
+	}
+
+	half := total / 2
+	sum := uint64(0)
+	numEntries := len(entries)
+	pos := 1
+
+	/*
+	 * Find the weighted median, keeping at least one color on each side.
+	 */
+	for i := 0; i < numEntries-1; i++ {
+		sum += entries[i].count
+		pos = i + 1
+
+		/*
+		 * Stop as soon as half of the counts are below.
+		 */
+		if sum >= half {
+			break
+		}
+
+	}
+
+	/*
+	 * The lower box.
+	 */
+	lower := colorBox{
+		entries: entries[:pos],
+	}
+
+	/*
+	 * The upper box.
+	 */
+	upper := colorBox{
+		entries: entries[pos:],
+	}
+
+	return lower, upper
+}
+
+/*
+ * Calculate the average color of a box, weighted by the counts of its colors.
+ */
+func (this *colorBox) average() color.NRGBA {
+	sums := [4]float64{}
+	total := float64(0.0)
+
+	/*
+	 * Sum up all channels, weighted by counts.
+	 */
+	for _, entry := range this.entries {
+		weight := float64(entry.count)
+		c := entry.color
+		sums[0] += weight * float64(c.R)
+		sums[1] += weight * float64(c.G)
+		sums[2] += weight * float64(c.B)
+		sums[3] += weight * float64(c.A)
+		total += weight
+	}
+
+	result := [4]uint8{}
+
+	/*
+	 * Divide by total weight.
+	 */
+	for i, sum := range sums {
+		value := (sum / total) + 0.5
+		value = clamp(value, 0.0, 255.0)
+		result[i] = uint8(value)
+	}
+
+	/*
+	 * The resulting color.
+	 */
+	c := color.NRGBA{
+		R: result[0],
+		G: result[1],
+		B: result[2],
+		A: result[3],
+	}
+
+	return c
+}
+
+/*
+ * Create a palette of at most maxColors colors from a histogram of colors.
+ */
+func quantizeHistogram(counts map[color.NRGBA]uint64, maxColors uint32) color.Palette {
+	numColors := len(counts)
+	entries := make([]histogramEntry, 0, numColors)
+
+	/*
+	 * Collect the entries of the histogram.
+	 */
+	for c, count := range counts {
+
+		/*
+		 * Create histogram entry.
+		 */
+		entry := histogramEntry{
+			color: c,
+			count: count,
+		}
+
+		entries = append(entries, entry)
+	}
+
+	/*
+	 * Order the entries to make the result deterministic.
+	 */
+	sort.Slice(entries, func(i int, j int) bool {
+		a := entries[i].color
+		b := entries[j].color
+		keyA := (uint32(a.R) << 24) | (uint32(a.G) << 16) | (uint32(a.B) << 8) | uint32(a.A)
+		keyB := (uint32(b.R) << 24) | (uint32(b.G) << 16) | (uint32(b.B) << 8) | uint32(b.A)
+		return keyA < keyB
+	})
+
+	maxColorsInt := int(maxColors)
+
+	/*
+	 * Decide whether colors must be reduced.
+	 */
+	if maxColorsInt == 0 {
+		return color.Palette{}
+	} else if numColors <= maxColorsInt {
+		palette := make(color.Palette, numColors)
+
+		/*
+		 * Use the colors directly.
+		 */
+		for i, entry := range entries {
+			palette[i] = entry.color
+		}
+
+		return palette
+	} else {
+
+		/*
+		 * Start with a single box holding all colors.
+		 */
+		boxes := []colorBox{
+			colorBox{
+				entries: entries,
+			},
+		}
+
+		/*
+		 * Split boxes until there are enough of them.
+		 */
+		for len(boxes) < maxColorsInt {
+			bestIdx := -1
+			bestRange := uint8(0)
+
+			/*
+			 * Find the box with the widest range, which can be
+			 * split.
+			 */
+			for i := range boxes {
+				box := &boxes[i]
+
+				/*
+				 * Only boxes with at least two colors can be
+				 * split.
+				 */
+				if len(box.entries) > 1 {
+					_, r := box.widest()
+
+					/*
+					 * Check if this box is wider.
+					 */
+					if (bestIdx < 0) || (r > bestRange) {
+						bestIdx = i
+						bestRange = r
+					}
+
+				}
+
+			}
+
+			/*
+			 * Stop if no box can be split.
+			 */
+			if bestIdx < 0 {
+				break
+			}
+
+			lower, upper := boxes[bestIdx].split()
+			boxes[bestIdx] = lower
+			boxes = append(boxes, upper)
+		}
+
+		numBoxes := len(boxes)
+		palette := make(color.Palette, numBoxes)
+
+		/*
+		 * Represent each box by its average color.
+		 */
+		for i := range boxes {
+			palette[i] = boxes[i].average()
+		}
+
+		return palette
+	}
+
+}
+
+/*
+ * Create a palette of at most maxColors colors representing a series of
+ * colors, e. g. as returned by a mapping.
+ *
+ * If the series holds at most maxColors distinct colors, the palette holds
+ * exactly these colors. Otherwise, colors are reduced using the median cut
+ * algorithm, which repeatedly splits the box in color space with the widest
+ * range of values at the median of its colors. A maxColors of zero returns an
+ * empty palette.
+ */
+func Quantize(colors []color.NRGBA, maxColors uint32) color.Palette {
+	counts := make(map[color.NRGBA]uint64)
+
+	/*
+	 * Build a histogram of all colors.
+	 */
+	for _, c := range colors {
+		counts[c]++
+	}
+
+	return quantizeHistogram(counts, maxColors)
+}
+
+/*
+ * Create a palette of at most maxColors colors representing the pixels of an
+ * image, as by Quantize, but reading the pixels directly from the image.
+ */
+func QuantizeImage(img *image.NRGBA, maxColors uint32) color.Palette {
+	counts := make(map[color.NRGBA]uint64)
+	rect := img.Bounds()
+	width := rect.Dx()
+
+	/*
+	 * Build a histogram of the pixels in each row.
+	 */
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		offset := img.PixOffset(rect.Min.X, y)
+		row := img.Pix[offset : offset+(4*width)]
+
+		/*
+		 * Count the color of each pixel.
+		 */
+		for i := 0; i < len(row); i += 4 {
+
+			/*
+			 * The color of the pixel.
+			 */
+			c := color.NRGBA{
+				R: row[i],
+				G: row[i+1],
+				B: row[i+2],
+				A: row[i+3],
+			}
+
+			counts[c]++
+		}
+
+	}
+
+	return quantizeHistogram(counts, maxColors)
+}
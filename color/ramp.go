@@ -0,0 +1,351 @@
+package color
+
+import (
+	"image/color"
+	"math"
+)
+
+/*
+ * Data structure representing a color with floating-point channels, which
+ * range from zero to one and are not alpha-premultiplied.
+ */
+type rgba struct {
+	r float64
+	g float64
+	b float64
+	a float64
+}
+
+/*
+ * A color ramp maps an intensity between zero and one to a color.
+ */
+type rampFunc func(intensity float64) rgba
+
+/*
+ * Convert a floating-point channel value to an 8-bit channel value.
+ */
+func channel8(value float64) uint8 {
+	value = math.Round(255.0 * value)
+	value = clamp(value, 0.0, 255.0)
+	return uint8(value)
+}
+
+/*
+ * Convert a floating-point color to an 8-bit NRGBA color.
+ */
+func (this rgba) nrgba() color.NRGBA {
+	red := channel8(this.r)
+	green := channel8(this.g)
+	blue := channel8(this.b)
+	alpha := channel8(this.a)
+
+	/*
+	 * The resulting color.
+	 */
+	c := color.NRGBA{
+		R: red,
+		G: green,
+		B: blue,
+		A: alpha,
+	}
+
+	return c
+}
+
+/*
+ * Convert a floating-point channel value to a 16-bit channel value.
+ */
+func channel16(value float64) uint16 {
+	value = math.Round(65535.0 * value)
+	value = clamp(value, 0.0, 65535.0)
+	return uint16(value)
+}
+
+/*
+ * Convert a floating-point color to a 16-bit NRGBA color.
+ */
+func (this rgba) nrgba64() color.NRGBA64 {
+	red := channel16(this.r)
+	green := channel16(this.g)
+	blue := channel16(this.b)
+	alpha := channel16(this.a)
+
+	/*
+	 * The resulting color.
+	 */
+	c := color.NRGBA64{
+		R: red,
+		G: green,
+		B: blue,
+		A: alpha,
+	}
+
+	return c
+}
+
+/*
+ * Evaluate a polynomial with one coefficient per color channel at x.
+ *
+ * Coefficients are ordered by ascending degree.
+ */
+func polynomial(coefficients [][3]float64, x float64) rgba {
+	numCoefficients := len(coefficients)
+	r := float64(0.0)
+	g := float64(0.0)
+	b := float64(0.0)
+
+	/*
+	 * Evaluate polynomial using Horner's method.
+	 */
+	for i := numCoefficients - 1; i >= 0; i-- {
+		c := coefficients[i]
+		r = (r * x) + c[0]
+		g = (g * x) + c[1]
+		b = (b * x) + c[2]
+	}
+
+	/*
+	 * The resulting color.
+	 */
+	result := rgba{
+		r: clamp(r, 0.0, 1.0),
+		g: clamp(g, 0.0, 1.0),
+		b: clamp(b, 0.0, 1.0),
+		a: 1.0,
+	}
+
+	return result
+}
+
+/*
+ * The color ramp of the default mapping, ranging from blue over cyan, green
+ * and yellow to white.
+ */
+func rainbow(intensity float64) rgba {
+	red := float64(0.0)
+	green := float64(0.0)
+	blue := float64(0.0)
+
+	/*
+	 * Map to a color.
+	 */
+	if intensity <= 0.25 {
+		diff := intensity - 0.0
+		green = 4.0 * diff
+		blue = 1.0
+	} else if intensity <= 0.5 {
+		diff := intensity - 0.25
+		green = 1.0
+		blue = 1.0 - (4.0 * diff)
+	} else if intensity <= 0.75 {
+		diff := intensity - 0.5
+		red = 4.0 * diff
+		green = 1.0
+	} else if intensity <= 1.0 {
+		diff := intensity - 0.75
+		red = 1.0
+		green = 1.0
+		blue = 4.0 * diff
+	} else {
+		red = 1.0
+		green = 1.0
+		blue = 1.0
+	}
+
+	/*
+	 * The resulting color.
+	 */
+	result := rgba{
+		r: red,
+		g: green,
+		b: blue,
+		a: 1.0,
+	}
+
+	return result
+}
+
+/*
+ * Polynomial approximation of the viridis color map.
+ */
+var viridisCoefficients = [][3]float64{
+	{0.2777273272234177, 0.005407344544966578, 0.3340998053353061},
+	{0.1050930431085774, 1.404613529898575, 1.384590162594685},
+	{-0.3308618287255563, 0.214847559468213, 0.09509516302823659},
+	{-4.634230498983486, -5.799100973351585, -19.33244095627987},
+	{6.228269936347081, 14.17993336680509, 56.69055260068105},
+	{4.776384997670288, -13.74514537774601, -65.35303263337234},
+	{-5.435455855934631, 4.645852612178535, 26.3124352495832},
+}
+
+/*
+ * The viridis color ramp, ranging from dark blue over green to yellow.
+ */
+func viridis(intensity float64) rgba {
+	intensity = clamp(intensity, 0.0, 1.0)
+	return polynomial(viridisCoefficients, intensity)
+}
+
+/*
+ * Polynomial approximation of the inferno color map.
+ */
+var infernoCoefficients = [][3]float64{
+	{0.0002189403691192265, 0.001651004631001012, -0.01948089843709184},
+	{0.1065134194856116, 0.5639564367884091, 3.932712388889277},
+	{11.60249308247187, -3.972853965665698, -15.9423941062914},
+	{-41.70399613139459, 17.43639888205313, 44.35414519872813},
+	{77.162935699427, -33.40235894210092, -81.80730925738993},
+	{-71.31942824499214, 32.62606426397723, 73.20951985803202},
+	{25.13112622477341, -12.24266895238567, -23.07032500287172},
+}
+
+/*
+ * Polynomial approximation of the magma color map.
+ */
+var magmaCoefficients = [][3]float64{
+	{-0.002136485053939582, -0.000749655052795221, -0.005386127855323933},
+	{0.2516605407371642, 0.6775232436837668, 2.494026599312351},
+	{8.353717279216625, -3.577719514958484, 0.3144679030132573},
+	{-27.66873308576866, 14.26473078096533, -13.64921318813922},
+	{52.17613981234068, -27.94360607168351, 12.94416944238394},
+	{-50.76852536473588, 29.04658282127291, 4.23415299384598},
+	{18.65570506591883, -11.48977351997711, -5.601961508734096},
+}
+
+/*
+ * Polynomial approximation of the plasma color map.
+ */
+var plasmaCoefficients = [][3]float64{
+	{0.05873234392399702, 0.02333670892565664, 0.5433401826748754},
+	{2.176514634195958, 0.2383834171260182, 0.7539604599784036},
+	{-2.689460476458034, -7.455851135738909, 3.110799939717086},
+	{6.130348345893603, 42.3461881477227, -28.51885465332158},
+	{-11.10743619062271, -82.66631109428045, 60.13984767418263},
+	{10.02306557647065, 71.41361770095349, -54.07218655560067},
+	{-3.658713842777788, -22.93153465461149, 18.19190778539828},
+}
+
+/*
+ * The inferno color ramp, ranging from black over purple and red to pale
+ * yellow.
+ */
+func inferno(intensity float64) rgba {
+	intensity = clamp(intensity, 0.0, 1.0)
+	return polynomial(infernoCoefficients, intensity)
+}
+
+/*
+ * The magma color ramp, ranging from black over purple and pink to pale
+ * yellow.
+ */
+func magma(intensity float64) rgba {
+	intensity = clamp(intensity, 0.0, 1.0)
+	return polynomial(magmaCoefficients, intensity)
+}
+
+/*
+ * The plasma color ramp, ranging from dark blue over magenta and orange to
+ * yellow.
+ */
+func plasma(intensity float64) rgba {
+	intensity = clamp(intensity, 0.0, 1.0)
+	return polynomial(plasmaCoefficients, intensity)
+}
+
+/*
+ * Create a cubehelix color ramp, which spirals around the gray diagonal of the
+ * RGB cube while increasing monotonically in perceived brightness.
+ *
+ * The start parameter selects the hue at the beginning of the ramp (0 is blue,
+ * 1 is red, 2 is green), rotations is the number of rotations around the gray
+ * diagonal, hue controls the saturation and gamma emphasizes low (gamma < 1)
+ * or high (gamma > 1) intensities.
+ */
+func cubehelix(start float64, rotations float64, hue float64, gamma float64) rampFunc {
+
+	/*
+	 * The color ramp.
+	 */
+	ramp := func(intensity float64) rgba {
+		intensity = clamp(intensity, 0.0, 1.0)
+		lambda := math.Pow(intensity, gamma)
+		amplitude := 0.5 * hue * lambda * (1.0 - lambda)
+		phi := 2.0 * math.Pi * ((start / 3.0) + (rotations * intensity))
+		cosPhi := math.Cos(phi)
+		sinPhi := math.Sin(phi)
+		r := lambda + (amplitude * ((-0.14861 * cosPhi) + (1.78277 * sinPhi)))
+		g := lambda + (amplitude * ((-0.29227 * cosPhi) - (0.90649 * sinPhi)))
+		b := lambda + (amplitude * (1.97294 * cosPhi))
+
+		/*
+		 * The resulting color.
+		 */
+		result := rgba{
+			r: clamp(r, 0.0, 1.0),
+			g: clamp(g, 0.0, 1.0),
+			b: clamp(b, 0.0, 1.0),
+			a: 1.0,
+		}
+
+		return result
+	}
+
+	return ramp
+}
+
+/*
+ * Polynomial approximation of the turbo color map.
+ */
+var turboCoefficients = [][3]float64{
+	{0.13572138, 0.09140261, 0.10667330},
+	{4.61539260, 2.19418839, 12.64194608},
+	{-42.66032258, 4.84296658, -60.58204836},
+	{132.13108234, -14.18503333, 110.36276771},
+	{-152.94239396, 4.27729857, -89.90310912},
+	{59.28637943, 2.82956604, 27.34824973},
+}
+
+/*
+ * The turbo color ramp, ranging from dark blue over cyan, green, yellow and
+ * orange to dark red.
+ */
+func turbo(intensity float64) rgba {
+	intensity = clamp(intensity, 0.0, 1.0)
+	return polynomial(turboCoefficients, intensity)
+}
+
+/*
+ * Create a color ramp, which maps all intensities to a fixed color.
+ */
+func solid(c color.NRGBA) rampFunc {
+	base := fromNRGBA(c)
+
+	/*
+	 * The color ramp.
+	 */
+	ramp := func(intensity float64) rgba {
+		return base
+	}
+
+	return ramp
+}
+
+/*
+ * Create a color ramp with a fixed color, whose opacity increases from fully
+ * transparent to fully opaque.
+ */
+func transparency(c color.NRGBA) rampFunc {
+	base := fromNRGBA(c)
+
+	/*
+	 * The color ramp.
+	 */
+	ramp := func(intensity float64) rgba {
+		intensity = clamp(intensity, 0.0, 1.0)
+		result := base
+		result.a = intensity
+		return result
+	}
+
+	return ramp
+}
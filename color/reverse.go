@@ -0,0 +1,108 @@
+package color
+
+import (
+	"image/color"
+)
+
+/*
+ * Data structure representing a color mapping which reverses the order of
+ * the non-zero counts before mapping them using another mapping.
+ */
+type reversedMappingStruct struct {
+	mapping Mapping
+}
+
+/*
+ * Map each count to a color value.
+ */
+func (this *reversedMappingStruct) Map(counts []uint64) []color.NRGBA {
+	min := uint64(0)
+	max := uint64(0)
+
+	/*
+	 * Find the smallest and largest non-zero count.
+	 */
+	for _, count := range counts {
+
+		/*
+		 * Only consider non-empty bins.
+		 */
+		if count > 0 {
+
+			/*
+			 * Update minimum and maximum.
+			 */
+			if (min == 0) || (count < min) {
+				min = count
+			}
+
+			if count > max {
+				max = count
+			}
+
+		}
+
+	}
+
+	n := len(counts)
+	reversed := make([]uint64, n)
+
+	/*
+	 * Mirror each non-zero count within the range of counts.
+	 */
+	for i, count := range counts {
+
+		/*
+		 * Empty bins remain empty.
+		 */
+		if count > 0 {
+			reversed[i] = (max - count) + min
+		}
+
+	}
+
+	return this.mapping.Map(reversed)
+}
+
+/*
+ * Create a new color mapping, which flips the direction in which intensities
+ * map to colors, so that low densities map to the colors originally used for
+ * high densities and vice versa.
+ *
+ * Mappings provided by this package are reversed exactly by reversing their
+ * color ramp. Any other mapping is reversed by mirroring the non-zero counts
+ * within the range of counts before mapping them, which is exact for mappings
+ * which scale counts linearly. If m is nil, this returns nil.
+ */
+func Reverse(m Mapping) Mapping {
+
+	/*
+	 * Decide on how to reverse the mapping.
+	 */
+	if m == nil {
+		return nil
+	} else if rm, ok := m.(*rampMappingStruct); ok {
+		ramp := rm.ramp
+		reversed := *rm
+
+		/*
+		 * The reversed color ramp.
+		 */
+		reversed.ramp = func(intensity float64) rgba {
+			return ramp(1.0 - intensity)
+		}
+
+		return &reversed
+	} else {
+
+		/*
+		 * Create reversed color mapping.
+		 */
+		reversed := reversedMappingStruct{
+			mapping: m,
+		}
+
+		return &reversed
+	}
+
+}
@@ -0,0 +1,472 @@
+package color
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+ * A scale maps a (non-zero) count to an intensity between zero and one, given
+ * the maximum count of the distribution.
+ */
+type Scale interface {
+	Intensity(count uint64, max uint64) float64
+}
+
+/*
+ * An adaptive scale depends on the entire distribution being mapped, e. g. on
+ * its histogram or its percentiles.
+ *
+ * Before mapping a distribution, color mappings fit an adaptive scale to the
+ * distribution and use the resulting scale to map its counts.
+ */
+type AdaptiveScale interface {
+	Scale
+	Fit(counts []uint64) Scale
+}
+
+/*
+ * Data structure representing a logarithmic scale.
+ */
+type logarithmicScaleStruct struct {
+}
+
+/*
+ * Data structure representing a logarithmic scale, which is offset by one.
+ */
+type log1pScaleStruct struct {
+}
+
+/*
+ * Data structure representing a linear scale.
+ */
+type linearScaleStruct struct {
+}
+
+/*
+ * Data structure representing a power-law scale.
+ */
+type gammaScaleStruct struct {
+	gamma float64
+}
+
+/*
+ * Data structure representing a scale, which equalizes the histogram of a
+ * distribution.
+ */
+type equalizedScaleStruct struct {
+	cumulative []uint64
+	values     []uint64
+}
+
+/*
+ * Data structure representing a scale, which clips counts at a percentile
+ * of a distribution.
+ */
+type percentileScaleStruct struct {
+	percentile float64
+	scale      Scale
+	threshold  uint64
+}
+
+/*
+ * An adaptive scale provided by this package, which can be fitted to the
+ * histogram of a distribution.
+ */
+type histogramScale interface {
+	fitHistogram(histogram map[uint64]uint64) Scale
+}
+
+/*
+ * Count how often each non-zero count occurs in a distribution.
+ */
+func histogram(counts []uint64) map[uint64]uint64 {
+	result := make(map[uint64]uint64)
+
+	/*
+	 * Iterate over the distribution.
+	 */
+	for _, count := range counts {
+
+		/*
+		 * Only consider non-empty bins.
+		 */
+		if count > 0 {
+			result[count]++
+		}
+
+	}
+
+	return result
+}
+
+/*
+ * Returns the distinct values of a histogram in ascending order, along with
+ * the number of occurences of values up to and including each of them.
+ */
+func cumulativeHistogram(histogram map[uint64]uint64) ([]uint64, []uint64) {
+	numValues := len(histogram)
+	values := make([]uint64, 0, numValues)
+
+	/*
+	 * Collect all distinct values.
+	 */
+	for value := range histogram {
+		values = append(values, value)
+	}
+
+	/*
+	 * Order values ascending.
+	 */
+	sort.Slice(values, func(i int, j int) bool {
+		return values[i] < values[j]
+	})
+
+	cumulative := make([]uint64, numValues)
+	sum := uint64(0)
+
+	/*
+	 * Sum up the occurences.
+	 */
+	for i, value := range values {
+		sum += histogram[value]
+		cumulative[i] = sum
+	}
+
+	return values, cumulative
+}
+
+/*
+ * Map a count to log(count) / log(max).
+ *
+ * If max is one, all non-zero counts map to full intensity.
+ */
+func (this *logarithmicScaleStruct) Intensity(count uint64, max uint64) float64 {
+	countFloat := float64(count)
+	countLog := math.Log(countFloat)
+	maxFloat := float64(max)
+	maxLog := math.Log(maxFloat)
+	frac := countLog / maxLog
+
+	/*
+	 * If all non-empty bins have a count of one, map them to full
+	 * intensity.
+	 */
+	if math.IsNaN(frac) {
+		frac = 1.0
+	}
+
+	return frac
+}
+
+/*
+ * Map a count to log(1 + count) / log(1 + max).
+ */
+func (this *log1pScaleStruct) Intensity(count uint64, max uint64) float64 {
+
+	/*
+	 * Avoid division by zero.
+	 */
+	if max == 0 {
+		return 0.0
+	} else {
+		countFloat := float64(count)
+		countLog := math.Log1p(countFloat)
+		maxFloat := float64(max)
+		maxLog := math.Log1p(maxFloat)
+		return countLog / maxLog
+	}
+
+}
+
+/*
+ * Map a count to count / max.
+ */
+func (this *linearScaleStruct) Intensity(count uint64, max uint64) float64 {
+
+	/*
+	 * Avoid division by zero.
+	 */
+	if max == 0 {
+		return 0.0
+	} else {
+		countFloat := float64(count)
+		maxFloat := float64(max)
+		return countFloat / maxFloat
+	}
+
+}
+
+/*
+ * Map a count to count^gamma / max^gamma.
+ */
+func (this *gammaScaleStruct) Intensity(count uint64, max uint64) float64 {
+
+	/*
+	 * Avoid division by zero.
+	 */
+	if max == 0 {
+		return 0.0
+	} else {
+		countFloat := float64(count)
+		maxFloat := float64(max)
+		frac := countFloat / maxFloat
+		gamma := this.gamma
+		return math.Pow(frac, gamma)
+	}
+
+}
+
+/*
+ * Create a new logarithmic scale, which maps counts to log(count) / log(max).
+ *
+ * This is the default scale of all color mappings. It makes best use of the
+ * available dynamic range for distributions spanning many orders of
+ * magnitude.
+ */
+func LogarithmicScale() Scale {
+	s := logarithmicScaleStruct{}
+	return &s
+}
+
+/*
+ * Create a new logarithmic scale, which maps counts to
+ * log(1 + count) / log(1 + max).
+ *
+ * Unlike the default logarithmic scale, which maps a count of one to zero
+ * intensity, this keeps bins with a single hit distinguishable from empty
+ * bins, even if the maximum count is large.
+ */
+func Log1pScale() Scale {
+	s := log1pScaleStruct{}
+	return &s
+}
+
+/*
+ * Create a new linear scale, which maps counts to count / max.
+ *
+ * This preserves contrast for distributions whose counts span a small range.
+ */
+func LinearScale() Scale {
+	s := linearScaleStruct{}
+	return &s
+}
+
+/*
+ * Create a new power-law scale, which maps counts to count^gamma / max^gamma.
+ *
+ * A gamma of one is equivalent to a linear scale, while values between zero
+ * and one increasingly emphasize low counts, approaching the contrast of a
+ * logarithmic scale.
+ */
+func GammaScale(gamma float64) Scale {
+
+	/*
+	 * Create power-law scale.
+	 */
+	s := gammaScaleStruct{
+		gamma: gamma,
+	}
+
+	return &s
+}
+
+/*
+ * Map a count to the fraction of non-empty bins of the fitted distribution
+ * with a lower count.
+ *
+ * If the scale was not fitted to a distribution, counts are mapped linearly.
+ */
+func (this *equalizedScaleStruct) Intensity(count uint64, max uint64) float64 {
+	values := this.values
+	numValues := len(values)
+
+	/*
+	 * Check if the scale was fitted to a distribution.
+	 */
+	if numValues == 0 {
+		s := linearScaleStruct{}
+		return s.Intensity(count, max)
+	} else {
+		cumulative := this.cumulative
+		first := cumulative[0]
+		total := cumulative[numValues-1]
+
+		/*
+		 * If all non-empty bins have the same count, map them to full
+		 * intensity.
+		 */
+		if total == first {
+			return 1.0
+		} else {
+
+			/*
+			 * Find the number of distinct values not exceeding the
+			 * count.
+			 */
+			idx := sort.Search(numValues, func(i int) bool {
+				return values[i] > count
+			})
+
+			/*
+			 * Counts below the smallest value map to zero
+			 * intensity.
+			 */
+			if idx == 0 {
+				return 0.0
+			} else {
+				below := float64(cumulative[idx-1] - first)
+				spread := float64(total - first)
+				return below / spread
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Fit the scale to a histogram.
+ */
+func (this *equalizedScaleStruct) fitHistogram(histogram map[uint64]uint64) Scale {
+	values, cumulative := cumulativeHistogram(histogram)
+
+	/*
+	 * Create fitted scale.
+	 */
+	s := equalizedScaleStruct{
+		cumulative: cumulative,
+		values:     values,
+	}
+
+	return &s
+}
+
+/*
+ * Fit the scale to the histogram of a distribution.
+ */
+func (this *equalizedScaleStruct) Fit(counts []uint64) Scale {
+	h := histogram(counts)
+	return this.fitHistogram(h)
+}
+
+/*
+ * Map a count using the underlying scale, treating the count at the
+ * percentile as the maximum.
+ *
+ * If the scale was not fitted to a distribution, the maximum count is used.
+ */
+func (this *percentileScaleStruct) Intensity(count uint64, max uint64) float64 {
+	threshold := this.threshold
+
+	/*
+	 * Use the maximum count if the scale was not fitted.
+	 */
+	if threshold == 0 {
+		threshold = max
+	}
+
+	/*
+	 * Clip counts above the threshold.
+	 */
+	if count > threshold {
+		count = threshold
+	}
+
+	return this.scale.Intensity(count, threshold)
+}
+
+/*
+ * Fit the scale to a histogram.
+ */
+func (this *percentileScaleStruct) fitHistogram(histogram map[uint64]uint64) Scale {
+	values, cumulative := cumulativeHistogram(histogram)
+	numValues := len(values)
+	threshold := uint64(0)
+
+	/*
+	 * Find the count at the percentile.
+	 */
+	if numValues > 0 {
+		total := cumulative[numValues-1]
+		totalFloat := float64(total)
+		rank := math.Ceil((this.percentile / 100.0) * totalFloat)
+		rank = clamp(rank, 1.0, totalFloat)
+		rank64 := uint64(rank)
+
+		/*
+		 * Find the first value reaching the rank.
+		 */
+		idx := sort.Search(numValues, func(i int) bool {
+			return cumulative[i] >= rank64
+		})
+
+		threshold = values[idx]
+	}
+
+	/*
+	 * Create fitted scale.
+	 */
+	s := percentileScaleStruct{
+		percentile: this.percentile,
+		scale:      this.scale,
+		threshold:  threshold,
+	}
+
+	return &s
+}
+
+/*
+ * Fit the scale to the percentiles of a distribution.
+ */
+func (this *percentileScaleStruct) Fit(counts []uint64) Scale {
+	h := histogram(counts)
+	return this.fitHistogram(h)
+}
+
+/*
+ * Create a new histogram equalization scale, which maps each count to the
+ * fraction of non-empty bins with a lower count.
+ *
+ * This spreads the intensities of the bins evenly across the color ramp, so
+ * that structure is visible at all densities, at the cost of no longer
+ * reflecting the magnitude of the counts. This scale adapts to the
+ * distribution being mapped.
+ */
+func EqualizedScale() Scale {
+	s := equalizedScaleStruct{}
+	return &s
+}
+
+/*
+ * Create a new percentile scale, which treats the count at a percentile
+ * (between 0 and 100) of the non-empty bins as the maximum, mapping all
+ * larger counts to full intensity.
+ *
+ * This prevents a few extreme outliers from compressing the color range of
+ * all other bins. Counts up to the percentile are mapped using the given
+ * scale, or logarithmically if scale is nil. This scale adapts to the
+ * distribution being mapped.
+ */
+func PercentileScale(percentile float64, scale Scale) Scale {
+
+	/*
+	 * Use default scale if none is given.
+	 */
+	if scale == nil {
+		scale = LogarithmicScale()
+	}
+
+	percentile = clamp(percentile, 0.0, 100.0)
+
+	/*
+	 * Create percentile scale.
+	 */
+	s := percentileScaleStruct{
+		percentile: percentile,
+		scale:      scale,
+	}
+
+	return &s
+}
@@ -0,0 +1,418 @@
+package color
+
+import (
+	"math"
+)
+
+/*
+ * Color spaces in which gradients may be interpolated.
+ */
+const (
+	INTERPOLATION_RGB   = 0
+	INTERPOLATION_HSV   = 1
+	INTERPOLATION_LAB   = 2
+	INTERPOLATION_OKLAB = 3
+)
+
+/*
+ * Data structure representing a color in an arbitrary color space, with an
+ * additional alpha channel.
+ */
+type coords struct {
+	c0    float64
+	c1    float64
+	c2    float64
+	alpha float64
+}
+
+/*
+ * Convert a gamma-encoded sRGB channel value to linear light.
+ */
+func linearize(value float64) float64 {
+
+	/*
+	 * Apply the inverse sRGB transfer function.
+	 */
+	if value <= 0.04045 {
+		return value / 12.92
+	} else {
+		return math.Pow((value+0.055)/1.055, 2.4)
+	}
+
+}
+
+/*
+ * Convert a linear light channel value to gamma-encoded sRGB.
+ */
+func delinearize(value float64) float64 {
+
+	/*
+	 * Apply the sRGB transfer function.
+	 */
+	if value <= 0.0031308 {
+		return 12.92 * value
+	} else {
+		return (1.055 * math.Pow(value, 1.0/2.4)) - 0.055
+	}
+
+}
+
+/*
+ * Nonlinearity of the CIELAB color space.
+ */
+func labF(t float64) float64 {
+	delta := 6.0 / 29.0
+
+	/*
+	 * Use linear segment near zero.
+	 */
+	if t > (delta * delta * delta) {
+		return math.Cbrt(t)
+	} else {
+		return (t / (3.0 * delta * delta)) + (4.0 / 29.0)
+	}
+
+}
+
+/*
+ * Inverse of the nonlinearity of the CIELAB color space.
+ */
+func labFInverse(t float64) float64 {
+	delta := 6.0 / 29.0
+
+	/*
+	 * Use linear segment near zero.
+	 */
+	if t > delta {
+		return t * t * t
+	} else {
+		return 3.0 * delta * delta * (t - (4.0 / 29.0))
+	}
+
+}
+
+/*
+ * Convert an sRGB color to HSV.
+ */
+func toHSV(c rgba) coords {
+	r := c.r
+	g := c.g
+	b := c.b
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+	hue := float64(0.0)
+	saturation := float64(0.0)
+
+	/*
+	 * Determine the hue.
+	 */
+	if delta > 0.0 {
+
+		/*
+		 * Decide on the sector of the hue circle.
+		 */
+		if max == r {
+			hue = math.Mod((g-b)/delta, 6.0)
+		} else if max == g {
+			hue = ((b - r) / delta) + 2.0
+		} else {
+			hue = ((r - g) / delta) + 4.0
+		}
+
+		/*
+		 * Make sure hue is non-negative.
+		 */
+		if hue < 0.0 {
+			hue += 6.0
+		}
+
+		hue /= 6.0
+	}
+
+	/*
+	 * Determine the saturation.
+	 */
+	if max > 0.0 {
+		saturation = delta / max
+	}
+
+	/*
+	 * The resulting color.
+	 */
+	result := coords{
+		c0:    hue,
+		c1:    saturation,
+		c2:    max,
+		alpha: c.a,
+	}
+
+	return result
+}
+
+/*
+ * Convert an HSV color to sRGB.
+ */
+func fromHSV(c coords) rgba {
+	hue := c.c0 - math.Floor(c.c0)
+	saturation := c.c1
+	value := c.c2
+	sector := 6.0 * hue
+	chroma := value * saturation
+	x := chroma * (1.0 - math.Abs(math.Mod(sector, 2.0)-1.0))
+	m := value - chroma
+	r := float64(0.0)
+	g := float64(0.0)
+	b := float64(0.0)
+
+	/*
+	 * Decide on the sector of the hue circle.
+	 */
+	switch {
+	case sector < 1.0:
+		r, g, b = chroma, x, 0.0
+	case sector < 2.0:
+		r, g, b = x, chroma, 0.0
+	case sector < 3.0:
+		r, g, b = 0.0, chroma, x
+	case sector < 4.0:
+		r, g, b = 0.0, x, chroma
+	case sector < 5.0:
+		r, g, b = x, 0.0, chroma
+	default:
+		r, g, b = chroma, 0.0, x
+	}
+
+	/*
+	 * The resulting color.
+	 */
+	result := rgba{
+		r: r + m,
+		g: g + m,
+		b: b + m,
+		a: c.alpha,
+	}
+
+	return result
+}
+
+/*
+ * Convert an sRGB color to CIELAB (D65 white point).
+ */
+func toLab(c rgba) coords {
+	r := linearize(c.r)
+	g := linearize(c.g)
+	b := linearize(c.b)
+	x := ((0.4124 * r) + (0.3576 * g) + (0.1805 * b)) / 0.95047
+	y := (0.2126 * r) + (0.7152 * g) + (0.0722 * b)
+	z := ((0.0193 * r) + (0.1192 * g) + (0.9505 * b)) / 1.08883
+	fx := labF(x)
+	fy := labF(y)
+	fz := labF(z)
+
+	/*
+	 * The resulting color.
+	 */
+	result := coords{
+		c0:    (116.0 * fy) - 16.0,
+		c1:    500.0 * (fx - fy),
+		c2:    200.0 * (fy - fz),
+		alpha: c.a,
+	}
+
+	return result
+}
+
+/*
+ * Convert a CIELAB color (D65 white point) to sRGB.
+ */
+func fromLab(c coords) rgba {
+	fy := (c.c0 + 16.0) / 116.0
+	fx := fy + (c.c1 / 500.0)
+	fz := fy - (c.c2 / 200.0)
+	x := 0.95047 * labFInverse(fx)
+	y := labFInverse(fy)
+	z := 1.08883 * labFInverse(fz)
+	r := (3.2406 * x) - (1.5372 * y) - (0.4986 * z)
+	g := (-0.9689 * x) + (1.8758 * y) + (0.0415 * z)
+	b := (0.0557 * x) - (0.2040 * y) + (1.0570 * z)
+
+	/*
+	 * The resulting color.
+	 */
+	result := rgba{
+		r: clamp(delinearize(r), 0.0, 1.0),
+		g: clamp(delinearize(g), 0.0, 1.0),
+		b: clamp(delinearize(b), 0.0, 1.0),
+		a: c.alpha,
+	}
+
+	return result
+}
+
+/*
+ * Convert an sRGB color to Oklab.
+ */
+func toOklab(c rgba) coords {
+	r := linearize(c.r)
+	g := linearize(c.g)
+	b := linearize(c.b)
+	l := math.Cbrt((0.4122214708 * r) + (0.5363325363 * g) + (0.0514459929 * b))
+	m := math.Cbrt((0.2119034982 * r) + (0.6806995451 * g) + (0.1073969566 * b))
+	s := math.Cbrt((0.0883024619 * r) + (0.2817188376 * g) + (0.6299787005 * b))
+
+	/*
+	 * The resulting color.
+	 */
+	result := coords{
+		c0:    (0.2104542553 * l) + (0.7936177850 * m) - (0.0040720468 * s),
+		c1:    (1.9779984951 * l) - (2.4285922050 * m) + (0.4505937099 * s),
+		c2:    (0.0259040371 * l) + (0.7827717662 * m) - (0.8086757660 * s),
+		alpha: c.a,
+	}
+
+	return result
+}
+
+/*
+ * Convert an Oklab color to sRGB.
+ */
+func fromOklab(c coords) rgba {
+	lightness := c.c0
+	a := c.c1
+	b := c.c2
+	l := lightness + (0.3963377774 * a) + (0.2158037573 * b)
+	m := lightness - (0.1055613458 * a) - (0.0638541728 * b)
+	s := lightness - (0.0894841775 * a) - (1.2914855480 * b)
+	l = l * l * l
+	m = m * m * m
+	s = s * s * s
+	red := (4.0767416621 * l) - (3.3077115913 * m) + (0.2309699292 * s)
+	green := (-1.2684380046 * l) + (2.6097574011 * m) - (0.3413193965 * s)
+	blue := (-0.0041960863 * l) - (0.7034186147 * m) + (1.7076147010 * s)
+
+	/*
+	 * The resulting color.
+	 */
+	result := rgba{
+		r: clamp(delinearize(red), 0.0, 1.0),
+		g: clamp(delinearize(green), 0.0, 1.0),
+		b: clamp(delinearize(blue), 0.0, 1.0),
+		a: c.alpha,
+	}
+
+	return result
+}
+
+/*
+ * Convert an sRGB color into the color space used for interpolation.
+ */
+func toSpace(c rgba, space uint8) coords {
+
+	/*
+	 * Decide on the color space.
+	 */
+	switch space {
+	case INTERPOLATION_HSV:
+		return toHSV(c)
+	case INTERPOLATION_LAB:
+		return toLab(c)
+	case INTERPOLATION_OKLAB:
+		return toOklab(c)
+	default:
+
+		/*
+		 * Use sRGB coordinates directly.
+		 */
+		result := coords{
+			c0:    c.r,
+			c1:    c.g,
+			c2:    c.b,
+			alpha: c.a,
+		}
+
+		return result
+	}
+
+}
+
+/*
+ * Convert a color from the color space used for interpolation to sRGB.
+ */
+func fromSpace(c coords, space uint8) rgba {
+
+	/*
+	 * Decide on the color space.
+	 */
+	switch space {
+	case INTERPOLATION_HSV:
+		return fromHSV(c)
+	case INTERPOLATION_LAB:
+		return fromLab(c)
+	case INTERPOLATION_OKLAB:
+		return fromOklab(c)
+	default:
+
+		/*
+		 * Use sRGB coordinates directly.
+		 */
+		result := rgba{
+			r: c.c0,
+			g: c.c1,
+			b: c.c2,
+			a: c.alpha,
+		}
+
+		return result
+	}
+
+}
+
+/*
+ * Interpolate linearly between two colors in a color space.
+ *
+ * In HSV space, hue is interpolated along the shorter arc of the hue circle
+ * and the hue of achromatic colors is taken from the other color.
+ */
+func interpolate(a coords, b coords, t float64, space uint8) coords {
+	s := 1.0 - t
+
+	/*
+	 * Handle circular hue in HSV space.
+	 */
+	if space == INTERPOLATION_HSV {
+
+		/*
+		 * Achromatic colors adopt the hue of the other color.
+		 */
+		if a.c1 == 0.0 {
+			a.c0 = b.c0
+		} else if b.c1 == 0.0 {
+			b.c0 = a.c0
+		}
+
+		diff := b.c0 - a.c0
+
+		/*
+		 * Take the shorter arc around the hue circle.
+		 */
+		if diff > 0.5 {
+			b.c0 -= 1.0
+		} else if diff < -0.5 {
+			b.c0 += 1.0
+		}
+
+	}
+
+	/*
+	 * The resulting color.
+	 */
+	result := coords{
+		c0:    (s * a.c0) + (t * b.c0),
+		c1:    (s * a.c1) + (t * b.c1),
+		c2:    (s * a.c2) + (t * b.c2),
+		alpha: (s * a.alpha) + (t * b.alpha),
+	}
+
+	return result
+}
@@ -0,0 +1,217 @@
+package coordinates
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+ * Normalize a longitude (in radians) into the interval (-pi, pi].
+ */
+func NormalizeLongitude(longitude float64) float64 {
+	twoPi := 2.0 * math.Pi
+	wrapped := math.Mod(longitude-math.Pi, twoPi)
+
+	/*
+	 * The remainder has the sign of the dividend.
+	 */
+	if wrapped <= -twoPi {
+		wrapped += twoPi
+	} else if wrapped > 0.0 {
+		wrapped -= twoPi
+	}
+
+	return wrapped + math.Pi
+}
+
+/*
+ * Returns a geographic location, whose longitude is normalized into the
+ * interval (-pi, pi].
+ */
+func NormalizeGeographic(location Geographic) Geographic {
+	longitude := NormalizeLongitude(location.longitude)
+	return CreateGeographic(longitude, location.latitude)
+}
+
+/*
+ * Split a path, e. g. a track, into parts which do not cross the
+ * antimeridian.
+ *
+ * Consecutive locations are connected the shorter way around the globe. When
+ * a segment crosses the antimeridian, the current part ends at the crossing
+ * and a new part starts on the other side of the map, so that no spurious
+ * line across the whole map is drawn. The latitude of the crossing is
+ * interpolated linearly. All longitudes are normalized into (-pi, pi].
+ */
+func SplitAntimeridian(path []Geographic) [][]Geographic {
+	parts := [][]Geographic{}
+	current := []Geographic{}
+
+	/*
+	 * Process all locations along the path.
+	 */
+	for i, location := range path {
+		b := NormalizeGeographic(location)
+
+		/*
+		 * Check if the segment leading to this location crosses the
+		 * antimeridian.
+		 */
+		if i > 0 {
+			a := current[len(current)-1]
+			diff := b.longitude - a.longitude
+
+			/*
+			 * The segment crosses the antimeridian if the shorter way
+			 * around the globe leaves the interval (-pi, pi].
+			 */
+			if math.Abs(diff) > math.Pi {
+				delta := NormalizeLongitude(diff)
+				edge := math.Pi
+
+				/*
+				 * Check if segment heads west.
+				 */
+				if delta < 0.0 {
+					edge = -math.Pi
+				}
+
+				f := (edge - a.longitude) / delta
+				latitude := a.latitude + (f * (b.latitude - a.latitude))
+				end := CreateGeographic(edge, latitude)
+				start := CreateGeographic(-edge, latitude)
+				current = append(current, end)
+				parts = append(parts, current)
+				current = []Geographic{start}
+			}
+
+		}
+
+		current = append(current, b)
+	}
+
+	/*
+	 * Add the last part, if any.
+	 */
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+
+	return parts
+}
+
+/*
+ * Returns the smallest bounding box containing a set of locations, which
+ * may wrap around the antimeridian.
+ *
+ * The minimum longitude of the bounding box lies in (-pi, pi]. If the
+ * bounding box crosses the antimeridian, its maximum longitude exceeds pi.
+ * Use Split to obtain bounding boxes within (-pi, pi].
+ */
+func EnclosingGeographicBounds(locations []Geographic) GeographicBounds {
+	numLocations := len(locations)
+
+	/*
+	 * An empty set of locations has an empty bounding box.
+	 */
+	if numLocations == 0 {
+		return EmptyGeographicBounds()
+	} else {
+		longitudes := make([]float64, numLocations)
+		minLat := math.Inf(1)
+		maxLat := math.Inf(-1)
+
+		/*
+		 * Collect longitudes and find range of latitudes.
+		 */
+		for i, location := range locations {
+			longitudes[i] = NormalizeLongitude(location.longitude)
+			minLat = math.Min(minLat, location.latitude)
+			maxLat = math.Max(maxLat, location.latitude)
+		}
+
+		sort.Float64s(longitudes)
+		last := numLocations - 1
+		minLon := longitudes[0]
+		maxLon := longitudes[last]
+		largestGap := (minLon + (2.0 * math.Pi)) - maxLon
+
+		/*
+		 * The bounding box leaves out the largest gap between longitudes.
+		 */
+		for i := 0; i < last; i++ {
+			gap := longitudes[i+1] - longitudes[i]
+
+			/*
+			 * Check if this gap is larger than the largest one found.
+			 */
+			if gap > largestGap {
+				largestGap = gap
+				minLon = longitudes[i+1]
+				maxLon = longitudes[i] + (2.0 * math.Pi)
+			}
+
+		}
+
+		min := CreateGeographic(minLon, minLat)
+		max := CreateGeographic(maxLon, maxLat)
+		return GeographicBounds{max: max, min: min}
+	}
+
+}
+
+/*
+ * Split this bounding box into bounding boxes, whose longitudes lie within
+ * [-pi, pi].
+ *
+ * A bounding box crossing the antimeridian is split into a western and an
+ * eastern part, so that each can be used with projections limited to a
+ * single copy of the world. An empty bounding box results in no parts.
+ */
+func (this *GeographicBounds) Split() []GeographicBounds {
+	minLat := this.min.latitude
+	maxLat := this.max.latitude
+	span := this.max.longitude - this.min.longitude
+
+	/*
+	 * Decide on the parts of the bounding box.
+	 */
+	if this.IsEmpty() {
+		return []GeographicBounds{}
+	} else if span >= 2.0*math.Pi {
+		min := CreateGeographic(-math.Pi, minLat)
+		max := CreateGeographic(math.Pi, maxLat)
+		bounds := GeographicBounds{max: max, min: min}
+		return []GeographicBounds{bounds}
+	} else {
+		minLon := NormalizeLongitude(this.min.longitude)
+		maxLon := minLon + span
+
+		/*
+		 * Check if bounding box crosses the antimeridian.
+		 */
+		if maxLon <= math.Pi {
+			min := CreateGeographic(minLon, minLat)
+			max := CreateGeographic(maxLon, maxLat)
+			bounds := GeographicBounds{max: max, min: min}
+			return []GeographicBounds{bounds}
+		} else {
+			eastMin := CreateGeographic(minLon, minLat)
+			eastMax := CreateGeographic(math.Pi, maxLat)
+			westMin := CreateGeographic(-math.Pi, minLat)
+			westMax := CreateGeographic(maxLon-(2.0*math.Pi), maxLat)
+
+			/*
+			 * The parts on both sides of the antimeridian.
+			 */
+			parts := []GeographicBounds{
+				GeographicBounds{max: westMax, min: westMin},
+				GeographicBounds{max: eastMax, min: eastMin},
+			}
+
+			return parts
+		}
+
+	}
+
+}
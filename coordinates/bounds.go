@@ -0,0 +1,321 @@
+package coordinates
+
+import (
+	"math"
+)
+
+/*
+ * Data structure representing an axis-aligned bounding box in Cartesian
+ * coordinates.
+ *
+ * A bounding box, whose minimum exceeds its maximum along any axis, is
+ * empty. Bounding boxes are immutable.
+ */
+type CartesianBounds struct {
+	max Cartesian
+	min Cartesian
+}
+
+/*
+ * Data structure representing a bounding box in geographic coordinates,
+ * spanning a range of longitudes and latitudes.
+ *
+ * A bounding box, whose minimum exceeds its maximum along any axis, is
+ * empty. A bounding box crossing the antimeridian has a maximum longitude
+ * exceeding pi. Bounding boxes are immutable.
+ */
+type GeographicBounds struct {
+	max Geographic
+	min Geographic
+}
+
+/*
+ * Returns the corner of this bounding box with the largest coordinates.
+ */
+func (this *CartesianBounds) Max() Cartesian {
+	return this.max
+}
+
+/*
+ * Returns the corner of this bounding box with the smallest coordinates.
+ */
+func (this *CartesianBounds) Min() Cartesian {
+	return this.min
+}
+
+/*
+ * Returns the center of this bounding box.
+ */
+func (this *CartesianBounds) Center() Cartesian {
+	x := 0.5 * (this.min.x + this.max.x)
+	y := 0.5 * (this.min.y + this.max.y)
+	return CreateCartesian(x, y)
+}
+
+/*
+ * Returns the size of this bounding box, i. e. its width and height.
+ */
+func (this *CartesianBounds) Size() Cartesian {
+	width := this.max.x - this.min.x
+	height := this.max.y - this.min.y
+	return CreateCartesian(width, height)
+}
+
+/*
+ * Returns whether this bounding box contains no points at all.
+ */
+func (this *CartesianBounds) IsEmpty() bool {
+	return !(this.min.x <= this.max.x) || !(this.min.y <= this.max.y)
+}
+
+/*
+ * Returns whether this bounding box contains a point.
+ *
+ * Points on the boundary are contained.
+ */
+func (this *CartesianBounds) Contains(point Cartesian) bool {
+	x := point.x
+	y := point.y
+	return (x >= this.min.x) && (x <= this.max.x) && (y >= this.min.y) && (y <= this.max.y)
+}
+
+/*
+ * Returns the smallest bounding box containing both this bounding box and a
+ * point.
+ */
+func (this *CartesianBounds) Expand(point Cartesian) CartesianBounds {
+	minX := math.Min(this.min.x, point.x)
+	minY := math.Min(this.min.y, point.y)
+	maxX := math.Max(this.max.x, point.x)
+	maxY := math.Max(this.max.y, point.y)
+	min := CreateCartesian(minX, minY)
+	max := CreateCartesian(maxX, maxY)
+	return CartesianBounds{max: max, min: min}
+}
+
+/*
+ * Returns the smallest bounding box containing both this and another
+ * bounding box.
+ */
+func (this *CartesianBounds) Union(other CartesianBounds) CartesianBounds {
+
+	/*
+	 * Empty bounding boxes do not contribute.
+	 */
+	if other.IsEmpty() {
+		return *this
+	} else if this.IsEmpty() {
+		return other
+	} else {
+		result := this.Expand(other.min)
+		return result.Expand(other.max)
+	}
+
+}
+
+/*
+ * Returns the bounding box containing the points contained in both this and
+ * another bounding box, which is empty if they do not overlap.
+ */
+func (this *CartesianBounds) Intersect(other CartesianBounds) CartesianBounds {
+	minX := math.Max(this.min.x, other.min.x)
+	minY := math.Max(this.min.y, other.min.y)
+	maxX := math.Min(this.max.x, other.max.x)
+	maxY := math.Min(this.max.y, other.max.y)
+	min := CreateCartesian(minX, minY)
+	max := CreateCartesian(maxX, maxY)
+	return CartesianBounds{max: max, min: min}
+}
+
+/*
+ * Returns the corner of this bounding box with the largest longitude and
+ * latitude, i. e. its northeastern corner.
+ */
+func (this *GeographicBounds) Max() Geographic {
+	return this.max
+}
+
+/*
+ * Returns the corner of this bounding box with the smallest longitude and
+ * latitude, i. e. its southwestern corner.
+ */
+func (this *GeographicBounds) Min() Geographic {
+	return this.min
+}
+
+/*
+ * Returns the center of this bounding box.
+ */
+func (this *GeographicBounds) Center() Geographic {
+	longitude := 0.5 * (this.min.longitude + this.max.longitude)
+	latitude := 0.5 * (this.min.latitude + this.max.latitude)
+	return CreateGeographic(longitude, latitude)
+}
+
+/*
+ * Returns the size of this bounding box, i. e. the ranges of longitudes and
+ * latitudes it spans.
+ */
+func (this *GeographicBounds) Size() Geographic {
+	longitude := this.max.longitude - this.min.longitude
+	latitude := this.max.latitude - this.min.latitude
+	return CreateGeographic(longitude, latitude)
+}
+
+/*
+ * Returns whether this bounding box contains no locations at all.
+ */
+func (this *GeographicBounds) IsEmpty() bool {
+	return !(this.min.longitude <= this.max.longitude) || !(this.min.latitude <= this.max.latitude)
+}
+
+/*
+ * Returns whether this bounding box contains a location.
+ *
+ * Locations on the boundary are contained. Longitudes are compared modulo
+ * 2 pi, so that bounding boxes crossing the antimeridian contain locations
+ * on both sides of it.
+ */
+func (this *GeographicBounds) Contains(location Geographic) bool {
+	minLon := this.min.longitude
+	maxLon := this.max.longitude
+	latitude := location.latitude
+
+	/*
+	 * Check range of latitudes first.
+	 */
+	if !(latitude >= this.min.latitude) || !(latitude <= this.max.latitude) {
+		return false
+	} else if (maxLon - minLon) >= 2.0*math.Pi {
+		return !math.IsNaN(location.longitude)
+	} else {
+		longitude := location.longitude
+
+		/*
+		 * Shift longitude to the first turn after the minimum.
+		 */
+		if (longitude < minLon) || (longitude > maxLon) {
+			offset := math.Mod(longitude-minLon, 2.0*math.Pi)
+
+			/*
+			 * The remainder has the sign of the dividend.
+			 */
+			if offset < 0.0 {
+				offset += 2.0 * math.Pi
+			}
+
+			longitude = minLon + offset
+		}
+
+		return (longitude >= minLon) && (longitude <= maxLon)
+	}
+
+}
+
+/*
+ * Returns the smallest bounding box containing both this bounding box and a
+ * location.
+ */
+func (this *GeographicBounds) Expand(location Geographic) GeographicBounds {
+	minLon := math.Min(this.min.longitude, location.longitude)
+	minLat := math.Min(this.min.latitude, location.latitude)
+	maxLon := math.Max(this.max.longitude, location.longitude)
+	maxLat := math.Max(this.max.latitude, location.latitude)
+	min := CreateGeographic(minLon, minLat)
+	max := CreateGeographic(maxLon, maxLat)
+	return GeographicBounds{max: max, min: min}
+}
+
+/*
+ * Returns the smallest bounding box containing both this and another
+ * bounding box.
+ */
+func (this *GeographicBounds) Union(other GeographicBounds) GeographicBounds {
+
+	/*
+	 * Empty bounding boxes do not contribute.
+	 */
+	if other.IsEmpty() {
+		return *this
+	} else if this.IsEmpty() {
+		return other
+	} else {
+		result := this.Expand(other.min)
+		return result.Expand(other.max)
+	}
+
+}
+
+/*
+ * Returns the bounding box containing the locations contained in both this
+ * and another bounding box, which is empty if they do not overlap.
+ */
+func (this *GeographicBounds) Intersect(other GeographicBounds) GeographicBounds {
+	minLon := math.Max(this.min.longitude, other.min.longitude)
+	minLat := math.Max(this.min.latitude, other.min.latitude)
+	maxLon := math.Min(this.max.longitude, other.max.longitude)
+	maxLat := math.Min(this.max.latitude, other.max.latitude)
+	min := CreateGeographic(minLon, minLat)
+	max := CreateGeographic(maxLon, maxLat)
+	return GeographicBounds{max: max, min: min}
+}
+
+/*
+ * Creates an immutable data structure representing the bounding box spanned
+ * by two corners in Cartesian coordinates.
+ *
+ * The corners may be given in any order.
+ */
+func CreateCartesianBounds(a Cartesian, b Cartesian) CartesianBounds {
+	bounds := EmptyCartesianBounds()
+	bounds = bounds.Expand(a)
+	return bounds.Expand(b)
+}
+
+/*
+ * Creates an immutable data structure representing an empty bounding box in
+ * Cartesian coordinates, which may be expanded by points.
+ */
+func EmptyCartesianBounds() CartesianBounds {
+	inf := math.Inf(1)
+
+	/*
+	 * Create an empty bounding box.
+	 */
+	bounds := CartesianBounds{
+		max: CreateCartesian(-inf, -inf),
+		min: CreateCartesian(inf, inf),
+	}
+
+	return bounds
+}
+
+/*
+ * Creates an immutable data structure representing the bounding box spanned
+ * by two corners in geographic coordinates.
+ *
+ * The corners may be given in any order.
+ */
+func CreateGeographicBounds(a Geographic, b Geographic) GeographicBounds {
+	bounds := EmptyGeographicBounds()
+	bounds = bounds.Expand(a)
+	return bounds.Expand(b)
+}
+
+/*
+ * Creates an immutable data structure representing an empty bounding box in
+ * geographic coordinates, which may be expanded by locations.
+ */
+func EmptyGeographicBounds() GeographicBounds {
+	inf := math.Inf(1)
+
+	/*
+	 * Create an empty bounding box.
+	 */
+	bounds := GeographicBounds{
+		max: CreateGeographic(-inf, -inf),
+		min: CreateGeographic(inf, inf),
+	}
+
+	return bounds
+}
@@ -0,0 +1,72 @@
+package coordinates
+
+/*
+ * Convert geographic locations, whose longitudes and latitudes were given in
+ * degrees, to radians in place.
+ *
+ * This is useful when locations are read in bulk using CreateGeographic
+ * with values in degrees, so that ingest pipelines need not convert each
+ * location on its own.
+ */
+func ToRadians(locations []Geographic) {
+
+	/*
+	 * Convert each location.
+	 */
+	for i := range locations {
+		location := &locations[i]
+		location.longitude *= RADIANS_PER_DEGREE
+		location.latitude *= RADIANS_PER_DEGREE
+	}
+
+}
+
+/*
+ * Convert geographic locations from radians to degrees in place, e. g. before
+ * writing them out.
+ *
+ * Keep in mind that the converted locations no longer follow the convention
+ * of storing values in radians.
+ */
+func ToDegrees(locations []Geographic) {
+
+	/*
+	 * Convert each location.
+	 */
+	for i := range locations {
+		location := &locations[i]
+		location.longitude *= DEGREES_PER_RADIAN
+		location.latitude *= DEGREES_PER_RADIAN
+	}
+
+}
+
+/*
+ * Convert raw angles, e. g. interleaved longitudes and latitudes, from
+ * degrees to radians in place.
+ */
+func DegreesToRadians(values []float64) {
+
+	/*
+	 * Convert each value.
+	 */
+	for i := range values {
+		values[i] *= RADIANS_PER_DEGREE
+	}
+
+}
+
+/*
+ * Convert raw angles, e. g. interleaved longitudes and latitudes, from
+ * radians to degrees in place.
+ */
+func RadiansToDegrees(values []float64) {
+
+	/*
+	 * Convert each value.
+	 */
+	for i := range values {
+		values[i] *= DEGREES_PER_RADIAN
+	}
+
+}
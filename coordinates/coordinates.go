@@ -1,5 +1,17 @@
 package coordinates
 
+import (
+	"math"
+)
+
+/*
+ * Factors converting between degrees and radians.
+ */
+const (
+	RADIANS_PER_DEGREE = math.Pi / 180.0
+	DEGREES_PER_RADIAN = 180.0 / math.Pi
+)
+
 /*
  * Data structure representing geographic coordinates as longitude and latitude.
  *
@@ -38,6 +50,20 @@ func (this *Geographic) Longitude() float64 {
 	return this.longitude
 }
 
+/*
+ * Returns the latitude value of this geographic location in degrees.
+ */
+func (this *Geographic) LatitudeDegrees() float64 {
+	return DEGREES_PER_RADIAN * this.latitude
+}
+
+/*
+ * Returns the longitude value of this geographic location in degrees.
+ */
+func (this *Geographic) LongitudeDegrees() float64 {
+	return DEGREES_PER_RADIAN * this.longitude
+}
+
 /*
  * Returns the abscissa (x-coordinate) of this two-dimensional Cartesian vector.
  */
@@ -69,6 +95,18 @@ func CreateGeographic(longitude float64, latitude float64) Geographic {
 	return geo
 }
 
+/*
+ * Creates an immutable data structure storing geographic coordinates as longitude
+ * and latitude, given in degrees.
+ *
+ * The values are converted to radians.
+ */
+func CreateGeographicDegrees(longitude float64, latitude float64) Geographic {
+	longitude = RADIANS_PER_DEGREE * longitude
+	latitude = RADIANS_PER_DEGREE * latitude
+	return CreateGeographic(longitude, latitude)
+}
+
 /*
  * Creates an immutable data structure representing a two-dimensional vector in
  * Cartesian coordinates.
@@ -0,0 +1,471 @@
+package coordinates
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+ * Data structure representing a token in a textual representation of a
+ * location.
+ *
+ * A token is either a hemisphere (if hemisphere is non-zero), a separator,
+ * a unit (if unit is non-negative) or a number.
+ */
+type dmsToken struct {
+	hemisphere rune
+	separator  bool
+	unit       int
+	value      float64
+}
+
+/*
+ * Data structure representing one coordinate (latitude or longitude) in a
+ * textual representation of a location.
+ */
+type dmsComponent struct {
+	hemisphere rune
+	hasUnits   bool
+	units      []int
+	values     []float64
+}
+
+/*
+ * Returns the unit (0 for degrees, 1 for minutes, 2 for seconds) denoted by
+ * a character or -1 if the character does not denote a unit.
+ */
+func dmsUnit(r rune) int {
+
+	/*
+	 * Decide on the unit.
+	 */
+	switch r {
+	case '°', 'º', '˚':
+		return 0
+	case '\'', '′', '’', '‘':
+		return 1
+	case '"', '″', '”', '“':
+		return 2
+	default:
+		return -1
+	}
+
+}
+
+/*
+ * Split a textual representation of a location into tokens.
+ */
+func dmsTokenize(s string) ([]dmsToken, error) {
+	runes := []rune(s)
+	numRunes := len(runes)
+	tokens := []dmsToken{}
+
+	/*
+	 * Process all characters.
+	 */
+	for i := 0; i < numRunes; i++ {
+		r := runes[i]
+		upper := unicode.ToUpper(r)
+		unit := dmsUnit(r)
+
+		/*
+		 * Decide on the kind of token.
+		 */
+		if unicode.IsDigit(r) || (r == '.') || (r == '-') || (r == '+') {
+			j := i + 1
+
+			/*
+			 * Find the end of the number.
+			 */
+			for (j < numRunes) && (unicode.IsDigit(runes[j]) || (runes[j] == '.')) {
+				j++
+			}
+
+			text := string(runes[i:j])
+			value, err := strconv.ParseFloat(text, 64)
+
+			/*
+			 * Check if number is valid.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Invalid number '%s'.", text)
+			}
+
+			token := dmsToken{
+				unit:  -1,
+				value: value,
+			}
+
+			tokens = append(tokens, token)
+			i = j - 1
+		} else if unit >= 0 {
+
+			/*
+			 * Two apostrophes denote seconds.
+			 */
+			if (unit == 1) && ((i + 1) < numRunes) && (dmsUnit(runes[i+1]) == 1) {
+				unit = 2
+				i++
+			}
+
+			token := dmsToken{
+				unit: unit,
+			}
+
+			tokens = append(tokens, token)
+		} else if (upper == 'N') || (upper == 'S') || (upper == 'E') || (upper == 'W') {
+
+			token := dmsToken{
+				hemisphere: upper,
+				unit:       -1,
+			}
+
+			tokens = append(tokens, token)
+		} else if (r == ',') || (r == ';') || (r == '/') {
+
+			token := dmsToken{
+				separator: true,
+				unit:      -1,
+			}
+
+			tokens = append(tokens, token)
+		} else if !unicode.IsSpace(r) {
+			return nil, fmt.Errorf("Unexpected character '%c'.", r)
+		}
+
+	}
+
+	return tokens, nil
+}
+
+/*
+ * Group the tokens of a textual representation of a location into
+ * components.
+ */
+func dmsGroup(tokens []dmsToken) ([]dmsComponent, error) {
+	components := []dmsComponent{}
+	current := dmsComponent{}
+
+	/*
+	 * Finish the current component.
+	 */
+	finish := func() {
+
+		/*
+		 * Only keep components with values.
+		 */
+		if len(current.values) > 0 {
+			components = append(components, current)
+		}
+
+		current = dmsComponent{}
+	}
+
+	/*
+	 * Process all tokens.
+	 */
+	for _, token := range tokens {
+
+		/*
+		 * Decide on the kind of token.
+		 */
+		if token.hemisphere != 0 {
+
+			/*
+			 * The hemisphere either precedes or follows the values.
+			 */
+			if len(current.values) == 0 {
+				current.hemisphere = token.hemisphere
+			} else if current.hemisphere == 0 {
+				current.hemisphere = token.hemisphere
+				finish()
+			} else {
+				finish()
+				current.hemisphere = token.hemisphere
+			}
+
+		} else if token.separator {
+			finish()
+		} else if token.unit >= 0 {
+			numValues := len(current.values)
+
+			/*
+			 * A unit must follow a number.
+			 */
+			if (numValues == 0) || (current.units[numValues-1] >= 0) {
+				return nil, fmt.Errorf("%s", "Unit without number.")
+			}
+
+			/*
+			 * Degrees start a new component.
+			 */
+			if (token.unit == 0) && (numValues > 1) {
+				value := current.values[numValues-1]
+				current.values = current.values[:numValues-1]
+				current.units = current.units[:numValues-1]
+				finish()
+				current.values = []float64{value}
+				current.units = []int{-1}
+				numValues = 1
+			}
+
+			current.units[numValues-1] = token.unit
+			current.hasUnits = true
+		} else {
+
+			/*
+			 * A component has at most three values.
+			 */
+			if len(current.values) >= 3 {
+				finish()
+			}
+
+			current.values = append(current.values, token.value)
+			current.units = append(current.units, -1)
+		}
+
+	}
+
+	finish()
+	numComponents := len(components)
+
+	/*
+	 * Split plain numbers without units or hemispheres into two halves.
+	 */
+	if numComponents == 1 {
+		c := components[0]
+		numValues := len(c.values)
+
+		/*
+		 * Check if component can be split.
+		 */
+		if !c.hasUnits && (c.hemisphere == 0) && ((numValues % 2) == 0) {
+			half := numValues / 2
+
+			/*
+			 * The first half.
+			 */
+			first := dmsComponent{
+				units:  c.units[:half],
+				values: c.values[:half],
+			}
+
+			/*
+			 * The second half.
+			 */
+			second := dmsComponent{
+				units:  c.units[half:],
+				values: c.values[half:],
+			}
+
+			components = []dmsComponent{first, second}
+		}
+
+	}
+
+	return components, nil
+}
+
+/*
+ * Convert a component of a textual representation of a location to an angle
+ * in degrees.
+ */
+func (this *dmsComponent) degrees() (float64, error) {
+	result := float64(0.0)
+	negative := false
+	expected := 0
+
+	/*
+	 * Add up degrees, minutes and seconds.
+	 */
+	for i, value := range this.values {
+		unit := this.units[i]
+
+		/*
+		 * Values without units follow the previous unit.
+		 */
+		if unit < 0 {
+			unit = expected
+		}
+
+		/*
+		 * Check order of units.
+		 */
+		if unit < expected {
+			return 0.0, fmt.Errorf("%s", "Units out of order.")
+		} else if (unit > 0) && ((value < 0.0) || (value >= 60.0)) {
+			return 0.0, fmt.Errorf("Minutes and seconds must be within [0, 60), got %f.", value)
+		}
+
+		/*
+		 * A sign applies to the whole angle.
+		 */
+		if i == 0 {
+			negative = math.Signbit(value)
+			value = math.Abs(value)
+		} else if math.Signbit(value) {
+			return 0.0, fmt.Errorf("%s", "Only degrees may carry a sign.")
+		}
+
+		result += value / math.Pow(60.0, float64(unit))
+		expected = unit + 1
+	}
+
+	/*
+	 * Apply the sign and the hemisphere.
+	 */
+	if negative {
+		result = -result
+	}
+
+	/*
+	 * Southern and western hemispheres are negative.
+	 */
+	if (this.hemisphere == 'S') || (this.hemisphere == 'W') {
+		result = -result
+	}
+
+	return result, nil
+}
+
+/*
+ * Parse a geographic location from a textual representation, e. g. in
+ * degrees, minutes and seconds ("48°51'29.6\"N 2°17'40.2\"E"), in degrees
+ * and decimal minutes ("N 48°51.493' E 2°17.67'") or in decimal degrees
+ * ("48.858222, 2.294500").
+ *
+ * Latitude and longitude are identified by their hemispheres (N, S, E, W),
+ * which may precede or follow the values. Without hemispheres, the latitude
+ * is expected first and negative values denote the southern and western
+ * hemispheres.
+ */
+func ParseGeographic(s string) (Geographic, error) {
+	tokens, err := dmsTokenize(s)
+
+	/*
+	 * Check if text could be split into tokens.
+	 */
+	if err != nil {
+		return Geographic{}, err
+	} else {
+		components, err := dmsGroup(tokens)
+		numComponents := len(components)
+
+		/*
+		 * Check if there is a latitude and a longitude.
+		 */
+		if err != nil {
+			return Geographic{}, err
+		} else if numComponents != 2 {
+			return Geographic{}, fmt.Errorf("Expected latitude and longitude, but found %d coordinates.", numComponents)
+		} else {
+			first := components[0]
+			second := components[1]
+			firstIsLongitude := (first.hemisphere == 'E') || (first.hemisphere == 'W')
+			secondIsLatitude := (second.hemisphere == 'N') || (second.hemisphere == 'S')
+
+			/*
+			 * Swap coordinates if longitude is given first.
+			 */
+			if firstIsLongitude || secondIsLatitude {
+				first, second = second, first
+			}
+
+			/*
+			 * Make sure that hemispheres are consistent.
+			 */
+			if (first.hemisphere == 'E') || (first.hemisphere == 'W') || (second.hemisphere == 'N') || (second.hemisphere == 'S') {
+				return Geographic{}, fmt.Errorf("%s", "Inconsistent hemispheres.")
+			} else {
+				latitude, errLat := first.degrees()
+				longitude, errLon := second.degrees()
+
+				/*
+				 * Check if coordinates are valid.
+				 */
+				if errLat != nil {
+					return Geographic{}, errLat
+				} else if errLon != nil {
+					return Geographic{}, errLon
+				} else if math.Abs(latitude) > 90.0 {
+					return Geographic{}, fmt.Errorf("Latitude %f out of range.", latitude)
+				} else if math.Abs(longitude) > 180.0 {
+					return Geographic{}, fmt.Errorf("Longitude %f out of range.", longitude)
+				} else {
+					location := CreateGeographicDegrees(longitude, latitude)
+					return location, nil
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Format an angle in degrees as degrees, minutes and seconds, with a number
+ * of decimals for the seconds.
+ */
+func formatDMS(angle float64, decimals int, positive rune, negative rune) string {
+	factor := math.Pow(10.0, float64(decimals))
+	total := math.Round(angle * 3600.0 * factor)
+	hemisphere := positive
+
+	/*
+	 * Negative angles lie in the other hemisphere.
+	 */
+	if total < 0.0 {
+		hemisphere = negative
+		total = -total
+	}
+
+	perDegree := 3600.0 * factor
+	perMinute := 60.0 * factor
+	degrees := math.Floor(total / perDegree)
+	total -= degrees * perDegree
+	minutes := math.Floor(total / perMinute)
+	total -= minutes * perMinute
+	seconds := total / factor
+	return fmt.Sprintf("%d°%d'%.*f\"%c", int(degrees), int(minutes), decimals, seconds, hemisphere)
+}
+
+/*
+ * Format a geographic location in degrees, minutes and seconds, e. g.
+ * "48°51'29.6\"N 2°17'40.2\"E", with a number of decimals for the seconds.
+ */
+func FormatDMS(location Geographic, decimals int) string {
+
+	/*
+	 * Do not allow negative numbers of decimals.
+	 */
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	latitude := formatDMS(location.LatitudeDegrees(), decimals, 'N', 'S')
+	longitude := formatDMS(location.LongitudeDegrees(), decimals, 'E', 'W')
+	return strings.Join([]string{latitude, longitude}, " ")
+}
+
+/*
+ * Format a geographic location in decimal degrees, latitude first, e. g.
+ * "48.858222, 2.294500", with a number of decimals.
+ */
+func FormatDecimal(location Geographic, decimals int) string {
+
+	/*
+	 * Do not allow negative numbers of decimals.
+	 */
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	latitude := location.LatitudeDegrees()
+	longitude := location.LongitudeDegrees()
+	return fmt.Sprintf("%.*f, %.*f", decimals, latitude, decimals, longitude)
+}
@@ -0,0 +1,206 @@
+package coordinates
+
+import (
+	"math"
+)
+
+/*
+ * Parameters of the WGS84 ellipsoid, as used by GPS, and the flattening of
+ * the GRS80 ellipsoid, on which the NAD83 and ETRS89 datums are based. The
+ * semi-major axis of GRS80 equals that of WGS84.
+ */
+const (
+	WGS84_SEMI_MAJOR_AXIS = 6378137.0
+	WGS84_FLATTENING      = 1.0 / 298.257223563
+	GRS80_FLATTENING      = 1.0 / 298.257222101
+)
+
+/*
+ * Number of iterations used to convert geocentric coordinates back to
+ * geographic latitudes.
+ */
+const ECEF_ITERATIONS = 5
+
+/*
+ * Data structure representing a 3-dimensional vector in Cartesian
+ * coordinates, e. g. a position in earth-centered, earth-fixed (ECEF)
+ * coordinates.
+ *
+ * Vectors are immutable.
+ */
+type Cartesian3 struct {
+	x float64
+	y float64
+	z float64
+}
+
+/*
+ * Data structure representing a reference ellipsoid.
+ *
+ * Ellipsoids are immutable.
+ */
+type Ellipsoid struct {
+	flattening    float64
+	semiMajorAxis float64
+}
+
+/*
+ * Returns the x-coordinate of this three-dimensional Cartesian vector.
+ */
+func (this *Cartesian3) X() float64 {
+	return this.x
+}
+
+/*
+ * Returns the y-coordinate of this three-dimensional Cartesian vector.
+ */
+func (this *Cartesian3) Y() float64 {
+	return this.y
+}
+
+/*
+ * Returns the z-coordinate of this three-dimensional Cartesian vector.
+ */
+func (this *Cartesian3) Z() float64 {
+	return this.z
+}
+
+/*
+ * Returns the flattening of this ellipsoid.
+ */
+func (this *Ellipsoid) Flattening() float64 {
+	return this.flattening
+}
+
+/*
+ * Returns the semi-major axis (equatorial radius, in meters) of this
+ * ellipsoid.
+ */
+func (this *Ellipsoid) SemiMajorAxis() float64 {
+	return this.semiMajorAxis
+}
+
+/*
+ * Returns the square of the first eccentricity of this ellipsoid.
+ */
+func (this *Ellipsoid) eccentricitySquared() float64 {
+	f := this.flattening
+	return f * (2.0 - f)
+}
+
+/*
+ * Returns the radius of curvature in the prime vertical at a latitude.
+ */
+func (this *Ellipsoid) primeVerticalRadius(sinLat float64) float64 {
+	e2 := this.eccentricitySquared()
+	return this.semiMajorAxis / math.Sqrt(1.0-(e2*sinLat*sinLat))
+}
+
+/*
+ * Convert a geographic location at a height (in meters) above this ellipsoid
+ * to earth-centered, earth-fixed (ECEF) coordinates in meters.
+ *
+ * The x axis points towards longitude and latitude zero, the z axis towards
+ * the north pole.
+ */
+func (this *Ellipsoid) ToECEF(location Geographic, height float64) Cartesian3 {
+	longitude := location.longitude
+	latitude := location.latitude
+	e2 := this.eccentricitySquared()
+	sinLat := math.Sin(latitude)
+	cosLat := math.Cos(latitude)
+	n := this.primeVerticalRadius(sinLat)
+	x := (n + height) * cosLat * math.Cos(longitude)
+	y := (n + height) * cosLat * math.Sin(longitude)
+	z := ((n * (1.0 - e2)) + height) * sinLat
+	return CreateCartesian3(x, y, z)
+}
+
+/*
+ * Convert earth-centered, earth-fixed (ECEF) coordinates in meters to a
+ * geographic location and its height (in meters) above this ellipsoid.
+ */
+func (this *Ellipsoid) FromECEF(point Cartesian3) (Geographic, float64) {
+	x := point.x
+	y := point.y
+	z := point.z
+	e2 := this.eccentricitySquared()
+	p := math.Hypot(x, y)
+	longitude := math.Atan2(y, x)
+	latitude := math.Atan2(z, p*(1.0-e2))
+
+	/*
+	 * Refine the latitude iteratively.
+	 */
+	for i := 0; i < ECEF_ITERATIONS; i++ {
+		sinLat := math.Sin(latitude)
+		n := this.primeVerticalRadius(sinLat)
+		latitude = math.Atan2(z+(e2*n*sinLat), p)
+	}
+
+	sinLat := math.Sin(latitude)
+	cosLat := math.Cos(latitude)
+	n := this.primeVerticalRadius(sinLat)
+	height := float64(0.0)
+
+	/*
+	 * Use the better-conditioned formula for the height.
+	 */
+	if math.Abs(cosLat) > math.Abs(sinLat) {
+		height = (p / cosLat) - n
+	} else {
+		height = (z / sinLat) - (n * (1.0 - e2))
+	}
+
+	location := CreateGeographic(longitude, latitude)
+	return location, height
+}
+
+/*
+ * Creates an immutable data structure representing a three-dimensional
+ * vector in Cartesian coordinates.
+ */
+func CreateCartesian3(x float64, y float64, z float64) Cartesian3 {
+
+	/*
+	 * Create a new three-dimensional vector in Cartesian coordinates.
+	 */
+	vec := Cartesian3{
+		x: x,
+		y: y,
+		z: z,
+	}
+
+	return vec
+}
+
+/*
+ * Creates an immutable data structure representing a reference ellipsoid
+ * with a semi-major axis (in meters) and a flattening.
+ */
+func CreateEllipsoid(semiMajorAxis float64, flattening float64) Ellipsoid {
+
+	/*
+	 * Create a new ellipsoid.
+	 */
+	ellipsoid := Ellipsoid{
+		flattening:    flattening,
+		semiMajorAxis: semiMajorAxis,
+	}
+
+	return ellipsoid
+}
+
+/*
+ * Returns the WGS84 ellipsoid, as used by GPS.
+ */
+func WGS84() Ellipsoid {
+	return CreateEllipsoid(WGS84_SEMI_MAJOR_AXIS, WGS84_FLATTENING)
+}
+
+/*
+ * Returns the GRS80 ellipsoid, as used by the NAD83 and ETRS89 datums.
+ */
+func GRS80() Ellipsoid {
+	return CreateEllipsoid(WGS84_SEMI_MAJOR_AXIS, GRS80_FLATTENING)
+}
@@ -0,0 +1,429 @@
+package coordinates
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ * Parameters of the iterative solution of the inverse geodesic problem.
+ */
+const (
+	GEODESIC_MAX_ITERATIONS = 200
+	GEODESIC_TOLERANCE      = 1e-12
+)
+
+/*
+ * Normalize an azimuth (in radians) into the interval [0, 2 * pi).
+ */
+func normalizeAzimuth(azimuth float64) float64 {
+	twoPi := 2.0 * math.Pi
+	azimuth = math.Mod(azimuth, twoPi)
+
+	/*
+	 * The remainder has the sign of the dividend.
+	 */
+	if azimuth < 0.0 {
+		azimuth += twoPi
+	}
+
+	return azimuth
+}
+
+/*
+ * Nodes and weights of the eight-point Gauss-Legendre quadrature on the
+ * interval [-1, 1].
+ */
+var gaussLegendreNodes = [8]float64{
+	-0.9602898564975363,
+	-0.7966664774136267,
+	-0.5255324099163290,
+	-0.1834346424956498,
+	0.1834346424956498,
+	0.5255324099163290,
+	0.7966664774136267,
+	0.9602898564975363,
+}
+
+var gaussLegendreWeights = [8]float64{
+	0.1012285362903763,
+	0.2223810344533745,
+	0.3137066458778873,
+	0.3626837833783620,
+	0.3626837833783620,
+	0.3137066458778873,
+	0.2223810344533745,
+	0.1012285362903763,
+}
+
+/*
+ * Integrate a smooth function from a to b using composite Gauss-Legendre
+ * quadrature on subintervals no longer than pi / 16.
+ */
+func integrate(fn func(x float64) float64, a float64, b float64) float64 {
+	numIntervals := int(math.Ceil(math.Abs(b-a) / (math.Pi / 16.0)))
+
+	/*
+	 * Use at least one subinterval.
+	 */
+	if numIntervals < 1 {
+		numIntervals = 1
+	}
+
+	step := (b - a) / float64(numIntervals)
+	halfStep := 0.5 * step
+	sum := float64(0.0)
+
+	/*
+	 * Integrate over each subinterval.
+	 */
+	for i := 0; i < numIntervals; i++ {
+		center := a + ((float64(i) + 0.5) * step)
+
+		/*
+		 * Evaluate the function at each node.
+		 */
+		for j, node := range gaussLegendreNodes {
+			x := center + (halfStep * node)
+			sum += gaussLegendreWeights[j] * fn(x)
+		}
+
+	}
+
+	return halfStep * sum
+}
+
+/*
+ * Data structure representing a geodesic leaving a location at a certain
+ * azimuth on the auxiliary sphere, as described by Karney (2013).
+ */
+type auxiliaryGeodesicStruct struct {
+	azimuth  float64
+	distance float64
+	lambda   float64
+}
+
+/*
+ * Follow the geodesic leaving a location at reduced latitude beta1 (which
+ * must not be positive) at azimuth alpha1, until it reaches reduced latitude
+ * beta2 heading north, with |beta2| <= |beta1|.
+ *
+ * Returns the difference in longitude covered by the geodesic, its length
+ * and its azimuth at the end.
+ */
+func (this *Ellipsoid) followGeodesic(sinBeta1 float64, cosBeta1 float64, sinBeta2 float64, cosBeta2 float64, alpha1 float64) auxiliaryGeodesicStruct {
+	f := this.flattening
+	semiMinor := (1.0 - f) * this.semiMajorAxis
+	ePrimeSq := (f * (2.0 - f)) / ((1.0 - f) * (1.0 - f))
+	sinAlpha1 := math.Sin(alpha1)
+	cosAlpha1 := math.Cos(alpha1)
+
+	/*
+	 * The azimuth is exactly zero when heading south along a meridian.
+	 */
+	if alpha1 == math.Pi {
+		sinAlpha1 = 0.0
+	}
+
+	sinAlpha0 := sinAlpha1 * cosBeta1
+	cosAlpha0 := math.Hypot(cosAlpha1, sinAlpha1*sinBeta1)
+	sinAlpha2 := sinAlpha1
+	cosAlpha2 := math.Abs(cosAlpha1)
+
+	/*
+	 * Unless both locations lie on opposite parallels, determine the
+	 * azimuth at the end using Clairaut's relation.
+	 */
+	if (cosBeta2 != cosBeta1) || (math.Abs(sinBeta2) != -sinBeta1) {
+		sinAlpha2 = sinAlpha0 / cosBeta2
+		cosAlpha1Beta1 := cosAlpha1 * cosBeta1
+		radicand := (cosAlpha1Beta1 * cosAlpha1Beta1) + ((cosBeta2 - cosBeta1) * (cosBeta1 + cosBeta2))
+
+		/*
+		 * Use the better conditioned form near the poles.
+		 */
+		if cosBeta1 >= -sinBeta1 {
+			radicand = (cosAlpha1Beta1 * cosAlpha1Beta1) + ((sinBeta1 - sinBeta2) * (sinBeta1 + sinBeta2))
+		}
+
+		cosAlpha2 = math.Sqrt(math.Max(radicand, 0.0)) / cosBeta2
+	}
+
+	sigma1 := math.Atan2(sinBeta1, cosAlpha1*cosBeta1)
+	sigma2 := math.Atan2(sinBeta2, cosAlpha2*cosBeta2)
+	omega1 := math.Atan2(sinAlpha0*sinBeta1, cosAlpha1*cosBeta1)
+	omega2 := math.Atan2(sinAlpha0*sinBeta2, cosAlpha2*cosBeta2)
+	sinSigma12 := math.Max(0.0, (math.Cos(sigma1)*math.Sin(sigma2))-(math.Sin(sigma1)*math.Cos(sigma2)))
+	cosSigma12 := (math.Cos(sigma1) * math.Cos(sigma2)) + (math.Sin(sigma1) * math.Sin(sigma2))
+	sigma12 := math.Atan2(sinSigma12, cosSigma12)
+	sinOmega12 := math.Max(0.0, (math.Cos(omega1)*math.Sin(omega2))-(math.Sin(omega1)*math.Cos(omega2)))
+	cosOmega12 := (math.Cos(omega1) * math.Cos(omega2)) + (math.Sin(omega1) * math.Sin(omega2))
+	omega12 := math.Atan2(sinOmega12, cosOmega12)
+	kSq := ePrimeSq * cosAlpha0 * cosAlpha0
+
+	/*
+	 * Integrand of the distance along the geodesic.
+	 */
+	distanceIntegrand := func(sigma float64) float64 {
+		sinSigma := math.Sin(sigma)
+		return math.Sqrt(1.0 + (kSq * sinSigma * sinSigma))
+	}
+
+	/*
+	 * Integrand of the difference between the longitude on the ellipsoid
+	 * and on the auxiliary sphere.
+	 */
+	longitudeIntegrand := func(sigma float64) float64 {
+		return (2.0 - f) / (1.0 + ((1.0 - f) * distanceIntegrand(sigma)))
+	}
+
+	end := sigma1 + sigma12
+	lambda := omega12 - (f * sinAlpha0 * integrate(longitudeIntegrand, sigma1, end))
+	distance := semiMinor * integrate(distanceIntegrand, sigma1, end)
+
+	/*
+	 * The resulting geodesic.
+	 */
+	result := auxiliaryGeodesicStruct{
+		azimuth:  math.Atan2(sinAlpha2, cosAlpha2),
+		distance: distance,
+		lambda:   lambda,
+	}
+
+	return result
+}
+
+/*
+ * Solve the inverse geodesic problem on this ellipsoid for any pair of
+ * locations, including nearly antipodal ones, following Karney (2013).
+ *
+ * The locations are brought into a canonical arrangement, in which the
+ * difference in longitude covered by the geodesic grows monotonically with
+ * its initial azimuth, which is then found by bisection. The integrals along
+ * the geodesic are evaluated using Gauss-Legendre quadrature.
+ */
+func (this *Ellipsoid) geodesicAuxiliary(a Geographic, b Geographic) (float64, float64, float64) {
+	f := this.flattening
+	deltaLon := NormalizeLongitude(b.longitude - a.longitude)
+	mirrorLon := deltaLon < 0.0
+	deltaLon = math.Abs(deltaLon)
+	lat1 := a.latitude
+	lat2 := b.latitude
+	swapped := math.Abs(lat1) < math.Abs(lat2)
+
+	/*
+	 * Start at the location farther from the equator, which reverses the
+	 * direction in longitude.
+	 */
+	if swapped {
+		lat1, lat2 = lat2, lat1
+		mirrorLon = !mirrorLon
+	}
+
+	mirrorLat := lat1 >= 0.0
+
+	/*
+	 * Make the first latitude negative.
+	 */
+	if mirrorLat {
+		lat1 = -lat1
+		lat2 = -lat2
+	}
+
+	/*
+	 * The first location must lie on or south of the equator.
+	 */
+	if lat1 == 0.0 {
+		lat1 = math.Copysign(0.0, -1.0)
+	}
+
+	beta1 := math.Atan((1.0 - f) * math.Tan(lat1))
+	beta2 := math.Atan((1.0 - f) * math.Tan(lat2))
+	sinBeta1 := math.Sin(beta1)
+	cosBeta1 := math.Max(math.Cos(beta1), 1e-300)
+	sinBeta2 := math.Sin(beta2)
+	cosBeta2 := math.Max(math.Cos(beta2), 1e-300)
+
+	/*
+	 * Locations of equal distance from the equator share the cosine of
+	 * their latitude exactly.
+	 */
+	if math.Abs(lat1) == math.Abs(lat2) {
+		cosBeta2 = cosBeta1
+	}
+
+	low := float64(0.0)
+	high := math.Pi
+
+	/*
+	 * Narrow down the initial azimuth until the interval no longer
+	 * shrinks.
+	 */
+	for {
+		mid := 0.5 * (low + high)
+
+		/*
+		 * Stop at the resolution of floating-point numbers.
+		 */
+		if (mid <= low) || (mid >= high) {
+			break
+		}
+
+		g := this.followGeodesic(sinBeta1, cosBeta1, sinBeta2, cosBeta2, mid)
+
+		/*
+		 * Keep the half of the interval containing the solution.
+		 */
+		if g.lambda < deltaLon {
+			low = mid
+		} else {
+			high = mid
+		}
+
+	}
+
+	alpha1 := 0.5 * (low + high)
+	g := this.followGeodesic(sinBeta1, cosBeta1, sinBeta2, cosBeta2, alpha1)
+	alpha2 := g.azimuth
+
+	/*
+	 * Undo mirroring at the equator.
+	 */
+	if mirrorLat {
+		alpha1 = math.Pi - alpha1
+		alpha2 = math.Pi - alpha2
+	}
+
+	/*
+	 * Undo swapping of locations, which reverses the direction.
+	 */
+	if swapped {
+		alpha1, alpha2 = alpha2+math.Pi, alpha1+math.Pi
+	}
+
+	/*
+	 * Undo mirroring at the meridian.
+	 */
+	if mirrorLon {
+		alpha1 = -alpha1
+		alpha2 = -alpha2
+	}
+
+	alpha1 = normalizeAzimuth(alpha1)
+	alpha2 = normalizeAzimuth(alpha2)
+	return g.distance, alpha1, alpha2
+}
+
+/*
+ * Solve the inverse geodesic problem on this ellipsoid.
+ *
+ * Returns the length (in meters) of the shortest path between two geographic
+ * locations along the surface of the ellipsoid, as well as its azimuths (in
+ * radians, clockwise from north) at the first and at the second location.
+ *
+ * Vincenty's formulae are used, which are accurate to within a millimeter.
+ * For nearly antipodal locations, where their iteration fails to converge,
+ * the geodesic is found using the method of Karney (2013) instead. An error
+ * is only returned for invalid locations.
+ */
+func (this *Ellipsoid) Geodesic(a Geographic, b Geographic) (float64, float64, float64, error) {
+	f := this.flattening
+	semiMajor := this.semiMajorAxis
+	semiMinor := (1.0 - f) * semiMajor
+	deltaLon := NormalizeLongitude(b.longitude - a.longitude)
+
+	/*
+	 * Verify that locations are valid.
+	 */
+	if math.IsNaN(a.latitude) || math.IsNaN(b.latitude) || math.IsNaN(deltaLon) || math.IsInf(deltaLon, 0) {
+		return math.NaN(), math.NaN(), math.NaN(), fmt.Errorf("%s", "Geodesic requires valid locations.")
+	} else if (a.latitude == b.latitude) && (deltaLon == 0.0) {
+		return 0.0, 0.0, 0.0, nil
+	} else {
+		u1 := math.Atan((1.0 - f) * math.Tan(a.latitude))
+		u2 := math.Atan((1.0 - f) * math.Tan(b.latitude))
+		sinU1 := math.Sin(u1)
+		cosU1 := math.Cos(u1)
+		sinU2 := math.Sin(u2)
+		cosU2 := math.Cos(u2)
+		lambda := deltaLon
+		sinLambda := float64(0.0)
+		cosLambda := float64(0.0)
+		sinSigma := float64(0.0)
+		cosSigma := float64(0.0)
+		sigma := float64(0.0)
+		cosSqAlpha := float64(0.0)
+		cos2SigmaM := float64(0.0)
+		coincident := false
+		converged := false
+		degenerate := false
+
+		/*
+		 * Iterate until the longitude on the auxiliary sphere
+		 * converges.
+		 */
+		for i := 0; (i < GEODESIC_MAX_ITERATIONS) && !converged && !degenerate; i++ {
+			sinLambda = math.Sin(lambda)
+			cosLambda = math.Cos(lambda)
+			p := cosU2 * sinLambda
+			q := (cosU1 * sinU2) - (sinU1 * cosU2 * cosLambda)
+			sinSigma = math.Hypot(p, q)
+			cosSigma = (sinU1 * sinU2) + (cosU1 * cosU2 * cosLambda)
+
+			/*
+			 * The azimuth is undefined for coincident and
+			 * antipodal locations.
+			 */
+			if sinSigma == 0.0 {
+				coincident = cosSigma > 0.0
+				degenerate = true
+			} else {
+				sigma = math.Atan2(sinSigma, cosSigma)
+				sinAlpha := (cosU1 * cosU2 * sinLambda) / sinSigma
+				cosSqAlpha = 1.0 - (sinAlpha * sinAlpha)
+				cos2SigmaM = 0.0
+
+				/*
+				 * Along the equator, cos2SigmaM is zero.
+				 */
+				if cosSqAlpha != 0.0 {
+					cos2SigmaM = cosSigma - ((2.0 * sinU1 * sinU2) / cosSqAlpha)
+				}
+
+				c := (f / 16.0) * cosSqAlpha * (4.0 + (f * (4.0 - (3.0 * cosSqAlpha))))
+				inner := cos2SigmaM + (c * cosSigma * (-1.0 + (2.0 * cos2SigmaM * cos2SigmaM)))
+				previous := lambda
+				lambda = deltaLon + ((1.0 - c) * f * sinAlpha * (sigma + (c * sinSigma * inner)))
+				converged = math.Abs(lambda-previous) < GEODESIC_TOLERANCE
+			}
+
+		}
+
+		/*
+		 * Fall back to the method of Karney if the iteration did not
+		 * converge or the longitude left its valid range, unless the
+		 * locations coincide.
+		 */
+		if coincident {
+			return 0.0, 0.0, 0.0, nil
+		} else if !converged || (math.Abs(lambda) > math.Pi) {
+			distance, azimuthA, azimuthB := this.geodesicAuxiliary(a, b)
+			return distance, azimuthA, azimuthB, nil
+		} else {
+			uSq := (cosSqAlpha * ((semiMajor * semiMajor) - (semiMinor * semiMinor))) / (semiMinor * semiMinor)
+			coeffA := 1.0 + ((uSq / 16384.0) * (4096.0 + (uSq * (-768.0 + (uSq * (320.0 - (175.0 * uSq)))))))
+			coeffB := (uSq / 1024.0) * (256.0 + (uSq * (-128.0 + (uSq * (74.0 - (47.0 * uSq))))))
+			termA := cosSigma * (-1.0 + (2.0 * cos2SigmaM * cos2SigmaM))
+			termB := (coeffB / 6.0) * cos2SigmaM * (-3.0 + (4.0 * sinSigma * sinSigma)) * (-3.0 + (4.0 * cos2SigmaM * cos2SigmaM))
+			deltaSigma := coeffB * sinSigma * (cos2SigmaM + ((coeffB / 4.0) * (termA - termB)))
+			distance := semiMinor * coeffA * (sigma - deltaSigma)
+			azimuthA := math.Atan2(cosU2*sinLambda, (cosU1*sinU2)-(sinU1*cosU2*cosLambda))
+			azimuthB := math.Atan2(cosU1*sinLambda, (-sinU1*cosU2)+(cosU1*sinU2*cosLambda))
+			azimuthA = normalizeAzimuth(azimuthA)
+			azimuthB = normalizeAzimuth(azimuthB)
+			return distance, azimuthA, azimuthB, nil
+		}
+
+	}
+
+}
@@ -0,0 +1,128 @@
+package coordinates
+
+import (
+	"math"
+	"testing"
+)
+
+/*
+ * Solve the inverse geodesic problem for known cases.
+ */
+func TestGeodesic(t *testing.T) {
+	grs80 := GRS80()
+	wgs84 := WGS84()
+
+	/*
+	 * Locations with known geodesics.
+	 */
+	cases := []struct {
+		name      string
+		ellipsoid *Ellipsoid
+		a         Geographic
+		b         Geographic
+		distance  float64
+		azimuth1  float64
+		azimuth2  float64
+	}{
+		{
+			name:      "Flinders Peak to Buninyong (Vincenty)",
+			ellipsoid: &grs80,
+			a:         CreateGeographicDegrees(dms(144, 25, 29.52440), -dms(37, 57, 3.72030)),
+			b:         CreateGeographicDegrees(dms(143, 55, 35.38390), -dms(37, 39, 10.15610)),
+			distance:  54972.271,
+			azimuth1:  dms(306, 52, 5.37),
+			azimuth2:  dms(307, 10, 25.07),
+		},
+		{
+			name:      "Nearly antipodal (Karney)",
+			ellipsoid: &wgs84,
+			a:         CreateGeographicDegrees(0.0, -30.0),
+			b:         CreateGeographicDegrees(179.8, 29.9),
+			distance:  19989832.828,
+			azimuth1:  161.890524736,
+			azimuth2:  18.090737246,
+		},
+		{
+			name:      "Quarter meridian",
+			ellipsoid: &wgs84,
+			a:         CreateGeographicDegrees(0.0, 0.0),
+			b:         CreateGeographicDegrees(0.0, 90.0),
+			distance:  10001965.729,
+			azimuth1:  0.0,
+			azimuth2:  0.0,
+		},
+	}
+
+	/*
+	 * Compare each geodesic with the known solution.
+	 */
+	for _, c := range cases {
+		distance, azimuth1, azimuth2, err := c.ellipsoid.Geodesic(c.a, c.b)
+
+		/*
+		 * Check if geodesic could be found.
+		 */
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err.Error())
+		} else {
+			azimuth1 *= DEGREES_PER_RADIAN
+			azimuth2 *= DEGREES_PER_RADIAN
+
+			/*
+			 * Distances must agree to a millimeter and azimuths to
+			 * about a hundredth of an arc second.
+			 */
+			if math.Abs(distance-c.distance) > 1e-3 {
+				t.Errorf("%s: distance is %f m, expected %f m", c.name, distance, c.distance)
+			}
+
+			if math.Abs(azimuth1-c.azimuth1) > 3e-6 {
+				t.Errorf("%s: first azimuth is %.9f, expected %.9f", c.name, azimuth1, c.azimuth1)
+			}
+
+			if math.Abs(azimuth2-c.azimuth2) > 3e-6 {
+				t.Errorf("%s: second azimuth is %.9f, expected %.9f", c.name, azimuth2, c.azimuth2)
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * The geodesic between a location and itself has zero length.
+ */
+func TestGeodesicCoincident(t *testing.T) {
+	wgs84 := WGS84()
+	a := CreateGeographicDegrees(13.4, 52.5)
+	distance, _, _, err := wgs84.Geodesic(a, a)
+
+	/*
+	 * Check if distance is zero.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if distance != 0.0 {
+		t.Errorf("Distance is %f m, expected zero.", distance)
+	}
+
+}
+
+/*
+ * Invalid locations are rejected.
+ */
+func TestGeodesicInvalid(t *testing.T) {
+	wgs84 := WGS84()
+	a := CreateGeographicDegrees(13.4, 52.5)
+	b := CreateGeographic(math.NaN(), 0.0)
+	_, _, _, err := wgs84.Geodesic(a, b)
+
+	/*
+	 * Check if an error was returned.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Expected an error for an invalid location.")
+	}
+
+}
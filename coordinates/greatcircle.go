@@ -0,0 +1,256 @@
+package coordinates
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ * Mean radius of the earth (in meters), as defined by the IUGG.
+ */
+const EARTH_RADIUS = 6371008.8
+
+/*
+ * Returns the central angle (in radians) between two geographic locations,
+ * i. e. their great-circle distance on the unit sphere.
+ *
+ * This uses the haversine formula, which is well-conditioned for small
+ * distances.
+ */
+func centralAngle(a Geographic, b Geographic) float64 {
+	deltaLat := b.latitude - a.latitude
+	deltaLon := b.longitude - a.longitude
+	sinHalfLat := math.Sin(0.5 * deltaLat)
+	sinHalfLon := math.Sin(0.5 * deltaLon)
+	cosLatA := math.Cos(a.latitude)
+	cosLatB := math.Cos(b.latitude)
+	h := (sinHalfLat * sinHalfLat) + (cosLatA * cosLatB * sinHalfLon * sinHalfLon)
+	h = math.Max(0.0, math.Min(h, 1.0))
+	return 2.0 * math.Asin(math.Sqrt(h))
+}
+
+/*
+ * Returns the great-circle distance (in meters) between two geographic
+ * locations on a sphere with the mean radius of the earth.
+ *
+ * Since the earth is not a sphere, the result may be off by up to 0.5 %.
+ */
+func Distance(a Geographic, b Geographic) float64 {
+	return DistanceOnSphere(a, b, EARTH_RADIUS)
+}
+
+/*
+ * Returns the great-circle distance between two geographic locations on a
+ * sphere with a given radius, in the unit of the radius.
+ *
+ * The distance is computed using the haversine formula.
+ */
+func DistanceOnSphere(a Geographic, b Geographic, radius float64) float64 {
+	return radius * centralAngle(a, b)
+}
+
+/*
+ * Returns the initial bearing (in radians, clockwise from north, within
+ * [0, 2 * pi)) of the great circle from one geographic location to another.
+ *
+ * Unlike along a rhumb line, the bearing generally changes along the way.
+ */
+func Bearing(a Geographic, b Geographic) float64 {
+	deltaLon := b.longitude - a.longitude
+	cosLatB := math.Cos(b.latitude)
+	y := math.Sin(deltaLon) * cosLatB
+	x := (math.Cos(a.latitude) * math.Sin(b.latitude)) - (math.Sin(a.latitude) * cosLatB * math.Cos(deltaLon))
+	bearing := math.Atan2(y, x)
+	return normalizeAzimuth(bearing)
+}
+
+/*
+ * Returns the location reached when travelling a distance (in meters) along
+ * a great circle from a starting location, leaving it at an initial bearing
+ * (in radians, clockwise from north).
+ *
+ * The earth is assumed to be a sphere with its mean radius, as for Distance.
+ */
+func Destination(start Geographic, bearing float64, distance float64) Geographic {
+	angle := distance / EARTH_RADIUS
+	sinAngle := math.Sin(angle)
+	cosAngle := math.Cos(angle)
+	sinLat := math.Sin(start.latitude)
+	cosLat := math.Cos(start.latitude)
+	sinLatB := (sinLat * cosAngle) + (cosLat * sinAngle * math.Cos(bearing))
+	sinLatB = math.Max(-1.0, math.Min(sinLatB, 1.0))
+	latitude := math.Asin(sinLatB)
+	y := math.Sin(bearing) * sinAngle * cosLat
+	x := cosAngle - (sinLat * sinLatB)
+	longitude := start.longitude + math.Atan2(y, x)
+	longitude = NormalizeLongitude(longitude)
+	return CreateGeographic(longitude, latitude)
+}
+
+/*
+ * Convert a geographic location to a unit vector in three dimensions.
+ */
+func unitVector(location Geographic) [3]float64 {
+	cosLat := math.Cos(location.latitude)
+
+	/*
+	 * The unit vector.
+	 */
+	v := [3]float64{
+		cosLat * math.Cos(location.longitude),
+		cosLat * math.Sin(location.longitude),
+		math.Sin(location.latitude),
+	}
+
+	return v
+}
+
+/*
+ * Convert a vector in three dimensions to a geographic location.
+ */
+func fromVector(v [3]float64) Geographic {
+	longitude := math.Atan2(v[1], v[0])
+	latitude := math.Atan2(v[2], math.Hypot(v[0], v[1]))
+	return CreateGeographic(longitude, latitude)
+}
+
+/*
+ * Interpolate between two unit vectors, which enclose a central angle, along
+ * the great circle through both of them.
+ *
+ * The fraction f ranges from zero (first vector) to one (second vector).
+ */
+func slerp(a [3]float64, b [3]float64, angle float64, f float64) [3]float64 {
+	sinAngle := math.Sin(angle)
+	wa := math.Sin((1.0-f)*angle) / sinAngle
+	wb := math.Sin(f*angle) / sinAngle
+
+	/*
+	 * The interpolated vector.
+	 */
+	v := [3]float64{
+		(wa * a[0]) + (wb * b[0]),
+		(wa * a[1]) + (wb * b[1]),
+		(wa * a[2]) + (wb * b[2]),
+	}
+
+	return v
+}
+
+/*
+ * Returns the location at a fraction f along the great circle between two
+ * geographic locations (spherical linear interpolation), e. g. to animate
+ * moving points.
+ *
+ * The fraction ranges from zero (first location) to one (second location),
+ * but values outside this range extrapolate along the great circle. Since
+ * the great circle between antipodal locations is not unique, the route
+ * along the meridian leading north from the first location is taken.
+ */
+func Interpolate(a Geographic, b Geographic, f float64) Geographic {
+	angle := centralAngle(a, b)
+	va := unitVector(a)
+
+	/*
+	 * Decide on the way to interpolate.
+	 */
+	if angle < 1e-12 {
+		return a
+	} else if (math.Pi - angle) < 1e-9 {
+		sinLat := math.Sin(a.latitude)
+		cosLat := math.Cos(a.latitude)
+		cosAngle := math.Cos(f * math.Pi)
+		sinAngle := math.Sin(f * math.Pi)
+
+		/*
+		 * Unit vector pointing north at the first location.
+		 */
+		north := [3]float64{
+			-sinLat * math.Cos(a.longitude),
+			-sinLat * math.Sin(a.longitude),
+			cosLat,
+		}
+
+		/*
+		 * The interpolated vector.
+		 */
+		v := [3]float64{
+			(cosAngle * va[0]) + (sinAngle * north[0]),
+			(cosAngle * va[1]) + (sinAngle * north[1]),
+			(cosAngle * va[2]) + (sinAngle * north[2]),
+		}
+
+		return fromVector(v)
+	} else {
+		vb := unitVector(b)
+		v := slerp(va, vb, angle, f)
+		return fromVector(v)
+	}
+
+}
+
+/*
+ * Returns the location halfway along the great circle between two
+ * geographic locations.
+ */
+func Midpoint(a Geographic, b Geographic) Geographic {
+	return Interpolate(a, b, 0.5)
+}
+
+/*
+ * Interpolate points along the great circle between two geographic
+ * locations, so that long segments (e. g. of flight or shipping routes) are
+ * projected as curves instead of straight chords.
+ *
+ * The spacing is the largest central angle (in radians) between subsequent
+ * points. Divide a distance by the radius of the earth to obtain it. The
+ * result includes both locations and the points are evenly spaced. Since the
+ * great circle between antipodal locations is not unique, these cannot be
+ * densified.
+ */
+func Densify(a Geographic, b Geographic, spacing float64) ([]Geographic, error) {
+
+	/*
+	 * Check parameters.
+	 */
+	if !(spacing > 0.0) {
+		return nil, fmt.Errorf("%s", "Spacing must be positive.")
+	} else {
+		angle := centralAngle(a, b)
+
+		/*
+		 * Check if great circle is unique.
+		 */
+		if (math.Pi - angle) < 1e-9 {
+			return nil, fmt.Errorf("%s", "Great circle between antipodal locations is not unique.")
+		} else {
+			numSegments := int(math.Ceil(angle / spacing))
+
+			/*
+			 * Use at least one segment.
+			 */
+			if numSegments < 1 {
+				numSegments = 1
+			}
+
+			result := make([]Geographic, numSegments+1)
+			result[0] = a
+			result[numSegments] = b
+			va := unitVector(a)
+			vb := unitVector(b)
+
+			/*
+			 * Interpolate inner points.
+			 */
+			for i := 1; i < numSegments; i++ {
+				f := float64(i) / float64(numSegments)
+				v := slerp(va, vb, angle, f)
+				result[i] = fromVector(v)
+			}
+
+			return result, nil
+		}
+
+	}
+
+}
@@ -0,0 +1,98 @@
+package coordinates
+
+import (
+	"math"
+	"testing"
+)
+
+/*
+ * Great-circle distances of known fractions of the circumference.
+ */
+func TestDistance(t *testing.T) {
+
+	/*
+	 * Locations with known distances.
+	 */
+	cases := []struct {
+		a        Geographic
+		b        Geographic
+		distance float64
+	}{
+		{CreateGeographicDegrees(0.0, 0.0), CreateGeographicDegrees(90.0, 0.0), 0.5 * math.Pi * EARTH_RADIUS},
+		{CreateGeographicDegrees(0.0, 0.0), CreateGeographicDegrees(180.0, 0.0), math.Pi * EARTH_RADIUS},
+		{CreateGeographicDegrees(45.0, 90.0), CreateGeographicDegrees(-135.0, 0.0), 0.5 * math.Pi * EARTH_RADIUS},
+		{CreateGeographicDegrees(170.0, 0.0), CreateGeographicDegrees(-170.0, 0.0), (math.Pi / 9.0) * EARTH_RADIUS},
+		{CreateGeographicDegrees(10.0, 20.0), CreateGeographicDegrees(10.0, 20.0), 0.0},
+	}
+
+	/*
+	 * Compare each distance with the known one.
+	 */
+	for i, c := range cases {
+		distance := Distance(c.a, c.b)
+
+		/*
+		 * Distances must agree to a millimeter.
+		 */
+		if math.Abs(distance-c.distance) > 1e-3 {
+			t.Errorf("Case %d: distance is %f m, expected %f m", i, distance, c.distance)
+		}
+
+	}
+
+}
+
+/*
+ * Initial bearings towards the cardinal directions.
+ */
+func TestBearing(t *testing.T) {
+	origin := CreateGeographicDegrees(0.0, 0.0)
+
+	/*
+	 * Locations with known bearings.
+	 */
+	cases := []struct {
+		b       Geographic
+		bearing float64
+	}{
+		{CreateGeographicDegrees(0.0, 10.0), 0.0},
+		{CreateGeographicDegrees(10.0, 0.0), 0.5 * math.Pi},
+		{CreateGeographicDegrees(0.0, -10.0), math.Pi},
+		{CreateGeographicDegrees(-10.0, 0.0), 1.5 * math.Pi},
+	}
+
+	/*
+	 * Compare each bearing with the known one.
+	 */
+	for i, c := range cases {
+		bearing := Bearing(origin, c.b)
+
+		/*
+		 * Check if bearing matches.
+		 */
+		if math.Abs(bearing-c.bearing) > 1e-12 {
+			t.Errorf("Case %d: bearing is %f, expected %f", i, bearing, c.bearing)
+		}
+
+	}
+
+}
+
+/*
+ * Travelling a quarter of the circumference east along the equator.
+ */
+func TestDestination(t *testing.T) {
+	origin := CreateGeographicDegrees(0.0, 0.0)
+	distance := 0.5 * math.Pi * EARTH_RADIUS
+	destination := Destination(origin, 0.5*math.Pi, distance)
+	longitude := destination.LongitudeDegrees()
+	latitude := destination.LatitudeDegrees()
+
+	/*
+	 * Check if destination lies on the equator at 90 degrees east.
+	 */
+	if (math.Abs(longitude-90.0) > 1e-9) || (math.Abs(latitude) > 1e-9) {
+		t.Errorf("Destination is (%f, %f), expected (90, 0).", longitude, latitude)
+	}
+
+}
@@ -0,0 +1,182 @@
+package coordinates
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+/*
+ * The maximum number of character pairs in a Maidenhead locator.
+ */
+const (
+	MAIDENHEAD_MAX_PAIRS = 6
+)
+
+/*
+ * Number of subdivisions for each character pair of a Maidenhead locator.
+ *
+ * Fields are denoted by letters A to R, squares by digits, subsquares by
+ * letters a to x, extended squares by digits and so on.
+ */
+var maidenheadBases = [MAIDENHEAD_MAX_PAIRS]int{18, 10, 24, 10, 24, 10}
+
+/*
+ * Returns the character denoting a subdivision at a certain character pair
+ * of a Maidenhead locator.
+ */
+func maidenheadChar(pair int, idx int) byte {
+
+	/*
+	 * Fields are upper case, subsquares are lower case, squares are digits.
+	 */
+	if pair == 0 {
+		return byte('A' + idx)
+	} else if (pair % 2) == 0 {
+		return byte('a' + idx)
+	} else {
+		return byte('0' + idx)
+	}
+
+}
+
+/*
+ * Returns the subdivision denoted by a character at a certain character pair
+ * of a Maidenhead locator or -1 if the character is invalid.
+ */
+func maidenheadIndex(pair int, c byte) int {
+	base := maidenheadBases[pair]
+	idx := -1
+
+	/*
+	 * Odd pairs are digits, even pairs are letters in any case.
+	 */
+	if (pair % 2) != 0 {
+		idx = int(c) - '0'
+	} else if (c >= 'a') && (c <= 'z') {
+		idx = int(c) - 'a'
+	} else {
+		idx = int(c) - 'A'
+	}
+
+	/*
+	 * Check if subdivision is in range.
+	 */
+	if (idx < 0) || (idx >= base) {
+		return -1
+	} else {
+		return idx
+	}
+
+}
+
+/*
+ * Encode a geographic location as a Maidenhead grid locator with a number of
+ * character pairs, e. g. "JN18du" for three pairs.
+ *
+ * Two pairs denote a square of 2° by 1°, three pairs a subsquare of 5' by
+ * 2.5', which is commonly exchanged during amateur radio contacts.
+ */
+func EncodeMaidenhead(location Geographic, pairs int) (string, error) {
+	longitude := location.LongitudeDegrees()
+	latitude := location.LatitudeDegrees()
+
+	/*
+	 * Check parameters.
+	 */
+	if (pairs < 1) || (pairs > MAIDENHEAD_MAX_PAIRS) {
+		return "", fmt.Errorf("Number of pairs must be between 1 and %d.", MAIDENHEAD_MAX_PAIRS)
+	} else if math.IsNaN(longitude) || math.IsNaN(latitude) || (math.Abs(latitude) > 90.0) {
+		return "", fmt.Errorf("%s", "Location must be valid.")
+	} else {
+		longitude = DEGREES_PER_RADIAN * NormalizeLongitude(location.Longitude())
+		x := (longitude + 180.0) / 360.0
+		y := (latitude + 90.0) / 180.0
+		buf := make([]byte, 2*pairs)
+
+		/*
+		 * Subdivide the grid for each pair of characters.
+		 */
+		for i := 0; i < pairs; i++ {
+			base := maidenheadBases[i]
+			baseFloat := float64(base)
+			x *= baseFloat
+			y *= baseFloat
+			xIdx := int(math.Floor(x))
+			yIdx := int(math.Floor(y))
+
+			/*
+			 * The eastern and northern edges belong to the last cell.
+			 */
+			if xIdx >= base {
+				xIdx = base - 1
+			}
+
+			/*
+			 * The eastern and northern edges belong to the last cell.
+			 */
+			if yIdx >= base {
+				yIdx = base - 1
+			}
+
+			x -= float64(xIdx)
+			y -= float64(yIdx)
+			buf[2*i] = maidenheadChar(i, xIdx)
+			buf[(2*i)+1] = maidenheadChar(i, yIdx)
+		}
+
+		return string(buf), nil
+	}
+
+}
+
+/*
+ * Decode a Maidenhead grid locator, returning the location at the center of
+ * the grid cell it denotes.
+ *
+ * Letters are accepted in any case, surrounding white space is ignored.
+ */
+func DecodeMaidenhead(locator string) (Geographic, error) {
+	locator = strings.TrimSpace(locator)
+	length := len(locator)
+	pairs := length / 2
+
+	/*
+	 * Check length of locator.
+	 */
+	if ((length % 2) != 0) || (pairs < 1) || (pairs > MAIDENHEAD_MAX_PAIRS) {
+		return Geographic{}, fmt.Errorf("Invalid Maidenhead locator: '%s'", locator)
+	} else {
+		longitude := -180.0
+		latitude := -90.0
+		width := 360.0
+		height := 180.0
+
+		/*
+		 * Refine the grid cell for each pair of characters.
+		 */
+		for i := 0; i < pairs; i++ {
+			xIdx := maidenheadIndex(i, locator[2*i])
+			yIdx := maidenheadIndex(i, locator[(2*i)+1])
+
+			/*
+			 * Check if characters are valid.
+			 */
+			if (xIdx < 0) || (yIdx < 0) {
+				return Geographic{}, fmt.Errorf("Invalid Maidenhead locator: '%s'", locator)
+			}
+
+			baseFloat := float64(maidenheadBases[i])
+			width /= baseFloat
+			height /= baseFloat
+			longitude += float64(xIdx) * width
+			latitude += float64(yIdx) * height
+		}
+
+		longitude += 0.5 * width
+		latitude += 0.5 * height
+		location := CreateGeographicDegrees(longitude, latitude)
+		return location, nil
+	}
+
+}
@@ -0,0 +1,200 @@
+package coordinates
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ * Data structure representing a 3 x 3 matrix, which transforms points on a
+ * plane in homogeneous coordinates, e. g. by scaling, rotating, shearing and
+ * translating them.
+ *
+ * Matrices are immutable.
+ */
+type Matrix struct {
+	values [9]float64
+}
+
+/*
+ * Returns the entries of this matrix in row-major order.
+ */
+func (this *Matrix) Values() [9]float64 {
+	return this.values
+}
+
+/*
+ * Returns the determinant of this matrix.
+ */
+func (this *Matrix) Determinant() float64 {
+	m := &this.values
+	a := m[0] * ((m[4] * m[8]) - (m[5] * m[7]))
+	b := m[1] * ((m[3] * m[8]) - (m[5] * m[6]))
+	c := m[2] * ((m[3] * m[7]) - (m[4] * m[6]))
+	return a - b + c
+}
+
+/*
+ * Returns the transform, which applies this transform first and the other
+ * transform afterwards, i. e. the matrix product of the other matrix and
+ * this matrix.
+ */
+func (this *Matrix) Compose(other Matrix) Matrix {
+	a := &other.values
+	b := &this.values
+	result := [9]float64{}
+
+	/*
+	 * Multiply matrices.
+	 */
+	for row := 0; row < 3; row++ {
+
+		/*
+		 * Calculate each entry of the row.
+		 */
+		for col := 0; col < 3; col++ {
+			sum := float64(0.0)
+
+			/*
+			 * Sum up the products.
+			 */
+			for k := 0; k < 3; k++ {
+				sum += a[(3*row)+k] * b[(3*k)+col]
+			}
+
+			result[(3*row)+col] = sum
+		}
+
+	}
+
+	return Matrix{values: result}
+}
+
+/*
+ * Returns the inverse of this matrix, which undoes its transform.
+ *
+ * Singular matrices cannot be inverted.
+ */
+func (this *Matrix) Invert() (Matrix, error) {
+	m := &this.values
+	det := this.Determinant()
+
+	/*
+	 * Check if matrix can be inverted.
+	 */
+	if (det == 0.0) || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Matrix{}, fmt.Errorf("%s", "Matrix must not be singular.")
+	} else {
+
+		/*
+		 * The adjugate matrix divided by the determinant.
+		 */
+		result := [9]float64{
+			((m[4] * m[8]) - (m[5] * m[7])) / det,
+			((m[2] * m[7]) - (m[1] * m[8])) / det,
+			((m[1] * m[5]) - (m[2] * m[4])) / det,
+			((m[5] * m[6]) - (m[3] * m[8])) / det,
+			((m[0] * m[8]) - (m[2] * m[6])) / det,
+			((m[2] * m[3]) - (m[0] * m[5])) / det,
+			((m[3] * m[7]) - (m[4] * m[6])) / det,
+			((m[1] * m[6]) - (m[0] * m[7])) / det,
+			((m[0] * m[4]) - (m[1] * m[3])) / det,
+		}
+
+		return Matrix{values: result}, nil
+	}
+
+}
+
+/*
+ * Apply the transform of this matrix to a point.
+ *
+ * If the last row of the matrix is not (0, 0, 1), the result is divided by
+ * its homogeneous coordinate.
+ */
+func (this *Matrix) Apply(point Cartesian) Cartesian {
+	m := &this.values
+	x := point.x
+	y := point.y
+	xNew := (m[0] * x) + (m[1] * y) + m[2]
+	yNew := (m[3] * x) + (m[4] * y) + m[5]
+	w := (m[6] * x) + (m[7] * y) + m[8]
+
+	/*
+	 * Divide by homogeneous coordinate if needed.
+	 */
+	if w != 1.0 {
+		xNew /= w
+		yNew /= w
+	}
+
+	return CreateCartesian(xNew, yNew)
+}
+
+/*
+ * Apply the transform of this matrix to a series of points.
+ *
+ * Source and destination may be the same slice.
+ */
+func (this *Matrix) ApplySlice(dst []Cartesian, src []Cartesian) error {
+
+	/*
+	 * Check parameters.
+	 */
+	if len(dst) != len(src) {
+		return fmt.Errorf("%s", "Source and destination must have same length")
+	} else {
+
+		/*
+		 * Transform all points.
+		 */
+		for i, point := range src {
+			dst[i] = this.Apply(point)
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Creates an immutable data structure representing a 3 x 3 matrix, given
+ * its entries in row-major order.
+ */
+func CreateMatrix(values [9]float64) Matrix {
+	return Matrix{values: values}
+}
+
+/*
+ * Creates a matrix, which leaves points unchanged.
+ */
+func IdentityMatrix() Matrix {
+	values := [9]float64{1.0, 0.0, 0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 1.0}
+	return Matrix{values: values}
+}
+
+/*
+ * Creates a matrix, which rotates points counter-clockwise around the origin
+ * by an angle (in radians).
+ */
+func RotationMatrix(angle float64) Matrix {
+	sin, cos := math.Sincos(angle)
+	values := [9]float64{cos, -sin, 0.0, sin, cos, 0.0, 0.0, 0.0, 1.0}
+	return Matrix{values: values}
+}
+
+/*
+ * Creates a matrix, which scales points relative to the origin.
+ */
+func ScaleMatrix(sx float64, sy float64) Matrix {
+	values := [9]float64{sx, 0.0, 0.0, 0.0, sy, 0.0, 0.0, 0.0, 1.0}
+	return Matrix{values: values}
+}
+
+/*
+ * Creates a matrix, which translates points.
+ */
+func TranslationMatrix(dx float64, dy float64) Matrix {
+	values := [9]float64{1.0, 0.0, dx, 0.0, 1.0, dy, 0.0, 0.0, 1.0}
+	return Matrix{values: values}
+}
@@ -0,0 +1,224 @@
+package coordinates
+
+/*
+ * Data structure representing a polygon in Cartesian coordinates, which
+ * consists of one or more closed rings, e. g. an outer boundary and holes.
+ *
+ * Polygons are immutable.
+ */
+type Polygon struct {
+	bounds CartesianBounds
+	rings  [][]Cartesian
+}
+
+/*
+ * Returns whether a point lies on the segment between two points.
+ */
+func onSegment(point Cartesian, a Cartesian, b Cartesian) bool {
+	ab := b.Sub(a)
+	ap := point.Sub(a)
+	cross := (ab.x * ap.y) - (ab.y * ap.x)
+
+	/*
+	 * The point must be collinear with the segment and lie between its
+	 * endpoints.
+	 */
+	if cross != 0.0 {
+		return false
+	} else {
+		dot := ab.Dot(ap)
+		return (dot >= 0.0) && (dot <= ab.Dot(ab))
+	}
+
+}
+
+/*
+ * Returns the bounding box of this polygon.
+ */
+func (this *Polygon) Bounds() CartesianBounds {
+	return this.bounds
+}
+
+/*
+ * Returns the number of rings of this polygon.
+ */
+func (this *Polygon) NumRings() int {
+	return len(this.rings)
+}
+
+/*
+ * Returns a copy of the vertices of a ring of this polygon.
+ */
+func (this *Polygon) Ring(idx int) []Cartesian {
+	ring := this.rings[idx]
+	result := make([]Cartesian, len(ring))
+	copy(result, ring)
+	return result
+}
+
+/*
+ * Returns whether a point lies on the boundary of this polygon.
+ */
+func (this *Polygon) onBoundary(point Cartesian) bool {
+
+	/*
+	 * Check the edges of all rings.
+	 */
+	for _, ring := range this.rings {
+		numVertices := len(ring)
+
+		/*
+		 * Check each edge of the ring.
+		 */
+		for i, a := range ring {
+			b := ring[(i+1)%numVertices]
+
+			/*
+			 * Check if point lies on this edge.
+			 */
+			if onSegment(point, a, b) {
+				return true
+			}
+
+		}
+
+	}
+
+	return false
+}
+
+/*
+ * Returns the winding number of this polygon around a point, i. e. the
+ * number of times its rings wind counter-clockwise around the point minus
+ * the number of times they wind clockwise around it.
+ */
+func (this *Polygon) WindingNumber(point Cartesian) int {
+	x := point.x
+	y := point.y
+	winding := 0
+
+	/*
+	 * Sum up the contributions of all rings.
+	 */
+	for _, ring := range this.rings {
+		numVertices := len(ring)
+
+		/*
+		 * Count the edges crossing the horizontal ray to the right of the
+		 * point.
+		 */
+		for i, a := range ring {
+			b := ring[(i+1)%numVertices]
+			isLeft := ((b.x - a.x) * (y - a.y)) - ((x - a.x) * (b.y - a.y))
+
+			/*
+			 * Upward edges crossing the ray count positive, downward edges
+			 * count negative.
+			 */
+			if (a.y <= y) && (b.y > y) && (isLeft > 0.0) {
+				winding++
+			} else if (a.y > y) && (b.y <= y) && (isLeft < 0.0) {
+				winding--
+			}
+
+		}
+
+	}
+
+	return winding
+}
+
+/*
+ * Returns whether this polygon contains a point according to the even-odd
+ * rule, i. e. a point is inside if a ray starting at it crosses the rings an
+ * odd number of times.
+ *
+ * This way, rings within other rings form holes, regardless of their
+ * orientation. Points on the boundary are contained.
+ */
+func (this *Polygon) Contains(point Cartesian) bool {
+
+	/*
+	 * Reject points outside the bounding box early.
+	 */
+	if !this.bounds.Contains(point) {
+		return false
+	} else if this.onBoundary(point) {
+		return true
+	} else {
+		winding := this.WindingNumber(point)
+		return (winding % 2) != 0
+	}
+
+}
+
+/*
+ * Returns whether this polygon contains a point according to the non-zero
+ * winding rule, i. e. a point is inside if the rings wind around it.
+ *
+ * Rings within other rings only form holes if their orientation is opposite.
+ * Points on the boundary are contained.
+ */
+func (this *Polygon) ContainsNonZero(point Cartesian) bool {
+
+	/*
+	 * Reject points outside the bounding box early.
+	 */
+	if !this.bounds.Contains(point) {
+		return false
+	} else if this.onBoundary(point) {
+		return true
+	} else {
+		winding := this.WindingNumber(point)
+		return winding != 0
+	}
+
+}
+
+/*
+ * Creates an immutable data structure representing a polygon with a set of
+ * rings.
+ *
+ * Each ring is closed implicitly, i. e. its last vertex is connected to its
+ * first one, so it need not be repeated. The vertices are copied. To test
+ * geographic locations, create the polygon in projected coordinates or from
+ * longitudes and latitudes as x and y coordinates.
+ */
+func CreatePolygon(rings [][]Cartesian) Polygon {
+	bounds := EmptyCartesianBounds()
+	copies := make([][]Cartesian, 0, len(rings))
+
+	/*
+	 * Copy all non-empty rings and find their bounding box.
+	 */
+	for _, ring := range rings {
+
+		/*
+		 * Skip empty rings.
+		 */
+		if len(ring) > 0 {
+			ringCopy := make([]Cartesian, len(ring))
+			copy(ringCopy, ring)
+			copies = append(copies, ringCopy)
+
+			/*
+			 * Expand bounding box by all vertices.
+			 */
+			for _, vertex := range ring {
+				bounds = bounds.Expand(vertex)
+			}
+
+		}
+
+	}
+
+	/*
+	 * Create polygon.
+	 */
+	polygon := Polygon{
+		bounds: bounds,
+		rings:  copies,
+	}
+
+	return polygon
+}
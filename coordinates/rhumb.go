@@ -0,0 +1,157 @@
+package coordinates
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ * Returns the isometric latitude of a latitude (in radians), i. e. the
+ * ordinate of the location on a Mercator map of the unit sphere.
+ *
+ * Latitudes are limited to just short of the poles, which lie at an infinite
+ * distance.
+ */
+func isometricLatitude(latitude float64) float64 {
+	limit := (0.5 * math.Pi) - 1e-12
+	latitude = math.Max(-limit, math.Min(latitude, limit))
+	return math.Log(math.Tan((0.25 * math.Pi) + (0.5 * latitude)))
+}
+
+/*
+ * Returns the differences in latitude, isometric latitude and longitude
+ * between two locations along the shorter rhumb line.
+ */
+func rhumbDeltas(a Geographic, b Geographic) (float64, float64, float64) {
+	deltaLat := b.latitude - a.latitude
+	deltaPsi := isometricLatitude(b.latitude) - isometricLatitude(a.latitude)
+	deltaLon := NormalizeLongitude(b.longitude - a.longitude)
+	return deltaLat, deltaPsi, deltaLon
+}
+
+/*
+ * Returns the length (in radians) of the rhumb line (loxodrome) between two
+ * geographic locations on the unit sphere.
+ *
+ * The shorter rhumb line is used, which may cross the antimeridian.
+ */
+func rhumbAngle(a Geographic, b Geographic) float64 {
+	deltaLat, deltaPsi, deltaLon := rhumbDeltas(a, b)
+	q := math.Cos(a.latitude)
+
+	/*
+	 * Along a parallel, the stretch of the longitude is the cosine of the
+	 * latitude.
+	 */
+	if math.Abs(deltaPsi) > 1e-12 {
+		q = deltaLat / deltaPsi
+	}
+
+	return math.Hypot(deltaLat, q*deltaLon)
+}
+
+/*
+ * Returns the length (in meters) of the rhumb line (loxodrome) between two
+ * geographic locations on a sphere with the mean radius of the earth.
+ *
+ * A rhumb line crosses all meridians at the same angle, so that it can be
+ * followed at a constant bearing. It is never shorter than the great circle
+ * between the same locations.
+ */
+func RhumbDistance(a Geographic, b Geographic) float64 {
+	return RhumbDistanceOnSphere(a, b, EARTH_RADIUS)
+}
+
+/*
+ * Returns the length of the rhumb line between two geographic locations on a
+ * sphere with a given radius, in the unit of the radius.
+ */
+func RhumbDistanceOnSphere(a Geographic, b Geographic, radius float64) float64 {
+	return radius * rhumbAngle(a, b)
+}
+
+/*
+ * Returns the constant bearing (in radians, clockwise from north, within
+ * [0, 2 * pi)) of the rhumb line from one geographic location to another.
+ */
+func RhumbBearing(a Geographic, b Geographic) float64 {
+	_, deltaPsi, deltaLon := rhumbDeltas(a, b)
+	bearing := math.Atan2(deltaLon, deltaPsi)
+
+	/*
+	 * Normalize bearing into [0, 2 * pi).
+	 */
+	if bearing < 0.0 {
+		bearing += 2.0 * math.Pi
+	}
+
+	return bearing
+}
+
+/*
+ * Returns the location at a fraction of the way along the rhumb line between
+ * two geographic locations.
+ *
+ * The fraction f ranges from zero (first location) to one (second location).
+ */
+func RhumbInterpolate(a Geographic, b Geographic, f float64) Geographic {
+	deltaLat, deltaPsi, deltaLon := rhumbDeltas(a, b)
+	latitude := a.latitude + (f * deltaLat)
+	longitude := a.longitude + (f * deltaLon)
+
+	/*
+	 * Unless the rhumb line follows a parallel, the longitude changes in
+	 * proportion to the isometric latitude.
+	 */
+	if math.Abs(deltaPsi) > 1e-12 {
+		psi := isometricLatitude(latitude) - isometricLatitude(a.latitude)
+		longitude = a.longitude + ((psi / deltaPsi) * deltaLon)
+	}
+
+	longitude = NormalizeLongitude(longitude)
+	return CreateGeographic(longitude, latitude)
+}
+
+/*
+ * Interpolate points along the rhumb line between two geographic locations,
+ * e. g. to project nautical tracks followed at a constant bearing.
+ *
+ * The spacing is the largest distance (in radians) between subsequent points
+ * along the rhumb line. Divide a distance by the radius of the earth to
+ * obtain it. The result includes both locations and the points are evenly
+ * spaced.
+ */
+func RhumbDensify(a Geographic, b Geographic, spacing float64) ([]Geographic, error) {
+
+	/*
+	 * Check parameters.
+	 */
+	if !(spacing > 0.0) {
+		return nil, fmt.Errorf("%s", "Spacing must be positive.")
+	} else {
+		angle := rhumbAngle(a, b)
+		numSegments := int(math.Ceil(angle / spacing))
+
+		/*
+		 * Use at least one segment.
+		 */
+		if numSegments < 1 {
+			numSegments = 1
+		}
+
+		result := make([]Geographic, numSegments+1)
+		result[0] = a
+		result[numSegments] = b
+
+		/*
+		 * Interpolate inner points.
+		 */
+		for i := 1; i < numSegments; i++ {
+			f := float64(i) / float64(numSegments)
+			result[i] = RhumbInterpolate(a, b, f)
+		}
+
+		return result, nil
+	}
+
+}
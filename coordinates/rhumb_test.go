@@ -0,0 +1,91 @@
+package coordinates
+
+import (
+	"math"
+	"testing"
+)
+
+/*
+ * Convert an angle given in degrees, minutes and seconds into degrees.
+ */
+func dms(degrees float64, minutes float64, seconds float64) float64 {
+	return degrees + (minutes / 60.0) + (seconds / 3600.0)
+}
+
+/*
+ * Create a geographic location from a longitude and latitude in degrees.
+ */
+func locationDegrees(longitude float64, latitude float64) Geographic {
+	return CreateGeographic((math.Pi/180.0)*longitude, (math.Pi/180.0)*latitude)
+}
+
+/*
+ * Constant bearing of the rhumb line from Dover to Calais.
+ */
+func TestRhumbBearing(t *testing.T) {
+	dover := locationDegrees(dms(1, 20, 17), dms(51, 7, 32))
+	calais := locationDegrees(dms(1, 51, 9), dms(50, 57, 48))
+	bearing := (180.0 / math.Pi) * RhumbBearing(dover, calais)
+	expected := dms(116, 38, 10)
+
+	/*
+	 * Bearings must agree to an arc second.
+	 */
+	if math.Abs(bearing-expected) > (1.0 / 3600.0) {
+		t.Errorf("Bearing is %f, expected %f", bearing, expected)
+	}
+
+}
+
+/*
+ * Rhumb lines along meridians and parallels have known lengths.
+ */
+func TestRhumbDistance(t *testing.T) {
+
+	/*
+	 * Locations with known rhumb distances.
+	 */
+	cases := []struct {
+		a        Geographic
+		b        Geographic
+		distance float64
+	}{
+		{locationDegrees(10.0, -20.0), locationDegrees(10.0, 70.0), 0.5 * math.Pi * EARTH_RADIUS},
+		{locationDegrees(0.0, 60.0), locationDegrees(90.0, 60.0), 0.25 * math.Pi * EARTH_RADIUS},
+		{locationDegrees(170.0, 0.0), locationDegrees(-170.0, 0.0), (math.Pi / 9.0) * EARTH_RADIUS},
+	}
+
+	/*
+	 * Compare each distance with the known one.
+	 */
+	for i, c := range cases {
+		distance := RhumbDistance(c.a, c.b)
+
+		/*
+		 * Distances must agree to a millimeter.
+		 */
+		if math.Abs(distance-c.distance) > 1e-3 {
+			t.Errorf("Case %d: distance is %f m, expected %f m", i, distance, c.distance)
+		}
+
+	}
+
+}
+
+/*
+ * Rhumb lines are never shorter than great circles.
+ */
+func TestRhumbLongerThanGreatCircle(t *testing.T) {
+	a := locationDegrees(-74.0, 40.7)
+	b := locationDegrees(2.35, 48.85)
+	rhumb := RhumbDistance(a, b)
+	greatCircle := EARTH_RADIUS * centralAngle(a, b)
+
+	/*
+	 * Check if rhumb line is longer.
+	 */
+	if rhumb <= greatCircle {
+		t.Errorf("Rhumb line of %f m is not longer than great circle of %f m.", rhumb, greatCircle)
+	}
+
+}
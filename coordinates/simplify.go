@@ -0,0 +1,131 @@
+package coordinates
+
+import (
+	"fmt"
+)
+
+/*
+ * Returns the squared distance between a point and the segment between two
+ * points.
+ */
+func segmentDistanceSquared(point Cartesian, a Cartesian, b Cartesian) float64 {
+	ab := b.Sub(a)
+	ap := point.Sub(a)
+	lengthSquared := ab.Dot(ab)
+
+	/*
+	 * Degenerate segments collapse to a single point.
+	 */
+	if lengthSquared == 0.0 {
+		return ap.Dot(ap)
+	} else {
+		t := ab.Dot(ap) / lengthSquared
+
+		/*
+		 * Limit the projection to the segment.
+		 */
+		if t < 0.0 {
+			t = 0.0
+		} else if t > 1.0 {
+			t = 1.0
+		}
+
+		closest := ab.Scale(t)
+		diff := ap.Sub(closest)
+		return diff.Dot(diff)
+	}
+
+}
+
+/*
+ * Simplify a path (e. g. a projected GPS trace) using the Douglas-Peucker
+ * algorithm, so that it can be thinned before aggregation.
+ *
+ * Points are removed as long as the simplified path deviates from the
+ * original one by no more than the tolerance, which is given in the units of
+ * the coordinates, e. g. the size of a pixel. The first and last points are
+ * always kept. The result is a new slice.
+ */
+func Simplify(path []Cartesian, tolerance float64) ([]Cartesian, error) {
+	numPoints := len(path)
+
+	/*
+	 * Check parameters.
+	 */
+	if !(tolerance >= 0.0) {
+		return nil, fmt.Errorf("%s", "Tolerance must not be negative.")
+	} else if numPoints < 3 {
+		result := make([]Cartesian, numPoints)
+		copy(result, path)
+		return result, nil
+	} else {
+		toleranceSquared := tolerance * tolerance
+		keep := make([]bool, numPoints)
+		last := numPoints - 1
+		keep[0] = true
+		keep[last] = true
+		stack := [][2]int{{0, last}}
+
+		/*
+		 * Use an explicit stack, since recursion might become very deep
+		 * for long paths.
+		 */
+		for len(stack) > 0 {
+			top := len(stack) - 1
+			span := stack[top]
+			stack = stack[:top]
+			first := span[0]
+			end := span[1]
+			a := path[first]
+			b := path[end]
+			maxDistance := float64(-1.0)
+			maxIdx := -1
+
+			/*
+			 * Find the point deviating most from the segment.
+			 */
+			for i := first + 1; i < end; i++ {
+				distance := segmentDistanceSquared(path[i], a, b)
+
+				/*
+				 * Check if this point deviates further.
+				 */
+				if distance > maxDistance {
+					maxDistance = distance
+					maxIdx = i
+				}
+
+			}
+
+			/*
+			 * Keep the point and split the span if the deviation is too
+			 * large.
+			 */
+			if (maxIdx >= 0) && (maxDistance > toleranceSquared) {
+				keep[maxIdx] = true
+				stack = append(stack, [2]int{first, maxIdx})
+				stack = append(stack, [2]int{maxIdx, end})
+			}
+
+		}
+
+		result := []Cartesian{}
+
+		/*
+		 * Collect the points to keep.
+		 */
+		for i, point := range path {
+
+			/*
+			 * Check if point is kept.
+			 */
+			if keep[i] {
+				result = append(result, point)
+			}
+
+		}
+
+		return result, nil
+	}
+
+}
@@ -0,0 +1,56 @@
+package coordinates
+
+import (
+	"math"
+)
+
+/*
+ * Returns the sum of this vector and another vector.
+ */
+func (this *Cartesian) Add(other Cartesian) Cartesian {
+	x := this.x + other.x
+	y := this.y + other.y
+	return CreateCartesian(x, y)
+}
+
+/*
+ * Returns the difference of this vector and another vector.
+ */
+func (this *Cartesian) Sub(other Cartesian) Cartesian {
+	x := this.x - other.x
+	y := this.y - other.y
+	return CreateCartesian(x, y)
+}
+
+/*
+ * Returns this vector multiplied by a scalar factor.
+ */
+func (this *Cartesian) Scale(factor float64) Cartesian {
+	x := factor * this.x
+	y := factor * this.y
+	return CreateCartesian(x, y)
+}
+
+/*
+ * Returns the dot (scalar) product of this vector and another vector.
+ */
+func (this *Cartesian) Dot(other Cartesian) float64 {
+	return (this.x * other.x) + (this.y * other.y)
+}
+
+/*
+ * Returns the Euclidean norm (length) of this vector.
+ */
+func (this *Cartesian) Norm() float64 {
+	return math.Hypot(this.x, this.y)
+}
+
+/*
+ * Returns the Euclidean distance between the points represented by this
+ * vector and another vector.
+ */
+func (this *Cartesian) Distance(other Cartesian) float64 {
+	dx := this.x - other.x
+	dy := this.y - other.y
+	return math.Hypot(dx, dy)
+}
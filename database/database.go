@@ -0,0 +1,685 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"time"
+)
+
+/*
+ * The number of rows aggregated at once.
+ */
+const (
+	BATCH_SIZE = 65536
+)
+
+/*
+ * Data structure representing a row returned by a query.
+ *
+ * Records are immutable.
+ */
+type Record struct {
+	timestamp time.Time
+	weight    float64
+	x         float64
+	y         float64
+}
+
+/*
+ * Returns the time of this record or the zero time if no time column was
+ * configured or the value is null.
+ */
+func (this *Record) Time() time.Time {
+	return this.timestamp
+}
+
+/*
+ * Returns the weight of this record or one if no weight column was
+ * configured or the value is null.
+ */
+func (this *Record) Weight() float64 {
+	return this.weight
+}
+
+/*
+ * Returns the x-coordinate or longitude (in degrees) of this record.
+ */
+func (this *Record) X() float64 {
+	return this.x
+}
+
+/*
+ * Returns the y-coordinate or latitude (in degrees) of this record.
+ */
+func (this *Record) Y() float64 {
+	return this.y
+}
+
+/*
+ * A queryer runs queries, e. g. *sql.DB, *sql.Conn or *sql.Tx.
+ */
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+/*
+ * Data structure representing the configuration of a reader.
+ */
+type configStruct struct {
+	args       []interface{}
+	key        string
+	pageSize   int
+	projection projection.Projection
+	start      interface{}
+	time       string
+	weight     string
+	x          string
+	y          string
+}
+
+/*
+ * A configuration option for a reader.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Pass arguments for the placeholders of the query.
+ */
+func WithArgs(args ...interface{}) Option {
+
+	/*
+	 * Set arguments.
+	 */
+	return func(config *configStruct) {
+		config.args = args
+	}
+
+}
+
+/*
+ * Select the columns holding the x- and y-coordinates (or longitude and
+ * latitude) by name.
+ */
+func WithCoordinates(x string, y string) Option {
+
+	/*
+	 * Set coordinate columns.
+	 */
+	return func(config *configStruct) {
+		config.x = x
+		config.y = y
+	}
+
+}
+
+/*
+ * Fetch rows in pages using keyset (cursor-based) paging, so that the
+ * database never has to deliver a large result set at once.
+ *
+ * The query is run once per page. It must order rows by a unique key column
+ * and accept two additional arguments after those passed using WithArgs:
+ * the key of the last row of the previous page, starting with start, and
+ * the page size, e. g. "SELECT id, lon AS x, lat AS y FROM points WHERE
+ * id > $1 ORDER BY id LIMIT $2" with key "id" and start 0.
+ */
+func WithPaging(key string, start interface{}, pageSize int) Option {
+
+	/*
+	 * Set paging parameters.
+	 */
+	return func(config *configStruct) {
+		config.key = key
+		config.pageSize = pageSize
+		config.start = start
+	}
+
+}
+
+/*
+ * Project longitudes and latitudes (in degrees) when aggregating records
+ * into a scene.
+ */
+func WithProjection(proj projection.Projection) Option {
+
+	/*
+	 * Set projection.
+	 */
+	return func(config *configStruct) {
+		config.projection = proj
+	}
+
+}
+
+/*
+ * Select the column holding the time of each record by name.
+ *
+ * The driver must be able to convert the column to time.Time.
+ */
+func WithTime(name string) Option {
+
+	/*
+	 * Set time column.
+	 */
+	return func(config *configStruct) {
+		config.time = name
+	}
+
+}
+
+/*
+ * Select the column holding the weight of each record by name.
+ */
+func WithWeight(name string) Option {
+
+	/*
+	 * Set weight column.
+	 */
+	return func(config *configStruct) {
+		config.weight = name
+	}
+
+}
+
+/*
+ * A reader reads records returned by a query.
+ */
+type Reader interface {
+	Aggregate(scn scene.Scene) error
+	Close() error
+	Read() (Record, error)
+}
+
+/*
+ * Data structure representing a reader.
+ */
+type readerStruct struct {
+	config    configStruct
+	ctx       context.Context
+	db        Queryer
+	done      bool
+	key       int
+	lastKey   interface{}
+	pageCount int
+	query     string
+	rows      *sql.Rows
+	targets   []interface{}
+	time      int
+	weight    int
+	x         int
+	y         int
+}
+
+/*
+ * Data structure receiving a value which is not used.
+ */
+type discardStruct struct {
+}
+
+/*
+ * Discard a value.
+ */
+func (this *discardStruct) Scan(value interface{}) error {
+	return nil
+}
+
+/*
+ * Data structure receiving the key of a row.
+ */
+type keyStruct struct {
+	value interface{}
+}
+
+/*
+ * Store the key of a row, copying byte slices, which the driver may reuse.
+ */
+func (this *keyStruct) Scan(value interface{}) error {
+
+	/*
+	 * Copy byte slices.
+	 */
+	if b, ok := value.([]byte); ok {
+		value = append([]byte{}, b...)
+	}
+
+	this.value = value
+	return nil
+}
+
+/*
+ * Returns the index of a column or -1 if no name is given.
+ */
+func columnIndex(columns []string, name string) (int, error) {
+
+	/*
+	 * No name selects no column.
+	 */
+	if name == "" {
+		return -1, nil
+	} else {
+
+		/*
+		 * Look for the column.
+		 */
+		for i, column := range columns {
+
+			/*
+			 * Check if column matches.
+			 */
+			if column == name {
+				return i, nil
+			}
+
+		}
+
+		return -1, fmt.Errorf("No such column: '%s'", name)
+	}
+
+}
+
+/*
+ * Resolve the configured columns and prepare the targets for scanning rows.
+ */
+func (this *readerStruct) prepare(columns []string) error {
+	config := &this.config
+	names := []string{config.x, config.y, config.weight, config.time, config.key}
+	indices := make([]int, len(names))
+
+	/*
+	 * Look up each configured column.
+	 */
+	for i, name := range names {
+		idx, err := columnIndex(columns, name)
+
+		/*
+		 * Check if column exists.
+		 */
+		if err != nil {
+			return err
+		}
+
+		indices[i] = idx
+	}
+
+	this.x, this.y, this.weight, this.time, this.key = indices[0], indices[1], indices[2], indices[3], indices[4]
+	targets := make([]interface{}, len(columns))
+
+	/*
+	 * Discard all values by default.
+	 */
+	for i := range targets {
+		targets[i] = &discardStruct{}
+	}
+
+	targets[this.x] = &sql.NullFloat64{}
+	targets[this.y] = &sql.NullFloat64{}
+
+	/*
+	 * Scan weight if configured.
+	 */
+	if this.weight >= 0 {
+		targets[this.weight] = &sql.NullFloat64{}
+	}
+
+	/*
+	 * Scan time if configured.
+	 */
+	if this.time >= 0 {
+		targets[this.time] = &sql.NullTime{}
+	}
+
+	/*
+	 * Scan key if paging is enabled.
+	 */
+	if this.key >= 0 {
+		targets[this.key] = &keyStruct{}
+	}
+
+	this.targets = targets
+	return nil
+}
+
+/*
+ * Run the query for the next page, or the only query if paging is
+ * disabled.
+ */
+func (this *readerStruct) open() error {
+	config := &this.config
+	args := config.args
+
+	/*
+	 * Pass the last key and the page size if paging is enabled.
+	 */
+	if config.key != "" {
+
+		/*
+		 * Check if page size is valid.
+		 */
+		if config.pageSize <= 0 {
+			return fmt.Errorf("Page size must be positive, but is %d.", config.pageSize)
+		}
+
+		args = append(append([]interface{}{}, args...), this.lastKey, config.pageSize)
+	}
+
+	rows, err := this.db.QueryContext(this.ctx, this.query, args...)
+
+	/*
+	 * Check if query succeeded.
+	 */
+	if err != nil {
+		return err
+	}
+
+	/*
+	 * Resolve columns on the first query.
+	 */
+	if this.targets == nil {
+		columns, err := rows.Columns()
+
+		/*
+		 * Check if columns could be resolved.
+		 */
+		if err == nil {
+			err = this.prepare(columns)
+		}
+
+		/*
+		 * Check if columns are valid.
+		 */
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+	}
+
+	this.rows = rows
+	this.pageCount = 0
+	return nil
+}
+
+/*
+ * Read the next record.
+ *
+ * Rows with null coordinates are skipped. Returns io.EOF once all rows are
+ * read.
+ */
+func (this *readerStruct) Read() (Record, error) {
+	config := &this.config
+
+	/*
+	 * Read rows until a record with coordinates is found.
+	 */
+	for {
+
+		/*
+		 * Run the query for the next page if needed.
+		 */
+		if this.rows == nil {
+
+			/*
+			 * Check if all rows are read.
+			 */
+			if this.done {
+				return Record{}, io.EOF
+			}
+
+			err := this.open()
+
+			/*
+			 * Check if query succeeded.
+			 */
+			if err != nil {
+				return Record{}, err
+			}
+
+		}
+
+		rows := this.rows
+
+		/*
+		 * Check if the current page ends.
+		 */
+		if !rows.Next() {
+			err := rows.Err()
+			rows.Close()
+			this.rows = nil
+
+			/*
+			 * A page which is not full is the last one.
+			 */
+			if err != nil {
+				return Record{}, err
+			} else if (config.key == "") || (this.pageCount < config.pageSize) {
+				this.done = true
+			}
+
+		} else {
+			err := rows.Scan(this.targets...)
+
+			/*
+			 * Check if row could be scanned.
+			 */
+			if err != nil {
+				return Record{}, err
+			}
+
+			this.pageCount++
+
+			/*
+			 * Remember the key for the next page.
+			 */
+			if this.key >= 0 {
+				this.lastKey = this.targets[this.key].(*keyStruct).value
+			}
+
+			x := this.targets[this.x].(*sql.NullFloat64)
+			y := this.targets[this.y].(*sql.NullFloat64)
+
+			/*
+			 * Skip rows with null coordinates.
+			 */
+			if x.Valid && y.Valid {
+				weight := 1.0
+				timestamp := time.Time{}
+
+				/*
+				 * Use weight if present.
+				 */
+				if this.weight >= 0 {
+					value := this.targets[this.weight].(*sql.NullFloat64)
+
+					/*
+					 * Null weights count as one.
+					 */
+					if value.Valid {
+						weight = value.Float64
+					}
+
+				}
+
+				/*
+				 * Use time if present.
+				 */
+				if this.time >= 0 {
+					timestamp = this.targets[this.time].(*sql.NullTime).Time
+				}
+
+				/*
+				 * Create record.
+				 */
+				record := Record{
+					timestamp: timestamp,
+					weight:    weight,
+					x:         x.Float64,
+					y:         y.Float64,
+				}
+
+				return record, nil
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Read all remaining records and aggregate them into a scene, projecting
+ * them first if a projection is configured.
+ *
+ * Records are streamed in batches, so that the result set need not fit into
+ * memory. Each record counts once, regardless of its weight.
+ */
+func (this *readerStruct) Aggregate(scn scene.Scene) error {
+	proj := this.config.projection
+	batch := make([]coordinates.Cartesian, 0, BATCH_SIZE)
+	locations := []coordinates.Geographic{}
+
+	/*
+	 * Aggregate collected points.
+	 */
+	flush := func() error {
+
+		/*
+		 * Project points if needed.
+		 */
+		if proj != nil {
+			locations = locations[:0]
+
+			/*
+			 * The x- and y-coordinates are longitude and latitude.
+			 */
+			for i := range batch {
+				point := &batch[i]
+				location := coordinates.CreateGeographicDegrees(point.X(), point.Y())
+				locations = append(locations, location)
+			}
+
+			err := proj.Forward(batch, locations)
+
+			/*
+			 * Points which could not be projected are NaN and are not
+			 * aggregated, so only other errors are fatal.
+			 */
+			if _, ok := err.(projection.BatchError); (err != nil) && !ok {
+				return err
+			}
+
+		}
+
+		scn.Aggregate(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	/*
+	 * Read all records.
+	 */
+	for {
+		record, err := this.Read()
+
+		/*
+		 * Stop once all rows are read.
+		 */
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		point := coordinates.CreateCartesian(record.x, record.y)
+		batch = append(batch, point)
+
+		/*
+		 * Aggregate full batches.
+		 */
+		if len(batch) >= BATCH_SIZE {
+			err = flush()
+
+			/*
+			 * Check if batch could be aggregated.
+			 */
+			if err != nil {
+				return err
+			}
+
+		}
+
+	}
+
+	return flush()
+}
+
+/*
+ * Close the result set of the current query, e. g. when reading is aborted
+ * before all rows are read.
+ */
+func (this *readerStruct) Close() error {
+	rows := this.rows
+	this.rows = nil
+	this.done = true
+
+	/*
+	 * Check if a query is running.
+	 */
+	if rows == nil {
+		return nil
+	} else {
+		return rows.Close()
+	}
+
+}
+
+/*
+ * Create a reader for the rows returned by a query.
+ *
+ * The query only runs once the first record is read. By default, the
+ * columns named "x" and "y" hold the coordinates and all rows are fetched
+ * using a single query, which relies on the driver to stream them.
+ */
+func Create(ctx context.Context, db Queryer, query string, options ...Option) Reader {
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		x: "x",
+		y: "y",
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	/*
+	 * Create reader data structure.
+	 */
+	rd := readerStruct{
+		config:  config,
+		ctx:     ctx,
+		db:      db,
+		key:     -1,
+		lastKey: config.start,
+		query:   query,
+		time:    -1,
+		weight:  -1,
+	}
+
+	return &rd
+}
@@ -0,0 +1,98 @@
+package decompress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+/*
+ * Magic numbers identifying compressed streams.
+ */
+const (
+	MAGIC_BZIP2 = "BZh"
+	MAGIC_GZIP  = "\x1f\x8b"
+	MAGIC_ZSTD  = "\x28\xb5\x2f\xfd"
+)
+
+/*
+ * Data structure representing a reader, which decompresses its source if
+ * needed.
+ */
+type readerStruct struct {
+	err         error
+	initialized bool
+	reader      io.Reader
+	source      io.Reader
+}
+
+/*
+ * Inspect the start of the source and select a decompressor.
+ */
+func (this *readerStruct) detect() {
+	buffered := bufio.NewReader(this.source)
+	magic, _ := buffered.Peek(len(MAGIC_ZSTD))
+	this.initialized = true
+	this.source = nil
+
+	/*
+	 * Decide on the compression.
+	 */
+	if bytes.HasPrefix(magic, []byte(MAGIC_GZIP)) {
+		this.reader, this.err = gzip.NewReader(buffered)
+	} else if bytes.HasPrefix(magic, []byte(MAGIC_BZIP2)) && (len(magic) > 3) && (magic[3] >= '1') && (magic[3] <= '9') {
+		this.reader = bzip2.NewReader(buffered)
+	} else if bytes.HasPrefix(magic, []byte(MAGIC_ZSTD)) {
+		this.err = fmt.Errorf("%s", "Zstandard compression is not supported, decompress the stream first.")
+	} else {
+		this.reader = buffered
+	}
+
+}
+
+/*
+ * Read decompressed data.
+ */
+func (this *readerStruct) Read(p []byte) (int, error) {
+
+	/*
+	 * Detect compression on first read.
+	 */
+	if !this.initialized {
+		this.detect()
+	}
+
+	/*
+	 * Check if a decompressor could be created.
+	 */
+	if this.err != nil {
+		return 0, this.err
+	} else {
+		return this.reader.Read(p)
+	}
+
+}
+
+/*
+ * Wrap a reader, which transparently decompresses gzip or bzip2 compressed
+ * data and passes uncompressed data through unchanged.
+ *
+ * The compression is detected from the first bytes of the stream once it is
+ * first read, so that wrapping a reader never blocks. Concatenated gzip
+ * members, as produced by parallel compressors, are read as a single stream.
+ * Zstandard compression is detected, but reported as an error.
+ */
+func Auto(r io.Reader) io.Reader {
+
+	/*
+	 * Create reader data structure.
+	 */
+	rd := readerStruct{
+		source: r,
+	}
+
+	return &rd
+}
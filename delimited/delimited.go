@@ -0,0 +1,818 @@
+package delimited
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Special layouts for time columns, which hold numeric timestamps.
+ */
+const (
+	TIME_UNIX        = "unix"
+	TIME_UNIX_MILLIS = "unixmillis"
+)
+
+/*
+ * The number of rows aggregated at once.
+ */
+const (
+	BATCH_SIZE = 65536
+)
+
+/*
+ * Data structure identifying a column, either by its index or by its name in
+ * the header row.
+ */
+type Column struct {
+	index int
+	name  string
+}
+
+/*
+ * Data structure representing a row read from a delimited file.
+ *
+ * Records are immutable.
+ */
+type Record struct {
+	category  string
+	timestamp time.Time
+	weight    float64
+	x         float64
+	y         float64
+}
+
+/*
+ * Data structure representing the configuration of a reader.
+ */
+type configStruct struct {
+	category     Column
+	decimalComma bool
+	delimiter    rune
+	header       bool
+	projection   projection.Projection
+	skipInvalid  bool
+	time         Column
+	timeLayout   string
+	weight       Column
+	x            Column
+	y            Column
+}
+
+/*
+ * A configuration option for a reader.
+ */
+type Option func(config *configStruct)
+
+/*
+ * A reader reads records from a delimited (e. g. CSV or TSV) file.
+ */
+type Reader interface {
+	Aggregate(scn scene.Scene) error
+	AggregateByCategory(scenes map[string]scene.Scene) error
+	Read() (Record, error)
+}
+
+/*
+ * Data structure representing a reader.
+ */
+type readerStruct struct {
+	category    int
+	config      configStruct
+	csv         *csv.Reader
+	initialized bool
+	line        int
+	time        int
+	weight      int
+	x           int
+	y           int
+}
+
+/*
+ * Returns the category of this record or the empty string if no category
+ * column was configured.
+ */
+func (this *Record) Category() string {
+	return this.category
+}
+
+/*
+ * Returns the time of this record or the zero time if no time column was
+ * configured.
+ */
+func (this *Record) Time() time.Time {
+	return this.timestamp
+}
+
+/*
+ * Returns the weight of this record or one if no weight column was
+ * configured.
+ */
+func (this *Record) Weight() float64 {
+	return this.weight
+}
+
+/*
+ * Returns the x-coordinate or longitude (in degrees) of this record.
+ */
+func (this *Record) X() float64 {
+	return this.x
+}
+
+/*
+ * Returns the y-coordinate or latitude (in degrees) of this record.
+ */
+func (this *Record) Y() float64 {
+	return this.y
+}
+
+/*
+ * Identify a column by its (zero-based) index.
+ */
+func ColumnIndex(index int) Column {
+	return Column{index: index}
+}
+
+/*
+ * Identify a column by its name in the header row.
+ */
+func ColumnName(name string) Column {
+	return Column{index: -1, name: name}
+}
+
+/*
+ * Set the columns holding the x- and y-coordinates or the longitude and
+ * latitude (in degrees). By default, these are the first two columns.
+ */
+func WithCoordinates(x Column, y Column) Option {
+
+	/*
+	 * Set the coordinate columns.
+	 */
+	option := func(config *configStruct) {
+		config.x = x
+		config.y = y
+	}
+
+	return option
+}
+
+/*
+ * Set the column holding a category, e. g. to aggregate each category into
+ * its own scene.
+ */
+func WithCategory(column Column) Option {
+
+	/*
+	 * Set the category column.
+	 */
+	option := func(config *configStruct) {
+		config.category = column
+	}
+
+	return option
+}
+
+/*
+ * Interpret decimal commas, e. g. "3,14", as used in many locales. The
+ * delimiter must not be a comma then.
+ */
+func WithDecimalComma() Option {
+
+	/*
+	 * Enable decimal commas.
+	 */
+	option := func(config *configStruct) {
+		config.decimalComma = true
+	}
+
+	return option
+}
+
+/*
+ * Set the delimiter between fields, e. g. '\t' for TSV files. By default,
+ * this is a comma.
+ */
+func WithDelimiter(delimiter rune) Option {
+
+	/*
+	 * Set the delimiter.
+	 */
+	option := func(config *configStruct) {
+		config.delimiter = delimiter
+	}
+
+	return option
+}
+
+/*
+ * Skip the header row even if all columns are identified by their index.
+ *
+ * If a column is identified by name, the header row is read anyway.
+ */
+func WithHeader() Option {
+
+	/*
+	 * Enable the header row.
+	 */
+	option := func(config *configStruct) {
+		config.header = true
+	}
+
+	return option
+}
+
+/*
+ * Interpret the coordinates as longitude and latitude (in degrees) and
+ * project them when aggregating them into a scene.
+ */
+func WithProjection(proj projection.Projection) Option {
+
+	/*
+	 * Set the projection.
+	 */
+	option := func(config *configStruct) {
+		config.projection = proj
+	}
+
+	return option
+}
+
+/*
+ * Skip rows, which cannot be parsed, instead of failing.
+ */
+func WithSkipInvalid() Option {
+
+	/*
+	 * Enable skipping of invalid rows.
+	 */
+	option := func(config *configStruct) {
+		config.skipInvalid = true
+	}
+
+	return option
+}
+
+/*
+ * Set the column holding the time along with its layout as understood by
+ * time.Parse or one of TIME_UNIX and TIME_UNIX_MILLIS. An empty layout is
+ * interpreted as RFC 3339.
+ */
+func WithTime(column Column, layout string) Option {
+
+	/*
+	 * Set the time column.
+	 */
+	option := func(config *configStruct) {
+		config.time = column
+		config.timeLayout = layout
+	}
+
+	return option
+}
+
+/*
+ * Set the column holding a weight.
+ *
+ * Since the bins of a scene hold counts, weights are rounded to the nearest
+ * integer when records are aggregated. Records with weights below 0.5 add
+ * nothing to the scene, so scale fractional weights (e. g. probabilities) up
+ * before aggregating them.
+ */
+func WithWeight(column Column) Option {
+
+	/*
+	 * Set the weight column.
+	 */
+	option := func(config *configStruct) {
+		config.weight = column
+	}
+
+	return option
+}
+
+/*
+ * Resolve a column to its index using the header row.
+ *
+ * Returns -1 if no column is identified.
+ */
+func resolve(column Column, header []string) (int, error) {
+
+	/*
+	 * Check if column is identified by name.
+	 */
+	if column.name == "" {
+		return column.index, nil
+	} else {
+
+		/*
+		 * Search the header row for the name.
+		 */
+		for i, name := range header {
+
+			/*
+			 * Check if name matches.
+			 */
+			if strings.TrimSpace(name) == column.name {
+				return i, nil
+			}
+
+		}
+
+		return -1, fmt.Errorf("Column '%s' not found in header.", column.name)
+	}
+
+}
+
+/*
+ * Resolve all configured columns, reading the header row if needed.
+ */
+func (this *readerStruct) initialize() error {
+	config := &this.config
+	columns := []*Column{&config.x, &config.y, &config.weight, &config.time, &config.category}
+	needHeader := config.header
+
+	/*
+	 * Check if any column is identified by name.
+	 */
+	for _, column := range columns {
+
+		/*
+		 * Check if column has a name.
+		 */
+		if column.name != "" {
+			needHeader = true
+		}
+
+	}
+
+	header := []string(nil)
+
+	/*
+	 * Read header row if needed.
+	 */
+	if needHeader {
+		row, err := this.csv.Read()
+
+		/*
+		 * Check if header could be read.
+		 */
+		if err != nil {
+			return err
+		}
+
+		this.line++
+		header = append(header, row...)
+	}
+
+	indices := []*int{&this.x, &this.y, &this.weight, &this.time, &this.category}
+
+	/*
+	 * Resolve each column.
+	 */
+	for i, column := range columns {
+		idx, err := resolve(*column, header)
+
+		/*
+		 * Check if column could be resolved.
+		 */
+		if err != nil {
+			return err
+		}
+
+		*indices[i] = idx
+	}
+
+	this.initialized = true
+	return nil
+}
+
+/*
+ * Parse a numeric field.
+ */
+func (this *readerStruct) parseNumber(field string) (float64, error) {
+	field = strings.TrimSpace(field)
+
+	/*
+	 * Replace decimal comma if needed.
+	 */
+	if this.config.decimalComma {
+		field = strings.Replace(field, ",", ".", 1)
+	}
+
+	return strconv.ParseFloat(field, 64)
+}
+
+/*
+ * Parse a time field.
+ */
+func (this *readerStruct) parseTime(field string) (time.Time, error) {
+	field = strings.TrimSpace(field)
+	layout := this.config.timeLayout
+
+	/*
+	 * Decide on the layout.
+	 */
+	switch layout {
+	case TIME_UNIX, TIME_UNIX_MILLIS:
+		value, err := this.parseNumber(field)
+
+		/*
+		 * Check if timestamp could be parsed.
+		 */
+		if err != nil {
+			return time.Time{}, err
+		} else {
+
+			/*
+			 * Convert milliseconds to seconds.
+			 */
+			if layout == TIME_UNIX_MILLIS {
+				value /= 1000.0
+			}
+
+			seconds, fraction := math.Modf(value)
+			nanos := math.Round(fraction * 1e9)
+			return time.Unix(int64(seconds), int64(nanos)).UTC(), nil
+		}
+
+	case "":
+		return time.Parse(time.RFC3339, field)
+	default:
+		return time.Parse(layout, field)
+	}
+
+}
+
+/*
+ * Parse a row into a record.
+ */
+func (this *readerStruct) parse(row []string) (Record, error) {
+	numFields := len(row)
+	xIdx := this.x
+	yIdx := this.y
+
+	/*
+	 * Check if coordinates are present.
+	 */
+	if (xIdx < 0) || (yIdx < 0) || (xIdx >= numFields) || (yIdx >= numFields) {
+		return Record{}, fmt.Errorf("%s", "Coordinates missing.")
+	} else {
+		x, errX := this.parseNumber(row[xIdx])
+		y, errY := this.parseNumber(row[yIdx])
+
+		/*
+		 * Check if coordinates could be parsed.
+		 */
+		if errX != nil {
+			return Record{}, errX
+		} else if errY != nil {
+			return Record{}, errY
+		} else {
+
+			/*
+			 * The record with default values.
+			 */
+			record := Record{
+				weight: 1.0,
+				x:      x,
+				y:      y,
+			}
+
+			/*
+			 * Parse weight if configured.
+			 */
+			if (this.weight >= 0) && (this.weight < numFields) {
+				weight, err := this.parseNumber(row[this.weight])
+
+				/*
+				 * Check if weight could be parsed.
+				 */
+				if err != nil {
+					return Record{}, err
+				}
+
+				record.weight = weight
+			}
+
+			/*
+			 * Parse time if configured.
+			 */
+			if (this.time >= 0) && (this.time < numFields) {
+				timestamp, err := this.parseTime(row[this.time])
+
+				/*
+				 * Check if time could be parsed.
+				 */
+				if err != nil {
+					return Record{}, err
+				}
+
+				record.timestamp = timestamp
+			}
+
+			/*
+			 * Read category if configured.
+			 */
+			if (this.category >= 0) && (this.category < numFields) {
+				record.category = strings.TrimSpace(row[this.category])
+			}
+
+			return record, nil
+		}
+
+	}
+
+}
+
+/*
+ * Read the next record.
+ *
+ * Returns io.EOF after the last record.
+ */
+func (this *readerStruct) Read() (Record, error) {
+
+	/*
+	 * Resolve columns on first read.
+	 */
+	if !this.initialized {
+		err := this.initialize()
+
+		/*
+		 * Check if columns could be resolved.
+		 */
+		if err != nil {
+			return Record{}, err
+		}
+
+	}
+
+	/*
+	 * Read rows until a valid one is found.
+	 */
+	for {
+		row, err := this.csv.Read()
+
+		/*
+		 * Check if row could be read.
+		 */
+		if err != nil {
+			return Record{}, err
+		}
+
+		this.line++
+		record, err := this.parse(row)
+
+		/*
+		 * Return valid records, skip or report invalid ones.
+		 */
+		if err == nil {
+			return record, nil
+		} else if !this.config.skipInvalid {
+			return Record{}, fmt.Errorf("Invalid row %d: %s", this.line, err.Error())
+		}
+
+	}
+
+}
+
+/*
+ * Read records and aggregate them into scenes in batches of BATCH_SIZE
+ * points. The function selects the scene for a record, which is skipped if
+ * no scene is returned.
+ *
+ * If a weight column is configured, each record adds its weight to the
+ * scene.
+ */
+func (this *readerStruct) aggregate(selectScene func(record *Record) scene.Scene) error {
+	proj := this.config.projection
+	points := make(map[scene.Scene][]coordinates.Cartesian)
+	weights := make(map[scene.Scene][]float64)
+	locations := []coordinates.Geographic{}
+
+	/*
+	 * Flush points collected for a scene.
+	 */
+	flush := func(scn scene.Scene) error {
+		batch := points[scn]
+
+		/*
+		 * Project points if needed.
+		 */
+		if proj != nil {
+			locations = locations[:0]
+
+			/*
+			 * The x- and y-coordinates are longitude and latitude.
+			 */
+			for i := range batch {
+				point := &batch[i]
+				location := coordinates.CreateGeographicDegrees(point.X(), point.Y())
+				locations = append(locations, location)
+			}
+
+			err := proj.Forward(batch, locations)
+
+			/*
+			 * Points which could not be projected are NaN and are not
+			 * aggregated, so only other errors are fatal.
+			 */
+			if _, ok := err.(projection.BatchError); (err != nil) && !ok {
+				return err
+			}
+
+		}
+
+		/*
+		 * Aggregate weighted points if a weight column is present.
+		 */
+		if this.weight >= 0 {
+			batchWeights := weights[scn]
+			err := scn.AggregateWeighted(batch, batchWeights)
+
+			/*
+			 * Check if points could be aggregated.
+			 */
+			if err != nil {
+				return err
+			}
+
+			weights[scn] = batchWeights[:0]
+		} else {
+			scn.Aggregate(batch)
+		}
+
+		points[scn] = batch[:0]
+		return nil
+	}
+
+	/*
+	 * Read all records.
+	 */
+	for {
+		record, err := this.Read()
+
+		/*
+		 * Stop at the end of the file.
+		 */
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		scn := selectScene(&record)
+
+		/*
+		 * Skip records without scene.
+		 */
+		if scn != nil {
+			point := coordinates.CreateCartesian(record.x, record.y)
+			batch := append(points[scn], point)
+			points[scn] = batch
+
+			/*
+			 * Keep the weight if a weight column is present.
+			 */
+			if this.weight >= 0 {
+				weights[scn] = append(weights[scn], record.weight)
+			}
+
+			/*
+			 * Aggregate full batches.
+			 */
+			if len(batch) >= BATCH_SIZE {
+				err = flush(scn)
+
+				/*
+				 * Check if batch could be aggregated.
+				 */
+				if err != nil {
+					return err
+				}
+
+			}
+
+		}
+
+	}
+
+	/*
+	 * Aggregate remaining points.
+	 */
+	for scn := range points {
+		err := flush(scn)
+
+		/*
+		 * Check if batch could be aggregated.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+/*
+ * Read all remaining records and aggregate them into a scene, projecting
+ * them first if a projection is configured.
+ *
+ * Records are streamed in batches, so that the file need not fit into
+ * memory. If a weight column is configured, each record adds its weight,
+ * rounded to the nearest integer, to the scene, so that records with weights
+ * below 0.5 are not counted at all. Otherwise, each record counts once.
+ */
+func (this *readerStruct) Aggregate(scn scene.Scene) error {
+
+	/*
+	 * Aggregate all records into the same scene.
+	 */
+	selectScene := func(record *Record) scene.Scene {
+		return scn
+	}
+
+	return this.aggregate(selectScene)
+}
+
+/*
+ * Read all remaining records and aggregate each into the scene of its
+ * category, e. g. to composite them using scene.Composite. Records of
+ * categories without scene are skipped. Weights are rounded as for Aggregate.
+ */
+func (this *readerStruct) AggregateByCategory(scenes map[string]scene.Scene) error {
+
+	/*
+	 * Aggregate records into the scene of their category.
+	 */
+	selectScene := func(record *Record) scene.Scene {
+		return scenes[record.category]
+	}
+
+	return this.aggregate(selectScene)
+}
+
+/*
+ * Create a reader for a delimited file, e. g. CSV or TSV.
+ *
+ * By default, fields are separated by commas, the first two columns hold
+ * the x- and y-coordinates and there is no header row.
+ */
+func Create(r io.Reader, options ...Option) Reader {
+	none := Column{index: -1}
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		category:  none,
+		delimiter: ',',
+		time:      none,
+		weight:    none,
+		x:         ColumnIndex(0),
+		y:         ColumnIndex(1),
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	reader := csv.NewReader(decompress.Auto(r))
+	reader.Comma = config.delimiter
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	reader.TrimLeadingSpace = true
+
+	/*
+	 * Create reader data structure.
+	 */
+	rd := readerStruct{
+		category: -1,
+		config:   config,
+		csv:      reader,
+		time:     -1,
+		weight:   -1,
+	}
+
+	return &rd
+}
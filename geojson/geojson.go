@@ -0,0 +1,480 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"io"
+)
+
+/*
+ * Geometry types supported by GeoJSON.
+ */
+const (
+	TYPE_POINT             = "Point"
+	TYPE_MULTI_POINT       = "MultiPoint"
+	TYPE_LINE_STRING       = "LineString"
+	TYPE_MULTI_LINE_STRING = "MultiLineString"
+	TYPE_POLYGON           = "Polygon"
+	TYPE_MULTI_POLYGON     = "MultiPolygon"
+)
+
+/*
+ * Data structure representing a GeoJSON object as it is encoded, which may
+ * be a feature collection, a feature or a geometry.
+ */
+type objectStruct struct {
+	Type        string                 `json:"type"`
+	Features    []objectStruct         `json:"features,omitempty"`
+	Geometry    *objectStruct          `json:"geometry,omitempty"`
+	Geometries  []objectStruct         `json:"geometries,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	Coordinates json.RawMessage        `json:"coordinates,omitempty"`
+}
+
+/*
+ * Data structure representing a GeoJSON feature, i. e. a geometry along with
+ * a set of properties.
+ *
+ * The geometry is stored as a list of polygons, each of which is a list of
+ * lines, each of which is a list of locations. Points and lines use the
+ * lower levels of nesting only. Features are immutable.
+ */
+type Feature struct {
+	geometryType string
+	polygons     [][][]coordinates.Geographic
+	properties   map[string]interface{}
+}
+
+/*
+ * Returns the type of the geometry of this feature, e. g. TYPE_POINT.
+ */
+func (this *Feature) GeometryType() string {
+	return this.geometryType
+}
+
+/*
+ * Returns all locations of the geometry of this feature, e. g. the points of
+ * a point or multi-point geometry or the vertices of a line or polygon.
+ */
+func (this *Feature) Points() []coordinates.Geographic {
+	points := []coordinates.Geographic{}
+
+	/*
+	 * Collect the locations of all lines of all polygons.
+	 */
+	for _, polygon := range this.polygons {
+
+		/*
+		 * Collect the locations of all lines.
+		 */
+		for _, line := range polygon {
+			points = append(points, line...)
+		}
+
+	}
+
+	return points
+}
+
+/*
+ * Returns the lines of the geometry of this feature, i. e. the lines of a
+ * line string or multi-line string or the rings of a polygon or
+ * multi-polygon.
+ */
+func (this *Feature) Lines() [][]coordinates.Geographic {
+	lines := [][]coordinates.Geographic{}
+
+	/*
+	 * Collect the lines of all polygons.
+	 */
+	for _, polygon := range this.polygons {
+
+		/*
+		 * Copy each line.
+		 */
+		for _, line := range polygon {
+			lineCopy := make([]coordinates.Geographic, len(line))
+			copy(lineCopy, line)
+			lines = append(lines, lineCopy)
+		}
+
+	}
+
+	return lines
+}
+
+/*
+ * Returns the polygons of the geometry of this feature.
+ *
+ * The longitudes and latitudes (in radians) of their rings are used as x and
+ * y coordinates. Since GeoJSON repeats the first vertex of each ring at its
+ * end, this vertex is removed.
+ */
+func (this *Feature) Polygons() []coordinates.Polygon {
+	result := []coordinates.Polygon{}
+
+	/*
+	 * Only polygons and multi-polygons have rings.
+	 */
+	if (this.geometryType == TYPE_POLYGON) || (this.geometryType == TYPE_MULTI_POLYGON) {
+
+		/*
+		 * Convert each polygon.
+		 */
+		for _, polygon := range this.polygons {
+			rings := [][]coordinates.Cartesian{}
+
+			/*
+			 * Convert each ring.
+			 */
+			for _, line := range polygon {
+				numVertices := len(line)
+
+				/*
+				 * Remove the closing vertex.
+				 */
+				if numVertices > 1 && (line[0] == line[numVertices-1]) {
+					numVertices--
+				}
+
+				ring := make([]coordinates.Cartesian, numVertices)
+
+				/*
+				 * Use longitude and latitude as coordinates.
+				 */
+				for i := 0; i < numVertices; i++ {
+					location := &line[i]
+					ring[i] = coordinates.CreateCartesian(location.Longitude(), location.Latitude())
+				}
+
+				rings = append(rings, ring)
+			}
+
+			p := coordinates.CreatePolygon(rings)
+			result = append(result, p)
+		}
+
+	}
+
+	return result
+}
+
+/*
+ * Returns the value of a property of this feature or nil if the property is
+ * not set.
+ *
+ * Values are decoded like encoding/json decodes them into an interface{},
+ * i. e. numbers are float64 values.
+ */
+func (this *Feature) Property(key string) interface{} {
+	return this.properties[key]
+}
+
+/*
+ * Returns the names of all properties of this feature.
+ */
+func (this *Feature) PropertyKeys() []string {
+	keys := make([]string, 0, len(this.properties))
+
+	/*
+	 * Collect the name of each property.
+	 */
+	for key := range this.properties {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+/*
+ * Decode a GeoJSON position in degrees.
+ */
+func decodePosition(position []float64) (coordinates.Geographic, error) {
+
+	/*
+	 * A position has at least longitude and latitude.
+	 */
+	if len(position) < 2 {
+		return coordinates.Geographic{}, fmt.Errorf("%s", "Position must contain longitude and latitude.")
+	} else {
+		location := coordinates.CreateGeographicDegrees(position[0], position[1])
+		return location, nil
+	}
+
+}
+
+/*
+ * Decode a list of GeoJSON positions.
+ */
+func decodePositions(positions [][]float64) ([]coordinates.Geographic, error) {
+	locations := make([]coordinates.Geographic, len(positions))
+
+	/*
+	 * Decode each position.
+	 */
+	for i, position := range positions {
+		location, err := decodePosition(position)
+
+		/*
+		 * Check if position is valid.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		locations[i] = location
+	}
+
+	return locations, nil
+}
+
+/*
+ * Decode the coordinates of a GeoJSON geometry into a list of polygons, each
+ * of which is a list of lines.
+ */
+func decodeCoordinates(geometryType string, raw json.RawMessage) ([][][]coordinates.Geographic, error) {
+
+	/*
+	 * Decode according to the nesting depth of the geometry type.
+	 */
+	switch geometryType {
+	case TYPE_POINT:
+		position := []float64{}
+		err := json.Unmarshal(raw, &position)
+
+		/*
+		 * Check if coordinates could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			location, err := decodePosition(position)
+
+			/*
+			 * Check if position is valid.
+			 */
+			if err != nil {
+				return nil, err
+			} else {
+				line := []coordinates.Geographic{location}
+				polygon := [][]coordinates.Geographic{line}
+				return [][][]coordinates.Geographic{polygon}, nil
+			}
+
+		}
+
+	case TYPE_MULTI_POINT, TYPE_LINE_STRING:
+		positions := [][]float64{}
+		err := json.Unmarshal(raw, &positions)
+
+		/*
+		 * Check if coordinates could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			line, err := decodePositions(positions)
+
+			/*
+			 * Check if positions are valid.
+			 */
+			if err != nil {
+				return nil, err
+			} else {
+				polygon := [][]coordinates.Geographic{line}
+				return [][][]coordinates.Geographic{polygon}, nil
+			}
+
+		}
+
+	case TYPE_MULTI_LINE_STRING, TYPE_POLYGON:
+		lines := [][][]float64{}
+		err := json.Unmarshal(raw, &lines)
+
+		/*
+		 * Check if coordinates could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			polygon := make([][]coordinates.Geographic, len(lines))
+
+			/*
+			 * Decode each line.
+			 */
+			for i, positions := range lines {
+				line, err := decodePositions(positions)
+
+				/*
+				 * Check if positions are valid.
+				 */
+				if err != nil {
+					return nil, err
+				}
+
+				polygon[i] = line
+			}
+
+			return [][][]coordinates.Geographic{polygon}, nil
+		}
+
+	case TYPE_MULTI_POLYGON:
+		polygons := [][][][]float64{}
+		err := json.Unmarshal(raw, &polygons)
+
+		/*
+		 * Check if coordinates could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			result := make([][][]coordinates.Geographic, len(polygons))
+
+			/*
+			 * Decode each polygon.
+			 */
+			for i, lines := range polygons {
+				polygon := make([][]coordinates.Geographic, len(lines))
+
+				/*
+				 * Decode each line.
+				 */
+				for j, positions := range lines {
+					line, err := decodePositions(positions)
+
+					/*
+					 * Check if positions are valid.
+					 */
+					if err != nil {
+						return nil, err
+					}
+
+					polygon[j] = line
+				}
+
+				result[i] = polygon
+			}
+
+			return result, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported geometry type: '%s'", geometryType)
+	}
+
+}
+
+/*
+ * Decode a GeoJSON object, appending the features it contains.
+ *
+ * Geometry collections result in one feature per geometry, all sharing the
+ * same properties.
+ */
+func decodeObject(obj *objectStruct, properties map[string]interface{}, features []Feature) ([]Feature, error) {
+
+	/*
+	 * Decide on the type of object.
+	 */
+	switch obj.Type {
+	case "FeatureCollection":
+
+		/*
+		 * Decode each feature.
+		 */
+		for i := range obj.Features {
+			var err error
+			features, err = decodeObject(&obj.Features[i], nil, features)
+
+			/*
+			 * Check if feature could be decoded.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+		}
+
+		return features, nil
+	case "Feature":
+		geometry := obj.Geometry
+
+		/*
+		 * Features without geometry are skipped.
+		 */
+		if geometry == nil {
+			return features, nil
+		} else {
+			return decodeObject(geometry, obj.Properties, features)
+		}
+
+	case "GeometryCollection":
+
+		/*
+		 * Decode each geometry.
+		 */
+		for i := range obj.Geometries {
+			var err error
+			features, err = decodeObject(&obj.Geometries[i], properties, features)
+
+			/*
+			 * Check if geometry could be decoded.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+		}
+
+		return features, nil
+	default:
+		polygons, err := decodeCoordinates(obj.Type, obj.Coordinates)
+
+		/*
+		 * Check if coordinates could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+
+			/*
+			 * Create feature.
+			 */
+			feature := Feature{
+				geometryType: obj.Type,
+				polygons:     polygons,
+				properties:   properties,
+			}
+
+			features = append(features, feature)
+			return features, nil
+		}
+
+	}
+
+}
+
+/*
+ * Read features from a GeoJSON document, which may be a feature collection,
+ * a single feature or a bare geometry.
+ *
+ * Points, lines and polygons as well as their multi-part variants are
+ * supported. Positions are converted from degrees to radians, elevations are
+ * ignored.
+ */
+func Read(r io.Reader) ([]Feature, error) {
+	decoder := json.NewDecoder(decompress.Auto(r))
+	obj := objectStruct{}
+	err := decoder.Decode(&obj)
+
+	/*
+	 * Check if document could be decoded.
+	 */
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode GeoJSON: %s", err.Error())
+	} else {
+		return decodeObject(&obj, nil, []Feature{})
+	}
+
+}
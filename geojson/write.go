@@ -0,0 +1,383 @@
+package geojson
+
+import (
+	"encoding/json"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+)
+
+/*
+ * Data structure representing a GeoJSON geometry as it is written.
+ */
+type geometryStruct struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+/*
+ * Data structure representing a GeoJSON feature as it is written.
+ */
+type featureStruct struct {
+	Type       string                 `json:"type"`
+	Geometry   geometryStruct         `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+/*
+ * Data structure representing a GeoJSON feature collection as it is written.
+ */
+type featureCollectionStruct struct {
+	Type     string          `json:"type"`
+	Features []featureStruct `json:"features"`
+}
+
+/*
+ * Encode a location as a GeoJSON position in degrees.
+ */
+func encodePosition(location coordinates.Geographic) []float64 {
+	longitude := location.LongitudeDegrees()
+	latitude := location.LatitudeDegrees()
+	return []float64{longitude, latitude}
+}
+
+/*
+ * Encode a list of locations as GeoJSON positions.
+ *
+ * If closed is set, the first position is repeated at the end unless it
+ * already is.
+ */
+func encodePositions(locations []coordinates.Geographic, closed bool) [][]float64 {
+	numLocations := len(locations)
+	positions := make([][]float64, 0, numLocations+1)
+
+	/*
+	 * Encode each location.
+	 */
+	for _, location := range locations {
+		position := encodePosition(location)
+		positions = append(positions, position)
+	}
+
+	/*
+	 * Close ring if needed.
+	 */
+	if closed && (numLocations > 0) && (locations[0] != locations[numLocations-1]) {
+		position := encodePosition(locations[0])
+		positions = append(positions, position)
+	}
+
+	return positions
+}
+
+/*
+ * Encode the geometry of a feature.
+ */
+func (this *Feature) encodeGeometry() geometryStruct {
+	geometryType := this.geometryType
+	polygons := this.polygons
+	closed := (geometryType == TYPE_POLYGON) || (geometryType == TYPE_MULTI_POLYGON)
+	encoded := make([][][][]float64, len(polygons))
+
+	/*
+	 * Encode all lines of all polygons.
+	 */
+	for i, polygon := range polygons {
+		lines := make([][][]float64, len(polygon))
+
+		/*
+		 * Encode each line.
+		 */
+		for j, line := range polygon {
+			lines[j] = encodePositions(line, closed)
+		}
+
+		encoded[i] = lines
+	}
+
+	coords := interface{}(nil)
+
+	/*
+	 * Strip nesting levels not used by the geometry type.
+	 */
+	switch geometryType {
+	case TYPE_POINT:
+		coords = encoded[0][0][0]
+	case TYPE_MULTI_POINT, TYPE_LINE_STRING:
+		coords = encoded[0][0]
+	case TYPE_MULTI_LINE_STRING, TYPE_POLYGON:
+		coords = encoded[0]
+	default:
+		coords = encoded
+	}
+
+	/*
+	 * Create geometry.
+	 */
+	geometry := geometryStruct{
+		Type:        geometryType,
+		Coordinates: coords,
+	}
+
+	return geometry
+}
+
+/*
+ * Create a feature with a geometry.
+ */
+func createFeature(geometryType string, polygons [][][]coordinates.Geographic, properties map[string]interface{}) Feature {
+	props := make(map[string]interface{}, len(properties))
+
+	/*
+	 * Copy properties.
+	 */
+	for key, value := range properties {
+		props[key] = value
+	}
+
+	/*
+	 * Create feature.
+	 */
+	feature := Feature{
+		geometryType: geometryType,
+		polygons:     polygons,
+		properties:   props,
+	}
+
+	return feature
+}
+
+/*
+ * Create a feature with a point geometry.
+ */
+func CreatePointFeature(location coordinates.Geographic, properties map[string]interface{}) Feature {
+	line := []coordinates.Geographic{location}
+	polygon := [][]coordinates.Geographic{line}
+	polygons := [][][]coordinates.Geographic{polygon}
+	return createFeature(TYPE_POINT, polygons, properties)
+}
+
+/*
+ * Create a feature with a multi-point geometry.
+ */
+func CreateMultiPointFeature(locations []coordinates.Geographic, properties map[string]interface{}) Feature {
+	line := make([]coordinates.Geographic, len(locations))
+	copy(line, locations)
+	polygon := [][]coordinates.Geographic{line}
+	polygons := [][][]coordinates.Geographic{polygon}
+	return createFeature(TYPE_MULTI_POINT, polygons, properties)
+}
+
+/*
+ * Create a feature with a line string geometry.
+ */
+func CreateLineStringFeature(locations []coordinates.Geographic, properties map[string]interface{}) Feature {
+	line := make([]coordinates.Geographic, len(locations))
+	copy(line, locations)
+	polygon := [][]coordinates.Geographic{line}
+	polygons := [][][]coordinates.Geographic{polygon}
+	return createFeature(TYPE_LINE_STRING, polygons, properties)
+}
+
+/*
+ * Create a feature with a polygon geometry, consisting of an outer ring and
+ * optional holes.
+ *
+ * Rings are closed when they are written, so the first vertex need not be
+ * repeated.
+ */
+func CreatePolygonFeature(rings [][]coordinates.Geographic, properties map[string]interface{}) Feature {
+	polygon := make([][]coordinates.Geographic, len(rings))
+
+	/*
+	 * Copy each ring.
+	 */
+	for i, ring := range rings {
+		ringCopy := make([]coordinates.Geographic, len(ring))
+		copy(ringCopy, ring)
+		polygon[i] = ringCopy
+	}
+
+	polygons := [][][]coordinates.Geographic{polygon}
+	return createFeature(TYPE_POLYGON, polygons, properties)
+}
+
+/*
+ * Convert points in data space to geographic locations.
+ *
+ * If no projection is given, x and y coordinates are used as longitude and
+ * latitude in degrees.
+ */
+func unproject(proj projection.Projection, points []coordinates.Cartesian) ([]coordinates.Geographic, error) {
+	locations := make([]coordinates.Geographic, len(points))
+
+	/*
+	 * Use inverse projection if available.
+	 */
+	if proj != nil {
+		err := proj.Inverse(locations, points)
+		return locations, err
+	} else {
+
+		/*
+		 * Use coordinates as they are.
+		 */
+		for i, point := range points {
+			locations[i] = coordinates.CreateGeographicDegrees(point.X(), point.Y())
+		}
+
+		return locations, nil
+	}
+
+}
+
+/*
+ * Create features from contours extracted from a scene, e. g. to display
+ * iso-density lines on a web map.
+ *
+ * Closed contours become polygons, open ones line strings. The density level
+ * is stored in the "level" property. Points are converted to geographic
+ * locations using the inverse of the projection used for aggregation. If no
+ * projection is given, x and y coordinates are written as longitude and
+ * latitude in degrees.
+ */
+func FromContours(contours []scene.Contour, proj projection.Projection) ([]Feature, error) {
+	features := make([]Feature, 0, len(contours))
+
+	/*
+	 * Convert each contour.
+	 */
+	for i := range contours {
+		contour := &contours[i]
+		points := contour.Points()
+		locations, err := unproject(proj, points)
+
+		/*
+		 * Check if points could be converted.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		/*
+		 * Properties of the feature.
+		 */
+		properties := map[string]interface{}{
+			"level": contour.Level(),
+		}
+
+		feature := Feature{}
+
+		/*
+		 * Closed contours are rings of a polygon.
+		 */
+		if contour.Closed() {
+			rings := [][]coordinates.Geographic{locations}
+			feature = CreatePolygonFeature(rings, properties)
+		} else {
+			feature = CreateLineStringFeature(locations, properties)
+		}
+
+		features = append(features, feature)
+	}
+
+	return features, nil
+}
+
+/*
+ * Create point features from clusters detected in a scene, located at their
+ * centroids.
+ *
+ * The label, count, number of bins and area of each cluster are stored in
+ * properties of the same name. Projections are handled like for contours.
+ */
+func FromClusters(clusters []scene.Cluster, proj projection.Projection) ([]Feature, error) {
+	numClusters := len(clusters)
+	centroids := make([]coordinates.Cartesian, numClusters)
+
+	/*
+	 * Collect the centroids of all clusters.
+	 */
+	for i := range clusters {
+		centroids[i] = clusters[i].Centroid()
+	}
+
+	locations, err := unproject(proj, centroids)
+
+	/*
+	 * Check if centroids could be converted.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		features := make([]Feature, numClusters)
+
+		/*
+		 * Create a feature for each cluster.
+		 */
+		for i := range clusters {
+			cluster := &clusters[i]
+
+			/*
+			 * Properties of the feature.
+			 */
+			properties := map[string]interface{}{
+				"area":  cluster.Area(),
+				"bins":  cluster.Bins(),
+				"count": cluster.Count(),
+				"label": cluster.Label(),
+			}
+
+			features[i] = CreatePointFeature(locations[i], properties)
+		}
+
+		return features, nil
+	}
+
+}
+
+/*
+ * Write features as a GeoJSON feature collection.
+ *
+ * Locations are written in degrees.
+ */
+func Write(w io.Writer, features []Feature) error {
+	encoded := make([]featureStruct, len(features))
+
+	/*
+	 * Encode each feature.
+	 */
+	for i := range features {
+		feature := &features[i]
+		properties := feature.properties
+
+		/*
+		 * GeoJSON requires an object or null.
+		 */
+		if properties == nil {
+			properties = map[string]interface{}{}
+		}
+
+		/*
+		 * Create encoded feature.
+		 */
+		encoded[i] = featureStruct{
+			Type:       "Feature",
+			Geometry:   feature.encodeGeometry(),
+			Properties: properties,
+		}
+
+	}
+
+	/*
+	 * Create feature collection.
+	 */
+	collection := featureCollectionStruct{
+		Type:     "FeatureCollection",
+		Features: encoded,
+	}
+
+	encoder := json.NewEncoder(w)
+	return encoder.Encode(&collection)
+}
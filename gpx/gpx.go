@@ -0,0 +1,532 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"github.com/andrepxx/sydney/track"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Constants describing the GPX documents written.
+ */
+const (
+	GPX_VERSION   = "1.1"
+	GPX_CREATOR   = "sydney"
+	GPX_NAMESPACE = "http://www.topografix.com/GPX/1/1"
+)
+
+/*
+ * Data structure representing a track to be written, consisting of one or
+ * more segments of track points.
+ *
+ * Tracks are immutable.
+ */
+type Track struct {
+	description string
+	name        string
+	segments    [][]track.TrackPoint
+}
+
+/*
+ * Returns the description of this track.
+ */
+func (this *Track) Description() string {
+	return this.description
+}
+
+/*
+ * Returns the name of this track.
+ */
+func (this *Track) Name() string {
+	return this.name
+}
+
+/*
+ * Returns the number of segments of this track.
+ */
+func (this *Track) NumSegments() int {
+	return len(this.segments)
+}
+
+/*
+ * Returns a copy of the points of a segment of this track.
+ */
+func (this *Track) Segment(idx int) []track.TrackPoint {
+	segment := this.segments[idx]
+	result := make([]track.TrackPoint, len(segment))
+	copy(result, segment)
+	return result
+}
+
+/*
+ * Data structure representing a waypoint to be written.
+ *
+ * Waypoints are immutable.
+ */
+type Waypoint struct {
+	description string
+	location    coordinates.Geographic
+	name        string
+}
+
+/*
+ * Returns the description of this waypoint.
+ */
+func (this *Waypoint) Description() string {
+	return this.description
+}
+
+/*
+ * Returns the location of this waypoint.
+ */
+func (this *Waypoint) Location() coordinates.Geographic {
+	return this.location
+}
+
+/*
+ * Returns the name of this waypoint.
+ */
+func (this *Waypoint) Name() string {
+	return this.name
+}
+
+/*
+ * Data structure representing a GPX point as it is written.
+ */
+type pointStruct struct {
+	Latitude    string `xml:"lat,attr"`
+	Longitude   string `xml:"lon,attr"`
+	Elevation   string `xml:"ele,omitempty"`
+	Time        string `xml:"time,omitempty"`
+	Name        string `xml:"name,omitempty"`
+	Description string `xml:"desc,omitempty"`
+}
+
+/*
+ * Data structure representing a GPX track segment as it is written.
+ */
+type segmentStruct struct {
+	Points []pointStruct `xml:"trkpt"`
+}
+
+/*
+ * Data structure representing a GPX track as it is written.
+ */
+type trackStruct struct {
+	Name        string          `xml:"name,omitempty"`
+	Description string          `xml:"desc,omitempty"`
+	Segments    []segmentStruct `xml:"trkseg"`
+}
+
+/*
+ * Data structure representing a GPX document as it is written.
+ */
+type documentStruct struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Namespace string        `xml:"xmlns,attr"`
+	Waypoints []pointStruct `xml:"wpt"`
+	Tracks    []trackStruct `xml:"trk"`
+}
+
+/*
+ * Format a number as a decimal without exponent, as required by GPX.
+ */
+func formatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+/*
+ * Format an angle in degrees, rounded to nine decimal places, which is below
+ * a millimetre and hides rounding errors from the conversion to radians.
+ */
+func formatDegrees(value float64) string {
+	s := strconv.FormatFloat(value, 'f', 9, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+
+	/*
+	 * Avoid writing negative zero.
+	 */
+	if s == "-0" {
+		s = "0"
+	}
+
+	return s
+}
+
+/*
+ * Encode a location as a GPX point.
+ */
+func encodeLocation(location coordinates.Geographic) pointStruct {
+
+	/*
+	 * Create point.
+	 */
+	point := pointStruct{
+		Latitude:  formatDegrees(location.LatitudeDegrees()),
+		Longitude: formatDegrees(location.LongitudeDegrees()),
+	}
+
+	return point
+}
+
+/*
+ * Encode a track point as a GPX point, including its elevation and time if
+ * they are known.
+ */
+func encodeTrackPoint(trackPoint *track.TrackPoint) pointStruct {
+	point := encodeLocation(trackPoint.Location())
+
+	/*
+	 * Write elevation if known.
+	 */
+	if trackPoint.HasElevation() {
+		point.Elevation = formatDecimal(trackPoint.Elevation())
+	}
+
+	timestamp := trackPoint.Time()
+
+	/*
+	 * Write time if known.
+	 */
+	if !timestamp.IsZero() {
+		point.Time = timestamp.UTC().Format(time.RFC3339Nano)
+	}
+
+	return point
+}
+
+/*
+ * Convert points in data space to track points without time and elevation.
+ *
+ * If no projection is given, x and y coordinates are used as longitude and
+ * latitude in degrees.
+ */
+func unproject(proj projection.Projection, points []coordinates.Cartesian) ([]track.TrackPoint, error) {
+	numPoints := len(points)
+	locations := make([]coordinates.Geographic, numPoints)
+
+	/*
+	 * Use inverse projection if available.
+	 */
+	if proj != nil {
+		err := proj.Inverse(locations, points)
+
+		/*
+		 * Check if points could be converted.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+	} else {
+
+		/*
+		 * Use coordinates as they are.
+		 */
+		for i, point := range points {
+			locations[i] = coordinates.CreateGeographicDegrees(point.X(), point.Y())
+		}
+
+	}
+
+	trackPoints := make([]track.TrackPoint, numPoints)
+	nan := math.NaN()
+
+	/*
+	 * Create a track point for each location.
+	 */
+	for i, location := range locations {
+		trackPoints[i] = track.CreateTrackPoint(location, time.Time{}, nan, nan)
+	}
+
+	return trackPoints, nil
+}
+
+/*
+ * Create a track consisting of segments of track points.
+ *
+ * The name and description are optional.
+ */
+func CreateTrack(name string, description string, segments [][]track.TrackPoint) Track {
+	copied := make([][]track.TrackPoint, len(segments))
+
+	/*
+	 * Copy each segment.
+	 */
+	for i, segment := range segments {
+		segmentCopy := make([]track.TrackPoint, len(segment))
+		copy(segmentCopy, segment)
+		copied[i] = segmentCopy
+	}
+
+	/*
+	 * Create track.
+	 */
+	t := Track{
+		description: description,
+		name:        name,
+		segments:    copied,
+	}
+
+	return t
+}
+
+/*
+ * Create a waypoint at a location.
+ *
+ * The name and description are optional.
+ */
+func CreateWaypoint(location coordinates.Geographic, name string, description string) Waypoint {
+
+	/*
+	 * Create waypoint.
+	 */
+	waypoint := Waypoint{
+		description: description,
+		location:    location,
+		name:        name,
+	}
+
+	return waypoint
+}
+
+/*
+ * Create a track from a recorded track, e. g. one resampled or sliced in
+ * time, keeping the time and elevation of its points.
+ */
+func FromTrack(t track.Track, name string) Track {
+	points := t.Points()
+	segments := [][]track.TrackPoint{points}
+	return CreateTrack(name, "", segments)
+}
+
+/*
+ * Create a track from a path in data space, e. g. a trace simplified using
+ * coordinates.Simplify.
+ *
+ * Points are converted to geographic locations using the inverse of the
+ * projection used for aggregation. If no projection is given, x and y
+ * coordinates are written as longitude and latitude in degrees.
+ */
+func FromPath(path []coordinates.Cartesian, name string, proj projection.Projection) (Track, error) {
+	points, err := unproject(proj, path)
+
+	/*
+	 * Check if points could be converted.
+	 */
+	if err != nil {
+		return Track{}, err
+	} else {
+		segments := [][]track.TrackPoint{points}
+		return CreateTrack(name, "", segments), nil
+	}
+
+}
+
+/*
+ * Create tracks from contours extracted from a scene, e. g. the outlines of
+ * dense regions.
+ *
+ * Each contour becomes a track, whose name holds its density level. Closed
+ * contours end at their first point. Projections are handled like for
+ * paths.
+ */
+func FromContours(contours []scene.Contour, proj projection.Projection) ([]Track, error) {
+	tracks := make([]Track, 0, len(contours))
+
+	/*
+	 * Convert each contour.
+	 */
+	for i := range contours {
+		contour := &contours[i]
+		points := contour.Points()
+		numPoints := len(points)
+
+		/*
+		 * Close ring if needed.
+		 */
+		if contour.Closed() && (numPoints > 0) && (points[0] != points[numPoints-1]) {
+			points = append(points, points[0])
+		}
+
+		trackPoints, err := unproject(proj, points)
+
+		/*
+		 * Check if points could be converted.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		level := formatDecimal(contour.Level())
+		name := fmt.Sprintf("Contour %s", level)
+		segments := [][]track.TrackPoint{trackPoints}
+		t := CreateTrack(name, "", segments)
+		tracks = append(tracks, t)
+	}
+
+	return tracks, nil
+}
+
+/*
+ * Create waypoints from clusters detected in a scene, located at their
+ * centroids.
+ *
+ * The name of each waypoint holds the label of the cluster, while its
+ * description holds the count, number of bins and area. Projections are
+ * handled like for paths.
+ */
+func FromClusters(clusters []scene.Cluster, proj projection.Projection) ([]Waypoint, error) {
+	numClusters := len(clusters)
+	centroids := make([]coordinates.Cartesian, numClusters)
+
+	/*
+	 * Collect the centroids of all clusters.
+	 */
+	for i := range clusters {
+		centroids[i] = clusters[i].Centroid()
+	}
+
+	points, err := unproject(proj, centroids)
+
+	/*
+	 * Check if centroids could be converted.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		waypoints := make([]Waypoint, numClusters)
+
+		/*
+		 * Create a waypoint for each cluster.
+		 */
+		for i := range clusters {
+			cluster := &clusters[i]
+			name := fmt.Sprintf("Cluster %d", cluster.Label())
+			area := formatDecimal(cluster.Area())
+			description := fmt.Sprintf("Count: %d, bins: %d, area: %s", cluster.Count(), cluster.Bins(), area)
+			waypoints[i] = CreateWaypoint(points[i].Location(), name, description)
+		}
+
+		return waypoints, nil
+	}
+
+}
+
+/*
+ * Write waypoints and tracks as a GPX 1.1 document.
+ *
+ * Locations are written in degrees. Points with an unknown location, e. g.
+ * because they could not be projected, are skipped.
+ */
+func Write(w io.Writer, waypoints []Waypoint, tracks []Track) error {
+	encodedWaypoints := make([]pointStruct, 0, len(waypoints))
+
+	/*
+	 * Encode each waypoint.
+	 */
+	for i := range waypoints {
+		waypoint := &waypoints[i]
+		location := waypoint.location
+
+		/*
+		 * Skip waypoints without a valid location.
+		 */
+		if !math.IsNaN(location.Longitude()) && !math.IsNaN(location.Latitude()) {
+			point := encodeLocation(location)
+			point.Name = waypoint.name
+			point.Description = waypoint.description
+			encodedWaypoints = append(encodedWaypoints, point)
+		}
+
+	}
+
+	encodedTracks := make([]trackStruct, len(tracks))
+
+	/*
+	 * Encode each track.
+	 */
+	for i := range tracks {
+		t := &tracks[i]
+		segments := make([]segmentStruct, len(t.segments))
+
+		/*
+		 * Encode each segment.
+		 */
+		for j, segment := range t.segments {
+			points := make([]pointStruct, 0, len(segment))
+
+			/*
+			 * Encode each point.
+			 */
+			for k := range segment {
+				trackPoint := &segment[k]
+				location := trackPoint.Location()
+
+				/*
+				 * Skip points without a valid location.
+				 */
+				if !math.IsNaN(location.Longitude()) && !math.IsNaN(location.Latitude()) {
+					point := encodeTrackPoint(trackPoint)
+					points = append(points, point)
+				}
+
+			}
+
+			segments[j] = segmentStruct{
+				Points: points,
+			}
+
+		}
+
+		encodedTracks[i] = trackStruct{
+			Name:        t.name,
+			Description: t.description,
+			Segments:    segments,
+		}
+
+	}
+
+	/*
+	 * Create document.
+	 */
+	document := documentStruct{
+		Version:   GPX_VERSION,
+		Creator:   GPX_CREATOR,
+		Namespace: GPX_NAMESPACE,
+		Waypoints: encodedWaypoints,
+		Tracks:    encodedTracks,
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+
+	/*
+	 * Write document after XML declaration.
+	 */
+	if err == nil {
+		encoder := xml.NewEncoder(w)
+		encoder.Indent("", "\t")
+		err = encoder.Encode(&document)
+	}
+
+	/*
+	 * Check if document could be written.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to write GPX document: %s", err.Error())
+	} else {
+		return nil
+	}
+
+}
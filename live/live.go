@@ -0,0 +1,427 @@
+package live
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"strconv"
+)
+
+/*
+ * The largest number of points aggregated at once.
+ */
+const (
+	BATCH_SIZE = 4096
+)
+
+/*
+ * Data structure representing the configuration of a feed.
+ */
+type configStruct struct {
+	projection projection.Projection
+	x          string
+	y          string
+}
+
+/*
+ * A configuration option for a feed.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Select the members of NDJSON objects holding the x- and y-coordinates (or
+ * longitude and latitude) by name.
+ */
+func WithCoordinates(x string, y string) Option {
+
+	/*
+	 * Set coordinate members.
+	 */
+	return func(config *configStruct) {
+		config.x = x
+		config.y = y
+	}
+
+}
+
+/*
+ * Project longitudes and latitudes (in degrees) before aggregating them.
+ */
+func WithProjection(proj projection.Projection) Option {
+
+	/*
+	 * Set projection.
+	 */
+	return func(config *configStruct) {
+		config.projection = proj
+	}
+
+}
+
+/*
+ * Apply options to the default configuration.
+ */
+func configure(options []Option) configStruct {
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		x: "lon",
+		y: "lat",
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	return config
+}
+
+/*
+ * Data structure collecting points before they are aggregated.
+ */
+type batchStruct struct {
+	locations  []coordinates.Geographic
+	points     []coordinates.Cartesian
+	projection projection.Projection
+	scene      scene.LiveScene
+}
+
+/*
+ * Add a location, given as longitude and latitude in degrees.
+ */
+func (this *batchStruct) add(longitude float64, latitude float64) {
+
+	/*
+	 * Collect locations if they need to be projected.
+	 */
+	if this.projection != nil {
+		location := coordinates.CreateGeographicDegrees(longitude, latitude)
+		this.locations = append(this.locations, location)
+	} else {
+		point := coordinates.CreateCartesian(longitude, latitude)
+		this.points = append(this.points, point)
+	}
+
+}
+
+/*
+ * Returns the number of collected points.
+ */
+func (this *batchStruct) size() int {
+	return len(this.locations) + len(this.points)
+}
+
+/*
+ * Aggregate all collected points into the scene.
+ */
+func (this *batchStruct) flush() error {
+	proj := this.projection
+
+	/*
+	 * Project locations if needed.
+	 */
+	if proj != nil {
+		locations := this.locations
+		points := this.points[:0]
+
+		/*
+		 * Make room for the projected points.
+		 */
+		for range locations {
+			points = append(points, coordinates.Cartesian{})
+		}
+
+		err := proj.Forward(points, locations)
+
+		/*
+		 * Points which could not be projected are NaN and are not
+		 * aggregated, so only other errors are fatal.
+		 */
+		if _, ok := err.(projection.BatchError); (err != nil) && !ok {
+			return err
+		}
+
+		this.points = points
+	}
+
+	/*
+	 * Only lock the scene if there is something to aggregate.
+	 */
+	if len(this.points) > 0 {
+		this.scene.Aggregate(this.points)
+	}
+
+	this.locations = this.locations[:0]
+	this.points = this.points[:0]
+	return nil
+}
+
+/*
+ * Parse a member of an object as a number, which may also be given as a
+ * string.
+ */
+func parseNumber(raw json.RawMessage) (float64, error) {
+	value := float64(0.0)
+	err := json.Unmarshal(raw, &value)
+
+	/*
+	 * Try string if member is not a number.
+	 */
+	if err != nil {
+		text := ""
+		err = json.Unmarshal(raw, &text)
+
+		/*
+		 * Check if string could be decoded.
+		 */
+		if err == nil {
+			value, err = strconv.ParseFloat(text, 64)
+		}
+
+	}
+
+	return value, err
+}
+
+/*
+ * Data structure representing a line read from a stream.
+ */
+type lineStruct struct {
+	data []byte
+	err  error
+}
+
+/*
+ * Read lines from a stream and send them to a channel until reading fails
+ * or stop is closed.
+ *
+ * The last line sent carries the error which ended reading, e. g. io.EOF.
+ */
+func readLines(r io.Reader, lines chan<- lineStruct, stop <-chan struct{}) {
+	rd := bufio.NewReader(decompress.Auto(r))
+
+	/*
+	 * Read lines until the stream ends.
+	 */
+	for {
+		data, err := rd.ReadBytes('\n')
+
+		/*
+		 * Create line.
+		 */
+		line := lineStruct{
+			data: data,
+			err:  err,
+		}
+
+		/*
+		 * Pass the line on unless reading was stopped.
+		 */
+		select {
+		case <-stop:
+			return
+		case lines <- line:
+		}
+
+		/*
+		 * Stop after the first error.
+		 */
+		if err != nil {
+			return
+		}
+
+	}
+
+}
+
+/*
+ * Continuously read newline-delimited JSON (NDJSON) objects, e. g. position
+ * updates received from an MQTT broker or a WebSocket, and aggregate their
+ * locations into a live scene until the stream ends or the context is
+ * cancelled.
+ *
+ * By default, the members "lon" and "lat" hold longitude and latitude.
+ * Lines which are empty, are no objects or lack valid coordinates are
+ * skipped. Points are aggregated as soon as no further lines are queued, so
+ * that they appear in the scene without delay. Returns nil at the end of the
+ * stream.
+ *
+ * The stream is read in a separate goroutine, so that cancelling the context
+ * returns immediately, even while waiting for the next line. Since a blocked
+ * read cannot be interrupted, that goroutine only ends once the read returns,
+ * so close the stream after cancelling to release it.
+ */
+func ReadNDJSON(ctx context.Context, r io.Reader, scn scene.LiveScene, options ...Option) error {
+	config := configure(options)
+	done := ctx.Done()
+	lines := make(chan lineStruct, BATCH_SIZE)
+	stop := make(chan struct{})
+	defer close(stop)
+	go readLines(r, lines, stop)
+
+	/*
+	 * Create batch.
+	 */
+	batch := batchStruct{
+		projection: config.projection,
+		scene:      scn,
+	}
+
+	/*
+	 * Read lines until the stream ends.
+	 */
+	for {
+		line := lineStruct{}
+
+		/*
+		 * Wait for the next line.
+		 */
+		select {
+		case <-done:
+			return ctx.Err()
+		case line = <-lines:
+		}
+
+		errRead := line.err
+		object := map[string]json.RawMessage{}
+		err := json.Unmarshal(line.data, &object)
+
+		/*
+		 * Skip lines which are no objects.
+		 */
+		if err == nil {
+			x, errX := parseNumber(object[config.x])
+			y, errY := parseNumber(object[config.y])
+
+			/*
+			 * Skip objects without valid coordinates.
+			 */
+			if (errX == nil) && (errY == nil) {
+				batch.add(x, y)
+			}
+
+		}
+
+		/*
+		 * Aggregate points once no further lines are queued or the
+		 * batch is full.
+		 */
+		if (errRead != nil) || (len(lines) == 0) || (batch.size() >= BATCH_SIZE) {
+			err = batch.flush()
+
+			/*
+			 * Check if points could be aggregated.
+			 */
+			if err != nil {
+				return err
+			}
+
+		}
+
+		/*
+		 * Check if the stream ended or reading was cancelled.
+		 */
+		if errRead == io.EOF {
+			return nil
+		} else if errRead != nil {
+			return fmt.Errorf("Failed to read stream: %s", errRead.Error())
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+	}
+
+}
+
+/*
+ * Continuously receive locations from a channel, e. g. positions decoded by
+ * a client for a message broker, and aggregate them into a live scene until
+ * the channel is closed or the context is cancelled.
+ *
+ * Locations which are already queued in the channel are aggregated in
+ * batches. Returns nil once the channel is closed.
+ */
+func ReadChannel(ctx context.Context, locations <-chan coordinates.Geographic, scn scene.LiveScene, options ...Option) error {
+	config := configure(options)
+	done := ctx.Done()
+
+	/*
+	 * Create batch.
+	 */
+	batch := batchStruct{
+		projection: config.projection,
+		scene:      scn,
+	}
+
+	/*
+	 * Receive locations until the channel is closed.
+	 */
+	for {
+
+		/*
+		 * Wait for the next location.
+		 */
+		select {
+		case <-done:
+			return ctx.Err()
+		case location, ok := <-locations:
+
+			/*
+			 * Check if channel is closed.
+			 */
+			if !ok {
+				return nil
+			}
+
+			batch.add(location.LongitudeDegrees(), location.LatitudeDegrees())
+			open := true
+
+			/*
+			 * Collect locations which are already queued.
+			 */
+			for open && (batch.size() < BATCH_SIZE) && (len(locations) > 0) {
+				location, open = <-locations
+
+				/*
+				 * Check if channel is still open.
+				 */
+				if open {
+					batch.add(location.LongitudeDegrees(), location.LatitudeDegrees())
+				}
+
+			}
+
+			err := batch.flush()
+
+			/*
+			 * Check if points could be aggregated.
+			 */
+			if err != nil {
+				return err
+			} else if !open {
+				return nil
+			}
+
+		}
+
+	}
+
+}
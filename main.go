@@ -4,11 +4,9 @@ import (
 	"fmt"
 	"github.com/andrepxx/sydney/color"
 	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/output"
 	"github.com/andrepxx/sydney/scene"
-	"image"
 	imagecolor "image/color"
-	"image/draw"
-	"image/png"
 	"math/rand"
 	"os"
 )
@@ -38,6 +36,18 @@ func main() {
 	}
 
 	scn.Spread(1)
+
+	/*
+	 * The background color.
+	 */
+	bg := imagecolor.NRGBA{
+		R: 0,
+		G: 0,
+		B: 0,
+		A: 255,
+	}
+
+	scn.SetBackground(bg)
 	mapping := color.DefaultMapping()
 	img, err := scn.Render(mapping)
 
@@ -48,30 +58,6 @@ func main() {
 		msg := err.Error()
 		fmt.Printf("Something went wrong: %s\n", msg)
 	} else {
-		dim := image.Rect(0, 0, 800, 800)
-		target := image.NewNRGBA(dim)
-
-		/*
-		 * The background color.
-		 */
-		c := imagecolor.NRGBA{
-			R: 0,
-			G: 0,
-			B: 0,
-			A: 255,
-		}
-
-		uniform := image.NewUniform(c)
-		draw.Draw(target, dim, uniform, image.ZP, draw.Over)
-		draw.Draw(target, dim, img, image.ZP, draw.Over)
-
-		/*
-		 * The PNG encoder.
-		 */
-		enc := png.Encoder{
-			CompressionLevel: png.BestCompression,
-		}
-
 		fd, err := os.Create("output.png")
 
 		/*
@@ -81,7 +67,7 @@ func main() {
 			msg := err.Error()
 			fmt.Printf("Error creating output file: %s", msg)
 		} else {
-			enc.Encode(fd, target)
+			output.EncodePNG(fd, img, output.WithBestCompression())
 			fd.Close()
 		}
 
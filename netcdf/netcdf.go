@@ -0,0 +1,446 @@
+package netcdf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"math"
+	"strings"
+)
+
+/*
+ * Constants of the NetCDF classic format with 64-bit offsets.
+ */
+const (
+	MAGIC        = "CDF\x02"
+	NC_DIMENSION = 0x0a
+	NC_VARIABLE  = 0x0b
+	NC_ATTRIBUTE = 0x0c
+	NC_CHAR      = 2
+	NC_DOUBLE    = 6
+	MAX_VAR_SIZE = 0xfffffffc
+)
+
+/*
+ * Indices of the dimensions written.
+ */
+const (
+	DIMENSION_Y = 0
+	DIMENSION_X = 1
+)
+
+/*
+ * Data structure representing a text attribute.
+ */
+type attributeStruct struct {
+	name  string
+	value string
+}
+
+/*
+ * Data structure representing a variable.
+ */
+type variableStruct struct {
+	attributes []attributeStruct
+	dimensions []uint32
+	name       string
+	values     []float64
+}
+
+/*
+ * Data structure representing the configuration of a writer.
+ */
+type configStruct struct {
+	attributes []attributeStruct
+	units      string
+	variables  []variableStruct
+}
+
+/*
+ * A configuration option for a writer.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Add a global text attribute, e. g. "title" or "source".
+ */
+func WithAttribute(name string, value string) Option {
+
+	/*
+	 * Add attribute.
+	 */
+	return func(config *configStruct) {
+
+		/*
+		 * Create attribute.
+		 */
+		attribute := attributeStruct{
+			name:  name,
+			value: value,
+		}
+
+		config.attributes = append(config.attributes, attribute)
+	}
+
+}
+
+/*
+ * Set the units of the coordinate variables, e. g. "m" for scenes aggregated
+ * using the Web Mercator projection.
+ */
+func WithCoordinateUnits(units string) Option {
+
+	/*
+	 * Set units.
+	 */
+	return func(config *configStruct) {
+		config.units = units
+	}
+
+}
+
+/*
+ * Write an additional grid of values alongside the counts, e. g. the mean
+ * speed per bin, which must hold one value per bin of the scene in the same
+ * order as the counts returned by scn.Counts(). Use NaN for bins without a
+ * value.
+ */
+func WithVariable(name string, values []float64) Option {
+
+	/*
+	 * Add variable.
+	 */
+	return func(config *configStruct) {
+
+		/*
+		 * Create variable.
+		 */
+		variable := variableStruct{
+			dimensions: []uint32{DIMENSION_Y, DIMENSION_X},
+			name:       name,
+			values:     values,
+		}
+
+		config.variables = append(config.variables, variable)
+	}
+
+}
+
+/*
+ * Data structure encoding the header of a file.
+ */
+type headerStruct struct {
+	buf bytes.Buffer
+}
+
+/*
+ * Encode a 32-bit integer.
+ */
+func (this *headerStruct) putInt32(value uint32) {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, value)
+	this.buf.Write(b)
+}
+
+/*
+ * Encode a 64-bit integer.
+ */
+func (this *headerStruct) putInt64(value uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, value)
+	this.buf.Write(b)
+}
+
+/*
+ * Encode a string, padded to a multiple of four bytes.
+ */
+func (this *headerStruct) putString(value string) {
+	length := len(value)
+	this.putInt32(uint32(length))
+	this.buf.WriteString(value)
+	padding := (4 - (length % 4)) % 4
+	this.buf.Write(make([]byte, padding))
+}
+
+/*
+ * Encode a list of text attributes.
+ */
+func (this *headerStruct) attributes(attributes []attributeStruct) {
+
+	/*
+	 * An empty list is encoded as absent.
+	 */
+	if len(attributes) == 0 {
+		this.putInt32(0)
+		this.putInt32(0)
+	} else {
+		this.putInt32(NC_ATTRIBUTE)
+		this.putInt32(uint32(len(attributes)))
+
+		/*
+		 * Encode each attribute.
+		 */
+		for _, attribute := range attributes {
+			this.putString(attribute.name)
+			this.putInt32(NC_CHAR)
+			this.putString(attribute.value)
+		}
+
+	}
+
+}
+
+/*
+ * Encode the header of a file with the given dimensions and variables,
+ * whose data starts at the given offsets.
+ */
+func encodeHeader(width uint32, height uint32, attributes []attributeStruct, variables []variableStruct, begins []uint64) []byte {
+	header := headerStruct{}
+	header.buf.WriteString(MAGIC)
+	header.putInt32(0)
+	header.putInt32(NC_DIMENSION)
+	header.putInt32(2)
+	header.putString("y")
+	header.putInt32(height)
+	header.putString("x")
+	header.putInt32(width)
+	header.attributes(attributes)
+	header.putInt32(NC_VARIABLE)
+	header.putInt32(uint32(len(variables)))
+
+	/*
+	 * Encode each variable.
+	 */
+	for i, variable := range variables {
+		header.putString(variable.name)
+		header.putInt32(uint32(len(variable.dimensions)))
+
+		/*
+		 * Encode the dimensions of the variable.
+		 */
+		for _, dimension := range variable.dimensions {
+			header.putInt32(dimension)
+		}
+
+		header.attributes(variable.attributes)
+		header.putInt32(NC_DOUBLE)
+		size := uint64(8 * len(variable.values))
+		header.putInt32(uint32(size))
+		header.putInt64(begins[i])
+	}
+
+	return header.buf.Bytes()
+}
+
+/*
+ * Check if a name may be used for a variable or attribute.
+ */
+func validName(name string) bool {
+	return (name != "") && !strings.ContainsAny(name, "/\x00") && (strings.TrimSpace(name) == name)
+}
+
+/*
+ * Write the counts of a scene in the NetCDF classic format (with 64-bit
+ * offsets), so that they can be analyzed using e. g. xarray or
+ * NCDatasets.jl.
+ *
+ * The file holds the dimensions "y" and "x", the coordinate variables "y"
+ * and "x" holding the centers of the rows (from top to bottom) and columns
+ * of bins, and the variable "count" holding the counts. Additional grids
+ * are written using WithVariable. All variables are stored as
+ * double-precision floating point numbers, which represent all counts
+ * produced by aggregation exactly.
+ */
+func Write(w io.Writer, scn scene.Scene, options ...Option) error {
+	config := configStruct{}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	width, height := scn.Dimensions()
+	bounds := scn.Bounds()
+	min := bounds.Min()
+	max := bounds.Max()
+	widthFloat := float64(width)
+	heightFloat := float64(height)
+	sizeX := (max.X() - min.X()) / widthFloat
+	sizeY := (max.Y() - min.Y()) / heightFloat
+	xs := make([]float64, width)
+	ys := make([]float64, height)
+
+	/*
+	 * Calculate the centers of all columns.
+	 */
+	for i := range xs {
+		xs[i] = min.X() + ((float64(i) + 0.5) * sizeX)
+	}
+
+	/*
+	 * Calculate the centers of all rows.
+	 */
+	for i := range ys {
+		ys[i] = max.Y() - ((float64(i) + 0.5) * sizeY)
+	}
+
+	counts := scn.Counts()
+	values := make([]float64, len(counts))
+
+	/*
+	 * Convert counts to floating-point values.
+	 */
+	for i, count := range counts {
+		values[i] = float64(count)
+	}
+
+	attributesX := []attributeStruct{attributeStruct{name: "long_name", value: "x-coordinate of bin center"}}
+	attributesY := []attributeStruct{attributeStruct{name: "long_name", value: "y-coordinate of bin center"}}
+
+	/*
+	 * Add units to coordinate variables if known.
+	 */
+	if config.units != "" {
+		attributesX = append(attributesX, attributeStruct{name: "units", value: config.units})
+		attributesY = append(attributesY, attributeStruct{name: "units", value: config.units})
+	}
+
+	/*
+	 * The coordinate and count variables.
+	 */
+	variables := []variableStruct{
+		variableStruct{
+			attributes: attributesY,
+			dimensions: []uint32{DIMENSION_Y},
+			name:       "y",
+			values:     ys,
+		},
+		variableStruct{
+			attributes: attributesX,
+			dimensions: []uint32{DIMENSION_X},
+			name:       "x",
+			values:     xs,
+		},
+		variableStruct{
+			attributes: []attributeStruct{attributeStruct{name: "long_name", value: "number of points per bin"}},
+			dimensions: []uint32{DIMENSION_Y, DIMENSION_X},
+			name:       "count",
+			values:     values,
+		},
+	}
+
+	variables = append(variables, config.variables...)
+	names := map[string]bool{}
+
+	/*
+	 * Verify all variables.
+	 */
+	for _, variable := range variables {
+		name := variable.name
+		numValues := uint64(len(variable.values))
+		expected := uint64(1)
+
+		/*
+		 * Calculate the expected number of values.
+		 */
+		for _, dimension := range variable.dimensions {
+
+			/*
+			 * Check which dimension is used.
+			 */
+			if dimension == DIMENSION_X {
+				expected *= uint64(width)
+			} else {
+				expected *= uint64(height)
+			}
+
+		}
+
+		/*
+		 * Check name and size of variable.
+		 */
+		if !validName(name) {
+			return fmt.Errorf("Invalid variable name: '%s'", name)
+		} else if names[name] {
+			return fmt.Errorf("Duplicate variable name: '%s'", name)
+		} else if numValues != expected {
+			return fmt.Errorf("Variable '%s' has %d values, but expected %d.", name, numValues, expected)
+		} else if (8 * numValues) > MAX_VAR_SIZE {
+			return fmt.Errorf("Variable '%s' is too large for the NetCDF classic format.", name)
+		}
+
+		names[name] = true
+	}
+
+	/*
+	 * Verify all attributes.
+	 */
+	for _, attribute := range config.attributes {
+
+		/*
+		 * Check name of attribute.
+		 */
+		if !validName(attribute.name) {
+			return fmt.Errorf("Invalid attribute name: '%s'", attribute.name)
+		}
+
+	}
+
+	numVariables := len(variables)
+	begins := make([]uint64, numVariables)
+	header := encodeHeader(width, height, config.attributes, variables, begins)
+	offset := uint64(len(header))
+
+	/*
+	 * Data of each variable follows the header in order.
+	 */
+	for i, variable := range variables {
+		begins[i] = offset
+		offset += uint64(8 * len(variable.values))
+	}
+
+	header = encodeHeader(width, height, config.attributes, variables, begins)
+	buffered := bufio.NewWriter(w)
+	buffered.Write(header)
+	b := make([]byte, 8)
+
+	/*
+	 * Write the values of each variable.
+	 */
+	for _, variable := range variables {
+
+		/*
+		 * Write each value.
+		 */
+		for _, value := range variable.values {
+			bits := math.Float64bits(value)
+			binary.BigEndian.PutUint64(b, bits)
+			buffered.Write(b)
+		}
+
+	}
+
+	err := buffered.Flush()
+
+	/*
+	 * Check if file could be written.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to write NetCDF file: %s", err.Error())
+	} else {
+		return nil
+	}
+
+}
@@ -0,0 +1,357 @@
+package netcdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/scene"
+	"math"
+	"testing"
+)
+
+/*
+ * Data structure representing a variable read back from a file.
+ */
+type decodedVariableStruct struct {
+	attributes map[string]string
+	dimensions []uint32
+	values     []float64
+}
+
+/*
+ * Data structure representing a file read back.
+ */
+type decodedFileStruct struct {
+	attributes map[string]string
+	dimensions []uint32
+	names      []string
+	variables  map[string]decodedVariableStruct
+}
+
+/*
+ * Data structure decoding a file.
+ */
+type decoderStruct struct {
+	data   []byte
+	err    error
+	offset int
+}
+
+/*
+ * Decode a 32-bit integer.
+ */
+func (this *decoderStruct) int32() uint32 {
+
+	/*
+	 * Check if enough data is left.
+	 */
+	if this.offset+4 > len(this.data) {
+		this.err = fmt.Errorf("%s", "Unexpected end of header.")
+		return 0
+	}
+
+	value := binary.BigEndian.Uint32(this.data[this.offset:])
+	this.offset += 4
+	return value
+}
+
+/*
+ * Decode a 64-bit integer.
+ */
+func (this *decoderStruct) int64() uint64 {
+	high := uint64(this.int32())
+	low := uint64(this.int32())
+	return (high << 32) | low
+}
+
+/*
+ * Decode a string padded to a multiple of four bytes.
+ */
+func (this *decoderStruct) string() string {
+	length := int(this.int32())
+	padded := length + ((4 - (length % 4)) % 4)
+
+	/*
+	 * Check if enough data is left.
+	 */
+	if (this.err != nil) || (this.offset+padded > len(this.data)) {
+		this.err = fmt.Errorf("%s", "Unexpected end of header.")
+		return ""
+	}
+
+	value := string(this.data[this.offset : this.offset+length])
+	this.offset += padded
+	return value
+}
+
+/*
+ * Decode a list of text attributes.
+ */
+func (this *decoderStruct) attributes() map[string]string {
+	tag := this.int32()
+	count := this.int32()
+	attributes := map[string]string{}
+
+	/*
+	 * Check if the list is present.
+	 */
+	if (tag != NC_ATTRIBUTE) && ((tag != 0) || (count != 0)) {
+		this.err = fmt.Errorf("Invalid attribute list tag: %d", tag)
+	} else {
+
+		/*
+		 * Decode each attribute.
+		 */
+		for i := uint32(0); (i < count) && (this.err == nil); i++ {
+			name := this.string()
+			kind := this.int32()
+
+			/*
+			 * Only text attributes are written.
+			 */
+			if kind != NC_CHAR {
+				this.err = fmt.Errorf("Attribute '%s' has type %d.", name, kind)
+			} else {
+				attributes[name] = this.string()
+			}
+
+		}
+
+	}
+
+	return attributes
+}
+
+/*
+ * Decode a file written by Write.
+ */
+func decode(data []byte) (decodedFileStruct, error) {
+	dec := decoderStruct{data: data}
+	file := decodedFileStruct{variables: map[string]decodedVariableStruct{}}
+
+	/*
+	 * Check the magic number.
+	 */
+	if !bytes.HasPrefix(data, []byte(MAGIC)) {
+		return file, fmt.Errorf("%s", "Invalid magic number.")
+	}
+
+	dec.offset = len(MAGIC)
+	dec.int32()
+
+	/*
+	 * Check the dimension list.
+	 */
+	if tag := dec.int32(); tag != NC_DIMENSION {
+		return file, fmt.Errorf("Invalid dimension list tag: %d", tag)
+	}
+
+	numDimensions := dec.int32()
+
+	/*
+	 * Decode each dimension.
+	 */
+	for i := uint32(0); i < numDimensions; i++ {
+		dec.string()
+		file.dimensions = append(file.dimensions, dec.int32())
+	}
+
+	file.attributes = dec.attributes()
+
+	/*
+	 * Check the variable list.
+	 */
+	if tag := dec.int32(); tag != NC_VARIABLE {
+		return file, fmt.Errorf("Invalid variable list tag: %d", tag)
+	}
+
+	numVariables := dec.int32()
+
+	/*
+	 * Decode each variable.
+	 */
+	for i := uint32(0); (i < numVariables) && (dec.err == nil); i++ {
+		name := dec.string()
+		numDims := dec.int32()
+		variable := decodedVariableStruct{}
+
+		/*
+		 * Decode the dimensions of the variable.
+		 */
+		for j := uint32(0); j < numDims; j++ {
+			variable.dimensions = append(variable.dimensions, dec.int32())
+		}
+
+		variable.attributes = dec.attributes()
+		kind := dec.int32()
+		size := int(dec.int32())
+		begin := int(dec.int64())
+
+		/*
+		 * Check the type and location of the data.
+		 */
+		if kind != NC_DOUBLE {
+			return file, fmt.Errorf("Variable '%s' has type %d.", name, kind)
+		} else if begin+size > len(data) {
+			return file, fmt.Errorf("Data of variable '%s' exceeds the file.", name)
+		}
+
+		/*
+		 * Decode each value.
+		 */
+		for j := 0; j < size; j += 8 {
+			bits := binary.BigEndian.Uint64(data[begin+j:])
+			variable.values = append(variable.values, math.Float64frombits(bits))
+		}
+
+		file.names = append(file.names, name)
+		file.variables[name] = variable
+	}
+
+	return file, dec.err
+}
+
+/*
+ * Returns whether two slices hold the same values, treating NaN as equal.
+ */
+func equalValues(a []float64, b []float64) bool {
+
+	/*
+	 * Check if lengths match.
+	 */
+	if len(a) != len(b) {
+		return false
+	}
+
+	/*
+	 * Compare each value.
+	 */
+	for i, value := range a {
+
+		/*
+		 * Check if values differ.
+		 */
+		if (value != b[i]) && !(math.IsNaN(value) && math.IsNaN(b[i])) {
+			return false
+		}
+
+	}
+
+	return true
+}
+
+/*
+ * Write a scene with an additional variable and read it back.
+ */
+func TestWrite(t *testing.T) {
+	scn := scene.Create(4, 2, 0.0, 8.0, 10.0, 12.0)
+
+	/*
+	 * Points falling into some of the bins.
+	 */
+	points := []coordinates.Cartesian{
+		coordinates.CreateCartesian(0.5, 10.5),
+		coordinates.CreateCartesian(0.7, 10.2),
+		coordinates.CreateCartesian(7.5, 11.5),
+		coordinates.CreateCartesian(3.0, 11.0),
+	}
+
+	scn.Aggregate(points)
+	speed := []float64{1.5, math.NaN(), -2.0, 0.0, 3.25, 4.0, 1e300, 7.0}
+	buf := bytes.Buffer{}
+	err := Write(&buf, scn, WithAttribute("title", "Test"), WithCoordinateUnits("m"), WithVariable("speed", speed), nil)
+
+	/*
+	 * Check if file could be written.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	file, err := decode(buf.Bytes())
+
+	/*
+	 * Check if file could be decoded.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	counts := scn.Counts()
+	countValues := make([]float64, len(counts))
+
+	/*
+	 * Convert counts to floating-point values.
+	 */
+	for i, count := range counts {
+		countValues[i] = float64(count)
+	}
+
+	names := fmt.Sprint(file.names)
+	x := file.variables["x"]
+	y := file.variables["y"]
+
+	/*
+	 * Compare the file with the scene.
+	 */
+	if (len(file.dimensions) != 2) || (file.dimensions[DIMENSION_Y] != 2) || (file.dimensions[DIMENSION_X] != 4) {
+		t.Errorf("Dimensions are %v, expected [2 4].", file.dimensions)
+	} else if file.attributes["title"] != "Test" {
+		t.Errorf("Title is '%s', expected 'Test'.", file.attributes["title"])
+	} else if names != "[y x count speed]" {
+		t.Errorf("Variables are %s, expected [y x count speed].", names)
+	} else if !equalValues(x.values, []float64{1.0, 3.0, 5.0, 7.0}) {
+		t.Errorf("Variable x is %v.", x.values)
+	} else if !equalValues(y.values, []float64{11.5, 10.5}) {
+		t.Errorf("Variable y is %v.", y.values)
+	} else if (x.attributes["units"] != "m") || (y.attributes["units"] != "m") {
+		t.Errorf("%s", "Coordinate variables lack units.")
+	} else if !equalValues(file.variables["count"].values, countValues) {
+		t.Errorf("Variable count is %v, expected %v.", file.variables["count"].values, countValues)
+	} else if !equalValues(file.variables["speed"].values, speed) {
+		t.Errorf("Variable speed is %v, expected %v.", file.variables["speed"].values, speed)
+	} else if fmt.Sprint(file.variables["speed"].dimensions) != "[0 1]" {
+		t.Errorf("Variable speed has dimensions %v.", file.variables["speed"].dimensions)
+	}
+
+}
+
+/*
+ * Invalid variables and attributes are rejected.
+ */
+func TestWriteInvalid(t *testing.T) {
+	scn := scene.Create(3, 2, 0.0, 3.0, 0.0, 2.0)
+	values := make([]float64, 6)
+
+	/*
+	 * Options which must be rejected.
+	 */
+	cases := []struct {
+		name   string
+		option Option
+	}{
+		{name: "duplicate", option: WithVariable("count", values)},
+		{name: "empty name", option: WithVariable("", values)},
+		{name: "slash", option: WithVariable("a/b", values)},
+		{name: "size", option: WithVariable("speed", values[:5])},
+		{name: "attribute", option: WithAttribute(" title", "Test")},
+	}
+
+	/*
+	 * Write the scene using each option.
+	 */
+	for _, c := range cases {
+		buf := bytes.Buffer{}
+		err := Write(&buf, scn, c.option)
+
+		/*
+		 * Check if an error was returned.
+		 */
+		if err == nil {
+			t.Errorf("Expected an error for case '%s'.", c.name)
+		}
+
+	}
+
+}
@@ -0,0 +1,507 @@
+package nmea
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"github.com/andrepxx/sydney/track"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Conversion factor from knots to meters per second.
+ */
+const (
+	METERS_PER_SECOND_PER_KNOT = 1852.0 / 3600.0
+)
+
+/*
+ * Data structure representing a (partial) fix decoded from one or more
+ * sentences reporting the same time.
+ */
+type fixStruct struct {
+	clock     time.Duration
+	date      time.Time
+	elevation float64
+	hasDate   bool
+	location  coordinates.Geographic
+	speed     float64
+}
+
+/*
+ * A reader reads fixes from a stream of NMEA 0183 sentences.
+ */
+type Reader interface {
+	Read() (track.TrackPoint, error)
+}
+
+/*
+ * Data structure representing a reader.
+ */
+type readerStruct struct {
+	date       time.Time
+	hasPending bool
+	pending    fixStruct
+	scanner    *bufio.Scanner
+}
+
+/*
+ * Verify the checksum of a sentence and return its fields.
+ *
+ * Sentences without checksum are accepted.
+ */
+func splitSentence(line string) ([]string, error) {
+	line = strings.TrimSpace(line)
+
+	/*
+	 * Sentences start with a dollar sign.
+	 */
+	if !strings.HasPrefix(line, "$") {
+		return nil, fmt.Errorf("%s", "Sentence must start with '$'.")
+	} else {
+		body := line[1:]
+		idx := strings.IndexByte(body, '*')
+
+		/*
+		 * Verify checksum if present.
+		 */
+		if idx >= 0 {
+			expected, err := strconv.ParseUint(body[idx+1:], 16, 8)
+			body = body[:idx]
+			sum := byte(0)
+
+			/*
+			 * The checksum is the XOR of all characters between '$' and '*'.
+			 */
+			for i := 0; i < len(body); i++ {
+				sum ^= body[i]
+			}
+
+			/*
+			 * Check if checksum matches.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Invalid checksum: %s", err.Error())
+			} else if uint64(sum) != expected {
+				return nil, fmt.Errorf("Checksum mismatch: expected %02X, found %02X", expected, sum)
+			}
+
+		}
+
+		fields := strings.Split(body, ",")
+		return fields, nil
+	}
+
+}
+
+/*
+ * Parse a time of day in the format hhmmss.ss.
+ */
+func parseClock(value string) (time.Duration, error) {
+
+	/*
+	 * The time of day has at least six digits.
+	 */
+	if len(value) < 6 {
+		return 0, fmt.Errorf("Invalid time: '%s'", value)
+	} else {
+		hours, errHours := strconv.Atoi(value[0:2])
+		minutes, errMinutes := strconv.Atoi(value[2:4])
+		seconds, errSeconds := strconv.ParseFloat(value[4:], 64)
+
+		/*
+		 * Check if time could be parsed.
+		 */
+		if (errHours != nil) || (errMinutes != nil) || (errSeconds != nil) {
+			return 0, fmt.Errorf("Invalid time: '%s'", value)
+		} else {
+			clock := time.Duration(hours) * time.Hour
+			clock += time.Duration(minutes) * time.Minute
+			clock += time.Duration(math.Round(seconds * float64(time.Second)))
+			return clock, nil
+		}
+
+	}
+
+}
+
+/*
+ * Parse a date in the format ddmmyy.
+ *
+ * Two-digit years from 80 onwards belong to the 20th century.
+ */
+func parseDate(value string) (time.Time, error) {
+
+	/*
+	 * The date has exactly six digits.
+	 */
+	if len(value) != 6 {
+		return time.Time{}, fmt.Errorf("Invalid date: '%s'", value)
+	} else {
+		day, errDay := strconv.Atoi(value[0:2])
+		month, errMonth := strconv.Atoi(value[2:4])
+		year, errYear := strconv.Atoi(value[4:6])
+
+		/*
+		 * Check if date could be parsed.
+		 */
+		if (errDay != nil) || (errMonth != nil) || (errYear != nil) {
+			return time.Time{}, fmt.Errorf("Invalid date: '%s'", value)
+		} else {
+
+			/*
+			 * Decide on the century.
+			 */
+			if year >= 80 {
+				year += 1900
+			} else {
+				year += 2000
+			}
+
+			date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+			return date, nil
+		}
+
+	}
+
+}
+
+/*
+ * Parse a latitude or longitude in the format (d)ddmm.mmmm along with its
+ * hemisphere, returning the angle in degrees.
+ */
+func parseAngle(value string, hemisphere string) (float64, error) {
+	raw, err := strconv.ParseFloat(value, 64)
+
+	/*
+	 * Check if angle could be parsed.
+	 */
+	if err != nil {
+		return 0.0, fmt.Errorf("Invalid coordinate: '%s'", value)
+	} else {
+		degrees := math.Floor(raw / 100.0)
+		minutes := raw - (100.0 * degrees)
+		angle := degrees + (minutes / 60.0)
+
+		/*
+		 * Decide on the hemisphere.
+		 */
+		switch hemisphere {
+		case "N", "E":
+			return angle, nil
+		case "S", "W":
+			return -angle, nil
+		default:
+			return 0.0, fmt.Errorf("Invalid hemisphere: '%s'", hemisphere)
+		}
+
+	}
+
+}
+
+/*
+ * Parse a location given as latitude, hemisphere, longitude and hemisphere.
+ */
+func parseLocation(fields []string) (coordinates.Geographic, error) {
+	latitude, errLat := parseAngle(fields[0], fields[1])
+	longitude, errLon := parseAngle(fields[2], fields[3])
+
+	/*
+	 * Check if location could be parsed.
+	 */
+	if errLat != nil {
+		return coordinates.Geographic{}, errLat
+	} else if errLon != nil {
+		return coordinates.Geographic{}, errLon
+	} else {
+		location := coordinates.CreateGeographicDegrees(longitude, latitude)
+		return location, nil
+	}
+
+}
+
+/*
+ * Decode a GGA sentence (fix data), which carries the time of day, the
+ * location and the altitude above mean sea level.
+ *
+ * Returns false if the sentence does not carry a valid fix.
+ */
+func decodeGGA(fields []string) (fixStruct, bool, error) {
+
+	/*
+	 * Check number of fields.
+	 */
+	if len(fields) < 10 {
+		return fixStruct{}, false, fmt.Errorf("%s", "GGA sentence too short.")
+	} else if (fields[6] == "") || (fields[6] == "0") {
+		return fixStruct{}, false, nil
+	} else {
+		clock, errClock := parseClock(fields[1])
+		location, errLocation := parseLocation(fields[2:6])
+
+		/*
+		 * Check if fix could be parsed.
+		 */
+		if errClock != nil {
+			return fixStruct{}, false, errClock
+		} else if errLocation != nil {
+			return fixStruct{}, false, errLocation
+		} else {
+			elevation, err := strconv.ParseFloat(fields[9], 64)
+
+			/*
+			 * The altitude is optional.
+			 */
+			if err != nil {
+				elevation = math.NaN()
+			}
+
+			/*
+			 * Create fix.
+			 */
+			fix := fixStruct{
+				clock:     clock,
+				elevation: elevation,
+				location:  location,
+				speed:     math.NaN(),
+			}
+
+			return fix, true, nil
+		}
+
+	}
+
+}
+
+/*
+ * Decode an RMC sentence (recommended minimum data), which carries the time
+ * of day, the location, the speed over ground and the date.
+ *
+ * Returns false if the sentence does not carry a valid fix.
+ */
+func decodeRMC(fields []string) (fixStruct, bool, error) {
+
+	/*
+	 * Check number of fields.
+	 */
+	if len(fields) < 10 {
+		return fixStruct{}, false, fmt.Errorf("%s", "RMC sentence too short.")
+	} else if fields[2] != "A" {
+		return fixStruct{}, false, nil
+	} else {
+		clock, errClock := parseClock(fields[1])
+		location, errLocation := parseLocation(fields[3:7])
+		date, errDate := parseDate(fields[9])
+
+		/*
+		 * Check if fix could be parsed.
+		 */
+		if errClock != nil {
+			return fixStruct{}, false, errClock
+		} else if errLocation != nil {
+			return fixStruct{}, false, errLocation
+		} else if errDate != nil {
+			return fixStruct{}, false, errDate
+		} else {
+			knots, err := strconv.ParseFloat(fields[7], 64)
+			speed := knots * METERS_PER_SECOND_PER_KNOT
+
+			/*
+			 * The speed is optional.
+			 */
+			if err != nil {
+				speed = math.NaN()
+			}
+
+			/*
+			 * Create fix.
+			 */
+			fix := fixStruct{
+				clock:     clock,
+				date:      date,
+				elevation: math.NaN(),
+				hasDate:   true,
+				location:  location,
+				speed:     speed,
+			}
+
+			return fix, true, nil
+		}
+
+	}
+
+}
+
+/*
+ * Merge another fix reported for the same time into this fix, filling in
+ * values which are unknown.
+ */
+func (this *fixStruct) merge(other *fixStruct) {
+
+	/*
+	 * Take date from other fix if needed.
+	 */
+	if !this.hasDate && other.hasDate {
+		this.date = other.date
+		this.hasDate = true
+	}
+
+	/*
+	 * Take elevation from other fix if needed.
+	 */
+	if math.IsNaN(this.elevation) {
+		this.elevation = other.elevation
+	}
+
+	/*
+	 * Take speed from other fix if needed.
+	 */
+	if math.IsNaN(this.speed) {
+		this.speed = other.speed
+	}
+
+}
+
+/*
+ * Convert the pending fix into a track point.
+ */
+func (this *readerStruct) emit() track.TrackPoint {
+	fix := &this.pending
+	date := fix.date
+
+	/*
+	 * Use the last known date for fixes without date.
+	 */
+	if !fix.hasDate {
+		date = this.date
+	}
+
+	timestamp := date.Add(fix.clock)
+	this.hasPending = false
+	return track.CreateTrackPoint(fix.location, timestamp, fix.elevation, fix.speed)
+}
+
+/*
+ * Read the next fix.
+ *
+ * GGA and RMC sentences reporting the same time are merged into a single
+ * fix, which is therefore only returned once a sentence reporting another
+ * time arrives or the stream ends. Sentences without valid fix, with invalid
+ * checksum or of other types are skipped. Fixes from GGA sentences carry
+ * the date of the last RMC sentence. Returns io.EOF at the end of the
+ * stream.
+ */
+func (this *readerStruct) Read() (track.TrackPoint, error) {
+	scanner := this.scanner
+
+	/*
+	 * Process lines until a fix is complete.
+	 */
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields, err := splitSentence(line)
+
+		/*
+		 * Skip garbled sentences.
+		 */
+		if (err == nil) && (len(fields[0]) >= 3) {
+			address := fields[0]
+			sentenceType := address[len(address)-3:]
+			fix := fixStruct{}
+			ok := false
+
+			/*
+			 * Decode supported sentence types.
+			 */
+			switch sentenceType {
+			case "GGA":
+				fix, ok, err = decodeGGA(fields)
+			case "RMC":
+				fix, ok, err = decodeRMC(fields)
+			}
+
+			/*
+			 * Merge fixes reporting the same time.
+			 */
+			if (err == nil) && ok {
+				result := track.TrackPoint{}
+				complete := false
+
+				/*
+				 * Check if fix belongs to pending one.
+				 */
+				if this.hasPending && (this.pending.clock == fix.clock) {
+					this.pending.merge(&fix)
+				} else {
+
+					/*
+					 * The pending fix is complete.
+					 */
+					if this.hasPending {
+						result = this.emit()
+						complete = true
+					}
+
+					this.pending = fix
+					this.hasPending = true
+				}
+
+				/*
+				 * Remember the date.
+				 */
+				if fix.hasDate {
+					this.date = fix.date
+				}
+
+				/*
+				 * Return complete fix.
+				 */
+				if complete {
+					return result, nil
+				}
+
+			}
+
+		}
+
+	}
+
+	err := scanner.Err()
+
+	/*
+	 * Report errors of the underlying stream or return the last fix.
+	 */
+	if err != nil {
+		return track.TrackPoint{}, err
+	} else if this.hasPending {
+		return this.emit(), nil
+	} else {
+		return track.TrackPoint{}, io.EOF
+	}
+
+}
+
+/*
+ * Create a reader for a stream of NMEA 0183 sentences, e. g. from a serial
+ * port or a TCP connection to a GPS receiver.
+ *
+ * Sentences are read line by line as they arrive, so that fixes can be fed
+ * into a scene while the stream is still open.
+ */
+func Create(r io.Reader) Reader {
+	scanner := bufio.NewScanner(decompress.Auto(r))
+
+	/*
+	 * Create reader data structure.
+	 */
+	rd := readerStruct{
+		scanner: scanner,
+	}
+
+	return &rd
+}
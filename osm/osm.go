@@ -0,0 +1,1154 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+/*
+ * Types of elements.
+ */
+const (
+	ELEMENT_NODE = 0
+	ELEMENT_WAY  = 1
+)
+
+/*
+ * Limits imposed on blocks by the PBF format.
+ */
+const (
+	MAX_HEADER_SIZE = 64 * 1024
+	MAX_BLOB_SIZE   = 32 * 1024 * 1024
+)
+
+/*
+ * Number of points aggregated into a scene at once.
+ */
+const (
+	BATCH_SIZE = 65536
+)
+
+/*
+ * Coordinates are stored in units of nanodegrees.
+ */
+const (
+	NANODEGREES_PER_DEGREE = 1e9
+)
+
+/*
+ * Features of the file format, which this reader supports.
+ */
+var supportedFeatures = map[string]bool{
+	"OsmSchema-V0.6":        true,
+	"DenseNodes":            true,
+	"HistoricalInformation": true,
+}
+
+/*
+ * An element of the map, i. e. a node or a way, reduced to a single location.
+ */
+type Element struct {
+	elementType int
+	id          int64
+	location    coordinates.Geographic
+	tags        map[string]string
+}
+
+/*
+ * Returns the ID of this element.
+ */
+func (this *Element) ID() int64 {
+	return this.id
+}
+
+/*
+ * Returns the location of this element.
+ *
+ * For ways, this is the mean location of its nodes.
+ */
+func (this *Element) Location() coordinates.Geographic {
+	return this.location
+}
+
+/*
+ * Returns the value of a tag of this element and whether the tag is present.
+ */
+func (this *Element) Tag(key string) (string, bool) {
+	value, ok := this.tags[key]
+	return value, ok
+}
+
+/*
+ * Returns the keys of all tags of this element.
+ */
+func (this *Element) TagKeys() []string {
+	keys := make([]string, 0, len(this.tags))
+
+	/*
+	 * Collect all keys.
+	 */
+	for key := range this.tags {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+/*
+ * Returns the type of this element, i. e. ELEMENT_NODE or ELEMENT_WAY.
+ */
+func (this *Element) Type() int {
+	return this.elementType
+}
+
+/*
+ * Data structure representing a tag filter.
+ */
+type filterStruct struct {
+	key   string
+	value string
+}
+
+/*
+ * Data structure representing the configuration of a reader.
+ */
+type configStruct struct {
+	filters    []filterStruct
+	projection projection.Projection
+	ways       bool
+}
+
+/*
+ * An option configures a reader.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Only read elements carrying a tag with the given key and value, e. g.
+ * "amenity" and "pub". An empty value matches any value, e. g. "building"
+ * and "" matches all buildings.
+ *
+ * If this option is given multiple times, elements matching any of the tags
+ * are read.
+ */
+func WithTag(key string, value string) Option {
+
+	/*
+	 * Add tag filter.
+	 */
+	return func(config *configStruct) {
+
+		/*
+		 * Create tag filter.
+		 */
+		filter := filterStruct{
+			key:   key,
+			value: value,
+		}
+
+		config.filters = append(config.filters, filter)
+	}
+
+}
+
+/*
+ * Project locations when aggregating them into a scene.
+ *
+ * Without projection, longitude and latitude in degrees are aggregated as x-
+ * and y-coordinates.
+ */
+func WithProjection(proj projection.Projection) Option {
+
+	/*
+	 * Set projection.
+	 */
+	return func(config *configStruct) {
+		config.projection = proj
+	}
+
+}
+
+/*
+ * Also read ways, e. g. buildings, each reduced to the mean location of its
+ * nodes.
+ *
+ * Unless the file stores locations on ways, this requires keeping the
+ * locations of all nodes in memory, since ways only reference nodes, so this
+ * is only feasible for regional extracts.
+ */
+func WithWays() Option {
+
+	/*
+	 * Enable ways.
+	 */
+	return func(config *configStruct) {
+		config.ways = true
+	}
+
+}
+
+/*
+ * A reader reads elements from an OpenStreetMap PBF file.
+ */
+type Reader interface {
+	Aggregate(scn scene.Scene) error
+	Read() (Element, error)
+}
+
+/*
+ * Data structure representing a reader.
+ */
+type readerStruct struct {
+	config  configStruct
+	nodes   map[int64][2]int32
+	pending []Element
+	r       io.Reader
+}
+
+/*
+ * Data structure representing the coordinate encoding of a block.
+ */
+type blockStruct struct {
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+	strings     [][]byte
+}
+
+/*
+ * Convert encoded coordinates of a block into a location.
+ */
+func (this *blockStruct) location(lat int64, lon int64) coordinates.Geographic {
+	latitude := float64(this.latOffset+(this.granularity*lat)) / NANODEGREES_PER_DEGREE
+	longitude := float64(this.lonOffset+(this.granularity*lon)) / NANODEGREES_PER_DEGREE
+	return coordinates.CreateGeographicDegrees(longitude, latitude)
+}
+
+/*
+ * Look up a string in the string table of a block.
+ */
+func (this *blockStruct) lookup(idx uint64) (string, error) {
+
+	/*
+	 * Check if index is in range.
+	 */
+	if idx >= uint64(len(this.strings)) {
+		return "", fmt.Errorf("String index out of range: %d", idx)
+	} else {
+		return string(this.strings[idx]), nil
+	}
+
+}
+
+/*
+ * Build the tags of an element from indices into the string table.
+ *
+ * Returns nil if the element has no tags.
+ */
+func (this *blockStruct) tags(keys []uint64, values []uint64) (map[string]string, error) {
+
+	/*
+	 * Check if keys and values match.
+	 */
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("%s", "Number of keys and values differs.")
+	} else if len(keys) == 0 {
+		return nil, nil
+	} else {
+		tags := make(map[string]string, len(keys))
+
+		/*
+		 * Look up each key and value.
+		 */
+		for i, keyIdx := range keys {
+			key, errKey := this.lookup(keyIdx)
+			value, errValue := this.lookup(values[i])
+
+			/*
+			 * Check if strings could be looked up.
+			 */
+			if errKey != nil {
+				return nil, errKey
+			} else if errValue != nil {
+				return nil, errValue
+			}
+
+			tags[key] = value
+		}
+
+		return tags, nil
+	}
+
+}
+
+/*
+ * Check if tags match the configured filters.
+ */
+func (this *configStruct) matches(tags map[string]string) bool {
+
+	/*
+	 * Without filters, all elements match.
+	 */
+	if len(this.filters) == 0 {
+		return true
+	} else {
+
+		/*
+		 * Check each filter.
+		 */
+		for _, filter := range this.filters {
+			value, ok := tags[filter.key]
+
+			/*
+			 * An empty value matches any value.
+			 */
+			if ok && ((filter.value == "") || (filter.value == value)) {
+				return true
+			}
+
+		}
+
+		return false
+	}
+
+}
+
+/*
+ * Remember the location of a node if ways must be resolved.
+ */
+func (this *readerStruct) remember(id int64, location coordinates.Geographic) {
+
+	/*
+	 * Locations are stored in units of 100 nanodegrees to save memory.
+	 */
+	if this.nodes != nil {
+		lat := int32(math.Round(location.LatitudeDegrees() * 1e7))
+		lon := int32(math.Round(location.LongitudeDegrees() * 1e7))
+		this.nodes[id] = [2]int32{lat, lon}
+	}
+
+}
+
+/*
+ * Add a node if it matches the configured filters.
+ */
+func (this *readerStruct) addNode(id int64, location coordinates.Geographic, tags map[string]string) {
+	this.remember(id, location)
+
+	/*
+	 * Only add matching nodes.
+	 */
+	if this.config.matches(tags) {
+
+		/*
+		 * Create element.
+		 */
+		element := Element{
+			elementType: ELEMENT_NODE,
+			id:          id,
+			location:    location,
+			tags:        tags,
+		}
+
+		this.pending = append(this.pending, element)
+	}
+
+}
+
+/*
+ * Decode a single node.
+ */
+func (this *readerStruct) decodeNode(block *blockStruct, data []byte) error {
+	id := int64(0)
+	lat := int64(0)
+	lon := int64(0)
+	keys := []uint64{}
+	values := []uint64{}
+
+	/*
+	 * Decode all fields.
+	 */
+	for len(data) > 0 {
+		field, rest, err := nextField(data)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+		data = rest
+
+		/*
+		 * Decide on the field.
+		 */
+		switch field.number {
+		case 1:
+			id = zigzag(field.value)
+		case 2:
+			keys, err = decodePacked(field.data, keys)
+		case 3:
+			values, err = decodePacked(field.data, values)
+		case 8:
+			lat = zigzag(field.value)
+		case 9:
+			lon = zigzag(field.value)
+		}
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+	tags, err := block.tags(keys, values)
+
+	/*
+	 * Check if tags could be decoded.
+	 */
+	if err != nil {
+		return err
+	} else {
+		location := block.location(lat, lon)
+		this.addNode(id, location, tags)
+		return nil
+	}
+
+}
+
+/*
+ * Decode densely encoded nodes.
+ */
+func (this *readerStruct) decodeDenseNodes(block *blockStruct, data []byte) error {
+	ids := []int64{}
+	lats := []int64{}
+	lons := []int64{}
+	keysValues := []uint64{}
+
+	/*
+	 * Decode all fields.
+	 */
+	for len(data) > 0 {
+		field, rest, err := nextField(data)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+		data = rest
+
+		/*
+		 * Decide on the field.
+		 */
+		switch field.number {
+		case 1:
+			ids, err = decodePackedDelta(field.data, ids)
+		case 8:
+			lats, err = decodePackedDelta(field.data, lats)
+		case 9:
+			lons, err = decodePackedDelta(field.data, lons)
+		case 10:
+			keysValues, err = decodePacked(field.data, keysValues)
+		}
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+	/*
+	 * Check if all nodes have coordinates.
+	 */
+	if (len(lats) != len(ids)) || (len(lons) != len(ids)) {
+		return fmt.Errorf("%s", "Number of IDs and coordinates of dense nodes differs.")
+	} else {
+		pos := 0
+
+		/*
+		 * Add each node.
+		 */
+		for i, id := range ids {
+			keys := []uint64{}
+			values := []uint64{}
+
+			/*
+			 * Tags of all nodes are stored as alternating keys and values,
+			 * each node terminated by a zero.
+			 */
+			for (pos < len(keysValues)) && (keysValues[pos] != 0) {
+
+				/*
+				 * Check if value is present.
+				 */
+				if pos+1 >= len(keysValues) {
+					return fmt.Errorf("%s", "Truncated tags of dense nodes.")
+				}
+
+				keys = append(keys, keysValues[pos])
+				values = append(values, keysValues[pos+1])
+				pos += 2
+			}
+
+			pos++
+			tags, err := block.tags(keys, values)
+
+			/*
+			 * Check if tags could be decoded.
+			 */
+			if err != nil {
+				return err
+			}
+
+			location := block.location(lats[i], lons[i])
+			this.addNode(id, location, tags)
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Decode a way, which is reduced to the mean location of its nodes.
+ *
+ * Ways whose nodes are unknown are skipped.
+ */
+func (this *readerStruct) decodeWay(block *blockStruct, data []byte) error {
+	id := int64(0)
+	keys := []uint64{}
+	values := []uint64{}
+	refs := []int64{}
+	lats := []int64{}
+	lons := []int64{}
+
+	/*
+	 * Decode all fields.
+	 */
+	for len(data) > 0 {
+		field, rest, err := nextField(data)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+		data = rest
+
+		/*
+		 * Decide on the field.
+		 */
+		switch field.number {
+		case 1:
+			id = int64(field.value)
+		case 2:
+			keys, err = decodePacked(field.data, keys)
+		case 3:
+			values, err = decodePacked(field.data, values)
+		case 8:
+			refs, err = decodePackedDelta(field.data, refs)
+		case 9:
+			lats, err = decodePackedDelta(field.data, lats)
+		case 10:
+			lons, err = decodePackedDelta(field.data, lons)
+		}
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+	tags, err := block.tags(keys, values)
+
+	/*
+	 * Check if tags could be decoded.
+	 */
+	if err != nil {
+		return err
+	} else if this.config.matches(tags) {
+		n := len(refs)
+
+		/*
+		 * Closed ways repeat their first node at the end.
+		 */
+		if (n > 1) && (refs[0] == refs[n-1]) {
+			n--
+		}
+
+		sumLat := float64(0.0)
+		sumLon := float64(0.0)
+		count := 0
+		embedded := (len(lats) == len(refs)) && (len(lons) == len(refs))
+
+		/*
+		 * Sum up the locations of all nodes.
+		 */
+		for i, ref := range refs[:n] {
+
+			/*
+			 * Use locations stored on the way if present.
+			 */
+			if embedded {
+				location := block.location(lats[i], lons[i])
+				sumLat += location.LatitudeDegrees()
+				sumLon += location.LongitudeDegrees()
+				count++
+			} else if node, ok := this.nodes[ref]; ok {
+				sumLat += float64(node[0]) / 1e7
+				sumLon += float64(node[1]) / 1e7
+				count++
+			}
+
+		}
+
+		/*
+		 * Skip ways without known nodes.
+		 */
+		if count > 0 {
+			scale := 1.0 / float64(count)
+			location := coordinates.CreateGeographicDegrees(scale*sumLon, scale*sumLat)
+
+			/*
+			 * Create element.
+			 */
+			element := Element{
+				elementType: ELEMENT_WAY,
+				id:          id,
+				location:    location,
+				tags:        tags,
+			}
+
+			this.pending = append(this.pending, element)
+		}
+
+	}
+
+	return nil
+}
+
+/*
+ * Decode a group of elements of the same type.
+ */
+func (this *readerStruct) decodeGroup(block *blockStruct, data []byte) error {
+
+	/*
+	 * Decode all fields.
+	 */
+	for len(data) > 0 {
+		field, rest, err := nextField(data)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+		data = rest
+
+		/*
+		 * Decide on the field, relations and changesets are skipped.
+		 */
+		switch field.number {
+		case 1:
+			err = this.decodeNode(block, field.data)
+		case 2:
+			err = this.decodeDenseNodes(block, field.data)
+		case 3:
+
+			/*
+			 * Only decode ways if requested.
+			 */
+			if this.config.ways {
+				err = this.decodeWay(block, field.data)
+			}
+
+		}
+
+		/*
+		 * Check if group could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+/*
+ * Decode a block of elements.
+ */
+func (this *readerStruct) decodeBlock(data []byte) error {
+	groups := [][]byte{}
+
+	/*
+	 * Default coordinate encoding.
+	 */
+	block := blockStruct{
+		granularity: 100,
+	}
+
+	/*
+	 * Decode all fields. Groups are decoded afterwards, since they depend
+	 * on the string table and the coordinate encoding.
+	 */
+	for len(data) > 0 {
+		field, rest, err := nextField(data)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+		data = rest
+
+		/*
+		 * Decide on the field.
+		 */
+		switch field.number {
+		case 1:
+			table := field.data
+
+			/*
+			 * Decode all strings of the table.
+			 */
+			for len(table) > 0 {
+				entry, rest, err := nextField(table)
+
+				/*
+				 * Check if entry could be decoded.
+				 */
+				if err != nil {
+					return err
+				}
+
+				table = rest
+
+				/*
+				 * Only strings are stored in the table.
+				 */
+				if entry.number == 1 {
+					block.strings = append(block.strings, entry.data)
+				}
+
+			}
+
+		case 2:
+			groups = append(groups, field.data)
+		case 17:
+			block.granularity = int64(field.value)
+		case 19:
+			block.latOffset = int64(field.value)
+		case 20:
+			block.lonOffset = int64(field.value)
+		}
+
+	}
+
+	/*
+	 * Decode each group.
+	 */
+	for _, group := range groups {
+		err := this.decodeGroup(&block, group)
+
+		/*
+		 * Check if group could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+/*
+ * Check that the reader supports all features required by a file.
+ */
+func checkHeader(data []byte) error {
+
+	/*
+	 * Decode all fields.
+	 */
+	for len(data) > 0 {
+		field, rest, err := nextField(data)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+		data = rest
+
+		/*
+		 * Check required features.
+		 */
+		if field.number == 4 {
+			feature := string(field.data)
+
+			/*
+			 * Check if feature is supported.
+			 */
+			if !supportedFeatures[feature] {
+				return fmt.Errorf("Unsupported required feature: '%s'", feature)
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
+/*
+ * Decompress the contents of a blob.
+ */
+func decodeBlob(data []byte) ([]byte, error) {
+
+	/*
+	 * Decode all fields.
+	 */
+	for len(data) > 0 {
+		field, rest, err := nextField(data)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		data = rest
+
+		/*
+		 * Decide on the compression.
+		 */
+		switch field.number {
+		case 1:
+			return field.data, nil
+		case 3:
+			rd, err := zlib.NewReader(bytes.NewReader(field.data))
+
+			/*
+			 * Check if stream could be opened.
+			 */
+			if err != nil {
+				return nil, err
+			} else {
+				defer rd.Close()
+				return ioutil.ReadAll(rd)
+			}
+
+		case 4, 5, 6, 7:
+			return nil, fmt.Errorf("Unsupported blob compression: %d", field.number)
+		}
+
+	}
+
+	return nil, fmt.Errorf("%s", "Blob without data.")
+}
+
+/*
+ * Read the next blob, returning its type and its decompressed contents.
+ *
+ * Returns io.EOF at the end of the file.
+ */
+func (this *readerStruct) readBlob() (string, []byte, error) {
+	prefix := make([]byte, 4)
+	_, err := io.ReadFull(this.r, prefix)
+
+	/*
+	 * Check if the file ends.
+	 */
+	if err != nil {
+		return "", nil, err
+	}
+
+	headerSize := binary.BigEndian.Uint32(prefix)
+
+	/*
+	 * Check size of header.
+	 */
+	if headerSize > MAX_HEADER_SIZE {
+		return "", nil, fmt.Errorf("Blob header too large: %d bytes", headerSize)
+	}
+
+	header := make([]byte, headerSize)
+	_, err = io.ReadFull(this.r, header)
+
+	/*
+	 * Check if header could be read.
+	 */
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to read blob header: %s", err.Error())
+	}
+
+	blobType := ""
+	blobSize := uint64(0)
+
+	/*
+	 * Decode all fields of the header.
+	 */
+	for len(header) > 0 {
+		field, rest, err := nextField(header)
+
+		/*
+		 * Check if field could be decoded.
+		 */
+		if err != nil {
+			return "", nil, err
+		}
+
+		header = rest
+
+		/*
+		 * Decide on the field.
+		 */
+		switch field.number {
+		case 1:
+			blobType = string(field.data)
+		case 3:
+			blobSize = field.value
+		}
+
+	}
+
+	/*
+	 * Check size of blob.
+	 */
+	if blobSize > MAX_BLOB_SIZE {
+		return "", nil, fmt.Errorf("Blob too large: %d bytes", blobSize)
+	}
+
+	blob := make([]byte, blobSize)
+	_, err = io.ReadFull(this.r, blob)
+
+	/*
+	 * Check if blob could be read.
+	 */
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to read blob: %s", err.Error())
+	}
+
+	data, err := decodeBlob(blob)
+
+	/*
+	 * Check if blob could be decompressed.
+	 */
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to decompress blob: %s", err.Error())
+	} else {
+		return blobType, data, nil
+	}
+
+}
+
+/*
+ * Read the next element matching the configured filters.
+ *
+ * Elements are decoded one block at a time, so that the file need not fit
+ * into memory. Untagged nodes only match if no filters are configured.
+ * Relations are skipped. Returns io.EOF at the end of the file.
+ */
+func (this *readerStruct) Read() (Element, error) {
+
+	/*
+	 * Decode blocks until an element is available.
+	 */
+	for len(this.pending) == 0 {
+		blobType, data, err := this.readBlob()
+
+		/*
+		 * Check if blob could be read.
+		 */
+		if err != nil {
+			return Element{}, err
+		}
+
+		/*
+		 * Decide on the type of blob, unknown types are skipped.
+		 */
+		switch blobType {
+		case "OSMHeader":
+			err = checkHeader(data)
+		case "OSMData":
+			err = this.decodeBlock(data)
+		}
+
+		/*
+		 * Check if blob could be decoded.
+		 */
+		if err != nil {
+			return Element{}, err
+		}
+
+	}
+
+	element := this.pending[0]
+	this.pending = this.pending[1:]
+	return element, nil
+}
+
+/*
+ * Read all remaining elements and aggregate their locations into a scene,
+ * projecting them first if a projection is configured.
+ *
+ * Locations are aggregated in batches, so that the file need not fit into
+ * memory.
+ */
+func (this *readerStruct) Aggregate(scn scene.Scene) error {
+	proj := this.config.projection
+	batch := make([]coordinates.Cartesian, 0, BATCH_SIZE)
+	locations := make([]coordinates.Geographic, 0, BATCH_SIZE)
+
+	/*
+	 * Aggregate collected locations.
+	 */
+	flush := func() error {
+
+		/*
+		 * Project locations if needed.
+		 */
+		if proj != nil {
+			batch = batch[:len(locations)]
+			err := proj.Forward(batch, locations)
+
+			/*
+			 * Points which could not be projected are NaN and are not
+			 * aggregated, so only other errors are fatal.
+			 */
+			if _, ok := err.(projection.BatchError); (err != nil) && !ok {
+				return err
+			}
+
+		} else {
+			batch = batch[:0]
+
+			/*
+			 * The x- and y-coordinates are longitude and latitude.
+			 */
+			for i := range locations {
+				location := &locations[i]
+				point := coordinates.CreateCartesian(location.LongitudeDegrees(), location.LatitudeDegrees())
+				batch = append(batch, point)
+			}
+
+		}
+
+		scn.Aggregate(batch)
+		locations = locations[:0]
+		return nil
+	}
+
+	/*
+	 * Read all elements.
+	 */
+	for {
+		element, err := this.Read()
+
+		/*
+		 * Stop at the end of the file.
+		 */
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		locations = append(locations, element.location)
+
+		/*
+		 * Aggregate full batches.
+		 */
+		if len(locations) >= BATCH_SIZE {
+			err = flush()
+
+			/*
+			 * Check if batch could be aggregated.
+			 */
+			if err != nil {
+				return err
+			}
+
+		}
+
+	}
+
+	return flush()
+}
+
+/*
+ * Create a reader for an OpenStreetMap PBF file, e. g. a regional extract or
+ * the whole planet.
+ *
+ * By default, all nodes are read and ways are skipped.
+ */
+func Create(r io.Reader, options ...Option) Reader {
+	config := configStruct{}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	nodes := map[int64][2]int32(nil)
+
+	/*
+	 * Node locations are only needed to resolve ways.
+	 */
+	if config.ways {
+		nodes = make(map[int64][2]int32)
+	}
+
+	/*
+	 * Create reader data structure.
+	 */
+	rd := readerStruct{
+		config: config,
+		nodes:  nodes,
+		r:      decompress.Auto(r),
+	}
+
+	return &rd
+}
@@ -0,0 +1,210 @@
+package osm
+
+import (
+	"fmt"
+)
+
+/*
+ * Wire types of the protocol buffer encoding.
+ */
+const (
+	WIRE_VARINT  = 0
+	WIRE_FIXED64 = 1
+	WIRE_BYTES   = 2
+	WIRE_FIXED32 = 5
+)
+
+/*
+ * Data structure representing a field of a protocol buffer message.
+ *
+ * For length-delimited fields, data holds the payload, otherwise value holds
+ * the (raw) value.
+ */
+type protoField struct {
+	data     []byte
+	number   uint64
+	value    uint64
+	wireType uint64
+}
+
+/*
+ * Decode a variable-length integer, returning the value and the number of
+ * bytes consumed.
+ */
+func decodeVarint(buf []byte) (uint64, int, error) {
+	value := uint64(0)
+	shift := uint(0)
+
+	/*
+	 * Process seven bits per byte.
+	 */
+	for i, b := range buf {
+
+		/*
+		 * Varints have at most ten bytes.
+		 */
+		if i >= 10 {
+			return 0, 0, fmt.Errorf("%s", "Varint too long.")
+		}
+
+		value |= uint64(b&0x7f) << shift
+		shift += 7
+
+		/*
+		 * The last byte has the high bit cleared.
+		 */
+		if b < 0x80 {
+			return value, i + 1, nil
+		}
+
+	}
+
+	return 0, 0, fmt.Errorf("%s", "Truncated varint.")
+}
+
+/*
+ * Decode a zigzag-encoded signed integer.
+ */
+func zigzag(value uint64) int64 {
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+/*
+ * Decode the next field of a protocol buffer message, returning the field
+ * and the remaining bytes.
+ */
+func nextField(buf []byte) (protoField, []byte, error) {
+	key, n, err := decodeVarint(buf)
+
+	/*
+	 * Check if key could be decoded.
+	 */
+	if err != nil {
+		return protoField{}, nil, err
+	} else {
+		buf = buf[n:]
+
+		/*
+		 * The field.
+		 */
+		field := protoField{
+			number:   key >> 3,
+			wireType: key & 0x7,
+		}
+
+		/*
+		 * Decode value according to wire type.
+		 */
+		switch field.wireType {
+		case WIRE_VARINT:
+			value, n, err := decodeVarint(buf)
+
+			/*
+			 * Check if value could be decoded.
+			 */
+			if err != nil {
+				return protoField{}, nil, err
+			} else {
+				field.value = value
+				return field, buf[n:], nil
+			}
+
+		case WIRE_FIXED64:
+
+			/*
+			 * Check if value is complete.
+			 */
+			if len(buf) < 8 {
+				return protoField{}, nil, fmt.Errorf("%s", "Truncated fixed64 value.")
+			} else {
+				field.data = buf[:8]
+				return field, buf[8:], nil
+			}
+
+		case WIRE_BYTES:
+			length, n, err := decodeVarint(buf)
+
+			/*
+			 * Check if length could be decoded.
+			 */
+			if err != nil {
+				return protoField{}, nil, err
+			} else if uint64(len(buf)-n) < length {
+				return protoField{}, nil, fmt.Errorf("%s", "Truncated length-delimited value.")
+			} else {
+				end := n + int(length)
+				field.data = buf[n:end]
+				return field, buf[end:], nil
+			}
+
+		case WIRE_FIXED32:
+
+			/*
+			 * Check if value is complete.
+			 */
+			if len(buf) < 4 {
+				return protoField{}, nil, fmt.Errorf("%s", "Truncated fixed32 value.")
+			} else {
+				field.data = buf[:4]
+				return field, buf[4:], nil
+			}
+
+		default:
+			return protoField{}, nil, fmt.Errorf("Unsupported wire type: %d", field.wireType)
+		}
+
+	}
+
+}
+
+/*
+ * Decode a packed field of variable-length integers.
+ */
+func decodePacked(data []byte, values []uint64) ([]uint64, error) {
+
+	/*
+	 * Decode all values.
+	 */
+	for len(data) > 0 {
+		value, n, err := decodeVarint(data)
+
+		/*
+		 * Check if value could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+		data = data[n:]
+	}
+
+	return values, nil
+}
+
+/*
+ * Decode a packed field of zigzag-encoded, delta-coded signed integers.
+ */
+func decodePackedDelta(data []byte, values []int64) ([]int64, error) {
+	current := int64(0)
+
+	/*
+	 * Decode all values.
+	 */
+	for len(data) > 0 {
+		value, n, err := decodeVarint(data)
+
+		/*
+		 * Check if value could be decoded.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		current += zigzag(value)
+		values = append(values, current)
+		data = data[n:]
+	}
+
+	return values, nil
+}
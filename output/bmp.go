@@ -0,0 +1,93 @@
+package output
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"math"
+)
+
+/*
+ * Constants describing the BMP files written.
+ */
+const (
+	BMP_FILE_HEADER_SIZE = 14
+	BMP_INFO_HEADER_SIZE = 40
+)
+
+/*
+ * Encode an image, e. g. rendered from a scene, as BMP and write it to w.
+ *
+ * The image is stored as uncompressed 24-bit RGB. Since this does not
+ * support transparency, the image is composited over the background, which
+ * is black unless configured otherwise.
+ */
+func EncodeBMP(w io.Writer, img image.Image, options ...Option) error {
+	config := configure(options)
+	background := opaqueBackground(config.background)
+	flattened := composite(img, background)
+	nrgba := toNRGBA(flattened)
+	rect := nrgba.Bounds()
+	width := rect.Dx()
+	height := rect.Dy()
+	rowSize := ((3 * width) + 3) &^ 3
+	headerSize := BMP_FILE_HEADER_SIZE + BMP_INFO_HEADER_SIZE
+	imageSize := uint64(rowSize) * uint64(height)
+	fileSize := uint64(headerSize) + imageSize
+
+	/*
+	 * Verify that sizes fit into the file format.
+	 */
+	if (fileSize > math.MaxUint32) || (width > math.MaxInt32) || (height > math.MaxInt32) {
+		return fmt.Errorf("%s", "Failed to encode BMP image: Image is too large.")
+	} else {
+		header := make([]byte, headerSize)
+		copy(header[0:2], "BM")
+		binary.LittleEndian.PutUint32(header[2:6], uint32(fileSize))
+		binary.LittleEndian.PutUint32(header[10:14], uint32(headerSize))
+		info := header[BMP_FILE_HEADER_SIZE:]
+		binary.LittleEndian.PutUint32(info[0:4], BMP_INFO_HEADER_SIZE)
+		binary.LittleEndian.PutUint32(info[4:8], uint32(width))
+		binary.LittleEndian.PutUint32(info[8:12], uint32(height))
+		binary.LittleEndian.PutUint16(info[12:14], 1)
+		binary.LittleEndian.PutUint16(info[14:16], 24)
+		binary.LittleEndian.PutUint32(info[20:24], uint32(imageSize))
+		binary.LittleEndian.PutUint32(info[24:28], 2835)
+		binary.LittleEndian.PutUint32(info[28:32], 2835)
+		_, err := w.Write(header)
+		row := make([]byte, rowSize)
+
+		/*
+		 * Rows are stored from bottom to top.
+		 */
+		for y := height - 1; (y >= 0) && (err == nil); y-- {
+			offset := nrgba.PixOffset(0, y)
+			pix := nrgba.Pix[offset : offset+(4*width)]
+
+			/*
+			 * Pixels are stored in blue, green, red order.
+			 */
+			for x := 0; x < width; x++ {
+				src := pix[4*x : (4*x)+4]
+				dst := row[3*x : (3*x)+3]
+				dst[0] = src[2]
+				dst[1] = src[1]
+				dst[2] = src[0]
+			}
+
+			_, err = w.Write(row)
+		}
+
+		/*
+		 * Check if image could be written.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to encode BMP image: %s", err.Error())
+		} else {
+			return nil
+		}
+
+	}
+
+}
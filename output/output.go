@@ -0,0 +1,273 @@
+package output
+
+import (
+	"fmt"
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+/*
+ * Data structure representing the configuration of an encoder.
+ */
+type configStruct struct {
+	background      imagecolor.NRGBA
+	bestCompression bool
+	quality         int
+}
+
+/*
+ * A configuration option for an encoder.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Composite the image over a background color before encoding it.
+ *
+ * Formats without transparency, i. e. JPEG and BMP, always composite the
+ * image over the background, which is itself composited over black if it is
+ * not fully opaque.
+ */
+func WithBackground(background imagecolor.NRGBA) Option {
+
+	/*
+	 * Set background.
+	 */
+	return func(config *configStruct) {
+		config.background = background
+	}
+
+}
+
+/*
+ * Compress as well as possible at the expense of speed.
+ *
+ * PNG images are encoded using the best compression level, while TIFF
+ * images are compressed using Deflate instead of being stored uncompressed.
+ */
+func WithBestCompression() Option {
+
+	/*
+	 * Enable best compression.
+	 */
+	return func(config *configStruct) {
+		config.bestCompression = true
+	}
+
+}
+
+/*
+ * Set the quality of JPEG images, ranging from 1 to 100.
+ */
+func WithQuality(quality int) Option {
+
+	/*
+	 * Set quality.
+	 */
+	return func(config *configStruct) {
+		config.quality = quality
+	}
+
+}
+
+/*
+ * Apply options to the default configuration.
+ */
+func configure(options []Option) configStruct {
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		quality: jpeg.DefaultQuality,
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	return config
+}
+
+/*
+ * Returns whether an image stores more than 8 bits per channel.
+ */
+func isDeep(img image.Image) bool {
+	model := img.ColorModel()
+	return (model == imagecolor.NRGBA64Model) || (model == imagecolor.RGBA64Model) || (model == imagecolor.Gray16Model)
+}
+
+/*
+ * Composite an image over a background color.
+ *
+ * Images with 16 bits per channel keep their precision.
+ */
+func composite(img image.Image, background imagecolor.NRGBA) image.Image {
+	rect := img.Bounds()
+	dst := draw.Image(nil)
+
+	/*
+	 * Decide on the precision of the result.
+	 */
+	if isDeep(img) {
+		dst = image.NewNRGBA64(rect)
+	} else {
+		dst = image.NewNRGBA(rect)
+	}
+
+	uniform := image.NewUniform(background)
+	draw.Draw(dst, rect, uniform, image.Point{}, draw.Src)
+	draw.Draw(dst, rect, img, rect.Min, draw.Over)
+	return dst
+}
+
+/*
+ * Returns the background color for formats without transparency, which is
+ * the configured background composited over black.
+ */
+func opaqueBackground(background imagecolor.NRGBA) imagecolor.NRGBA {
+	alpha := uint32(background.A)
+
+	/*
+	 * Scale a single channel by the opacity of the background.
+	 */
+	scale := func(value uint8) uint8 {
+		scaled := ((uint32(value) * alpha) + 127) / 255
+		return uint8(scaled)
+	}
+
+	/*
+	 * The resulting color.
+	 */
+	result := imagecolor.NRGBA{
+		R: scale(background.R),
+		G: scale(background.G),
+		B: scale(background.B),
+		A: 255,
+	}
+
+	return result
+}
+
+/*
+ * Convert an image into an image with 8-bit, non-premultiplied RGBA pixels,
+ * whose bounds start at the origin.
+ */
+func toNRGBA(img image.Image) *image.NRGBA {
+	rect := img.Bounds()
+	nrgba, ok := img.(*image.NRGBA)
+
+	/*
+	 * Convert image unless it already has the desired layout.
+	 */
+	if ok && (rect.Min == image.Point{}) {
+		return nrgba
+	} else {
+		width := rect.Dx()
+		height := rect.Dy()
+		target := image.Rect(0, 0, width, height)
+		result := image.NewNRGBA(target)
+		draw.Draw(result, target, img, rect.Min, draw.Src)
+		return result
+	}
+
+}
+
+/*
+ * Encode an image, e. g. rendered from a scene, as PNG and write it to w.
+ *
+ * Images with 16 bits per channel, e. g. rendered using Render16, are
+ * encoded with 16 bits per channel.
+ */
+func EncodePNG(w io.Writer, img image.Image, options ...Option) error {
+	config := configure(options)
+
+	/*
+	 * Composite image over background if needed.
+	 */
+	if config.background.A > 0 {
+		img = composite(img, config.background)
+	}
+
+	level := png.DefaultCompression
+
+	/*
+	 * Use best compression if requested.
+	 */
+	if config.bestCompression {
+		level = png.BestCompression
+	}
+
+	/*
+	 * The PNG encoder.
+	 */
+	enc := png.Encoder{
+		CompressionLevel: level,
+	}
+
+	err := enc.Encode(w, img)
+
+	/*
+	 * Check if image could be encoded.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to encode PNG image: %s", err.Error())
+	} else {
+		return nil
+	}
+
+}
+
+/*
+ * Encode an image, e. g. rendered from a scene, as JPEG and write it to w.
+ *
+ * Since JPEG does not support transparency, the image is composited over the
+ * background, which is black unless configured otherwise.
+ */
+func EncodeJPEG(w io.Writer, img image.Image, options ...Option) error {
+	config := configure(options)
+	quality := config.quality
+
+	/*
+	 * Verify that quality lies within valid range.
+	 */
+	if (quality < 1) || (quality > 100) {
+		return fmt.Errorf("JPEG quality must be between 1 and 100, but is %d.", quality)
+	} else {
+		background := opaqueBackground(config.background)
+		flattened := composite(img, background)
+
+		/*
+		 * The JPEG options.
+		 */
+		opts := jpeg.Options{
+			Quality: quality,
+		}
+
+		err := jpeg.Encode(w, flattened, &opts)
+
+		/*
+		 * Check if image could be encoded.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to encode JPEG image: %s", err.Error())
+		} else {
+			return nil
+		}
+
+	}
+
+}
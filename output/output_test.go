@@ -0,0 +1,412 @@
+package output
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	imagecolor "image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"testing"
+)
+
+/*
+ * Returns an image holding opaque, translucent and transparent pixels,
+ * whose bounds do not start at the origin.
+ */
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(10, 20, 13, 22))
+
+	/*
+	 * The pixels of the image, row by row.
+	 */
+	pixels := []imagecolor.NRGBA{
+		imagecolor.NRGBA{R: 255, G: 0, B: 0, A: 255},
+		imagecolor.NRGBA{R: 0, G: 255, B: 0, A: 255},
+		imagecolor.NRGBA{R: 0, G: 0, B: 255, A: 255},
+		imagecolor.NRGBA{R: 255, G: 255, B: 255, A: 0},
+		imagecolor.NRGBA{R: 200, G: 100, B: 50, A: 128},
+		imagecolor.NRGBA{R: 12, G: 34, B: 56, A: 255},
+	}
+
+	/*
+	 * Set each pixel.
+	 */
+	for i, pixel := range pixels {
+		img.SetNRGBA(10+(i%3), 20+(i/3), pixel)
+	}
+
+	return img
+}
+
+/*
+ * Returns whether a channel of a pixel composited over an opaque background
+ * matches, allowing for rounding.
+ */
+func blend(actual uint8, src uint8, alpha uint8, background uint8) bool {
+	expected := ((int(src) * int(alpha)) + (int(background) * (255 - int(alpha)))) / 255
+	difference := int(actual) - expected
+	return (difference >= -1) && (difference <= 1)
+}
+
+/*
+ * Data structure representing an entry of a TIFF image file directory as
+ * it is read.
+ */
+type readEntryStruct struct {
+	count    uint32
+	typeCode uint16
+	value    uint32
+}
+
+/*
+ * Encode an image as TIFF, with and without compression, and read it back.
+ */
+func TestEncodeTIFF(t *testing.T) {
+	img := testImage()
+
+	/*
+	 * Encode with and without compression.
+	 */
+	for _, compressed := range []bool{false, true} {
+		options := []Option{nil}
+
+		/*
+		 * Request compression if needed.
+		 */
+		if compressed {
+			options = append(options, WithBestCompression())
+		}
+
+		buf := bytes.Buffer{}
+		err := EncodeTIFF(&buf, img, options...)
+
+		/*
+		 * Check if image could be encoded.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		data := buf.Bytes()
+
+		/*
+		 * Check the header.
+		 */
+		if (string(data[0:2]) != "II") || (binary.LittleEndian.Uint16(data[2:4]) != 42) {
+			t.Fatalf("%s", "Invalid TIFF header.")
+		}
+
+		directory := int(binary.LittleEndian.Uint32(data[4:8]))
+		numEntries := int(binary.LittleEndian.Uint16(data[directory:]))
+		entries := map[uint16]readEntryStruct{}
+		lastTag := uint16(0)
+
+		/*
+		 * Read each entry of the image file directory.
+		 */
+		for i := 0; i < numEntries; i++ {
+			field := data[directory+2+(12*i):]
+			tag := binary.LittleEndian.Uint16(field[0:2])
+
+			/*
+			 * Create entry.
+			 */
+			entry := readEntryStruct{
+				count:    binary.LittleEndian.Uint32(field[4:8]),
+				typeCode: binary.LittleEndian.Uint16(field[2:4]),
+				value:    binary.LittleEndian.Uint32(field[8:12]),
+			}
+
+			/*
+			 * Single short values occupy the first two bytes.
+			 */
+			if (entry.typeCode == TIFF_TYPE_SHORT) && (entry.count == 1) {
+				entry.value = uint32(binary.LittleEndian.Uint16(field[8:10]))
+			}
+
+			/*
+			 * Entries must be sorted by tag.
+			 */
+			if tag <= lastTag {
+				t.Errorf("Tag %d follows tag %d.", tag, lastTag)
+			}
+
+			entries[tag] = entry
+			lastTag = tag
+		}
+
+		expectedCompression := uint32(TIFF_COMPRESSION_NONE)
+
+		/*
+		 * Compressed samples are marked.
+		 */
+		if compressed {
+			expectedCompression = TIFF_COMPRESSION_DEFLATE
+		}
+
+		/*
+		 * Check the entries describing the image.
+		 */
+		if (entries[256].value != 3) || (entries[257].value != 2) {
+			t.Errorf("Image is %dx%d, expected 3x2.", entries[256].value, entries[257].value)
+		} else if entries[259].value != expectedCompression {
+			t.Errorf("Compression is %d, expected %d.", entries[259].value, expectedCompression)
+		} else if (entries[277].value != 4) || (entries[338].value != 2) {
+			t.Errorf("%s", "Samples are not RGB with unassociated alpha.")
+		}
+
+		offset := entries[273].value
+		size := entries[279].value
+		strip := data[offset : offset+size]
+
+		/*
+		 * Decompress strip if needed.
+		 */
+		if compressed {
+			rd, err := zlib.NewReader(bytes.NewReader(strip))
+
+			/*
+			 * Read samples if stream could be opened.
+			 */
+			if err == nil {
+				strip, err = ioutil.ReadAll(rd)
+			}
+
+			/*
+			 * Check if samples could be decompressed.
+			 */
+			if err != nil {
+				t.Fatalf("%s", err.Error())
+			}
+
+		}
+
+		/*
+		 * Samples are stored unchanged.
+		 */
+		if !bytes.Equal(strip, img.Pix) {
+			t.Errorf("Samples are %v, expected %v.", strip, img.Pix)
+		}
+
+	}
+
+}
+
+/*
+ * Encode an image as BMP, compositing it over a background, and read it
+ * back.
+ */
+func TestEncodeBMP(t *testing.T) {
+	img := testImage()
+	background := imagecolor.NRGBA{R: 40, G: 80, B: 120, A: 255}
+	buf := bytes.Buffer{}
+	err := EncodeBMP(&buf, img, WithBackground(background))
+
+	/*
+	 * Check if image could be encoded.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	data := buf.Bytes()
+	info := data[BMP_FILE_HEADER_SIZE:]
+	pixelOffset := int(binary.LittleEndian.Uint32(data[10:14]))
+	width := int(binary.LittleEndian.Uint32(info[4:8]))
+	height := int(binary.LittleEndian.Uint32(info[8:12]))
+	rowSize := 12
+
+	/*
+	 * Check the headers.
+	 */
+	if string(data[0:2]) != "BM" {
+		t.Fatalf("%s", "Invalid BMP signature.")
+	} else if int(binary.LittleEndian.Uint32(data[2:6])) != len(data) {
+		t.Errorf("File size is %d, but file has %d bytes.", binary.LittleEndian.Uint32(data[2:6]), len(data))
+	} else if (width != 3) || (height != 2) {
+		t.Fatalf("Image is %dx%d, expected 3x2.", width, height)
+	} else if binary.LittleEndian.Uint16(info[14:16]) != 24 {
+		t.Errorf("Image has %d bits per pixel, expected 24.", binary.LittleEndian.Uint16(info[14:16]))
+	} else if len(data) != pixelOffset+(height*rowSize) {
+		t.Fatalf("File has %d bytes, expected %d.", len(data), pixelOffset+(height*rowSize))
+	}
+
+	/*
+	 * Compare each pixel.
+	 */
+	for i := 0; i < 6; i++ {
+		x := i % 3
+		y := i / 3
+		pos := pixelOffset + ((height - 1 - y) * rowSize) + (3 * x)
+		src := img.NRGBAAt(img.Rect.Min.X+x, img.Rect.Min.Y+y)
+		actual := imagecolor.NRGBA{R: data[pos+2], G: data[pos+1], B: data[pos], A: 255}
+
+		/*
+		 * Check if pixel matches.
+		 */
+		if !blend(actual.R, src.R, src.A, background.R) || !blend(actual.G, src.G, src.A, background.G) || !blend(actual.B, src.B, src.A, background.B) {
+			t.Errorf("Pixel %d is %v, expected %v composited over %v.", i, actual, src, background)
+		}
+
+	}
+
+}
+
+/*
+ * Encode images as PNG and decode them again.
+ */
+func TestEncodePNG(t *testing.T) {
+	img := testImage()
+	deep := image.NewNRGBA64(image.Rect(0, 0, 2, 1))
+	deep.SetNRGBA64(0, 0, imagecolor.NRGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xffff})
+	deep.SetNRGBA64(1, 0, imagecolor.NRGBA64{R: 0xffff, G: 0x0001, B: 0x8000, A: 0xffff})
+
+	/*
+	 * The images to encode.
+	 */
+	images := []image.Image{img, deep}
+
+	/*
+	 * Encode each image with default and best compression.
+	 */
+	for i, src := range images {
+
+		/*
+		 * Encode each image using each compression level.
+		 */
+		for _, option := range []Option{nil, WithBestCompression()} {
+			buf := bytes.Buffer{}
+			err := EncodePNG(&buf, src, option)
+
+			/*
+			 * Check if image could be encoded.
+			 */
+			if err != nil {
+				t.Fatalf("%s", err.Error())
+			}
+
+			decoded, err := png.Decode(&buf)
+
+			/*
+			 * Check if image could be decoded.
+			 */
+			if err != nil {
+				t.Fatalf("%s", err.Error())
+			}
+
+			rect := src.Bounds()
+			decodedRect := decoded.Bounds()
+
+			/*
+			 * Check the dimensions.
+			 */
+			if (decodedRect.Dx() != rect.Dx()) || (decodedRect.Dy() != rect.Dy()) {
+				t.Fatalf("Image %d is %v, expected %v.", i, decodedRect, rect)
+			}
+
+			/*
+			 * Compare each pixel.
+			 */
+			for y := 0; y < rect.Dy(); y++ {
+
+				/*
+				 * Compare each pixel of the row.
+				 */
+				for x := 0; x < rect.Dx(); x++ {
+					expected := imagecolor.NRGBA64Model.Convert(src.At(rect.Min.X+x, rect.Min.Y+y))
+					actual := imagecolor.NRGBA64Model.Convert(decoded.At(decodedRect.Min.X+x, decodedRect.Min.Y+y))
+
+					/*
+					 * Transparent pixels lose their color.
+					 */
+					if (actual != expected) && (expected.(imagecolor.NRGBA64).A != 0) {
+						t.Errorf("Pixel (%d, %d) of image %d is %v, expected %v.", x, y, i, actual, expected)
+					}
+
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Encode a uniform image as JPEG and decode it again.
+ */
+func TestEncodeJPEG(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	translucent := imagecolor.NRGBA{R: 255, G: 255, B: 255, A: 128}
+	background := imagecolor.NRGBA{R: 0, G: 0, B: 255, A: 255}
+
+	/*
+	 * Fill the image with a translucent color.
+	 */
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i] = translucent.R
+		img.Pix[i+1] = translucent.G
+		img.Pix[i+2] = translucent.B
+		img.Pix[i+3] = translucent.A
+	}
+
+	buf := bytes.Buffer{}
+	err := EncodeJPEG(&buf, img, WithBackground(background), WithQuality(100))
+
+	/*
+	 * Check if image could be encoded.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	decoded, err := jpeg.Decode(&buf)
+
+	/*
+	 * Check if image could be decoded.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	r, g, b, _ := decoded.At(8, 8).RGBA()
+	distance := func(a uint32, b uint32) uint32 {
+
+		/*
+		 * Compare in 8-bit precision.
+		 */
+		if (a >> 8) > b {
+			return (a >> 8) - b
+		} else {
+			return b - (a >> 8)
+		}
+
+	}
+
+	/*
+	 * White at half opacity over blue.
+	 */
+	if (distance(r, 128) > 3) || (distance(g, 128) > 3) || (distance(b, 255) > 3) {
+		t.Errorf("Pixel is (%d, %d, %d), expected (128, 128, 255).", r>>8, g>>8, b>>8)
+	}
+
+	/*
+	 * Qualities out of range are rejected.
+	 */
+	for _, quality := range []int{0, 101} {
+		err = EncodeJPEG(&buf, img, WithQuality(quality))
+
+		/*
+		 * Check if an error was returned.
+		 */
+		if err == nil {
+			t.Errorf("Expected an error for quality %d.", quality)
+		}
+
+	}
+
+}
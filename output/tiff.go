@@ -0,0 +1,225 @@
+package output
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"math"
+)
+
+/*
+ * Constants describing the TIFF files written.
+ */
+const (
+	TIFF_COMPRESSION_DEFLATE = 8
+	TIFF_COMPRESSION_NONE    = 1
+	TIFF_HEADER_SIZE         = 8
+	TIFF_NUM_ENTRIES         = 14
+	TIFF_TYPE_LONG           = 4
+	TIFF_TYPE_RATIONAL       = 5
+	TIFF_TYPE_SHORT          = 3
+)
+
+/*
+ * Data structure representing an entry of a TIFF image file directory.
+ */
+type tiffEntryStruct struct {
+	count    uint32
+	tag      uint16
+	typeCode uint16
+	value    uint32
+}
+
+/*
+ * Serialize the pixels of an image as 8-bit RGBA samples in a single strip,
+ * optionally compressed using Deflate.
+ */
+func tiffStrip(img *image.NRGBA, compressed bool) ([]byte, error) {
+	rect := img.Bounds()
+	width := rect.Dx()
+	height := rect.Dy()
+	rowSize := 4 * width
+	buf := bytes.Buffer{}
+	w := io.Writer(&buf)
+	zw := (*zlib.Writer)(nil)
+
+	/*
+	 * Compress samples if requested.
+	 */
+	if compressed {
+		zw, _ = zlib.NewWriterLevel(&buf, zlib.BestCompression)
+		w = zw
+	}
+
+	err := error(nil)
+
+	/*
+	 * Write each row.
+	 */
+	for y := 0; (y < height) && (err == nil); y++ {
+		offset := img.PixOffset(0, y)
+		row := img.Pix[offset : offset+rowSize]
+		_, err = w.Write(row)
+	}
+
+	/*
+	 * Finish compressed stream.
+	 */
+	if (zw != nil) && (err == nil) {
+		err = zw.Close()
+	}
+
+	/*
+	 * Check if samples could be written.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		return buf.Bytes(), nil
+	}
+
+}
+
+/*
+ * Encode an image, e. g. rendered from a scene, as baseline TIFF and write
+ * it to w.
+ *
+ * The image is stored as 8-bit RGB with unassociated alpha in a single
+ * strip, which is uncompressed unless best compression is requested.
+ */
+func EncodeTIFF(w io.Writer, img image.Image, options ...Option) error {
+	config := configure(options)
+
+	/*
+	 * Composite image over background if needed.
+	 */
+	if config.background.A > 0 {
+		img = composite(img, config.background)
+	}
+
+	nrgba := toNRGBA(img)
+	rect := nrgba.Bounds()
+	width := uint32(rect.Dx())
+	height := uint32(rect.Dy())
+	compressed := config.bestCompression
+	strip, err := tiffStrip(nrgba, compressed)
+
+	/*
+	 * Check if samples could be serialized.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to encode TIFF image: %s", err.Error())
+	} else {
+		directorySize := 2 + (12 * TIFF_NUM_ENTRIES) + 4
+		bitsOffset := TIFF_HEADER_SIZE + directorySize
+		resolutionXOffset := bitsOffset + 8
+		resolutionYOffset := resolutionXOffset + 8
+		stripOffset := resolutionYOffset + 8
+		stripSize := uint64(len(strip))
+
+		/*
+		 * Verify that offsets fit into the file format.
+		 */
+		if (uint64(stripOffset) + stripSize) > math.MaxUint32 {
+			return fmt.Errorf("%s", "Failed to encode TIFF image: Image is too large.")
+		} else {
+			compression := uint32(TIFF_COMPRESSION_NONE)
+
+			/*
+			 * Mark compressed samples.
+			 */
+			if compressed {
+				compression = TIFF_COMPRESSION_DEFLATE
+			}
+
+			/*
+			 * The entries of the image file directory, sorted by
+			 * tag.
+			 */
+			entries := []tiffEntryStruct{
+				tiffEntryStruct{tag: 256, typeCode: TIFF_TYPE_LONG, count: 1, value: width},
+				tiffEntryStruct{tag: 257, typeCode: TIFF_TYPE_LONG, count: 1, value: height},
+				tiffEntryStruct{tag: 258, typeCode: TIFF_TYPE_SHORT, count: 4, value: uint32(bitsOffset)},
+				tiffEntryStruct{tag: 259, typeCode: TIFF_TYPE_SHORT, count: 1, value: compression},
+				tiffEntryStruct{tag: 262, typeCode: TIFF_TYPE_SHORT, count: 1, value: 2},
+				tiffEntryStruct{tag: 273, typeCode: TIFF_TYPE_LONG, count: 1, value: uint32(stripOffset)},
+				tiffEntryStruct{tag: 277, typeCode: TIFF_TYPE_SHORT, count: 1, value: 4},
+				tiffEntryStruct{tag: 278, typeCode: TIFF_TYPE_LONG, count: 1, value: height},
+				tiffEntryStruct{tag: 279, typeCode: TIFF_TYPE_LONG, count: 1, value: uint32(stripSize)},
+				tiffEntryStruct{tag: 282, typeCode: TIFF_TYPE_RATIONAL, count: 1, value: uint32(resolutionXOffset)},
+				tiffEntryStruct{tag: 283, typeCode: TIFF_TYPE_RATIONAL, count: 1, value: uint32(resolutionYOffset)},
+				tiffEntryStruct{tag: 284, typeCode: TIFF_TYPE_SHORT, count: 1, value: 1},
+				tiffEntryStruct{tag: 296, typeCode: TIFF_TYPE_SHORT, count: 1, value: 2},
+				tiffEntryStruct{tag: 338, typeCode: TIFF_TYPE_SHORT, count: 1, value: 2},
+			}
+
+			header := make([]byte, stripOffset)
+			copy(header[0:2], "II")
+			binary.LittleEndian.PutUint16(header[2:4], 42)
+			binary.LittleEndian.PutUint32(header[4:8], TIFF_HEADER_SIZE)
+			binary.LittleEndian.PutUint16(header[8:10], TIFF_NUM_ENTRIES)
+
+			/*
+			 * Serialize each entry.
+			 */
+			for i, entry := range entries {
+				offset := TIFF_HEADER_SIZE + 2 + (12 * i)
+				field := header[offset : offset+12]
+				binary.LittleEndian.PutUint16(field[0:2], entry.tag)
+				binary.LittleEndian.PutUint16(field[2:4], entry.typeCode)
+				binary.LittleEndian.PutUint32(field[4:8], entry.count)
+
+				/*
+				 * Single short values are stored in the first
+				 * two bytes of the value field.
+				 */
+				if (entry.typeCode == TIFF_TYPE_SHORT) && (entry.count == 1) {
+					binary.LittleEndian.PutUint16(field[8:10], uint16(entry.value))
+				} else {
+					binary.LittleEndian.PutUint32(field[8:12], entry.value)
+				}
+
+			}
+
+			/*
+			 * Eight bits for each of the four samples.
+			 */
+			for i := 0; i < 4; i++ {
+				offset := bitsOffset + (2 * i)
+				binary.LittleEndian.PutUint16(header[offset:offset+2], 8)
+			}
+
+			/*
+			 * Resolution of 72 pixels per inch in both directions.
+			 */
+			for _, offset := range []int{resolutionXOffset, resolutionYOffset} {
+				binary.LittleEndian.PutUint32(header[offset:offset+4], 72)
+				binary.LittleEndian.PutUint32(header[offset+4:offset+8], 1)
+			}
+
+			_, err = w.Write(header)
+
+			/*
+			 * Write samples after header.
+			 */
+			if err == nil {
+				_, err = w.Write(strip)
+			}
+
+			/*
+			 * Check if image could be written.
+			 */
+			if err != nil {
+				return fmt.Errorf("Failed to encode TIFF image: %s", err.Error())
+			} else {
+				return nil
+			}
+
+		}
+
+	}
+
+}
@@ -0,0 +1,1762 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+	"time"
+)
+
+/*
+ * Magic number at the start and end of a Parquet file.
+ */
+const (
+	PARQUET_MAGIC = "PAR1"
+)
+
+/*
+ * Physical types of columns.
+ */
+const (
+	TYPE_BOOLEAN              = 0
+	TYPE_INT32                = 1
+	TYPE_INT64                = 2
+	TYPE_INT96                = 3
+	TYPE_FLOAT                = 4
+	TYPE_DOUBLE               = 5
+	TYPE_BYTE_ARRAY           = 6
+	TYPE_FIXED_LEN_BYTE_ARRAY = 7
+)
+
+/*
+ * Converted types relevant for time columns.
+ */
+const (
+	CONVERTED_DATE             = 6
+	CONVERTED_TIMESTAMP_MILLIS = 9
+	CONVERTED_TIMESTAMP_MICROS = 10
+)
+
+/*
+ * Repetition types of fields.
+ */
+const (
+	REPETITION_REQUIRED = 0
+	REPETITION_OPTIONAL = 1
+	REPETITION_REPEATED = 2
+)
+
+/*
+ * Compression codecs.
+ */
+const (
+	CODEC_UNCOMPRESSED = 0
+	CODEC_SNAPPY       = 1
+	CODEC_GZIP         = 2
+)
+
+/*
+ * Encodings of values.
+ */
+const (
+	ENCODING_PLAIN            = 0
+	ENCODING_PLAIN_DICTIONARY = 2
+	ENCODING_RLE              = 3
+	ENCODING_RLE_DICTIONARY   = 8
+)
+
+/*
+ * Types of pages.
+ */
+const (
+	PAGE_DATA       = 0
+	PAGE_DICTIONARY = 2
+	PAGE_DATA_V2    = 3
+)
+
+/*
+ * Limits protecting against corrupt files.
+ */
+const (
+	MAX_FOOTER_SIZE = 64 * 1024 * 1024
+	MAX_PAGE_SIZE   = 256 * 1024 * 1024
+)
+
+/*
+ * The number of rows aggregated at once.
+ */
+const (
+	BATCH_SIZE = 65536
+)
+
+/*
+ * Data structure representing a row read from a Parquet file.
+ *
+ * Records are immutable.
+ */
+type Record struct {
+	timestamp time.Time
+	weight    float64
+	x         float64
+	y         float64
+}
+
+/*
+ * Returns the time of this record or the zero time if no time column was
+ * configured or the value is null.
+ */
+func (this *Record) Time() time.Time {
+	return this.timestamp
+}
+
+/*
+ * Returns the weight of this record or one if no weight column was
+ * configured or the value is null.
+ */
+func (this *Record) Weight() float64 {
+	return this.weight
+}
+
+/*
+ * Returns the x-coordinate or longitude (in degrees) of this record.
+ */
+func (this *Record) X() float64 {
+	return this.x
+}
+
+/*
+ * Returns the y-coordinate or latitude (in degrees) of this record.
+ */
+func (this *Record) Y() float64 {
+	return this.y
+}
+
+/*
+ * Data structure representing the configuration of a reader.
+ */
+type configStruct struct {
+	projection projection.Projection
+	time       string
+	weight     string
+	x          string
+	y          string
+}
+
+/*
+ * A configuration option for a reader.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Select the columns holding the x- and y-coordinates (or longitude and
+ * latitude) by name. Fields of nested groups are named by their path, joined
+ * by dots, e. g. "position.lon".
+ */
+func WithCoordinates(x string, y string) Option {
+
+	/*
+	 * Set coordinate columns.
+	 */
+	return func(config *configStruct) {
+		config.x = x
+		config.y = y
+	}
+
+}
+
+/*
+ * Project longitudes and latitudes (in degrees) when aggregating records
+ * into a scene.
+ */
+func WithProjection(proj projection.Projection) Option {
+
+	/*
+	 * Set projection.
+	 */
+	return func(config *configStruct) {
+		config.projection = proj
+	}
+
+}
+
+/*
+ * Select the column holding the time of each record by name.
+ *
+ * The column must hold integers. Timestamps annotated as milliseconds,
+ * microseconds or nanoseconds and dates are converted accordingly, other
+ * integers are interpreted as seconds since the epoch.
+ */
+func WithTime(name string) Option {
+
+	/*
+	 * Set time column.
+	 */
+	return func(config *configStruct) {
+		config.time = name
+	}
+
+}
+
+/*
+ * Select the column holding the weight of each record by name.
+ */
+func WithWeight(name string) Option {
+
+	/*
+	 * Set weight column.
+	 */
+	return func(config *configStruct) {
+		config.weight = name
+	}
+
+}
+
+/*
+ * A reader reads records from a Parquet file.
+ */
+type Reader interface {
+	Aggregate(scn scene.Scene) error
+	NumRows() int64
+	Read() (Record, error)
+}
+
+/*
+ * Data structure representing a leaf column of the schema.
+ */
+type columnStruct struct {
+	maxDefinition int
+	maxRepetition int
+	name          string
+	physicalType  int64
+	unit          time.Duration
+}
+
+/*
+ * Data structure representing the location of a column chunk within a file.
+ */
+type chunkStruct struct {
+	codec            int64
+	dataOffset       int64
+	dictionaryOffset int64
+	name             string
+	numValues        int64
+	size             int64
+}
+
+/*
+ * Data structure representing a row group.
+ */
+type rowGroupStruct struct {
+	chunks  map[string]chunkStruct
+	numRows int64
+}
+
+/*
+ * Data structure representing the decoded values of a column chunk.
+ *
+ * Values are stored as raw bits, which are interpreted according to the
+ * physical type of the column.
+ */
+type valuesStruct struct {
+	valid  []bool
+	values []uint64
+}
+
+/*
+ * Data structure representing a reader.
+ */
+type readerStruct struct {
+	columns   map[string]columnStruct
+	config    configStruct
+	file      io.ReaderAt
+	numRows   int64
+	records   []Record
+	rowGroup  int
+	rowGroups []rowGroupStruct
+	size      int64
+}
+
+/*
+ * Interpret raw bits as a floating-point number.
+ */
+func (this *columnStruct) float(raw uint64) float64 {
+
+	/*
+	 * Decide on the physical type.
+	 */
+	switch this.physicalType {
+	case TYPE_INT32:
+		return float64(int32(raw))
+	case TYPE_INT64:
+		return float64(int64(raw))
+	case TYPE_FLOAT:
+		return float64(math.Float32frombits(uint32(raw)))
+	default:
+		return math.Float64frombits(raw)
+	}
+
+}
+
+/*
+ * Interpret raw bits as a time.
+ */
+func (this *columnStruct) time(raw uint64) time.Time {
+	value := int64(raw)
+
+	/*
+	 * Sign-extend 32-bit integers.
+	 */
+	if this.physicalType == TYPE_INT32 {
+		value = int64(int32(raw))
+	}
+
+	/*
+	 * Large units may exceed the range of a duration.
+	 */
+	if this.unit >= time.Second {
+		seconds := int64(this.unit/time.Second) * value
+		return time.Unix(seconds, 0).UTC()
+	} else {
+		return time.Unix(0, int64(this.unit)*value).UTC()
+	}
+
+}
+
+/*
+ * Decode a time unit from a logical type annotation.
+ */
+func readTimeUnit(t *thriftStruct, unit *time.Duration) error {
+
+	/*
+	 * The time unit is a union of empty structs.
+	 */
+	return t.readStruct(func(id int16, fieldType byte) error {
+
+		/*
+		 * Decide on the unit.
+		 */
+		switch id {
+		case 1:
+			*unit = time.Millisecond
+		case 2:
+			*unit = time.Microsecond
+		case 3:
+			*unit = time.Nanosecond
+		}
+
+		return t.skip(fieldType)
+	})
+
+}
+
+/*
+ * Decode a logical type annotation, only the time unit of timestamps and
+ * dates are of interest.
+ */
+func readLogicalType(t *thriftStruct, unit *time.Duration) error {
+
+	/*
+	 * The logical type is a union of structs.
+	 */
+	return t.readStruct(func(id int16, fieldType byte) error {
+
+		/*
+		 * Decide on the logical type.
+		 */
+		switch id {
+		case 6:
+			*unit = 24 * time.Hour
+			return t.skip(fieldType)
+		case 8:
+
+			/*
+			 * Decode timestamp annotation.
+			 */
+			return t.readStruct(func(id int16, fieldType byte) error {
+
+				/*
+				 * Only the unit is of interest.
+				 */
+				if id == 2 {
+					return readTimeUnit(t, unit)
+				} else {
+					return t.skip(fieldType)
+				}
+
+			})
+
+		default:
+			return t.skip(fieldType)
+		}
+
+	})
+
+}
+
+/*
+ * Data structure representing an element of the schema.
+ */
+type schemaElementStruct struct {
+	name         string
+	numChildren  int64
+	physicalType int64
+	repetition   int64
+	unit         time.Duration
+}
+
+/*
+ * Decode an element of the schema.
+ */
+func readSchemaElement(t *thriftStruct) (schemaElementStruct, error) {
+
+	/*
+	 * Defaults for integer columns without annotation.
+	 */
+	element := schemaElementStruct{
+		physicalType: -1,
+		unit:         time.Second,
+	}
+
+	/*
+	 * Decode all fields.
+	 */
+	err := t.readStruct(func(id int16, fieldType byte) error {
+		err := error(nil)
+
+		/*
+		 * Decide on the field.
+		 */
+		switch id {
+		case 1:
+			element.physicalType, err = t.readInt()
+		case 3:
+			element.repetition, err = t.readInt()
+		case 4:
+			name := []byte{}
+			name, err = t.readBinary()
+			element.name = string(name)
+		case 5:
+			element.numChildren, err = t.readInt()
+		case 6:
+			converted := int64(0)
+			converted, err = t.readInt()
+
+			/*
+			 * Decide on the unit of time columns.
+			 */
+			switch converted {
+			case CONVERTED_DATE:
+				element.unit = 24 * time.Hour
+			case CONVERTED_TIMESTAMP_MILLIS:
+				element.unit = time.Millisecond
+			case CONVERTED_TIMESTAMP_MICROS:
+				element.unit = time.Microsecond
+			}
+
+		case 10:
+			err = readLogicalType(t, &element.unit)
+		default:
+			err = t.skip(fieldType)
+		}
+
+		return err
+	})
+
+	return element, err
+}
+
+/*
+ * Derive the leaf columns from the flattened schema, which lists the
+ * elements in depth-first order, starting with the root.
+ */
+func flattenSchema(elements []schemaElementStruct) (map[string]columnStruct, error) {
+	columns := make(map[string]columnStruct)
+	pos := 0
+
+	/*
+	 * Visit an element and its children.
+	 */
+	var visit func(path []string, maxDefinition int, maxRepetition int) error
+
+	visit = func(path []string, maxDefinition int, maxRepetition int) error {
+
+		/*
+		 * Check if element exists.
+		 */
+		if pos >= len(elements) {
+			return fmt.Errorf("%s", "Truncated schema.")
+		}
+
+		element := elements[pos]
+		pos++
+
+		/*
+		 * The root is not part of the path.
+		 */
+		if pos > 1 {
+			path = append(path, element.name)
+
+			/*
+			 * Optional and repeated fields add levels.
+			 */
+			switch element.repetition {
+			case REPETITION_OPTIONAL:
+				maxDefinition++
+			case REPETITION_REPEATED:
+				maxDefinition++
+				maxRepetition++
+			}
+
+		}
+
+		/*
+		 * Groups have children, columns do not.
+		 */
+		if element.numChildren > 0 {
+
+			/*
+			 * Visit each child.
+			 */
+			for i := int64(0); i < element.numChildren; i++ {
+				err := visit(path, maxDefinition, maxRepetition)
+
+				/*
+				 * Check if child could be visited.
+				 */
+				if err != nil {
+					return err
+				}
+
+			}
+
+		} else {
+			name := strings.Join(path, ".")
+
+			/*
+			 * Create column.
+			 */
+			column := columnStruct{
+				maxDefinition: maxDefinition,
+				maxRepetition: maxRepetition,
+				name:          name,
+				physicalType:  element.physicalType,
+				unit:          element.unit,
+			}
+
+			columns[name] = column
+		}
+
+		return nil
+	}
+
+	err := visit([]string{}, 0, 0)
+	return columns, err
+}
+
+/*
+ * Decode the metadata of a column chunk.
+ */
+func readColumnMetaData(t *thriftStruct, chunk *chunkStruct) error {
+
+	/*
+	 * Decode all fields.
+	 */
+	return t.readStruct(func(id int16, fieldType byte) error {
+		err := error(nil)
+
+		/*
+		 * Decide on the field.
+		 */
+		switch id {
+		case 3:
+			path := []string{}
+
+			/*
+			 * Decode each component of the path.
+			 */
+			err = t.readList(func(elementType byte) error {
+				component, err := t.readBinary()
+				path = append(path, string(component))
+				return err
+			})
+
+			chunk.name = strings.Join(path, ".")
+		case 4:
+			chunk.codec, err = t.readInt()
+		case 5:
+			chunk.numValues, err = t.readInt()
+		case 7:
+			chunk.size, err = t.readInt()
+		case 9:
+			chunk.dataOffset, err = t.readInt()
+		case 11:
+			chunk.dictionaryOffset, err = t.readInt()
+		default:
+			err = t.skip(fieldType)
+		}
+
+		return err
+	})
+
+}
+
+/*
+ * Decode a row group.
+ */
+func readRowGroup(t *thriftStruct) (rowGroupStruct, error) {
+
+	/*
+	 * Create row group.
+	 */
+	group := rowGroupStruct{
+		chunks: make(map[string]chunkStruct),
+	}
+
+	/*
+	 * Decode all fields.
+	 */
+	err := t.readStruct(func(id int16, fieldType byte) error {
+		err := error(nil)
+
+		/*
+		 * Decide on the field.
+		 */
+		switch id {
+		case 1:
+
+			/*
+			 * Decode each column chunk.
+			 */
+			err = t.readList(func(elementType byte) error {
+				chunk := chunkStruct{}
+
+				/*
+				 * Decode all fields of the column chunk.
+				 */
+				err := t.readStruct(func(id int16, fieldType byte) error {
+
+					/*
+					 * Chunks stored in other files are not supported.
+					 */
+					switch id {
+					case 1:
+						return fmt.Errorf("%s", "Column chunks in external files are not supported.")
+					case 3:
+						return readColumnMetaData(t, &chunk)
+					default:
+						return t.skip(fieldType)
+					}
+
+				})
+
+				group.chunks[chunk.name] = chunk
+				return err
+			})
+
+		case 3:
+			group.numRows, err = t.readInt()
+		default:
+			err = t.skip(fieldType)
+		}
+
+		return err
+	})
+
+	return group, err
+}
+
+/*
+ * Decompress a page.
+ */
+func decompress(codec int64, data []byte) ([]byte, error) {
+
+	/*
+	 * Decide on the codec.
+	 */
+	switch codec {
+	case CODEC_UNCOMPRESSED:
+		return data, nil
+	case CODEC_SNAPPY:
+		return decodeSnappy(data)
+	case CODEC_GZIP:
+		rd, err := gzip.NewReader(bytes.NewReader(data))
+
+		/*
+		 * Check if stream could be opened.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			defer rd.Close()
+			limited := io.LimitReader(rd, MAX_PAGE_SIZE)
+			return ioutil.ReadAll(limited)
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported compression codec: %d", codec)
+	}
+
+}
+
+/*
+ * Decode values stored using the hybrid of run-length encoding and
+ * bit-packing, appending count values to dst.
+ */
+func decodeHybrid(data []byte, bitWidth int, count int, dst []uint64) ([]uint64, error) {
+	target := len(dst) + count
+	mask := (uint64(1) << uint(bitWidth)) - 1
+	byteWidth := (bitWidth + 7) / 8
+
+	/*
+	 * Decode runs until all values are decoded.
+	 */
+	for len(dst) < target {
+		header, n := binary.Uvarint(data)
+
+		/*
+		 * Check if header could be decoded.
+		 */
+		if n <= 0 {
+			return nil, fmt.Errorf("%s", "Truncated run-length encoded data.")
+		}
+
+		data = data[n:]
+
+		/*
+		 * The lowest bit decides between run-length encoding and
+		 * bit-packing.
+		 */
+		if (header & 1) == 0 {
+			run := int(header >> 1)
+
+			/*
+			 * Check if value is complete.
+			 */
+			if len(data) < byteWidth {
+				return nil, fmt.Errorf("%s", "Truncated run-length encoded data.")
+			}
+
+			value := uint64(0)
+
+			/*
+			 * The value is stored in little-endian order.
+			 */
+			for i := byteWidth - 1; i >= 0; i-- {
+				value = (value << 8) | uint64(data[i])
+			}
+
+			data = data[byteWidth:]
+
+			/*
+			 * Repeat the value.
+			 */
+			for i := 0; (i < run) && (len(dst) < target); i++ {
+				dst = append(dst, value)
+			}
+
+		} else {
+			numValues := int(header>>1) * 8
+			numBytes := int(header>>1) * bitWidth
+
+			/*
+			 * Trailing bytes may be left out at the end of the data.
+			 */
+			if numBytes > len(data) {
+				numBytes = len(data)
+			}
+
+			packed := data[:numBytes]
+			data = data[numBytes:]
+			acc := uint64(0)
+			bits := 0
+
+			/*
+			 * Values are packed starting with the least significant bit.
+			 */
+			for i := 0; (i < numValues) && (len(dst) < target); i++ {
+
+				/*
+				 * Fill the accumulator.
+				 */
+				for bits < bitWidth {
+
+					/*
+					 * Check if data is available.
+					 */
+					if len(packed) == 0 {
+						return nil, fmt.Errorf("%s", "Truncated bit-packed data.")
+					}
+
+					acc |= uint64(packed[0]) << uint(bits)
+					packed = packed[1:]
+					bits += 8
+				}
+
+				dst = append(dst, acc&mask)
+				acc >>= uint(bitWidth)
+				bits -= bitWidth
+			}
+
+		}
+
+	}
+
+	return dst, nil
+}
+
+/*
+ * Returns the number of bits needed to store values up to a maximum.
+ */
+func bitWidth(max int) int {
+	width := 0
+
+	/*
+	 * Count bits.
+	 */
+	for max > 0 {
+		width++
+		max >>= 1
+	}
+
+	return width
+}
+
+/*
+ * Decode plain-encoded values, appending count values to dst.
+ */
+func (this *columnStruct) decodePlain(data []byte, count int, dst []uint64) ([]uint64, error) {
+	size := 8
+
+	/*
+	 * Decide on the size of values.
+	 */
+	if (this.physicalType == TYPE_INT32) || (this.physicalType == TYPE_FLOAT) {
+		size = 4
+	}
+
+	/*
+	 * Check if values are complete.
+	 */
+	if len(data) < count*size {
+		return nil, fmt.Errorf("Truncated values in column '%s'.", this.name)
+	} else {
+
+		/*
+		 * Decode each value.
+		 */
+		for i := 0; i < count; i++ {
+			offset := i * size
+
+			/*
+			 * Values are stored in little-endian order.
+			 */
+			if size == 4 {
+				dst = append(dst, uint64(binary.LittleEndian.Uint32(data[offset:])))
+			} else {
+				dst = append(dst, binary.LittleEndian.Uint64(data[offset:]))
+			}
+
+		}
+
+		return dst, nil
+	}
+
+}
+
+/*
+ * Decode the values of a data page, appending them to result.
+ *
+ * Null values are stored as zero and marked as invalid.
+ */
+func (this *columnStruct) decodeValues(levels []byte, data []byte, numValues int, encoding int64, dictionary []uint64, result *valuesStruct) error {
+	definitions := []uint64(nil)
+	numPresent := numValues
+
+	/*
+	 * Decode definition levels of optional columns.
+	 */
+	if this.maxDefinition > 0 {
+		err := error(nil)
+		width := bitWidth(this.maxDefinition)
+		definitions, err = decodeHybrid(levels, width, numValues, nil)
+
+		/*
+		 * Check if definition levels could be decoded.
+		 */
+		if err != nil {
+			return err
+		}
+
+		numPresent = 0
+
+		/*
+		 * Count values which are present.
+		 */
+		for _, level := range definitions {
+
+			/*
+			 * Only values at the maximum level are present.
+			 */
+			if level == uint64(this.maxDefinition) {
+				numPresent++
+			}
+
+		}
+
+	}
+
+	present := []uint64(nil)
+	err := error(nil)
+
+	/*
+	 * Decide on the encoding.
+	 */
+	switch encoding {
+	case ENCODING_PLAIN:
+		present, err = this.decodePlain(data, numPresent, nil)
+	case ENCODING_PLAIN_DICTIONARY, ENCODING_RLE_DICTIONARY:
+
+		/*
+		 * Check if dictionary and bit width are present.
+		 */
+		if dictionary == nil {
+			return fmt.Errorf("Dictionary missing in column '%s'.", this.name)
+		} else if (numPresent > 0) && (len(data) < 1) {
+			return fmt.Errorf("Truncated values in column '%s'.", this.name)
+		} else if numPresent > 0 {
+			width := int(data[0])
+
+			/*
+			 * Check if bit width is plausible.
+			 */
+			if width > 32 {
+				return fmt.Errorf("Invalid bit width in column '%s': %d", this.name, width)
+			}
+
+			present, err = decodeHybrid(data[1:], width, numPresent, nil)
+
+			/*
+			 * Look up each value in the dictionary.
+			 */
+			for i, idx := range present {
+
+				/*
+				 * Check if index is in range.
+				 */
+				if idx >= uint64(len(dictionary)) {
+					return fmt.Errorf("Dictionary index out of range in column '%s': %d", this.name, idx)
+				}
+
+				present[i] = dictionary[idx]
+			}
+
+		}
+
+	default:
+		return fmt.Errorf("Unsupported encoding in column '%s': %d", this.name, encoding)
+	}
+
+	/*
+	 * Check if values could be decoded.
+	 */
+	if err != nil {
+		return err
+	} else if definitions == nil {
+		result.values = append(result.values, present...)
+
+		/*
+		 * All values are present.
+		 */
+		for i := 0; i < numValues; i++ {
+			result.valid = append(result.valid, true)
+		}
+
+	} else {
+		pos := 0
+
+		/*
+		 * Distribute present values across rows.
+		 */
+		for _, level := range definitions {
+
+			/*
+			 * Only values at the maximum level are present.
+			 */
+			if level == uint64(this.maxDefinition) {
+				result.values = append(result.values, present[pos])
+				result.valid = append(result.valid, true)
+				pos++
+			} else {
+				result.values = append(result.values, 0)
+				result.valid = append(result.valid, false)
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
+/*
+ * Data structure representing the header of a page.
+ */
+type pageHeaderStruct struct {
+	compressedSize    int64
+	definitionsLength int64
+	encoding          int64
+	isCompressed      bool
+	numValues         int64
+	pageType          int64
+	repetitionsLength int64
+	uncompressedSize  int64
+}
+
+/*
+ * Decode the header of a data page or dictionary page.
+ */
+func readPageDetails(t *thriftStruct, header *pageHeaderStruct) error {
+
+	/*
+	 * Decode all fields.
+	 */
+	return t.readStruct(func(id int16, fieldType byte) error {
+		err := error(nil)
+
+		/*
+		 * Fields of the headers of different types of pages are numbered
+		 * differently.
+		 */
+		switch {
+		case id == 1:
+			header.numValues, err = t.readInt()
+		case (id == 2) && (header.pageType != PAGE_DATA_V2):
+			header.encoding, err = t.readInt()
+		case (id == 4) && (header.pageType == PAGE_DATA_V2):
+			header.encoding, err = t.readInt()
+		case (id == 5) && (header.pageType == PAGE_DATA_V2):
+			header.definitionsLength, err = t.readInt()
+		case (id == 6) && (header.pageType == PAGE_DATA_V2):
+			header.repetitionsLength, err = t.readInt()
+		case (id == 7) && (header.pageType == PAGE_DATA_V2):
+			header.isCompressed = t.readBool(fieldType)
+		default:
+			err = t.skip(fieldType)
+		}
+
+		return err
+	})
+
+}
+
+/*
+ * Decode the header of a page.
+ */
+func readPageHeader(t *thriftStruct) (pageHeaderStruct, error) {
+
+	/*
+	 * Pages of version 2 are compressed by default.
+	 */
+	header := pageHeaderStruct{
+		isCompressed: true,
+	}
+
+	/*
+	 * Decode all fields.
+	 */
+	err := t.readStruct(func(id int16, fieldType byte) error {
+		err := error(nil)
+
+		/*
+		 * Decide on the field.
+		 */
+		switch id {
+		case 1:
+			header.pageType, err = t.readInt()
+		case 2:
+			header.uncompressedSize, err = t.readInt()
+		case 3:
+			header.compressedSize, err = t.readInt()
+		case 5, 7, 8:
+			err = readPageDetails(t, &header)
+		default:
+			err = t.skip(fieldType)
+		}
+
+		return err
+	})
+
+	return header, err
+}
+
+/*
+ * Read and decode all values of a column chunk.
+ */
+func (this *readerStruct) readChunk(column *columnStruct, chunk *chunkStruct) (valuesStruct, error) {
+	start := chunk.dataOffset
+
+	/*
+	 * The dictionary page precedes the data pages.
+	 */
+	if (chunk.dictionaryOffset > 0) && (chunk.dictionaryOffset < start) {
+		start = chunk.dictionaryOffset
+	}
+
+	/*
+	 * Check if chunk lies within the file.
+	 */
+	if (start < 0) || (chunk.size < 0) || (start+chunk.size > this.size) {
+		return valuesStruct{}, fmt.Errorf("Column chunk '%s' exceeds file.", column.name)
+	}
+
+	buf := make([]byte, chunk.size)
+	_, err := this.file.ReadAt(buf, start)
+
+	/*
+	 * Check if chunk could be read.
+	 */
+	if err != nil {
+		return valuesStruct{}, err
+	}
+
+	result := valuesStruct{}
+	dictionary := []uint64(nil)
+
+	/*
+	 * Decode pages until all values are decoded.
+	 */
+	for (int64(len(result.values)) < chunk.numValues) && (len(buf) > 0) {
+		t := thriftStruct{buf: buf}
+		header, err := readPageHeader(&t)
+
+		/*
+		 * Check if page header could be decoded.
+		 */
+		if err != nil {
+			return valuesStruct{}, err
+		}
+
+		buf = buf[t.pos:]
+
+		/*
+		 * Check if page is complete.
+		 */
+		if (header.compressedSize < 0) || (header.compressedSize > int64(len(buf))) {
+			return valuesStruct{}, fmt.Errorf("Truncated page in column '%s'.", column.name)
+		} else if header.uncompressedSize > MAX_PAGE_SIZE {
+			return valuesStruct{}, fmt.Errorf("Page too large in column '%s': %d bytes", column.name, header.uncompressedSize)
+		}
+
+		page := buf[:header.compressedSize]
+		buf = buf[header.compressedSize:]
+		numValues := int(header.numValues)
+
+		/*
+		 * Decide on the type of page, index pages are skipped.
+		 */
+		switch header.pageType {
+		case PAGE_DICTIONARY:
+			data, err := decompress(chunk.codec, page)
+
+			/*
+			 * Check if page could be decompressed.
+			 */
+			if err != nil {
+				return valuesStruct{}, err
+			}
+
+			dictionary, err = column.decodePlain(data, numValues, nil)
+
+			/*
+			 * Check if dictionary could be decoded.
+			 */
+			if err != nil {
+				return valuesStruct{}, err
+			}
+
+		case PAGE_DATA:
+			data, err := decompress(chunk.codec, page)
+
+			/*
+			 * Check if page could be decompressed.
+			 */
+			if err != nil {
+				return valuesStruct{}, err
+			}
+
+			levels := []byte(nil)
+
+			/*
+			 * Definition levels are prefixed with their length.
+			 */
+			if column.maxDefinition > 0 {
+
+				/*
+				 * Check if length is present.
+				 */
+				if len(data) < 4 {
+					return valuesStruct{}, fmt.Errorf("Truncated page in column '%s'.", column.name)
+				}
+
+				length := binary.LittleEndian.Uint32(data)
+				data = data[4:]
+
+				/*
+				 * Check if definition levels are complete.
+				 */
+				if uint64(length) > uint64(len(data)) {
+					return valuesStruct{}, fmt.Errorf("Truncated page in column '%s'.", column.name)
+				}
+
+				levels = data[:length]
+				data = data[length:]
+			}
+
+			err = column.decodeValues(levels, data, numValues, header.encoding, dictionary, &result)
+
+			/*
+			 * Check if values could be decoded.
+			 */
+			if err != nil {
+				return valuesStruct{}, err
+			}
+
+		case PAGE_DATA_V2:
+			levelsLength := header.repetitionsLength + header.definitionsLength
+
+			/*
+			 * Check if levels are complete.
+			 */
+			if (header.repetitionsLength < 0) || (header.definitionsLength < 0) || (levelsLength > int64(len(page))) {
+				return valuesStruct{}, fmt.Errorf("Truncated page in column '%s'.", column.name)
+			}
+
+			levels := page[header.repetitionsLength:levelsLength]
+			data := page[levelsLength:]
+
+			/*
+			 * Levels are never compressed, values may be.
+			 */
+			if header.isCompressed {
+				data, err = decompress(chunk.codec, data)
+
+				/*
+				 * Check if values could be decompressed.
+				 */
+				if err != nil {
+					return valuesStruct{}, err
+				}
+
+			}
+
+			err = column.decodeValues(levels, data, numValues, header.encoding, dictionary, &result)
+
+			/*
+			 * Check if values could be decoded.
+			 */
+			if err != nil {
+				return valuesStruct{}, err
+			}
+
+		}
+
+	}
+
+	/*
+	 * Check if all values were decoded.
+	 */
+	if int64(len(result.values)) != chunk.numValues {
+		return valuesStruct{}, fmt.Errorf("Column '%s' holds %d values, expected %d.", column.name, len(result.values), chunk.numValues)
+	} else {
+		return result, nil
+	}
+
+}
+
+/*
+ * Decode the values of a configured column within the current row group.
+ *
+ * Returns nil if the column is not configured.
+ */
+func (this *readerStruct) readColumn(group *rowGroupStruct, name string) (*valuesStruct, error) {
+
+	/*
+	 * Check if column is configured.
+	 */
+	if name == "" {
+		return nil, nil
+	} else {
+		column := this.columns[name]
+		chunk, ok := group.chunks[name]
+
+		/*
+		 * Check if row group holds the column.
+		 */
+		if !ok {
+			return nil, fmt.Errorf("Row group does not hold column '%s'.", name)
+		} else if chunk.numValues != group.numRows {
+			return nil, fmt.Errorf("Column '%s' holds %d values for %d rows.", name, chunk.numValues, group.numRows)
+		} else {
+			values, err := this.readChunk(&column, &chunk)
+
+			/*
+			 * Check if values could be read.
+			 */
+			if err != nil {
+				return nil, err
+			} else {
+				return &values, nil
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Decode the records of the next row group.
+ *
+ * Rows with null coordinates are skipped.
+ */
+func (this *readerStruct) readRowGroup() error {
+	group := &this.rowGroups[this.rowGroup]
+	this.rowGroup++
+	config := &this.config
+	names := []string{config.x, config.y, config.weight, config.time}
+	values := make([]*valuesStruct, len(names))
+
+	/*
+	 * Decode each configured column.
+	 */
+	for i, name := range names {
+		err := error(nil)
+		values[i], err = this.readColumn(group, name)
+
+		/*
+		 * Check if column could be decoded.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to decode row group %d: %s", this.rowGroup-1, err.Error())
+		}
+
+	}
+
+	xs, ys, weights, times := values[0], values[1], values[2], values[3]
+	xColumn := this.columns[config.x]
+	yColumn := this.columns[config.y]
+	weightColumn := this.columns[config.weight]
+	timeColumn := this.columns[config.time]
+	records := this.records[:0]
+
+	/*
+	 * Create a record for each row.
+	 */
+	for i := range xs.values {
+
+		/*
+		 * Skip rows with null coordinates.
+		 */
+		if xs.valid[i] && ys.valid[i] {
+			weight := 1.0
+			timestamp := time.Time{}
+
+			/*
+			 * Use weight if present.
+			 */
+			if (weights != nil) && weights.valid[i] {
+				weight = weightColumn.float(weights.values[i])
+			}
+
+			/*
+			 * Use time if present.
+			 */
+			if (times != nil) && times.valid[i] {
+				timestamp = timeColumn.time(times.values[i])
+			}
+
+			/*
+			 * Create record.
+			 */
+			record := Record{
+				timestamp: timestamp,
+				weight:    weight,
+				x:         xColumn.float(xs.values[i]),
+				y:         yColumn.float(ys.values[i]),
+			}
+
+			records = append(records, record)
+		}
+
+	}
+
+	this.records = records
+	return nil
+}
+
+/*
+ * Returns the total number of rows in the file, including rows with null
+ * coordinates.
+ */
+func (this *readerStruct) NumRows() int64 {
+	return this.numRows
+}
+
+/*
+ * Read the next record.
+ *
+ * Records are decoded one row group at a time, so that only a single row
+ * group of the configured columns needs to fit into memory. Rows with null
+ * coordinates are skipped. Returns io.EOF at the end of the file.
+ */
+func (this *readerStruct) Read() (Record, error) {
+
+	/*
+	 * Decode row groups until a record is available.
+	 */
+	for len(this.records) == 0 {
+
+		/*
+		 * Check if row groups are left.
+		 */
+		if this.rowGroup >= len(this.rowGroups) {
+			return Record{}, io.EOF
+		}
+
+		err := this.readRowGroup()
+
+		/*
+		 * Check if row group could be decoded.
+		 */
+		if err != nil {
+			return Record{}, err
+		}
+
+	}
+
+	record := this.records[0]
+	this.records = this.records[1:]
+	return record, nil
+}
+
+/*
+ * Read all remaining records and aggregate them into a scene, projecting
+ * them first if a projection is configured.
+ *
+ * Records are streamed in batches, so that the file need not fit into
+ * memory. Each record counts once, regardless of its weight.
+ */
+func (this *readerStruct) Aggregate(scn scene.Scene) error {
+	proj := this.config.projection
+	batch := make([]coordinates.Cartesian, 0, BATCH_SIZE)
+	locations := []coordinates.Geographic{}
+
+	/*
+	 * Aggregate collected points.
+	 */
+	flush := func() error {
+
+		/*
+		 * Project points if needed.
+		 */
+		if proj != nil {
+			locations = locations[:0]
+
+			/*
+			 * The x- and y-coordinates are longitude and latitude.
+			 */
+			for i := range batch {
+				point := &batch[i]
+				location := coordinates.CreateGeographicDegrees(point.X(), point.Y())
+				locations = append(locations, location)
+			}
+
+			err := proj.Forward(batch, locations)
+
+			/*
+			 * Points which could not be projected are NaN and are not
+			 * aggregated, so only other errors are fatal.
+			 */
+			if _, ok := err.(projection.BatchError); (err != nil) && !ok {
+				return err
+			}
+
+		}
+
+		scn.Aggregate(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	/*
+	 * Read all records.
+	 */
+	for {
+		record, err := this.Read()
+
+		/*
+		 * Stop at the end of the file.
+		 */
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		point := coordinates.CreateCartesian(record.x, record.y)
+		batch = append(batch, point)
+
+		/*
+		 * Aggregate full batches.
+		 */
+		if len(batch) >= BATCH_SIZE {
+			err = flush()
+
+			/*
+			 * Check if batch could be aggregated.
+			 */
+			if err != nil {
+				return err
+			}
+
+		}
+
+	}
+
+	return flush()
+}
+
+/*
+ * Check that a configured column exists and holds numbers.
+ */
+func checkColumn(columns map[string]columnStruct, name string, integer bool) error {
+	column, ok := columns[name]
+
+	/*
+	 * Check if column exists and is supported.
+	 */
+	if !ok {
+		return fmt.Errorf("No such column: '%s'", name)
+	} else if column.maxRepetition > 0 {
+		return fmt.Errorf("Repeated column '%s' is not supported.", name)
+	} else {
+
+		/*
+		 * Decide on the physical type.
+		 */
+		switch column.physicalType {
+		case TYPE_INT32, TYPE_INT64:
+			return nil
+		case TYPE_FLOAT, TYPE_DOUBLE:
+
+			/*
+			 * Time columns must hold integers.
+			 */
+			if integer {
+				return fmt.Errorf("Time column '%s' must hold integers.", name)
+			} else {
+				return nil
+			}
+
+		default:
+			return fmt.Errorf("Column '%s' does not hold numbers.", name)
+		}
+
+	}
+
+}
+
+/*
+ * Decode the metadata stored in the footer of a file.
+ */
+func (this *readerStruct) readFooter() error {
+	size := this.size
+	magicLength := int64(len(PARQUET_MAGIC))
+
+	/*
+	 * Check if file can hold magic numbers and footer length.
+	 */
+	if size < (2*magicLength)+4 {
+		return fmt.Errorf("%s", "File too short.")
+	}
+
+	tail := make([]byte, 4+magicLength)
+	_, err := this.file.ReadAt(tail, size-int64(len(tail)))
+
+	/*
+	 * Check if tail could be read.
+	 */
+	if err != nil {
+		return err
+	} else if string(tail[4:]) != PARQUET_MAGIC {
+		return fmt.Errorf("%s", "Not a Parquet file.")
+	}
+
+	footerSize := int64(binary.LittleEndian.Uint32(tail))
+
+	/*
+	 * Check if footer fits into file.
+	 */
+	if (footerSize > MAX_FOOTER_SIZE) || (footerSize > size-(2*magicLength)-4) {
+		return fmt.Errorf("Invalid footer size: %d bytes", footerSize)
+	}
+
+	footer := make([]byte, footerSize)
+	_, err = this.file.ReadAt(footer, size-int64(len(tail))-footerSize)
+
+	/*
+	 * Check if footer could be read.
+	 */
+	if err != nil {
+		return err
+	}
+
+	elements := []schemaElementStruct{}
+	t := thriftStruct{buf: footer}
+
+	/*
+	 * Decode all fields of the file metadata.
+	 */
+	err = t.readStruct(func(id int16, fieldType byte) error {
+		err := error(nil)
+
+		/*
+		 * Decide on the field.
+		 */
+		switch id {
+		case 2:
+
+			/*
+			 * Decode each element of the schema.
+			 */
+			err = t.readList(func(elementType byte) error {
+				element, err := readSchemaElement(&t)
+				elements = append(elements, element)
+				return err
+			})
+
+		case 3:
+			this.numRows, err = t.readInt()
+		case 4:
+
+			/*
+			 * Decode each row group.
+			 */
+			err = t.readList(func(elementType byte) error {
+				group, err := readRowGroup(&t)
+				this.rowGroups = append(this.rowGroups, group)
+				return err
+			})
+
+		default:
+			err = t.skip(fieldType)
+		}
+
+		return err
+	})
+
+	/*
+	 * Check if metadata could be decoded.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to decode metadata: %s", err.Error())
+	}
+
+	this.columns, err = flattenSchema(elements)
+
+	/*
+	 * Check if schema could be decoded.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to decode schema: %s", err.Error())
+	} else {
+		return nil
+	}
+
+}
+
+/*
+ * Create a reader for a Parquet file of a certain size, e. g. an *os.File
+ * and the size reported by its Stat method.
+ *
+ * By default, the columns named "x" and "y" hold the coordinates. Columns
+ * must hold integers or floating-point numbers and must not be repeated.
+ * Values may be plain or dictionary encoded and pages may be uncompressed or
+ * compressed using Snappy or gzip.
+ */
+func Create(r io.ReaderAt, size int64, options ...Option) (Reader, error) {
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		x: "x",
+		y: "y",
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	/*
+	 * Create reader data structure.
+	 */
+	rd := readerStruct{
+		config: config,
+		file:   r,
+		size:   size,
+	}
+
+	err := rd.readFooter()
+
+	/*
+	 * Check if footer could be read.
+	 */
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{config.x, config.y, config.weight, config.time}
+
+	/*
+	 * Check each configured column.
+	 */
+	for i, name := range names {
+
+		/*
+		 * Weight and time are optional.
+		 */
+		if (i < 2) || (name != "") {
+			err = checkColumn(rd.columns, name, i == 3)
+
+			/*
+			 * Check if column is supported.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+		}
+
+	}
+
+	return &rd, nil
+}
@@ -0,0 +1,710 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/scene"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+/*
+ * Data structure encoding metadata using the Thrift compact protocol.
+ */
+type compactStruct struct {
+	buf    bytes.Buffer
+	lastID int16
+	stack  []int16
+}
+
+/*
+ * Encode an unsigned varint.
+ */
+func (this *compactStruct) varint(value uint64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(b, value)
+	this.buf.Write(b[:n])
+}
+
+/*
+ * Encode the header of a field.
+ */
+func (this *compactStruct) field(id int16, fieldType byte) {
+	delta := id - this.lastID
+
+	/*
+	 * Small increments are stored in the header itself.
+	 */
+	if (delta > 0) && (delta <= 15) {
+		this.buf.WriteByte(byte(delta<<4) | fieldType)
+	} else {
+		this.buf.WriteByte(fieldType)
+		this.integer(int64(id))
+	}
+
+	this.lastID = id
+}
+
+/*
+ * Encode a zigzag-encoded integer without field header, e. g. as an element
+ * of a list.
+ */
+func (this *compactStruct) integer(value int64) {
+	this.varint(uint64((value << 1) ^ (value >> 63)))
+}
+
+/*
+ * Encode an integer field, which is zigzag-encoded for all widths.
+ */
+func (this *compactStruct) int(id int16, fieldType byte, value int64) {
+	this.field(id, fieldType)
+	this.integer(value)
+}
+
+/*
+ * Encode a byte string without field header, e. g. as an element of a list.
+ */
+func (this *compactStruct) binary(value string) {
+	this.varint(uint64(len(value)))
+	this.buf.WriteString(value)
+}
+
+/*
+ * Encode the name of a schema element, which is field 4.
+ */
+func (this *compactStruct) name(value string) {
+	this.field(4, THRIFT_BINARY)
+	this.binary(value)
+}
+
+/*
+ * Encode the header of a list field.
+ */
+func (this *compactStruct) list(id int16, elementType byte, size int) {
+	this.field(id, THRIFT_LIST)
+
+	/*
+	 * Large sizes follow as a varint.
+	 */
+	if size < 15 {
+		this.buf.WriteByte(byte(size<<4) | elementType)
+	} else {
+		this.buf.WriteByte(0xf0 | elementType)
+		this.varint(uint64(size))
+	}
+
+}
+
+/*
+ * Begin a nested struct, e. g. an element of a list, whose field IDs start
+ * over.
+ */
+func (this *compactStruct) begin() {
+	this.stack = append(this.stack, this.lastID)
+	this.lastID = 0
+}
+
+/*
+ * End a struct with a stop field.
+ */
+func (this *compactStruct) end() {
+	this.buf.WriteByte(0)
+	last := len(this.stack) - 1
+
+	/*
+	 * Restore the field ID of the enclosing struct.
+	 */
+	if last >= 0 {
+		this.lastID = this.stack[last]
+		this.stack = this.stack[:last]
+	}
+
+}
+
+/*
+ * Data structure representing a column to be encoded.
+ *
+ * Values are raw bits, which are interpreted according to the physical
+ * type. If valid is nil, the column is required.
+ */
+type testColumnStruct struct {
+	converted    int64
+	dictionary   bool
+	name         string
+	physicalType int64
+	valid        []bool
+	values       []uint64
+}
+
+/*
+ * Encode values using bit-packing of the hybrid encoding.
+ */
+func packBits(values []uint64, width int) []byte {
+	numGroups := (len(values) + 7) / 8
+	buf := bytes.Buffer{}
+	b := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(b, uint64(numGroups<<1)|1)
+	buf.Write(b[:n])
+	packed := make([]byte, numGroups*width)
+
+	/*
+	 * Pack values starting with the least significant bit.
+	 */
+	for i, value := range values {
+
+		/*
+		 * Set each bit of the value.
+		 */
+		for j := 0; j < width; j++ {
+			bit := (i * width) + j
+
+			/*
+			 * Check if bit is set.
+			 */
+			if (value>>uint(j))&1 != 0 {
+				packed[bit/8] |= 1 << uint(bit%8)
+			}
+
+		}
+
+	}
+
+	buf.Write(packed)
+	return buf.Bytes()
+}
+
+/*
+ * Encode values using the plain encoding of a physical type.
+ */
+func encodePlain(physicalType int64, values []uint64) []byte {
+	buf := bytes.Buffer{}
+
+	/*
+	 * Encode each value.
+	 */
+	for _, value := range values {
+
+		/*
+		 * Decide on the size of values.
+		 */
+		if (physicalType == TYPE_INT32) || (physicalType == TYPE_FLOAT) {
+			binary.Write(&buf, binary.LittleEndian, uint32(value))
+		} else {
+			binary.Write(&buf, binary.LittleEndian, value)
+		}
+
+	}
+
+	return buf.Bytes()
+}
+
+/*
+ * Compress a page using a codec, storing Snappy blocks as literals.
+ */
+func compressPage(codec int64, data []byte) []byte {
+	buf := bytes.Buffer{}
+
+	/*
+	 * Decide on the codec.
+	 */
+	switch codec {
+	case CODEC_SNAPPY:
+		b := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(b, uint64(len(data)))
+		buf.Write(b[:n])
+
+		/*
+		 * Store literals of up to 60 bytes.
+		 */
+		for len(data) > 0 {
+			size := len(data)
+
+			/*
+			 * Limit the size of the literal.
+			 */
+			if size > 60 {
+				size = 60
+			}
+
+			buf.WriteByte(byte((size - 1) << 2))
+			buf.Write(data[:size])
+			data = data[size:]
+		}
+
+	case CODEC_GZIP:
+		w := gzip.NewWriter(&buf)
+		w.Write(data)
+		w.Close()
+	default:
+		buf.Write(data)
+	}
+
+	return buf.Bytes()
+}
+
+/*
+ * Encode a page along with its header.
+ */
+func encodePage(buf *bytes.Buffer, pageType int64, numValues int, encoding int64, codec int64, data []byte) {
+	compressed := compressPage(codec, data)
+	header := compactStruct{}
+	header.int(1, THRIFT_I32, pageType)
+	header.int(2, THRIFT_I32, int64(len(data)))
+	header.int(3, THRIFT_I32, int64(len(compressed)))
+
+	/*
+	 * Data pages and dictionary pages have different headers.
+	 */
+	if pageType == PAGE_DICTIONARY {
+		header.field(7, THRIFT_STRUCT)
+	} else {
+		header.field(5, THRIFT_STRUCT)
+	}
+
+	header.begin()
+	header.int(1, THRIFT_I32, int64(numValues))
+	header.int(2, THRIFT_I32, encoding)
+
+	/*
+	 * Data pages describe the encoding of their levels.
+	 */
+	if pageType != PAGE_DICTIONARY {
+		header.int(3, THRIFT_I32, ENCODING_RLE)
+		header.int(4, THRIFT_I32, ENCODING_RLE)
+	}
+
+	header.end()
+	header.end()
+	buf.Write(header.buf.Bytes())
+	buf.Write(compressed)
+}
+
+/*
+ * Encode a Parquet file holding columns split into row groups of the given
+ * sizes.
+ */
+func encodeFile(columns []testColumnStruct, groupSizes []int, codec int64) []byte {
+	buf := bytes.Buffer{}
+	buf.WriteString(PARQUET_MAGIC)
+	meta := compactStruct{}
+	meta.int(1, THRIFT_I32, 1)
+	meta.list(2, THRIFT_STRUCT, len(columns)+1)
+	meta.begin()
+	meta.name("schema")
+	meta.int(5, THRIFT_I32, int64(len(columns)))
+	meta.end()
+
+	/*
+	 * Encode the schema element of each column.
+	 */
+	for _, column := range columns {
+		repetition := int64(REPETITION_REQUIRED)
+
+		/*
+		 * Columns with validity are optional.
+		 */
+		if column.valid != nil {
+			repetition = REPETITION_OPTIONAL
+		}
+
+		meta.begin()
+		meta.int(1, THRIFT_I32, column.physicalType)
+		meta.int(3, THRIFT_I32, repetition)
+		meta.name(column.name)
+
+		/*
+		 * Annotate the column if needed.
+		 */
+		if column.converted != 0 {
+			meta.int(6, THRIFT_I32, column.converted)
+		}
+
+		meta.end()
+	}
+
+	numRows := 0
+
+	/*
+	 * Count the rows of all row groups.
+	 */
+	for _, size := range groupSizes {
+		numRows += size
+	}
+
+	meta.int(3, THRIFT_I64, int64(numRows))
+	meta.list(4, THRIFT_STRUCT, len(groupSizes))
+	start := 0
+
+	/*
+	 * Encode each row group.
+	 */
+	for _, size := range groupSizes {
+		meta.begin()
+		meta.list(1, THRIFT_STRUCT, len(columns))
+
+		/*
+		 * Encode the chunk of each column.
+		 */
+		for _, column := range columns {
+			offset := int64(buf.Len())
+			values := column.values[start : start+size]
+			levels := []byte(nil)
+			present := values
+
+			/*
+			 * Encode definition levels of optional columns.
+			 */
+			if column.valid != nil {
+				definitions := []uint64{}
+				present = []uint64{}
+
+				/*
+				 * Only valid values are present.
+				 */
+				for i, valid := range column.valid[start : start+size] {
+
+					/*
+					 * Check if value is present.
+					 */
+					if valid {
+						definitions = append(definitions, 1)
+						present = append(present, values[i])
+					} else {
+						definitions = append(definitions, 0)
+					}
+
+				}
+
+				packed := packBits(definitions, 1)
+				levels = make([]byte, 4)
+				binary.LittleEndian.PutUint32(levels, uint32(len(packed)))
+				levels = append(levels, packed...)
+			}
+
+			dictionaryOffset := int64(0)
+			encoding := int64(ENCODING_PLAIN)
+			data := []byte(nil)
+
+			/*
+			 * Encode values using a dictionary if requested.
+			 */
+			if column.dictionary {
+				dictionary := []uint64{}
+				indices := []uint64{}
+				positions := map[uint64]uint64{}
+
+				/*
+				 * Look up each value in the dictionary.
+				 */
+				for _, value := range present {
+					idx, ok := positions[value]
+
+					/*
+					 * Add value to the dictionary if needed.
+					 */
+					if !ok {
+						idx = uint64(len(dictionary))
+						positions[value] = idx
+						dictionary = append(dictionary, value)
+					}
+
+					indices = append(indices, idx)
+				}
+
+				dictionaryOffset = offset
+				encodePage(&buf, PAGE_DICTIONARY, len(dictionary), ENCODING_PLAIN, codec, encodePlain(column.physicalType, dictionary))
+				width := bitWidth(len(dictionary) - 1)
+				data = append([]byte{byte(width)}, packBits(indices, width)...)
+				encoding = ENCODING_RLE_DICTIONARY
+			} else {
+				data = encodePlain(column.physicalType, present)
+			}
+
+			dataOffset := int64(buf.Len())
+			encodePage(&buf, PAGE_DATA, size, encoding, codec, append(levels, data...))
+			chunkSize := int64(buf.Len()) - offset
+			meta.begin()
+			meta.int(2, THRIFT_I64, offset)
+			meta.field(3, THRIFT_STRUCT)
+			meta.begin()
+			meta.int(1, THRIFT_I32, column.physicalType)
+			meta.list(2, THRIFT_I32, 1)
+			meta.integer(encoding)
+			meta.list(3, THRIFT_BINARY, 1)
+			meta.binary(column.name)
+			meta.int(4, THRIFT_I32, codec)
+			meta.int(5, THRIFT_I64, int64(size))
+			meta.int(6, THRIFT_I64, chunkSize)
+			meta.int(7, THRIFT_I64, chunkSize)
+			meta.int(9, THRIFT_I64, dataOffset)
+
+			/*
+			 * Locate the dictionary page if present.
+			 */
+			if dictionaryOffset > 0 {
+				meta.int(11, THRIFT_I64, dictionaryOffset)
+			}
+
+			meta.end()
+			meta.end()
+		}
+
+		meta.int(2, THRIFT_I64, 0)
+		meta.int(3, THRIFT_I64, int64(size))
+		meta.end()
+		start += size
+	}
+
+	meta.end()
+	buf.Write(meta.buf.Bytes())
+	binary.Write(&buf, binary.LittleEndian, uint32(meta.buf.Len()))
+	buf.WriteString(PARQUET_MAGIC)
+	return buf.Bytes()
+}
+
+/*
+ * Returns the raw bits of double values.
+ */
+func doubles(values ...float64) []uint64 {
+	raw := make([]uint64, len(values))
+
+	/*
+	 * Convert each value.
+	 */
+	for i, value := range values {
+		raw[i] = math.Float64bits(value)
+	}
+
+	return raw
+}
+
+/*
+ * Returns the raw bits of single-precision values.
+ */
+func floats(values ...float32) []uint64 {
+	raw := make([]uint64, len(values))
+
+	/*
+	 * Convert each value.
+	 */
+	for i, value := range values {
+		raw[i] = uint64(math.Float32bits(value))
+	}
+
+	return raw
+}
+
+/*
+ * Returns columns holding five rows, the fourth of which has a null
+ * y-coordinate.
+ */
+func testColumns() []testColumnStruct {
+
+	/*
+	 * The columns of the file.
+	 */
+	columns := []testColumnStruct{
+		testColumnStruct{
+			name:         "x",
+			physicalType: TYPE_DOUBLE,
+			values:       doubles(13.4, -0.1, 2.35, 100.0, 151.2),
+		},
+		testColumnStruct{
+			dictionary:   true,
+			name:         "y",
+			physicalType: TYPE_DOUBLE,
+			valid:        []bool{true, true, true, false, true},
+			values:       doubles(52.5, 51.5, 52.5, 0.0, -33.9),
+		},
+		testColumnStruct{
+			name:         "weight",
+			physicalType: TYPE_FLOAT,
+			valid:        []bool{true, false, true, true, true},
+			values:       floats(2.5, 0.0, 0.25, 1.0, 4.0),
+		},
+		testColumnStruct{
+			converted:    CONVERTED_TIMESTAMP_MILLIS,
+			dictionary:   true,
+			name:         "time",
+			physicalType: TYPE_INT64,
+			values:       []uint64{1600000000000, 1600000000500, 1600000000000, 0, 1700000000000},
+		},
+		testColumnStruct{
+			name:         "id",
+			physicalType: TYPE_INT32,
+			values:       []uint64{1, 2, 3, 4, 5},
+		},
+	}
+
+	return columns
+}
+
+/*
+ * Write columns using each codec and read the records back.
+ */
+func TestRead(t *testing.T) {
+
+	/*
+	 * The records expected, skipping the row with null coordinates.
+	 */
+	expected := []Record{
+		Record{x: 13.4, y: 52.5, weight: 2.5, timestamp: time.Unix(1600000000, 0).UTC()},
+		Record{x: -0.1, y: 51.5, weight: 1.0, timestamp: time.Unix(1600000000, 500000000).UTC()},
+		Record{x: 2.35, y: 52.5, weight: 0.25, timestamp: time.Unix(1600000000, 0).UTC()},
+		Record{x: 151.2, y: -33.9, weight: 4.0, timestamp: time.Unix(1700000000, 0).UTC()},
+	}
+
+	/*
+	 * Encode the file using each codec.
+	 */
+	for _, codec := range []int64{CODEC_UNCOMPRESSED, CODEC_SNAPPY, CODEC_GZIP} {
+		data := encodeFile(testColumns(), []int{3, 2}, codec)
+		rd, err := Create(bytes.NewReader(data), int64(len(data)), WithWeight("weight"), WithTime("time"), nil)
+
+		/*
+		 * Check if reader could be created.
+		 */
+		if err != nil {
+			t.Fatalf("Codec %d: %s", codec, err.Error())
+		}
+
+		/*
+		 * Check the number of rows.
+		 */
+		if rd.NumRows() != 5 {
+			t.Errorf("Codec %d: File has %d rows, expected 5.", codec, rd.NumRows())
+		}
+
+		/*
+		 * Read each record.
+		 */
+		for i, want := range expected {
+			record, err := rd.Read()
+
+			/*
+			 * Compare the record.
+			 */
+			if err != nil {
+				t.Fatalf("Codec %d: %s", codec, err.Error())
+			} else if (record.X() != want.X()) || (record.Y() != want.Y()) {
+				t.Errorf("Codec %d: Record %d is at (%f, %f), expected (%f, %f).", codec, i, record.X(), record.Y(), want.X(), want.Y())
+			} else if record.Weight() != want.Weight() {
+				t.Errorf("Codec %d: Record %d has weight %f, expected %f.", codec, i, record.Weight(), want.Weight())
+			} else if !record.Time().Equal(want.Time()) {
+				t.Errorf("Codec %d: Record %d has time %s, expected %s.", codec, i, record.Time(), want.Time())
+			}
+
+		}
+
+		_, err = rd.Read()
+
+		/*
+		 * Check if the end of the file was reached.
+		 */
+		if err != io.EOF {
+			t.Errorf("Codec %d: Expected io.EOF after the last record, got %v", codec, err)
+		}
+
+	}
+
+}
+
+/*
+ * Aggregate a file into a scene and compare it with aggregating the points
+ * directly.
+ */
+func TestAggregate(t *testing.T) {
+	data := encodeFile(testColumns(), []int{2, 2, 1}, CODEC_UNCOMPRESSED)
+	rd, err := Create(bytes.NewReader(data), int64(len(data)), WithCoordinates("id", "x"))
+
+	/*
+	 * Check if reader could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	scn := scene.Create(8, 8, 0.0, 8.0, -50.0, 200.0)
+	err = rd.Aggregate(scn)
+
+	/*
+	 * Check if records could be aggregated.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	expected := scene.Create(8, 8, 0.0, 8.0, -50.0, 200.0)
+
+	/*
+	 * The integer column "id" holds the x-coordinates.
+	 */
+	points := []coordinates.Cartesian{
+		coordinates.CreateCartesian(1.0, 13.4),
+		coordinates.CreateCartesian(2.0, -0.1),
+		coordinates.CreateCartesian(3.0, 2.35),
+		coordinates.CreateCartesian(4.0, 100.0),
+		coordinates.CreateCartesian(5.0, 151.2),
+	}
+
+	expected.Aggregate(points)
+	bins := fmt.Sprint(scn.Hotspots(64))
+	expectedBins := fmt.Sprint(expected.Hotspots(64))
+
+	/*
+	 * Compare the counts of all non-empty bins.
+	 */
+	if bins != expectedBins {
+		t.Errorf("Bins are %s, expected %s.", bins, expectedBins)
+	}
+
+}
+
+/*
+ * Invalid files and columns are rejected.
+ */
+func TestInvalid(t *testing.T) {
+	data := encodeFile(testColumns(), []int{5}, CODEC_UNCOMPRESSED)
+	truncated := data[:len(data)-1]
+	_, err := Create(bytes.NewReader(truncated), int64(len(truncated)))
+
+	/*
+	 * Check if a file without magic number was rejected.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Expected an error for a file without magic number.")
+	}
+
+	/*
+	 * Options which must be rejected.
+	 */
+	cases := []struct {
+		name   string
+		option Option
+	}{
+		{name: "missing column", option: WithCoordinates("lon", "lat")},
+		{name: "float time", option: WithTime("weight")},
+		{name: "missing weight", option: WithWeight("mass")},
+	}
+
+	/*
+	 * Create a reader using each option.
+	 */
+	for _, c := range cases {
+		_, err := Create(bytes.NewReader(data), int64(len(data)), c.option)
+
+		/*
+		 * Check if an error was returned.
+		 */
+		if err == nil {
+			t.Errorf("Expected an error for case '%s'.", c.name)
+		}
+
+	}
+
+}
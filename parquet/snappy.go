@@ -0,0 +1,152 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+ * Decompress a raw (unframed) Snappy block.
+ */
+func decodeSnappy(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+
+	/*
+	 * Check if length could be decoded.
+	 */
+	if n <= 0 {
+		return nil, fmt.Errorf("%s", "Invalid Snappy block length.")
+	} else if length > MAX_PAGE_SIZE {
+		return nil, fmt.Errorf("Snappy block too large: %d bytes", length)
+	} else {
+		dst := make([]byte, 0, length)
+		src = src[n:]
+
+		/*
+		 * Process literals and copies.
+		 */
+		for len(src) > 0 {
+			tag := src[0]
+			src = src[1:]
+
+			/*
+			 * The lower two bits of the tag encode the type of element.
+			 */
+			if (tag & 0x03) == 0 {
+				size := uint64(tag >> 2)
+
+				/*
+				 * Large literals store their size in up to four bytes.
+				 */
+				if size >= 60 {
+					numBytes := int(size - 59)
+
+					/*
+					 * Check if size is complete.
+					 */
+					if len(src) < numBytes {
+						return nil, fmt.Errorf("%s", "Truncated Snappy literal.")
+					}
+
+					size = 0
+
+					/*
+					 * The size is stored in little-endian order.
+					 */
+					for i := numBytes - 1; i >= 0; i-- {
+						size = (size << 8) | uint64(src[i])
+					}
+
+					src = src[numBytes:]
+				}
+
+				size++
+
+				/*
+				 * Check if literal is complete.
+				 */
+				if size > uint64(len(src)) {
+					return nil, fmt.Errorf("%s", "Truncated Snappy literal.")
+				}
+
+				dst = append(dst, src[:size]...)
+				src = src[size:]
+			} else {
+				size := 0
+				offset := 0
+
+				/*
+				 * Copies differ in the size of their offset.
+				 */
+				switch tag & 0x03 {
+				case 1:
+
+					/*
+					 * Check if offset is complete.
+					 */
+					if len(src) < 1 {
+						return nil, fmt.Errorf("%s", "Truncated Snappy copy.")
+					}
+
+					size = 4 + int((tag>>2)&0x07)
+					offset = (int(tag&0xe0) << 3) | int(src[0])
+					src = src[1:]
+				case 2:
+
+					/*
+					 * Check if offset is complete.
+					 */
+					if len(src) < 2 {
+						return nil, fmt.Errorf("%s", "Truncated Snappy copy.")
+					}
+
+					size = 1 + int(tag>>2)
+					offset = int(binary.LittleEndian.Uint16(src))
+					src = src[2:]
+				case 3:
+
+					/*
+					 * Check if offset is complete.
+					 */
+					if len(src) < 4 {
+						return nil, fmt.Errorf("%s", "Truncated Snappy copy.")
+					}
+
+					size = 1 + int(tag>>2)
+					offset = int(binary.LittleEndian.Uint32(src))
+					src = src[4:]
+				}
+
+				/*
+				 * Check if offset refers to decompressed data.
+				 */
+				if (offset <= 0) || (offset > len(dst)) {
+					return nil, fmt.Errorf("Invalid Snappy copy offset: %d", offset)
+				}
+
+				start := len(dst) - offset
+
+				/*
+				 * Copy byte by byte, since source and destination may
+				 * overlap.
+				 */
+				for i := 0; i < size; i++ {
+					dst = append(dst, dst[start+i])
+				}
+
+			}
+
+		}
+
+		/*
+		 * Check if length matches.
+		 */
+		if uint64(len(dst)) != length {
+			return nil, fmt.Errorf("Snappy block length mismatch: expected %d bytes, found %d", length, len(dst))
+		} else {
+			return dst, nil
+		}
+
+	}
+
+}
@@ -0,0 +1,343 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+ * Types of the Thrift compact protocol.
+ */
+const (
+	THRIFT_BOOLEAN_TRUE  = 1
+	THRIFT_BOOLEAN_FALSE = 2
+	THRIFT_BYTE          = 3
+	THRIFT_I16           = 4
+	THRIFT_I32           = 5
+	THRIFT_I64           = 6
+	THRIFT_DOUBLE        = 7
+	THRIFT_BINARY        = 8
+	THRIFT_LIST          = 9
+	THRIFT_SET           = 10
+	THRIFT_MAP           = 11
+	THRIFT_STRUCT        = 12
+)
+
+/*
+ * Data structure representing a decoder for the Thrift compact protocol,
+ * which Parquet uses for its metadata.
+ */
+type thriftStruct struct {
+	buf []byte
+	pos int
+}
+
+/*
+ * Read a single byte.
+ */
+func (this *thriftStruct) readByte() (byte, error) {
+
+	/*
+	 * Check if data is available.
+	 */
+	if this.pos >= len(this.buf) {
+		return 0, fmt.Errorf("%s", "Truncated metadata.")
+	} else {
+		b := this.buf[this.pos]
+		this.pos++
+		return b, nil
+	}
+
+}
+
+/*
+ * Read a variable-length unsigned integer.
+ */
+func (this *thriftStruct) readVarint() (uint64, error) {
+	value, n := binary.Uvarint(this.buf[this.pos:])
+
+	/*
+	 * Check if varint could be decoded.
+	 */
+	if n <= 0 {
+		return 0, fmt.Errorf("%s", "Invalid varint in metadata.")
+	} else {
+		this.pos += n
+		return value, nil
+	}
+
+}
+
+/*
+ * Read a zigzag-encoded signed integer, which is used for all of I16, I32
+ * and I64.
+ */
+func (this *thriftStruct) readInt() (int64, error) {
+	value, err := this.readVarint()
+	return int64(value>>1) ^ -int64(value&1), err
+}
+
+/*
+ * Read a length-prefixed byte string.
+ */
+func (this *thriftStruct) readBinary() ([]byte, error) {
+	length, err := this.readVarint()
+
+	/*
+	 * Check if length could be decoded.
+	 */
+	if err != nil {
+		return nil, err
+	} else if length > uint64(len(this.buf)-this.pos) {
+		return nil, fmt.Errorf("%s", "Truncated metadata.")
+	} else {
+		end := this.pos + int(length)
+		data := this.buf[this.pos:end]
+		this.pos = end
+		return data, nil
+	}
+
+}
+
+/*
+ * Read the header of a list or set, returning the number and type of its
+ * elements.
+ */
+func (this *thriftStruct) readListHeader() (int, byte, error) {
+	header, err := this.readByte()
+
+	/*
+	 * Check if header could be read.
+	 */
+	if err != nil {
+		return 0, 0, err
+	} else {
+		size := uint64(header >> 4)
+		elementType := header & 0x0f
+
+		/*
+		 * Large sizes follow as a varint.
+		 */
+		if size == 15 {
+			size, err = this.readVarint()
+		}
+
+		/*
+		 * Check if size is plausible, each element has at least one byte.
+		 */
+		if err != nil {
+			return 0, 0, err
+		} else if size > uint64(len(this.buf)-this.pos) {
+			return 0, 0, fmt.Errorf("%s", "Truncated metadata.")
+		} else {
+			return int(size), elementType, nil
+		}
+
+	}
+
+}
+
+/*
+ * Read a list, calling a function for each element.
+ */
+func (this *thriftStruct) readList(element func(elementType byte) error) error {
+	size, elementType, err := this.readListHeader()
+
+	/*
+	 * Check if header could be read.
+	 */
+	if err != nil {
+		return err
+	} else {
+
+		/*
+		 * Read each element.
+		 */
+		for i := 0; i < size; i++ {
+			err = element(elementType)
+
+			/*
+			 * Check if element could be read.
+			 */
+			if err != nil {
+				return err
+			}
+
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Read a struct, calling a function for each field, which must either read
+ * or skip the value.
+ */
+func (this *thriftStruct) readStruct(field func(id int16, fieldType byte) error) error {
+	id := int16(0)
+
+	/*
+	 * Read fields until the stop field.
+	 */
+	for {
+		header, err := this.readByte()
+
+		/*
+		 * Check if header could be read.
+		 */
+		if err != nil {
+			return err
+		} else if header == 0 {
+			return nil
+		}
+
+		delta := int16(header >> 4)
+		fieldType := header & 0x0f
+
+		/*
+		 * Field IDs are either relative or follow as an integer.
+		 */
+		if delta != 0 {
+			id += delta
+		} else {
+			value, err := this.readInt()
+
+			/*
+			 * Check if field ID could be read.
+			 */
+			if err != nil {
+				return err
+			}
+
+			id = int16(value)
+		}
+
+		err = field(id, fieldType)
+
+		/*
+		 * Check if field could be read.
+		 */
+		if err != nil {
+			return err
+		}
+
+	}
+
+}
+
+/*
+ * Read a boolean field, whose value is encoded in its type.
+ */
+func (this *thriftStruct) readBool(fieldType byte) bool {
+	return fieldType == THRIFT_BOOLEAN_TRUE
+}
+
+/*
+ * Skip a value of a certain type.
+ */
+func (this *thriftStruct) skip(fieldType byte) error {
+
+	/*
+	 * Decide on the type.
+	 */
+	switch fieldType {
+	case THRIFT_BOOLEAN_TRUE, THRIFT_BOOLEAN_FALSE:
+		return nil
+	case THRIFT_BYTE:
+		_, err := this.readByte()
+		return err
+	case THRIFT_I16, THRIFT_I32, THRIFT_I64:
+		_, err := this.readVarint()
+		return err
+	case THRIFT_DOUBLE:
+
+		/*
+		 * Check if value is complete.
+		 */
+		if len(this.buf)-this.pos < 8 {
+			return fmt.Errorf("%s", "Truncated metadata.")
+		} else {
+			this.pos += 8
+			return nil
+		}
+
+	case THRIFT_BINARY:
+		_, err := this.readBinary()
+		return err
+	case THRIFT_LIST, THRIFT_SET:
+
+		/*
+		 * Skip each element.
+		 */
+		return this.readList(this.skipElement)
+
+	case THRIFT_MAP:
+		size, err := this.readVarint()
+
+		/*
+		 * Check if size could be read.
+		 */
+		if (err != nil) || (size == 0) {
+			return err
+		}
+
+		types, err := this.readByte()
+
+		/*
+		 * Check if types could be read.
+		 */
+		if err != nil {
+			return err
+		}
+
+		/*
+		 * Skip each pair of key and value.
+		 */
+		for i := uint64(0); i < size; i++ {
+			errKey := this.skipElement(types >> 4)
+			errValue := this.skipElement(types & 0x0f)
+
+			/*
+			 * Check if pair could be skipped.
+			 */
+			if errKey != nil {
+				return errKey
+			} else if errValue != nil {
+				return errValue
+			}
+
+		}
+
+		return nil
+	case THRIFT_STRUCT:
+
+		/*
+		 * Skip each field.
+		 */
+		return this.readStruct(func(id int16, fieldType byte) error {
+			return this.skip(fieldType)
+		})
+
+	default:
+		return fmt.Errorf("Unsupported type in metadata: %d", fieldType)
+	}
+
+}
+
+/*
+ * Skip an element of a list, set or map.
+ */
+func (this *thriftStruct) skipElement(elementType byte) error {
+
+	/*
+	 * Unlike fields, boolean elements occupy a byte each.
+	 */
+	if (elementType == THRIFT_BOOLEAN_TRUE) || (elementType == THRIFT_BOOLEAN_FALSE) {
+		_, err := this.readByte()
+		return err
+	} else {
+		return this.skip(elementType)
+	}
+
+}
@@ -0,0 +1,146 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+)
+
+/*
+ * Data structure representing a transform given by a matrix in homogeneous
+ * coordinates.
+ */
+type matrixTransformStruct struct {
+	inverse coordinates.Matrix
+	matrix  coordinates.Matrix
+}
+
+/*
+ * Apply the transform to a point.
+ */
+func (this *matrixTransformStruct) ForwardCartesian(dst *coordinates.Cartesian, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		*dst = this.matrix.Apply(*src)
+		return nil
+	}
+
+}
+
+/*
+ * Apply the inverse of the transform to a point.
+ */
+func (this *matrixTransformStruct) InverseCartesian(dst *coordinates.Cartesian, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		*dst = this.inverse.Apply(*src)
+		return nil
+	}
+
+}
+
+/*
+ * Apply a Cartesian transform to a series of points.
+ *
+ * All points are transformed, even if some of them fail. In this case, a
+ * BatchError reporting the indices of the failed points is returned.
+ */
+func TransformCartesian(transform CartesianTransform, dst []coordinates.Cartesian, src []coordinates.Cartesian) error {
+	numSrc := len(src)
+	numDst := len(dst)
+
+	/*
+	 * Check parameters.
+	 */
+	if transform == nil {
+		return fmt.Errorf("%s", "Transform must be non-nil")
+	} else if numSrc != numDst {
+		return fmt.Errorf("%s", "Source and destination must have same length")
+	} else {
+		batch := createBatchError(numSrc)
+
+		/*
+		 * Transform all data points.
+		 */
+		for i := range src {
+			srcPtr := &src[i]
+			dstPtr := &dst[i]
+			err := transform.ForwardCartesian(dstPtr, srcPtr)
+
+			/*
+			 * Record the failure of this point.
+			 */
+			if err != nil {
+				batch.add(i, err)
+			}
+
+		}
+
+		return batch.result()
+	}
+
+}
+
+/*
+ * Create an affine transform, which scales, rotates, shears and translates
+ * points on a plane, e. g. to convert floor plans or local engineering
+ * coordinates before aggregation.
+ *
+ * The transform is given as a 2 x 3 matrix in row-major order
+ * [a, b, c, d, e, f], which maps (x, y) to (a * x + b * y + c,
+ * d * x + e * y + f). Since the inverse is needed as well, the matrix must
+ * not be singular. Use Chain to apply the transform after a projection.
+ */
+func Affine(matrix [6]float64) (CartesianTransform, error) {
+
+	/*
+	 * The matrix in homogeneous coordinates.
+	 */
+	values := [9]float64{
+		matrix[0], matrix[1], matrix[2],
+		matrix[3], matrix[4], matrix[5],
+		0.0, 0.0, 1.0,
+	}
+
+	m := coordinates.CreateMatrix(values)
+	return MatrixTransform(m)
+}
+
+/*
+ * Create a transform from a 3 x 3 matrix in homogeneous coordinates, e. g.
+ * composed of rotations, scalings and translations.
+ *
+ * Since the inverse is needed as well, the matrix must not be singular. Use
+ * Chain to apply the transform after a projection.
+ */
+func MatrixTransform(matrix coordinates.Matrix) (CartesianTransform, error) {
+	inverse, err := matrix.Invert()
+
+	/*
+	 * Check if matrix can be inverted.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+
+		/*
+		 * Create matrix transform.
+		 */
+		t := matrixTransformStruct{
+			inverse: inverse,
+			matrix:  matrix,
+		}
+
+		return &t, nil
+	}
+
+}
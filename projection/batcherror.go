@@ -0,0 +1,101 @@
+package projection
+
+import (
+	"fmt"
+)
+
+/*
+ * Interface type representing the failures of a batch operation, which
+ * processes a series of points.
+ *
+ * Indices returns the indices of the points, which failed, in ascending
+ * order, while Errors returns the corresponding errors.
+ */
+type BatchError interface {
+	error
+	Errors() []error
+	Indices() []int
+}
+
+/*
+ * Data structure collecting the failures of a batch operation.
+ */
+type batchErrorStruct struct {
+	errs    []error
+	indices []int
+	total   int
+}
+
+/*
+ * Returns a message describing the failures.
+ */
+func (this *batchErrorStruct) Error() string {
+	numErrors := len(this.errs)
+	idx := this.indices[0]
+	msg := this.errs[0].Error()
+	return fmt.Sprintf("Failed to process %d of %d points, first at index %d: %s", numErrors, this.total, idx, msg)
+}
+
+/*
+ * Returns the errors of the points, which failed.
+ */
+func (this *batchErrorStruct) Errors() []error {
+	errs := this.errs
+	numErrors := len(errs)
+	result := make([]error, numErrors)
+	copy(result, errs)
+	return result
+}
+
+/*
+ * Returns the indices of the points, which failed.
+ */
+func (this *batchErrorStruct) Indices() []int {
+	indices := this.indices
+	numIndices := len(indices)
+	result := make([]int, numIndices)
+	copy(result, indices)
+	return result
+}
+
+/*
+ * Record the failure of a point.
+ */
+func (this *batchErrorStruct) add(idx int, err error) {
+	this.errs = append(this.errs, err)
+	this.indices = append(this.indices, idx)
+}
+
+/*
+ * Returns the collected failures as an error or nil if no point failed.
+ */
+func (this *batchErrorStruct) result() error {
+
+	/*
+	 * Check if any point failed.
+	 */
+	if len(this.errs) == 0 {
+		return nil
+	} else {
+		return this
+	}
+
+}
+
+/*
+ * Create a collector for the failures of a batch operation on a number of
+ * points.
+ */
+func createBatchError(total int) *batchErrorStruct {
+
+	/*
+	 * Create batch error.
+	 */
+	batch := batchErrorStruct{
+		errs:    []error{},
+		indices: []int{},
+		total:   total,
+	}
+
+	return &batch
+}
@@ -0,0 +1,184 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Factors converting the rotations (in arc seconds) and the scale (in parts
+ * per million) of a Helmert transform.
+ */
+const (
+	RADIANS_PER_ARC_SECOND = math.Pi / (180.0 * 3600.0)
+	SCALE_PER_PPM          = 1e-6
+)
+
+/*
+ * Data structure representing a seven-parameter Helmert transform between
+ * two geodetic datums.
+ */
+type helmertTransformStruct struct {
+	inverse [9]float64
+	matrix  [9]float64
+	source  coordinates.Ellipsoid
+	target  coordinates.Ellipsoid
+	shift   [3]float64
+}
+
+/*
+ * Convert a three-dimensional vector to an array.
+ */
+func vector3(v coordinates.Cartesian3) [3]float64 {
+	return [3]float64{v.X(), v.Y(), v.Z()}
+}
+
+/*
+ * Multiply a 3x3 matrix, stored in row-major order, with a vector.
+ */
+func multiply3(m [9]float64, v [3]float64) [3]float64 {
+
+	/*
+	 * The resulting vector.
+	 */
+	result := [3]float64{
+		(m[0] * v[0]) + (m[1] * v[1]) + (m[2] * v[2]),
+		(m[3] * v[0]) + (m[4] * v[1]) + (m[5] * v[2]),
+		(m[6] * v[0]) + (m[7] * v[1]) + (m[8] * v[2]),
+	}
+
+	return result
+}
+
+/*
+ * Transform a geographic location from the source to the target datum.
+ *
+ * If src == nil or dst == nil, this is a no-op.
+ */
+func (this *helmertTransformStruct) ForwardGeographic(dst *coordinates.Geographic, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		ecef := this.source.ToECEF(*src, 0.0)
+		v := vector3(ecef)
+		v = multiply3(this.matrix, v)
+		shift := this.shift
+
+		/*
+		 * Translate the geocentric coordinates.
+		 */
+		for i := range v {
+			v[i] += shift[i]
+		}
+
+		ecef = coordinates.CreateCartesian3(v[0], v[1], v[2])
+		*dst, _ = this.target.FromECEF(ecef)
+		return nil
+	}
+
+}
+
+/*
+ * Transform a geographic location from the target back to the source datum.
+ *
+ * If src == nil or dst == nil, this is a no-op.
+ */
+func (this *helmertTransformStruct) InverseGeographic(dst *coordinates.Geographic, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		ecef := this.target.ToECEF(*src, 0.0)
+		v := vector3(ecef)
+		shift := this.shift
+
+		/*
+		 * Undo the translation of the geocentric coordinates.
+		 */
+		for i := range v {
+			v[i] -= shift[i]
+		}
+
+		v = multiply3(this.inverse, v)
+		ecef = coordinates.CreateCartesian3(v[0], v[1], v[2])
+		*dst, _ = this.source.FromECEF(ecef)
+		return nil
+	}
+
+}
+
+/*
+ * Create a seven-parameter Helmert transform, which shifts geographic
+ * locations from a source to a target datum, e. g. to merge datasets
+ * referenced to different datums in a projection chain.
+ *
+ * The parameters are the translations along the x, y and z axes (in meters),
+ * the rotations about these axes (in arc seconds) and the scale difference
+ * (in parts per million), using the position vector convention (EPSG method
+ * 1033). Negate the rotations of parameters given in the coordinate frame
+ * convention (EPSG method 1032). Locations are assumed to lie on the surface
+ * of the source ellipsoid and their height above the target ellipsoid is
+ * discarded.
+ *
+ * WGS84, NAD83 and ETRS89 agree within about two meters, which is below the
+ * resolution of most maps. For higher accuracy, pass the parameters published
+ * for the realizations and the epoch of your data.
+ */
+func Helmert(parameters [7]float64, source coordinates.Ellipsoid, target coordinates.Ellipsoid) GeographicTransform {
+	rx := RADIANS_PER_ARC_SECOND * parameters[3]
+	ry := RADIANS_PER_ARC_SECOND * parameters[4]
+	rz := RADIANS_PER_ARC_SECOND * parameters[5]
+	s := 1.0 + (SCALE_PER_PPM * parameters[6])
+
+	/*
+	 * The scaled rotation matrix.
+	 */
+	m := [9]float64{
+		s, -s * rz, s * ry,
+		s * rz, s, -s * rx,
+		-s * ry, s * rx, s,
+	}
+
+	cofactor := [9]float64{
+		(m[4] * m[8]) - (m[5] * m[7]),
+		(m[2] * m[7]) - (m[1] * m[8]),
+		(m[1] * m[5]) - (m[2] * m[4]),
+		(m[5] * m[6]) - (m[3] * m[8]),
+		(m[0] * m[8]) - (m[2] * m[6]),
+		(m[2] * m[3]) - (m[0] * m[5]),
+		(m[3] * m[7]) - (m[4] * m[6]),
+		(m[1] * m[6]) - (m[0] * m[7]),
+		(m[0] * m[4]) - (m[1] * m[3]),
+	}
+
+	det := (m[0] * cofactor[0]) + (m[1] * cofactor[3]) + (m[2] * cofactor[6])
+	inverse := [9]float64{}
+
+	/*
+	 * Divide the adjugate by the determinant.
+	 */
+	for i, c := range cofactor {
+		inverse[i] = c / det
+	}
+
+	/*
+	 * Create Helmert transform.
+	 */
+	transform := helmertTransformStruct{
+		inverse: inverse,
+		matrix:  m,
+		source:  source,
+		target:  target,
+		shift:   [3]float64{parameters[0], parameters[1], parameters[2]},
+	}
+
+	return &transform
+}
@@ -0,0 +1,48 @@
+package projection
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"testing"
+)
+
+/*
+ * Transform a location from WGS72 to WGS84 using the example of the EPSG
+ * guidance note 7-2 for the position vector transformation.
+ */
+func TestHelmert(t *testing.T) {
+	wgs72 := coordinates.CreateEllipsoid(6378135.0, 1.0/298.26)
+	wgs84 := coordinates.WGS84()
+	parameters := [7]float64{0.0, 0.0, 4.5, 0.0, 0.0, 0.554, 0.219}
+	transform := Helmert(parameters, wgs72, wgs84)
+	source := coordinates.CreateGeographicDegrees(4.0, 55.0)
+	target := coordinates.Geographic{}
+	err := transform.ForwardGeographic(&target, &source)
+
+	/*
+	 * The geocentric coordinates published for the result.
+	 */
+	expected, _ := wgs84.FromECEF(coordinates.CreateCartesian3(3657660.78, 255778.43, 5201387.75))
+
+	/*
+	 * Locations must agree to about a centimeter.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if (math.Abs(target.LongitudeDegrees()-expected.LongitudeDegrees()) > 1e-7) || (math.Abs(target.LatitudeDegrees()-expected.LatitudeDegrees()) > 1e-7) {
+		t.Errorf("Transformed to (%.9f, %.9f), expected (%.9f, %.9f)", target.LongitudeDegrees(), target.LatitudeDegrees(), expected.LongitudeDegrees(), expected.LatitudeDegrees())
+	}
+
+	inverse := coordinates.Geographic{}
+	err = transform.InverseGeographic(&inverse, &target)
+
+	/*
+	 * Check if the inverse transform returns to the source.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if (math.Abs(inverse.LongitudeDegrees()-4.0) > 1e-9) || (math.Abs(inverse.LatitudeDegrees()-55.0) > 1e-9) {
+		t.Errorf("Transformed back to (%.12f, %.12f), expected (4, 55)", inverse.LongitudeDegrees(), inverse.LatitudeDegrees())
+	}
+
+}
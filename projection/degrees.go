@@ -0,0 +1,135 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+)
+
+/*
+ * Data structure representing a projection, which accepts and returns
+ * geographic coordinates in degrees instead of radians.
+ */
+type degreesStruct struct {
+	projection Projection
+}
+
+/*
+ * Convert a geographic location from degrees to radians.
+ */
+func toRadians(location coordinates.Geographic) coordinates.Geographic {
+	longitude := location.Longitude()
+	latitude := location.Latitude()
+	return coordinates.CreateGeographicDegrees(longitude, latitude)
+}
+
+/*
+ * Convert a geographic location from radians to degrees.
+ */
+func toDegrees(location coordinates.Geographic) coordinates.Geographic {
+	longitude := location.LongitudeDegrees()
+	latitude := location.LatitudeDegrees()
+	return coordinates.CreateGeographic(longitude, latitude)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude (in degrees) to
+ * points on a map.
+ */
+func (this *degreesStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude (in degrees) to a
+ * point on a map.
+ */
+func (this *degreesStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		location := toRadians(*src)
+		return this.projection.ForwardSingle(dst, &location)
+	}
+
+}
+
+/*
+ * Returns the bounding box (in degrees) of the geographic domain of the
+ * projection.
+ */
+func (this *degreesStruct) Domain() coordinates.GeographicBounds {
+	domain := this.projection.Domain()
+	sw := domain.Min()
+	ne := domain.Max()
+	sw = toDegrees(sw)
+	ne = toDegrees(ne)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the projection.
+ */
+func (this *degreesStruct) Extent() coordinates.CartesianBounds {
+	return this.projection.Extent()
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * (in degrees).
+ */
+func (this *degreesStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and
+ * latitude (in degrees).
+ */
+func (this *degreesStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		location := coordinates.Geographic{}
+		err := this.projection.InverseSingle(&location, src)
+		*dst = toDegrees(location)
+		return err
+	}
+
+}
+
+/*
+ * Wrap a projection, so that it accepts and returns geographic coordinates in
+ * degrees instead of radians, as delivered by most data sources (e. g. GPX,
+ * GeoJSON or CSV files).
+ *
+ * Points on the map are not affected. Options of the wrapped projection, like
+ * its center, are still given in radians. If proj is nil, this returns nil.
+ */
+func Degrees(proj Projection) Projection {
+
+	/*
+	 * Check if a projection was given.
+	 */
+	if proj == nil {
+		return nil
+	} else {
+
+		/*
+		 * Create projection converting degrees.
+		 */
+		wrapper := degreesStruct{
+			projection: proj,
+		}
+
+		return &wrapper
+	}
+
+}
@@ -0,0 +1,62 @@
+package projection
+
+import (
+	"fmt"
+)
+
+/*
+ * EPSG codes supported by FromEPSG.
+ */
+const (
+	EPSG_WGS84                 = 4326
+	EPSG_WEB_MERCATOR          = 3857
+	EPSG_WEB_MERCATOR_LEGACY   = 900913
+	EPSG_UTM_NORTH_FIRST       = 32601
+	EPSG_UTM_NORTH_LAST        = 32660
+	EPSG_UTM_SOUTH_FIRST       = 32701
+	EPSG_UTM_SOUTH_LAST        = 32760
+	EPSG_ETRS89_UTM_FIRST      = 25828
+	EPSG_ETRS89_UTM_LAST       = 25838
+	EPSG_ETRS89_UTM_FIRST_ZONE = 28
+)
+
+/*
+ * Create a projection from an EPSG code.
+ *
+ * The following codes are supported:
+ *
+ * - 4326 (WGS84 geographic coordinates), which maps longitude and latitude
+ *   to x and y unchanged (in radians), using Equirectangular.
+ * - 3857 (and its legacy alias 900913), using WebMercator.
+ * - 32601 to 32660 and 32701 to 32760 (WGS84 / UTM zones on the northern and
+ *   southern hemisphere), using UTM.
+ * - 25828 to 25838 (ETRS89 / UTM zones 28N to 38N), using UTM, since the
+ *   difference between the ETRS89 and WGS84 ellipsoids is negligible for
+ *   rendering.
+ */
+func FromEPSG(code int) (Projection, error) {
+
+	/*
+	 * Decide on the projection.
+	 */
+	switch {
+	case code == EPSG_WGS84:
+		proj := Equirectangular()
+		return proj, nil
+	case (code == EPSG_WEB_MERCATOR) || (code == EPSG_WEB_MERCATOR_LEGACY):
+		proj := WebMercator()
+		return proj, nil
+	case (code >= EPSG_UTM_NORTH_FIRST) && (code <= EPSG_UTM_NORTH_LAST):
+		zone := (code - EPSG_UTM_NORTH_FIRST) + 1
+		return UTM(zone, true)
+	case (code >= EPSG_UTM_SOUTH_FIRST) && (code <= EPSG_UTM_SOUTH_LAST):
+		zone := (code - EPSG_UTM_SOUTH_FIRST) + 1
+		return UTM(zone, false)
+	case (code >= EPSG_ETRS89_UTM_FIRST) && (code <= EPSG_ETRS89_UTM_LAST):
+		zone := (code - EPSG_ETRS89_UTM_FIRST) + EPSG_ETRS89_UTM_FIRST_ZONE
+		return UTM(zone, true)
+	default:
+		return nil, fmt.Errorf("Unsupported EPSG code: %d", code)
+	}
+
+}
@@ -0,0 +1,190 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Standard parallels (in radians) of well-known cylindrical equal-area
+ * projections.
+ */
+const (
+	STANDARD_PARALLEL_LAMBERT     = 0.0
+	STANDARD_PARALLEL_BEHRMANN    = math.Pi / 6.0
+	STANDARD_PARALLEL_GALL_PETERS = math.Pi / 4.0
+)
+
+/*
+ * Data structure representing the cylindrical equal-area projection.
+ */
+type cylindricalEqualAreaProjectionStruct struct {
+	centralMeridian     float64
+	cosStandardParallel float64
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * using the cylindrical equal-area projection.
+ */
+func (this *cylindricalEqualAreaProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the cylindrical equal-area projection.
+ */
+func (this *cylindricalEqualAreaProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		longitude := src.Longitude()
+		latitude := src.Latitude()
+		cosLat0 := this.cosStandardParallel
+		deltaLon := longitude - this.centralMeridian
+		x := deltaLon * cosLat0
+		y := math.Sin(latitude) / cosLat0
+		*dst = coordinates.CreateCartesian(x, y)
+		return nil
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the cylindrical equal-area projection.
+ *
+ * Points beyond the poles are projected to NaN and an error is returned.
+ */
+func (this *cylindricalEqualAreaProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * using the cylindrical equal-area projection.
+ *
+ * Points beyond the poles are projected to NaN and an error is returned.
+ */
+func (this *cylindricalEqualAreaProjectionStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		x := src.X()
+		y := src.Y()
+		cosLat0 := this.cosStandardParallel
+		sinLat := y * cosLat0
+
+		/*
+		 * Reject points beyond the poles.
+		 */
+		if math.Abs(sinLat) > 1.0 {
+			*dst = invalidGeographic()
+			return fmt.Errorf("Point (%f, %f) lies beyond the poles.", x, y)
+		} else {
+			longitude := this.centralMeridian + (x / cosLat0)
+			latitude := math.Asin(sinLat)
+			*dst = coordinates.CreateGeographic(longitude, latitude)
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the cylindrical
+ * equal-area projection, which is the whole world.
+ */
+func (this *cylindricalEqualAreaProjectionStruct) Domain() coordinates.GeographicBounds {
+	centralMeridian := this.centralMeridian
+	sw := coordinates.CreateGeographic(centralMeridian-math.Pi, -MATH_HALF_PI)
+	ne := coordinates.CreateGeographic(centralMeridian+math.Pi, MATH_HALF_PI)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the cylindrical equal-area
+ * projection.
+ */
+func (this *cylindricalEqualAreaProjectionStruct) Extent() coordinates.CartesianBounds {
+	cosLat0 := this.cosStandardParallel
+	maxX := math.Pi * cosLat0
+	maxY := 1.0 / cosLat0
+	min := coordinates.CreateCartesian(-maxX, -maxY)
+	max := coordinates.CreateCartesian(maxX, maxY)
+	return coordinates.CreateCartesianBounds(min, max)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the cylindrical equal-area projection, which is the unit sphere.
+ */
+func (this *cylindricalEqualAreaProjectionStruct) surface() (float64, float64) {
+	return 1.0, 0.0
+}
+
+/*
+ * Create a cylindrical equal-area projection, which preserves area and is
+ * true to scale along its standard parallels.
+ *
+ * Points are projected onto a map of the unit sphere. The standard parallel
+ * selects the variant of the projection, e. g. STANDARD_PARALLEL_LAMBERT (the
+ * default), STANDARD_PARALLEL_BEHRMANN or STANDARD_PARALLEL_GALL_PETERS. The
+ * options WithStandardParallel and WithCenter (whose longitude selects the
+ * central meridian) are supported. Standard parallels at the poles are
+ * replaced by the equator.
+ */
+func CylindricalEqualArea(options ...Option) Projection {
+	params := createParameters(options)
+	center := params.center
+	latitude := params.standardParallel
+	cosLatitude := math.Abs(math.Cos(latitude))
+
+	/*
+	 * Fall back to the equator for standard parallels at the poles.
+	 */
+	if cosLatitude < 1e-12 {
+		cosLatitude = 1.0
+	}
+
+	/*
+	 * Create cylindrical equal-area projection.
+	 */
+	proj := cylindricalEqualAreaProjectionStruct{
+		centralMeridian:     center.Longitude(),
+		cosStandardParallel: cosLatitude,
+	}
+
+	return &proj
+}
+
+/*
+ * Create a Gall-Peters projection, i. e. a cylindrical equal-area projection
+ * with standard parallels at 45 degrees north and south.
+ */
+func GallPeters(options ...Option) Projection {
+	defaults := []Option{WithStandardParallel(STANDARD_PARALLEL_GALL_PETERS)}
+	options = append(defaults, options...)
+	return CylindricalEqualArea(options...)
+}
+
+/*
+ * Create a Behrmann projection, i. e. a cylindrical equal-area projection
+ * with standard parallels at 30 degrees north and south.
+ */
+func Behrmann(options ...Option) Projection {
+	defaults := []Option{WithStandardParallel(STANDARD_PARALLEL_BEHRMANN)}
+	options = append(defaults, options...)
+	return CylindricalEqualArea(options...)
+}
@@ -0,0 +1,151 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Data structure representing the equirectangular projection.
+ */
+type equirectangularProjectionStruct struct {
+	cosStandardParallel float64
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * using the equirectangular projection.
+ */
+func (this *equirectangularProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the equirectangular projection.
+ */
+func (this *equirectangularProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		longitude := src.Longitude()
+		latitude := src.Latitude()
+		x := longitude * this.cosStandardParallel
+		y := latitude
+		*dst = coordinates.CreateCartesian(x, y)
+		return nil
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the equirectangular projection.
+ */
+func (this *equirectangularProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * using the equirectangular projection.
+ */
+func (this *equirectangularProjectionStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		x := src.X()
+		y := src.Y()
+		longitude := x / this.cosStandardParallel
+		latitude := y
+		*dst = coordinates.CreateGeographic(longitude, latitude)
+		return nil
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the equirectangular
+ * projection, which is the whole world.
+ */
+func (this *equirectangularProjectionStruct) Domain() coordinates.GeographicBounds {
+	sw := coordinates.CreateGeographic(-math.Pi, -MATH_HALF_PI)
+	ne := coordinates.CreateGeographic(math.Pi, MATH_HALF_PI)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the equirectangular projection.
+ */
+func (this *equirectangularProjectionStruct) Extent() coordinates.CartesianBounds {
+	maxX := math.Pi * this.cosStandardParallel
+	min := coordinates.CreateCartesian(-maxX, -MATH_HALF_PI)
+	max := coordinates.CreateCartesian(maxX, MATH_HALF_PI)
+	return coordinates.CreateCartesianBounds(min, max)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the equirectangular projection, which is the unit sphere.
+ */
+func (this *equirectangularProjectionStruct) surface() (float64, float64) {
+	return 1.0, 0.0
+}
+
+/*
+ * Create an option, which sets the standard parallel (latitude in radians) of
+ * a projection, along which the map is true to scale.
+ */
+func WithStandardParallel(latitude float64) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(params *parametersStruct) {
+		params.standardParallel = latitude
+	}
+
+	return option
+}
+
+/*
+ * Create an equirectangular projection, which maps longitude and latitude
+ * linearly to x and y.
+ *
+ * Points are projected onto a map of the unit sphere, so y equals the
+ * latitude and x equals the longitude, scaled by the cosine of the standard
+ * parallel. With the default standard parallel at the equator, this is the
+ * Plate Carree projection, which matches many global raster datasets. The
+ * option WithStandardParallel is supported. Standard parallels at the poles
+ * are replaced by the equator.
+ */
+func Equirectangular(options ...Option) Projection {
+	params := createParameters(options)
+	latitude := params.standardParallel
+	cosLatitude := math.Cos(latitude)
+
+	/*
+	 * Fall back to the equator for standard parallels at the poles.
+	 */
+	if math.Abs(cosLatitude) < 1e-12 {
+		cosLatitude = 1.0
+	}
+
+	/*
+	 * Create equirectangular projection.
+	 */
+	proj := equirectangularProjectionStruct{
+		cosStandardParallel: math.Abs(cosLatitude),
+	}
+
+	return &proj
+}
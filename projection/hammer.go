@@ -0,0 +1,172 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Data structure representing the Hammer projection.
+ */
+type hammerProjectionStruct struct {
+	centralMeridian float64
+}
+
+/*
+ * Normalize a longitude (in radians) into the interval [-pi, pi).
+ */
+func wrapLongitude(longitude float64) float64 {
+	wrapped := math.Mod(longitude+math.Pi, MATH_TWO_PI)
+
+	/*
+	 * The remainder has the sign of the dividend.
+	 */
+	if wrapped < 0.0 {
+		wrapped += MATH_TWO_PI
+	}
+
+	return wrapped - math.Pi
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * using the Hammer projection.
+ */
+func (this *hammerProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the Hammer projection.
+ */
+func (this *hammerProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		longitude := src.Longitude()
+		latitude := src.Latitude()
+		deltaLon := wrapLongitude(longitude - this.centralMeridian)
+		halfLon := 0.5 * deltaLon
+		cosLat := math.Cos(latitude)
+		sinLat := math.Sin(latitude)
+		z := math.Sqrt(1.0 + (cosLat * math.Cos(halfLon)))
+		x := (2.0 * math.Sqrt2 * cosLat * math.Sin(halfLon)) / z
+		y := (math.Sqrt2 * sinLat) / z
+		*dst = coordinates.CreateCartesian(x, y)
+		return nil
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the Hammer projection.
+ *
+ * Points outside of the map are projected to NaN and an error is returned.
+ */
+func (this *hammerProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * using the Hammer projection.
+ *
+ * Points outside of the elliptical outline of the map are projected to NaN
+ * and an error is returned.
+ */
+func (this *hammerProjectionStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		x := src.X()
+		y := src.Y()
+		ex := x / (2.0 * math.Sqrt2)
+		ey := y / math.Sqrt2
+
+		/*
+		 * Reject points outside of the map.
+		 */
+		if ((ex * ex) + (ey * ey)) > 1.0 {
+			*dst = invalidGeographic()
+			return fmt.Errorf("Point (%f, %f) lies outside of the map.", x, y)
+		} else {
+			qx := 0.25 * x
+			qy := 0.5 * y
+			z := math.Sqrt(1.0 - (qx * qx) - (qy * qy))
+			lonA := z * x
+			lonB := 2.0 * ((2.0 * z * z) - 1.0)
+			longitude := this.centralMeridian + (2.0 * math.Atan2(lonA, lonB))
+			latA := math.Max(-1.0, math.Min(z*y, 1.0))
+			latitude := math.Asin(latA)
+			*dst = coordinates.CreateGeographic(longitude, latitude)
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the Hammer projection,
+ * which is the whole world.
+ */
+func (this *hammerProjectionStruct) Domain() coordinates.GeographicBounds {
+	centralMeridian := this.centralMeridian
+	sw := coordinates.CreateGeographic(centralMeridian-math.Pi, -MATH_HALF_PI)
+	ne := coordinates.CreateGeographic(centralMeridian+math.Pi, MATH_HALF_PI)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the Hammer projection, which is
+ * the bounding box of its elliptical outline.
+ */
+func (this *hammerProjectionStruct) Extent() coordinates.CartesianBounds {
+	maxX := 2.0 * math.Sqrt2
+	maxY := math.Sqrt2
+	min := coordinates.CreateCartesian(-maxX, -maxY)
+	max := coordinates.CreateCartesian(maxX, maxY)
+	return coordinates.CreateCartesianBounds(min, max)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the Hammer projection, which is the unit sphere.
+ */
+func (this *hammerProjectionStruct) surface() (float64, float64) {
+	return 1.0, 0.0
+}
+
+/*
+ * Create a Hammer (Hammer-Aitoff) projection, which maps the whole globe (or
+ * the whole sky) to an ellipse, preserving area.
+ *
+ * Points are projected onto a map of the unit sphere, so the map spans
+ * +/- 2 * sqrt(2) horizontally and +/- sqrt(2) vertically. The longitude of
+ * the center passed using the option WithCenter selects the central
+ * meridian.
+ */
+func Hammer(options ...Option) Projection {
+	params := createParameters(options)
+	center := params.center
+
+	/*
+	 * Create Hammer projection.
+	 */
+	proj := hammerProjectionStruct{
+		centralMeridian: center.Longitude(),
+	}
+
+	return &proj
+}
@@ -0,0 +1,288 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Data structure representing the oblique Mercator projection.
+ */
+type obliqueMercatorProjectionStruct struct {
+	centerLongitude float64
+	cosAzimuth      float64
+	maxLatitude     float64
+	polePolicy      uint8
+	rotation        [9]float64
+	scaleFactor     float64
+	sinAzimuth      float64
+}
+
+/*
+ * Convert a geographic location to a unit vector in three dimensions.
+ */
+func unitVector(location coordinates.Geographic) [3]float64 {
+	longitude := location.Longitude()
+	latitude := location.Latitude()
+	cosLat := math.Cos(latitude)
+
+	/*
+	 * The unit vector.
+	 */
+	v := [3]float64{
+		cosLat * math.Cos(longitude),
+		cosLat * math.Sin(longitude),
+		math.Sin(latitude),
+	}
+
+	return v
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * using the oblique Mercator projection.
+ *
+ * All locations are projected, even if some of them fail. In this case, a
+ * BatchError reporting the indices of the failed locations is returned.
+ */
+func (this *obliqueMercatorProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the oblique Mercator projection.
+ *
+ * Locations beyond the maximum latitude, measured from the central line, are
+ * handled according to the pole policy of the projection.
+ */
+func (this *obliqueMercatorProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		v := unitVector(*src)
+		v = multiply3(this.rotation, v)
+		longitude := math.Atan2(v[1], v[0])
+		latitude := math.Asin(math.Max(-1.0, math.Min(v[2], 1.0)))
+		latitude, ok, err := limitLatitude(latitude, this.polePolicy, this.maxLatitude)
+
+		/*
+		 * Check if location can be projected.
+		 */
+		if !ok {
+			*dst = invalidCartesian()
+		} else {
+			k := this.scaleFactor
+			u := k * longitude
+			w := k * math.Log(math.Tan(MATH_QUARTER_PI+(0.5*latitude)))
+			sinAzimuth := this.sinAzimuth
+			cosAzimuth := this.cosAzimuth
+			x := (u * sinAzimuth) - (w * cosAzimuth)
+			y := (u * cosAzimuth) + (w * sinAzimuth)
+			*dst = coordinates.CreateCartesian(x, y)
+		}
+
+		return err
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the oblique Mercator projection.
+ */
+func (this *obliqueMercatorProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * using the oblique Mercator projection.
+ */
+func (this *obliqueMercatorProjectionStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		x := src.X()
+		y := src.Y()
+		sinAzimuth := this.sinAzimuth
+		cosAzimuth := this.cosAzimuth
+		k := this.scaleFactor
+		u := ((x * sinAzimuth) + (y * cosAzimuth)) / k
+		w := ((y * sinAzimuth) - (x * cosAzimuth)) / k
+		latitude := (2.0 * math.Atan(math.Exp(w))) - MATH_HALF_PI
+		rotated := coordinates.CreateGeographic(u, latitude)
+		v := unitVector(rotated)
+		r := this.rotation
+
+		/*
+		 * Rotate back using the transposed matrix.
+		 */
+		transposed := [9]float64{
+			r[0], r[3], r[6],
+			r[1], r[4], r[7],
+			r[2], r[5], r[8],
+		}
+
+		v = multiply3(transposed, v)
+		longitude := math.Atan2(v[1], v[0])
+		latitude = math.Asin(math.Max(-1.0, math.Min(v[2], 1.0)))
+		*dst = coordinates.CreateGeographic(longitude, latitude)
+		return nil
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the oblique Mercator
+ * projection, which is the whole world.
+ *
+ * Locations near the poles of the central line are still handled according
+ * to the pole policy.
+ */
+func (this *obliqueMercatorProjectionStruct) Domain() coordinates.GeographicBounds {
+	centerLongitude := this.centerLongitude
+	sw := coordinates.CreateGeographic(centerLongitude-math.Pi, -MATH_HALF_PI)
+	ne := coordinates.CreateGeographic(centerLongitude+math.Pi, MATH_HALF_PI)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the oblique Mercator projection.
+ *
+ * As for the Mercator projection, a domain extending up to the poles of the
+ * central line is limited to WEB_MERCATOR_MAX_LATITUDE.
+ */
+func (this *obliqueMercatorProjectionStruct) Extent() coordinates.CartesianBounds {
+	maxLatitude := this.maxLatitude
+
+	/*
+	 * Limit the extent if the domain extends to the poles.
+	 */
+	if maxLatitude >= MATH_HALF_PI {
+		maxLatitude = WEB_MERCATOR_MAX_LATITUDE
+	}
+
+	k := this.scaleFactor
+	maxU := k * math.Pi
+	maxW := k * math.Log(math.Tan(MATH_QUARTER_PI+(0.5*maxLatitude)))
+	min := coordinates.CreateCartesian(-maxU, -maxW)
+	max := coordinates.CreateCartesian(maxU, maxW)
+	bounds := coordinates.CreateCartesianBounds(min, max)
+	corners := cartesianCorners(bounds)
+	sinAzimuth := this.sinAzimuth
+	cosAzimuth := this.cosAzimuth
+
+	/*
+	 * Rotate the corners like the map.
+	 */
+	for i, corner := range corners {
+		u := corner.X()
+		w := corner.Y()
+		x := (u * sinAzimuth) - (w * cosAzimuth)
+		y := (u * cosAzimuth) + (w * sinAzimuth)
+		corners[i] = coordinates.CreateCartesian(x, y)
+	}
+
+	return cartesianBounds(corners)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the oblique Mercator projection, which is the unit sphere.
+ */
+func (this *obliqueMercatorProjectionStruct) surface() (float64, float64) {
+	return 1.0, 0.0
+}
+
+/*
+ * Create an option, which sets the azimuth (in radians, clockwise from north)
+ * of a projection, i. e. the direction of its central line at its center.
+ */
+func WithAzimuth(azimuth float64) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(params *parametersStruct) {
+		params.azimuth = azimuth
+	}
+
+	return option
+}
+
+/*
+ * Create an oblique Mercator projection, which is a Mercator projection
+ * along an arbitrary great circle instead of the equator, so that long
+ * diagonal features (e. g. coastlines or flight corridors) can be mapped
+ * with little distortion along their axis.
+ *
+ * The central line passes through the center passed using the option
+ * WithCenter in the direction passed using the option WithAzimuth (north by
+ * default). Points are projected onto a map of the unit sphere, which is
+ * centered on the center and rotated, so that north points up at the center.
+ * The map is true to scale along the central line, unless a different scale
+ * factor is passed using the option WithScaleFactor. Since the poles of the
+ * central line lie at an infinite distance, locations near them are handled
+ * according to the option WithPolePolicy.
+ */
+func ObliqueMercator(options ...Option) Projection {
+	params := createParameters(options)
+	center := params.center
+	longitude := center.Longitude()
+	latitude := center.Latitude()
+	azimuth := params.azimuth
+	sinAzimuth := math.Sin(azimuth)
+	cosAzimuth := math.Cos(azimuth)
+	sinLon := math.Sin(longitude)
+	cosLon := math.Cos(longitude)
+	sinLat := math.Sin(latitude)
+	cosLat := math.Cos(latitude)
+	c := unitVector(center)
+
+	/*
+	 * Direction of the central line at the center.
+	 */
+	d := [3]float64{
+		(-cosAzimuth * sinLat * cosLon) - (sinAzimuth * sinLon),
+		(-cosAzimuth * sinLat * sinLon) + (sinAzimuth * cosLon),
+		cosAzimuth * cosLat,
+	}
+
+	/*
+	 * Pole of the central line.
+	 */
+	p := [3]float64{
+		(c[1] * d[2]) - (c[2] * d[1]),
+		(c[2] * d[0]) - (c[0] * d[2]),
+		(c[0] * d[1]) - (c[1] * d[0]),
+	}
+
+	/*
+	 * Create oblique Mercator projection.
+	 */
+	proj := obliqueMercatorProjectionStruct{
+		centerLongitude: longitude,
+		cosAzimuth:      cosAzimuth,
+		maxLatitude:     params.maxLatitude,
+		polePolicy:      params.polePolicy,
+		rotation: [9]float64{
+			c[0], c[1], c[2],
+			d[0], d[1], d[2],
+			p[0], p[1], p[2],
+		},
+		scaleFactor: params.scaleFactor,
+		sinAzimuth:  sinAzimuth,
+	}
+
+	return &proj
+}
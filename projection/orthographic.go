@@ -0,0 +1,219 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Data structure representing the orthographic projection.
+ */
+type orthographicProjectionStruct struct {
+	cosCenterLatitude float64
+	centerLongitude   float64
+	sinCenterLatitude float64
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * using the orthographic projection.
+ *
+ * Locations on the far side of the globe are projected to NaN and an error is
+ * returned.
+ */
+func (this *orthographicProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the orthographic projection.
+ *
+ * Locations on the far side of the globe are not visible. They are projected
+ * to NaN, so that they are not aggregated into any scene, and an error is
+ * returned.
+ */
+func (this *orthographicProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		longitude := src.Longitude()
+		latitude := src.Latitude()
+		sinLat := math.Sin(latitude)
+		cosLat := math.Cos(latitude)
+		deltaLon := longitude - this.centerLongitude
+		sinDeltaLon := math.Sin(deltaLon)
+		cosDeltaLon := math.Cos(deltaLon)
+		sinLat0 := this.sinCenterLatitude
+		cosLat0 := this.cosCenterLatitude
+		cosC := (sinLat0 * sinLat) + (cosLat0 * cosLat * cosDeltaLon)
+
+		/*
+		 * Reject locations on the far side of the globe.
+		 */
+		if cosC < 0.0 {
+			*dst = invalidCartesian()
+			return fmt.Errorf("Location (%f, %f) lies on the far side of the globe.", longitude, latitude)
+		} else {
+			x := cosLat * sinDeltaLon
+			y := (cosLat0 * sinLat) - (sinLat0 * cosLat * cosDeltaLon)
+			*dst = coordinates.CreateCartesian(x, y)
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the orthographic projection.
+ *
+ * Points outside of the globe are projected to NaN and an error is returned.
+ */
+func (this *orthographicProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * using the orthographic projection.
+ *
+ * Points outside of the unit circle do not lie on the globe. They are
+ * projected to NaN and an error is returned.
+ */
+func (this *orthographicProjectionStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		x := src.X()
+		y := src.Y()
+		rho := math.Hypot(x, y)
+		lon0 := this.centerLongitude
+		sinLat0 := this.sinCenterLatitude
+		cosLat0 := this.cosCenterLatitude
+
+		/*
+		 * Decide whether the point lies on the globe.
+		 */
+		if rho > 1.0 {
+			*dst = invalidGeographic()
+			return fmt.Errorf("Point (%f, %f) does not lie on the globe.", x, y)
+		} else if rho == 0.0 {
+			lat0 := math.Atan2(sinLat0, cosLat0)
+			*dst = coordinates.CreateGeographic(lon0, lat0)
+			return nil
+		} else {
+			c := math.Asin(rho)
+			sinC := math.Sin(c)
+			cosC := math.Cos(c)
+			latA := (cosC * sinLat0) + ((y * sinC * cosLat0) / rho)
+			latA = math.Max(-1.0, math.Min(latA, 1.0))
+			latitude := math.Asin(latA)
+			lonA := x * sinC
+			lonB := (rho * cosC * cosLat0) - (y * sinC * sinLat0)
+			longitude := lon0 + math.Atan2(lonA, lonB)
+			*dst = coordinates.CreateGeographic(longitude, latitude)
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the orthographic
+ * projection.
+ *
+ * This is the bounding box of the visible hemisphere. If a pole is visible,
+ * it spans all longitudes.
+ */
+func (this *orthographicProjectionStruct) Domain() coordinates.GeographicBounds {
+	centerLongitude := this.centerLongitude
+	centerLatitude := math.Atan2(this.sinCenterLatitude, this.cosCenterLatitude)
+	minLatitude := math.Max(centerLatitude-MATH_HALF_PI, -MATH_HALF_PI)
+	maxLatitude := math.Min(centerLatitude+MATH_HALF_PI, MATH_HALF_PI)
+	minLongitude := centerLongitude - MATH_HALF_PI
+	maxLongitude := centerLongitude + MATH_HALF_PI
+
+	/*
+	 * All longitudes are visible if a pole is visible.
+	 */
+	if math.Abs(centerLatitude) > 1e-12 {
+		minLongitude = centerLongitude - math.Pi
+		maxLongitude = centerLongitude + math.Pi
+	}
+
+	sw := coordinates.CreateGeographic(minLongitude, minLatitude)
+	ne := coordinates.CreateGeographic(maxLongitude, maxLatitude)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the orthographic projection, which
+ * is the bounding box of the unit circle.
+ */
+func (this *orthographicProjectionStruct) Extent() coordinates.CartesianBounds {
+	min := coordinates.CreateCartesian(-1.0, -1.0)
+	max := coordinates.CreateCartesian(1.0, 1.0)
+	return coordinates.CreateCartesianBounds(min, max)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the orthographic projection, which is the unit sphere.
+ */
+func (this *orthographicProjectionStruct) surface() (float64, float64) {
+	return 1.0, 0.0
+}
+
+/*
+ * Create an option, which sets the center (in radians) of a projection,
+ * i. e. the location which is projected to the origin of the map.
+ */
+func WithCenter(center coordinates.Geographic) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(params *parametersStruct) {
+		params.center = center
+	}
+
+	return option
+}
+
+/*
+ * Create an orthographic projection, which shows the globe as seen from
+ * space, with the center of the projection facing the viewer.
+ *
+ * Points are projected onto a map of the unit sphere, so the visible
+ * hemisphere fills the unit circle. Locations on the far side of the globe
+ * are rejected. The option WithCenter is supported.
+ */
+func Orthographic(options ...Option) Projection {
+	params := createParameters(options)
+	center := params.center
+	longitude := center.Longitude()
+	latitude := center.Latitude()
+
+	/*
+	 * Create orthographic projection.
+	 */
+	proj := orthographicProjectionStruct{
+		cosCenterLatitude: math.Cos(latitude),
+		centerLongitude:   longitude,
+		sinCenterLatitude: math.Sin(latitude),
+	}
+
+	return &proj
+}
@@ -0,0 +1,188 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"runtime"
+	"sync"
+)
+
+/*
+ * Split a number of elements into chunks for a number of workers, returning
+ * the start index of each chunk and, as the last element, the number of
+ * elements.
+ */
+func chunks(n int, workers int) []int {
+
+	/*
+	 * Use one worker per CPU by default.
+	 */
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	/*
+	 * Do not use more workers than elements.
+	 */
+	if workers > n {
+		workers = n
+	}
+
+	/*
+	 * Use at least one worker.
+	 */
+	if workers < 1 {
+		workers = 1
+	}
+
+	bounds := make([]int, workers+1)
+
+	/*
+	 * Spread the elements evenly across the workers.
+	 */
+	for i := range bounds {
+		bounds[i] = (n * i) / workers
+	}
+
+	return bounds
+}
+
+/*
+ * Run a function concurrently for each chunk of a number of elements.
+ *
+ * If the function reports the failed elements of its chunk using a
+ * BatchError, the failures of all chunks are merged into a single BatchError.
+ * Otherwise, the first error in the order of the chunks is returned.
+ */
+func parallel(n int, workers int, f func(start int, end int) error) error {
+	bounds := chunks(n, workers)
+	numChunks := len(bounds) - 1
+	errs := make([]error, numChunks)
+	wg := sync.WaitGroup{}
+	wg.Add(numChunks)
+
+	/*
+	 * Process each chunk in its own goroutine.
+	 */
+	for i := 0; i < numChunks; i++ {
+		start := bounds[i]
+		end := bounds[i+1]
+		idx := i
+
+		/*
+		 * Process chunk.
+		 */
+		go func() {
+			errs[idx] = f(start, end)
+			wg.Done()
+		}()
+
+	}
+
+	wg.Wait()
+	batch := createBatchError(n)
+
+	/*
+	 * Merge the errors of all chunks.
+	 */
+	for i, err := range errs {
+
+		/*
+		 * Check if an error occured.
+		 */
+		if err != nil {
+			chunkErr, ok := err.(BatchError)
+
+			/*
+			 * Return errors, which do not refer to points.
+			 */
+			if !ok {
+				return err
+			} else {
+				start := bounds[i]
+				indices := chunkErr.Indices()
+				chunkErrs := chunkErr.Errors()
+
+				/*
+				 * Offset the indices by the start of the chunk.
+				 */
+				for j, idx := range indices {
+					batch.add(start+idx, chunkErrs[j])
+				}
+
+			}
+
+		}
+
+	}
+
+	return batch.result()
+}
+
+/*
+ * Project geographic coordinates to points on a map using a number of
+ * concurrent workers.
+ *
+ * The data is split into one contiguous chunk per worker, each of which is
+ * projected using the Forward method of the projection. If workers is zero
+ * or negative, one worker per CPU is used. The projection must be safe for
+ * concurrent use, which all projections of this package are.
+ */
+func ForwardParallel(proj Projection, dst []coordinates.Cartesian, src []coordinates.Geographic, workers int) error {
+	numSrc := len(src)
+	numDst := len(dst)
+
+	/*
+	 * Check parameters.
+	 */
+	if proj == nil {
+		return fmt.Errorf("%s", "Projection must be non-nil")
+	} else if numSrc != numDst {
+		return fmt.Errorf("%s", "Source and destination must have same length")
+	} else {
+
+		/*
+		 * Project a single chunk.
+		 */
+		f := func(start int, end int) error {
+			return proj.Forward(dst[start:end], src[start:end])
+		}
+
+		return parallel(numSrc, workers, f)
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates using a number of
+ * concurrent workers.
+ *
+ * The data is split into one contiguous chunk per worker, each of which is
+ * projected using the Inverse method of the projection. If workers is zero
+ * or negative, one worker per CPU is used. The projection must be safe for
+ * concurrent use, which all projections of this package are.
+ */
+func InverseParallel(proj Projection, dst []coordinates.Geographic, src []coordinates.Cartesian, workers int) error {
+	numSrc := len(src)
+	numDst := len(dst)
+
+	/*
+	 * Check parameters.
+	 */
+	if proj == nil {
+		return fmt.Errorf("%s", "Projection must be non-nil")
+	} else if numSrc != numDst {
+		return fmt.Errorf("%s", "Source and destination must have same length")
+	} else {
+
+		/*
+		 * Project a single chunk.
+		 */
+		f := func(start int, end int) error {
+			return proj.Inverse(dst[start:end], src[start:end])
+		}
+
+		return parallel(numSrc, workers, f)
+	}
+
+}
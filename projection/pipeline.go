@@ -0,0 +1,325 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+)
+
+/*
+ * A geographic transform maps geographic locations to other geographic
+ * locations and back, e. g. to shift them between datums.
+ */
+type GeographicTransform interface {
+	ForwardGeographic(dst *coordinates.Geographic, src *coordinates.Geographic) error
+	InverseGeographic(dst *coordinates.Geographic, src *coordinates.Geographic) error
+}
+
+/*
+ * A Cartesian transform maps points on a plane to other points on a plane
+ * and back, e. g. to scale or rotate them.
+ */
+type CartesianTransform interface {
+	ForwardCartesian(dst *coordinates.Cartesian, src *coordinates.Cartesian) error
+	InverseCartesian(dst *coordinates.Cartesian, src *coordinates.Cartesian) error
+}
+
+/*
+ * Data structure representing a pipeline of transforms around a projection.
+ */
+type pipelineStruct struct {
+	cartesian  []CartesianTransform
+	geographic []GeographicTransform
+	projection Projection
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * by applying all steps of the pipeline.
+ */
+func (this *pipelineStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * by applying all steps of the pipeline.
+ *
+ * Processing stops at the first step which fails.
+ */
+func (this *pipelineStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		geo := *src
+
+		/*
+		 * Apply geographic transforms.
+		 */
+		for _, transform := range this.geographic {
+			err := transform.ForwardGeographic(&geo, &geo)
+
+			/*
+			 * Check if transform failed.
+			 */
+			if err != nil {
+				*dst = invalidCartesian()
+				return err
+			}
+
+		}
+
+		point := coordinates.Cartesian{}
+		err := this.projection.ForwardSingle(&point, &geo)
+
+		/*
+		 * Check if projection failed.
+		 */
+		if err != nil {
+			*dst = point
+			return err
+		}
+
+		/*
+		 * Apply Cartesian transforms.
+		 */
+		for _, transform := range this.cartesian {
+			err := transform.ForwardCartesian(&point, &point)
+
+			/*
+			 * Check if transform failed.
+			 */
+			if err != nil {
+				*dst = invalidCartesian()
+				return err
+			}
+
+		}
+
+		*dst = point
+		return nil
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * by applying the inverse of all steps of the pipeline in reverse order.
+ */
+func (this *pipelineStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * by applying the inverse of all steps of the pipeline in reverse order.
+ *
+ * Processing stops at the first step which fails.
+ */
+func (this *pipelineStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		point := *src
+		cartesian := this.cartesian
+		numCartesian := len(cartesian)
+
+		/*
+		 * Apply inverse Cartesian transforms in reverse order.
+		 */
+		for i := numCartesian - 1; i >= 0; i-- {
+			err := cartesian[i].InverseCartesian(&point, &point)
+
+			/*
+			 * Check if transform failed.
+			 */
+			if err != nil {
+				*dst = invalidGeographic()
+				return err
+			}
+
+		}
+
+		geo := coordinates.Geographic{}
+		err := this.projection.InverseSingle(&geo, &point)
+
+		/*
+		 * Check if projection failed.
+		 */
+		if err != nil {
+			*dst = geo
+			return err
+		}
+
+		geographic := this.geographic
+		numGeographic := len(geographic)
+
+		/*
+		 * Apply inverse geographic transforms in reverse order.
+		 */
+		for i := numGeographic - 1; i >= 0; i-- {
+			err := geographic[i].InverseGeographic(&geo, &geo)
+
+			/*
+			 * Check if transform failed.
+			 */
+			if err != nil {
+				*dst = invalidGeographic()
+				return err
+			}
+
+		}
+
+		*dst = geo
+		return nil
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the pipeline.
+ *
+ * This is the bounding box of the corners of the domain of the projection,
+ * passed through the inverse geographic transforms in reverse order.
+ */
+func (this *pipelineStruct) Domain() coordinates.GeographicBounds {
+	domain := this.projection.Domain()
+	corners := geographicCorners(domain)
+	geographic := this.geographic
+	numGeographic := len(geographic)
+
+	/*
+	 * Apply inverse geographic transforms in reverse order.
+	 */
+	for i := numGeographic - 1; i >= 0; i-- {
+
+		/*
+		 * Transform each corner.
+		 */
+		for j := range corners {
+			corner := &corners[j]
+			geographic[i].InverseGeographic(corner, corner)
+		}
+
+	}
+
+	return geographicBounds(corners)
+}
+
+/*
+ * Returns the bounding box of the extent of the pipeline.
+ *
+ * This is the bounding box of the corners of the extent of the projection,
+ * passed through the Cartesian transforms in order. This is exact for affine
+ * transforms.
+ */
+func (this *pipelineStruct) Extent() coordinates.CartesianBounds {
+	extent := this.projection.Extent()
+	corners := cartesianCorners(extent)
+
+	/*
+	 * Apply Cartesian transforms.
+	 */
+	for _, transform := range this.cartesian {
+
+		/*
+		 * Transform each corner.
+		 */
+		for j := range corners {
+			corner := &corners[j]
+			transform.ForwardCartesian(corner, corner)
+		}
+
+	}
+
+	return cartesianBounds(corners)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the projection of the pipeline.
+ */
+func (this *pipelineStruct) surface() (float64, float64) {
+
+	/*
+	 * Assume the unit sphere unless the projection knows its surface.
+	 */
+	if s, ok := this.projection.(surfaceProjection); ok {
+		return s.surface()
+	} else {
+		return 1.0, 0.0
+	}
+
+}
+
+/*
+ * Compose a projection with transforms applied before and after it into a
+ * single projection, which can be used anywhere a projection is accepted.
+ *
+ * When projecting forward, geographic locations first pass through the
+ * geographic transforms (e. g. a datum shift) in order, then through the
+ * projection and finally through the Cartesian transforms (e. g. an affine
+ * transform) in order. The inverse applies the inverse of each step in
+ * reverse order. Nil transforms are ignored. If proj is nil, this returns
+ * nil.
+ */
+func Chain(geographic []GeographicTransform, proj Projection, cartesian []CartesianTransform) Projection {
+
+	/*
+	 * Check if a projection was given.
+	 */
+	if proj == nil {
+		return nil
+	} else {
+		geographicSteps := []GeographicTransform{}
+
+		/*
+		 * Collect non-nil geographic transforms.
+		 */
+		for _, transform := range geographic {
+
+			/*
+			 * Ignore nil transforms.
+			 */
+			if transform != nil {
+				geographicSteps = append(geographicSteps, transform)
+			}
+
+		}
+
+		cartesianSteps := []CartesianTransform{}
+
+		/*
+		 * Collect non-nil Cartesian transforms.
+		 */
+		for _, transform := range cartesian {
+
+			/*
+			 * Ignore nil transforms.
+			 */
+			if transform != nil {
+				cartesianSteps = append(cartesianSteps, transform)
+			}
+
+		}
+
+		/*
+		 * Create pipeline.
+		 */
+		pipeline := pipelineStruct{
+			cartesian:  cartesianSteps,
+			geographic: geographicSteps,
+			projection: proj,
+		}
+
+		return &pipeline
+	}
+
+}
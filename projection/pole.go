@@ -0,0 +1,82 @@
+package projection
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ * Policies for handling latitudes near the poles, which cannot be projected
+ * onto a finite map.
+ *
+ * POLE_DROP projects such locations to NaN, so that they are not aggregated.
+ * POLE_CLAMP clamps their latitudes to the maximum latitude. POLE_ERROR
+ * projects them to NaN and reports an error.
+ */
+const (
+	POLE_DROP  = 0
+	POLE_CLAMP = 1
+	POLE_ERROR = 2
+)
+
+/*
+ * Create an option, which selects how a projection handles locations beyond a
+ * maximum latitude (in radians).
+ *
+ * The maximum latitude must lie strictly between zero and the pole. Otherwise,
+ * WEB_MERCATOR_MAX_LATITUDE is used instead. By default, locations at or
+ * beyond the poles are dropped.
+ */
+func WithPolePolicy(policy uint8, maxLatitude float64) Option {
+
+	/*
+	 * Replace invalid maximum latitudes.
+	 */
+	if !(maxLatitude > 0.0) || (maxLatitude >= MATH_HALF_PI) {
+		maxLatitude = WEB_MERCATOR_MAX_LATITUDE
+	}
+
+	/*
+	 * The option.
+	 */
+	option := func(params *parametersStruct) {
+		params.maxLatitude = maxLatitude
+		params.polePolicy = policy
+	}
+
+	return option
+}
+
+/*
+ * Apply a pole policy to a latitude.
+ *
+ * Returns the latitude to project and whether the location can be projected
+ * at all. If it cannot, an error is returned if required by the policy.
+ */
+func limitLatitude(latitude float64, policy uint8, maxLatitude float64) (float64, bool, error) {
+	absLatitude := math.Abs(latitude)
+
+	/*
+	 * Check if latitude is within the valid range.
+	 */
+	if (absLatitude <= maxLatitude) && (absLatitude < MATH_HALF_PI) {
+		return latitude, true, nil
+	} else {
+
+		/*
+		 * Decide on the policy.
+		 */
+		switch policy {
+		case POLE_CLAMP:
+			latitude = math.Max(latitude, -maxLatitude)
+			latitude = math.Min(latitude, maxLatitude)
+			return latitude, true, nil
+		case POLE_ERROR:
+			return latitude, false, fmt.Errorf("Latitude %f exceeds maximum latitude %f.", latitude, maxLatitude)
+		default:
+			return latitude, false, nil
+		}
+
+	}
+
+}
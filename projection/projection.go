@@ -18,25 +18,79 @@ const (
 /*
  * Interface type representing a projection from geographic locations to points
  * in a plane (surface of a map) and the other way round.
+ *
+ * Domain returns the bounding box of the geographic locations the projection
+ * is valid for, while Extent returns the bounding box of the map, which this
+ * domain is projected to. Pass the extent to scene.CreateFromBounds to render
+ * the whole world.
  */
 type Projection interface {
 	Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error
 	ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error
+	Domain() coordinates.GeographicBounds
+	Extent() coordinates.CartesianBounds
 	Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error
 	InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error
 }
 
 /*
- * Data structure representing the Mercator projection.
+ * Data structure representing the parameters of a projection.
  */
-type mercatorProjectionStruct struct {
+type parametersStruct struct {
+	azimuth          float64
+	center           coordinates.Geographic
+	falseEasting     float64
+	falseNorthing    float64
+	maxLatitude      float64
+	polePolicy       uint8
+	scaleFactor      float64
+	standardParallel float64
+	tileSpace        bool
 }
 
 /*
- * Project geographic coordinates in longitude and latitude to points on a map
- * using the Mercator projection.
+ * An option configures the parameters of a projection.
  */
-func (this *mercatorProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+type Option func(params *parametersStruct)
+
+/*
+ * Create the parameters of a projection by applying options in order.
+ */
+func createParameters(options []Option) parametersStruct {
+
+	/*
+	 * Default parameters.
+	 */
+	params := parametersStruct{
+		maxLatitude: MATH_HALF_PI,
+		scaleFactor: 1.0,
+	}
+
+	/*
+	 * Apply options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Ignore nil options.
+		 */
+		if option != nil {
+			option(&params)
+		}
+
+	}
+
+	return params
+}
+
+/*
+ * Project geographic coordinates to points on a map using a function
+ * projecting a single location.
+ *
+ * All locations are projected, even if some of them fail. In this case, a
+ * BatchError reporting the indices of the failed locations is returned.
+ */
+func forwardAll(dst []coordinates.Cartesian, src []coordinates.Geographic, single func(dst *coordinates.Cartesian, src *coordinates.Geographic) error) error {
 	numSrc := len(src)
 	numDst := len(dst)
 
@@ -46,6 +100,7 @@ func (this *mercatorProjectionStruct) Forward(dst []coordinates.Cartesian, src [
 	if numSrc != numDst {
 		return fmt.Errorf("%s", "Source and destination must have same length")
 	} else {
+		batch := createBatchError(numSrc)
 
 		/*
 		 * Project all data points.
@@ -53,71 +108,225 @@ func (this *mercatorProjectionStruct) Forward(dst []coordinates.Cartesian, src [
 		for i := range src {
 			srcPtr := &src[i]
 			dstPtr := &dst[i]
-			this.ForwardSingle(dstPtr, srcPtr)
+			err := single(dstPtr, srcPtr)
+
+			/*
+			 * Record the failure of this point.
+			 */
+			if err != nil {
+				batch.add(i, err)
+			}
+
 		}
 
-		return nil
+		return batch.result()
 	}
 
 }
 
 /*
- * Project geographic coordinates in longitude and latitude to a point on a map
- * using the Mercator projection.
+ * Project points on a map to geographic coordinates using a function
+ * projecting a single point.
  *
- * If src == nil or dst == nil, this is a no-op.
+ * All points are projected, even if some of them fail. In this case, a
+ * BatchError reporting the indices of the failed points is returned.
  */
-func (this *mercatorProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+func inverseAll(dst []coordinates.Geographic, src []coordinates.Cartesian, single func(dst *coordinates.Geographic, src *coordinates.Cartesian) error) error {
+	numSrc := len(src)
+	numDst := len(dst)
 
 	/*
-	 * Make sure source and destination are valid.
+	 * Check if source and destination have same length.
 	 */
-	if src == nil || dst == nil {
-		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	if numSrc != numDst {
+		return fmt.Errorf("%s", "Source and destination must have same length")
 	} else {
-		longitude := src.Longitude()
-		latitude := src.Latitude()
-		x := longitude / MATH_TWO_PI
-		latA := 0.5 * latitude
-		latB := MATH_QUARTER_PI + latA
-		latC := math.Tan(latB)
-		latD := math.Log(latC)
-		y := latD / MATH_TWO_PI
-		*dst = coordinates.CreateCartesian(x, y)
-		return nil
+		batch := createBatchError(numSrc)
+
+		/*
+		 * Project all data points.
+		 */
+		for i := range src {
+			srcPtr := &src[i]
+			dstPtr := &dst[i]
+			err := single(dstPtr, srcPtr)
+
+			/*
+			 * Record the failure of this point.
+			 */
+			if err != nil {
+				batch.add(i, err)
+			}
+
+		}
+
+		return batch.result()
 	}
 
 }
 
 /*
- * Project points on a map to geographic coordinates in longitude and latitude
+ * Returns the four corners of a bounding box in geographic coordinates.
+ */
+func geographicCorners(bounds coordinates.GeographicBounds) []coordinates.Geographic {
+	sw := bounds.Min()
+	ne := bounds.Max()
+
+	/*
+	 * The corners of the bounding box.
+	 */
+	corners := []coordinates.Geographic{
+		sw,
+		coordinates.CreateGeographic(ne.Longitude(), sw.Latitude()),
+		ne,
+		coordinates.CreateGeographic(sw.Longitude(), ne.Latitude()),
+	}
+
+	return corners
+}
+
+/*
+ * Returns the bounding box of geographic locations.
+ */
+func geographicBounds(locations []coordinates.Geographic) coordinates.GeographicBounds {
+	bounds := coordinates.EmptyGeographicBounds()
+
+	/*
+	 * Extend the bounding box by each location.
+	 */
+	for _, location := range locations {
+		bounds = bounds.Expand(location)
+	}
+
+	return bounds
+}
+
+/*
+ * Returns the four corners of a bounding box in Cartesian coordinates.
+ */
+func cartesianCorners(bounds coordinates.CartesianBounds) []coordinates.Cartesian {
+	min := bounds.Min()
+	max := bounds.Max()
+
+	/*
+	 * The corners of the bounding box.
+	 */
+	corners := []coordinates.Cartesian{
+		min,
+		coordinates.CreateCartesian(max.X(), min.Y()),
+		max,
+		coordinates.CreateCartesian(min.X(), max.Y()),
+	}
+
+	return corners
+}
+
+/*
+ * Returns the bounding box of points.
+ */
+func cartesianBounds(points []coordinates.Cartesian) coordinates.CartesianBounds {
+	bounds := coordinates.EmptyCartesianBounds()
+
+	/*
+	 * Extend the bounding box by each point.
+	 */
+	for _, point := range points {
+		bounds = bounds.Expand(point)
+	}
+
+	return bounds
+}
+
+/*
+ * Returns a point, which lies outside of any scene, for geographic locations
+ * which cannot be projected.
+ */
+func invalidCartesian() coordinates.Cartesian {
+	nan := math.NaN()
+	return coordinates.CreateCartesian(nan, nan)
+}
+
+/*
+ * Returns a location, which is not a valid geographic location, for points
+ * which cannot be projected.
+ */
+func invalidGeographic() coordinates.Geographic {
+	nan := math.NaN()
+	return coordinates.CreateGeographic(nan, nan)
+}
+
+/*
+ * Data structure representing the Mercator projection.
+ */
+type mercatorProjectionStruct struct {
+	centralMeridian float64
+	maxLatitude     float64
+	polePolicy      uint8
+	scale           float64
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
  * using the Mercator projection.
+ *
+ * All locations are projected, even if some of them fail. In this case, a
+ * BatchError reporting the indices of the failed locations is returned.
  */
-func (this *mercatorProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
-	numSrc := len(src)
-	numDst := len(dst)
+func (this *mercatorProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the Mercator projection.
+ *
+ * Locations beyond the maximum latitude are handled according to the pole
+ * policy of the projection.
+ *
+ * If src == nil or dst == nil, this is a no-op.
+ */
+func (this *mercatorProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
 
 	/*
-	 * Check if source and destination have same length.
+	 * Make sure source and destination are valid.
 	 */
-	if numSrc != numDst {
-		return fmt.Errorf("%s", "Source and destination must have same length")
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
 	} else {
+		longitude := src.Longitude()
+		latitude := src.Latitude()
+		latitude, ok, err := limitLatitude(latitude, this.polePolicy, this.maxLatitude)
 
 		/*
-		 * Project all data points.
+		 * Check if location can be projected.
 		 */
-		for i := range src {
-			srcPtr := &src[i]
-			dstPtr := &dst[i]
-			this.InverseSingle(dstPtr, srcPtr)
+		if !ok {
+			*dst = invalidCartesian()
+		} else {
+			deltaLon := wrapLongitude(longitude - this.centralMeridian)
+			scale := this.scale
+			x := (scale * deltaLon) / MATH_TWO_PI
+			latA := 0.5 * latitude
+			latB := MATH_QUARTER_PI + latA
+			latC := math.Tan(latB)
+			latD := math.Log(latC)
+			y := (scale * latD) / MATH_TWO_PI
+			*dst = coordinates.CreateCartesian(x, y)
 		}
 
-		return nil
+		return err
 	}
 
 }
 
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the Mercator projection.
+ */
+func (this *mercatorProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
 /*
  * Project a point on a map to geographic coordinates in longitude and latitude
  * using the Mercator projection.
@@ -134,8 +343,10 @@ func (this *mercatorProjectionStruct) InverseSingle(dst *coordinates.Geographic,
 	} else {
 		x := src.X()
 		y := src.Y()
-		longitude := MATH_TWO_PI * x
-		yA := MATH_TWO_PI * y
+		scale := this.scale
+		deltaLon := (MATH_TWO_PI * x) / scale
+		longitude := wrapLongitude(deltaLon + this.centralMeridian)
+		yA := (MATH_TWO_PI * y) / scale
 		yB := math.Exp(yA)
 		yC := math.Atan(yB)
 		yD := 2.0 * yC
@@ -146,10 +357,95 @@ func (this *mercatorProjectionStruct) InverseSingle(dst *coordinates.Geographic,
 
 }
 
+/*
+ * Returns the bounding box of the geographic domain of the Mercator
+ * projection.
+ *
+ * Unless the latitudes are limited using WithPolePolicy, the domain extends
+ * up to (but does not include) the poles.
+ */
+func (this *mercatorProjectionStruct) Domain() coordinates.GeographicBounds {
+	centralMeridian := this.centralMeridian
+	maxLatitude := this.maxLatitude
+	sw := coordinates.CreateGeographic(centralMeridian-math.Pi, -maxLatitude)
+	ne := coordinates.CreateGeographic(centralMeridian+math.Pi, maxLatitude)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the Mercator projection.
+ *
+ * Since the poles lie at an infinite distance, the extent of a domain, which
+ * extends up to the poles, is limited to WEB_MERCATOR_MAX_LATITUDE, so that
+ * the whole world maps to a square.
+ */
+func (this *mercatorProjectionStruct) Extent() coordinates.CartesianBounds {
+	maxLatitude := this.maxLatitude
+
+	/*
+	 * Limit the extent to a square if the domain extends to the poles.
+	 */
+	if maxLatitude >= MATH_HALF_PI {
+		maxLatitude = WEB_MERCATOR_MAX_LATITUDE
+	}
+
+	scale := this.scale
+	maxX := 0.5 * scale
+	latA := 0.5 * maxLatitude
+	latB := MATH_QUARTER_PI + latA
+	latC := math.Tan(latB)
+	latD := math.Log(latC)
+	maxY := (scale * latD) / MATH_TWO_PI
+	min := coordinates.CreateCartesian(-maxX, -maxY)
+	max := coordinates.CreateCartesian(maxX, maxY)
+	return coordinates.CreateCartesianBounds(min, max)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the Mercator projection.
+ */
+func (this *mercatorProjectionStruct) surface() (float64, float64) {
+	return 1.0 / MATH_TWO_PI, 0.0
+}
+
 /*
  * Create a Mercator projection.
+ *
+ * The map is centered on the longitude of the center passed using the option
+ * WithCenter, so that, for example, Pacific-centered maps do not split at the
+ * antimeridian. Points are projected onto a map of a sphere with a
+ * circumference of one, which is true to scale along the latitude passed
+ * using the option WithStandardParallel (the equator by default).
+ *
+ * Since the poles lie at an infinite distance from the equator, locations at
+ * or beyond the poles are projected to NaN by default. Use the option
+ * WithPolePolicy to clamp their latitudes instead or to report an error.
  */
-func Mercator() Projection {
-	proj := mercatorProjectionStruct{}
+func Mercator(options ...Option) Projection {
+	params := createParameters(options)
+	center := params.center
+	centralMeridian := center.Longitude()
+	standardParallel := params.standardParallel
+	scale := math.Cos(standardParallel)
+
+	/*
+	 * A pole as the standard parallel would collapse the map, so use the
+	 * equator instead.
+	 */
+	if math.Abs(scale) < 1e-12 {
+		scale = 1.0
+	}
+
+	/*
+	 * Create Mercator projection.
+	 */
+	proj := mercatorProjectionStruct{
+		centralMeridian: centralMeridian,
+		maxLatitude:     params.maxLatitude,
+		polePolicy:      params.polePolicy,
+		scale:           scale,
+	}
+
 	return &proj
 }
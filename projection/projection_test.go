@@ -0,0 +1,197 @@
+package projection
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"testing"
+)
+
+/*
+ * Data structure representing a location with its known position on a map.
+ */
+type knownPoint struct {
+	longitude float64
+	latitude  float64
+	x         float64
+	y         float64
+}
+
+/*
+ * Create a geographic location from a longitude and latitude in degrees.
+ */
+func locationDegrees(longitude float64, latitude float64) coordinates.Geographic {
+	return coordinates.CreateGeographic((math.Pi/180.0)*longitude, (math.Pi/180.0)*latitude)
+}
+
+/*
+ * Project locations given in degrees and compare them with their known
+ * positions on the map, then project the positions back.
+ */
+func checkKnownPoints(t *testing.T, name string, proj Projection, points []knownPoint, tolerance float64) {
+
+	/*
+	 * Check each location.
+	 */
+	for _, p := range points {
+		location := locationDegrees(p.longitude, p.latitude)
+		point := coordinates.Cartesian{}
+		err := proj.ForwardSingle(&point, &location)
+
+		/*
+		 * Check if location could be projected.
+		 */
+		if err != nil {
+			t.Errorf("%s: failed to project (%f, %f): %s", name, p.longitude, p.latitude, err.Error())
+		} else if (math.Abs(point.X()-p.x) > tolerance) || (math.Abs(point.Y()-p.y) > tolerance) {
+			t.Errorf("%s: (%f, %f) projected to (%.9f, %.9f), expected (%.9f, %.9f)", name, p.longitude, p.latitude, point.X(), point.Y(), p.x, p.y)
+		} else {
+			inverse := coordinates.Geographic{}
+			err = proj.InverseSingle(&inverse, &point)
+			longitude := inverse.LongitudeDegrees()
+			latitude := inverse.LatitudeDegrees()
+
+			/*
+			 * The longitude of the poles is arbitrary.
+			 */
+			if math.Abs(p.latitude) == 90.0 {
+				longitude = p.longitude
+			}
+
+			/*
+			 * Check if point projects back to the location.
+			 */
+			if err != nil {
+				t.Errorf("%s: failed to project (%f, %f) back: %s", name, point.X(), point.Y(), err.Error())
+			} else if (math.Abs(longitude-p.longitude) > 1e-9) || (math.Abs(latitude-p.latitude) > 1e-9) {
+				t.Errorf("%s: (%f, %f) projected back to (%.12f, %.12f)", name, p.longitude, p.latitude, longitude, latitude)
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Equirectangular maps longitude and latitude linearly.
+ */
+func TestEquirectangular(t *testing.T) {
+
+	/*
+	 * Known positions for Plate Carree.
+	 */
+	points := []knownPoint{
+		{0.0, 0.0, 0.0, 0.0},
+		{90.0, 45.0, 0.5 * math.Pi, 0.25 * math.Pi},
+		{-180.0, -90.0, -math.Pi, -0.5 * math.Pi},
+	}
+
+	checkKnownPoints(t, "Plate Carree", Equirectangular(), points, 1e-12)
+
+	/*
+	 * Known positions with a standard parallel at 60 degrees.
+	 */
+	points = []knownPoint{
+		{90.0, 45.0, 0.25 * math.Pi, 0.25 * math.Pi},
+		{-180.0, 30.0, -0.5 * math.Pi, math.Pi / 6.0},
+	}
+
+	proj := Equirectangular(WithStandardParallel(math.Pi / 3.0))
+	checkKnownPoints(t, "Equirectangular", proj, points, 1e-12)
+}
+
+/*
+ * Orthographic shows the hemisphere around its center on the unit disc.
+ */
+func TestOrthographic(t *testing.T) {
+
+	/*
+	 * Known positions.
+	 */
+	points := []knownPoint{
+		{0.0, 0.0, 0.0, 0.0},
+		{60.0, 0.0, 0.5 * math.Sqrt(3.0), 0.0},
+		{0.0, 30.0, 0.0, 0.5},
+		{-30.0, -60.0, -0.25, -0.5 * math.Sqrt(3.0)},
+	}
+
+	checkKnownPoints(t, "Orthographic", Orthographic(), points, 1e-12)
+	proj := Orthographic()
+	location := locationDegrees(180.0, 0.0)
+	point := coordinates.Cartesian{}
+	err := proj.ForwardSingle(&point, &location)
+
+	/*
+	 * Check if the far side of the globe is rejected.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Orthographic: expected an error for the far side of the globe.")
+	}
+
+}
+
+/*
+ * Hammer maps the globe to an ellipse with semi-axes of 2 * sqrt(2) and
+ * sqrt(2).
+ */
+func TestHammer(t *testing.T) {
+
+	/*
+	 * Known positions.
+	 */
+	points := []knownPoint{
+		{0.0, 0.0, 0.0, 0.0},
+		{0.0, 90.0, 0.0, math.Sqrt2},
+		{0.0, -90.0, 0.0, -math.Sqrt2},
+		{90.0, 0.0, 2.0 / math.Sqrt(1.0+math.Sqrt(0.5)), 0.0},
+		{179.999999, 0.0, 2.0 * math.Sqrt2, 0.0},
+	}
+
+	checkKnownPoints(t, "Hammer", Hammer(), points, 1e-6)
+}
+
+/*
+ * Cylindrical equal-area projections map latitudes to their sines.
+ */
+func TestCylindricalEqualArea(t *testing.T) {
+
+	/*
+	 * Known positions for the Lambert variant.
+	 */
+	points := []knownPoint{
+		{0.0, 0.0, 0.0, 0.0},
+		{180.0, 90.0, math.Pi, 1.0},
+		{-90.0, -30.0, -0.5 * math.Pi, -0.5},
+	}
+
+	checkKnownPoints(t, "Lambert", CylindricalEqualArea(), points, 1e-12)
+
+	/*
+	 * Known positions for Gall-Peters.
+	 */
+	points = []knownPoint{
+		{180.0, 90.0, math.Pi / math.Sqrt2, math.Sqrt2},
+		{-90.0, -30.0, -0.5 * math.Pi / math.Sqrt2, -0.5 * math.Sqrt2},
+	}
+
+	checkKnownPoints(t, "Gall-Peters", GallPeters(), points, 1e-12)
+}
+
+/*
+ * Mercator maps the sphere to a map with a circumference of one.
+ */
+func TestMercator(t *testing.T) {
+	y45 := math.Asinh(1.0) / (2.0 * math.Pi)
+
+	/*
+	 * Known positions.
+	 */
+	points := []knownPoint{
+		{0.0, 0.0, 0.0, 0.0},
+		{-180.0, 0.0, -0.5, 0.0},
+		{-90.0, 45.0, -0.25, y45},
+		{90.0, -45.0, 0.25, -y45},
+	}
+
+	checkKnownPoints(t, "Mercator", Mercator(), points, 1e-12)
+}
@@ -0,0 +1,214 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Step (in radians) used to differentiate projections numerically.
+ */
+const TISSOT_STEP = 1e-6
+
+/*
+ * Interface type representing a projection, which knows the radius (in map
+ * units) and the flattening of the surface it projects.
+ *
+ * Projections which do not implement this interface are assumed to project
+ * the unit sphere.
+ */
+type surfaceProjection interface {
+	surface() (float64, float64)
+}
+
+/*
+ * Data structure representing the local distortion of a projection at a
+ * geographic location, as described by Tissot's indicatrix.
+ *
+ * Distortions are immutable.
+ */
+type Distortion struct {
+	angular  float64
+	area     float64
+	maximum  float64
+	meridian float64
+	minimum  float64
+	parallel float64
+}
+
+/*
+ * Returns the maximum angular distortion (in radians), which is zero for
+ * conformal projections.
+ */
+func (this *Distortion) AngularDistortion() float64 {
+	return this.angular
+}
+
+/*
+ * Returns the scale factor of areas, which is constant for equal-area
+ * projections.
+ */
+func (this *Distortion) AreaScale() float64 {
+	return this.area
+}
+
+/*
+ * Returns the largest scale factor in any direction, i. e. the semi-major
+ * axis of Tissot's indicatrix.
+ */
+func (this *Distortion) MaxScale() float64 {
+	return this.maximum
+}
+
+/*
+ * Returns the scale factor along the meridian.
+ */
+func (this *Distortion) MeridianScale() float64 {
+	return this.meridian
+}
+
+/*
+ * Returns the smallest scale factor in any direction, i. e. the semi-minor
+ * axis of Tissot's indicatrix.
+ */
+func (this *Distortion) MinScale() float64 {
+	return this.minimum
+}
+
+/*
+ * Returns the scale factor along the parallel.
+ */
+func (this *Distortion) ParallelScale() float64 {
+	return this.parallel
+}
+
+/*
+ * Project a location, which is displaced from another location by a
+ * difference in longitude and latitude.
+ */
+func displaced(proj Projection, location coordinates.Geographic, deltaLon float64, deltaLat float64) (coordinates.Cartesian, error) {
+	longitude := location.Longitude() + deltaLon
+	latitude := location.Latitude() + deltaLat
+	src := coordinates.CreateGeographic(longitude, latitude)
+	dst := coordinates.Cartesian{}
+	err := proj.ForwardSingle(&dst, &src)
+
+	/*
+	 * Check if the point is valid.
+	 */
+	if err == nil {
+		x := dst.X()
+		y := dst.Y()
+
+		/*
+		 * Reject points, which are not finite.
+		 */
+		if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+			err = fmt.Errorf("Location (%f, %f) cannot be projected.", longitude, latitude)
+		}
+
+	}
+
+	return dst, err
+}
+
+/*
+ * Compute the local distortion of a projection at a geographic location, e. g.
+ * to render Tissot's indicatrices or to verify that a projection preserves
+ * area.
+ *
+ * Scale factors are relative to the surface the projection is based on, i. e.
+ * the WGS84 ellipsoid for the transverse Mercator projection and a sphere for
+ * all other projections of this package. Custom projections are assumed to
+ * project the unit sphere. The distortion is determined numerically from the
+ * derivatives of the projection, so it includes the effect of any transforms
+ * in a chain. It is undefined at the poles and an error is returned for
+ * locations, which cannot be projected.
+ */
+func Tissot(proj Projection, location coordinates.Geographic) (Distortion, error) {
+
+	/*
+	 * Differentiate with respect to radians.
+	 */
+	if wrapper, ok := proj.(*degreesStruct); ok {
+		proj = wrapper.projection
+		location = toRadians(location)
+	}
+
+	latitude := location.Latitude()
+	cosLat := math.Cos(latitude)
+
+	/*
+	 * Check parameters.
+	 */
+	if proj == nil {
+		return Distortion{}, fmt.Errorf("%s", "Projection must be non-nil")
+	} else if math.Abs(cosLat) < 1e-9 {
+		return Distortion{}, fmt.Errorf("%s", "Distortion is undefined at the poles.")
+	} else {
+		radius := float64(1.0)
+		flattening := float64(0.0)
+
+		/*
+		 * Find the surface projected.
+		 */
+		if s, ok := proj.(surfaceProjection); ok {
+			radius, flattening = s.surface()
+		}
+
+		h := TISSOT_STEP
+		north, errNorth := displaced(proj, location, 0.0, h)
+		south, errSouth := displaced(proj, location, 0.0, -h)
+		east, errEast := displaced(proj, location, h, 0.0)
+		west, errWest := displaced(proj, location, -h, 0.0)
+
+		/*
+		 * Check if all points could be projected.
+		 */
+		if errNorth != nil {
+			return Distortion{}, errNorth
+		} else if errSouth != nil {
+			return Distortion{}, errSouth
+		} else if errEast != nil {
+			return Distortion{}, errEast
+		} else if errWest != nil {
+			return Distortion{}, errWest
+		} else {
+			xLat := (north.X() - south.X()) / (2.0 * h)
+			yLat := (north.Y() - south.Y()) / (2.0 * h)
+			xLon := (east.X() - west.X()) / (2.0 * h)
+			yLon := (east.Y() - west.Y()) / (2.0 * h)
+			e2 := flattening * (2.0 - flattening)
+			sinLat := math.Sin(latitude)
+			w := math.Sqrt(1.0 - (e2 * sinLat * sinLat))
+			meridianRadius := (radius * (1.0 - e2)) / (w * w * w)
+			parallelRadius := (radius / w) * math.Abs(cosLat)
+			meridian := math.Hypot(xLat, yLat) / meridianRadius
+			parallel := math.Hypot(xLon, yLon) / parallelRadius
+			area := math.Abs((xLat*yLon)-(yLat*xLon)) / (meridianRadius * parallelRadius)
+			sumSquares := (meridian * meridian) + (parallel * parallel)
+			aPlusB := math.Sqrt(sumSquares + (2.0 * area))
+			aMinusB := math.Sqrt(math.Max(0.0, sumSquares-(2.0*area)))
+			maximum := 0.5 * (aPlusB + aMinusB)
+			minimum := 0.5 * (aPlusB - aMinusB)
+			angular := 2.0 * math.Asin(aMinusB/aPlusB)
+
+			/*
+			 * The resulting distortion.
+			 */
+			result := Distortion{
+				angular:  angular,
+				area:     area,
+				maximum:  maximum,
+				meridian: meridian,
+				minimum:  minimum,
+				parallel: parallel,
+			}
+
+			return result, nil
+		}
+
+	}
+
+}
@@ -0,0 +1,342 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Parameters of the WGS84 ellipsoid.
+ */
+const (
+	WGS84_SEMI_MAJOR_AXIS = coordinates.WGS84_SEMI_MAJOR_AXIS
+	WGS84_FLATTENING      = coordinates.WGS84_FLATTENING
+)
+
+/*
+ * Number of iterations used to convert conformal latitudes back to
+ * geographic latitudes.
+ */
+const TRANSVERSE_MERCATOR_ITERATIONS = 5
+
+/*
+ * Largest difference in longitude (in radians) from the central meridian
+ * within the domain of the transverse Mercator projection (30 degrees).
+ */
+const TRANSVERSE_MERCATOR_MAX_DELTA = math.Pi / 6.0
+
+/*
+ * Data structure representing the transverse Mercator projection.
+ */
+type transverseMercatorProjectionStruct struct {
+	alpha           [6]float64
+	beta            [6]float64
+	centralMeridian float64
+	eccentricity    float64
+	falseEasting    float64
+	falseNorthing   float64
+	originXi        float64
+	radius          float64
+	scaleFactor     float64
+}
+
+/*
+ * Calculate the tangent of the conformal latitude for a latitude.
+ */
+func (this *transverseMercatorProjectionStruct) conformal(latitude float64) float64 {
+	e := this.eccentricity
+	sinLat := math.Sin(latitude)
+	psi := math.Atanh(sinLat) - (e * math.Atanh(e*sinLat))
+	return math.Sinh(psi)
+}
+
+/*
+ * Calculate the latitude for the tangent of a conformal latitude.
+ */
+func (this *transverseMercatorProjectionStruct) geographic(tauPrime float64) float64 {
+	e := this.eccentricity
+	e2 := e * e
+	tau := tauPrime
+
+	/*
+	 * Refine the tangent of the latitude using Newton's method.
+	 */
+	for i := 0; i < TRANSVERSE_MERCATOR_ITERATIONS; i++ {
+		root := math.Sqrt(1.0 + (tau * tau))
+		sigma := math.Sinh(e * math.Atanh((e*tau)/root))
+		sigmaRoot := math.Sqrt(1.0 + (sigma * sigma))
+		tauPrimeI := (tau * sigmaRoot) - (sigma * root)
+		tauPrimeRoot := math.Sqrt(1.0 + (tauPrimeI * tauPrimeI))
+		derivative := ((1.0 - e2) * tauPrimeRoot * root) / (1.0 + ((1.0 - e2) * tau * tau))
+		tau += (tauPrime - tauPrimeI) / derivative
+	}
+
+	return math.Atan(tau)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * using the transverse Mercator projection.
+ */
+func (this *transverseMercatorProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the transverse Mercator projection.
+ *
+ * Locations 90 degrees or more from the central meridian cannot be projected,
+ * since they lie on or beyond the equator of the transverse cylinder.
+ */
+func (this *transverseMercatorProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		longitude := src.Longitude()
+		latitude := src.Latitude()
+		deltaLon := wrapLongitude(longitude - this.centralMeridian)
+
+		/*
+		 * Reject locations outside the domain of the projection.
+		 */
+		if !(math.Abs(deltaLon) < MATH_HALF_PI) {
+			*dst = invalidCartesian()
+			return fmt.Errorf("Location (%f, %f) lies 90 degrees or more from the central meridian.", longitude, latitude)
+		} else {
+			tau := this.conformal(latitude)
+			xiPrime := math.Atan2(tau, math.Cos(deltaLon))
+			etaPrime := math.Asinh(math.Sin(deltaLon) / math.Hypot(tau, math.Cos(deltaLon)))
+			xi := xiPrime
+			eta := etaPrime
+
+			/*
+			 * Evaluate the Krueger series.
+			 */
+			for j, alpha := range this.alpha {
+				k := 2.0 * float64(j+1)
+				xi += alpha * math.Sin(k*xiPrime) * math.Cosh(k*etaPrime)
+				eta += alpha * math.Cos(k*xiPrime) * math.Sinh(k*etaPrime)
+			}
+
+			scale := this.scaleFactor * this.radius
+			x := this.falseEasting + (scale * eta)
+			y := this.falseNorthing + (scale * (xi - this.originXi))
+			*dst = coordinates.CreateCartesian(x, y)
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the transverse Mercator projection.
+ */
+func (this *transverseMercatorProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * using the transverse Mercator projection.
+ *
+ * The longitude is normalized to the range from -180 to 180 degrees, even if
+ * the central meridian lies close to the antimeridian.
+ */
+func (this *transverseMercatorProjectionStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		x := src.X()
+		y := src.Y()
+		scale := this.scaleFactor * this.radius
+		xi := ((y - this.falseNorthing) / scale) + this.originXi
+		eta := (x - this.falseEasting) / scale
+		xiPrime := xi
+		etaPrime := eta
+
+		/*
+		 * Evaluate the inverse Krueger series.
+		 */
+		for j, beta := range this.beta {
+			k := 2.0 * float64(j+1)
+			xiPrime -= beta * math.Sin(k*xi) * math.Cosh(k*eta)
+			etaPrime -= beta * math.Cos(k*xi) * math.Sinh(k*eta)
+		}
+
+		sinhEta := math.Sinh(etaPrime)
+		cosXi := math.Cos(xiPrime)
+		tauPrime := math.Sin(xiPrime) / math.Hypot(sinhEta, cosXi)
+		deltaLon := math.Atan2(sinhEta, cosXi)
+		longitude := wrapLongitude(this.centralMeridian + deltaLon)
+		latitude := this.geographic(tauPrime)
+		*dst = coordinates.CreateGeographic(longitude, latitude)
+		return nil
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the transverse Mercator
+ * projection.
+ *
+ * The domain covers all latitudes within TRANSVERSE_MERCATOR_MAX_DELTA of
+ * the central meridian, where the Krueger series is accurate to within a few
+ * nanometers.
+ */
+func (this *transverseMercatorProjectionStruct) Domain() coordinates.GeographicBounds {
+	centralMeridian := this.centralMeridian
+	sw := coordinates.CreateGeographic(centralMeridian-TRANSVERSE_MERCATOR_MAX_DELTA, -MATH_HALF_PI)
+	ne := coordinates.CreateGeographic(centralMeridian+TRANSVERSE_MERCATOR_MAX_DELTA, MATH_HALF_PI)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the transverse Mercator
+ * projection, in meters.
+ */
+func (this *transverseMercatorProjectionStruct) Extent() coordinates.CartesianBounds {
+	location := coordinates.CreateGeographic(this.centralMeridian+TRANSVERSE_MERCATOR_MAX_DELTA, 0.0)
+	edge := coordinates.Cartesian{}
+	this.ForwardSingle(&edge, &location)
+	halfWidth := edge.X() - this.falseEasting
+	scale := this.scaleFactor * this.radius
+	minX := this.falseEasting - halfWidth
+	maxX := this.falseEasting + halfWidth
+	minY := this.falseNorthing + (scale * (-MATH_HALF_PI - this.originXi))
+	maxY := this.falseNorthing + (scale * (MATH_HALF_PI - this.originXi))
+	min := coordinates.CreateCartesian(minX, minY)
+	max := coordinates.CreateCartesian(maxX, maxY)
+	return coordinates.CreateCartesianBounds(min, max)
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the transverse Mercator projection, which is the WGS84
+ * ellipsoid.
+ */
+func (this *transverseMercatorProjectionStruct) surface() (float64, float64) {
+	return WGS84_SEMI_MAJOR_AXIS, WGS84_FLATTENING
+}
+
+/*
+ * Create an option, which sets the scale factor of a projection along its
+ * line of true scale, e. g. the central meridian of a transverse Mercator
+ * projection.
+ */
+func WithScaleFactor(scaleFactor float64) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(params *parametersStruct) {
+		params.scaleFactor = scaleFactor
+	}
+
+	return option
+}
+
+/*
+ * Create an option, which sets the false easting and false northing (in map
+ * units) of a projection, which are added to all projected coordinates.
+ */
+func WithFalseOrigin(easting float64, northing float64) Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(params *parametersStruct) {
+		params.falseEasting = easting
+		params.falseNorthing = northing
+	}
+
+	return option
+}
+
+/*
+ * Create a transverse Mercator projection on the WGS84 ellipsoid, which is
+ * conformal and true to scale (up to the scale factor) along its central
+ * meridian.
+ *
+ * Points are projected to meters using the Krueger series, which is accurate
+ * to well below a millimeter within several thousand kilometers of the
+ * central meridian. The options WithCenter (whose longitude selects the
+ * central meridian and whose latitude selects the latitude of origin),
+ * WithScaleFactor and WithFalseOrigin are supported.
+ */
+func TransverseMercator(options ...Option) Projection {
+	params := createParameters(options)
+	center := params.center
+	f := WGS84_FLATTENING
+	n := f / (2.0 - f)
+	n2 := n * n
+	n3 := n2 * n
+	n4 := n3 * n
+	n5 := n4 * n
+	n6 := n5 * n
+	radius := (WGS84_SEMI_MAJOR_AXIS / (1.0 + n)) * (1.0 + (n2 / 4.0) + (n4 / 64.0) + (n6 / 256.0))
+
+	/*
+	 * Coefficients of the Krueger series.
+	 */
+	alpha := [6]float64{
+		(n / 2.0) - ((2.0 * n2) / 3.0) + ((5.0 * n3) / 16.0) + ((41.0 * n4) / 180.0) - ((127.0 * n5) / 288.0) + ((7891.0 * n6) / 37800.0),
+		((13.0 * n2) / 48.0) - ((3.0 * n3) / 5.0) + ((557.0 * n4) / 1440.0) + ((281.0 * n5) / 630.0) - ((1983433.0 * n6) / 1935360.0),
+		((61.0 * n3) / 240.0) - ((103.0 * n4) / 140.0) + ((15061.0 * n5) / 26880.0) + ((167603.0 * n6) / 181440.0),
+		((49561.0 * n4) / 161280.0) - ((179.0 * n5) / 168.0) + ((6601661.0 * n6) / 7257600.0),
+		((34729.0 * n5) / 80640.0) - ((3418889.0 * n6) / 1995840.0),
+		(212378941.0 * n6) / 319334400.0,
+	}
+
+	/*
+	 * Coefficients of the inverse Krueger series.
+	 */
+	beta := [6]float64{
+		(n / 2.0) - ((2.0 * n2) / 3.0) + ((37.0 * n3) / 96.0) - (n4 / 360.0) - ((81.0 * n5) / 512.0) + ((96199.0 * n6) / 604800.0),
+		(n2 / 48.0) + (n3 / 15.0) - ((437.0 * n4) / 1440.0) + ((46.0 * n5) / 105.0) - ((1118711.0 * n6) / 3870720.0),
+		((17.0 * n3) / 480.0) - ((37.0 * n4) / 840.0) - ((209.0 * n5) / 4480.0) + ((5569.0 * n6) / 90720.0),
+		((4397.0 * n4) / 161280.0) - ((11.0 * n5) / 504.0) - ((830251.0 * n6) / 7257600.0),
+		((4583.0 * n5) / 161280.0) - ((108847.0 * n6) / 3991680.0),
+		(20648693.0 * n6) / 638668800.0,
+	}
+
+	/*
+	 * Create transverse Mercator projection.
+	 */
+	proj := transverseMercatorProjectionStruct{
+		alpha:           alpha,
+		beta:            beta,
+		centralMeridian: center.Longitude(),
+		eccentricity:    math.Sqrt(f * (2.0 - f)),
+		falseEasting:    params.falseEasting,
+		falseNorthing:   params.falseNorthing,
+		radius:          radius,
+		scaleFactor:     params.scaleFactor,
+	}
+
+	originTau := proj.conformal(center.Latitude())
+	originXiPrime := math.Atan(originTau)
+	originXi := originXiPrime
+
+	/*
+	 * Evaluate the Krueger series at the latitude of origin.
+	 */
+	for j, a := range alpha {
+		k := 2.0 * float64(j+1)
+		originXi += a * math.Sin(k*originXiPrime)
+	}
+
+	proj.originXi = originXi
+	return &proj
+}
@@ -0,0 +1,105 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Parameters of the Universal Transverse Mercator (UTM) system.
+ */
+const (
+	UTM_NUM_ZONES      = 60
+	UTM_SCALE_FACTOR   = 0.9996
+	UTM_FALSE_EASTING  = 500000.0
+	UTM_FALSE_NORTHING = 10000000.0
+)
+
+/*
+ * Create a projection for a zone of the Universal Transverse Mercator (UTM)
+ * system, which projects to meters on the WGS84 ellipsoid.
+ *
+ * Zones are numbered from 1 to 60. Locations on the southern hemisphere use
+ * a false northing of 10000 kilometers, if north is false.
+ */
+func UTM(zone int, north bool) (Projection, error) {
+
+	/*
+	 * Verify that the zone is valid.
+	 */
+	if (zone < 1) || (zone > UTM_NUM_ZONES) {
+		return nil, fmt.Errorf("Invalid UTM zone: %d", zone)
+	} else {
+		zoneFloat := float64(zone)
+		centralMeridianDeg := (6.0 * zoneFloat) - 183.0
+		centralMeridian := centralMeridianDeg * (math.Pi / 180.0)
+		center := coordinates.CreateGeographic(centralMeridian, 0.0)
+		falseNorthing := float64(0.0)
+
+		/*
+		 * Use false northing on the southern hemisphere.
+		 */
+		if !north {
+			falseNorthing = UTM_FALSE_NORTHING
+		}
+
+		proj := TransverseMercator(
+			WithCenter(center),
+			WithScaleFactor(UTM_SCALE_FACTOR),
+			WithFalseOrigin(UTM_FALSE_EASTING, falseNorthing),
+		)
+
+		return proj, nil
+	}
+
+}
+
+/*
+ * Select the UTM zone containing a location, given in radians, and whether
+ * the location lies on the northern hemisphere.
+ *
+ * This takes the exceptions for southwestern Norway and Svalbard into
+ * account.
+ */
+func UTMZone(location coordinates.Geographic) (int, bool) {
+	longitude := wrapLongitude(location.Longitude())
+	latitude := location.Latitude()
+	lonDeg := longitude * (180.0 / math.Pi)
+	latDeg := latitude * (180.0 / math.Pi)
+	zone := int(math.Floor((lonDeg+180.0)/6.0)) + 1
+
+	/*
+	 * Make sure zone is in valid range.
+	 */
+	if zone > UTM_NUM_ZONES {
+		zone = UTM_NUM_ZONES
+	} else if zone < 1 {
+		zone = 1
+	}
+
+	/*
+	 * Handle exceptions for southwestern Norway and Svalbard.
+	 */
+	if (latDeg >= 56.0) && (latDeg < 64.0) && (lonDeg >= 3.0) && (lonDeg < 12.0) {
+		zone = 32
+	} else if (latDeg >= 72.0) && (latDeg < 84.0) {
+
+		/*
+		 * Decide on the zone in Svalbard.
+		 */
+		if (lonDeg >= 0.0) && (lonDeg < 9.0) {
+			zone = 31
+		} else if (lonDeg >= 9.0) && (lonDeg < 21.0) {
+			zone = 33
+		} else if (lonDeg >= 21.0) && (lonDeg < 33.0) {
+			zone = 35
+		} else if (lonDeg >= 33.0) && (lonDeg < 42.0) {
+			zone = 37
+		}
+
+	}
+
+	north := latitude >= 0.0
+	return zone, north
+}
@@ -0,0 +1,157 @@
+package projection
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"testing"
+)
+
+/*
+ * Known UTM coordinates, e. g. of the CN Tower in Toronto.
+ */
+func TestUTM(t *testing.T) {
+	north, err := UTM(17, true)
+
+	/*
+	 * Check if projection could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	/*
+	 * Known positions in zone 17N.
+	 */
+	points := []knownPoint{
+		{-81.0, 0.0, 500000.0, 0.0},
+		{-(79.0 + (23.0 / 60.0) + (13.7 / 3600.0)), 43.0 + (38.0 / 60.0) + (33.24 / 3600.0), 630084.3, 4833438.5},
+	}
+
+	checkKnownPoints(t, "UTM 17N", north, points, 0.1)
+	south, err := UTM(17, false)
+
+	/*
+	 * Check if projection could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	/*
+	 * Known positions in zone 17S.
+	 */
+	points = []knownPoint{
+		{-81.0, 0.0, 500000.0, 10000000.0},
+	}
+
+	checkKnownPoints(t, "UTM 17S", south, points, 1e-6)
+
+	/*
+	 * Check that invalid zones are rejected.
+	 */
+	if _, err := UTM(61, true); err == nil {
+		t.Errorf("%s", "Expected an error for zone 61.")
+	}
+
+}
+
+/*
+ * Zones are selected by longitude, except in Norway and on Svalbard.
+ */
+func TestUTMZone(t *testing.T) {
+
+	/*
+	 * Locations with known zones.
+	 */
+	cases := []struct {
+		longitude float64
+		latitude  float64
+		zone      int
+		north     bool
+	}{
+		{-79.4, 43.6, 17, true},
+		{-179.9, -10.0, 1, false},
+		{179.9, 10.0, 60, true},
+		{5.0, 60.0, 32, true},
+		{10.0, 75.0, 33, true},
+		{20.0, 75.0, 33, true},
+		{22.0, 75.0, 35, true},
+	}
+
+	/*
+	 * Compare each zone with the known one.
+	 */
+	for _, c := range cases {
+		location := locationDegrees(c.longitude, c.latitude)
+		zone, north := UTMZone(location)
+
+		/*
+		 * Check if zone matches.
+		 */
+		if (zone != c.zone) || (north != c.north) {
+			t.Errorf("(%f, %f) lies in zone %d (north: %t), expected %d (north: %t)", c.longitude, c.latitude, zone, north, c.zone, c.north)
+		}
+
+	}
+
+}
+
+/*
+ * Transverse Mercator rejects locations far from its central meridian and
+ * returns longitudes within the range of -180 to 180 degrees.
+ */
+func TestTransverseMercatorDomain(t *testing.T) {
+	center := locationDegrees(177.0, 0.0)
+	proj := TransverseMercator(WithCenter(center))
+
+	/*
+	 * Locations outside the domain of the projection.
+	 */
+	outside := []coordinates.Geographic{
+		locationDegrees(-93.0, 0.0),
+		locationDegrees(87.0, 45.0),
+		locationDegrees(0.0, -30.0),
+	}
+
+	/*
+	 * Check if each location is rejected.
+	 */
+	for _, location := range outside {
+		point := coordinates.Cartesian{}
+		err := proj.ForwardSingle(&point, &location)
+
+		/*
+		 * Check if projection failed.
+		 */
+		if err == nil {
+			t.Errorf("Expected an error for (%f, %f), got (%f, %f).", location.LongitudeDegrees(), location.LatitudeDegrees(), point.X(), point.Y())
+		}
+
+	}
+
+	location := locationDegrees(-179.0, 10.0)
+	point := coordinates.Cartesian{}
+	err := proj.ForwardSingle(&point, &location)
+
+	/*
+	 * Check if location beyond the antimeridian could be projected.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	inverse := coordinates.Geographic{}
+	err = proj.InverseSingle(&inverse, &point)
+	longitude := inverse.LongitudeDegrees()
+	latitude := inverse.LatitudeDegrees()
+
+	/*
+	 * Check if point projects back to the normalized location.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if (math.Abs(longitude+179.0) > 1e-9) || (math.Abs(latitude-10.0) > 1e-9) {
+		t.Errorf("Projected back to (%.12f, %.12f), expected (-179, 10).", longitude, latitude)
+	}
+
+}
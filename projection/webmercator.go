@@ -0,0 +1,213 @@
+package projection
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Parameters of the Web Mercator projection (EPSG:3857).
+ *
+ * WEB_MERCATOR_MAX_LATITUDE is the largest latitude (in radians), at which
+ * the projected map becomes square (approximately 85.051 degrees).
+ */
+const (
+	WEB_MERCATOR_RADIUS       = 6378137.0
+	WEB_MERCATOR_MAX_LATITUDE = 1.4844222297453324
+)
+
+/*
+ * Data structure representing the Web Mercator projection.
+ */
+type webMercatorProjectionStruct struct {
+	tileSpace bool
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to points on a map
+ * using the Web Mercator projection.
+ */
+func (this *webMercatorProjectionStruct) Forward(dst []coordinates.Cartesian, src []coordinates.Geographic) error {
+	return forwardAll(dst, src, this.ForwardSingle)
+}
+
+/*
+ * Project geographic coordinates in longitude and latitude to a point on a map
+ * using the Web Mercator projection.
+ *
+ * Latitudes are clamped to +/- WEB_MERCATOR_MAX_LATITUDE.
+ */
+func (this *webMercatorProjectionStruct) ForwardSingle(dst *coordinates.Cartesian, src *coordinates.Geographic) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		longitude := src.Longitude()
+		latitude := src.Latitude()
+		latitude = math.Max(latitude, -WEB_MERCATOR_MAX_LATITUDE)
+		latitude = math.Min(latitude, WEB_MERCATOR_MAX_LATITUDE)
+		latA := 0.5 * latitude
+		latB := MATH_QUARTER_PI + latA
+		latC := math.Tan(latB)
+		latD := math.Log(latC)
+
+		/*
+		 * Decide on the output space.
+		 */
+		if this.tileSpace {
+			x := (longitude + math.Pi) / MATH_TWO_PI
+			y := (latD + math.Pi) / MATH_TWO_PI
+			*dst = coordinates.CreateCartesian(x, y)
+		} else {
+			x := WEB_MERCATOR_RADIUS * longitude
+			y := WEB_MERCATOR_RADIUS * latD
+			*dst = coordinates.CreateCartesian(x, y)
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Project points on a map to geographic coordinates in longitude and latitude
+ * using the Web Mercator projection.
+ */
+func (this *webMercatorProjectionStruct) Inverse(dst []coordinates.Geographic, src []coordinates.Cartesian) error {
+	return inverseAll(dst, src, this.InverseSingle)
+}
+
+/*
+ * Project a point on a map to geographic coordinates in longitude and latitude
+ * using the Web Mercator projection.
+ */
+func (this *webMercatorProjectionStruct) InverseSingle(dst *coordinates.Geographic, src *coordinates.Cartesian) error {
+
+	/*
+	 * Make sure source and destination are valid.
+	 */
+	if src == nil || dst == nil {
+		return fmt.Errorf("%s", "Src and dst must be non-nil")
+	} else {
+		x := src.X()
+		y := src.Y()
+		longitude := float64(0.0)
+		yA := float64(0.0)
+
+		/*
+		 * Decide on the output space.
+		 */
+		if this.tileSpace {
+			longitude = (MATH_TWO_PI * x) - math.Pi
+			yA = (MATH_TWO_PI * y) - math.Pi
+		} else {
+			longitude = x / WEB_MERCATOR_RADIUS
+			yA = y / WEB_MERCATOR_RADIUS
+		}
+
+		yB := math.Exp(yA)
+		yC := math.Atan(yB)
+		yD := 2.0 * yC
+		latitude := yD - MATH_HALF_PI
+		*dst = coordinates.CreateGeographic(longitude, latitude)
+		return nil
+	}
+
+}
+
+/*
+ * Returns the bounding box of the geographic domain of the Web Mercator
+ * projection.
+ */
+func (this *webMercatorProjectionStruct) Domain() coordinates.GeographicBounds {
+	sw := coordinates.CreateGeographic(-math.Pi, -WEB_MERCATOR_MAX_LATITUDE)
+	ne := coordinates.CreateGeographic(math.Pi, WEB_MERCATOR_MAX_LATITUDE)
+	return coordinates.CreateGeographicBounds(sw, ne)
+}
+
+/*
+ * Returns the bounding box of the extent of the Web Mercator projection.
+ *
+ * This is the unit square in tile space and a square with a side length of
+ * about 40075 km otherwise.
+ */
+func (this *webMercatorProjectionStruct) Extent() coordinates.CartesianBounds {
+
+	/*
+	 * Decide on the output space.
+	 */
+	if this.tileSpace {
+		min := coordinates.CreateCartesian(0.0, 0.0)
+		max := coordinates.CreateCartesian(1.0, 1.0)
+		return coordinates.CreateCartesianBounds(min, max)
+	} else {
+		halfSize := WEB_MERCATOR_RADIUS * math.Pi
+		min := coordinates.CreateCartesian(-halfSize, -halfSize)
+		max := coordinates.CreateCartesian(halfSize, halfSize)
+		return coordinates.CreateCartesianBounds(min, max)
+	}
+
+}
+
+/*
+ * Returns the radius (in map units) and the flattening of the surface
+ * projected by the Web Mercator projection.
+ */
+func (this *webMercatorProjectionStruct) surface() (float64, float64) {
+
+	/*
+	 * Decide on the output space.
+	 */
+	if this.tileSpace {
+		return 1.0 / MATH_TWO_PI, 0.0
+	} else {
+		return WEB_MERCATOR_RADIUS, 0.0
+	}
+
+}
+
+/*
+ * Create an option, which makes a projection produce coordinates normalized
+ * to the unit square instead of meters.
+ *
+ * For Web Mercator, x increases from zero at 180 degrees west to one at 180
+ * degrees east and y increases from zero at the southern to one at the
+ * northern edge of the map. At zoom level z, the slippy map tile containing a
+ * point has the column floor(x * 2^z) and the row floor((1 - y) * 2^z).
+ */
+func WithTileSpace() Option {
+
+	/*
+	 * The option.
+	 */
+	option := func(params *parametersStruct) {
+		params.tileSpace = true
+	}
+
+	return option
+}
+
+/*
+ * Create a Web Mercator projection (EPSG:3857), as used by most web maps and
+ * tile servers.
+ *
+ * Points are projected to meters on a sphere with the equatorial radius of
+ * WGS84 and latitudes are clamped to +/- WEB_MERCATOR_MAX_LATITUDE, so that
+ * the whole world maps to a square. The option WithTileSpace is supported.
+ */
+func WebMercator(options ...Option) Projection {
+	params := createParameters(options)
+
+	/*
+	 * Create Web Mercator projection.
+	 */
+	proj := webMercatorProjectionStruct{
+		tileSpace: params.tileSpace,
+	}
+
+	return &proj
+}
@@ -0,0 +1,35 @@
+package projection
+
+import (
+	"testing"
+)
+
+/*
+ * Web Mercator maps the world to a square of about 40075 km.
+ */
+func TestWebMercator(t *testing.T) {
+	edge := 20037508.342789244
+
+	/*
+	 * Known positions in meters.
+	 */
+	points := []knownPoint{
+		{0.0, 0.0, 0.0, 0.0},
+		{180.0, 85.0511287798066, edge, edge},
+		{-180.0, -85.0511287798066, -edge, -edge},
+		{90.0, 0.0, 0.5 * edge, 0.0},
+	}
+
+	checkKnownPoints(t, "Web Mercator", WebMercator(), points, 1e-6)
+
+	/*
+	 * Known positions in tile space.
+	 */
+	points = []knownPoint{
+		{0.0, 0.0, 0.5, 0.5},
+		{-180.0, -85.0511287798066, 0.0, 0.0},
+		{90.0, 0.0, 0.75, 0.5},
+	}
+
+	checkKnownPoints(t, "Web Mercator (tile space)", WebMercator(WithTileSpace()), points, 1e-12)
+}
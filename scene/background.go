@@ -0,0 +1,139 @@
+package scene
+
+import (
+	imagecolor "image/color"
+)
+
+/*
+ * Composite a color over a background color.
+ */
+func over(c imagecolor.NRGBA, bg imagecolor.NRGBA) imagecolor.NRGBA {
+
+	/*
+	 * Handle opaque and transparent colors without blending.
+	 */
+	if c.A == 255 {
+		return c
+	} else if c.A == 0 {
+		return bg
+	} else {
+		alpha := float64(c.A) / 255.0
+		bgAlpha := float64(bg.A) / 255.0
+		bgWeight := bgAlpha * (1.0 - alpha)
+		outAlpha := alpha + bgWeight
+
+		/*
+		 * Blend a single channel.
+		 */
+		blend := func(fg uint8, bg uint8) uint8 {
+			fgFloat := float64(fg)
+			bgFloat := float64(bg)
+			value := ((alpha * fgFloat) + (bgWeight * bgFloat)) / outAlpha
+			value = clamp(value+0.5, 0.0, 255.0)
+			return uint8(value)
+		}
+
+		/*
+		 * The resulting color.
+		 */
+		result := imagecolor.NRGBA{
+			R: blend(c.R, bg.R),
+			G: blend(c.G, bg.G),
+			B: blend(c.B, bg.B),
+			A: uint8((255.0 * outAlpha) + 0.5),
+		}
+
+		return result
+	}
+
+}
+
+/*
+ * Composite the colors mapped from the bins of the scene over the background
+ * color of the scene.
+ */
+func (this *sceneStruct) composite(colors []imagecolor.NRGBA) {
+	bg := this.background
+
+	/*
+	 * Only composite if a background is set.
+	 */
+	if bg.A > 0 {
+
+		/*
+		 * Composite each color over the background.
+		 */
+		for i, c := range colors {
+			colors[i] = over(c, bg)
+		}
+
+	}
+
+}
+
+/*
+ * Composite the 16-bit colors mapped from the bins of the scene over the
+ * background color of the scene.
+ */
+func (this *sceneStruct) composite16(colors []imagecolor.NRGBA64) {
+	bg := this.background
+
+	/*
+	 * Only composite if a background is set.
+	 */
+	if bg.A > 0 {
+		bgAlpha := float64(bg.A) / 255.0
+
+		/*
+		 * Composite each color over the background.
+		 */
+		for i, c := range colors {
+
+			/*
+			 * Only blend colors which are not opaque.
+			 */
+			if c.A != 65535 {
+				alpha := float64(c.A) / 65535.0
+				bgWeight := bgAlpha * (1.0 - alpha)
+				outAlpha := alpha + bgWeight
+
+				/*
+				 * Blend a single channel.
+				 */
+				blend := func(fg uint16, bg uint8) uint16 {
+					fgFloat := float64(fg)
+					bgFloat := 257.0 * float64(bg)
+					value := ((alpha * fgFloat) + (bgWeight * bgFloat)) / outAlpha
+					value = clamp(value+0.5, 0.0, 65535.0)
+					return uint16(value)
+				}
+
+				/*
+				 * The resulting color.
+				 */
+				colors[i] = imagecolor.NRGBA64{
+					R: blend(c.R, bg.R),
+					G: blend(c.G, bg.G),
+					B: blend(c.B, bg.B),
+					A: uint16((65535.0 * outAlpha) + 0.5),
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Sets the background color over which the scene is rendered.
+ *
+ * This works with any color mapping, so that callers do not have to draw the
+ * rendered image over a uniform background. Hillshading is applied before
+ * compositing, so it does not affect the background. A fully transparent
+ * background (the default) leaves the rendered image unchanged.
+ */
+func (this *sceneStruct) SetBackground(background imagecolor.NRGBA) {
+	this.background = background
+}
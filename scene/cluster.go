@@ -0,0 +1,196 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Data structure representing a connected region of dense bins in a scene.
+ *
+ * Clusters are immutable.
+ */
+type Cluster struct {
+	area     float64
+	bins     uint64
+	centroid coordinates.Cartesian
+	count    uint64
+	label    uint32
+}
+
+/*
+ * Returns the area covered by this cluster in data space.
+ */
+func (this *Cluster) Area() float64 {
+	return this.area
+}
+
+/*
+ * Returns the number of bins which make up this cluster.
+ */
+func (this *Cluster) Bins() uint64 {
+	return this.bins
+}
+
+/*
+ * Returns the count-weighted centroid of this cluster in data space.
+ */
+func (this *Cluster) Centroid() coordinates.Cartesian {
+	return this.centroid
+}
+
+/*
+ * Returns the total number of data points aggregated into this cluster.
+ */
+func (this *Cluster) Count() uint64 {
+	return this.count
+}
+
+/*
+ * Returns the label of this cluster.
+ *
+ * Labels are assigned consecutively, starting at one, in the order in which
+ * clusters are first encountered when scanning the scene row by row.
+ */
+func (this *Cluster) Label() uint32 {
+	return this.label
+}
+
+/*
+ * Detect clusters of dense bins.
+ *
+ * A bin is dense if its count is at least threshold (and at least one). Dense
+ * bins which are adjacent to each other (including diagonally) are joined into
+ * the same cluster.
+ */
+func (this *sceneStruct) Clusters(threshold uint64) []Cluster {
+
+	/*
+	 * Empty bins never belong to a cluster.
+	 */
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	bins := this.bins
+	numBins := len(bins)
+	labels := make([]uint32, numBins)
+	width := this.width
+	height := this.height
+	width64 := uint64(width)
+	widthFloat := float64(width)
+	heightFloat := float64(height)
+	sizeX := (this.maxX - this.minX) / widthFloat
+	sizeY := (this.maxY - this.minY) / heightFloat
+	binArea := math.Abs(sizeX * sizeY)
+	result := []Cluster{}
+	queue := []uint64{}
+	label := uint32(0)
+
+	/*
+	 * Iterate over all bins to find unlabeled dense bins.
+	 */
+	for i, count := range bins {
+
+		/*
+		 * Start a new cluster at each dense bin which is not yet labeled.
+		 */
+		if (count >= threshold) && (labels[i] == 0) {
+			label++
+			labels[i] = label
+			idxStart := uint64(i)
+			queue = append(queue[:0], idxStart)
+			numBinsCluster := uint64(0)
+			total := uint64(0)
+			sumX := float64(0.0)
+			sumY := float64(0.0)
+
+			/*
+			 * Flood-fill the cluster.
+			 */
+			for len(queue) > 0 {
+				last := len(queue) - 1
+				idx := queue[last]
+				queue = queue[:last]
+				x := uint32(idx % width64)
+				y := uint32(idx / width64)
+				val := bins[idx]
+				valFloat := float64(val)
+				pos := this.position(x, y)
+				numBinsCluster++
+				totalOld := total
+				total += val
+
+				/*
+				 * Check for overflow.
+				 */
+				if total < totalOld {
+					total = math.MaxUint64
+				}
+
+				sumX += valFloat * pos.X()
+				sumY += valFloat * pos.Y()
+				x64 := int64(x)
+				y64 := int64(y)
+
+				/*
+				 * Visit the neighbouring rows.
+				 */
+				for j := int64(-1); j <= 1; j++ {
+
+					/*
+					 * Visit the neighbouring columns.
+					 */
+					for k := int64(-1); k <= 1; k++ {
+						xx64 := x64 + k
+						yy64 := y64 + j
+
+						/*
+						 * Check if neighbour lies within the scene.
+						 */
+						if xx64 >= 0 && xx64 <= math.MaxUint32 && yy64 >= 0 && yy64 <= math.MaxUint32 {
+							xx := uint32(xx64)
+							yy := uint32(yy64)
+							idxNeighbour, ok := this.index(xx, yy)
+
+							/*
+							 * Add dense, unlabeled neighbours to the cluster.
+							 */
+							if ok && (bins[idxNeighbour] >= threshold) && (labels[idxNeighbour] == 0) {
+								labels[idxNeighbour] = label
+								queue = append(queue, idxNeighbour)
+							}
+
+						}
+
+					}
+
+				}
+
+			}
+
+			totalFloat := float64(total)
+			centroidX := sumX / totalFloat
+			centroidY := sumY / totalFloat
+			centroid := coordinates.CreateCartesian(centroidX, centroidY)
+			numBinsFloat := float64(numBinsCluster)
+			area := numBinsFloat * binArea
+
+			/*
+			 * Create cluster.
+			 */
+			c := Cluster{
+				area:     area,
+				bins:     numBinsCluster,
+				centroid: centroid,
+				count:    total,
+				label:    label,
+			}
+
+			result = append(result, c)
+		}
+
+	}
+
+	return result
+}
@@ -0,0 +1,87 @@
+package scene
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"image"
+	imagecolor "image/color"
+)
+
+/*
+ * Render several scenes, one per category, into a single image, whose hue
+ * reflects the mixture of categories and whose brightness reflects the total
+ * density of each pixel.
+ *
+ * All scenes must have been created by this package with identical
+ * dimensions and there must be one color per scene. See color.Composite for
+ * the supported options. Hillshading, the background color of the scenes and
+ * post-processing filters are not applied.
+ */
+func Composite(scenes []Scene, colors []imagecolor.NRGBA, options ...color.Option) (*image.NRGBA, error) {
+	numScenes := len(scenes)
+
+	/*
+	 * Verify that there is at least one scene.
+	 */
+	if numScenes == 0 {
+		return nil, fmt.Errorf("%s", "At least one scene is required for compositing.")
+	} else {
+		counts := make([][]uint64, numScenes)
+		width := uint32(0)
+		height := uint32(0)
+
+		/*
+		 * Collect the bins of all scenes.
+		 */
+		for i, scn := range scenes {
+			s, ok := scn.(*sceneStruct)
+
+			/*
+			 * Verify that scene is supported and dimensions match.
+			 */
+			if !ok || (s == nil) {
+				return nil, fmt.Errorf("Scene %d is not supported for compositing.", i)
+			} else if i == 0 {
+				width = s.width
+				height = s.height
+			} else if (s.width != width) || (s.height != height) {
+				return nil, fmt.Errorf("Scene %d has dimensions (%d * %d), but expected (%d * %d).", i, s.width, s.height, width, height)
+			}
+
+			counts[i] = s.bins
+		}
+
+		composite, err := color.Composite(counts, colors, options...)
+
+		/*
+		 * Check if colors could be composited.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			widthInt := int(width)
+			heightInt := int(height)
+			rect := image.Rect(0, 0, widthInt, heightInt)
+			img := image.NewNRGBA(rect)
+			pix := img.Pix
+
+			/*
+			 * Bins and pixels are both stored in row-major order
+			 * without padding, so pixel data can be written in a
+			 * single linear pass.
+			 */
+			for i, c := range composite {
+				offset := 4 * i
+				p := pix[offset : offset+4 : offset+4]
+				p[0] = c.R
+				p[1] = c.G
+				p[2] = c.B
+				p[3] = c.A
+			}
+
+			return img, nil
+		}
+
+	}
+
+}
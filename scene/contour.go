@@ -0,0 +1,452 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+)
+
+/*
+ * Orientations of the edges between two adjacent grid nodes.
+ */
+const (
+	edgeHorizontal = 0
+	edgeVertical   = 1
+)
+
+/*
+ * Data structure representing an iso-density line extracted from a scene.
+ *
+ * Contours are immutable.
+ */
+type Contour struct {
+	closed bool
+	level  float64
+	points []coordinates.Cartesian
+}
+
+/*
+ * Data structure identifying the edge between two adjacent grid nodes.
+ *
+ * Horizontal edges connect node (x, y) with node (x + 1, y), vertical edges
+ * connect node (x, y) with node (x, y + 1).
+ */
+type edgeKey struct {
+	orientation uint8
+	x           int64
+	y           int64
+}
+
+/*
+ * Data structure representing a line segment within a single grid cell,
+ * connecting the crossing points on two of its edges.
+ */
+type contourSegment struct {
+	from edgeKey
+	to   edgeKey
+	used bool
+}
+
+/*
+ * Returns whether this contour is a closed ring.
+ *
+ * The first point of a closed contour is not repeated at its end.
+ */
+func (this *Contour) Closed() bool {
+	return this.closed
+}
+
+/*
+ * Returns the density level of this contour.
+ */
+func (this *Contour) Level() float64 {
+	return this.level
+}
+
+/*
+ * Returns the points of this contour in data space.
+ */
+func (this *Contour) Points() []coordinates.Cartesian {
+	points := this.points
+	numPoints := len(points)
+	result := make([]coordinates.Cartesian, numPoints)
+	copy(result, points)
+	return result
+}
+
+/*
+ * Returns the count stored at a grid node.
+ *
+ * Nodes outside the scene have a count of zero, so that all contours are
+ * closed at the boundary of the scene.
+ */
+func (this *sceneStruct) nodeValue(x int64, y int64) float64 {
+	width64 := int64(this.width)
+	height64 := int64(this.height)
+
+	/*
+	 * Check if node lies within the scene.
+	 */
+	if (x < 0) || (y < 0) || (x >= width64) || (y >= height64) {
+		return 0.0
+	} else {
+		idx := (width64 * y) + x
+		val := this.bins[idx]
+		return float64(val)
+	}
+
+}
+
+/*
+ * Calculate the position in data space at which a contour line at a certain
+ * level crosses an edge of the grid.
+ */
+func (this *sceneStruct) crossing(edge edgeKey, level float64) coordinates.Cartesian {
+	x0 := edge.x
+	y0 := edge.y
+	x1 := x0
+	y1 := y0
+
+	/*
+	 * Determine the second node of the edge.
+	 */
+	if edge.orientation == edgeHorizontal {
+		x1++
+	} else {
+		y1++
+	}
+
+	v0 := this.nodeValue(x0, y0)
+	v1 := this.nodeValue(x1, y1)
+	t := float64(0.5)
+
+	/*
+	 * Interpolate linearly between the two nodes.
+	 */
+	if v1 != v0 {
+		t = (level - v0) / (v1 - v0)
+	}
+
+	x0Float := float64(x0)
+	y0Float := float64(y0)
+	x1Float := float64(x1)
+	y1Float := float64(y1)
+	fx := x0Float + (t * (x1Float - x0Float)) + 0.5
+	fy := y0Float + (t * (y1Float - y0Float)) + 0.5
+	widthFloat := float64(this.width)
+	heightFloat := float64(this.height)
+	minX := this.minX
+	maxY := this.maxY
+	sizeX := (this.maxX - minX) / widthFloat
+	sizeY := (maxY - this.minY) / heightFloat
+	posX := minX + (fx * sizeX)
+	posY := maxY - (fy * sizeY)
+	pos := coordinates.CreateCartesian(posX, posY)
+	return pos
+}
+
+/*
+ * Run the marching squares algorithm on the grid for a single level and
+ * return the resulting line segments.
+ */
+func (this *sceneStruct) marchingSquares(level float64) []contourSegment {
+	width64 := int64(this.width)
+	height64 := int64(this.height)
+	segments := []contourSegment{}
+
+	/*
+	 * Iterate over the rows of cells, including a border of empty nodes.
+	 */
+	for y := int64(-1); y < height64; y++ {
+
+		/*
+		 * Iterate over the columns of cells.
+		 */
+		for x := int64(-1); x < width64; x++ {
+			tl := this.nodeValue(x, y)
+			tr := this.nodeValue(x+1, y)
+			br := this.nodeValue(x+1, y+1)
+			bl := this.nodeValue(x, y+1)
+			cell := uint8(0)
+
+			/*
+			 * Check which corners lie inside the contour.
+			 */
+			if tl >= level {
+				cell |= 8
+			}
+
+			if tr >= level {
+				cell |= 4
+			}
+
+			if br >= level {
+				cell |= 2
+			}
+
+			if bl >= level {
+				cell |= 1
+			}
+
+			/*
+			 * The four edges of this cell.
+			 */
+			top := edgeKey{orientation: edgeHorizontal, x: x, y: y}
+			right := edgeKey{orientation: edgeVertical, x: x + 1, y: y}
+			bottom := edgeKey{orientation: edgeHorizontal, x: x, y: y + 1}
+			left := edgeKey{orientation: edgeVertical, x: x, y: y}
+			center := 0.25 * (tl + tr + br + bl)
+			centerInside := center >= level
+			pairs := [][2]edgeKey{}
+
+			/*
+			 * Look up the segments for this cell configuration.
+			 */
+			switch cell {
+			case 1, 14:
+				pairs = append(pairs, [2]edgeKey{left, bottom})
+			case 2, 13:
+				pairs = append(pairs, [2]edgeKey{bottom, right})
+			case 3, 12:
+				pairs = append(pairs, [2]edgeKey{left, right})
+			case 4, 11:
+				pairs = append(pairs, [2]edgeKey{top, right})
+			case 6, 9:
+				pairs = append(pairs, [2]edgeKey{top, bottom})
+			case 7, 8:
+				pairs = append(pairs, [2]edgeKey{left, top})
+			case 5:
+
+				/*
+				 * Resolve saddle point using the center value.
+				 */
+				if centerInside {
+					pairs = append(pairs, [2]edgeKey{left, top}, [2]edgeKey{bottom, right})
+				} else {
+					pairs = append(pairs, [2]edgeKey{left, bottom}, [2]edgeKey{top, right})
+				}
+
+			case 10:
+
+				/*
+				 * Resolve saddle point using the center value.
+				 */
+				if centerInside {
+					pairs = append(pairs, [2]edgeKey{top, right}, [2]edgeKey{left, bottom})
+				} else {
+					pairs = append(pairs, [2]edgeKey{left, top}, [2]edgeKey{bottom, right})
+				}
+
+			}
+
+			/*
+			 * Store segments.
+			 */
+			for _, pair := range pairs {
+
+				/*
+				 * Create segment.
+				 */
+				seg := contourSegment{
+					from: pair[0],
+					to:   pair[1],
+				}
+
+				segments = append(segments, seg)
+			}
+
+		}
+
+	}
+
+	return segments
+}
+
+/*
+ * Join line segments sharing an edge into polylines.
+ */
+func joinSegments(segments []contourSegment) [][]edgeKey {
+	adjacency := make(map[edgeKey][]int)
+
+	/*
+	 * Register each segment with both of its edges.
+	 */
+	for i := range segments {
+		seg := &segments[i]
+		adjacency[seg.from] = append(adjacency[seg.from], i)
+		adjacency[seg.to] = append(adjacency[seg.to], i)
+	}
+
+	/*
+	 * Find an unused segment touching an edge and return the edge at its
+	 * other end.
+	 */
+	next := func(edge edgeKey) (edgeKey, bool) {
+		candidates := adjacency[edge]
+
+		/*
+		 * Look for an unused segment.
+		 */
+		for _, i := range candidates {
+			seg := &segments[i]
+
+			/*
+			 * Use this segment if it has not been used yet.
+			 */
+			if !seg.used {
+				seg.used = true
+
+				/*
+				 * Return the edge on the other end.
+				 */
+				if seg.from == edge {
+					return seg.to, true
+				} else {
+					return seg.from, true
+				}
+
+			}
+
+		}
+
+		return edgeKey{}, false
+	}
+
+	lines := [][]edgeKey{}
+
+	/*
+	 * Start a new polyline at each unused segment.
+	 */
+	for i := range segments {
+		seg := &segments[i]
+
+		/*
+		 * Only start at unused segments.
+		 */
+		if !seg.used {
+			seg.used = true
+			forward := []edgeKey{seg.from, seg.to}
+
+			/*
+			 * Extend the polyline forward.
+			 */
+			for {
+				last := forward[len(forward)-1]
+				edge, ok := next(last)
+
+				/*
+				 * Stop when there is no further segment.
+				 */
+				if !ok {
+					break
+				}
+
+				forward = append(forward, edge)
+			}
+
+			backward := []edgeKey{}
+
+			/*
+			 * Extend the polyline backward.
+			 */
+			for {
+				first := forward[0]
+
+				/*
+				 * Backward extension continues from the first edge
+				 * or from the last one added.
+				 */
+				if len(backward) > 0 {
+					first = backward[len(backward)-1]
+				}
+
+				edge, ok := next(first)
+
+				/*
+				 * Stop when there is no further segment.
+				 */
+				if !ok {
+					break
+				}
+
+				backward = append(backward, edge)
+			}
+
+			numBackward := len(backward)
+			numForward := len(forward)
+			line := make([]edgeKey, 0, numBackward+numForward)
+
+			/*
+			 * Prepend the backward extension in reverse order.
+			 */
+			for j := numBackward - 1; j >= 0; j-- {
+				line = append(line, backward[j])
+			}
+
+			line = append(line, forward...)
+			lines = append(lines, line)
+		}
+
+	}
+
+	return lines
+}
+
+/*
+ * Extract iso-density contour lines from the scene using the marching squares
+ * algorithm.
+ *
+ * For each level, the scene is treated as a grid of counts sampled at the bin
+ * centers and the lines separating bins with counts of at least level from
+ * bins with lower counts are returned in data coordinates. Since the area
+ * outside the scene is treated as empty, contours at positive levels are
+ * always closed.
+ */
+func (this *sceneStruct) Contours(levels []float64) []Contour {
+	result := []Contour{}
+
+	/*
+	 * Extract contours for each level.
+	 */
+	for _, level := range levels {
+		segments := this.marchingSquares(level)
+		lines := joinSegments(segments)
+
+		/*
+		 * Convert each polyline into a contour.
+		 */
+		for _, line := range lines {
+			numEdges := len(line)
+			closed := (numEdges > 2) && (line[0] == line[numEdges-1])
+
+			/*
+			 * Do not repeat the first point of a closed contour.
+			 */
+			if closed {
+				line = line[:numEdges-1]
+			}
+
+			numPoints := len(line)
+			points := make([]coordinates.Cartesian, numPoints)
+
+			/*
+			 * Calculate the crossing point for each edge.
+			 */
+			for i, edge := range line {
+				points[i] = this.crossing(edge, level)
+			}
+
+			/*
+			 * Create contour.
+			 */
+			c := Contour{
+				closed: closed,
+				level:  level,
+				points: points,
+			}
+
+			result = append(result, c)
+		}
+
+	}
+
+	return result
+}
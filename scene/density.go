@@ -0,0 +1,498 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Fixed-point scale applied to density estimates, so that they can be stored
+ * in the (integer) bins of a scene without losing the fractional tails of the
+ * kernel.
+ *
+ * Like counts, fixed-point values are limited to math.MaxUint32, so that bins
+ * receiving the mass of more than math.MaxUint32 / DENSITY_SCALE (i. e. about
+ * 65,000) data points saturate.
+ */
+const (
+	DENSITY_SCALE = 1 << 16
+)
+
+/*
+ * Number of standard deviations after which the Gaussian kernel is truncated.
+ *
+ * Kernels are never wider than the axis they are applied to, so that very
+ * large bandwidths are truncated at the size of the scene instead.
+ */
+const (
+	KERNEL_RADIUS = 4.0
+)
+
+/*
+ * Estimate the kernel bandwidth along both axes from a set of data points,
+ * each carrying a weight, using Silverman's rule of thumb for two-dimensional
+ * data.
+ *
+ * If weights is nil, each data point has a weight of one.
+ */
+func weightedBandwidth(data []coordinates.Cartesian, weights []uint64) (float64, float64) {
+	n := float64(0.0)
+	meanX := float64(0.0)
+	meanY := float64(0.0)
+	m2X := float64(0.0)
+	m2Y := float64(0.0)
+
+	/*
+	 * Calculate weighted mean and variance using West's algorithm.
+	 */
+	for i := range data {
+		point := &data[i]
+		x := point.X()
+		y := point.Y()
+		weight := float64(1.0)
+
+		/*
+		 * Use the weight of the data point if there is one.
+		 */
+		if weights != nil {
+			weight = float64(weights[i])
+		}
+
+		/*
+		 * Only consider valid data points with positive weight.
+		 */
+		if (weight > 0.0) && !math.IsNaN(x) && !math.IsNaN(y) && !math.IsInf(x, 0) && !math.IsInf(y, 0) {
+			n += weight
+			deltaX := x - meanX
+			deltaY := y - meanY
+			meanX += (weight / n) * deltaX
+			meanY += (weight / n) * deltaY
+			m2X += weight * deltaX * (x - meanX)
+			m2Y += weight * deltaY * (y - meanY)
+		}
+
+	}
+
+	/*
+	 * At least two data points are required to estimate the spread.
+	 */
+	if n < 2.0 {
+		return 0.0, 0.0
+	} else {
+		sigmaX := math.Sqrt(m2X / (n - 1.0))
+		sigmaY := math.Sqrt(m2Y / (n - 1.0))
+		factor := math.Pow(n, -1.0/6.0)
+		bandwidthX := factor * sigmaX
+		bandwidthY := factor * sigmaY
+		return bandwidthX, bandwidthY
+	}
+
+}
+
+/*
+ * Estimate the kernel bandwidth (standard deviation of the Gaussian kernel)
+ * along both axes from a set of data points using Silverman's rule of thumb
+ * for two-dimensional data.
+ */
+func Bandwidth(data []coordinates.Cartesian) (float64, float64) {
+	return weightedBandwidth(data, nil)
+}
+
+/*
+ * Estimate the kernel bandwidth along both axes from the data aggregated into
+ * the scene in the same way as Bandwidth.
+ *
+ * Each data point is assumed to lie at the center of its bin, so each
+ * non-empty bin is treated as a single point weighted by its count.
+ */
+func (this *sceneStruct) bandwidth() (float64, float64) {
+	bins := this.bins
+	width64 := uint64(this.width)
+	positions := []coordinates.Cartesian{}
+	weights := []uint64{}
+
+	/*
+	 * Collect the center and count of each non-empty bin.
+	 */
+	for i, count := range bins {
+
+		/*
+		 * Only consider non-empty bins.
+		 */
+		if count > 0 {
+			idx := uint64(i)
+			x := uint32(idx % width64)
+			y := uint32(idx / width64)
+			pos := this.position(x, y)
+			positions = append(positions, pos)
+			weights = append(weights, count)
+		}
+
+	}
+
+	return weightedBandwidth(positions, weights)
+}
+
+/*
+ * Create a normalized, truncated Gaussian kernel with a standard deviation of
+ * sigma bins, which is applied to an axis of size bins.
+ */
+func gaussianKernel(sigma float64, size int) []float64 {
+
+	/*
+	 * A kernel without (valid) width does not smooth at all.
+	 */
+	if !(sigma > 0.0) {
+		kernel := []float64{1.0}
+		return kernel
+	} else {
+		radius := int(math.Min(math.Ceil(KERNEL_RADIUS*sigma), float64(size)))
+		size := (2 * radius) + 1
+		kernel := make([]float64, size)
+		twoSigmaSquared := 2.0 * sigma * sigma
+		sum := float64(0.0)
+
+		/*
+		 * Sample the Gaussian function.
+		 */
+		for i := range kernel {
+			d := float64(i - radius)
+			val := math.Exp(-(d * d) / twoSigmaSquared)
+			kernel[i] = val
+			sum += val
+		}
+
+		/*
+		 * Normalize the kernel to unit sum.
+		 */
+		for i := range kernel {
+			kernel[i] /= sum
+		}
+
+		return kernel
+	}
+
+}
+
+/*
+ * Convolve a row-major grid along one axis with a kernel.
+ *
+ * If vertical is false, each row is convolved, otherwise each column.
+ */
+func convolve(dst []float64, src []float64, width int, height int, kernel []float64, vertical bool) {
+	radius := len(kernel) / 2
+
+	/*
+	 * Iterate over the rows of the grid.
+	 */
+	for y := 0; y < height; y++ {
+
+		/*
+		 * Iterate over the columns of the grid.
+		 */
+		for x := 0; x < width; x++ {
+			sum := float64(0.0)
+
+			/*
+			 * Apply the kernel.
+			 */
+			for k, weight := range kernel {
+				offset := k - radius
+				xx := x
+				yy := y
+
+				/*
+				 * Decide on the axis of the convolution.
+				 */
+				if vertical {
+					yy += offset
+				} else {
+					xx += offset
+				}
+
+				/*
+				 * Check if sample lies within the grid.
+				 */
+				if (xx >= 0) && (xx < width) && (yy >= 0) && (yy < height) {
+					idx := (yy * width) + xx
+					sum += weight * src[idx]
+				}
+
+			}
+
+			idx := (y * width) + x
+			dst[idx] = sum
+		}
+
+	}
+
+}
+
+/*
+ * Convert density estimates to fixed-point values, storing them in a set of
+ * bins.
+ *
+ * Values are limited to math.MaxUint32, like the counts of a scene.
+ */
+func toFixedPoint(bins []uint64, grid []float64) {
+
+	/*
+	 * Convert each density estimate.
+	 */
+	for i, val := range grid {
+		val = math.Round(DENSITY_SCALE * val)
+
+		/*
+		 * Make sure we are not exceeding datatype bounds.
+		 */
+		if val >= math.MaxUint32 {
+			bins[i] = math.MaxUint32
+		} else if val > 0.0 {
+			bins[i] = uint64(val)
+		} else {
+			bins[i] = 0
+		}
+
+	}
+
+}
+
+/*
+ * Create a scene of the same size, region and rendering settings as this
+ * scene, which holds a different set of bins.
+ */
+func (this *sceneStruct) derive(bins []uint64) *sceneStruct {
+	filters := make([]Filter, len(this.filters))
+	copy(filters, this.filters)
+
+	/*
+	 * Create derived scene data structure.
+	 */
+	scn := sceneStruct{
+		background: this.background,
+		bins:       bins,
+		dithering:  this.dithering,
+		filters:    filters,
+		height:     this.height,
+		light:      this.light,
+		maxX:       this.maxX,
+		maxY:       this.maxY,
+		minX:       this.minX,
+		minY:       this.minY,
+		width:      this.width,
+	}
+
+	return &scn
+}
+
+/*
+ * Create a new scene holding a kernel density estimate of the data
+ * aggregated into this scene, which is left unchanged.
+ *
+ * Each data point aggregated into the scene is replaced by a Gaussian kernel
+ * with standard deviations of bandwidthX and bandwidthY in data space. Since
+ * data points are assumed to lie at the centers of their bins, this is a
+ * fast approximation of CreateEstimate and a higher-quality alternative to
+ * Spread.
+ *
+ * If either bandwidth is zero or negative, both are estimated from the data
+ * aggregated into the scene as by Bandwidth.
+ *
+ * Since the kernel is normalized, each data point contributes a total of
+ * DENSITY_SCALE to the bins of the estimate. The estimate inherits the
+ * background, filters and hillshading of this scene.
+ */
+func (this *sceneStruct) Estimate(bandwidthX float64, bandwidthY float64) Scene {
+
+	/*
+	 * Estimate bandwidth automatically if required.
+	 */
+	if (bandwidthX <= 0.0) || (bandwidthY <= 0.0) {
+		bandwidthX, bandwidthY = this.bandwidth()
+	}
+
+	numBins := len(this.bins)
+	width := this.width
+	widthInt := int(width)
+	widthFloat := float64(width)
+	height := this.height
+	heightInt := int(height)
+	heightFloat := float64(height)
+	scaleX := widthFloat / (this.maxX - this.minX)
+	scaleY := heightFloat / (this.maxY - this.minY)
+	sigmaX := math.Abs(bandwidthX * scaleX)
+	sigmaY := math.Abs(bandwidthY * scaleY)
+	kernelX := gaussianKernel(sigmaX, widthInt)
+	kernelY := gaussianKernel(sigmaY, heightInt)
+	grid := make([]float64, numBins)
+	tmp := make([]float64, numBins)
+
+	/*
+	 * Convert counts to floating-point values.
+	 */
+	for i, count := range this.bins {
+		grid[i] = float64(count)
+	}
+
+	convolve(tmp, grid, widthInt, heightInt, kernelX, false)
+	convolve(grid, tmp, widthInt, heightInt, kernelY, true)
+	bins := make([]uint64, numBins)
+	toFixedPoint(bins, grid)
+	return this.derive(bins)
+}
+
+/*
+ * Calculate the weights of a Gaussian kernel centered at a position along an
+ * axis of bins, which is given in units of bins.
+ *
+ * Returns the index of the first bin receiving weight and the weights of it
+ * and the following bins. The weights of all bins within the kernel,
+ * including those outside the axis, sum to one.
+ */
+func axisWeights(pos float64, sigma float64, count int) (int, []float64) {
+	countFloat := float64(count)
+	nearest := math.Floor(pos)
+	radius := float64(0.0)
+
+	/*
+	 * Truncate the kernel, but never beyond the size of the axis.
+	 */
+	if sigma > 0.0 {
+		radius = math.Min(math.Ceil(KERNEL_RADIUS*sigma), countFloat)
+	}
+
+	/*
+	 * Check if kernel overlaps the axis before converting positions to
+	 * integers, which is safe afterwards.
+	 */
+	if !(nearest+radius >= 0.0) || !(nearest-radius < countFloat) {
+		return 0, nil
+	} else if !(sigma > 0.0) {
+		weights := []float64{1.0}
+		return int(nearest), weights
+	} else {
+		center := int(nearest)
+		radiusInt := int(radius)
+		first := center - radiusInt
+		last := center + radiusInt
+		twoSigmaSquared := 2.0 * sigma * sigma
+		sum := float64(0.0)
+
+		/*
+		 * Sum the weights of all bins within the kernel.
+		 */
+		for j := first; j <= last; j++ {
+			d := (float64(j) + 0.5) - pos
+			sum += math.Exp(-(d * d) / twoSigmaSquared)
+		}
+
+		start := first
+		end := last
+
+		/*
+		 * Clamp the first bin to the axis.
+		 */
+		if start < 0 {
+			start = 0
+		}
+
+		/*
+		 * Clamp the last bin to the axis.
+		 */
+		if end > count-1 {
+			end = count - 1
+		}
+
+		/*
+		 * Check if kernel has any weight.
+		 */
+		if !(sum > 0.0) {
+			return 0, nil
+		} else {
+			weights := make([]float64, (end-start)+1)
+
+			/*
+			 * Calculate the normalized weight of each bin within the
+			 * axis.
+			 */
+			for i := range weights {
+				d := (float64(start+i) + 0.5) - pos
+				weights[i] = math.Exp(-(d*d)/twoSigmaSquared) / sum
+			}
+
+			return start, weights
+		}
+
+	}
+
+}
+
+/*
+ * Create a new scene holding a kernel density estimate of a set of data
+ * points, which places a Gaussian kernel with standard deviations of
+ * bandwidthX and bandwidthY in data space at the exact position of each
+ * point.
+ *
+ * This is more accurate than aggregating the points and calling Estimate,
+ * especially for bandwidths close to the size of a bin, but takes time
+ * proportional to the number of points. If either bandwidth is zero or
+ * negative, both are estimated from the data points using Bandwidth.
+ *
+ * Since the kernel is normalized, each data point contributes a total of
+ * DENSITY_SCALE to the bins, less the part of its kernel outside the scene.
+ */
+func CreateEstimate(width uint32, height uint32, minX float64, maxX float64, minY float64, maxY float64, data []coordinates.Cartesian, bandwidthX float64, bandwidthY float64) Scene {
+	scn := Create(width, height, minX, maxX, minY, maxY).(*sceneStruct)
+
+	/*
+	 * Estimate bandwidth automatically if required.
+	 */
+	if (bandwidthX <= 0.0) || (bandwidthY <= 0.0) {
+		bandwidthX, bandwidthY = Bandwidth(data)
+	}
+
+	widthInt := int(width)
+	heightInt := int(height)
+	scaleX := float64(width) / (maxX - minX)
+	scaleY := float64(height) / (maxY - minY)
+	sigmaX := math.Abs(bandwidthX * scaleX)
+	sigmaY := math.Abs(bandwidthY * scaleY)
+	grid := make([]float64, len(scn.bins))
+
+	/*
+	 * Add the kernel of each data point.
+	 */
+	for i := range data {
+		point := &data[i]
+		posX := (point.X() - minX) * scaleX
+		posY := (maxY - point.Y()) * scaleY
+
+		/*
+		 * Skip invalid data points.
+		 */
+		if !math.IsNaN(posX) && !math.IsNaN(posY) && !math.IsInf(posX, 0) && !math.IsInf(posY, 0) {
+			startX, weightsX := axisWeights(posX, sigmaX, widthInt)
+			startY, weightsY := axisWeights(posY, sigmaY, heightInt)
+
+			/*
+			 * Add the kernel to each row it overlaps.
+			 */
+			for j, weightY := range weightsY {
+				offset := ((startY + j) * widthInt) + startX
+
+				/*
+				 * Add the kernel to each bin of the row.
+				 */
+				for k, weightX := range weightsX {
+					grid[offset+k] += weightX * weightY
+				}
+
+			}
+
+		}
+
+	}
+
+	toFixedPoint(scn.bins, grid)
+	return scn
+}
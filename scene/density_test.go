@@ -0,0 +1,320 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"testing"
+)
+
+/*
+ * Returns the sum of all bins of a scene.
+ */
+func binSum(scn Scene) float64 {
+	sum := float64(0.0)
+
+	/*
+	 * Add the count of each bin.
+	 */
+	for _, count := range scn.(*sceneStruct).bins {
+		sum += float64(count)
+	}
+
+	return sum
+}
+
+/*
+ * Silverman's rule applied to the corners of a square.
+ */
+func TestBandwidth(t *testing.T) {
+
+	/*
+	 * The corners of a square, along with invalid points to be ignored.
+	 */
+	data := []coordinates.Cartesian{
+		coordinates.CreateCartesian(0.0, 0.0),
+		coordinates.CreateCartesian(2.0, 0.0),
+		coordinates.CreateCartesian(math.NaN(), 1.0),
+		coordinates.CreateCartesian(0.0, 4.0),
+		coordinates.CreateCartesian(2.0, 4.0),
+		coordinates.CreateCartesian(1.0, math.Inf(1)),
+	}
+
+	bandwidthX, bandwidthY := Bandwidth(data)
+	factor := math.Pow(4.0, -1.0/6.0)
+	expectedX := factor * math.Sqrt(4.0/3.0)
+	expectedY := factor * math.Sqrt(16.0/3.0)
+
+	/*
+	 * Check the bandwidth.
+	 */
+	if (math.Abs(bandwidthX-expectedX) > 1e-12) || (math.Abs(bandwidthY-expectedY) > 1e-12) {
+		t.Errorf("Bandwidth is (%f, %f), expected (%f, %f).", bandwidthX, bandwidthY, expectedX, expectedY)
+	}
+
+	bandwidthX, bandwidthY = Bandwidth(data[:1])
+
+	/*
+	 * A single point has no spread.
+	 */
+	if (bandwidthX != 0.0) || (bandwidthY != 0.0) {
+		t.Errorf("Bandwidth of a single point is (%f, %f), expected zero.", bandwidthX, bandwidthY)
+	}
+
+}
+
+/*
+ * The estimate of a scene holds the mass of its data points and leaves the
+ * scene unchanged.
+ */
+func TestEstimate(t *testing.T) {
+	scn := Create(32, 32, 0.0, 32.0, 0.0, 32.0)
+
+	/*
+	 * Points far enough from the edges to keep their kernels within the
+	 * scene.
+	 */
+	data := []coordinates.Cartesian{
+		coordinates.CreateCartesian(12.5, 12.5),
+		coordinates.CreateCartesian(12.5, 12.5),
+		coordinates.CreateCartesian(16.5, 20.5),
+		coordinates.CreateCartesian(19.5, 14.5),
+	}
+
+	scn.Aggregate(data)
+	estimate := scn.Estimate(1.5, 1.0)
+	sum := binSum(estimate)
+	expected := float64(len(data)) * DENSITY_SCALE
+	numBins := float64(32 * 32)
+
+	/*
+	 * Each bin may be off by rounding.
+	 */
+	if math.Abs(sum-expected) > 0.5*numBins {
+		t.Errorf("Estimate holds a mass of %f, expected %f.", sum, expected)
+	}
+
+	/*
+	 * The scene itself keeps its counts.
+	 */
+	if binSum(scn) != float64(len(data)) {
+		t.Errorf("Scene holds %f points after estimation, expected %d.", binSum(scn), len(data))
+	}
+
+	direct := CreateEstimate(32, 32, 0.0, 32.0, 0.0, 32.0, data, 1.5, 1.0)
+	bins := estimate.(*sceneStruct).bins
+	directBins := direct.(*sceneStruct).bins
+
+	/*
+	 * For points at the centers of bins, both estimates agree.
+	 */
+	for i := range bins {
+		difference := float64(bins[i]) - float64(directBins[i])
+
+		/*
+		 * Check if bins agree up to rounding.
+		 */
+		if math.Abs(difference) > 1.0 {
+			t.Errorf("Bin %d holds %d, but %d when estimated per point.", i, bins[i], directBins[i])
+		}
+
+	}
+
+}
+
+/*
+ * The kernel of a single point is symmetric around its position.
+ */
+func TestCreateEstimateSymmetric(t *testing.T) {
+
+	/*
+	 * A single point on the corner of four bins.
+	 */
+	data := []coordinates.Cartesian{
+		coordinates.CreateCartesian(8.0, 8.0),
+	}
+
+	scn := CreateEstimate(16, 16, 0.0, 16.0, 0.0, 16.0, data, 1.5, 1.5)
+	bins := scn.(*sceneStruct).bins
+	sum := binSum(scn)
+
+	/*
+	 * Check the mass of the kernel.
+	 */
+	if math.Abs(sum-DENSITY_SCALE) > 0.5*256.0 {
+		t.Errorf("Kernel holds a mass of %f, expected %d.", sum, DENSITY_SCALE)
+	}
+
+	/*
+	 * Compare each bin with its mirror images.
+	 */
+	for y := 0; y < 16; y++ {
+
+		/*
+		 * Compare each bin of the row.
+		 */
+		for x := 0; x < 16; x++ {
+			val := bins[(16*y)+x]
+			mirrorX := bins[(16*y)+(15-x)]
+			mirrorY := bins[(16*(15-y))+x]
+			transposed := bins[(16*x)+y]
+
+			/*
+			 * Check if kernel is symmetric.
+			 */
+			if (val != mirrorX) || (val != mirrorY) || (val != transposed) {
+				t.Errorf("Kernel is not symmetric at (%d, %d).", x, y)
+			}
+
+		}
+
+	}
+
+	center := bins[(16*7)+7]
+
+	/*
+	 * The bins next to the point hold the maximum.
+	 */
+	for i, val := range bins {
+
+		/*
+		 * Check if bin exceeds the center.
+		 */
+		if val > center {
+			t.Errorf("Bin %d holds %d, which exceeds the center of %d.", i, val, center)
+		}
+
+	}
+
+}
+
+/*
+ * Without bandwidth, the estimate of a single point is a single bin.
+ */
+func TestCreateEstimateSinglePoint(t *testing.T) {
+
+	/*
+	 * A single point, from which no bandwidth can be estimated.
+	 */
+	data := []coordinates.Cartesian{
+		coordinates.CreateCartesian(2.5, 1.5),
+	}
+
+	scn := CreateEstimate(4, 4, 0.0, 4.0, 0.0, 4.0, data, 0.0, 0.0)
+	bins := scn.(*sceneStruct).bins
+
+	/*
+	 * Check each bin.
+	 */
+	for i, val := range bins {
+		expected := uint64(0)
+
+		/*
+		 * The point lies in the third column of the third row.
+		 */
+		if i == (4*2)+2 {
+			expected = DENSITY_SCALE
+		}
+
+		/*
+		 * Check if bin holds the expected value.
+		 */
+		if val != expected {
+			t.Errorf("Bin %d holds %d, expected %d.", i, val, expected)
+		}
+
+	}
+
+}
+
+/*
+ * Kernels far outside an axis or wider than the axis are handled without
+ * iterating over the whole kernel.
+ */
+func TestAxisWeights(t *testing.T) {
+
+	/*
+	 * Positions and widths of kernels which do not overlap the axis.
+	 */
+	outside := []struct {
+		pos   float64
+		sigma float64
+	}{
+		{-1.0, 0.0},
+		{16.0, 0.0},
+		{math.Ldexp(1.0, 60), 1.0},
+		{-math.Ldexp(1.0, 60), 1.0},
+		{1e300, 1e200},
+		{-1e300, math.Inf(1)},
+	}
+
+	/*
+	 * Check each kernel.
+	 */
+	for _, c := range outside {
+		_, weights := axisWeights(c.pos, c.sigma, 16)
+
+		/*
+		 * Check if any bin receives weight.
+		 */
+		if weights != nil {
+			t.Errorf("Kernel at %g with sigma %g has %d weights, expected none.", c.pos, c.sigma, len(weights))
+		}
+
+	}
+
+	start, weights := axisWeights(8.5, 1e12, 16)
+	sum := float64(0.0)
+
+	/*
+	 * Sum the weights within the axis.
+	 */
+	for _, weight := range weights {
+		sum += weight
+	}
+
+	/*
+	 * A very wide kernel covers the axis, but is truncated at its size.
+	 */
+	if (start != 0) || (len(weights) != 16) {
+		t.Errorf("Wide kernel covers %d bins starting at %d, expected 16 bins starting at 0.", len(weights), start)
+	} else if math.Abs(sum-(16.0/33.0)) > 1e-9 {
+		t.Errorf("Wide kernel holds a weight of %f within the axis, expected %f.", sum, 16.0/33.0)
+	}
+
+	kernel := gaussianKernel(1e12, 16)
+
+	/*
+	 * A very wide kernel for convolution is truncated as well.
+	 */
+	if len(kernel) != 33 {
+		t.Errorf("Wide kernel has %d taps, expected 33.", len(kernel))
+	}
+
+}
+
+/*
+ * Fixed-point values saturate at the same limit as counts.
+ */
+func TestCreateEstimateSaturate(t *testing.T) {
+	numPoints := (math.MaxUint32 / DENSITY_SCALE) + 1
+	data := make([]coordinates.Cartesian, numPoints)
+
+	/*
+	 * Place all points in the same bin.
+	 */
+	for i := range data {
+		data[i] = coordinates.CreateCartesian(0.5, 0.5)
+	}
+
+	scn := CreateEstimate(2, 2, 0.0, 2.0, 0.0, 2.0, data, 0.0, 0.0)
+	bins := scn.(*sceneStruct).bins
+	val := bins[2]
+
+	/*
+	 * Check if bin saturated.
+	 */
+	if val != math.MaxUint32 {
+		t.Errorf("Bin holds %d, expected %d.", val, uint64(math.MaxUint32))
+	}
+
+}
@@ -0,0 +1,109 @@
+package scene
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"image"
+)
+
+/*
+ * Returns the difference between the counts of two scenes, i. e. the count of
+ * each bin of scene a minus the count of the same bin of scene b, in
+ * row-major order.
+ *
+ * Both scenes must have been created by this package with identical
+ * dimensions.
+ */
+func Difference(a Scene, b Scene) ([]int64, error) {
+	sa, okA := a.(*sceneStruct)
+	sb, okB := b.(*sceneStruct)
+
+	/*
+	 * Verify that scenes are supported and dimensions match.
+	 */
+	if !okA || (sa == nil) || !okB || (sb == nil) {
+		return nil, fmt.Errorf("%s", "Scene is not supported for differencing.")
+	} else if (sa.width != sb.width) || (sa.height != sb.height) {
+		return nil, fmt.Errorf("Scenes have dimensions (%d * %d) and (%d * %d), which do not match.", sa.width, sa.height, sb.width, sb.height)
+	} else {
+		binsB := sb.bins
+		values := make([]int64, len(sa.bins))
+
+		/*
+		 * Counts are limited to math.MaxUint32, so their difference
+		 * cannot overflow.
+		 */
+		for i, count := range sa.bins {
+			values[i] = int64(count) - int64(binsB[i])
+		}
+
+		return values, nil
+	}
+
+}
+
+/*
+ * Render the difference between two scenes into an image using a signed
+ * color mapping, e. g. to compare two periods of time or two datasets.
+ *
+ * Bins holding more data points in scene a than in scene b map to values above
+ * zero, the others to values below zero. Both scenes must have been created by
+ * this package with identical dimensions. Hillshading, the background color
+ * of the scenes and post-processing filters are not applied.
+ */
+func RenderDifference(a Scene, b Scene, mapping color.SignedMapping) (*image.NRGBA, error) {
+
+	/*
+	 * Verify that color mapping is non-nil.
+	 */
+	if mapping == nil {
+		return nil, fmt.Errorf("%s", "Color mapping must not be nil when rendering an image!")
+	} else {
+		values, err := Difference(a, b)
+
+		/*
+		 * Check if scenes could be differenced.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			colors := mapping.MapSigned(values)
+			numValues := len(values)
+			numColors := len(colors)
+
+			/*
+			 * Verify that the color mapping returned a result of the
+			 * expected length.
+			 */
+			if numColors != numValues {
+				return nil, fmt.Errorf("Color mapping returned %d pixels, but expected %d.", numColors, numValues)
+			} else {
+				scn := a.(*sceneStruct)
+				widthInt := int(scn.width)
+				heightInt := int(scn.height)
+				rect := image.Rect(0, 0, widthInt, heightInt)
+				img := image.NewNRGBA(rect)
+				pix := img.Pix
+
+				/*
+				 * Bins and pixels are both stored in row-major order
+				 * without padding, so pixel data can be written in a
+				 * single linear pass.
+				 */
+				for i, c := range colors {
+					offset := 4 * i
+					p := pix[offset : offset+4 : offset+4]
+					p[0] = c.R
+					p[1] = c.G
+					p[2] = c.B
+					p[3] = c.A
+				}
+
+				return img, nil
+			}
+
+		}
+
+	}
+
+}
@@ -0,0 +1,99 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/coordinates"
+	"testing"
+)
+
+/*
+ * The difference between two scenes is rendered using a diverging mapping.
+ */
+func TestRenderDifference(t *testing.T) {
+	a := Create(3, 1, 0.0, 3.0, 0.0, 1.0)
+	b := Create(3, 1, 0.0, 3.0, 0.0, 1.0)
+
+	/*
+	 * Scene a holds more points in the first bin, scene b in the last.
+	 */
+	a.Aggregate([]coordinates.Cartesian{
+		coordinates.CreateCartesian(0.5, 0.5),
+		coordinates.CreateCartesian(0.5, 0.5),
+		coordinates.CreateCartesian(1.5, 0.5),
+	})
+
+	b.Aggregate([]coordinates.Cartesian{
+		coordinates.CreateCartesian(1.5, 0.5),
+		coordinates.CreateCartesian(2.5, 0.5),
+		coordinates.CreateCartesian(2.5, 0.5),
+	})
+
+	values, err := Difference(a, b)
+	expected := []int64{2, 0, -2}
+
+	/*
+	 * Check if scenes could be differenced.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	/*
+	 * Compare each value.
+	 */
+	for i, value := range expected {
+
+		/*
+		 * Check if value matches.
+		 */
+		if values[i] != value {
+			t.Errorf("Bin %d holds a difference of %d, expected %d.", i, values[i], value)
+		}
+
+	}
+
+	mapping := color.BlueWhiteRedMapping(0)
+	img, err := RenderDifference(a, b, mapping)
+
+	/*
+	 * Check if difference could be rendered.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	colors := mapping.MapSigned(expected)
+
+	/*
+	 * Compare each pixel.
+	 */
+	for i, c := range colors {
+		pixel := img.NRGBAAt(i, 0)
+
+		/*
+		 * Check if pixel matches.
+		 */
+		if pixel != c {
+			t.Errorf("Pixel %d is %v, expected %v.", i, pixel, c)
+		}
+
+	}
+
+	/*
+	 * Positive differences map to red, negative ones to blue.
+	 */
+	if (colors[0].R <= colors[0].B) || (colors[2].B <= colors[2].R) {
+		t.Errorf("Differences map to %v and %v, expected red and blue.", colors[0], colors[2])
+	}
+
+	c := Create(2, 1, 0.0, 2.0, 0.0, 1.0)
+	_, err = Difference(a, c)
+
+	/*
+	 * Scenes of different dimensions cannot be differenced.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Differencing scenes of different dimensions did not fail.")
+	}
+
+}
@@ -0,0 +1,88 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+)
+
+/*
+ * Convert a coordinate into the index of a bin along one axis, limited to
+ * the range of valid indices.
+ */
+func binIndex(value float64, size uint32) uint32 {
+
+	/*
+	 * Clamp index into valid range.
+	 */
+	if !(value > 0.0) {
+		return 0
+	} else if value >= float64(size) {
+		return size - 1
+	} else {
+		return uint32(value)
+	}
+
+}
+
+/*
+ * Add a count to all bins whose centers lie within a polygon, e. g. to
+ * render areas like grid cells or administrative regions weighted by a
+ * value.
+ *
+ * Counts are limited to the same maximum as during aggregation.
+ */
+func (this *sceneStruct) Fill(polygon coordinates.Polygon, count uint64) {
+	width := this.width
+	height := this.height
+	bounds := polygon.Bounds()
+
+	/*
+	 * Only fill non-empty polygons within non-empty scenes.
+	 */
+	if (width > 0) && (height > 0) && (count > 0) && !bounds.IsEmpty() {
+		min := bounds.Min()
+		max := bounds.Max()
+		minX := this.minX
+		maxY := this.maxY
+		scaleX := float64(width) / (this.maxX - minX)
+		scaleY := float64(height) / (maxY - this.minY)
+		startX := binIndex((min.X()-minX)*scaleX, width)
+		endX := binIndex((max.X()-minX)*scaleX, width)
+		startY := binIndex((maxY-max.Y())*scaleY, height)
+		endY := binIndex((maxY-min.Y())*scaleY, height)
+
+		/*
+		 * Iterate over all bins within the bounding box of the polygon.
+		 */
+		for y := startY; y <= endY; y++ {
+
+			/*
+			 * Iterate over all bins of the row.
+			 */
+			for x := startX; x <= endX; x++ {
+				center := this.position(x, y)
+
+				/*
+				 * Check if center of bin lies within the polygon.
+				 */
+				if polygon.Contains(center) {
+					idx, _ := this.index(x, y)
+					sum := this.bins[idx] + count
+
+					/*
+					 * Make sure we are not exceeding datatype bounds.
+					 */
+					if (sum < count) || (sum > math.MaxUint32) {
+						sum = math.MaxUint32
+					}
+
+					this.bins[idx] = sum
+				}
+
+			}
+
+		}
+
+	}
+
+}
@@ -0,0 +1,283 @@
+package scene
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"io"
+	"math"
+	"strconv"
+)
+
+/*
+ * Number of points sampled along each edge of a scene to determine the
+ * geographic region it covers.
+ */
+const (
+	GEOREF_EDGE_SAMPLES = 64
+)
+
+/*
+ * Data structure representing the bounds of a scene as they are written.
+ */
+type boundsStruct struct {
+	MinX float64 `json:"minX"`
+	MaxX float64 `json:"maxX"`
+	MinY float64 `json:"minY"`
+	MaxY float64 `json:"maxY"`
+}
+
+/*
+ * Data structure representing the geographic region covered by a scene as it
+ * is written.
+ */
+type geographicBoundsStruct struct {
+	West  float64 `json:"west"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	North float64 `json:"north"`
+}
+
+/*
+ * Data structure representing the size of a pixel as it is written.
+ */
+type scaleStruct struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+/*
+ * Data structure representing the metadata of a rendered scene as it is
+ * written.
+ */
+type metadataStruct struct {
+	Width            uint32                  `json:"width"`
+	Height           uint32                  `json:"height"`
+	CRS              string                  `json:"crs,omitempty"`
+	Bounds           boundsStruct            `json:"bounds"`
+	Scale            scaleStruct             `json:"scale"`
+	GeographicBounds *geographicBoundsStruct `json:"geographicBounds,omitempty"`
+}
+
+/*
+ * Returns the scene data structure behind a scene, verifying that it can be
+ * georeferenced.
+ */
+func georeferenced(scn Scene) (*sceneStruct, error) {
+	s, ok := scn.(*sceneStruct)
+
+	/*
+	 * Verify that scene is supported and not empty.
+	 */
+	if !ok || (s == nil) {
+		return nil, fmt.Errorf("%s", "Scene is not supported for georeferencing.")
+	} else if (s.width == 0) || (s.height == 0) {
+		return nil, fmt.Errorf("Scene has invalid dimensions (%d * %d).", s.width, s.height)
+	} else {
+		return s, nil
+	}
+
+}
+
+/*
+ * Returns the width and height of a pixel of the scene in units of its
+ * coordinate system.
+ */
+func (this *sceneStruct) pixelSize() (float64, float64) {
+	widthFloat := float64(this.width)
+	heightFloat := float64(this.height)
+	sizeX := (this.maxX - this.minX) / widthFloat
+	sizeY := (this.maxY - this.minY) / heightFloat
+	return sizeX, sizeY
+}
+
+/*
+ * Determine the geographic region covered by the scene, sampling its edges
+ * and projecting them back into geographic coordinates.
+ *
+ * Returns nil if no point of the edges could be projected.
+ */
+func (this *sceneStruct) geographicBounds(proj projection.Projection) *geographicBoundsStruct {
+	numSamples := 4 * GEOREF_EDGE_SAMPLES
+	points := make([]coordinates.Cartesian, 0, numSamples)
+	minX := this.minX
+	maxX := this.maxX
+	minY := this.minY
+	maxY := this.maxY
+
+	/*
+	 * Sample points along all four edges.
+	 */
+	for i := 0; i < GEOREF_EDGE_SAMPLES; i++ {
+		f := float64(i) / float64(GEOREF_EDGE_SAMPLES)
+		x := minX + (f * (maxX - minX))
+		y := minY + (f * (maxY - minY))
+		xReverse := maxX - (f * (maxX - minX))
+		yReverse := maxY - (f * (maxY - minY))
+		points = append(points, coordinates.CreateCartesian(x, maxY))
+		points = append(points, coordinates.CreateCartesian(maxX, yReverse))
+		points = append(points, coordinates.CreateCartesian(xReverse, minY))
+		points = append(points, coordinates.CreateCartesian(minX, y))
+	}
+
+	locations := make([]coordinates.Geographic, numSamples)
+	proj.Inverse(locations, points)
+	bounds := coordinates.EmptyGeographicBounds()
+	valid := false
+
+	/*
+	 * Expand bounds by each location which could be projected.
+	 */
+	for _, location := range locations {
+		longitude := location.Longitude()
+		latitude := location.Latitude()
+
+		/*
+		 * Points which could not be projected are NaN.
+		 */
+		if !math.IsNaN(longitude) && !math.IsNaN(latitude) {
+			bounds = bounds.Expand(location)
+			valid = true
+		}
+
+	}
+
+	/*
+	 * Check if any point could be projected.
+	 */
+	if !valid {
+		return nil
+	} else {
+		min := bounds.Min()
+		max := bounds.Max()
+
+		/*
+		 * Create geographic bounds.
+		 */
+		result := geographicBoundsStruct{
+			West:  min.LongitudeDegrees(),
+			South: min.LatitudeDegrees(),
+			East:  max.LongitudeDegrees(),
+			North: max.LatitudeDegrees(),
+		}
+
+		return &result
+	}
+
+}
+
+/*
+ * Write a world file for an image rendered from a scene, so that it can be
+ * georeferenced by GIS tools, which do not read GeoTIFF.
+ *
+ * Store the world file next to the image, using the same base name and the
+ * extension ".pgw" for PNG images, ".jgw" for JPEG images or ".wld" for any
+ * image. A world file holds six lines: the size of a pixel along the x-axis,
+ * two rotation terms (always zero), the negative size of a pixel along the
+ * y-axis and the coordinates of the center of the upper-left pixel. All
+ * values are given in the coordinate system of the scene, i. e. the units of
+ * the projection used for aggregation.
+ */
+func WriteWorldFile(w io.Writer, scn Scene) error {
+	s, err := georeferenced(scn)
+
+	/*
+	 * Check if scene can be georeferenced.
+	 */
+	if err != nil {
+		return err
+	} else {
+		sizeX, sizeY := s.pixelSize()
+		centerX := s.minX + (0.5 * sizeX)
+		centerY := s.maxY - (0.5 * sizeY)
+		values := []float64{sizeX, 0.0, 0.0, -sizeY, centerX, centerY}
+		buffered := bufio.NewWriter(w)
+
+		/*
+		 * Write each value on its own line.
+		 */
+		for _, value := range values {
+			line := strconv.FormatFloat(value, 'f', -1, 64)
+			buffered.WriteString(line)
+			buffered.WriteByte('\n')
+		}
+
+		err = buffered.Flush()
+
+		/*
+		 * Check if world file could be written.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to write world file: %s", err.Error())
+		} else {
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Write a JSON document describing an image rendered from a scene, i. e. its
+ * dimensions, the bounds of the scene and the size of a pixel, to be stored
+ * alongside the image.
+ *
+ * The coordinate reference system, e. g. "EPSG:3857", is recorded as given
+ * and omitted if empty. If a projection is given, the geographic region
+ * covered by the image is determined using its inverse and written as well.
+ */
+func WriteMetadata(w io.Writer, scn Scene, crs string, proj projection.Projection) error {
+	s, err := georeferenced(scn)
+
+	/*
+	 * Check if scene can be georeferenced.
+	 */
+	if err != nil {
+		return err
+	} else {
+		sizeX, sizeY := s.pixelSize()
+
+		/*
+		 * Create metadata.
+		 */
+		metadata := metadataStruct{
+			Width:  s.width,
+			Height: s.height,
+			CRS:    crs,
+			Bounds: boundsStruct{
+				MinX: s.minX,
+				MaxX: s.maxX,
+				MinY: s.minY,
+				MaxY: s.maxY,
+			},
+			Scale: scaleStruct{
+				X: sizeX,
+				Y: sizeY,
+			},
+		}
+
+		/*
+		 * Determine geographic region if projection is known.
+		 */
+		if proj != nil {
+			metadata.GeographicBounds = s.geographicBounds(proj)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "\t")
+		err = encoder.Encode(&metadata)
+
+		/*
+		 * Check if metadata could be written.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to write metadata: %s", err.Error())
+		} else {
+			return nil
+		}
+
+	}
+
+}
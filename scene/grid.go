@@ -0,0 +1,298 @@
+package scene
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+/*
+ * Constants describing the raw grid format.
+ *
+ * A grid file starts with a header of GRID_HEADER_SIZE bytes, all values
+ * being stored in little-endian byte order.
+ *
+ *   Offset  Size  Content
+ *        0     8  Magic number "SYDGRID\x00"
+ *        8     2  Version of the format, currently 1
+ *       10     2  Compression of the bins (GRID_COMPRESSION_*)
+ *       12     4  Width of the grid in bins (uint32)
+ *       16     4  Height of the grid in bins (uint32)
+ *       20     8  Minimum x-coordinate (float64)
+ *       28     8  Maximum x-coordinate (float64)
+ *       36     8  Minimum y-coordinate (float64)
+ *       44     8  Maximum y-coordinate (float64)
+ *
+ * The header is followed by width * height counts (uint64) in row-major
+ * order, starting with the top row, i. e. at the maximum y-coordinate. If
+ * compression is enabled, the counts form a single zlib stream.
+ */
+const (
+	GRID_MAGIC            = "SYDGRID\x00"
+	GRID_VERSION          = 1
+	GRID_HEADER_SIZE      = 52
+	GRID_COMPRESSION_NONE = 0
+	GRID_COMPRESSION_ZLIB = 1
+	GRID_BYTES_PER_BIN    = 8
+)
+
+/*
+ * Write the counts of a scene, along with its dimensions and bounds, in the
+ * raw grid format, e. g. to archive them or to merge them with the counts of
+ * other machines later.
+ *
+ * The scene must have been created by this package. If compressed is true,
+ * the counts are compressed using zlib, which usually shrinks sparse scenes
+ * considerably. Filters, hillshading and the background color are not
+ * written.
+ */
+func WriteGrid(w io.Writer, scn Scene, compressed bool) error {
+	s, ok := scn.(*sceneStruct)
+
+	/*
+	 * Verify that scene is supported.
+	 */
+	if !ok || (s == nil) {
+		return fmt.Errorf("%s", "Scene is not supported for writing grids.")
+	} else {
+		header := make([]byte, GRID_HEADER_SIZE)
+		copy(header[0:8], GRID_MAGIC)
+		binary.LittleEndian.PutUint16(header[8:10], GRID_VERSION)
+		compression := uint16(GRID_COMPRESSION_NONE)
+
+		/*
+		 * Check if counts should be compressed.
+		 */
+		if compressed {
+			compression = GRID_COMPRESSION_ZLIB
+		}
+
+		binary.LittleEndian.PutUint16(header[10:12], compression)
+		binary.LittleEndian.PutUint32(header[12:16], s.width)
+		binary.LittleEndian.PutUint32(header[16:20], s.height)
+		binary.LittleEndian.PutUint64(header[20:28], math.Float64bits(s.minX))
+		binary.LittleEndian.PutUint64(header[28:36], math.Float64bits(s.maxX))
+		binary.LittleEndian.PutUint64(header[36:44], math.Float64bits(s.minY))
+		binary.LittleEndian.PutUint64(header[44:52], math.Float64bits(s.maxY))
+		_, err := w.Write(header)
+
+		/*
+		 * Check if header could be written.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to write grid header: %s", err.Error())
+		}
+
+		buffered := bufio.NewWriter(w)
+		out := io.Writer(buffered)
+		zw := (*zlib.Writer)(nil)
+
+		/*
+		 * Compress counts if requested.
+		 */
+		if compressed {
+			zw = zlib.NewWriter(buffered)
+			out = zw
+		}
+
+		buf := make([]byte, GRID_BYTES_PER_BIN*int(s.width))
+		bins := s.bins
+		width := uint64(s.width)
+
+		/*
+		 * Write counts one row at a time.
+		 */
+		for offset := uint64(0); offset < uint64(len(bins)); offset += width {
+			row := bins[offset : offset+width]
+
+			/*
+			 * Encode each count of the row.
+			 */
+			for i, count := range row {
+				pos := GRID_BYTES_PER_BIN * i
+				binary.LittleEndian.PutUint64(buf[pos:pos+GRID_BYTES_PER_BIN], count)
+			}
+
+			_, err = out.Write(buf)
+
+			/*
+			 * Check if row could be written.
+			 */
+			if err != nil {
+				return fmt.Errorf("Failed to write grid: %s", err.Error())
+			}
+
+		}
+
+		/*
+		 * Finish the compressed stream.
+		 */
+		if zw != nil {
+			err = zw.Close()
+
+			/*
+			 * Check if compressed stream could be finished.
+			 */
+			if err != nil {
+				return fmt.Errorf("Failed to compress grid: %s", err.Error())
+			}
+
+		}
+
+		err = buffered.Flush()
+
+		/*
+		 * Check if grid could be written.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to write grid: %s", err.Error())
+		} else {
+			return nil
+		}
+
+	}
+
+}
+
+/*
+ * Read a scene written in the raw grid format.
+ *
+ * The returned scene has the dimensions, bounds and counts stored in the
+ * grid. It may be rendered like any other scene, or merged into another
+ * scene using Merge.
+ */
+func ReadGrid(r io.Reader) (Scene, error) {
+	header := make([]byte, GRID_HEADER_SIZE)
+	_, err := io.ReadFull(r, header)
+
+	/*
+	 * Check if header could be read.
+	 */
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read grid header: %s", err.Error())
+	}
+
+	version := binary.LittleEndian.Uint16(header[8:10])
+	compression := binary.LittleEndian.Uint16(header[10:12])
+	width := binary.LittleEndian.Uint32(header[12:16])
+	height := binary.LittleEndian.Uint32(header[16:20])
+	minX := math.Float64frombits(binary.LittleEndian.Uint64(header[20:28]))
+	maxX := math.Float64frombits(binary.LittleEndian.Uint64(header[28:36]))
+	minY := math.Float64frombits(binary.LittleEndian.Uint64(header[36:44]))
+	maxY := math.Float64frombits(binary.LittleEndian.Uint64(header[44:52]))
+	numBins := uint64(width) * uint64(height)
+
+	/*
+	 * Verify header.
+	 */
+	if !bytes.Equal(header[0:8], []byte(GRID_MAGIC)) {
+		return nil, fmt.Errorf("%s", "Data is not in the raw grid format.")
+	} else if version != GRID_VERSION {
+		return nil, fmt.Errorf("Unsupported grid version: %d", version)
+	} else if (compression != GRID_COMPRESSION_NONE) && (compression != GRID_COMPRESSION_ZLIB) {
+		return nil, fmt.Errorf("Unsupported grid compression: %d", compression)
+	} else if numBins > (math.MaxInt64 / GRID_BYTES_PER_BIN) {
+		return nil, fmt.Errorf("Grid dimensions (%d * %d) are too large.", width, height)
+	}
+
+	in := r
+
+	/*
+	 * Decompress counts if needed.
+	 */
+	if compression == GRID_COMPRESSION_ZLIB {
+		zr, err := zlib.NewReader(r)
+
+		/*
+		 * Check if decompressor could be created.
+		 */
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decompress grid: %s", err.Error())
+		}
+
+		defer zr.Close()
+		in = zr
+	}
+
+	scn := Create(width, height, minX, maxX, minY, maxY).(*sceneStruct)
+	bins := scn.bins
+	width64 := uint64(width)
+	buf := make([]byte, GRID_BYTES_PER_BIN*int(width))
+
+	/*
+	 * Read counts one row at a time.
+	 */
+	for offset := uint64(0); offset < numBins; offset += width64 {
+		_, err = io.ReadFull(in, buf)
+
+		/*
+		 * Check if row could be read.
+		 */
+		if err != nil {
+			y := offset / width64
+			return nil, fmt.Errorf("Failed to read row %d of grid: %s", y, err.Error())
+		}
+
+		row := bins[offset : offset+width64]
+
+		/*
+		 * Decode each count of the row.
+		 */
+		for i := range row {
+			pos := GRID_BYTES_PER_BIN * i
+			row[i] = binary.LittleEndian.Uint64(buf[pos : pos+GRID_BYTES_PER_BIN])
+		}
+
+	}
+
+	return scn, nil
+}
+
+/*
+ * Add the counts of a scene to those of another scene, e. g. one read using
+ * ReadGrid, so that data aggregated on several machines can be combined.
+ *
+ * Both scenes must have been created by this package with identical
+ * dimensions and bounds. Counts are limited to the same maximum as during
+ * aggregation.
+ */
+func Merge(dst Scene, src Scene) error {
+	d, okDst := dst.(*sceneStruct)
+	s, okSrc := src.(*sceneStruct)
+
+	/*
+	 * Verify that scenes are supported and compatible.
+	 */
+	if !okDst || (d == nil) || !okSrc || (s == nil) {
+		return fmt.Errorf("%s", "Scene is not supported for merging.")
+	} else if (d.width != s.width) || (d.height != s.height) {
+		return fmt.Errorf("Scene has dimensions (%d * %d), but expected (%d * %d).", s.width, s.height, d.width, d.height)
+	} else if (d.minX != s.minX) || (d.maxX != s.maxX) || (d.minY != s.minY) || (d.maxY != s.maxY) {
+		return fmt.Errorf("%s", "Scenes cover different bounds.")
+	} else {
+		bins := d.bins
+
+		/*
+		 * Add each count.
+		 */
+		for i, count := range s.bins {
+			sum := bins[i] + count
+
+			/*
+			 * Make sure we are not exceeding datatype bounds.
+			 */
+			if (sum < count) || (sum > math.MaxUint32) {
+				sum = math.MaxUint32
+			}
+
+			bins[i] = sum
+		}
+
+		return nil
+	}
+
+}
@@ -0,0 +1,186 @@
+package scene
+
+import (
+	"container/heap"
+	"github.com/andrepxx/sydney/coordinates"
+	"sort"
+)
+
+/*
+ * Data structure representing a bin of a scene together with its position
+ * in data space.
+ *
+ * Hotspots are immutable.
+ */
+type Hotspot struct {
+	count    uint64
+	position coordinates.Cartesian
+}
+
+/*
+ * Data structure representing a candidate bin during hotspot extraction.
+ */
+type hotspotCandidate struct {
+	count uint64
+	index uint64
+}
+
+/*
+ * A min-heap of hotspot candidates, which keeps the K largest bins seen so far.
+ */
+type hotspotHeap []hotspotCandidate
+
+/*
+ * Returns the number of data points aggregated into the bin of this hotspot.
+ */
+func (this *Hotspot) Count() uint64 {
+	return this.count
+}
+
+/*
+ * Returns the position of the center of the bin of this hotspot in data space.
+ */
+func (this *Hotspot) Position() coordinates.Cartesian {
+	return this.position
+}
+
+/*
+ * Returns whether candidate a ranks lower than candidate b.
+ *
+ * Bins with larger counts rank higher. Among bins with equal counts, the bin
+ * with the lower index ranks higher, so that results are deterministic.
+ */
+func lessCandidate(a hotspotCandidate, b hotspotCandidate) bool {
+
+	/*
+	 * Compare counts first, then indices.
+	 */
+	if a.count != b.count {
+		return a.count < b.count
+	} else {
+		return a.index > b.index
+	}
+
+}
+
+/*
+ * Returns the number of candidates in the heap.
+ */
+func (this hotspotHeap) Len() int {
+	return len(this)
+}
+
+/*
+ * Returns whether the candidate at index i ranks lower than the candidate at
+ * index j.
+ */
+func (this hotspotHeap) Less(i int, j int) bool {
+	a := this[i]
+	b := this[j]
+	return lessCandidate(a, b)
+}
+
+/*
+ * Swaps the candidates at index i and index j.
+ */
+func (this hotspotHeap) Swap(i int, j int) {
+	this[i], this[j] = this[j], this[i]
+}
+
+/*
+ * Adds a candidate to the heap.
+ */
+func (this *hotspotHeap) Push(x interface{}) {
+	candidate := x.(hotspotCandidate)
+	*this = append(*this, candidate)
+}
+
+/*
+ * Removes the lowest-ranking candidate from the heap.
+ */
+func (this *hotspotHeap) Pop() interface{} {
+	old := *this
+	n := len(old)
+	candidate := old[n-1]
+	*this = old[0 : n-1]
+	return candidate
+}
+
+/*
+ * Returns the (at most) k bins with the highest counts, ordered by descending
+ * count, together with their positions in data space.
+ *
+ * Empty bins are never returned.
+ */
+func (this *sceneStruct) Hotspots(k uint32) []Hotspot {
+	bins := this.bins
+	k64 := uint64(k)
+	h := hotspotHeap{}
+
+	/*
+	 * Iterate over all bins and keep the k largest ones.
+	 */
+	for i, count := range bins {
+
+		/*
+		 * Only consider non-empty bins.
+		 */
+		if count > 0 {
+			idx := uint64(i)
+
+			/*
+			 * The candidate for this bin.
+			 */
+			candidate := hotspotCandidate{
+				count: count,
+				index: idx,
+			}
+
+			numCandidates := uint64(len(h))
+
+			/*
+			 * Either add the candidate or replace the lowest-ranking one.
+			 */
+			if numCandidates < k64 {
+				heap.Push(&h, candidate)
+			} else if (numCandidates > 0) && lessCandidate(h[0], candidate) {
+				h[0] = candidate
+				heap.Fix(&h, 0)
+			}
+
+		}
+
+	}
+
+	/*
+	 * Order candidates by descending rank.
+	 */
+	sort.Slice(h, func(i int, j int) bool {
+		return lessCandidate(h[j], h[i])
+	})
+
+	numHotspots := len(h)
+	result := make([]Hotspot, numHotspots)
+	width64 := uint64(this.width)
+
+	/*
+	 * Convert candidates into hotspots.
+	 */
+	for i, candidate := range h {
+		idx := candidate.index
+		x := uint32(idx % width64)
+		y := uint32(idx / width64)
+		pos := this.position(x, y)
+
+		/*
+		 * Create hotspot.
+		 */
+		result[i] = Hotspot{
+			count:    candidate.count,
+			position: pos,
+		}
+
+	}
+
+	return result
+}
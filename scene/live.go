@@ -0,0 +1,221 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"sync"
+	"time"
+)
+
+/*
+ * Largest exponent of the weight of new points before the weights are
+ * renormalized.
+ */
+const (
+	LIVE_MAX_EXPONENT = 32.0
+)
+
+/*
+ * A live scene continuously aggregates points, e. g. positions of vehicles,
+ * from multiple goroutines, while the weight of older points decays over
+ * time.
+ */
+type LiveScene interface {
+	Aggregate(data []coordinates.Cartesian)
+	Clear()
+	Snapshot() Scene
+}
+
+/*
+ * Data structure representing a live scene.
+ *
+ * Instead of decaying all weights over time, points are added with a weight
+ * which grows exponentially with the time elapsed since a reference time.
+ */
+type liveSceneStruct struct {
+	halfLife  time.Duration
+	mutex     sync.Mutex
+	now       func() time.Time
+	reference time.Time
+	scene     *sceneStruct
+	weights   []float64
+}
+
+/*
+ * Returns the exponent (base two) of the weight of points aggregated at a
+ * certain time.
+ */
+func (this *liveSceneStruct) exponent(t time.Time) float64 {
+	halfLife := this.halfLife
+
+	/*
+	 * Without half-life, weights do not decay.
+	 */
+	if halfLife <= 0 {
+		return 0.0
+	} else {
+		elapsed := t.Sub(this.reference)
+		return float64(elapsed) / float64(halfLife)
+	}
+
+}
+
+/*
+ * Move the reference time, scaling all weights accordingly, so that the
+ * weights of new points do not overflow.
+ */
+func (this *liveSceneStruct) renormalize(t time.Time) {
+	factor := math.Exp2(-this.exponent(t))
+	weights := this.weights
+
+	/*
+	 * Scale each weight.
+	 */
+	for i := range weights {
+		weights[i] *= factor
+	}
+
+	this.reference = t
+}
+
+/*
+ * Aggregate data into the scene at the current time.
+ *
+ * This method may be called from multiple goroutines.
+ */
+func (this *liveSceneStruct) Aggregate(data []coordinates.Cartesian) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	t := this.now()
+	exponent := this.exponent(t)
+
+	/*
+	 * Renormalize weights if needed.
+	 */
+	if exponent > LIVE_MAX_EXPONENT {
+		this.renormalize(t)
+		exponent = 0.0
+	}
+
+	weight := math.Exp2(exponent)
+	scn := this.scene
+	minX := scn.minX
+	maxX := scn.maxX
+	widthFloat := float64(scn.width)
+	scaleX := widthFloat / (maxX - minX)
+	minY := scn.minY
+	maxY := scn.maxY
+	heightFloat := float64(scn.height)
+	scaleY := heightFloat / (maxY - minY)
+
+	/*
+	 * Iterate over all data points.
+	 */
+	for i := range data {
+		point := &data[i]
+		x := point.X()
+		y := point.Y()
+
+		/*
+		 * Check if point lies within plot bounds.
+		 */
+		if ((x >= minX) && (x < maxX)) && ((y > minY) && (y <= maxY)) {
+			plotX := uint32((x - minX) * scaleX)
+			plotY := uint32((maxY - y) * scaleY)
+			idx, ok := scn.index(plotX, plotY)
+
+			/*
+			 * Check if point can be mapped to bin.
+			 */
+			if ok {
+				this.weights[idx] += weight
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Clear all data from the scene.
+ *
+ * This method may be called from multiple goroutines.
+ */
+func (this *liveSceneStruct) Clear() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	weights := this.weights
+
+	/*
+	 * Reset the weight of each bin to zero.
+	 */
+	for i := range weights {
+		weights[i] = 0.0
+	}
+
+	this.reference = this.now()
+}
+
+/*
+ * Create a scene holding the decayed counts at the current time, which may
+ * then be spread and rendered like any other scene, while aggregation
+ * continues.
+ *
+ * Decayed counts are rounded to the nearest integer, so that a single point
+ * fades out after about one half-life.
+ *
+ * This method may be called from multiple goroutines.
+ */
+func (this *liveSceneStruct) Snapshot() Scene {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	t := this.now()
+	factor := math.Exp2(-this.exponent(t))
+	live := this.scene
+	snapshot := Create(live.width, live.height, live.minX, live.maxX, live.minY, live.maxY).(*sceneStruct)
+	bins := snapshot.bins
+
+	/*
+	 * Convert the decayed weight of each bin into a count.
+	 */
+	for i, weight := range this.weights {
+		count := math.Round(weight * factor)
+
+		/*
+		 * Make sure we are not exceeding datatype bounds.
+		 */
+		if count > math.MaxUint32 {
+			count = math.MaxUint32
+		}
+
+		bins[i] = uint64(count)
+	}
+
+	return snapshot
+}
+
+/*
+ * Create a new live scene, whose counts halve every half-life.
+ *
+ * A half-life of zero or less disables decay, which turns the live scene
+ * into a scene that is safe for concurrent use.
+ */
+func CreateLive(width uint32, height uint32, minX float64, maxX float64, minY float64, maxY float64, halfLife time.Duration) LiveScene {
+	scn := Create(width, height, minX, maxX, minY, maxY).(*sceneStruct)
+	numBins := len(scn.bins)
+	scn.bins = nil
+
+	/*
+	 * Create live scene data structure.
+	 */
+	live := liveSceneStruct{
+		halfLife:  halfLife,
+		now:       time.Now,
+		reference: time.Now(),
+		scene:     scn,
+		weights:   make([]float64, numBins),
+	}
+
+	return &live
+}
@@ -0,0 +1,91 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/color"
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+)
+
+/*
+ * Maximum number of colors in a paletted image.
+ */
+const MAX_PALETTE_SIZE = 256
+
+/*
+ * Enables or disables Floyd-Steinberg dithering of images rendered with a
+ * reduced number of colors.
+ */
+func (this *sceneStruct) SetDithering(enabled bool) {
+	this.dithering = enabled
+}
+
+/*
+ * Render a set of data points into an image with a palette of at most 256
+ * colors using a color mapping.
+ *
+ * The image is rendered as by Render, including hillshading, background and
+ * post-processing filters, and then quantized using color.QuantizeImage. Encoding
+ * the result as PNG yields an 8-bit indexed image, which is considerably
+ * smaller than a true color image. If dithering is enabled, quantization
+ * errors are diffused to neighbouring pixels, so that gradients do not show
+ * visible banding.
+ */
+func (this *sceneStruct) RenderPaletted(mapping color.Mapping) (*image.Paletted, error) {
+	img, err := this.Render(mapping)
+
+	/*
+	 * Check if image could be rendered.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		palette := color.QuantizeImage(img, MAX_PALETTE_SIZE)
+		rect := img.Bounds()
+		result := image.NewPaletted(rect, palette)
+
+		/*
+		 * Decide on how to map pixels to palette entries.
+		 */
+		if this.dithering {
+			draw.FloydSteinberg.Draw(result, rect, img, rect.Min)
+		} else {
+			pix := img.Pix
+			indices := make(map[imagecolor.NRGBA]uint8)
+
+			/*
+			 * Find the closest palette entry for each pixel.
+			 */
+			for i := range result.Pix {
+				offset := 4 * i
+				p := pix[offset : offset+4 : offset+4]
+
+				/*
+				 * The color of the pixel.
+				 */
+				c := imagecolor.NRGBA{
+					R: p[0],
+					G: p[1],
+					B: p[2],
+					A: p[3],
+				}
+
+				idx, ok := indices[c]
+
+				/*
+				 * Look up colors which were not seen before.
+				 */
+				if !ok {
+					idx = uint8(palette.Index(c))
+					indices[c] = idx
+				}
+
+				result.Pix[i] = idx
+			}
+
+		}
+
+		return result, nil
+	}
+
+}
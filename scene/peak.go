@@ -0,0 +1,181 @@
+package scene
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+ * Returns whether the bin at a pair of (integer) coordinates is a local
+ * maximum, i. e. whether none of its (up to eight) neighbours has a larger
+ * count.
+ */
+func (this *sceneStruct) isLocalMaximum(x uint32, y uint32, count uint64) bool {
+	bins := this.bins
+	x64 := int64(x)
+	y64 := int64(y)
+
+	/*
+	 * Visit the neighbouring rows.
+	 */
+	for j := int64(-1); j <= 1; j++ {
+
+		/*
+		 * Visit the neighbouring columns.
+		 */
+		for i := int64(-1); i <= 1; i++ {
+			xx64 := x64 + i
+			yy64 := y64 + j
+
+			/*
+			 * Check if neighbour lies within the scene.
+			 */
+			if xx64 >= 0 && xx64 <= math.MaxUint32 && yy64 >= 0 && yy64 <= math.MaxUint32 {
+				xx := uint32(xx64)
+				yy := uint32(yy64)
+				idx, ok := this.index(xx, yy)
+
+				/*
+				 * Check if neighbour has a larger count.
+				 */
+				if ok && (bins[idx] > count) {
+					return false
+				}
+
+			}
+
+		}
+
+	}
+
+	return true
+}
+
+/*
+ * Find local maxima of the density surface.
+ *
+ * Only bins with a count of at least minCount (and at least one) are
+ * considered. When two local maxima are closer to each other than
+ * minSeparation bins, only the one with the larger count is returned.
+ *
+ * Peaks are returned ordered by descending count.
+ */
+func (this *sceneStruct) Peaks(minCount uint64, minSeparation uint32) []Hotspot {
+
+	/*
+	 * Empty bins are never peaks.
+	 */
+	if minCount < 1 {
+		minCount = 1
+	}
+
+	bins := this.bins
+	width64 := uint64(this.width)
+	candidates := []hotspotCandidate{}
+
+	/*
+	 * Find all local maxima.
+	 */
+	for i, count := range bins {
+
+		/*
+		 * Only consider bins with sufficient count.
+		 */
+		if count >= minCount {
+			idx := uint64(i)
+			x := uint32(idx % width64)
+			y := uint32(idx / width64)
+
+			/*
+			 * Check if this bin is a local maximum.
+			 */
+			if this.isLocalMaximum(x, y, count) {
+
+				/*
+				 * The candidate for this bin.
+				 */
+				candidate := hotspotCandidate{
+					count: count,
+					index: idx,
+				}
+
+				candidates = append(candidates, candidate)
+			}
+
+		}
+
+	}
+
+	/*
+	 * Order candidates by descending rank.
+	 */
+	sort.Slice(candidates, func(i int, j int) bool {
+		return lessCandidate(candidates[j], candidates[i])
+	})
+
+	minSeparationFloat := float64(minSeparation)
+	minDistSquared := minSeparationFloat * minSeparationFloat
+	accepted := []hotspotCandidate{}
+
+	/*
+	 * Greedily accept candidates which are far enough from all
+	 * higher-ranking peaks.
+	 */
+	for _, candidate := range candidates {
+		idx := candidate.index
+		x := float64(idx % width64)
+		y := float64(idx / width64)
+		separated := true
+
+		/*
+		 * Check distance to all peaks accepted so far.
+		 */
+		for _, peak := range accepted {
+			idxPeak := peak.index
+			dx := x - float64(idxPeak%width64)
+			dy := y - float64(idxPeak/width64)
+			distSquared := (dx * dx) + (dy * dy)
+
+			/*
+			 * Reject candidate if it is too close to a peak.
+			 */
+			if distSquared < minDistSquared {
+				separated = false
+				break
+			}
+
+		}
+
+		/*
+		 * Accept candidate if it is far enough from other peaks.
+		 */
+		if separated {
+			accepted = append(accepted, candidate)
+		}
+
+	}
+
+	numPeaks := len(accepted)
+	result := make([]Hotspot, numPeaks)
+
+	/*
+	 * Convert accepted candidates into hotspots.
+	 */
+	for i, candidate := range accepted {
+		idx := candidate.index
+		x := uint32(idx % width64)
+		y := uint32(idx / width64)
+		pos := this.position(x, y)
+
+		/*
+		 * Create hotspot.
+		 */
+		result[i] = Hotspot{
+			count:    candidate.count,
+			position: pos,
+		}
+
+	}
+
+	return result
+}
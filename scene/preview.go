@@ -0,0 +1,200 @@
+package scene
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	imagecolor "image/color"
+	"io"
+	"math"
+)
+
+/*
+ * Characters used to draw two pixels in a single character cell.
+ */
+const (
+	PREVIEW_LOWER_HALF = "▄"
+	PREVIEW_UPPER_HALF = "▀"
+)
+
+/*
+ * Reduce the scene to a smaller scene, whose bins hold the sum of the counts
+ * of square blocks of bins.
+ *
+ * Counts are limited to the same maximum as during aggregation.
+ */
+func (this *sceneStruct) downsample(factor uint32) *sceneStruct {
+	width := this.width
+	height := this.height
+	targetWidth := (width + factor - 1) / factor
+	targetHeight := (height + factor - 1) / factor
+	numBins := uint64(targetWidth) * uint64(targetHeight)
+	bins := make([]uint64, numBins)
+
+	/*
+	 * Add the count of each bin to its block.
+	 */
+	for y := uint32(0); y < height; y++ {
+		targetY := y / factor
+
+		/*
+		 * Iterate over all bins of the row.
+		 */
+		for x := uint32(0); x < width; x++ {
+			idx, _ := this.index(x, y)
+			count := this.bins[idx]
+			targetX := x / factor
+			targetIdx := (uint64(targetY) * uint64(targetWidth)) + uint64(targetX)
+			sum := bins[targetIdx] + count
+
+			/*
+			 * Make sure we are not exceeding datatype bounds.
+			 */
+			if (sum < count) || (sum > math.MaxUint32) {
+				sum = math.MaxUint32
+			}
+
+			bins[targetIdx] = sum
+		}
+
+	}
+
+	/*
+	 * Create downsampled scene data structure.
+	 */
+	scn := sceneStruct{
+		background: this.background,
+		bins:       bins,
+		height:     targetHeight,
+		maxX:       this.maxX,
+		maxY:       this.maxY,
+		minX:       this.minX,
+		minY:       this.minY,
+		width:      targetWidth,
+	}
+
+	return &scn
+}
+
+/*
+ * Returns whether a color is (fully) transparent and the escape sequence
+ * parameters of the color composited over black otherwise.
+ */
+func terminalColor(c imagecolor.NRGBA) (bool, string) {
+
+	/*
+	 * Transparent pixels show the background of the terminal.
+	 */
+	if c.A == 0 {
+		return true, ""
+	} else {
+		black := imagecolor.NRGBA{
+			A: 255,
+		}
+
+		c = over(c, black)
+		result := fmt.Sprintf("2;%d;%d;%d", c.R, c.G, c.B)
+		return false, result
+	}
+
+}
+
+/*
+ * Print a downsampled preview of the scene to a terminal, e. g. to check the
+ * bounds and density of a scene on a headless server before rendering it
+ * at full size.
+ *
+ * The scene is reduced to at most a number of columns by summing the counts
+ * of square blocks of bins, mapped to colors and composited over the
+ * background of the scene. Each character cell shows two pixels on top of
+ * each other using half-block characters and 24-bit ANSI colors, so that
+ * pixels appear roughly square. Transparent pixels show the background of
+ * the terminal. Hillshading and filters are not applied.
+ */
+func (this *sceneStruct) Preview(w io.Writer, mapping color.Mapping, columns uint32) error {
+	width := this.width
+	height := this.height
+
+	/*
+	 * Verify that there is something to print.
+	 */
+	if columns == 0 {
+		return fmt.Errorf("%s", "Preview must have at least one column.")
+	} else if (width == 0) || (height == 0) {
+		return nil
+	} else {
+		factor := (width + columns - 1) / columns
+		preview := this.downsample(factor)
+		colors, err := preview.colors(mapping)
+
+		/*
+		 * Check if colors could be mapped.
+		 */
+		if err != nil {
+			return err
+		} else {
+			previewWidth := int(preview.width)
+			previewHeight := int(preview.height)
+			bw := bufio.NewWriter(w)
+
+			/*
+			 * Print two rows of pixels in each line.
+			 */
+			for y := 0; y < previewHeight; y += 2 {
+				previous := ""
+
+				/*
+				 * Print each character cell.
+				 */
+				for x := 0; x < previewWidth; x++ {
+					top := colors[(y*previewWidth)+x]
+					bottom := imagecolor.NRGBA{}
+
+					/*
+					 * The last line may only have a top pixel.
+					 */
+					if (y + 1) < previewHeight {
+						bottom = colors[((y+1)*previewWidth)+x]
+					}
+
+					topTransparent, topColor := terminalColor(top)
+					bottomTransparent, bottomColor := terminalColor(bottom)
+					sequence := ""
+					character := PREVIEW_UPPER_HALF
+
+					/*
+					 * Decide on the character and colors.
+					 */
+					if topTransparent && bottomTransparent {
+						sequence = "\x1b[0m"
+						character = " "
+					} else if topTransparent {
+						sequence = fmt.Sprintf("\x1b[0;38;%sm", bottomColor)
+						character = PREVIEW_LOWER_HALF
+					} else if bottomTransparent {
+						sequence = fmt.Sprintf("\x1b[0;38;%sm", topColor)
+					} else {
+						sequence = fmt.Sprintf("\x1b[0;38;%s;48;%sm", topColor, bottomColor)
+					}
+
+					/*
+					 * Only change colors when needed.
+					 */
+					if sequence != previous {
+						bw.WriteString(sequence)
+						previous = sequence
+					}
+
+					bw.WriteString(character)
+				}
+
+				bw.WriteString("\x1b[0m\n")
+			}
+
+			err = bw.Flush()
+			return err
+		}
+
+	}
+
+}
@@ -0,0 +1,168 @@
+package scene
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ * Calculate, for each bin along an axis of a target scene, the range of bins
+ * along the same axis of a source scene, whose counts are added to it.
+ *
+ * Each axis is described by the position of the edge of its first bin, the
+ * signed size of its bins and its number of bins. Returns the first and one
+ * past the last source bin for each target bin.
+ */
+func resampleAxis(dstOrigin float64, dstStep float64, dstCount uint32, srcOrigin float64, srcStep float64, srcCount uint32) ([]int64, []int64) {
+	starts := make([]int64, dstCount)
+	ends := make([]int64, dstCount)
+	srcCount64 := int64(srcCount)
+
+	/*
+	 * Calculate range of source bins for each target bin.
+	 */
+	for j := range starts {
+		jFloat := float64(j)
+		a := ((dstOrigin + (jFloat * dstStep)) - srcOrigin) / srcStep
+		b := ((dstOrigin + ((jFloat + 1.0) * dstStep)) - srcOrigin) / srcStep
+		start := int64(0)
+		end := int64(0)
+
+		/*
+		 * Only consider valid edges.
+		 */
+		if !math.IsNaN(a) && !math.IsNaN(b) && !math.IsInf(a, 0) && !math.IsInf(b, 0) {
+			startFloat := math.Ceil(a - 0.5)
+			endFloat := math.Ceil(b - 0.5)
+
+			/*
+			 * If no source bin has its center within the target
+			 * bin, use the source bin containing its center.
+			 */
+			if endFloat <= startFloat {
+				startFloat = math.Floor(0.5 * (a + b))
+				endFloat = startFloat + 1.0
+			}
+
+			startFloat = math.Max(startFloat, 0.0)
+			endFloat = math.Min(endFloat, float64(srcCount))
+
+			/*
+			 * Check if range lies within the source scene.
+			 */
+			if startFloat < endFloat {
+				start = int64(startFloat)
+				end = int64(endFloat)
+			}
+
+		}
+
+		/*
+		 * Make sure range is valid.
+		 */
+		if (start < 0) || (end > srcCount64) || (start > end) {
+			start = 0
+			end = 0
+		}
+
+		starts[j] = start
+		ends[j] = end
+	}
+
+	return starts, ends
+}
+
+/*
+ * Add the counts of a scene to another scene of a possibly different size and
+ * region, e. g. to cut a map tile from a scene covering a larger area.
+ *
+ * Each bin of dst receives the sum of the bins of src whose centers lie
+ * within it. Bins of dst which are smaller than the bins of src receive the
+ * count of the bin of src containing their center instead, so that magnified
+ * scenes have no gaps. Counts are limited to the same maximum as during
+ * aggregation.
+ */
+func Resample(dst Scene, src Scene) error {
+	d, okDst := dst.(*sceneStruct)
+	s, okSrc := src.(*sceneStruct)
+
+	/*
+	 * Verify that scenes are supported.
+	 */
+	if !okDst || (d == nil) || !okSrc || (s == nil) {
+		return fmt.Errorf("%s", "Scene is not supported for resampling.")
+	} else if (d.width == 0) || (d.height == 0) || (s.width == 0) || (s.height == 0) {
+		return nil
+	} else {
+		dstSizeX := (d.maxX - d.minX) / float64(d.width)
+		dstSizeY := (d.maxY - d.minY) / float64(d.height)
+		srcSizeX := (s.maxX - s.minX) / float64(s.width)
+		srcSizeY := (s.maxY - s.minY) / float64(s.height)
+		colStarts, colEnds := resampleAxis(d.minX, dstSizeX, d.width, s.minX, srcSizeX, s.width)
+		rowStarts, rowEnds := resampleAxis(d.maxY, -dstSizeY, d.height, s.maxY, -srcSizeY, s.height)
+		dstWidth := int64(d.width)
+		srcWidth := int64(s.width)
+		dstBins := d.bins
+		srcBins := s.bins
+
+		/*
+		 * Fill each row of the target scene.
+		 */
+		for y, rowStart := range rowStarts {
+			rowEnd := rowEnds[y]
+
+			/*
+			 * Skip rows outside of the source scene.
+			 */
+			if rowStart < rowEnd {
+
+				/*
+				 * Fill each bin of the row.
+				 */
+				for x, colStart := range colStarts {
+					colEnd := colEnds[x]
+					sum := uint64(0)
+
+					/*
+					 * Add the bins of each source row.
+					 */
+					for sy := rowStart; sy < rowEnd; sy++ {
+						offset := sy * srcWidth
+
+						/*
+						 * Add the bins of the source row.
+						 */
+						for sx := colStart; sx < colEnd; sx++ {
+							sum += srcBins[offset+sx]
+						}
+
+					}
+
+					/*
+					 * Only touch bins receiving counts.
+					 */
+					if sum > 0 {
+						idx := (int64(y) * dstWidth) + int64(x)
+						total := dstBins[idx] + sum
+
+						/*
+						 * Make sure we are not exceeding datatype
+						 * bounds.
+						 */
+						if (total < sum) || (total > math.MaxUint32) {
+							total = math.MaxUint32
+						}
+
+						dstBins[idx] = total
+					}
+
+				}
+
+			}
+
+		}
+
+		return nil
+	}
+
+}
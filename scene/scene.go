@@ -5,16 +5,43 @@ import (
 	"github.com/andrepxx/sydney/color"
 	"github.com/andrepxx/sydney/coordinates"
 	"image"
+	imagecolor "image/color"
+	"io"
 	"math"
 )
 
+/*
+ * A filter post-processes a rendered image in place.
+ */
+type Filter func(img *image.NRGBA)
+
 /*
  * A scene is a plane onto which points are drawn.
  */
 type Scene interface {
+	AddFilter(filter Filter)
 	Aggregate(data []coordinates.Cartesian)
+	AggregateWeighted(data []coordinates.Cartesian, weights []float64) error
+	Bounds() coordinates.CartesianBounds
 	Clear()
+	ClearFilters()
+	Clusters(threshold uint64) []Cluster
+	Contours(levels []float64) []Contour
+	Counts() []uint64
+	Dimensions() (uint32, uint32)
+	Estimate(bandwidthX float64, bandwidthY float64) Scene
+	Fill(polygon coordinates.Polygon, count uint64)
+	Hillshade(azimuth float64, elevation float64, strength float64)
+	Hotspots(k uint32) []Hotspot
+	Peaks(minCount uint64, minSeparation uint32) []Hotspot
+	Preview(w io.Writer, mapping color.Mapping, columns uint32) error
 	Render(mapping color.Mapping) (*image.NRGBA, error)
+	Render16(mapping color.Mapping16) (*image.NRGBA64, error)
+	RenderPNG(w io.Writer, mapping color.Mapping) error
+	RenderPaletted(mapping color.Mapping) (*image.Paletted, error)
+	RenderRGBA(mapping color.Mapping) (*image.RGBA, error)
+	SetBackground(background imagecolor.NRGBA)
+	SetDithering(enabled bool)
 	Spread(amount uint8)
 }
 
@@ -22,13 +49,17 @@ type Scene interface {
  * Data structure representing a scene.
  */
 type sceneStruct struct {
-	bins   []uint64
-	height uint32
-	maxX   float64
-	maxY   float64
-	minX   float64
-	minY   float64
-	width  uint32
+	background imagecolor.NRGBA
+	bins       []uint64
+	dithering  bool
+	filters    []Filter
+	height     uint32
+	light      lightStruct
+	maxX       float64
+	maxY       float64
+	minX       float64
+	minY       float64
+	width      uint32
 }
 
 /*
@@ -54,57 +85,171 @@ func (this *sceneStruct) index(x uint32, y uint32) (uint64, bool) {
 }
 
 /*
- * Aggregate data into the scene.
+ * Calculate the position in data space of the center of the bin with a pair
+ * of (integer) coordinates.
  */
-func (this *sceneStruct) Aggregate(data []coordinates.Cartesian) {
+func (this *sceneStruct) position(x uint32, y uint32) coordinates.Cartesian {
 	minX := this.minX
 	maxX := this.maxX
 	width := this.width
 	widthFloat := float64(width)
-	scaleX := widthFloat / (maxX - minX)
-	minY := this.minY
+	sizeX := (maxX - minX) / widthFloat
 	maxY := this.maxY
+	minY := this.minY
 	height := this.height
 	heightFloat := float64(height)
-	scaleY := heightFloat / (maxY - minY)
+	sizeY := (maxY - minY) / heightFloat
+	xFloat := float64(x) + 0.5
+	yFloat := float64(y) + 0.5
+	posX := minX + (xFloat * sizeX)
+	posY := maxY - (yFloat * sizeY)
+	pos := coordinates.CreateCartesian(posX, posY)
+	return pos
+}
+
+/*
+ * Add a filter to the end of the post-processing chain of the scene.
+ *
+ * Filters are applied in the order in which they were added to each image
+ * produced by Render, before it is returned. If filter is nil, this is a no-op.
+ */
+func (this *sceneStruct) AddFilter(filter Filter) {
+
+	/*
+	 * Only add non-nil filters.
+	 */
+	if filter != nil {
+		this.filters = append(this.filters, filter)
+	}
+
+}
+
+/*
+ * Calculate the index of the bin a point in data space falls into.
+ */
+func (this *sceneStruct) locate(point *coordinates.Cartesian) (uint64, bool) {
+	minX := this.minX
+	maxX := this.maxX
+	minY := this.minY
+	maxY := this.maxY
+	x := point.X()
+	y := point.Y()
+
+	/*
+	 * Check if point lies within plot bounds.
+	 */
+	if ((x >= minX) && (x < maxX)) && ((y > minY) && (y <= maxY)) {
+		widthFloat := float64(this.width)
+		scaleX := widthFloat / (maxX - minX)
+		heightFloat := float64(this.height)
+		scaleY := heightFloat / (maxY - minY)
+		plotX := uint32((x - minX) * scaleX)
+		plotY := uint32((maxY - y) * scaleY)
+		return this.index(plotX, plotY)
+	} else {
+		return 0, false
+	}
+
+}
+
+/*
+ * Aggregate data into the scene.
+ */
+func (this *sceneStruct) Aggregate(data []coordinates.Cartesian) {
 
 	/*
 	 * Iterate over all data points.
 	 */
 	for i := range data {
-		point := &data[i]
-		x := point.X()
-		y := point.Y()
+		idx, ok := this.locate(&data[i])
+
+		/*
+		 * Check if point can be mapped to bin.
+		 */
+		if ok {
+			val := this.bins[idx]
+
+			/*
+			 * Make sure we are not exceeding datatype bounds.
+			 */
+			if val < math.MaxUint32 {
+				this.bins[idx] = val + 1
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Aggregate weighted data into the scene, so that each point adds its weight
+ * instead of one to its bin.
+ *
+ * Since bins hold counts, weights are rounded to the nearest integer. Points
+ * with negative or invalid weights are ignored.
+ */
+func (this *sceneStruct) AggregateWeighted(data []coordinates.Cartesian, weights []float64) error {
+	numPoints := len(data)
+	numWeights := len(weights)
+
+	/*
+	 * Verify that each point has a weight.
+	 */
+	if numPoints != numWeights {
+		return fmt.Errorf("Got %d points, but %d weights.", numPoints, numWeights)
+	} else {
 
 		/*
-		 * Check if point lies within plot bounds.
+		 * Iterate over all data points.
 		 */
-		if ((x >= minX) && (x < maxX)) && ((y > minY) && (y <= maxY)) {
-			plotX := uint32((x - minX) * scaleX)
-			plotY := uint32((maxY - y) * scaleY)
-			idx, ok := this.index(plotX, plotY)
+		for i := range data {
+			weight := math.Round(weights[i])
+			idx, ok := this.locate(&data[i])
 
 			/*
-			 * Check if point can be mapped to bin.
+			 * Check if point can be mapped to bin and has a valid
+			 * weight.
 			 */
-			if ok {
+			if ok && (weight > 0.0) {
+				count := uint64(math.MaxUint32)
+
+				/*
+				 * Limit weight to the maximum count.
+				 */
+				if weight < math.MaxUint32 {
+					count = uint64(weight)
+				}
+
 				val := this.bins[idx]
+				sum := val + count
 
 				/*
 				 * Make sure we are not exceeding datatype bounds.
 				 */
-				if val < math.MaxUint32 {
-					this.bins[idx] = val + 1
+				if sum > math.MaxUint32 {
+					sum = math.MaxUint32
 				}
 
+				this.bins[idx] = sum
 			}
 
 		}
 
+		return nil
 	}
 
 }
 
+/*
+ * Returns the region of the plane covered by the scene.
+ */
+func (this *sceneStruct) Bounds() coordinates.CartesianBounds {
+	min := coordinates.CreateCartesian(this.minX, this.minY)
+	max := coordinates.CreateCartesian(this.maxX, this.maxY)
+	return coordinates.CreateCartesianBounds(min, max)
+}
+
 /*
  * Clear all data from the scene.
  */
@@ -120,13 +265,141 @@ func (this *sceneStruct) Clear() {
 
 }
 
+/*
+ * Remove all filters from the post-processing chain of the scene.
+ */
+func (this *sceneStruct) ClearFilters() {
+	this.filters = nil
+}
+
+/*
+ * Returns a copy of the counts of all bins in row-major order, starting with
+ * the top row, i. e. at the maximum y-coordinate.
+ */
+func (this *sceneStruct) Counts() []uint64 {
+	bins := this.bins
+	counts := make([]uint64, len(bins))
+	copy(counts, bins)
+	return counts
+}
+
+/*
+ * Returns the width and height of the scene in bins.
+ */
+func (this *sceneStruct) Dimensions() (uint32, uint32) {
+	return this.width, this.height
+}
+
+/*
+ * Map the bins of the scene to colors using a color mapping, verify the
+ * result and apply hillshading.
+ */
+func (this *sceneStruct) colors(mapping color.Mapping) ([]imagecolor.NRGBA, error) {
+
+	/*
+	 * Verify that color mapping is non-nil.
+	 */
+	if mapping == nil {
+		return nil, fmt.Errorf("%s", "Color mapping must not be nil when rendering an image!")
+	} else {
+		data := this.bins
+		colors := []imagecolor.NRGBA(nil)
+
+		/*
+		 * Pass the width of the scene to mappings which need it.
+		 */
+		if grid, ok := mapping.(color.GridMapping); ok {
+			colors = grid.MapGrid(data, this.width)
+		} else {
+			colors = mapping.Map(data)
+		}
+
+		/*
+		 * Verify that color mapping returned non-nil slice.
+		 */
+		if colors == nil {
+			return nil, fmt.Errorf("%s", "Color mapping must not map to nil slice when rendering an image!")
+		} else {
+			width := this.width
+			widthInt := int(width)
+			height := this.height
+			heightInt := int(height)
+			numColors := len(colors)
+			expectedNumColors := widthInt * heightInt
+
+			/*
+			 * Verify that the color mapping returned a result of the
+			 * expected length.
+			 */
+			if numColors != expectedNumColors {
+				return nil, fmt.Errorf("Color mapping returned %d pixels, but expected %d for a (%d * %d) image.", numColors, expectedNumColors, width, height)
+			} else {
+				this.shade(colors)
+				this.composite(colors)
+				return colors, nil
+			}
+
+		}
+
+	}
+
+}
+
 /*
  * Render a set of data points into an image using a color mapping.
  *
  * Generates an NRGBA-image of width times height pixels displaying
- * the data points with minX <= x < maxX and minY <= y < maxY.
+ * the data points with minX <= x < maxX and minY <= y < maxY, then applies
+ * all filters added to the scene.
  */
 func (this *sceneStruct) Render(mapping color.Mapping) (*image.NRGBA, error) {
+	colors, err := this.colors(mapping)
+
+	/*
+	 * Check if colors could be mapped.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		width := this.width
+		widthInt := int(width)
+		height := this.height
+		heightInt := int(height)
+		rect := image.Rect(0, 0, widthInt, heightInt)
+		img := image.NewNRGBA(rect)
+		pix := img.Pix
+
+		/*
+		 * Bins and pixels are both stored in row-major order without
+		 * padding, so pixel data can be written in a single linear pass.
+		 */
+		for i, c := range colors {
+			offset := 4 * i
+			p := pix[offset : offset+4 : offset+4]
+			p[0] = c.R
+			p[1] = c.G
+			p[2] = c.B
+			p[3] = c.A
+		}
+
+		filters := this.filters
+
+		/*
+		 * Apply post-processing filters.
+		 */
+		for _, filter := range filters {
+			filter(img)
+		}
+
+		return img, nil
+	}
+
+}
+
+/*
+ * Map the bins of the scene to 16-bit colors and apply hillshading.
+ */
+func (this *sceneStruct) colors16(mapping color.Mapping16) ([]imagecolor.NRGBA64, error) {
 
 	/*
 	 * Verify that color mapping is non-nil.
@@ -135,7 +408,7 @@ func (this *sceneStruct) Render(mapping color.Mapping) (*image.NRGBA, error) {
 		return nil, fmt.Errorf("%s", "Color mapping must not be nil when rendering an image!")
 	} else {
 		data := this.bins
-		colors := mapping.Map(data)
+		colors := mapping.Map16(data)
 
 		/*
 		 * Verify that color mapping returned non-nil slice.
@@ -155,41 +428,108 @@ func (this *sceneStruct) Render(mapping color.Mapping) (*image.NRGBA, error) {
 			 * expected length.
 			 */
 			if numColors != expectedNumColors {
-				return nil, fmt.Errorf("%s", "Color mapping returned %d pixels, but expected %d for a (%d * %d) image.", numColors, expectedNumColors, width, height)
+				return nil, fmt.Errorf("Color mapping returned %d pixels, but expected %d for a (%d * %d) image.", numColors, expectedNumColors, width, height)
 			} else {
-				rect := image.Rect(0, 0, widthInt, heightInt)
-				img := image.NewNRGBA(rect)
+				this.shade16(colors)
+				this.composite16(colors)
+				return colors, nil
+			}
 
-				/*
-				 * Iterate over the rows of the image.
-				 */
-				for y := uint32(0); y < height; y++ {
-					yy := int(y)
+		}
 
-					/*
-					 * Iterate over the columns of the image and set pixel data.
-					 */
-					for x := uint32(0); x < width; x++ {
-						xx := int(x)
-						idx, ok := this.index(x, y)
+	}
 
-						/*
-						 * Check if index is valid.
-						 */
-						if ok {
-							c := colors[idx]
-							img.SetNRGBA(xx, yy, c)
-						}
+}
 
-					}
+/*
+ * Render a set of data points into an image with 16 bits per channel using a
+ * color mapping.
+ *
+ * This avoids visible banding of smooth gradients, which may occur with 8 bits
+ * per channel. Hillshading is applied, but post-processing filters only
+ * operate on 8-bit images and are therefore not applied.
+ */
+func (this *sceneStruct) Render16(mapping color.Mapping16) (*image.NRGBA64, error) {
+	colors, err := this.colors16(mapping)
 
-				}
+	/*
+	 * Check if colors could be mapped.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		width := this.width
+		widthInt := int(width)
+		height := this.height
+		heightInt := int(height)
+		rect := image.Rect(0, 0, widthInt, heightInt)
+		img := image.NewNRGBA64(rect)
+		pix := img.Pix
 
-				return img, nil
-			}
+		/*
+		 * Bins and pixels are both stored in row-major order without
+		 * padding, so pixel data can be written in a single linear pass.
+		 * Channels are stored in big-endian byte order.
+		 */
+		for i, c := range colors {
+			offset := 8 * i
+			p := pix[offset : offset+8 : offset+8]
+			p[0] = uint8(c.R >> 8)
+			p[1] = uint8(c.R)
+			p[2] = uint8(c.G >> 8)
+			p[3] = uint8(c.G)
+			p[4] = uint8(c.B >> 8)
+			p[5] = uint8(c.B)
+			p[6] = uint8(c.A >> 8)
+			p[7] = uint8(c.A)
+		}
+
+		return img, nil
+	}
+
+}
+
+/*
+ * Render a set of data points into an image with premultiplied alpha using a
+ * color mapping.
+ *
+ * Premultiplied images composite faster using draw.Draw, which avoids a
+ * conversion pass when always drawing the result over a background.
+ * Hillshading is applied, but post-processing filters only operate on
+ * non-premultiplied images and are therefore not applied.
+ */
+func (this *sceneStruct) RenderRGBA(mapping color.Mapping) (*image.RGBA, error) {
+	colors, err := this.colors(mapping)
 
+	/*
+	 * Check if colors could be mapped.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		width := this.width
+		widthInt := int(width)
+		height := this.height
+		heightInt := int(height)
+		rect := image.Rect(0, 0, widthInt, heightInt)
+		img := image.NewRGBA(rect)
+		pix := img.Pix
+
+		/*
+		 * Bins and pixels are both stored in row-major order without
+		 * padding, so pixel data can be written in a single linear pass.
+		 */
+		for i, c := range colors {
+			offset := 4 * i
+			p := pix[offset : offset+4 : offset+4]
+			pc := color.PremultiplyColor(c)
+			p[0] = pc.R
+			p[1] = pc.G
+			p[2] = pc.B
+			p[3] = pc.A
 		}
 
+		return img, nil
 	}
 
 }
@@ -307,3 +647,13 @@ func Create(width uint32, height uint32, minX float64, maxX float64, minY float6
 
 	return &scn
 }
+
+/*
+ * Create a new scene covering a bounding box, e. g. the extent of a
+ * projection.
+ */
+func CreateFromBounds(width uint32, height uint32, bounds coordinates.CartesianBounds) Scene {
+	min := bounds.Min()
+	max := bounds.Max()
+	return Create(width, height, min.X(), max.X(), min.Y(), max.Y())
+}
@@ -0,0 +1,248 @@
+package scene
+
+import (
+	imagecolor "image/color"
+	"math"
+)
+
+/*
+ * Data structure representing the light source used for hillshading.
+ */
+type lightStruct struct {
+	azimuth   float64
+	elevation float64
+	strength  float64
+}
+
+/*
+ * Returns the height of the density surface at a pair of (integer)
+ * coordinates, clamping coordinates to the edges of the scene.
+ */
+func (this *sceneStruct) relief(x int64, y int64) float64 {
+	width64 := int64(this.width)
+	height64 := int64(this.height)
+
+	/*
+	 * Clamp abscissa to the scene.
+	 */
+	if x < 0 {
+		x = 0
+	} else if x >= width64 {
+		x = width64 - 1
+	}
+
+	/*
+	 * Clamp ordinate to the scene.
+	 */
+	if y < 0 {
+		y = 0
+	} else if y >= height64 {
+		y = height64 - 1
+	}
+
+	idx := (width64 * y) + x
+	count := this.bins[idx]
+	countFloat := float64(count)
+	return math.Log1p(countFloat)
+}
+
+/*
+ * Configure hillshading of rendered images.
+ *
+ * The density surface is treated as a heightfield, with the height of each bin
+ * being log(1 + count), and lit from a light source at the given azimuth
+ * (measured clockwise from the top of the image) and elevation (measured from
+ * the horizon), both in radians. The resulting shading is blended with the
+ * colors produced by the color mapping, where strength ranges from zero (no
+ * shading, the default) to one (full shading).
+ */
+func (this *sceneStruct) Hillshade(azimuth float64, elevation float64, strength float64) {
+
+	/*
+	 * Configure light source.
+	 */
+	this.light = lightStruct{
+		azimuth:   azimuth,
+		elevation: elevation,
+		strength:  strength,
+	}
+
+}
+
+/*
+ * Calculate the factor by which hillshading scales the color of each bin in
+ * a row of the scene, storing the factors in a slice of width elements.
+ */
+func (this *sceneStruct) shadeRow(y uint32, factors []float64) {
+	light := this.light
+	strength := light.strength
+	zenith := (0.5 * math.Pi) - light.elevation
+	cosZenith := math.Cos(zenith)
+	sinZenith := math.Sin(zenith)
+	azimuthMath := (0.5 * math.Pi) - light.azimuth
+	flat := cosZenith
+
+	/*
+	 * Avoid division by zero for light at the horizon.
+	 */
+	if flat <= 0.0 {
+		flat = 1.0
+	}
+
+	width := this.width
+	y64 := int64(y)
+
+	/*
+	 * Iterate over the columns of the scene.
+	 */
+	for x := uint32(0); x < width; x++ {
+		x64 := int64(x)
+		a := this.relief(x64-1, y64-1)
+		b := this.relief(x64, y64-1)
+		c := this.relief(x64+1, y64-1)
+		d := this.relief(x64-1, y64)
+		f := this.relief(x64+1, y64)
+		g := this.relief(x64-1, y64+1)
+		h := this.relief(x64, y64+1)
+		i := this.relief(x64+1, y64+1)
+		dzdx := ((c + (2.0 * f) + i) - (a + (2.0 * d) + g)) / 8.0
+		dzdy := ((g + (2.0 * h) + i) - (a + (2.0 * b) + c)) / 8.0
+		slope := math.Atan(math.Hypot(dzdx, dzdy))
+		aspect := math.Atan2(dzdy, -dzdx)
+		shade := (cosZenith * math.Cos(slope)) + (sinZenith * math.Sin(slope) * math.Cos(azimuthMath-aspect))
+		shade = clamp(shade, 0.0, 1.0)
+		factors[x] = (1.0 - strength) + (strength * shade / flat)
+	}
+
+}
+
+/*
+ * Calculate the factor by which hillshading scales the color of each bin.
+ *
+ * Returns nil if hillshading is disabled.
+ */
+func (this *sceneStruct) shading() []float64 {
+	strength := this.light.strength
+
+	/*
+	 * Only shade if needed.
+	 */
+	if strength <= 0.0 {
+		return nil
+	} else {
+		width := this.width
+		width64 := uint64(width)
+		height := this.height
+		numBins := len(this.bins)
+		factors := make([]float64, numBins)
+
+		/*
+		 * Iterate over the rows of the scene.
+		 */
+		for y := uint32(0); y < height; y++ {
+			y64 := uint64(y)
+			offset := width64 * y64
+			this.shadeRow(y, factors[offset:offset+width64])
+		}
+
+		return factors
+	}
+
+}
+
+/*
+ * Scale each color by its shading factor.
+ */
+func applyShading(colors []imagecolor.NRGBA, factors []float64) {
+
+	/*
+	 * Scale each color by its shading factor.
+	 */
+	for i, factor := range factors {
+		col := &colors[i]
+		col.R = shadeChannel(col.R, factor)
+		col.G = shadeChannel(col.G, factor)
+		col.B = shadeChannel(col.B, factor)
+	}
+
+}
+
+/*
+ * Apply hillshading to the colors mapped from the bins of the scene.
+ */
+func (this *sceneStruct) shade(colors []imagecolor.NRGBA) {
+	factors := this.shading()
+
+	/*
+	 * Only shade if needed.
+	 */
+	if factors != nil {
+		applyShading(colors, factors)
+	}
+
+}
+
+/*
+ * Apply hillshading to the 16-bit colors mapped from the bins of the scene.
+ */
+func (this *sceneStruct) shade16(colors []imagecolor.NRGBA64) {
+	factors := this.shading()
+
+	/*
+	 * Only shade if needed.
+	 */
+	if factors != nil {
+
+		/*
+		 * Scale each color by its shading factor.
+		 */
+		for i, factor := range factors {
+			col := &colors[i]
+			col.R = shadeChannel16(col.R, factor)
+			col.G = shadeChannel16(col.G, factor)
+			col.B = shadeChannel16(col.B, factor)
+		}
+
+	}
+
+}
+
+/*
+ * Multiply a color channel by a factor, clamping the result to the range of
+ * the channel.
+ */
+func shadeChannel(value uint8, factor float64) uint8 {
+	valueFloat := float64(value)
+	result := math.Round(factor * valueFloat)
+	result = clamp(result, 0.0, 255.0)
+	return uint8(result)
+}
+
+/*
+ * Multiply a 16-bit color channel by a factor, clamping the result to the
+ * range of the channel.
+ */
+func shadeChannel16(value uint16, factor float64) uint16 {
+	valueFloat := float64(value)
+	result := math.Round(factor * valueFloat)
+	result = clamp(result, 0.0, 65535.0)
+	return uint16(result)
+}
+
+/*
+ * Restricts a value to an interval, so that min <= value <= max.
+ */
+func clamp(value float64, min float64, max float64) float64 {
+
+	/*
+	 * Decide on the value.
+	 */
+	if value < min {
+		return min
+	} else if value > max {
+		return max
+	} else {
+		return value
+	}
+
+}
@@ -0,0 +1,292 @@
+package scene
+
+import (
+	"github.com/andrepxx/sydney/color"
+	"image"
+	imagecolor "image/color"
+	"image/png"
+	"io"
+)
+
+/*
+ * Data structure representing an image which reads its pixels directly from
+ * the colors produced by a color mapping, so that no pixel buffer has to be
+ * allocated when encoding it.
+ */
+type colorImageStruct struct {
+	colors []imagecolor.NRGBA
+	height int
+	width  int
+}
+
+/*
+ * Data structure representing an image which maps its pixels one row at a
+ * time while it is being read, so that only the colors of a single row have
+ * to be held in memory.
+ */
+type rowImageStruct struct {
+	current int
+	err     error
+	factors []float64
+	mapping color.ChunkedMapping
+	row     []imagecolor.NRGBA
+	scene   *sceneStruct
+	stats   color.Statistics
+}
+
+/*
+ * Returns the color model of the image.
+ */
+func (this *rowImageStruct) ColorModel() imagecolor.Model {
+	return imagecolor.NRGBAModel
+}
+
+/*
+ * Returns the bounds of the image.
+ */
+func (this *rowImageStruct) Bounds() image.Rectangle {
+	scn := this.scene
+	width := int(scn.width)
+	height := int(scn.height)
+	rect := image.Rect(0, 0, width, height)
+	return rect
+}
+
+/*
+ * Map the colors of a row of the scene, applying hillshading and the
+ * background color.
+ */
+func (this *rowImageStruct) load(y int) {
+	scn := this.scene
+	width := uint64(scn.width)
+	y64 := uint64(y)
+	offset := width * y64
+	counts := scn.bins[offset : offset+width]
+	row := this.row
+	err := this.mapping.MapChunk(row, counts, this.stats)
+
+	/*
+	 * Remember the first error.
+	 */
+	if (err != nil) && (this.err == nil) {
+		this.err = err
+	}
+
+	factors := this.factors
+
+	/*
+	 * Apply hillshading if needed.
+	 */
+	if factors != nil {
+		y32 := uint32(y)
+		scn.shadeRow(y32, factors)
+		applyShading(row, factors)
+	}
+
+	scn.composite(row)
+	this.current = y
+}
+
+/*
+ * Returns the color of the pixel at (x, y).
+ */
+func (this *rowImageStruct) At(x int, y int) imagecolor.Color {
+	scn := this.scene
+	width := int(scn.width)
+	height := int(scn.height)
+
+	/*
+	 * Check if coordinates are in valid range.
+	 */
+	if (x < 0) || (y < 0) || (x >= width) || (y >= height) {
+		return imagecolor.NRGBA{}
+	} else {
+
+		/*
+		 * Map the row if it is not the current one.
+		 */
+		if y != this.current {
+			this.load(y)
+		}
+
+		return this.row[x]
+	}
+
+}
+
+/*
+ * Returns whether the image is fully opaque.
+ *
+ * Without mapping all rows, this is only known for scenes with an opaque
+ * background.
+ */
+func (this *rowImageStruct) Opaque() bool {
+	return this.scene.background.A == 255
+}
+
+/*
+ * Returns the color model of the image.
+ */
+func (this *colorImageStruct) ColorModel() imagecolor.Model {
+	return imagecolor.NRGBAModel
+}
+
+/*
+ * Returns the bounds of the image.
+ */
+func (this *colorImageStruct) Bounds() image.Rectangle {
+	width := this.width
+	height := this.height
+	rect := image.Rect(0, 0, width, height)
+	return rect
+}
+
+/*
+ * Returns the color of the pixel at (x, y).
+ */
+func (this *colorImageStruct) At(x int, y int) imagecolor.Color {
+	width := this.width
+	height := this.height
+
+	/*
+	 * Check if coordinates are in valid range.
+	 */
+	if (x < 0) || (y < 0) || (x >= width) || (y >= height) {
+		return imagecolor.NRGBA{}
+	} else {
+		idx := (width * y) + x
+		return this.colors[idx]
+	}
+
+}
+
+/*
+ * Returns whether the image is fully opaque.
+ *
+ * This allows the PNG encoder to omit the alpha channel without inspecting
+ * each pixel through At.
+ */
+func (this *colorImageStruct) Opaque() bool {
+
+	/*
+	 * Check the alpha value of each pixel.
+	 */
+	for _, c := range this.colors {
+
+		/*
+		 * Check if pixel is not fully opaque.
+		 */
+		if c.A != 255 {
+			return false
+		}
+
+	}
+
+	return true
+}
+
+/*
+ * Render the scene using a color mapping and stream it into a PNG encoder,
+ * writing the encoded image to w.
+ *
+ * Unlike Render, no NRGBA image is allocated. Pixel rows are produced
+ * incrementally from the colors returned by the mapping while the image is
+ * being encoded, which roughly halves peak memory use for very large images.
+ * If the mapping implements color.ChunkedMapping, only a single row of colors
+ * is mapped at a time, so that memory use no longer grows with the number of
+ * pixels. Other mappings, as well as mappings implementing color.GridMapping,
+ * which need the neighbours of each bin, map the colors of the entire scene
+ * at once, as for Render.
+ *
+ * Since filters operate on complete images, a scene with filters is rendered
+ * using Render before it is encoded and no memory is saved.
+ */
+func (this *sceneStruct) RenderPNG(w io.Writer, mapping color.Mapping) error {
+	filters := this.filters
+	_, grid := mapping.(color.GridMapping)
+	enc := png.Encoder{}
+
+	/*
+	 * Only stream if there are no filters to apply and the mapping does
+	 * not need the entire scene.
+	 */
+	if len(filters) > 0 {
+		img, err := this.Render(mapping)
+
+		/*
+		 * Check if image could be rendered.
+		 */
+		if err != nil {
+			return err
+		} else {
+			err = enc.Encode(w, img)
+			return err
+		}
+
+	} else if chunked, ok := mapping.(color.ChunkedMapping); ok && !grid {
+		width := this.width
+		widthInt := int(width)
+		bins := this.bins
+		stats := color.CreateStatistics()
+		stats.Add(bins)
+		factors := []float64(nil)
+
+		/*
+		 * Allocate shading factors for a single row if needed.
+		 */
+		if this.light.strength > 0.0 {
+			factors = make([]float64, widthInt)
+		}
+
+		/*
+		 * Create image mapping one row at a time.
+		 */
+		img := rowImageStruct{
+			current: -1,
+			factors: factors,
+			mapping: chunked,
+			row:     make([]imagecolor.NRGBA, widthInt),
+			scene:   this,
+			stats:   stats,
+		}
+
+		err := enc.Encode(w, &img)
+
+		/*
+		 * Report errors which occured during mapping.
+		 */
+		if err == nil {
+			err = img.err
+		}
+
+		return err
+	} else {
+		colors, err := this.colors(mapping)
+
+		/*
+		 * Check if colors could be mapped.
+		 */
+		if err != nil {
+			return err
+		} else {
+			width := this.width
+			widthInt := int(width)
+			height := this.height
+			heightInt := int(height)
+
+			/*
+			 * Create image reading from the mapped colors.
+			 */
+			img := colorImageStruct{
+				colors: colors,
+				height: heightInt,
+				width:  widthInt,
+			}
+
+			err = enc.Encode(w, &img)
+			return err
+		}
+
+	}
+
+}
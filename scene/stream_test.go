@@ -0,0 +1,162 @@
+package scene
+
+import (
+	"bytes"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/coordinates"
+	imagecolor "image/color"
+	"image/png"
+	"testing"
+)
+
+/*
+ * Streaming a scene into a PNG encoder yields the same pixels as rendering
+ * it, whether or not the mapping maps one row at a time.
+ */
+func TestRenderPNG(t *testing.T) {
+	scn := Create(8, 6, 0.0, 8.0, 0.0, 6.0)
+
+	/*
+	 * Data points forming a small cluster and a few outliers.
+	 */
+	data := []coordinates.Cartesian{
+		coordinates.CreateCartesian(3.5, 2.5),
+		coordinates.CreateCartesian(3.5, 2.5),
+		coordinates.CreateCartesian(3.5, 2.5),
+		coordinates.CreateCartesian(4.5, 2.5),
+		coordinates.CreateCartesian(3.5, 3.5),
+		coordinates.CreateCartesian(0.5, 0.5),
+		coordinates.CreateCartesian(7.5, 5.5),
+	}
+
+	scn.Aggregate(data)
+	scn.Hillshade(0.25, 0.75, 0.5)
+
+	/*
+	 * Mappings with and without support for mapping one row at a time.
+	 */
+	mappings := []struct {
+		name    string
+		mapping color.Mapping
+	}{
+		{"chunked", color.DefaultMapping()},
+		{"reversed", color.Reverse(color.DefaultMapping())},
+		{"embossed", color.EmbossMapping(color.DefaultMapping(), 0.8)},
+	}
+
+	/*
+	 * Render the scene using each mapping.
+	 */
+	for _, m := range mappings {
+		expected, err := scn.Render(m.mapping)
+
+		/*
+		 * Check if scene could be rendered.
+		 */
+		if err != nil {
+			t.Fatalf("%s: %s", m.name, err.Error())
+		}
+
+		buf := bytes.Buffer{}
+		err = scn.RenderPNG(&buf, m.mapping)
+
+		/*
+		 * Check if scene could be streamed.
+		 */
+		if err != nil {
+			t.Fatalf("%s: %s", m.name, err.Error())
+		}
+
+		img, err := png.Decode(&buf)
+
+		/*
+		 * Check if image could be decoded.
+		 */
+		if err != nil {
+			t.Fatalf("%s: %s", m.name, err.Error())
+		}
+
+		rect := expected.Bounds()
+
+		/*
+		 * Check if image has the size of the scene.
+		 */
+		if img.Bounds() != rect {
+			t.Fatalf("%s: Image has bounds %v, expected %v.", m.name, img.Bounds(), rect)
+		}
+
+		/*
+		 * Compare each row of pixels.
+		 */
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+
+			/*
+			 * Compare each pixel of the row.
+			 */
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				c := imagecolor.NRGBAModel.Convert(img.At(x, y))
+				e := expected.NRGBAAt(x, y)
+
+				/*
+				 * Check if pixel matches.
+				 */
+				if c != e {
+					t.Errorf("%s: Pixel (%d, %d) is %v, expected %v.", m.name, x, y, c, e)
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Embossing uses the neighbours of each bin, which the scene passes to the
+ * mapping when rendering.
+ */
+func TestRenderEmboss(t *testing.T) {
+	scn := Create(4, 4, 0.0, 4.0, 0.0, 4.0)
+
+	/*
+	 * A dense bin next to a sparse one.
+	 */
+	data := []coordinates.Cartesian{
+		coordinates.CreateCartesian(1.5, 2.5),
+		coordinates.CreateCartesian(1.5, 2.5),
+		coordinates.CreateCartesian(1.5, 2.5),
+		coordinates.CreateCartesian(2.5, 2.5),
+	}
+
+	scn.Aggregate(data)
+	plain, err := scn.Render(color.DefaultMapping())
+
+	/*
+	 * Check if scene could be rendered.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	embossed, err := scn.Render(color.EmbossMapping(color.DefaultMapping(), 0.8))
+
+	/*
+	 * Check if scene could be rendered.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	c := embossed.NRGBAAt(2, 1)
+	expected := plain.NRGBAAt(2, 1)
+
+	/*
+	 * The bin right of the dense bin lies on the slope facing away from the
+	 * light, so it is darkened.
+	 */
+	if (c.R > expected.R) || (c.G > expected.G) || (c.B >= expected.B) {
+		t.Errorf("Bin facing away from the light is %v, expected darker than %v.", c, expected)
+	}
+
+}
@@ -0,0 +1,355 @@
+package scene
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/track"
+	"math"
+	"sort"
+	"time"
+)
+
+/*
+ * A temporal scene aggregates timestamped points into a series of scenes,
+ * one for each slice of time, e. g. one per month of a year.
+ */
+type TemporalScene interface {
+	Aggregate(data []coordinates.Cartesian, times []time.Time) error
+	AggregateTrack(t track.Track, proj projection.Projection) error
+	Boundaries() []time.Time
+	Clear()
+	Len() int
+	Slice(idx int) (Scene, error)
+	Window(start int, end int) (Scene, error)
+}
+
+/*
+ * Data structure representing a temporal scene.
+ */
+type temporalSceneStruct struct {
+	boundaries []time.Time
+	slices     []*sceneStruct
+}
+
+/*
+ * Aggregate timestamped points into the slices of the scene.
+ *
+ * Each point is aggregated into the slice whose interval contains its time,
+ * where intervals include their start, but not their end. Points outside of
+ * all intervals are ignored.
+ */
+func (this *temporalSceneStruct) Aggregate(data []coordinates.Cartesian, times []time.Time) error {
+	numPoints := len(data)
+	numTimes := len(times)
+
+	/*
+	 * Verify that each point has a time.
+	 */
+	if numPoints != numTimes {
+		return fmt.Errorf("Got %d points, but %d times.", numPoints, numTimes)
+	} else {
+		boundaries := this.boundaries
+		numBoundaries := len(boundaries)
+		slices := this.slices
+		numSlices := len(slices)
+
+		/*
+		 * Aggregate each point into its slice.
+		 */
+		for i, t := range times {
+
+			/*
+			 * Find the number of boundaries not after the time.
+			 */
+			idx := sort.Search(numBoundaries, func(j int) bool {
+				return boundaries[j].After(t)
+			})
+
+			idx--
+
+			/*
+			 * Check if point lies within a slice.
+			 */
+			if (idx >= 0) && (idx < numSlices) {
+				slices[idx].Aggregate(data[i : i+1])
+			}
+
+		}
+
+		return nil
+	}
+
+}
+
+/*
+ * Project the points of a track and aggregate them into the slices of the
+ * scene according to their times, as by Aggregate.
+ */
+func (this *temporalSceneStruct) AggregateTrack(t track.Track, proj projection.Projection) error {
+
+	/*
+	 * Verify that track and projection are present.
+	 */
+	if t == nil {
+		return fmt.Errorf("%s", "Track must not be nil.")
+	} else if proj == nil {
+		return fmt.Errorf("%s", "Projection must not be nil.")
+	} else {
+		points := t.Points()
+		numPoints := len(points)
+		locations := t.Locations()
+		times := make([]time.Time, numPoints)
+
+		/*
+		 * Extract the time of each point.
+		 */
+		for i := range points {
+			times[i] = points[i].Time()
+		}
+
+		data := make([]coordinates.Cartesian, numPoints)
+		err := proj.Forward(data, locations)
+
+		/*
+		 * Check if points could be projected.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to project track: %s", err.Error())
+		} else {
+			return this.Aggregate(data, times)
+		}
+
+	}
+
+}
+
+/*
+ * Returns the boundaries of the slices of this scene, where slice i covers
+ * the interval between boundaries i and i + 1.
+ */
+func (this *temporalSceneStruct) Boundaries() []time.Time {
+	boundaries := this.boundaries
+	result := make([]time.Time, len(boundaries))
+	copy(result, boundaries)
+	return result
+}
+
+/*
+ * Clear all data from the scene.
+ */
+func (this *temporalSceneStruct) Clear() {
+
+	/*
+	 * Clear each slice.
+	 */
+	for _, slice := range this.slices {
+		slice.Clear()
+	}
+
+}
+
+/*
+ * Returns the number of slices of this scene.
+ */
+func (this *temporalSceneStruct) Len() int {
+	return len(this.slices)
+}
+
+/*
+ * Returns a copy of a single slice of this scene.
+ */
+func (this *temporalSceneStruct) Slice(idx int) (Scene, error) {
+	return this.Window(idx, idx+1)
+}
+
+/*
+ * Returns a scene holding the sum of the slices from start (inclusive) to
+ * end (exclusive), e. g. all slices up to a certain one for a cumulative
+ * view.
+ *
+ * Counts are limited to the same maximum as during aggregation.
+ */
+func (this *temporalSceneStruct) Window(start int, end int) (Scene, error) {
+	slices := this.slices
+	numSlices := len(slices)
+
+	/*
+	 * Verify that window lies within the scene.
+	 */
+	if (start < 0) || (end > numSlices) || (start >= end) {
+		return nil, fmt.Errorf("Window [%d, %d) is invalid for a scene with %d slices.", start, end, numSlices)
+	} else {
+		first := slices[start]
+		width := first.width
+		height := first.height
+		result := Create(width, height, first.minX, first.maxX, first.minY, first.maxY)
+		scn := result.(*sceneStruct)
+		bins := scn.bins
+
+		/*
+		 * Add the bins of each slice in the window.
+		 */
+		for _, slice := range slices[start:end] {
+
+			/*
+			 * Add each bin.
+			 */
+			for i, count := range slice.bins {
+				sum := bins[i] + count
+
+				/*
+				 * Make sure we are not exceeding datatype bounds.
+				 */
+				if (sum < count) || (sum > math.MaxUint32) {
+					sum = math.MaxUint32
+				}
+
+				bins[i] = sum
+			}
+
+		}
+
+		return result, nil
+	}
+
+}
+
+/*
+ * Create boundaries from start to end at regular intervals.
+ *
+ * If the interval does not divide the time span, the last slice ends early
+ * at end.
+ */
+func Steps(start time.Time, end time.Time, step time.Duration) []time.Time {
+	boundaries := []time.Time{}
+
+	/*
+	 * Only create boundaries for non-empty time spans.
+	 */
+	if end.After(start) {
+		boundaries = append(boundaries, start)
+
+		/*
+		 * Without a positive step, the time span forms a single slice.
+		 */
+		if step > 0 {
+
+			/*
+			 * Add boundaries until the end is reached.
+			 */
+			for t := start.Add(step); t.Before(end); t = t.Add(step) {
+				boundaries = append(boundaries, t)
+			}
+
+		}
+
+		boundaries = append(boundaries, end)
+	}
+
+	return boundaries
+}
+
+/*
+ * Create boundaries from start to end at calendar intervals, e. g. one month
+ * for a month-by-month animation, which take the varying lengths of months
+ * and years into account.
+ *
+ * Boundaries are calculated from start using time.AddDate, so that they do
+ * not drift when a month is shorter than the day of month of start. If the
+ * interval does not divide the time span, the last slice ends early at end.
+ */
+func CalendarSteps(start time.Time, end time.Time, years int, months int, days int) []time.Time {
+	boundaries := []time.Time{}
+
+	/*
+	 * Only create boundaries for non-empty time spans.
+	 */
+	if end.After(start) {
+		boundaries = append(boundaries, start)
+
+		/*
+		 * A step must advance in time.
+		 */
+		if start.AddDate(years, months, days).After(start) {
+
+			/*
+			 * Add boundaries until the end is reached.
+			 */
+			for i := 1; ; i++ {
+				t := start.AddDate(i*years, i*months, i*days)
+
+				/*
+				 * Stop when the end is reached.
+				 */
+				if !t.Before(end) {
+					break
+				}
+
+				boundaries = append(boundaries, t)
+			}
+
+		}
+
+		boundaries = append(boundaries, end)
+	}
+
+	return boundaries
+}
+
+/*
+ * Create a new temporal scene, whose slices are separated by a series of
+ * boundaries, e. g. created using Steps or CalendarSteps.
+ *
+ * The boundaries must be strictly increasing. N boundaries separate N - 1
+ * slices.
+ */
+func CreateTemporal(width uint32, height uint32, minX float64, maxX float64, minY float64, maxY float64, boundaries []time.Time) (TemporalScene, error) {
+	numBoundaries := len(boundaries)
+
+	/*
+	 * Verify that there is at least one slice.
+	 */
+	if numBoundaries < 2 {
+		return nil, fmt.Errorf("Need at least two boundaries, but got %d.", numBoundaries)
+	} else {
+
+		/*
+		 * Verify that boundaries are strictly increasing.
+		 */
+		for i := 1; i < numBoundaries; i++ {
+
+			/*
+			 * Check if boundary follows its predecessor.
+			 */
+			if !boundaries[i].After(boundaries[i-1]) {
+				return nil, fmt.Errorf("Boundary %d does not follow boundary %d.", i, i-1)
+			}
+
+		}
+
+		boundariesCopy := make([]time.Time, numBoundaries)
+		copy(boundariesCopy, boundaries)
+		numSlices := numBoundaries - 1
+		slices := make([]*sceneStruct, numSlices)
+
+		/*
+		 * Create a scene for each slice.
+		 */
+		for i := range slices {
+			slice := Create(width, height, minX, maxX, minY, maxY)
+			slices[i] = slice.(*sceneStruct)
+		}
+
+		/*
+		 * Create temporal scene data structure.
+		 */
+		scn := temporalSceneStruct{
+			boundaries: boundariesCopy,
+			slices:     slices,
+		}
+
+		return &scn, nil
+	}
+
+}
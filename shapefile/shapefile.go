@@ -0,0 +1,608 @@
+package shapefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+)
+
+/*
+ * Shape types, which may be stored in a shapefile.
+ */
+const (
+	SHAPE_NULL         = 0
+	SHAPE_POINT        = 1
+	SHAPE_POLYLINE     = 3
+	SHAPE_POLYGON      = 5
+	SHAPE_MULTIPOINT   = 8
+	SHAPE_POINT_Z      = 11
+	SHAPE_POLYLINE_Z   = 13
+	SHAPE_POLYGON_Z    = 15
+	SHAPE_MULTIPOINT_Z = 18
+	SHAPE_POINT_M      = 21
+	SHAPE_POLYLINE_M   = 23
+	SHAPE_POLYGON_M    = 25
+	SHAPE_MULTIPOINT_M = 28
+)
+
+/*
+ * Constants of the file formats.
+ */
+const (
+	DBF_DESCRIPTOR_SIZE = 32
+	DBF_HEADER_SIZE     = 32
+	DBF_TERMINATOR      = 0x0d
+	SHP_FILE_CODE       = 9994
+	SHP_HEADER_SIZE     = 100
+	SHP_RECORD_HEADER   = 8
+)
+
+/*
+ * Data structure representing a shape read from a shapefile along with its
+ * attributes.
+ *
+ * Shapes are immutable.
+ */
+type Shape struct {
+	attributes []string
+	fields     []string
+	number     int32
+	parts      [][]coordinates.Cartesian
+	shapeType  int32
+}
+
+/*
+ * Data structure representing a field of a dBASE table.
+ */
+type fieldStruct struct {
+	length int
+	name   string
+}
+
+/*
+ * A reader reads shapes from a shapefile.
+ */
+type Reader interface {
+	Bounds() coordinates.CartesianBounds
+	Fields() []string
+	Read() (Shape, error)
+	ShapeType() int
+}
+
+/*
+ * Data structure representing a reader.
+ */
+type readerStruct struct {
+	bounds     coordinates.CartesianBounds
+	dbf        *bufio.Reader
+	fields     []fieldStruct
+	names      []string
+	numRecords uint32
+	recordLen  int
+	records    uint32
+	remaining  int64
+	shapeType  int32
+	shp        *bufio.Reader
+}
+
+/*
+ * Returns the value of an attribute of this shape or the empty string if
+ * there is no such attribute.
+ *
+ * Values are stored as text and are trimmed.
+ */
+func (this *Shape) Attribute(name string) string {
+
+	/*
+	 * Search the field by name.
+	 */
+	for i, field := range this.fields {
+
+		/*
+		 * Check if name matches and attribute is present.
+		 */
+		if (field == name) && (i < len(this.attributes)) {
+			return this.attributes[i]
+		}
+
+	}
+
+	return ""
+}
+
+/*
+ * Returns the (one-based) record number of this shape.
+ */
+func (this *Shape) Number() int {
+	return int(this.number)
+}
+
+/*
+ * Returns the parts of this shape, i. e. the lines of a polyline or the rings
+ * of a polygon. A point or multipoint consists of a single part.
+ *
+ * If the shapefile stores geographic coordinates, x and y are longitude and
+ * latitude in degrees.
+ */
+func (this *Shape) Parts() [][]coordinates.Cartesian {
+	parts := make([][]coordinates.Cartesian, len(this.parts))
+
+	/*
+	 * Copy each part.
+	 */
+	for i, part := range this.parts {
+		partCopy := make([]coordinates.Cartesian, len(part))
+		copy(partCopy, part)
+		parts[i] = partCopy
+	}
+
+	return parts
+}
+
+/*
+ * Returns all points of this shape, regardless of the part they belong to.
+ */
+func (this *Shape) Points() []coordinates.Cartesian {
+	points := []coordinates.Cartesian{}
+
+	/*
+	 * Collect the points of all parts.
+	 */
+	for _, part := range this.parts {
+		points = append(points, part...)
+	}
+
+	return points
+}
+
+/*
+ * Returns the type of this shape, e. g. SHAPE_POINT.
+ */
+func (this *Shape) Type() int {
+	return int(this.shapeType)
+}
+
+/*
+ * Returns the bounding box of all shapes as stored in the file header.
+ */
+func (this *readerStruct) Bounds() coordinates.CartesianBounds {
+	return this.bounds
+}
+
+/*
+ * Returns the names of the attributes of the shapes.
+ */
+func (this *readerStruct) Fields() []string {
+	names := make([]string, len(this.names))
+	copy(names, this.names)
+	return names
+}
+
+/*
+ * Returns the type of the shapes as stored in the file header.
+ */
+func (this *readerStruct) ShapeType() int {
+	return int(this.shapeType)
+}
+
+/*
+ * Decode a point stored as two little-endian double values.
+ */
+func decodePoint(buf []byte) coordinates.Cartesian {
+	xBits := binary.LittleEndian.Uint64(buf[0:8])
+	yBits := binary.LittleEndian.Uint64(buf[8:16])
+	x := math.Float64frombits(xBits)
+	y := math.Float64frombits(yBits)
+	return coordinates.CreateCartesian(x, y)
+}
+
+/*
+ * Decode the parts of a shape from the content of its record, which starts
+ * after the shape type.
+ */
+func decodeParts(shapeType int32, content []byte) ([][]coordinates.Cartesian, error) {
+	size := len(content)
+
+	/*
+	 * Decide on the layout of the record.
+	 */
+	switch shapeType {
+	case SHAPE_NULL:
+		return [][]coordinates.Cartesian{}, nil
+	case SHAPE_POINT, SHAPE_POINT_Z, SHAPE_POINT_M:
+
+		/*
+		 * A point consists of two double values.
+		 */
+		if size < 16 {
+			return nil, fmt.Errorf("%s", "Point record too short.")
+		} else {
+			point := decodePoint(content)
+			part := []coordinates.Cartesian{point}
+			return [][]coordinates.Cartesian{part}, nil
+		}
+
+	case SHAPE_MULTIPOINT, SHAPE_MULTIPOINT_Z, SHAPE_MULTIPOINT_M:
+
+		/*
+		 * A multipoint consists of a bounding box, the number of points
+		 * and the points.
+		 */
+		if size < 36 {
+			return nil, fmt.Errorf("%s", "Multipoint record too short.")
+		} else {
+			numPoints := int(binary.LittleEndian.Uint32(content[32:36]))
+
+			/*
+			 * Check if all points are present.
+			 */
+			if (numPoints < 0) || ((size-36)/16 < numPoints) {
+				return nil, fmt.Errorf("%s", "Multipoint record too short.")
+			} else {
+				part := make([]coordinates.Cartesian, numPoints)
+
+				/*
+				 * Decode each point.
+				 */
+				for i := range part {
+					offset := 36 + (16 * i)
+					part[i] = decodePoint(content[offset:])
+				}
+
+				return [][]coordinates.Cartesian{part}, nil
+			}
+
+		}
+
+	case SHAPE_POLYLINE, SHAPE_POLYLINE_Z, SHAPE_POLYLINE_M, SHAPE_POLYGON, SHAPE_POLYGON_Z, SHAPE_POLYGON_M:
+
+		/*
+		 * A polyline or polygon consists of a bounding box, the number of
+		 * parts and points, the indices of the first point of each part
+		 * and the points.
+		 */
+		if size < 40 {
+			return nil, fmt.Errorf("%s", "Polyline record too short.")
+		} else {
+			numParts := int(binary.LittleEndian.Uint32(content[32:36]))
+			numPoints := int(binary.LittleEndian.Uint32(content[36:40]))
+			pointsOffset := 40 + (4 * numParts)
+
+			/*
+			 * Check if all parts and points are present.
+			 */
+			if (numParts < 0) || (numPoints < 0) || ((size-40)/4 < numParts) || ((size-pointsOffset)/16 < numPoints) {
+				return nil, fmt.Errorf("%s", "Polyline record too short.")
+			} else {
+				parts := make([][]coordinates.Cartesian, numParts)
+
+				/*
+				 * Decode each part.
+				 */
+				for i := range parts {
+					offset := 40 + (4 * i)
+					start := int(binary.LittleEndian.Uint32(content[offset : offset+4]))
+					end := numPoints
+
+					/*
+					 * A part ends where the next one starts.
+					 */
+					if i+1 < numParts {
+						end = int(binary.LittleEndian.Uint32(content[offset+4 : offset+8]))
+					}
+
+					/*
+					 * Check if indices are valid.
+					 */
+					if (start < 0) || (start > end) || (end > numPoints) {
+						return nil, fmt.Errorf("%s", "Invalid part index.")
+					}
+
+					part := make([]coordinates.Cartesian, end-start)
+
+					/*
+					 * Decode each point of the part.
+					 */
+					for j := range part {
+						pointOffset := pointsOffset + (16 * (start + j))
+						part[j] = decodePoint(content[pointOffset:])
+					}
+
+					parts[i] = part
+				}
+
+				return parts, nil
+			}
+
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported shape type: %d", shapeType)
+	}
+
+}
+
+/*
+ * Read the attributes of the next record from the dBASE table.
+ */
+func (this *readerStruct) readAttributes() ([]string, error) {
+
+	/*
+	 * Check if there is a dBASE table with remaining records.
+	 */
+	if (this.dbf == nil) || (this.records >= this.numRecords) {
+		return nil, nil
+	} else {
+		buf := make([]byte, this.recordLen)
+		_, err := io.ReadFull(this.dbf, buf)
+
+		/*
+		 * Check if record could be read.
+		 */
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read attributes: %s", err.Error())
+		} else {
+			this.records++
+			fields := this.fields
+			attributes := make([]string, len(fields))
+			offset := 1
+
+			/*
+			 * Extract each field, skipping the deletion flag.
+			 */
+			for i, field := range fields {
+				end := offset + field.length
+
+				/*
+				 * Make sure field lies within record.
+				 */
+				if end > len(buf) {
+					end = len(buf)
+				}
+
+				value := string(buf[offset:end])
+				attributes[i] = strings.TrimSpace(value)
+				offset = end
+			}
+
+			return attributes, nil
+		}
+
+	}
+
+}
+
+/*
+ * Read the next shape.
+ *
+ * Returns io.EOF after the last shape, as given by the file length stored in
+ * the file header.
+ */
+func (this *readerStruct) Read() (Shape, error) {
+	header := make([]byte, SHP_RECORD_HEADER)
+	err := io.EOF
+
+	/*
+	 * Only read up to the end of the file as stored in the header.
+	 */
+	if this.remaining >= SHP_RECORD_HEADER {
+		_, err = io.ReadFull(this.shp, header)
+	}
+
+	/*
+	 * Check if record header could be read.
+	 */
+	if err == io.EOF {
+		return Shape{}, io.EOF
+	} else if err != nil {
+		return Shape{}, fmt.Errorf("Failed to read record header: %s", err.Error())
+	} else {
+		this.remaining -= SHP_RECORD_HEADER
+		number := int32(binary.BigEndian.Uint32(header[0:4]))
+		words := binary.BigEndian.Uint32(header[4:8])
+		size := int64(words) * 2
+
+		/*
+		 * The content contains at least the shape type and must lie
+		 * within the file.
+		 */
+		if size < 4 {
+			return Shape{}, fmt.Errorf("Record %d too short.", number)
+		} else if size > this.remaining {
+			return Shape{}, fmt.Errorf("Record %d of %d bytes exceeds the remaining %d bytes of the file.", number, size, this.remaining)
+		} else {
+			this.remaining -= size
+			limited := io.LimitReader(this.shp, size)
+
+			/*
+			 * Read the content as it arrives, so that memory is only
+			 * allocated for data actually present.
+			 */
+			content, err := ioutil.ReadAll(limited)
+			numBytes := int64(len(content))
+
+			/*
+			 * Check if record content could be read.
+			 */
+			if err != nil {
+				return Shape{}, fmt.Errorf("Failed to read record %d: %s", number, err.Error())
+			} else if numBytes != size {
+				return Shape{}, fmt.Errorf("Record %d truncated after %d of %d bytes.", number, numBytes, size)
+			} else {
+				shapeType := int32(binary.LittleEndian.Uint32(content[0:4]))
+				parts, errParts := decodeParts(shapeType, content[4:])
+				attributes, errAttributes := this.readAttributes()
+
+				/*
+				 * Check if record could be decoded.
+				 */
+				if errParts != nil {
+					return Shape{}, fmt.Errorf("Failed to decode record %d: %s", number, errParts.Error())
+				} else if errAttributes != nil {
+					return Shape{}, errAttributes
+				} else {
+
+					/*
+					 * Create shape.
+					 */
+					shape := Shape{
+						attributes: attributes,
+						fields:     this.names,
+						number:     number,
+						parts:      parts,
+						shapeType:  shapeType,
+					}
+
+					return shape, nil
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Read the header and field descriptors of a dBASE table.
+ */
+func (this *readerStruct) readTableHeader() error {
+	header := make([]byte, DBF_HEADER_SIZE)
+	_, err := io.ReadFull(this.dbf, header)
+
+	/*
+	 * Check if header could be read.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to read table header: %s", err.Error())
+	} else {
+		this.numRecords = binary.LittleEndian.Uint32(header[4:8])
+		headerLen := int(binary.LittleEndian.Uint16(header[8:10]))
+		this.recordLen = int(binary.LittleEndian.Uint16(header[10:12]))
+		remaining := headerLen - DBF_HEADER_SIZE
+
+		/*
+		 * Check header length.
+		 */
+		if remaining < 1 {
+			return fmt.Errorf("%s", "Invalid table header length.")
+		} else {
+			descriptors := make([]byte, remaining)
+			_, err = io.ReadFull(this.dbf, descriptors)
+
+			/*
+			 * Check if field descriptors could be read.
+			 */
+			if err != nil {
+				return fmt.Errorf("Failed to read field descriptors: %s", err.Error())
+			} else {
+				fields := []fieldStruct{}
+				names := []string{}
+
+				/*
+				 * Decode field descriptors up to the terminator.
+				 */
+				for offset := 0; (offset+DBF_DESCRIPTOR_SIZE <= remaining) && (descriptors[offset] != DBF_TERMINATOR); offset += DBF_DESCRIPTOR_SIZE {
+					descriptor := descriptors[offset : offset+DBF_DESCRIPTOR_SIZE]
+					rawName := string(descriptor[0:11])
+					name := strings.TrimRight(rawName, "\x00 ")
+
+					/*
+					 * Create field.
+					 */
+					field := fieldStruct{
+						length: int(descriptor[16]),
+						name:   name,
+					}
+
+					fields = append(fields, field)
+					names = append(names, name)
+				}
+
+				this.fields = fields
+				this.names = names
+				return nil
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Create a reader for a shapefile, consisting of the main file (.shp) and
+ * optionally the dBASE table (.dbf) holding the attributes of the shapes.
+ *
+ * Points, multipoints, polylines and polygons (including their variants with
+ * z- or m-values, which are ignored) are supported. Shapes are read one at a
+ * time, so that large files need not fit into memory. Pass nil for the table
+ * if attributes are not needed.
+ */
+func Create(shp io.Reader, dbf io.Reader) (Reader, error) {
+	shpReader := bufio.NewReader(decompress.Auto(shp))
+	header := make([]byte, SHP_HEADER_SIZE)
+	_, err := io.ReadFull(shpReader, header)
+
+	/*
+	 * Check if header could be read.
+	 */
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read shapefile header: %s", err.Error())
+	} else {
+		fileCode := binary.BigEndian.Uint32(header[0:4])
+
+		/*
+		 * Check file code.
+		 */
+		if fileCode != SHP_FILE_CODE {
+			return nil, fmt.Errorf("Invalid file code: %d", fileCode)
+		} else {
+			words := binary.BigEndian.Uint32(header[24:28])
+			remaining := (int64(words) * 2) - SHP_HEADER_SIZE
+			shapeType := int32(binary.LittleEndian.Uint32(header[32:36]))
+			min := decodePoint(header[36:52])
+			max := decodePoint(header[52:68])
+			bounds := coordinates.CreateCartesianBounds(min, max)
+
+			/*
+			 * Create reader data structure.
+			 */
+			rd := readerStruct{
+				bounds:    bounds,
+				fields:    []fieldStruct{},
+				names:     []string{},
+				remaining: remaining,
+				shapeType: shapeType,
+				shp:       shpReader,
+			}
+
+			/*
+			 * Read table header if present.
+			 */
+			if dbf != nil {
+				rd.dbf = bufio.NewReader(decompress.Auto(dbf))
+				err = rd.readTableHeader()
+
+				/*
+				 * Check if table header could be read.
+				 */
+				if err != nil {
+					return nil, err
+				}
+
+			}
+
+			return &rd, nil
+		}
+
+	}
+
+}
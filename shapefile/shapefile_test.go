@@ -0,0 +1,411 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/andrepxx/sydney/coordinates"
+	"io"
+	"math"
+	"testing"
+)
+
+/*
+ * Encode a point as two little-endian double values.
+ */
+func encodePoint(buf *bytes.Buffer, point coordinates.Cartesian) {
+	binary.Write(buf, binary.LittleEndian, point.X())
+	binary.Write(buf, binary.LittleEndian, point.Y())
+}
+
+/*
+ * Encode the content of a point record.
+ */
+func encodePointRecord(point coordinates.Cartesian) []byte {
+	buf := bytes.Buffer{}
+	binary.Write(&buf, binary.LittleEndian, int32(SHAPE_POINT))
+	encodePoint(&buf, point)
+	return buf.Bytes()
+}
+
+/*
+ * Encode the content of a polygon record.
+ */
+func encodePolygonRecord(parts [][]coordinates.Cartesian) []byte {
+	buf := bytes.Buffer{}
+	binary.Write(&buf, binary.LittleEndian, int32(SHAPE_POLYGON))
+	bounds := coordinates.EmptyCartesianBounds()
+	numPoints := 0
+
+	/*
+	 * Determine the bounding box and the number of points.
+	 */
+	for _, part := range parts {
+
+		/*
+		 * Expand the bounding box by each point.
+		 */
+		for _, point := range part {
+			bounds = bounds.Expand(point)
+		}
+
+		numPoints += len(part)
+	}
+
+	encodePoint(&buf, bounds.Min())
+	encodePoint(&buf, bounds.Max())
+	binary.Write(&buf, binary.LittleEndian, int32(len(parts)))
+	binary.Write(&buf, binary.LittleEndian, int32(numPoints))
+	start := int32(0)
+
+	/*
+	 * Encode the index of the first point of each part.
+	 */
+	for _, part := range parts {
+		binary.Write(&buf, binary.LittleEndian, start)
+		start += int32(len(part))
+	}
+
+	/*
+	 * Encode the points of all parts.
+	 */
+	for _, part := range parts {
+
+		/*
+		 * Encode each point.
+		 */
+		for _, point := range part {
+			encodePoint(&buf, point)
+		}
+
+	}
+
+	return buf.Bytes()
+}
+
+/*
+ * Encode a shapefile holding records of a shape type.
+ */
+func encodeShapefile(shapeType int32, bounds coordinates.CartesianBounds, records [][]byte) []byte {
+	body := bytes.Buffer{}
+
+	/*
+	 * Encode each record along with its header.
+	 */
+	for i, record := range records {
+		binary.Write(&body, binary.BigEndian, int32(i+1))
+		binary.Write(&body, binary.BigEndian, int32(len(record)/2))
+		body.Write(record)
+	}
+
+	buf := bytes.Buffer{}
+	length := SHP_HEADER_SIZE + body.Len()
+	binary.Write(&buf, binary.BigEndian, int32(SHP_FILE_CODE))
+	buf.Write(make([]byte, 20))
+	binary.Write(&buf, binary.BigEndian, int32(length/2))
+	binary.Write(&buf, binary.LittleEndian, int32(1000))
+	binary.Write(&buf, binary.LittleEndian, shapeType)
+	encodePoint(&buf, bounds.Min())
+	encodePoint(&buf, bounds.Max())
+	buf.Write(make([]byte, 32))
+	buf.Write(body.Bytes())
+	return buf.Bytes()
+}
+
+/*
+ * Encode a dBASE table holding character fields.
+ */
+func encodeTable(names []string, lengths []int, rows [][]string) []byte {
+	numFields := len(names)
+	recordLen := 1
+
+	/*
+	 * Each record starts with a deletion flag.
+	 */
+	for _, length := range lengths {
+		recordLen += length
+	}
+
+	buf := bytes.Buffer{}
+	headerLen := DBF_HEADER_SIZE + (DBF_DESCRIPTOR_SIZE * numFields) + 1
+	header := make([]byte, DBF_HEADER_SIZE)
+	header[0] = 0x03
+	binary.LittleEndian.PutUint32(header[4:8], uint32(len(rows)))
+	binary.LittleEndian.PutUint16(header[8:10], uint16(headerLen))
+	binary.LittleEndian.PutUint16(header[10:12], uint16(recordLen))
+	buf.Write(header)
+
+	/*
+	 * Encode the descriptor of each field.
+	 */
+	for i, name := range names {
+		descriptor := make([]byte, DBF_DESCRIPTOR_SIZE)
+		copy(descriptor[0:11], name)
+		descriptor[11] = 'C'
+		descriptor[16] = byte(lengths[i])
+		buf.Write(descriptor)
+	}
+
+	buf.WriteByte(DBF_TERMINATOR)
+
+	/*
+	 * Encode each record, padding values with spaces.
+	 */
+	for _, row := range rows {
+		buf.WriteByte(' ')
+
+		/*
+		 * Encode each value.
+		 */
+		for i, value := range row {
+			field := bytes.Repeat([]byte{' '}, lengths[i])
+			copy(field, value)
+			buf.Write(field)
+		}
+
+	}
+
+	return buf.Bytes()
+}
+
+/*
+ * Returns whether two points are equal.
+ */
+func equalPoints(a coordinates.Cartesian, b coordinates.Cartesian) bool {
+	return (a.X() == b.X()) && (a.Y() == b.Y())
+}
+
+/*
+ * Write polygons along with their attributes and read them back.
+ */
+func TestReadPolygons(t *testing.T) {
+	square := []coordinates.Cartesian{
+		coordinates.CreateCartesian(0.0, 0.0),
+		coordinates.CreateCartesian(0.0, 10.0),
+		coordinates.CreateCartesian(10.0, 10.0),
+		coordinates.CreateCartesian(10.0, 0.0),
+		coordinates.CreateCartesian(0.0, 0.0),
+	}
+
+	hole := []coordinates.Cartesian{
+		coordinates.CreateCartesian(2.0, 2.0),
+		coordinates.CreateCartesian(4.0, 2.0),
+		coordinates.CreateCartesian(4.0, 4.0),
+		coordinates.CreateCartesian(2.0, 2.0),
+	}
+
+	triangle := []coordinates.Cartesian{
+		coordinates.CreateCartesian(-5.5, -1.25),
+		coordinates.CreateCartesian(-3.0, 7.75),
+		coordinates.CreateCartesian(-1.0, -1.25),
+		coordinates.CreateCartesian(-5.5, -1.25),
+	}
+
+	shapes := [][][]coordinates.Cartesian{
+		[][]coordinates.Cartesian{square, hole},
+		[][]coordinates.Cartesian{triangle},
+	}
+
+	names := []string{"Square", "Triangle"}
+	bounds := coordinates.CreateCartesianBounds(coordinates.CreateCartesian(-5.5, -1.25), coordinates.CreateCartesian(10.0, 10.0))
+	records := [][]byte{}
+
+	/*
+	 * Encode each shape.
+	 */
+	for _, parts := range shapes {
+		records = append(records, encodePolygonRecord(parts))
+	}
+
+	shp := encodeShapefile(SHAPE_POLYGON, bounds, records)
+	dbf := encodeTable([]string{"NAME"}, []int{12}, [][]string{[]string{names[0]}, []string{names[1]}})
+	rd, err := Create(bytes.NewReader(shp), bytes.NewReader(dbf))
+
+	/*
+	 * Check if reader could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	readBounds := rd.Bounds()
+
+	/*
+	 * Check the header.
+	 */
+	if rd.ShapeType() != SHAPE_POLYGON {
+		t.Errorf("Shape type is %d, expected %d.", rd.ShapeType(), SHAPE_POLYGON)
+	} else if !equalPoints(readBounds.Min(), bounds.Min()) || !equalPoints(readBounds.Max(), bounds.Max()) {
+		t.Errorf("%s", "Bounds do not match.")
+	} else if fields := rd.Fields(); (len(fields) != 1) || (fields[0] != "NAME") {
+		t.Errorf("Fields are %v, expected [NAME].", fields)
+	}
+
+	/*
+	 * Read each shape back.
+	 */
+	for i, parts := range shapes {
+		shape, err := rd.Read()
+
+		/*
+		 * Check if shape could be read.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		readParts := shape.Parts()
+
+		/*
+		 * Compare the shape with the one written.
+		 */
+		if shape.Number() != i+1 {
+			t.Errorf("Shape %d has number %d.", i, shape.Number())
+		} else if shape.Type() != SHAPE_POLYGON {
+			t.Errorf("Shape %d has type %d.", i, shape.Type())
+		} else if shape.Attribute("NAME") != names[i] {
+			t.Errorf("Shape %d has name '%s', expected '%s'.", i, shape.Attribute("NAME"), names[i])
+		} else if len(readParts) != len(parts) {
+			t.Errorf("Shape %d has %d parts, expected %d.", i, len(readParts), len(parts))
+		} else {
+
+			/*
+			 * Compare each part.
+			 */
+			for j, part := range parts {
+				readPart := readParts[j]
+
+				/*
+				 * Check if number of points matches.
+				 */
+				if len(readPart) != len(part) {
+					t.Errorf("Part %d of shape %d has %d points, expected %d.", j, i, len(readPart), len(part))
+				} else {
+
+					/*
+					 * Compare each point.
+					 */
+					for k, point := range part {
+
+						/*
+						 * Check if point matches.
+						 */
+						if !equalPoints(readPart[k], point) {
+							t.Errorf("Point %d of part %d of shape %d does not match.", k, j, i)
+						}
+
+					}
+
+				}
+
+			}
+
+		}
+
+	}
+
+	_, err = rd.Read()
+
+	/*
+	 * Check if the end of the file was reached.
+	 */
+	if err != io.EOF {
+		t.Errorf("Expected io.EOF after the last shape, got %v", err)
+	}
+
+}
+
+/*
+ * Only read records within the file length stored in the header.
+ */
+func TestFileLength(t *testing.T) {
+	point := coordinates.CreateCartesian(13.4, 52.5)
+	bounds := coordinates.CreateCartesianBounds(point, point)
+	record := encodePointRecord(point)
+	shp := encodeShapefile(SHAPE_POINT, bounds, [][]byte{record})
+	trailing := append(append([]byte{}, shp...), bytes.Repeat([]byte{0xff}, 64)...)
+	rd, err := Create(bytes.NewReader(trailing), nil)
+
+	/*
+	 * Check if reader could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	shape, err := rd.Read()
+	points := shape.Points()
+
+	/*
+	 * Check if point could be read.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	} else if (len(points) != 1) || !equalPoints(points[0], point) {
+		t.Errorf("Read points %v, expected %v.", points, point)
+	}
+
+	_, err = rd.Read()
+
+	/*
+	 * Trailing data is ignored.
+	 */
+	if err != io.EOF {
+		t.Errorf("Expected io.EOF after the last shape, got %v", err)
+	}
+
+	truncated := shp[:len(shp)-4]
+	rd, err = Create(bytes.NewReader(truncated), nil)
+
+	/*
+	 * Check if reader could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	_, err = rd.Read()
+
+	/*
+	 * Truncated records are reported.
+	 */
+	if (err == nil) || (err == io.EOF) {
+		t.Errorf("Expected an error for a truncated record, got %v", err)
+	}
+
+	oversized := append([]byte{}, shp...)
+	binary.BigEndian.PutUint32(oversized[SHP_HEADER_SIZE+4:], math.MaxInt32)
+	rd, err = Create(bytes.NewReader(oversized), nil)
+
+	/*
+	 * Check if reader could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	_, err = rd.Read()
+
+	/*
+	 * Records exceeding the file are reported.
+	 */
+	if (err == nil) || (err == io.EOF) {
+		t.Errorf("Expected an error for an oversized record, got %v", err)
+	}
+
+}
+
+/*
+ * Files which are no shapefiles are rejected.
+ */
+func TestInvalidFileCode(t *testing.T) {
+	data := make([]byte, SHP_HEADER_SIZE)
+	_, err := Create(bytes.NewReader(data), nil)
+
+	/*
+	 * Check if an error was returned.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Expected an error for an invalid file code.")
+	}
+
+}
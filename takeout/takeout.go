@@ -0,0 +1,539 @@
+package takeout
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/decompress"
+	"github.com/andrepxx/sydney/track"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Scale of coordinates stored as integers in the legacy format.
+ */
+const (
+	COORDINATE_SCALE_E7 = 1e7
+)
+
+/*
+ * Data structure representing a location record in the legacy format
+ * (Records.json).
+ */
+type recordStruct struct {
+	LatitudeE7  *int64   `json:"latitudeE7"`
+	LongitudeE7 *int64   `json:"longitudeE7"`
+	Altitude    *float64 `json:"altitude"`
+	Velocity    *float64 `json:"velocity"`
+	Timestamp   string   `json:"timestamp"`
+	TimestampMs string   `json:"timestampMs"`
+}
+
+/*
+ * Data structure representing a location given as text, e. g.
+ * "48.1234567°, 11.5678901°" or "geo:48.123457,11.567890".
+ */
+type latLngStruct struct {
+	LatLng string `json:"latLng"`
+}
+
+/*
+ * Data structure representing a point along a timeline path.
+ */
+type pathPointStruct struct {
+	Point                              string `json:"point"`
+	Time                               string `json:"time"`
+	DurationMinutesOffsetFromStartTime string `json:"durationMinutesOffsetFromStartTime"`
+}
+
+/*
+ * Data structure representing a semantic segment, i. e. a visit, an activity
+ * or a timeline path.
+ */
+type segmentStruct struct {
+	StartTime    string            `json:"startTime"`
+	EndTime      string            `json:"endTime"`
+	TimelinePath []pathPointStruct `json:"timelinePath"`
+	Visit        *struct {
+		TopCandidate struct {
+			PlaceLocation json.RawMessage `json:"placeLocation"`
+		} `json:"topCandidate"`
+	} `json:"visit"`
+	Activity *struct {
+		Start json.RawMessage `json:"start"`
+		End   json.RawMessage `json:"end"`
+	} `json:"activity"`
+}
+
+/*
+ * Data structure representing a raw signal, of which only positions are used.
+ */
+type signalStruct struct {
+	Position *struct {
+		LatLng               string   `json:"LatLng"`
+		Timestamp            string   `json:"timestamp"`
+		AltitudeMeters       *float64 `json:"altitudeMeters"`
+		SpeedMetersPerSecond *float64 `json:"speedMetersPerSecond"`
+	} `json:"position"`
+}
+
+/*
+ * Parse a location given as text, e. g. "48.1234567°, 11.5678901°" or
+ * "geo:48.123457,11.567890".
+ */
+func parseLatLng(s string) (coordinates.Geographic, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "geo:")
+	return coordinates.ParseGeographic(s)
+}
+
+/*
+ * Parse a location, which is either given as text or as an object with a
+ * "latLng" member.
+ */
+func parseLocation(raw json.RawMessage) (coordinates.Geographic, error) {
+	text := ""
+	err := json.Unmarshal(raw, &text)
+
+	/*
+	 * Try object with a "latLng" member if location is not a string.
+	 */
+	if err != nil {
+		obj := latLngStruct{}
+		err = json.Unmarshal(raw, &obj)
+
+		/*
+		 * Check if object could be decoded.
+		 */
+		if err != nil {
+			return coordinates.Geographic{}, err
+		}
+
+		text = obj.LatLng
+	}
+
+	return parseLatLng(text)
+}
+
+/*
+ * Parse a timestamp in RFC 3339 format, with or without fractional seconds.
+ */
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+}
+
+/*
+ * Returns the value of an optional number or NaN if it is missing.
+ */
+func optional(value *float64) float64 {
+
+	/*
+	 * Check if value is present.
+	 */
+	if value == nil {
+		return math.NaN()
+	} else {
+		return *value
+	}
+
+}
+
+/*
+ * Convert a record of the legacy format into a track point.
+ */
+func (this *recordStruct) point() (track.TrackPoint, error) {
+
+	/*
+	 * Check if coordinates are present.
+	 */
+	if (this.LatitudeE7 == nil) || (this.LongitudeE7 == nil) {
+		return track.TrackPoint{}, fmt.Errorf("%s", "Record without coordinates.")
+	} else {
+		latitude := float64(*this.LatitudeE7) / COORDINATE_SCALE_E7
+		longitude := float64(*this.LongitudeE7) / COORDINATE_SCALE_E7
+		location := coordinates.CreateGeographicDegrees(longitude, latitude)
+		timestamp := time.Time{}
+		err := error(nil)
+
+		/*
+		 * Older exports store milliseconds since the epoch.
+		 */
+		if this.Timestamp != "" {
+			timestamp, err = parseTime(this.Timestamp)
+		} else {
+			millis := int64(0)
+			millis, err = strconv.ParseInt(this.TimestampMs, 10, 64)
+			timestamp = time.Unix(0, millis*int64(time.Millisecond)).UTC()
+		}
+
+		/*
+		 * Check if timestamp could be parsed.
+		 */
+		if err != nil {
+			return track.TrackPoint{}, err
+		} else {
+			elevation := optional(this.Altitude)
+			speed := optional(this.Velocity)
+			p := track.CreateTrackPoint(location, timestamp, elevation, speed)
+			return p, nil
+		}
+
+	}
+
+}
+
+/*
+ * Convert a semantic segment into track points.
+ */
+func (this *segmentStruct) points() ([]track.TrackPoint, error) {
+	points := []track.TrackPoint{}
+	nan := math.NaN()
+	start := time.Time{}
+	err := error(nil)
+
+	/*
+	 * Parse start time if present.
+	 */
+	if this.StartTime != "" {
+		start, err = parseTime(this.StartTime)
+
+		/*
+		 * Check if start time could be parsed.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	/*
+	 * Add the location of a visit.
+	 */
+	if (this.Visit != nil) && (len(this.Visit.TopCandidate.PlaceLocation) > 0) {
+		location, err := parseLocation(this.Visit.TopCandidate.PlaceLocation)
+
+		/*
+		 * Check if location could be parsed.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		p := track.CreateTrackPoint(location, start, nan, nan)
+		points = append(points, p)
+	}
+
+	/*
+	 * Add the start and end of an activity.
+	 */
+	if this.Activity != nil {
+		ends := []json.RawMessage{this.Activity.Start, this.Activity.End}
+		times := []string{this.StartTime, this.EndTime}
+
+		/*
+		 * Add each end of the activity.
+		 */
+		for i, raw := range ends {
+
+			/*
+			 * Skip missing ends.
+			 */
+			if len(raw) > 0 {
+				location, err := parseLocation(raw)
+
+				/*
+				 * Check if location could be parsed.
+				 */
+				if err != nil {
+					return nil, err
+				}
+
+				timestamp, err := parseTime(times[i])
+
+				/*
+				 * Check if time could be parsed.
+				 */
+				if err != nil {
+					return nil, err
+				}
+
+				p := track.CreateTrackPoint(location, timestamp, nan, nan)
+				points = append(points, p)
+			}
+
+		}
+
+	}
+
+	/*
+	 * Add all points along the timeline path.
+	 */
+	for _, pathPoint := range this.TimelinePath {
+		location, err := parseLatLng(pathPoint.Point)
+
+		/*
+		 * Check if location could be parsed.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		timestamp := start
+
+		/*
+		 * Points carry either a time or an offset from the start time.
+		 */
+		if pathPoint.Time != "" {
+			timestamp, err = parseTime(pathPoint.Time)
+		} else if pathPoint.DurationMinutesOffsetFromStartTime != "" {
+			minutes := float64(0.0)
+			minutes, err = strconv.ParseFloat(pathPoint.DurationMinutesOffsetFromStartTime, 64)
+			offset := time.Duration(minutes * float64(time.Minute))
+			timestamp = start.Add(offset)
+		}
+
+		/*
+		 * Check if time could be parsed.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		p := track.CreateTrackPoint(location, timestamp, nan, nan)
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+/*
+ * Convert a raw signal into track points.
+ */
+func (this *signalStruct) points() ([]track.TrackPoint, error) {
+	position := this.Position
+
+	/*
+	 * Only positions are used.
+	 */
+	if position == nil {
+		return []track.TrackPoint{}, nil
+	} else {
+		location, err := parseLatLng(position.LatLng)
+
+		/*
+		 * Check if location could be parsed.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		timestamp, err := parseTime(position.Timestamp)
+
+		/*
+		 * Check if time could be parsed.
+		 */
+		if err != nil {
+			return nil, err
+		}
+
+		elevation := optional(position.AltitudeMeters)
+		speed := optional(position.SpeedMetersPerSecond)
+		p := track.CreateTrackPoint(location, timestamp, elevation, speed)
+		return []track.TrackPoint{p}, nil
+	}
+
+}
+
+/*
+ * Decode the elements of a JSON array one at a time, so that the whole array
+ * need not fit into memory. The function converts each element into track
+ * points.
+ */
+func decodeArray(decoder *json.Decoder, convert func(decoder *json.Decoder) ([]track.TrackPoint, error), points []track.TrackPoint) ([]track.TrackPoint, error) {
+	token, err := decoder.Token()
+
+	/*
+	 * Check if array starts.
+	 */
+	if err != nil {
+		return nil, err
+	} else if token != json.Delim('[') {
+		return nil, fmt.Errorf("%s", "Expected array.")
+	} else {
+
+		/*
+		 * Decode each element.
+		 */
+		for decoder.More() {
+			converted, err := convert(decoder)
+
+			/*
+			 * Check if element could be converted.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+			points = append(points, converted...)
+		}
+
+		_, err = decoder.Token()
+		return points, err
+	}
+
+}
+
+/*
+ * Convert the next legacy record.
+ */
+func convertRecord(decoder *json.Decoder) ([]track.TrackPoint, error) {
+	record := recordStruct{}
+	err := decoder.Decode(&record)
+
+	/*
+	 * Check if record could be decoded.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		p, err := record.point()
+
+		/*
+		 * Check if record could be converted.
+		 */
+		if err != nil {
+			return nil, err
+		} else {
+			return []track.TrackPoint{p}, nil
+		}
+
+	}
+
+}
+
+/*
+ * Convert the next semantic segment.
+ */
+func convertSegment(decoder *json.Decoder) ([]track.TrackPoint, error) {
+	segment := segmentStruct{}
+	err := decoder.Decode(&segment)
+
+	/*
+	 * Check if segment could be decoded.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		return segment.points()
+	}
+
+}
+
+/*
+ * Convert the next raw signal.
+ */
+func convertSignal(decoder *json.Decoder) ([]track.TrackPoint, error) {
+	signal := signalStruct{}
+	err := decoder.Decode(&signal)
+
+	/*
+	 * Check if signal could be decoded.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		return signal.points()
+	}
+
+}
+
+/*
+ * Read timestamped locations from a Google Takeout Location History export.
+ *
+ * Both the legacy format (Records.json, with a "locations" array) and the
+ * newer semantic segments format (with "semanticSegments" and "rawSignals"
+ * arrays or a top-level array of segments) are supported. For semantic
+ * segments, the locations of visits, the start and end of activities and all
+ * points along timeline paths are returned. Arrays are decoded one element at
+ * a time, so that only the resulting points need to fit into memory. The
+ * points are returned in the order in which they appear in the file, use
+ * track.Create to sort them by time.
+ */
+func Read(r io.Reader) ([]track.TrackPoint, error) {
+	decoder := json.NewDecoder(decompress.Auto(r))
+	points := []track.TrackPoint{}
+	token, err := decoder.Token()
+
+	/*
+	 * Decide on the format.
+	 */
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode location history: %s", err.Error())
+	} else if token == json.Delim('[') {
+
+		/*
+		 * A top-level array contains semantic segments.
+		 */
+		for decoder.More() {
+			converted, err := convertSegment(decoder)
+
+			/*
+			 * Check if segment could be converted.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Failed to decode location history: %s", err.Error())
+			}
+
+			points = append(points, converted...)
+		}
+
+		return points, nil
+	} else if token == json.Delim('{') {
+
+		/*
+		 * Process all members of the top-level object.
+		 */
+		for decoder.More() {
+			key, err := decoder.Token()
+
+			/*
+			 * Decide on the contents of the member.
+			 */
+			if err == nil {
+
+				/*
+				 * Convert known arrays, skip anything else.
+				 */
+				switch key {
+				case "locations":
+					points, err = decodeArray(decoder, convertRecord, points)
+				case "semanticSegments":
+					points, err = decodeArray(decoder, convertSegment, points)
+				case "rawSignals":
+					points, err = decodeArray(decoder, convertSignal, points)
+				default:
+					skipped := json.RawMessage{}
+					err = decoder.Decode(&skipped)
+				}
+
+			}
+
+			/*
+			 * Check if member could be decoded.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Failed to decode location history: %s", err.Error())
+			}
+
+		}
+
+		return points, nil
+	} else {
+		return nil, fmt.Errorf("%s", "Location history must be an object or an array.")
+	}
+
+}
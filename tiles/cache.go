@@ -0,0 +1,131 @@
+package tiles
+
+import (
+	"container/list"
+	"sync"
+)
+
+/*
+ * Data structure representing a cached tile.
+ */
+type cacheEntryStruct struct {
+	data []byte
+	tile tileStruct
+}
+
+/*
+ * Data structure representing a cache holding the most recently used tiles.
+ *
+ * The generation is incremented whenever the cache is cleared, so that tiles
+ * rendered from outdated data are not added afterwards.
+ */
+type cacheStruct struct {
+	capacity   int
+	entries    map[tileStruct]*list.Element
+	generation uint64
+	mutex      sync.Mutex
+	order      *list.List
+}
+
+/*
+ * Look up a tile in the cache and mark it as most recently used.
+ *
+ * Returns the tile, whether it was found and the current generation of the
+ * cache. Empty tiles are cached as nil.
+ */
+func (this *cacheStruct) get(tile tileStruct) ([]byte, bool, uint64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	elem, ok := this.entries[tile]
+	generation := this.generation
+
+	/*
+	 * Check if tile is cached.
+	 */
+	if !ok {
+		return nil, false, generation
+	} else {
+		this.order.MoveToFront(elem)
+		entry := elem.Value.(*cacheEntryStruct)
+		return entry.data, true, generation
+	}
+
+}
+
+/*
+ * Add a tile rendered during a generation of the cache, evicting the least
+ * recently used tiles if the cache is full.
+ */
+func (this *cacheStruct) put(tile tileStruct, data []byte, generation uint64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	/*
+	 * Only add tiles rendered from current data.
+	 */
+	if (this.capacity > 0) && (generation == this.generation) {
+		elem, ok := this.entries[tile]
+
+		/*
+		 * Update the tile if it is already cached.
+		 */
+		if ok {
+			entry := elem.Value.(*cacheEntryStruct)
+			entry.data = data
+			this.order.MoveToFront(elem)
+		} else {
+
+			/*
+			 * Create cache entry.
+			 */
+			entry := cacheEntryStruct{
+				data: data,
+				tile: tile,
+			}
+
+			elem = this.order.PushFront(&entry)
+			this.entries[tile] = elem
+
+			/*
+			 * Evict least recently used tiles.
+			 */
+			for this.order.Len() > this.capacity {
+				oldest := this.order.Back()
+				oldestEntry := oldest.Value.(*cacheEntryStruct)
+				this.order.Remove(oldest)
+				delete(this.entries, oldestEntry.tile)
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Remove all tiles from the cache and start a new generation.
+ */
+func (this *cacheStruct) clear() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.entries = make(map[tileStruct]*list.Element)
+	this.order.Init()
+	this.generation++
+}
+
+/*
+ * Create a cache holding at most a certain number of tiles.
+ */
+func createCache(capacity int) *cacheStruct {
+
+	/*
+	 * Create cache data structure.
+	 */
+	cache := cacheStruct{
+		capacity: capacity,
+		entries:  make(map[tileStruct]*list.Element),
+		order:    list.New(),
+	}
+
+	return &cache
+}
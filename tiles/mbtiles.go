@@ -0,0 +1,177 @@
+package tiles
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+/*
+ * Constants describing MBTiles databases.
+ */
+const (
+	MBTILES_FORMAT  = "png"
+	MBTILES_TYPE    = "overlay"
+	MBTILES_VERSION = "1.0"
+)
+
+/*
+ * Statements creating and accessing an MBTiles database.
+ */
+const (
+	mbtilesCreateMetadata = "CREATE TABLE IF NOT EXISTS metadata (name TEXT, value TEXT)"
+	mbtilesCreateTiles    = "CREATE TABLE IF NOT EXISTS tiles (zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_data BLOB)"
+	mbtilesCreateIndex    = "CREATE UNIQUE INDEX IF NOT EXISTS tile_index ON tiles (zoom_level, tile_column, tile_row)"
+	mbtilesDeleteMetadata = "DELETE FROM metadata WHERE name = ?"
+	mbtilesInsertMetadata = "INSERT INTO metadata (name, value) VALUES (?, ?)"
+	mbtilesHasTile        = "SELECT COUNT(*) FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?"
+	mbtilesPutTile        = "INSERT OR REPLACE INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES (?, ?, ?, ?)"
+)
+
+/*
+ * Data structure representing a store writing tiles into an MBTiles
+ * database.
+ */
+type mbtilesStoreStruct struct {
+	db    *sql.DB
+	mutex sync.Mutex
+}
+
+/*
+ * Returns the row of a tile in an MBTiles database, which counts rows from
+ * the bottom as in the TMS scheme.
+ */
+func mbtilesRow(z uint32, y uint32) uint64 {
+	n := uint64(1) << z
+	return n - 1 - uint64(y)
+}
+
+/*
+ * Returns whether a tile is stored in the database.
+ */
+func (this *mbtilesStoreStruct) Has(z uint32, x uint32, y uint32) (bool, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	row := mbtilesRow(z, y)
+	count := int64(0)
+	result := this.db.QueryRow(mbtilesHasTile, z, x, row)
+	err := result.Scan(&count)
+
+	/*
+	 * Check if tile could be looked up.
+	 */
+	if err != nil {
+		return false, fmt.Errorf("Failed to look up tile %d/%d/%d: %s", z, x, y, err.Error())
+	} else {
+		return count > 0, nil
+	}
+
+}
+
+/*
+ * Write a tile into the database, replacing any previous version.
+ */
+func (this *mbtilesStoreStruct) Put(z uint32, x uint32, y uint32, data []byte) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	row := mbtilesRow(z, y)
+	_, err := this.db.Exec(mbtilesPutTile, z, x, row, data)
+
+	/*
+	 * Check if tile could be stored.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to store tile %d/%d/%d: %s", z, x, y, err.Error())
+	} else {
+		return nil
+	}
+
+}
+
+/*
+ * Create a store, which writes tiles into an MBTiles database, e. g. to
+ * publish a tile set as a single file.
+ *
+ * The database must be opened by the caller using a SQLite driver of its
+ * choice. Tables are created if they do not exist and the metadata is set to
+ * the name of the tile set and to describe PNG tiles. Access to the database
+ * is serialized, since SQLite does not support concurrent writers.
+ */
+func MBTiles(db *sql.DB, name string) (Store, error) {
+
+	/*
+	 * Verify that database is non-nil.
+	 */
+	if db == nil {
+		return nil, fmt.Errorf("%s", "Database must not be nil.")
+	} else {
+
+		/*
+		 * Statements creating the tables.
+		 */
+		statements := []string{
+			mbtilesCreateMetadata,
+			mbtilesCreateTiles,
+			mbtilesCreateIndex,
+		}
+
+		/*
+		 * Execute each statement.
+		 */
+		for _, statement := range statements {
+			_, err := db.Exec(statement)
+
+			/*
+			 * Check if statement could be executed.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Failed to create MBTiles tables: %s", err.Error())
+			}
+
+		}
+
+		/*
+		 * The metadata of the tile set.
+		 */
+		metadata := [][]string{
+			[]string{"name", name},
+			[]string{"format", MBTILES_FORMAT},
+			[]string{"type", MBTILES_TYPE},
+			[]string{"version", MBTILES_VERSION},
+		}
+
+		/*
+		 * Replace each metadata entry.
+		 */
+		for _, entry := range metadata {
+			key := entry[0]
+			value := entry[1]
+			_, err := db.Exec(mbtilesDeleteMetadata, key)
+
+			/*
+			 * Insert entry if old entry could be removed.
+			 */
+			if err == nil {
+				_, err = db.Exec(mbtilesInsertMetadata, key, value)
+			}
+
+			/*
+			 * Check if metadata could be written.
+			 */
+			if err != nil {
+				return nil, fmt.Errorf("Failed to write MBTiles metadata: %s", err.Error())
+			}
+
+		}
+
+		/*
+		 * Create MBTiles store.
+		 */
+		store := mbtilesStoreStruct{
+			db: db,
+		}
+
+		return &store, nil
+	}
+
+}
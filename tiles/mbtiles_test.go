@@ -0,0 +1,444 @@
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/scene"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+/*
+ * Data structure representing the tables of a database kept in memory,
+ * which understands the statements issued by the MBTiles store.
+ */
+type memoryDatabaseStruct struct {
+	metadata map[string]string
+	mutex    sync.Mutex
+	tables   int
+	tiles    map[[3]int64][]byte
+}
+
+/*
+ * Data structure representing a connection to a database kept in memory.
+ */
+type memoryConnStruct struct {
+	db *memoryDatabaseStruct
+}
+
+/*
+ * Data structure representing a prepared statement.
+ */
+type memoryStmtStruct struct {
+	db    *memoryDatabaseStruct
+	query string
+}
+
+/*
+ * Data structure representing the result of a query, which consists of a
+ * single value.
+ */
+type memoryRowsStruct struct {
+	done  bool
+	value int64
+}
+
+/*
+ * Data structure representing a driver, which opens databases kept in memory
+ * by name.
+ */
+type memoryDriverStruct struct {
+	databases map[string]*memoryDatabaseStruct
+	mutex     sync.Mutex
+}
+
+/*
+ * The driver used to test MBTiles stores.
+ */
+var memoryDriver = &memoryDriverStruct{databases: map[string]*memoryDatabaseStruct{}}
+
+/*
+ * Register the driver.
+ */
+func init() {
+	sql.Register("tiles-memory", memoryDriver)
+}
+
+/*
+ * Open a connection to a database, creating it if it does not exist.
+ */
+func (this *memoryDriverStruct) Open(name string) (driver.Conn, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	db, ok := this.databases[name]
+
+	/*
+	 * Create database if needed.
+	 */
+	if !ok {
+		db = &memoryDatabaseStruct{metadata: map[string]string{}, tiles: map[[3]int64][]byte{}}
+		this.databases[name] = db
+	}
+
+	return &memoryConnStruct{db: db}, nil
+}
+
+/*
+ * Prepare a statement.
+ */
+func (this *memoryConnStruct) Prepare(query string) (driver.Stmt, error) {
+	return &memoryStmtStruct{db: this.db, query: query}, nil
+}
+
+/*
+ * Close the connection.
+ */
+func (this *memoryConnStruct) Close() error {
+	return nil
+}
+
+/*
+ * Transactions are not supported.
+ */
+func (this *memoryConnStruct) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("%s", "Transactions are not supported.")
+}
+
+/*
+ * Close the statement.
+ */
+func (this *memoryStmtStruct) Close() error {
+	return nil
+}
+
+/*
+ * The number of arguments is not checked.
+ */
+func (this *memoryStmtStruct) NumInput() int {
+	return -1
+}
+
+/*
+ * Execute a statement, which does not return rows.
+ */
+func (this *memoryStmtStruct) Exec(args []driver.Value) (driver.Result, error) {
+	db := this.db
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	/*
+	 * Decide on the statement.
+	 */
+	switch this.query {
+	case mbtilesCreateMetadata, mbtilesCreateTiles, mbtilesCreateIndex:
+		db.tables++
+	case mbtilesDeleteMetadata:
+		delete(db.metadata, args[0].(string))
+	case mbtilesInsertMetadata:
+		db.metadata[args[0].(string)] = args[1].(string)
+	case mbtilesPutTile:
+		key := [3]int64{args[0].(int64), args[1].(int64), args[2].(int64)}
+		db.tiles[key] = append([]byte{}, args[3].([]byte)...)
+	default:
+		return nil, fmt.Errorf("Unsupported statement: %s", this.query)
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+/*
+ * Execute a query, which returns rows.
+ */
+func (this *memoryStmtStruct) Query(args []driver.Value) (driver.Rows, error) {
+	db := this.db
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	/*
+	 * Only tiles are looked up.
+	 */
+	if this.query != mbtilesHasTile {
+		return nil, fmt.Errorf("Unsupported query: %s", this.query)
+	} else {
+		key := [3]int64{args[0].(int64), args[1].(int64), args[2].(int64)}
+		_, ok := db.tiles[key]
+		rows := memoryRowsStruct{}
+
+		/*
+		 * Count the tile if it exists.
+		 */
+		if ok {
+			rows.value = 1
+		}
+
+		return &rows, nil
+	}
+
+}
+
+/*
+ * Returns the names of the columns.
+ */
+func (this *memoryRowsStruct) Columns() []string {
+	return []string{"COUNT(*)"}
+}
+
+/*
+ * Close the rows.
+ */
+func (this *memoryRowsStruct) Close() error {
+	return nil
+}
+
+/*
+ * Return the single row.
+ */
+func (this *memoryRowsStruct) Next(dest []driver.Value) error {
+
+	/*
+	 * Check if row was already returned.
+	 */
+	if this.done {
+		return io.EOF
+	} else {
+		this.done = true
+		dest[0] = this.value
+		return nil
+	}
+
+}
+
+/*
+ * Open a database kept in memory.
+ */
+func openMemoryDatabase(t *testing.T) (*sql.DB, *memoryDatabaseStruct) {
+	name := t.Name()
+	db, err := sql.Open("tiles-memory", name)
+
+	/*
+	 * Check if database could be opened.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	memoryDriver.Open(name)
+	memoryDriver.mutex.Lock()
+	defer memoryDriver.mutex.Unlock()
+	return db, memoryDriver.databases[name]
+}
+
+/*
+ * Store tiles in an MBTiles database and look them up again.
+ */
+func TestMBTiles(t *testing.T) {
+	db, memory := openMemoryDatabase(t)
+	defer db.Close()
+	store, err := MBTiles(db, "Test")
+
+	/*
+	 * Check if store could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	_, err = MBTiles(db, "Renamed")
+
+	/*
+	 * Check if store could be created again.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	/*
+	 * The metadata expected.
+	 */
+	metadata := map[string]string{
+		"name":    "Renamed",
+		"format":  MBTILES_FORMAT,
+		"type":    MBTILES_TYPE,
+		"version": MBTILES_VERSION,
+	}
+
+	/*
+	 * Check the metadata.
+	 */
+	if fmt.Sprint(memory.metadata) != fmt.Sprint(metadata) {
+		t.Errorf("Metadata is %v, expected %v.", memory.metadata, metadata)
+	}
+
+	data := []byte{1, 2, 3}
+	err = store.Put(3, 5, 1, data)
+
+	/*
+	 * Check if tile could be stored.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	has, err := store.Has(3, 5, 1)
+	hasOther, errOther := store.Has(3, 5, 6)
+	stored, ok := memory.tiles[[3]int64{3, 5, 6}]
+
+	/*
+	 * Rows are counted from the bottom.
+	 */
+	if (err != nil) || (errOther != nil) {
+		t.Errorf("%s", "Failed to look up tiles.")
+	} else if !has {
+		t.Errorf("%s", "Stored tile was not found.")
+	} else if hasOther {
+		t.Errorf("%s", "Tile in flipped row was found.")
+	} else if !ok || !bytes.Equal(stored, data) {
+		t.Errorf("%s", "Tile is not stored in TMS row 6.")
+	}
+
+	_, err = MBTiles(nil, "Test")
+
+	/*
+	 * Check if a nil database was rejected.
+	 */
+	if err == nil {
+		t.Errorf("%s", "Expected an error for a nil database.")
+	}
+
+}
+
+/*
+ * Generate a pyramid into each kind of store and compare the stored tiles
+ * with rendered ones.
+ */
+func TestGenerate(t *testing.T) {
+	extent := coordinates.CreateCartesianBounds(coordinates.CreateCartesian(0.0, 0.0), coordinates.CreateCartesian(4.0, 4.0))
+	scn := scene.Create(8, 8, 0.0, 4.0, 0.0, 4.0)
+
+	/*
+	 * Points in the upper left and lower right quarter.
+	 */
+	points := []coordinates.Cartesian{
+		coordinates.CreateCartesian(0.5, 3.5),
+		coordinates.CreateCartesian(0.6, 3.4),
+		coordinates.CreateCartesian(3.5, 0.5),
+	}
+
+	scn.Aggregate(points)
+	r, err := Create([]scene.Scene{scn}, color.DefaultMapping(), WithExtent(extent), WithTileSize(16))
+
+	/*
+	 * Check if renderer could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	db, memory := openMemoryDatabase(t)
+	defer db.Close()
+	mbtiles, err := MBTiles(db, "Test")
+
+	/*
+	 * Check if store could be created.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	path := t.TempDir()
+	directory := DirectoryStore(path)
+
+	/*
+	 * Generate the pyramid into each store.
+	 */
+	for _, store := range []Store{mbtiles, directory} {
+		err = Generate(context.Background(), r, store, 0, 1, WithWorkers(2))
+
+		/*
+		 * Check if pyramid could be generated.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+	}
+
+	/*
+	 * The tiles holding data.
+	 */
+	tiles := [][3]uint32{
+		[3]uint32{0, 0, 0},
+		[3]uint32{1, 0, 0},
+		[3]uint32{1, 1, 1},
+	}
+
+	/*
+	 * Compare each tile.
+	 */
+	for _, tile := range tiles {
+		z, x, y := tile[0], tile[1], tile[2]
+		expected, err := r.Render(z, x, y)
+
+		/*
+		 * Check if tile could be rendered.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		key := [3]int64{int64(z), int64(x), int64(mbtilesRow(z, y))}
+		stored := memory.tiles[key]
+		file, err := ioutil.ReadFile(filepath.Join(path, fmt.Sprint(z), fmt.Sprint(x), fmt.Sprint(y)+".png"))
+
+		/*
+		 * Check if tile was stored.
+		 */
+		if !bytes.Equal(stored, expected) {
+			t.Errorf("Tile %d/%d/%d in MBTiles does not match.", z, x, y)
+		} else if err != nil {
+			t.Errorf("%s", err.Error())
+		} else if !bytes.Equal(file, expected) {
+			t.Errorf("Tile %d/%d/%d in directory does not match.", z, x, y)
+		}
+
+		img, err := png.Decode(bytes.NewReader(stored))
+
+		/*
+		 * Check if tile is a PNG image of the configured size.
+		 */
+		if err != nil {
+			t.Errorf("%s", err.Error())
+		} else if bounds := img.Bounds(); (bounds.Dx() != 16) || (bounds.Dy() != 16) {
+			t.Errorf("Tile %d/%d/%d is %v, expected 16x16.", z, x, y, bounds)
+		}
+
+	}
+
+	/*
+	 * Empty tiles are not stored.
+	 */
+	if len(memory.tiles) != len(tiles) {
+		t.Errorf("Database holds %d tiles, expected %d.", len(memory.tiles), len(tiles))
+	}
+
+	has, err := directory.Has(1, 1, 0)
+
+	/*
+	 * Check if empty tile is absent from the directory.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if has {
+		t.Errorf("%s", "Empty tile was written into the directory.")
+	}
+
+}
@@ -0,0 +1,322 @@
+package tiles
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+/*
+ * A store holds the tiles of a pyramid, e. g. in a directory tree or an
+ * MBTiles database.
+ *
+ * Has returns whether a tile was already stored, so that the generation of
+ * a pyramid can be resumed, while Put stores a tile encoded as PNG.
+ */
+type Store interface {
+	Has(z uint32, x uint32, y uint32) (bool, error)
+	Put(z uint32, x uint32, y uint32, data []byte) error
+}
+
+/*
+ * Data structure representing a store writing tiles into a directory tree.
+ */
+type directoryStoreStruct struct {
+	path string
+}
+
+/*
+ * Returns the path of the file holding a tile.
+ */
+func (this *directoryStoreStruct) tilePath(z uint32, x uint32, y uint32) string {
+	zString := strconv.FormatUint(uint64(z), 10)
+	xString := strconv.FormatUint(uint64(x), 10)
+	yString := strconv.FormatUint(uint64(y), 10)
+	return filepath.Join(this.path, zString, xString, yString+".png")
+}
+
+/*
+ * Returns whether the file holding a tile exists.
+ */
+func (this *directoryStoreStruct) Has(z uint32, x uint32, y uint32) (bool, error) {
+	path := this.tilePath(z, x, y)
+	_, err := os.Stat(path)
+
+	/*
+	 * Check if file exists.
+	 */
+	if err == nil {
+		return true, nil
+	} else if os.IsNotExist(err) {
+		return false, nil
+	} else {
+		return false, err
+	}
+
+}
+
+/*
+ * Write a tile into its file, creating directories as needed.
+ *
+ * The tile is written to a temporary file, which is then renamed, so that
+ * an interrupted run never leaves a partially written tile behind.
+ */
+func (this *directoryStoreStruct) Put(z uint32, x uint32, y uint32, data []byte) error {
+	path := this.tilePath(z, x, y)
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0755)
+
+	/*
+	 * Check if directory could be created.
+	 */
+	if err != nil {
+		return err
+	} else {
+		fd, err := ioutil.TempFile(dir, ".tile-*")
+
+		/*
+		 * Check if temporary file could be created.
+		 */
+		if err != nil {
+			return err
+		} else {
+			tempPath := fd.Name()
+			_, err = fd.Write(data)
+			errClose := fd.Close()
+
+			/*
+			 * Report the first error.
+			 */
+			if err == nil {
+				err = errClose
+			}
+
+			/*
+			 * Make the tile readable like other files.
+			 */
+			if err == nil {
+				err = os.Chmod(tempPath, 0644)
+			}
+
+			/*
+			 * Move file into place.
+			 */
+			if err == nil {
+				err = os.Rename(tempPath, path)
+			}
+
+			/*
+			 * Remove temporary file on failure.
+			 */
+			if err != nil {
+				os.Remove(tempPath)
+			}
+
+			return err
+		}
+
+	}
+
+}
+
+/*
+ * Create a store, which writes each tile into a file named z/x/y.png below
+ * a directory, as expected by most web maps.
+ */
+func DirectoryStore(path string) Store {
+
+	/*
+	 * Create directory store.
+	 */
+	store := directoryStoreStruct{
+		path: path,
+	}
+
+	return &store
+}
+
+/*
+ * Data structure representing a tile to be generated.
+ */
+type tileStruct struct {
+	x uint32
+	y uint32
+	z uint32
+}
+
+/*
+ * Render all tiles of a pyramid from minZoom to maxZoom, which contain data,
+ * and put them into a store, e. g. to publish a static tile set on any web
+ * server.
+ *
+ * Tiles are rendered by several workers in parallel. Tiles which are already
+ * stored are skipped, so that an interrupted run can be resumed by calling
+ * Generate again. Empty tiles are not stored, since web maps treat missing
+ * tiles as empty. Returns the first error encountered or the error of the
+ * context, if it is cancelled.
+ */
+func Generate(ctx context.Context, r Renderer, store Store, minZoom uint32, maxZoom uint32, options ...Option) error {
+	config := configure(options)
+	workers := config.workers
+
+	/*
+	 * Use at least one worker.
+	 */
+	if workers < 1 {
+		workers = 1
+	}
+
+	/*
+	 * Verify zoom levels.
+	 */
+	if minZoom > maxZoom {
+		return fmt.Errorf("Minimum zoom level %d exceeds maximum zoom level %d.", minZoom, maxZoom)
+	} else if maxZoom > MAX_ZOOM {
+		return fmt.Errorf("Maximum zoom level %d exceeds %d.", maxZoom, MAX_ZOOM)
+	} else {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		jobs := make(chan tileStruct)
+		firstErr := error(nil)
+		mutex := sync.Mutex{}
+		wg := sync.WaitGroup{}
+
+		/*
+		 * Remember the first error and stop all workers.
+		 */
+		fail := func(err error) {
+			mutex.Lock()
+
+			/*
+			 * Check if this is the first error.
+			 */
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			mutex.Unlock()
+			cancel()
+		}
+
+		/*
+		 * Render and store one tile.
+		 */
+		process := func(tile tileStruct) error {
+			z := tile.z
+			x := tile.x
+			y := tile.y
+			has, err := store.Has(z, x, y)
+
+			/*
+			 * Skip tiles which are already stored.
+			 */
+			if (err != nil) || has {
+				return err
+			} else {
+				data, err := r.Render(z, x, y)
+
+				/*
+				 * Only store tiles containing data.
+				 */
+				if (err != nil) || (data == nil) {
+					return err
+				} else {
+					return store.Put(z, x, y, data)
+				}
+
+			}
+
+		}
+
+		/*
+		 * Start workers.
+		 */
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+
+			/*
+			 * Process tiles until there are no more jobs.
+			 */
+			go func() {
+
+				/*
+				 * Process each tile.
+				 */
+				for tile := range jobs {
+					err := process(tile)
+
+					/*
+					 * Check if tile could be processed.
+					 */
+					if err != nil {
+						err = fmt.Errorf("Failed to generate tile %d/%d/%d: %s", tile.z, tile.x, tile.y, err.Error())
+						fail(err)
+					}
+
+				}
+
+				wg.Done()
+			}()
+
+		}
+
+		done := ctx.Done()
+
+		/*
+		 * Enumerate the tiles of each zoom level.
+		 */
+		for z := minZoom; (z <= maxZoom) && (ctx.Err() == nil); z++ {
+			rect := r.Tiles(z)
+
+			/*
+			 * Enumerate each row.
+			 */
+			for y := rect.Min.Y; (y < rect.Max.Y) && (ctx.Err() == nil); y++ {
+
+				/*
+				 * Enumerate each tile of the row.
+				 */
+				for x := rect.Min.X; x < rect.Max.X; x++ {
+
+					/*
+					 * The tile to generate.
+					 */
+					tile := tileStruct{
+						x: uint32(x),
+						y: uint32(y),
+						z: z,
+					}
+
+					/*
+					 * Hand tile to a worker unless cancelled.
+					 */
+					select {
+					case jobs <- tile:
+					case <-done:
+					}
+
+				}
+
+			}
+
+		}
+
+		close(jobs)
+		wg.Wait()
+
+		/*
+		 * Report errors of workers before cancellation.
+		 */
+		if firstErr != nil {
+			return firstErr
+		} else {
+			return ctx.Err()
+		}
+
+	}
+
+}
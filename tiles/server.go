@@ -0,0 +1,311 @@
+package tiles
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/scene"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+ * A server serves tiles rendered on demand from one or more scenes over
+ * HTTP, e. g. to mount a live heatmap into a web application.
+ *
+ * Tiles are requested using paths ending in {z}/{x}/{y}.png, so that the
+ * server may be mounted below any prefix, e. g. /tiles/. SetMapping changes
+ * the color mapping, while Update replaces the scenes, e. g. with a new
+ * snapshot of a live scene. Both discard all cached tiles, as does
+ * Invalidate, which should be called after the scenes have been modified in
+ * place.
+ */
+type Server interface {
+	Invalidate()
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+	SetMapping(mapping color.Mapping) error
+	Update(scenes []scene.Scene) error
+}
+
+/*
+ * Data structure representing a tile server.
+ */
+type serverStruct struct {
+	cache    *cacheStruct
+	config   configStruct
+	mapping  color.Mapping
+	mutex    sync.RWMutex
+	options  []Option
+	renderer Renderer
+	scenes   []scene.Scene
+}
+
+/*
+ * Parse the zoom level, column and row of a tile from the last three
+ * elements of a path.
+ */
+func parseTilePath(path string) (tileStruct, error) {
+	elements := strings.Split(path, "/")
+	numElements := len(elements)
+
+	/*
+	 * Verify that path has enough elements.
+	 */
+	if numElements < 3 {
+		return tileStruct{}, fmt.Errorf("Path '%s' does not denote a tile.", path)
+	} else {
+		zString := elements[numElements-3]
+		xString := elements[numElements-2]
+		yString := elements[numElements-1]
+
+		/*
+		 * Verify that tile is requested as PNG.
+		 */
+		if !strings.HasSuffix(yString, ".png") {
+			return tileStruct{}, fmt.Errorf("Path '%s' does not denote a PNG tile.", path)
+		} else {
+			yString = strings.TrimSuffix(yString, ".png")
+			z, errZ := strconv.ParseUint(zString, 10, 32)
+			x, errX := strconv.ParseUint(xString, 10, 32)
+			y, errY := strconv.ParseUint(yString, 10, 32)
+
+			/*
+			 * Check if coordinates could be parsed.
+			 */
+			if (errZ != nil) || (errX != nil) || (errY != nil) {
+				return tileStruct{}, fmt.Errorf("Path '%s' contains invalid tile coordinates.", path)
+			} else if (z > MAX_ZOOM) || (x >= (uint64(1) << z)) || (y >= (uint64(1) << z)) {
+				return tileStruct{}, fmt.Errorf("Tile %d/%d/%d does not exist.", z, x, y)
+			} else {
+
+				/*
+				 * The requested tile.
+				 */
+				tile := tileStruct{
+					x: uint32(x),
+					y: uint32(y),
+					z: uint32(z),
+				}
+
+				return tile, nil
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Replace the renderer, e. g. after the scenes or the mapping changed, and
+ * discard all cached tiles.
+ *
+ * The cache is cleared after the renderer is replaced, so that tiles
+ * rendered by the previous renderer are not cached afterwards.
+ */
+func (this *serverStruct) replace(scenes []scene.Scene, mapping color.Mapping) error {
+	this.mutex.Lock()
+
+	/*
+	 * Keep the current scenes if none are given.
+	 */
+	if scenes == nil {
+		scenes = this.scenes
+	}
+
+	/*
+	 * Keep the current mapping if none is given.
+	 */
+	if mapping == nil {
+		mapping = this.mapping
+	}
+
+	r, err := Create(scenes, mapping, this.options...)
+
+	/*
+	 * Check if renderer could be created.
+	 */
+	if err != nil {
+		this.mutex.Unlock()
+		return err
+	} else {
+		scenesCopy := make([]scene.Scene, len(scenes))
+		copy(scenesCopy, scenes)
+		this.mapping = mapping
+		this.renderer = r
+		this.scenes = scenesCopy
+		this.mutex.Unlock()
+		this.cache.clear()
+		return nil
+	}
+
+}
+
+/*
+ * Discard all cached tiles, e. g. after the scenes were modified in place.
+ */
+func (this *serverStruct) Invalidate() {
+	this.replace(nil, nil)
+}
+
+/*
+ * Serve a tile.
+ *
+ * Tiles are encoded as PNG. Empty tiles are answered with status 204 (No
+ * Content), which web maps display as transparent, and tiles outside the
+ * valid range with status 404 (Not Found).
+ */
+func (this *serverStruct) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.Method
+
+	/*
+	 * Only GET and HEAD requests are supported.
+	 */
+	if (method != http.MethodGet) && (method != http.MethodHead) {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+	} else {
+		tile, err := parseTilePath(r.URL.Path)
+
+		/*
+		 * Check if tile could be parsed.
+		 */
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			data, ok, generation := this.cache.get(tile)
+
+			/*
+			 * Render tile if it is not cached.
+			 */
+			if !ok {
+				this.mutex.RLock()
+				renderer := this.renderer
+				this.mutex.RUnlock()
+				data, err = renderer.Render(tile.z, tile.x, tile.y)
+
+				/*
+				 * Cache tile if it could be rendered.
+				 */
+				if err == nil {
+					this.cache.put(tile, data, generation)
+				}
+
+			}
+
+			header := w.Header()
+			maxAge := this.config.maxAge
+
+			/*
+			 * Decide on the caching policy of clients.
+			 */
+			if maxAge > 0 {
+				seconds := int64(maxAge.Seconds())
+				header.Set("Cache-Control", fmt.Sprintf("max-age=%d", seconds))
+			} else {
+				header.Set("Cache-Control", "no-cache")
+			}
+
+			/*
+			 * Send the tile or report failure.
+			 */
+			if err != nil {
+				msg := fmt.Sprintf("Failed to render tile %d/%d/%d: %s", tile.z, tile.x, tile.y, err.Error())
+				http.Error(w, msg, http.StatusInternalServerError)
+			} else if data == nil {
+				w.WriteHeader(http.StatusNoContent)
+			} else {
+				length := strconv.Itoa(len(data))
+				header.Set("Content-Type", "image/png")
+				header.Set("Content-Length", length)
+				w.WriteHeader(http.StatusOK)
+
+				/*
+				 * Responses to HEAD requests have no body.
+				 */
+				if method != http.MethodHead {
+					w.Write(data)
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Change the color mapping and discard all cached tiles.
+ */
+func (this *serverStruct) SetMapping(mapping color.Mapping) error {
+
+	/*
+	 * Verify that mapping is non-nil.
+	 */
+	if mapping == nil {
+		return fmt.Errorf("%s", "Color mapping must not be nil when rendering tiles!")
+	} else {
+		return this.replace(nil, mapping)
+	}
+
+}
+
+/*
+ * Replace the scenes, e. g. with a new snapshot of a live scene, and discard
+ * all cached tiles.
+ */
+func (this *serverStruct) Update(scenes []scene.Scene) error {
+
+	/*
+	 * Verify that there are scenes.
+	 */
+	if len(scenes) == 0 {
+		return fmt.Errorf("%s", "Need at least one scene to render tiles.")
+	} else {
+		return this.replace(scenes, nil)
+	}
+
+}
+
+/*
+ * Create a tile server, which renders tiles from one or more scenes using a
+ * color mapping on demand and keeps the most recently used tiles in memory.
+ *
+ * Accepts the same options as Create, as well as WithCacheSize and
+ * WithMaxAge. Scenes must not be modified while tiles are served, unless
+ * this is safe for concurrent use. To serve a live scene, periodically pass
+ * a new snapshot to Update.
+ */
+func CreateServer(scenes []scene.Scene, mapping color.Mapping, options ...Option) (Server, error) {
+	config := configure(options)
+	r, err := Create(scenes, mapping, options...)
+
+	/*
+	 * Check if renderer could be created.
+	 */
+	if err != nil {
+		return nil, err
+	} else {
+		optionsCopy := make([]Option, len(options))
+		copy(optionsCopy, options)
+		scenesCopy := make([]scene.Scene, len(scenes))
+		copy(scenesCopy, scenes)
+
+		/*
+		 * Create tile server.
+		 */
+		s := serverStruct{
+			cache:    createCache(config.cacheSize),
+			config:   config,
+			mapping:  mapping,
+			options:  optionsCopy,
+			renderer: r,
+			scenes:   scenesCopy,
+		}
+
+		return &s, nil
+	}
+
+}
@@ -0,0 +1,517 @@
+package tiles
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/andrepxx/sydney/color"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/projection"
+	"github.com/andrepxx/sydney/scene"
+	"image"
+	"image/png"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+/*
+ * Constants describing tiles.
+ */
+const (
+	DEFAULT_CACHE_SIZE = 1024
+	DEFAULT_TILE_SIZE  = 256
+	MAX_ZOOM           = 30
+)
+
+/*
+ * Data structure representing the configuration of a renderer, a tile
+ * pyramid or a tile server.
+ */
+type configStruct struct {
+	cacheSize int
+	extent    coordinates.CartesianBounds
+	maxAge    time.Duration
+	paletted  bool
+	size      uint32
+	workers   int
+}
+
+/*
+ * A configuration option for a renderer, a tile pyramid or a tile server.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Set the number of tiles a tile server keeps in memory. A size of zero
+ * disables caching.
+ */
+func WithCacheSize(size int) Option {
+
+	/*
+	 * Set cache size.
+	 */
+	return func(config *configStruct) {
+		config.cacheSize = size
+	}
+
+}
+
+/*
+ * Set the region of the plane covered by the tile at zoom level zero.
+ *
+ * By default, this is the extent of projection.WebMercator(), i. e. scenes
+ * are expected in meters as in EPSG:3857. For scenes aggregated using the
+ * tile space of Web Mercator, pass the extent of
+ * projection.WebMercator(projection.WithTileSpace()).
+ */
+func WithExtent(extent coordinates.CartesianBounds) Option {
+
+	/*
+	 * Set extent.
+	 */
+	return func(config *configStruct) {
+		config.extent = extent
+	}
+
+}
+
+/*
+ * Allow clients of a tile server to cache tiles for a certain duration.
+ *
+ * By default, clients have to revalidate tiles on every use, since the
+ * scenes of a live heatmap may be updated at any time.
+ */
+func WithMaxAge(maxAge time.Duration) Option {
+
+	/*
+	 * Set maximum age.
+	 */
+	return func(config *configStruct) {
+		config.maxAge = maxAge
+	}
+
+}
+
+/*
+ * Encode tiles as 8-bit indexed PNG images with at most 256 colors, which
+ * are considerably smaller than true color images.
+ */
+func WithPaletted() Option {
+
+	/*
+	 * Enable paletted tiles.
+	 */
+	return func(config *configStruct) {
+		config.paletted = true
+	}
+
+}
+
+/*
+ * Set the width and height of tiles in pixels.
+ */
+func WithTileSize(size uint32) Option {
+
+	/*
+	 * Set tile size.
+	 */
+	return func(config *configStruct) {
+		config.size = size
+	}
+
+}
+
+/*
+ * Set the number of tiles of a pyramid rendered in parallel, which defaults
+ * to the number of CPUs.
+ */
+func WithWorkers(workers int) Option {
+
+	/*
+	 * Set number of workers.
+	 */
+	return func(config *configStruct) {
+		config.workers = workers
+	}
+
+}
+
+/*
+ * Apply options to the default configuration.
+ */
+func configure(options []Option) configStruct {
+	proj := projection.WebMercator()
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		cacheSize: DEFAULT_CACHE_SIZE,
+		extent:    proj.Extent(),
+		size:      DEFAULT_TILE_SIZE,
+		workers:   runtime.NumCPU(),
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	return config
+}
+
+/*
+ * Returns whether two bounding boxes share an area.
+ */
+func overlaps(a coordinates.CartesianBounds, b coordinates.CartesianBounds) bool {
+	intersection := a.Intersect(b)
+	size := intersection.Size()
+	return !intersection.IsEmpty() && (size.X() > 0.0) && (size.Y() > 0.0)
+}
+
+/*
+ * A renderer renders slippy map tiles, addressed by zoom level, column and
+ * row, from one or more scenes.
+ *
+ * Render returns a tile encoded as PNG or nil if it contains no data, while
+ * Tiles returns the range of tiles at a zoom level which may contain data.
+ */
+type Renderer interface {
+	Render(z uint32, x uint32, y uint32) ([]byte, error)
+	Tiles(z uint32) image.Rectangle
+}
+
+/*
+ * Data structure representing a tile renderer.
+ */
+type rendererStruct struct {
+	config  configStruct
+	mapping color.Mapping
+	mutex   sync.Mutex
+	scenes  []scene.Scene
+	stats   map[uint32]color.Statistics
+}
+
+/*
+ * Returns the region of the plane covered by a tile.
+ */
+func (this *rendererStruct) tileBounds(z uint32, x uint32, y uint32) coordinates.CartesianBounds {
+	extent := this.config.extent
+	min := extent.Min()
+	max := extent.Max()
+	n := float64(uint64(1) << z)
+	sizeX := (max.X() - min.X()) / n
+	sizeY := (max.Y() - min.Y()) / n
+	minX := min.X() + (float64(x) * sizeX)
+	maxY := max.Y() - (float64(y) * sizeY)
+	a := coordinates.CreateCartesian(minX, maxY-sizeY)
+	b := coordinates.CreateCartesian(minX+sizeX, maxY)
+	return coordinates.CreateCartesianBounds(a, b)
+}
+
+/*
+ * Returns the region of the plane covered by all scenes.
+ */
+func (this *rendererStruct) dataBounds() coordinates.CartesianBounds {
+	bounds := coordinates.EmptyCartesianBounds()
+
+	/*
+	 * Add the bounds of each scene.
+	 */
+	for _, scn := range this.scenes {
+		bounds = bounds.Union(scn.Bounds())
+	}
+
+	return bounds
+}
+
+/*
+ * Returns the statistics shared by all tiles of a zoom level, so that they
+ * share an identical color scale.
+ *
+ * The statistics are gathered from the scenes resampled to the resolution of
+ * the zoom level, but at most to their own resolution, and are cached.
+ */
+func (this *rendererStruct) levelStats(z uint32) (color.Statistics, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	stats, ok := this.stats[z]
+
+	/*
+	 * Gather statistics if they are not cached.
+	 */
+	if !ok {
+		extent := this.config.extent
+		size := extent.Size()
+		n := float64(uint64(1) << z)
+		pixels := n * float64(this.config.size)
+		pixelX := size.X() / pixels
+		pixelY := size.Y() / pixels
+
+		/*
+		 * Do not resample beyond the resolution of the scenes.
+		 */
+		for _, scn := range this.scenes {
+			width, height := scn.Dimensions()
+			bounds := scn.Bounds()
+			binSize := bounds.Size()
+			pixelX = math.Max(pixelX, binSize.X()/float64(width))
+			pixelY = math.Max(pixelY, binSize.Y()/float64(height))
+		}
+
+		bounds := this.dataBounds()
+		dataSize := bounds.Size()
+		width := uint32(math.Ceil(dataSize.X() / pixelX))
+		height := uint32(math.Ceil(dataSize.Y() / pixelY))
+		level := scene.CreateFromBounds(width, height, bounds)
+
+		/*
+		 * Add each scene to the level.
+		 */
+		for _, scn := range this.scenes {
+			err := scene.Resample(level, scn)
+
+			/*
+			 * Check if scene could be resampled.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+		}
+
+		stats = color.CreateStatistics()
+		counts := level.Counts()
+		stats.Add(counts)
+		this.stats[z] = stats
+	}
+
+	return stats, nil
+}
+
+/*
+ * Render a tile and encode it as PNG.
+ *
+ * Returns nil if the tile contains no data.
+ */
+func (this *rendererStruct) Render(z uint32, x uint32, y uint32) ([]byte, error) {
+
+	/*
+	 * Verify that tile exists.
+	 */
+	if (z > MAX_ZOOM) || (uint64(x) >= (uint64(1) << z)) || (uint64(y) >= (uint64(1) << z)) {
+		return nil, fmt.Errorf("Tile %d/%d/%d does not exist.", z, x, y)
+	} else {
+		bounds := this.tileBounds(z, x, y)
+		size := this.config.size
+		tile := scene.Scene(nil)
+
+		/*
+		 * Add each scene overlapping the tile.
+		 */
+		for _, scn := range this.scenes {
+
+			/*
+			 * Skip scenes which do not overlap the tile.
+			 */
+			if overlaps(bounds, scn.Bounds()) {
+
+				/*
+				 * Create tile when it is needed.
+				 */
+				if tile == nil {
+					tile = scene.CreateFromBounds(size, size, bounds)
+				}
+
+				err := scene.Resample(tile, scn)
+
+				/*
+				 * Check if scene could be resampled.
+				 */
+				if err != nil {
+					return nil, err
+				}
+
+			}
+
+		}
+
+		empty := true
+
+		/*
+		 * Check if tile contains any data.
+		 */
+		if tile != nil {
+
+			/*
+			 * Look for a non-empty bin.
+			 */
+			for _, count := range tile.Counts() {
+
+				/*
+				 * Check if bin is non-empty.
+				 */
+				if count > 0 {
+					empty = false
+					break
+				}
+
+			}
+
+		}
+
+		/*
+		 * Empty tiles are not rendered.
+		 */
+		if empty {
+			return nil, nil
+		} else {
+			stats, err := this.levelStats(z)
+
+			/*
+			 * Check if statistics could be gathered.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+			mapping := color.Shared(this.mapping, stats)
+			img := image.Image(nil)
+
+			/*
+			 * Decide on the kind of image.
+			 */
+			if this.config.paletted {
+				img, err = tile.RenderPaletted(mapping)
+			} else {
+				img, err = tile.Render(mapping)
+			}
+
+			/*
+			 * Check if tile could be rendered.
+			 */
+			if err != nil {
+				return nil, err
+			}
+
+			buf := bytes.Buffer{}
+			enc := png.Encoder{}
+			err = enc.Encode(&buf, img)
+
+			/*
+			 * Check if tile could be encoded.
+			 */
+			if err != nil {
+				return nil, err
+			} else {
+				return buf.Bytes(), nil
+			}
+
+		}
+
+	}
+
+}
+
+/*
+ * Returns the range of tiles at a zoom level, which overlap any of the
+ * scenes and may therefore contain data.
+ */
+func (this *rendererStruct) Tiles(z uint32) image.Rectangle {
+	bounds := this.dataBounds()
+	extent := this.config.extent
+
+	/*
+	 * Only zoom levels up to the maximum are supported.
+	 */
+	if (z > MAX_ZOOM) || !overlaps(bounds, extent) {
+		return image.Rectangle{}
+	} else {
+		n := float64(uint64(1) << z)
+		min := extent.Min()
+		max := extent.Max()
+		size := extent.Size()
+		dataMin := bounds.Min()
+		dataMax := bounds.Max()
+		tileX := size.X() / n
+		tileY := size.Y() / n
+		minX := math.Floor((dataMin.X() - min.X()) / tileX)
+		maxX := math.Ceil((dataMax.X() - min.X()) / tileX)
+		minY := math.Floor((max.Y() - dataMax.Y()) / tileY)
+		maxY := math.Ceil((max.Y() - dataMin.Y()) / tileY)
+		minX = math.Max(minX, 0.0)
+		minY = math.Max(minY, 0.0)
+		maxX = math.Min(maxX, n)
+		maxY = math.Min(maxY, n)
+		return image.Rect(int(minX), int(minY), int(maxX), int(maxY))
+	}
+
+}
+
+/*
+ * Create a renderer, which renders tiles from one or more scenes using a
+ * color mapping.
+ *
+ * All scenes must be aggregated in the coordinate system of the extent, by
+ * default Web Mercator in meters. The counts of overlapping scenes are
+ * added. All tiles of a zoom level share an identical color scale, if the
+ * mapping implements color.ChunkedMapping. Scenes must not be modified while
+ * tiles are rendered.
+ */
+func Create(scenes []scene.Scene, mapping color.Mapping, options ...Option) (Renderer, error) {
+	config := configure(options)
+	numScenes := len(scenes)
+
+	/*
+	 * Verify configuration.
+	 */
+	if numScenes == 0 {
+		return nil, fmt.Errorf("%s", "Need at least one scene to render tiles.")
+	} else if mapping == nil {
+		return nil, fmt.Errorf("%s", "Color mapping must not be nil when rendering tiles!")
+	} else if config.size == 0 {
+		return nil, fmt.Errorf("%s", "Tile size must be positive.")
+	} else if !overlaps(config.extent, config.extent) {
+		return nil, fmt.Errorf("%s", "Extent must not be empty.")
+	} else {
+		scenesCopy := make([]scene.Scene, numScenes)
+
+		/*
+		 * Copy each scene.
+		 */
+		for i, scn := range scenes {
+
+			/*
+			 * Verify that scene is non-nil.
+			 */
+			if scn == nil {
+				return nil, fmt.Errorf("Scene %d must not be nil.", i)
+			}
+
+			scenesCopy[i] = scn
+		}
+
+		/*
+		 * Create tile renderer.
+		 */
+		r := rendererStruct{
+			config:  config,
+			mapping: mapping,
+			scenes:  scenesCopy,
+			stats:   make(map[uint32]color.Statistics),
+		}
+
+		return &r, nil
+	}
+
+}
@@ -0,0 +1,158 @@
+package track
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"time"
+)
+
+/*
+ * Data structure representing the segment between two subsequent points of
+ * a track along with quantities derived from them.
+ *
+ * Segments are immutable.
+ */
+type Segment struct {
+	ascent   float64
+	distance float64
+	duration time.Duration
+	midpoint coordinates.Geographic
+}
+
+/*
+ * Returns the change in elevation along this segment in meters, which is
+ * negative for descents.
+ *
+ * If the elevation of either point is unknown, this is NaN.
+ */
+func (this *Segment) Ascent() float64 {
+	return this.ascent
+}
+
+/*
+ * Returns the rate of ascent along this segment in meters per second, which
+ * is negative for descents.
+ *
+ * If the elevation of either point is unknown or both points were recorded
+ * at the same time, this is NaN.
+ */
+func (this *Segment) AscentRate() float64 {
+	seconds := this.duration.Seconds()
+
+	/*
+	 * The rate is undefined for segments without duration.
+	 */
+	if seconds <= 0.0 {
+		return math.NaN()
+	} else {
+		return this.ascent / seconds
+	}
+
+}
+
+/*
+ * Returns the great-circle distance covered along this segment in meters.
+ */
+func (this *Segment) Distance() float64 {
+	return this.distance
+}
+
+/*
+ * Returns the time elapsed along this segment.
+ */
+func (this *Segment) Duration() time.Duration {
+	return this.duration
+}
+
+/*
+ * Returns the location halfway along this segment, which may be used to
+ * aggregate the derived quantities.
+ */
+func (this *Segment) Midpoint() coordinates.Geographic {
+	return this.midpoint
+}
+
+/*
+ * Returns the average speed along this segment in meters per second.
+ *
+ * If both points were recorded at the same time, this is NaN.
+ */
+func (this *Segment) Speed() float64 {
+	seconds := this.duration.Seconds()
+
+	/*
+	 * The speed is undefined for segments without duration.
+	 */
+	if seconds <= 0.0 {
+		return math.NaN()
+	} else {
+		return this.distance / seconds
+	}
+
+}
+
+/*
+ * Returns the total elevation gain and loss (both positive, in meters) along
+ * this track.
+ *
+ * Segments, for which the elevation of either point is unknown, do not
+ * contribute.
+ */
+func (this *trackStruct) ElevationGain() (float64, float64) {
+	points := this.points
+	numPoints := len(points)
+	gain := float64(0.0)
+	loss := float64(0.0)
+
+	/*
+	 * Sum up elevation changes along all segments.
+	 */
+	for i := 1; i < numPoints; i++ {
+		diff := points[i].elevation - points[i-1].elevation
+
+		/*
+		 * NaN compares false and is skipped.
+		 */
+		if diff > 0.0 {
+			gain += diff
+		} else if diff < 0.0 {
+			loss -= diff
+		}
+
+	}
+
+	return gain, loss
+}
+
+/*
+ * Returns the segments between subsequent points of this track, from which
+ * speeds and ascent rates are derived, e. g. to map the average speed per
+ * pixel.
+ */
+func (this *trackStruct) Segments() []Segment {
+	points := this.points
+	numPoints := len(points)
+	segments := []Segment{}
+
+	/*
+	 * Derive quantities for each pair of subsequent points.
+	 */
+	for i := 1; i < numPoints; i++ {
+		a := &points[i-1]
+		b := &points[i]
+
+		/*
+		 * Create segment.
+		 */
+		segment := Segment{
+			ascent:   b.elevation - a.elevation,
+			distance: coordinates.Distance(a.location, b.location),
+			duration: b.timestamp.Sub(a.timestamp),
+			midpoint: coordinates.Midpoint(a.location, b.location),
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
@@ -0,0 +1,139 @@
+package track
+
+import (
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"time"
+)
+
+/*
+ * Interpolate between two track points.
+ *
+ * The location is interpolated along the great circle, while time, elevation
+ * and speed are interpolated linearly. The fraction f ranges from zero (first
+ * point) to one (second point).
+ */
+func interpolatePoint(a *TrackPoint, b *TrackPoint, f float64) TrackPoint {
+	location := coordinates.Interpolate(a.location, b.location, f)
+	duration := b.timestamp.Sub(a.timestamp)
+	offset := time.Duration(f * float64(duration))
+	timestamp := a.timestamp.Add(offset)
+	elevation := a.elevation + (f * (b.elevation - a.elevation))
+	speed := a.speed + (f * (b.speed - a.speed))
+	return CreateTrackPoint(location, timestamp, elevation, speed)
+}
+
+/*
+ * Resample this track at a fixed distance step in meters, so that devices
+ * logging at different rates contribute equally to a density map.
+ *
+ * The resulting track starts at the first point of this track and contains
+ * a point after each step along the track, up to its end. Points in between
+ * are interpolated.
+ */
+func (this *trackStruct) ResampleDistance(step float64) (Track, error) {
+	points := this.points
+	numPoints := len(points)
+
+	/*
+	 * Check parameters.
+	 */
+	if !(step > 0.0) {
+		return nil, fmt.Errorf("%s", "Distance step must be positive.")
+	} else if numPoints == 0 {
+		return this, nil
+	} else {
+		result := []TrackPoint{points[0]}
+		next := step
+		travelled := float64(0.0)
+
+		/*
+		 * Walk along all segments of the track.
+		 */
+		for i := 1; i < numPoints; i++ {
+			a := &points[i-1]
+			b := &points[i]
+			length := coordinates.Distance(a.location, b.location)
+			end := travelled + length
+
+			/*
+			 * Insert all samples within this segment.
+			 */
+			for next <= end {
+				f := (next - travelled) / length
+				point := interpolatePoint(a, b, f)
+				result = append(result, point)
+				next += step
+			}
+
+			travelled = end
+		}
+
+		/*
+		 * Create track data structure.
+		 */
+		t := trackStruct{
+			points: result,
+		}
+
+		return &t, nil
+	}
+
+}
+
+/*
+ * Resample this track at a fixed time step, so that devices logging at
+ * different rates contribute equally to a density map.
+ *
+ * The resulting track starts at the first point of this track and contains
+ * a point after each step, up to its end. Points in between are
+ * interpolated.
+ */
+func (this *trackStruct) ResampleTime(step time.Duration) (Track, error) {
+	points := this.points
+	numPoints := len(points)
+
+	/*
+	 * Check parameters.
+	 */
+	if step <= 0 {
+		return nil, fmt.Errorf("%s", "Time step must be positive.")
+	} else if numPoints == 0 {
+		return this, nil
+	} else {
+		start := points[0].timestamp
+		result := []TrackPoint{points[0]}
+		next := start.Add(step)
+
+		/*
+		 * Walk along all segments of the track.
+		 */
+		for i := 1; i < numPoints; i++ {
+			a := &points[i-1]
+			b := &points[i]
+			duration := b.timestamp.Sub(a.timestamp)
+
+			/*
+			 * Insert all samples within this segment.
+			 */
+			for !next.After(b.timestamp) {
+				offset := next.Sub(a.timestamp)
+				f := float64(offset) / float64(duration)
+				point := interpolatePoint(a, b, f)
+				result = append(result, point)
+				next = next.Add(step)
+			}
+
+		}
+
+		/*
+		 * Create track data structure.
+		 */
+		t := trackStruct{
+			points: result,
+		}
+
+		return &t, nil
+	}
+
+}
@@ -0,0 +1,257 @@
+package track
+
+import (
+	"github.com/andrepxx/sydney/coordinates"
+	"math"
+	"sort"
+	"time"
+)
+
+/*
+ * Data structure representing a timestamped point along a track, optionally
+ * carrying an elevation (in meters) and a speed (in meters per second).
+ *
+ * Track points are immutable.
+ */
+type TrackPoint struct {
+	elevation float64
+	location  coordinates.Geographic
+	speed     float64
+	timestamp time.Time
+}
+
+/*
+ * A track is a series of track points, ordered by time.
+ */
+type Track interface {
+	ElevationGain() (float64, float64)
+	End() time.Time
+	Len() int
+	Locations() []coordinates.Geographic
+	Point(idx int) TrackPoint
+	Points() []TrackPoint
+	ResampleDistance(step float64) (Track, error)
+	ResampleTime(step time.Duration) (Track, error)
+	Segments() []Segment
+	Slice(start time.Time, end time.Time) Track
+	Start() time.Time
+}
+
+/*
+ * Data structure representing a track.
+ */
+type trackStruct struct {
+	points []TrackPoint
+}
+
+/*
+ * Returns the elevation of this track point in meters.
+ *
+ * If the elevation is unknown, this is NaN.
+ */
+func (this *TrackPoint) Elevation() float64 {
+	return this.elevation
+}
+
+/*
+ * Returns whether the elevation of this track point is known.
+ */
+func (this *TrackPoint) HasElevation() bool {
+	return !math.IsNaN(this.elevation)
+}
+
+/*
+ * Returns whether the speed of this track point is known.
+ */
+func (this *TrackPoint) HasSpeed() bool {
+	return !math.IsNaN(this.speed)
+}
+
+/*
+ * Returns the geographic location of this track point.
+ */
+func (this *TrackPoint) Location() coordinates.Geographic {
+	return this.location
+}
+
+/*
+ * Returns the speed at this track point in meters per second.
+ *
+ * If the speed is unknown, this is NaN.
+ */
+func (this *TrackPoint) Speed() float64 {
+	return this.speed
+}
+
+/*
+ * Returns the time at which this track point was recorded.
+ */
+func (this *TrackPoint) Time() time.Time {
+	return this.timestamp
+}
+
+/*
+ * Returns the time of the last point of this track or the zero time if the
+ * track is empty.
+ */
+func (this *trackStruct) End() time.Time {
+	points := this.points
+	numPoints := len(points)
+
+	/*
+	 * Check if track is empty.
+	 */
+	if numPoints == 0 {
+		return time.Time{}
+	} else {
+		return points[numPoints-1].timestamp
+	}
+
+}
+
+/*
+ * Returns the number of points of this track.
+ */
+func (this *trackStruct) Len() int {
+	return len(this.points)
+}
+
+/*
+ * Returns the locations of all points of this track, e. g. to project them.
+ */
+func (this *trackStruct) Locations() []coordinates.Geographic {
+	points := this.points
+	locations := make([]coordinates.Geographic, len(points))
+
+	/*
+	 * Extract the location of each point.
+	 */
+	for i, point := range points {
+		locations[i] = point.location
+	}
+
+	return locations
+}
+
+/*
+ * Returns a point of this track.
+ */
+func (this *trackStruct) Point(idx int) TrackPoint {
+	return this.points[idx]
+}
+
+/*
+ * Returns a copy of all points of this track.
+ */
+func (this *trackStruct) Points() []TrackPoint {
+	points := this.points
+	result := make([]TrackPoint, len(points))
+	copy(result, points)
+	return result
+}
+
+/*
+ * Returns the part of this track recorded at or after start, but before end.
+ *
+ * The points are shared with this track, which is fine since tracks are
+ * immutable.
+ */
+func (this *trackStruct) Slice(start time.Time, end time.Time) Track {
+	points := this.points
+	numPoints := len(points)
+
+	/*
+	 * Find the first point recorded at or after start.
+	 */
+	first := sort.Search(numPoints, func(i int) bool {
+		return !points[i].timestamp.Before(start)
+	})
+
+	/*
+	 * Find the first point recorded at or after end.
+	 */
+	last := sort.Search(numPoints, func(i int) bool {
+		return !points[i].timestamp.Before(end)
+	})
+
+	/*
+	 * An empty time range results in an empty track.
+	 */
+	if last < first {
+		last = first
+	}
+
+	/*
+	 * Create track data structure.
+	 */
+	t := trackStruct{
+		points: points[first:last:last],
+	}
+
+	return &t
+}
+
+/*
+ * Returns the time of the first point of this track or the zero time if the
+ * track is empty.
+ */
+func (this *trackStruct) Start() time.Time {
+	points := this.points
+
+	/*
+	 * Check if track is empty.
+	 */
+	if len(points) == 0 {
+		return time.Time{}
+	} else {
+		return points[0].timestamp
+	}
+
+}
+
+/*
+ * Creates an immutable data structure representing a track point.
+ *
+ * Pass NaN as elevation or speed if these are unknown.
+ */
+func CreateTrackPoint(location coordinates.Geographic, timestamp time.Time, elevation float64, speed float64) TrackPoint {
+
+	/*
+	 * Create track point.
+	 */
+	point := TrackPoint{
+		elevation: elevation,
+		location:  location,
+		speed:     speed,
+		timestamp: timestamp,
+	}
+
+	return point
+}
+
+/*
+ * Create a track from a series of track points.
+ *
+ * The points are copied and sorted by time, keeping the order of points
+ * recorded at the same time.
+ */
+func Create(points []TrackPoint) Track {
+	sorted := make([]TrackPoint, len(points))
+	copy(sorted, points)
+
+	/*
+	 * Sort points by time.
+	 */
+	sort.SliceStable(sorted, func(i int, j int) bool {
+		return sorted[i].timestamp.Before(sorted[j].timestamp)
+	})
+
+	/*
+	 * Create track data structure.
+	 */
+	t := trackStruct{
+		points: sorted,
+	}
+
+	return &t
+}
@@ -0,0 +1,762 @@
+package zarr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/andrepxx/sydney/scene"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ * Constants of the Zarr format.
+ */
+const (
+	ZARR_FORMAT         = 2
+	CONSOLIDATED_FORMAT = 1
+	DEFAULT_CHUNK_SIZE  = 256
+	BYTES_PER_VALUE     = 8
+)
+
+/*
+ * A store receives the objects making up a Zarr hierarchy, e. g. a
+ * directory on disk or a bucket of an object storage.
+ *
+ * Keys are paths relative to the root of the hierarchy, using slashes as
+ * separators.
+ */
+type Store interface {
+	Put(key string, data []byte) error
+}
+
+/*
+ * Data structure representing a store writing into a directory.
+ */
+type directoryStoreStruct struct {
+	path string
+}
+
+/*
+ * Write an object into a file below the directory, creating directories as
+ * needed.
+ */
+func (this *directoryStoreStruct) Put(key string, data []byte) error {
+	path := filepath.Join(this.path, filepath.FromSlash(key))
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0755)
+
+	/*
+	 * Check if directory could be created.
+	 */
+	if err != nil {
+		return err
+	} else {
+		return ioutil.WriteFile(path, data, 0644)
+	}
+
+}
+
+/*
+ * Create a store writing into a directory, which is created if it does not
+ * exist, e. g. "density.zarr".
+ */
+func DirectoryStore(path string) Store {
+
+	/*
+	 * Create store data structure.
+	 */
+	store := directoryStoreStruct{
+		path: path,
+	}
+
+	return &store
+}
+
+/*
+ * Data structure representing the compressor of an array as it is written.
+ */
+type compressorStruct struct {
+	ID    string `json:"id"`
+	Level int    `json:"level"`
+}
+
+/*
+ * Data structure representing the metadata of an array as it is written.
+ */
+type arrayMetadataStruct struct {
+	Chunks     []uint32          `json:"chunks"`
+	Compressor *compressorStruct `json:"compressor"`
+	DType      string            `json:"dtype"`
+	FillValue  interface{}       `json:"fill_value"`
+	Filters    interface{}       `json:"filters"`
+	Order      string            `json:"order"`
+	Shape      []uint32          `json:"shape"`
+	ZarrFormat int               `json:"zarr_format"`
+}
+
+/*
+ * Data structure representing the metadata of a group as it is written.
+ */
+type groupMetadataStruct struct {
+	ZarrFormat int `json:"zarr_format"`
+}
+
+/*
+ * Data structure representing consolidated metadata as it is written.
+ */
+type consolidatedStruct struct {
+	Metadata map[string]interface{} `json:"metadata"`
+	Format   int                    `json:"zarr_consolidated_format"`
+}
+
+/*
+ * Data structure representing an array to be written.
+ *
+ * One-dimensional arrays have a height of one. Either counts or values are
+ * set.
+ */
+type arrayStruct struct {
+	attributes map[string]interface{}
+	counts     []uint64
+	dimensions []string
+	height     uint32
+	name       string
+	values     []float64
+	width      uint32
+}
+
+/*
+ * Data structure representing the configuration of a writer.
+ */
+type configStruct struct {
+	attributes map[string]interface{}
+	chunkSize  uint32
+	compressed bool
+	units      string
+	variables  []arrayStruct
+}
+
+/*
+ * A configuration option for a writer.
+ */
+type Option func(config *configStruct)
+
+/*
+ * Add a text attribute to the root group, e. g. "title" or "source".
+ */
+func WithAttribute(name string, value string) Option {
+
+	/*
+	 * Add attribute.
+	 */
+	return func(config *configStruct) {
+		config.attributes[name] = value
+	}
+
+}
+
+/*
+ * Set the width and height of the chunks of two-dimensional arrays in bins.
+ *
+ * The default is DEFAULT_CHUNK_SIZE. Chunks consisting only of fill values
+ * are not written.
+ */
+func WithChunkSize(size uint32) Option {
+
+	/*
+	 * Set chunk size.
+	 */
+	return func(config *configStruct) {
+		config.chunkSize = size
+	}
+
+}
+
+/*
+ * Enable or disable zlib compression of chunks. Chunks are compressed by
+ * default.
+ */
+func WithCompression(compressed bool) Option {
+
+	/*
+	 * Set compression.
+	 */
+	return func(config *configStruct) {
+		config.compressed = compressed
+	}
+
+}
+
+/*
+ * Set the units of the coordinate arrays, e. g. "m" for scenes aggregated
+ * using the Web Mercator projection.
+ */
+func WithCoordinateUnits(units string) Option {
+
+	/*
+	 * Set units.
+	 */
+	return func(config *configStruct) {
+		config.units = units
+	}
+
+}
+
+/*
+ * Write an additional grid of values alongside the counts, e. g. the mean
+ * speed per bin, which must hold one value per bin of the scene in the same
+ * order as the counts returned by scn.Counts(). Use NaN for bins without a
+ * value.
+ */
+func WithVariable(name string, values []float64) Option {
+
+	/*
+	 * Add variable.
+	 */
+	return func(config *configStruct) {
+
+		/*
+		 * Create array.
+		 */
+		variable := arrayStruct{
+			dimensions: []string{"y", "x"},
+			name:       name,
+			values:     values,
+		}
+
+		config.variables = append(config.variables, variable)
+	}
+
+}
+
+/*
+ * Returns the data type of an array.
+ */
+func (this *arrayStruct) dtype() string {
+
+	/*
+	 * Check if array holds counts.
+	 */
+	if this.counts != nil {
+		return "<u8"
+	} else {
+		return "<f8"
+	}
+
+}
+
+/*
+ * Returns the fill value of an array, i. e. zero for counts and NaN for
+ * floating-point values.
+ */
+func (this *arrayStruct) fillValue() interface{} {
+
+	/*
+	 * Check if array holds counts.
+	 */
+	if this.counts != nil {
+		return 0
+	} else {
+		return "NaN"
+	}
+
+}
+
+/*
+ * Encode a chunk of an array, padding it with fill values where it exceeds
+ * the array.
+ *
+ * Returns false if the chunk consists only of fill values.
+ */
+func (this *arrayStruct) encodeChunk(buf []byte, row uint32, col uint32, chunkHeight uint32, chunkWidth uint32) bool {
+	width := this.width
+	height := this.height
+	counts := this.counts
+	values := this.values
+	nan := math.Float64bits(math.NaN())
+	used := false
+
+	/*
+	 * Encode each row of the chunk.
+	 */
+	for y := uint32(0); y < chunkHeight; y++ {
+		globalY := row + y
+
+		/*
+		 * Encode each value of the row.
+		 */
+		for x := uint32(0); x < chunkWidth; x++ {
+			globalX := col + x
+			pos := BYTES_PER_VALUE * ((uint64(y) * uint64(chunkWidth)) + uint64(x))
+			bits := uint64(0)
+
+			/*
+			 * Values outside the array are fill values.
+			 */
+			if (globalX >= width) || (globalY >= height) {
+
+				/*
+				 * Check if array holds counts.
+				 */
+				if counts == nil {
+					bits = nan
+				}
+
+			} else {
+				idx := (uint64(globalY) * uint64(width)) + uint64(globalX)
+
+				/*
+				 * Check if array holds counts.
+				 */
+				if counts != nil {
+					bits = counts[idx]
+					used = used || (bits != 0)
+				} else {
+					value := values[idx]
+					bits = math.Float64bits(value)
+					used = used || !math.IsNaN(value)
+				}
+
+			}
+
+			binary.LittleEndian.PutUint64(buf[pos:pos+BYTES_PER_VALUE], bits)
+		}
+
+	}
+
+	return used
+}
+
+/*
+ * Data structure writing a hierarchy into a store.
+ */
+type writerStruct struct {
+	config   configStruct
+	metadata map[string]interface{}
+	store    Store
+}
+
+/*
+ * Encode a value as JSON without escaping angle brackets, which occur in
+ * data types.
+ */
+func encodeJSON(value interface{}) ([]byte, error) {
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "\t")
+	err := encoder.Encode(value)
+	return buf.Bytes(), err
+}
+
+/*
+ * Encode a metadata object and write it into the store.
+ */
+func (this *writerStruct) putMetadata(key string, value interface{}) error {
+	data, err := encodeJSON(value)
+
+	/*
+	 * Check if metadata could be encoded.
+	 */
+	if err != nil {
+		return fmt.Errorf("Failed to encode '%s': %s", key, err.Error())
+	} else {
+		this.metadata[key] = value
+		return this.store.Put(key, data)
+	}
+
+}
+
+/*
+ * Compress a chunk if compression is enabled.
+ */
+func (this *writerStruct) compress(chunk []byte) ([]byte, error) {
+
+	/*
+	 * Check if compression is enabled.
+	 */
+	if !this.config.compressed {
+		return chunk, nil
+	} else {
+		buf := bytes.Buffer{}
+		w := zlib.NewWriter(&buf)
+		w.Write(chunk)
+		err := w.Close()
+		return buf.Bytes(), err
+	}
+
+}
+
+/*
+ * Write the metadata and all chunks of an array.
+ */
+func (this *writerStruct) putArray(array *arrayStruct) error {
+	name := array.name
+	chunkWidth := array.width
+	chunkHeight := uint32(1)
+	shape := []uint32{array.width}
+
+	/*
+	 * One-dimensional arrays are written as a single chunk, which must not
+	 * be empty.
+	 */
+	if chunkWidth == 0 {
+		chunkWidth = 1
+	}
+
+	/*
+	 * Two-dimensional arrays are split into square chunks.
+	 */
+	if len(array.dimensions) == 2 {
+		chunkSize := this.config.chunkSize
+		chunkWidth = chunkSize
+		chunkHeight = chunkSize
+		shape = []uint32{array.height, array.width}
+	}
+
+	chunks := []uint32{chunkWidth}
+
+	/*
+	 * Chunks have the same number of dimensions as the array.
+	 */
+	if len(array.dimensions) == 2 {
+		chunks = []uint32{chunkHeight, chunkWidth}
+	}
+
+	compressor := (*compressorStruct)(nil)
+
+	/*
+	 * Describe compressor if compression is enabled.
+	 */
+	if this.config.compressed {
+
+		/*
+		 * Create compressor description.
+		 */
+		compressor = &compressorStruct{
+			ID:    "zlib",
+			Level: zlib.DefaultCompression,
+		}
+
+	}
+
+	/*
+	 * Create array metadata.
+	 */
+	metadata := arrayMetadataStruct{
+		Chunks:     chunks,
+		Compressor: compressor,
+		DType:      array.dtype(),
+		FillValue:  array.fillValue(),
+		Filters:    nil,
+		Order:      "C",
+		Shape:      shape,
+		ZarrFormat: ZARR_FORMAT,
+	}
+
+	attributes := map[string]interface{}{}
+
+	/*
+	 * Copy attributes of the array.
+	 */
+	for key, value := range array.attributes {
+		attributes[key] = value
+	}
+
+	attributes["_ARRAY_DIMENSIONS"] = array.dimensions
+	err := this.putMetadata(name+"/.zarray", &metadata)
+
+	/*
+	 * Write array attributes.
+	 */
+	if err == nil {
+		err = this.putMetadata(name+"/.zattrs", attributes)
+	}
+
+	/*
+	 * Check if metadata could be written.
+	 */
+	if err != nil {
+		return err
+	}
+
+	chunkBytes := BYTES_PER_VALUE * uint64(chunkWidth) * uint64(chunkHeight)
+	buf := make([]byte, chunkBytes)
+
+	/*
+	 * Iterate over all rows of chunks.
+	 */
+	for i := uint32(0); (uint64(i) * uint64(chunkHeight)) < uint64(array.height); i++ {
+		row := i * chunkHeight
+
+		/*
+		 * Iterate over all chunks of the row.
+		 */
+		for j := uint32(0); (uint64(j) * uint64(chunkWidth)) < uint64(array.width); j++ {
+			col := j * chunkWidth
+			used := array.encodeChunk(buf, row, col, chunkHeight, chunkWidth)
+
+			/*
+			 * Chunks consisting only of fill values are omitted.
+			 */
+			if used {
+				key := fmt.Sprintf("%s/%d", name, j)
+
+				/*
+				 * Two-dimensional chunks are indexed by row and column.
+				 */
+				if len(array.dimensions) == 2 {
+					key = fmt.Sprintf("%s/%d.%d", name, i, j)
+				}
+
+				data, err := this.compress(buf)
+
+				/*
+				 * Write chunk if it could be compressed.
+				 */
+				if err == nil {
+					err = this.store.Put(key, data)
+				}
+
+				/*
+				 * Check if chunk could be written.
+				 */
+				if err != nil {
+					return fmt.Errorf("Failed to write chunk '%s': %s", key, err.Error())
+				}
+
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
+/*
+ * Check if a name may be used for an array.
+ */
+func validName(name string) bool {
+	return (name != "") && !strings.ContainsAny(name, "/\\.") && (strings.TrimSpace(name) == name)
+}
+
+/*
+ * Write the counts of a scene as a Zarr (version 2) hierarchy, so that they
+ * can be analyzed using e. g. xarray or Zarr.jl.
+ *
+ * The root group holds the one-dimensional arrays "y" and "x" holding the
+ * centers of the rows (from top to bottom) and columns of bins, and the
+ * two-dimensional array "count" holding the counts as 64-bit unsigned
+ * integers. Additional grids are written using WithVariable. Dimension names
+ * are recorded as expected by xarray, and consolidated metadata is written,
+ * so that the hierarchy can be opened using a single request.
+ */
+func Write(store Store, scn scene.Scene, options ...Option) error {
+
+	/*
+	 * The default configuration.
+	 */
+	config := configStruct{
+		attributes: map[string]interface{}{},
+		chunkSize:  DEFAULT_CHUNK_SIZE,
+		compressed: true,
+	}
+
+	/*
+	 * Apply all options.
+	 */
+	for _, option := range options {
+
+		/*
+		 * Skip nil options.
+		 */
+		if option != nil {
+			option(&config)
+		}
+
+	}
+
+	width, height := scn.Dimensions()
+	bounds := scn.Bounds()
+	min := bounds.Min()
+	max := bounds.Max()
+	widthFloat := float64(width)
+	heightFloat := float64(height)
+	sizeX := (max.X() - min.X()) / widthFloat
+	sizeY := (max.Y() - min.Y()) / heightFloat
+	xs := make([]float64, width)
+	ys := make([]float64, height)
+
+	/*
+	 * Calculate the centers of all columns.
+	 */
+	for i := range xs {
+		xs[i] = min.X() + ((float64(i) + 0.5) * sizeX)
+	}
+
+	/*
+	 * Calculate the centers of all rows.
+	 */
+	for i := range ys {
+		ys[i] = max.Y() - ((float64(i) + 0.5) * sizeY)
+	}
+
+	attributesX := map[string]interface{}{"long_name": "x-coordinate of bin center"}
+	attributesY := map[string]interface{}{"long_name": "y-coordinate of bin center"}
+
+	/*
+	 * Add units to coordinate arrays if known.
+	 */
+	if config.units != "" {
+		attributesX["units"] = config.units
+		attributesY["units"] = config.units
+	}
+
+	/*
+	 * The coordinate and count arrays.
+	 */
+	arrays := []arrayStruct{
+		arrayStruct{
+			attributes: attributesY,
+			dimensions: []string{"y"},
+			height:     1,
+			name:       "y",
+			values:     ys,
+			width:      height,
+		},
+		arrayStruct{
+			attributes: attributesX,
+			dimensions: []string{"x"},
+			height:     1,
+			name:       "x",
+			values:     xs,
+			width:      width,
+		},
+		arrayStruct{
+			attributes: map[string]interface{}{"long_name": "number of points per bin"},
+			counts:     scn.Counts(),
+			dimensions: []string{"y", "x"},
+			height:     height,
+			name:       "count",
+			width:      width,
+		},
+	}
+
+	arrays = append(arrays, config.variables...)
+	numBins := uint64(width) * uint64(height)
+	names := map[string]bool{}
+
+	/*
+	 * Verify all arrays.
+	 */
+	for i := range arrays {
+		array := &arrays[i]
+		name := array.name
+
+		/*
+		 * Additional variables cover the whole scene.
+		 */
+		if len(array.dimensions) == 2 {
+			array.height = height
+			array.width = width
+		}
+
+		numValues := uint64(len(array.values)) + uint64(len(array.counts))
+		expected := uint64(array.width) * uint64(array.height)
+
+		/*
+		 * Check name and size of array.
+		 */
+		if !validName(name) {
+			return fmt.Errorf("Invalid array name: '%s'", name)
+		} else if names[name] {
+			return fmt.Errorf("Duplicate array name: '%s'", name)
+		} else if numValues != expected {
+			return fmt.Errorf("Array '%s' has %d values, but expected %d.", name, numValues, expected)
+		}
+
+		names[name] = true
+	}
+
+	/*
+	 * Verify chunk size.
+	 */
+	if (config.chunkSize == 0) && (numBins > 0) {
+		return fmt.Errorf("%s", "Chunk size must be positive.")
+	}
+
+	/*
+	 * Create writer data structure.
+	 */
+	writer := writerStruct{
+		config:   config,
+		metadata: map[string]interface{}{},
+		store:    store,
+	}
+
+	/*
+	 * Create group metadata.
+	 */
+	group := groupMetadataStruct{
+		ZarrFormat: ZARR_FORMAT,
+	}
+
+	err := writer.putMetadata(".zgroup", &group)
+
+	/*
+	 * Write group attributes.
+	 */
+	if err == nil {
+		err = writer.putMetadata(".zattrs", config.attributes)
+	}
+
+	/*
+	 * Write each array.
+	 */
+	for i := range arrays {
+
+		/*
+		 * Stop at the first error.
+		 */
+		if err == nil {
+			err = writer.putArray(&arrays[i])
+		}
+
+	}
+
+	/*
+	 * Check if hierarchy could be written.
+	 */
+	if err != nil {
+		return err
+	} else {
+
+		/*
+		 * Create consolidated metadata.
+		 */
+		consolidated := consolidatedStruct{
+			Metadata: writer.metadata,
+			Format:   CONSOLIDATED_FORMAT,
+		}
+
+		data, err := encodeJSON(&consolidated)
+
+		/*
+		 * Check if consolidated metadata could be encoded.
+		 */
+		if err != nil {
+			return fmt.Errorf("Failed to encode consolidated metadata: %s", err.Error())
+		} else {
+			return store.Put(".zmetadata", data)
+		}
+
+	}
+
+}
@@ -0,0 +1,377 @@
+package zarr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/andrepxx/sydney/coordinates"
+	"github.com/andrepxx/sydney/scene"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+/*
+ * Data structure representing a store keeping objects in memory.
+ */
+type memoryStoreStruct struct {
+	objects map[string][]byte
+}
+
+/*
+ * Keep an object in memory.
+ */
+func (this *memoryStoreStruct) Put(key string, data []byte) error {
+	this.objects[key] = append([]byte{}, data...)
+	return nil
+}
+
+/*
+ * Data structure representing the metadata of an array as it is read.
+ */
+type readMetadataStruct struct {
+	Chunks     []uint32          `json:"chunks"`
+	Compressor *compressorStruct `json:"compressor"`
+	DType      string            `json:"dtype"`
+	Shape      []uint32          `json:"shape"`
+	ZarrFormat int               `json:"zarr_format"`
+}
+
+/*
+ * Read the metadata of an array from a store.
+ */
+func readMetadata(store *memoryStoreStruct, name string) (readMetadataStruct, error) {
+	metadata := readMetadataStruct{}
+	data, ok := store.objects[name+"/.zarray"]
+
+	/*
+	 * Check if metadata exists.
+	 */
+	if !ok {
+		return metadata, fmt.Errorf("Array '%s' has no metadata.", name)
+	} else {
+		err := json.Unmarshal(data, &metadata)
+		return metadata, err
+	}
+
+}
+
+/*
+ * Read all values of an array from a store as raw 64-bit words, filling
+ * missing chunks with the given fill value.
+ */
+func readArray(store *memoryStoreStruct, name string, fill uint64) ([]uint64, readMetadataStruct, error) {
+	metadata, err := readMetadata(store, name)
+
+	/*
+	 * Check if metadata could be read.
+	 */
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	shape := metadata.Shape
+	chunks := metadata.Chunks
+
+	/*
+	 * One-dimensional arrays have a height of one.
+	 */
+	if len(shape) == 1 {
+		shape = []uint32{1, shape[0]}
+		chunks = []uint32{1, chunks[0]}
+	}
+
+	height := shape[0]
+	width := shape[1]
+	values := make([]uint64, uint64(width)*uint64(height))
+
+	/*
+	 * Iterate over all rows of chunks.
+	 */
+	for i := uint32(0); i*chunks[0] < height; i++ {
+
+		/*
+		 * Iterate over all chunks of the row.
+		 */
+		for j := uint32(0); j*chunks[1] < width; j++ {
+			key := fmt.Sprintf("%s/%d", name, j)
+
+			/*
+			 * Two-dimensional chunks are indexed by row and column.
+			 */
+			if len(metadata.Shape) == 2 {
+				key = fmt.Sprintf("%s/%d.%d", name, i, j)
+			}
+
+			data, ok := store.objects[key]
+
+			/*
+			 * Decompress chunk if needed.
+			 */
+			if ok && (metadata.Compressor != nil) {
+				rd, err := zlib.NewReader(bytes.NewReader(data))
+
+				/*
+				 * Check if chunk could be decompressed.
+				 */
+				if err == nil {
+					data, err = ioutil.ReadAll(rd)
+				}
+
+				/*
+				 * Check if chunk could be decompressed.
+				 */
+				if err != nil {
+					return nil, metadata, err
+				}
+
+			}
+
+			/*
+			 * Check if chunk has the expected size.
+			 */
+			if ok && (uint64(len(data)) != BYTES_PER_VALUE*uint64(chunks[0])*uint64(chunks[1])) {
+				return nil, metadata, fmt.Errorf("Chunk '%s' has %d bytes.", key, len(data))
+			}
+
+			/*
+			 * Copy each value of the chunk which lies within the array.
+			 */
+			for y := uint32(0); (y < chunks[0]) && (i*chunks[0]+y < height); y++ {
+
+				/*
+				 * Copy each value of the row.
+				 */
+				for x := uint32(0); (x < chunks[1]) && (j*chunks[1]+x < width); x++ {
+					idx := (uint64(i*chunks[0]+y) * uint64(width)) + uint64(j*chunks[1]+x)
+					values[idx] = fill
+
+					/*
+					 * Missing chunks hold fill values.
+					 */
+					if ok {
+						pos := BYTES_PER_VALUE * ((y * chunks[1]) + x)
+						values[idx] = binary.LittleEndian.Uint64(data[pos:])
+					}
+
+				}
+
+			}
+
+		}
+
+	}
+
+	return values, metadata, nil
+}
+
+/*
+ * Returns whether floating-point values equal raw 64-bit words.
+ */
+func equalFloats(words []uint64, values []float64) bool {
+
+	/*
+	 * Check if lengths match.
+	 */
+	if len(words) != len(values) {
+		return false
+	}
+
+	/*
+	 * Compare each value, treating NaN as equal.
+	 */
+	for i, value := range values {
+		word := math.Float64frombits(words[i])
+
+		/*
+		 * Check if values differ.
+		 */
+		if (word != value) && !(math.IsNaN(word) && math.IsNaN(value)) {
+			return false
+		}
+
+	}
+
+	return true
+}
+
+/*
+ * Write a scene with and without compression and read it back.
+ */
+func TestWrite(t *testing.T) {
+	scn := scene.Create(5, 3, 0.0, 10.0, -3.0, 0.0)
+
+	/*
+	 * Points falling into some of the bins, leaving the chunk in the
+	 * lower right corner empty.
+	 */
+	points := []coordinates.Cartesian{
+		coordinates.CreateCartesian(0.5, -0.5),
+		coordinates.CreateCartesian(0.7, -0.2),
+		coordinates.CreateCartesian(5.5, -0.5),
+		coordinates.CreateCartesian(1.0, -2.5),
+	}
+
+	scn.Aggregate(points)
+	counts := scn.Counts()
+	nan := math.NaN()
+	speed := make([]float64, len(counts))
+
+	/*
+	 * Only the upper left bin holds a speed.
+	 */
+	for i := range speed {
+		speed[i] = nan
+	}
+
+	speed[0] = 12.5
+
+	/*
+	 * Write with and without compression.
+	 */
+	for _, compressed := range []bool{false, true} {
+		store := memoryStoreStruct{objects: map[string][]byte{}}
+		err := Write(&store, scn, WithChunkSize(2), WithCompression(compressed), WithCoordinateUnits("m"), WithAttribute("title", "Test"), WithVariable("speed", speed), nil)
+
+		/*
+		 * Check if hierarchy could be written.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		readCounts, metadata, err := readArray(&store, "count", 0)
+
+		/*
+		 * Check if counts could be read.
+		 */
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		readSpeed, _, errSpeed := readArray(&store, "speed", math.Float64bits(nan))
+		readX, _, errX := readArray(&store, "x", 0)
+		readY, _, errY := readArray(&store, "y", 0)
+		attributes := map[string]interface{}{}
+		errAttributes := json.Unmarshal(store.objects[".zattrs"], &attributes)
+		arrayAttributes := map[string]interface{}{}
+		errArrayAttributes := json.Unmarshal(store.objects["x/.zattrs"], &arrayAttributes)
+		consolidated := map[string]interface{}{}
+		errConsolidated := json.Unmarshal(store.objects[".zmetadata"], &consolidated)
+
+		/*
+		 * Check if all objects could be read.
+		 */
+		if (errSpeed != nil) || (errX != nil) || (errY != nil) || (errAttributes != nil) || (errArrayAttributes != nil) || (errConsolidated != nil) {
+			t.Fatalf("%s", "Failed to read objects.")
+		}
+
+		_, emptyChunk := store.objects["count/1.2"]
+
+		/*
+		 * Compare the hierarchy with the scene.
+		 */
+		if fmt.Sprintf("%v %v %s", metadata.Shape, metadata.Chunks, metadata.DType) != "[3 5] [2 2] <u8" {
+			t.Errorf("Array count has shape %v, chunks %v and type %s.", metadata.Shape, metadata.Chunks, metadata.DType)
+		} else if (metadata.Compressor != nil) != compressed {
+			t.Errorf("Compressor is %v, but compression is %t.", metadata.Compressor, compressed)
+		} else if fmt.Sprint(readCounts) != fmt.Sprint(counts) {
+			t.Errorf("Counts are %v, expected %v.", readCounts, counts)
+		} else if emptyChunk {
+			t.Errorf("%s", "Chunk without counts was written.")
+		} else if !equalFloats(readSpeed, speed) {
+			t.Errorf("%s", "Speed does not match.")
+		} else if !equalFloats(readX, []float64{1.0, 3.0, 5.0, 7.0, 9.0}) {
+			t.Errorf("%s", "Array x does not match.")
+		} else if !equalFloats(readY, []float64{-0.5, -1.5, -2.5}) {
+			t.Errorf("%s", "Array y does not match.")
+		} else if (attributes["title"] != "Test") || (arrayAttributes["units"] != "m") {
+			t.Errorf("%s", "Attributes do not match.")
+		} else if fmt.Sprint(arrayAttributes["_ARRAY_DIMENSIONS"]) != "[x]" {
+			t.Errorf("Dimensions of x are %v.", arrayAttributes["_ARRAY_DIMENSIONS"])
+		} else if consolidated["zarr_consolidated_format"] != float64(CONSOLIDATED_FORMAT) {
+			t.Errorf("%s", "Consolidated metadata has wrong format.")
+		}
+
+	}
+
+}
+
+/*
+ * Write a hierarchy into a directory.
+ */
+func TestDirectoryStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "density.zarr")
+	store := DirectoryStore(path)
+	scn := scene.Create(2, 2, 0.0, 2.0, 0.0, 2.0)
+	err := Write(store, scn)
+
+	/*
+	 * Check if hierarchy could be written.
+	 */
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "count", ".zarray"))
+	metadata := readMetadataStruct{}
+
+	/*
+	 * Decode the metadata if it could be read.
+	 */
+	if err == nil {
+		err = json.Unmarshal(data, &metadata)
+	}
+
+	/*
+	 * Check the metadata.
+	 */
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	} else if (metadata.ZarrFormat != ZARR_FORMAT) || (metadata.Compressor == nil) {
+		t.Errorf("%s", "Metadata does not match the defaults.")
+	}
+
+}
+
+/*
+ * Invalid arrays and options are rejected.
+ */
+func TestWriteInvalid(t *testing.T) {
+	scn := scene.Create(3, 2, 0.0, 3.0, 0.0, 2.0)
+	values := make([]float64, 6)
+
+	/*
+	 * Options which must be rejected.
+	 */
+	cases := []struct {
+		name   string
+		option Option
+	}{
+		{name: "duplicate", option: WithVariable("x", values)},
+		{name: "empty name", option: WithVariable("", values)},
+		{name: "dot", option: WithVariable("a.b", values)},
+		{name: "size", option: WithVariable("speed", values[:5])},
+		{name: "chunk size", option: WithChunkSize(0)},
+	}
+
+	/*
+	 * Write the scene using each option.
+	 */
+	for _, c := range cases {
+		store := memoryStoreStruct{objects: map[string][]byte{}}
+		err := Write(&store, scn, c.option)
+
+		/*
+		 * Check if an error was returned.
+		 */
+		if err == nil {
+			t.Errorf("Expected an error for case '%s'.", c.name)
+		}
+
+	}
+
+}